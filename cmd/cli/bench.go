@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dfanso/commit-msg/internal/bench"
+	"github.com/dfanso/commit-msg/internal/utils"
+	"github.com/pterm/pterm"
+)
+
+// RunBenchmark times the local stages of generation on a synthetic
+// repository sized by opts and prints each stage against its budget. The
+// process exits with ExitError when a stage is over budget.
+func RunBenchmark(opts bench.Options) {
+	spinner, _ := pterm.DefaultSpinner.Start(fmt.Sprintf("Timing %d runs over %d files of %d changed lines...", opts.Runs, opts.Files, opts.Lines))
+	results, size, err := bench.Run(opts)
+	if err != nil {
+		spinner.Fail("Benchmark failed")
+		exitf(ExitError, "%v\n", err)
+	}
+	spinner.Success(fmt.Sprintf("Collected %s of changes", utils.FormatBytes(int64(size))))
+
+	pterm.Println()
+	rows := [][]string{{"Stage", "Median", "Budget", "Status"}}
+	var over []string
+	for _, result := range results {
+		status := pterm.Green("ok")
+		if result.OverBudget() {
+			status = pterm.Red("over budget")
+			over = append(over, result.Stage)
+		}
+		rows = append(rows, []string{
+			result.Stage,
+			result.Median.Round(time.Microsecond).String(),
+			result.Budget.Round(time.Millisecond).String(),
+			status,
+		})
+	}
+	pterm.DefaultTable.WithHasHeader().WithData(rows).Render()
+	pterm.Println()
+
+	if len(over) > 0 {
+		exitf(ExitError, "Over budget: %v\n", over)
+	}
+	pterm.Success.Println("Every stage is within its budget.")
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/dfanso/commit-msg/internal/blocklist"
+	"github.com/dfanso/commit-msg/internal/config"
+	"github.com/dfanso/commit-msg/internal/logging"
+	"github.com/dfanso/commit-msg/pkg/types"
+	"github.com/pterm/pterm"
+)
+
+// blockedRetries is how many times a message containing a blocked phrase is
+// regenerated before generation fails.
+const blockedRetries = 2
+
+var (
+	blockedPhrasesOnce sync.Once
+	blockedPhrases     *blocklist.List
+)
+
+// loadBlocklist reads the blocked phrases from config.json once per run.
+func loadBlocklist() *blocklist.List {
+	blockedPhrasesOnce.Do(func() {
+		settings, err := config.LoadBlocklist()
+		if err != nil {
+			pterm.Warning.Printf("Ignoring blocked phrases: %v\n", err)
+		}
+		blockedPhrases = blocklist.New(settings.Phrases)
+	})
+	return blockedPhrases
+}
+
+// withBlocklist wraps generate so no message contains a phrase from the
+// blocklist. Candidates with a blocked phrase are dropped, and when every
+// message has one the provider is asked again with an instruction to avoid
+// them, failing after blockedRetries attempts.
+func withBlocklist(generate generateFunc) generateFunc {
+	list := loadBlocklist()
+	if list.Empty() {
+		return generate
+	}
+	return func(opts *types.GenerationOptions) (*types.GenerationResult, error) {
+		request := opts
+		var avoid []string
+		for retry := 0; ; retry++ {
+			result, err := generate(request)
+			if err != nil {
+				return nil, err
+			}
+
+			found := list.Find(result.Message)
+			var allowed []string
+			for _, alternative := range result.Alternatives {
+				if len(list.Find(alternative)) == 0 {
+					allowed = append(allowed, alternative)
+				}
+			}
+			if len(found) > 0 && len(allowed) > 0 {
+				logging.Debug("blocked message replaced by a candidate", "phrases", found)
+				result.Message, found = allowed[0], nil
+			}
+			if len(found) == 0 {
+				result.Alternatives = allowed
+				return result, nil
+			}
+
+			if retry == blockedRetries {
+				return nil, fmt.Errorf("generated message still contains blocked phrases after %d retries: %s", blockedRetries, strings.Join(found, ", "))
+			}
+			logging.Debug("regenerating blocked message", "phrases", found, "retry", retry+1)
+			for _, phrase := range found {
+				if !slices.Contains(avoid, phrase) {
+					avoid = append(avoid, phrase)
+				}
+			}
+			request = withInstruction(opts, fmt.Sprintf(
+				"Do not use these words or phrases anywhere in the message: %s.", quotedList(avoid)))
+			// A later attempt number keeps the blocked message in the cache
+			// from being returned again.
+			request.Attempt = max(request.Attempt, 1) + retry + 1
+		}
+	}
+}
+
+func quotedList(values []string) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = fmt.Sprintf("%q", value)
+	}
+	return strings.Join(quoted, ", ")
+}
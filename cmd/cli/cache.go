@@ -5,9 +5,25 @@ import (
 	"time"
 
 	"github.com/dfanso/commit-msg/cmd/cli/store"
+	"github.com/dfanso/commit-msg/internal/cache"
+	"github.com/dfanso/commit-msg/internal/config"
+	"github.com/dfanso/commit-msg/internal/history"
+	"github.com/dfanso/commit-msg/internal/sqlstore"
+	"github.com/dfanso/commit-msg/internal/utils"
 	"github.com/pterm/pterm"
 )
 
+// ResetCacheStats zeroes the cache hit and miss counters, keeping the
+// cached messages.
+func ResetCacheStats(Store *store.StoreMethods) error {
+	if err := Store.ResetCacheStats(); err != nil {
+		return fmt.Errorf("failed to reset cache statistics: %w", err)
+	}
+	pterm.Success.Println("Cache hit and miss counters reset.")
+	pterm.Println()
+	return nil
+}
+
 // ShowCacheStats displays cache statistics.
 func ShowCacheStats(Store *store.StoreMethods) error {
 	stats := Store.GetCacheStats()
@@ -26,7 +42,7 @@ func ShowCacheStats(Store *store.StoreMethods) error {
 		{"Cache Misses", fmt.Sprintf("%d", stats.TotalMisses)},
 		{"Hit Rate", fmt.Sprintf("%.2f%%", stats.HitRate*100)},
 		{"Total Cost Saved", fmt.Sprintf("$%.4f", stats.TotalCostSaved)},
-		{"Cache Size", formatBytes(stats.CacheSizeBytes)},
+		{"Cache Size", utils.FormatBytes(stats.CacheSizeBytes)},
 	}
 
 	if stats.OldestEntry != "" {
@@ -37,6 +53,10 @@ func ShowCacheStats(Store *store.StoreMethods) error {
 		statsData = append(statsData, []string{"Newest Entry", formatTime(stats.NewestEntry)})
 	}
 
+	if stats.LastCleanup != "" {
+		statsData = append(statsData, []string{"Last Cleanup", formatTime(stats.LastCleanup)})
+	}
+
 	pterm.DefaultTable.WithHasHeader(false).WithData(statsData).Render()
 
 	pterm.Println()
@@ -143,20 +163,6 @@ func CleanupCache(Store *store.StoreMethods) error {
 
 // Helper functions
 
-// formatBytes formats bytes into human-readable format.
-func formatBytes(bytes int64) string {
-	const unit = 1024
-	if bytes < unit {
-		return fmt.Sprintf("%d B", bytes)
-	}
-	div, exp := int64(unit), 0
-	for n := bytes / unit; n >= unit; n /= unit {
-		div *= unit
-		exp++
-	}
-	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
-}
-
 // formatTime formats a timestamp string for display.
 func formatTime(timestamp string) string {
 	t, err := time.Parse(time.RFC3339, timestamp)
@@ -165,3 +171,62 @@ func formatTime(timestamp string) string {
 	}
 	return t.Format("2006-01-02 15:04:05")
 }
+
+// MigrateStorage copies cache.json and history.json into the SQLite
+// database, replacing what it held, so the "storage" section of config.json
+// can switch to it without losing entries.
+func MigrateStorage() error {
+	pterm.DefaultHeader.WithFullWidth().
+		WithBackgroundStyle(pterm.NewStyle(pterm.BgBlue)).
+		WithTextStyle(pterm.NewStyle(pterm.FgWhite, pterm.Bold)).
+		Println("Migrate to SQLite")
+
+	pterm.Println()
+
+	dbPath, err := sqlstore.DefaultPath()
+	if err != nil {
+		return err
+	}
+	db, err := sqlstore.Open(dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	cachePath, err := cache.DefaultPath()
+	if err != nil {
+		return err
+	}
+	entries, err := cache.Migrate(cache.NewJSONBackend(cachePath), cache.NewSQLiteBackend(db))
+	if err != nil {
+		return fmt.Errorf("failed to migrate cache: %w", err)
+	}
+
+	historyPath, err := history.DefaultPath()
+	if err != nil {
+		return err
+	}
+	messages, err := history.NewStore(historyPath, 0).Load()
+	if err != nil {
+		return fmt.Errorf("failed to migrate history: %w", err)
+	}
+	settings, err := config.LoadHistory()
+	if err != nil {
+		return err
+	}
+	target := history.NewSQLiteStore(db, settings.MaxEntries)
+	if err := target.Clear(); err != nil {
+		return err
+	}
+	if err := target.Add(messages...); err != nil {
+		return fmt.Errorf("failed to migrate history: %w", err)
+	}
+
+	pterm.Success.Printf("Copied %d cache entries and %d history messages to %s\n", entries, len(messages), dbPath)
+
+	storage, err := config.LoadStorage()
+	if err == nil && !storage.UseSQLite() {
+		pterm.Info.Println(`Add "storage": {"backend": "sqlite"} to config.json to use it.`)
+	}
+	return nil
+}
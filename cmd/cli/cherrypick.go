@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dfanso/commit-msg/internal/git"
+	"github.com/dfanso/commit-msg/internal/postprocess"
+	"github.com/dfanso/commit-msg/pkg/types"
+)
+
+// cherryPickSummary describes a cherry-pick in progress for the prompt: the
+// picked commit's message, the files whose conflicts were resolved, and the
+// original changes to those files so the LLM can tell how the resolution
+// differs from them.
+func cherryPickSummary(config *types.RepoConfig, pick *git.CherryPick) string {
+	var summary strings.Builder
+	fmt.Fprintf(&summary, "Cherry-pick of commit %s in progress.\n", pick.ShortHash())
+	if len(pick.Conflicts) > 0 {
+		summary.WriteString("Conflicts were resolved in:\n- " + strings.Join(pick.Conflicts, "\n- ") + "\n")
+	}
+	summary.WriteString("\nOriginal commit message:\n" + strings.TrimSpace(pick.Message) + "\n")
+	if original, err := pick.OriginalChanges(config); err == nil && original != "" {
+		summary.WriteString("\nOriginal changes to the conflicted files:\n" + original)
+	}
+	return summary.String()
+}
+
+// cherryPickInstruction asks for a message that keeps the picked commit's
+// intent and notes how its conflicts were resolved.
+func cherryPickInstruction(pick *git.CherryPick) string {
+	subject, _, _ := strings.Cut(strings.TrimSpace(pick.Message), "\n")
+	instruction := fmt.Sprintf("These changes cherry-pick commit %s %q. Keep its subject unless the changes no longer do what it says.", pick.ShortHash(), subject)
+	if len(pick.Conflicts) == 0 {
+		return instruction + " Mention any way the changes differ from the original commit."
+	}
+	return instruction + fmt.Sprintf(" Conflicts were resolved in %s: end the body with a short paragraph noting how each was resolved compared with the original changes.",
+		strings.Join(pick.Conflicts, ", "))
+}
+
+// withCherryPick wraps generate so every message is written for the
+// cherry-pick in progress.
+func withCherryPick(pick *git.CherryPick, generate generateFunc) generateFunc {
+	return func(opts *types.GenerationOptions) (*types.GenerationResult, error) {
+		return generate(withInstruction(opts, cherryPickInstruction(pick)))
+	}
+}
+
+// withCherryPickReference appends the line git cherry-pick -x adds to name
+// the picked commit. message is returned unchanged when pick is nil.
+func withCherryPickReference(pick *git.CherryPick, message string) string {
+	if pick == nil {
+		return message
+	}
+	return postprocess.AddTrailer(message, "(cherry picked from commit "+pick.Hash+")")
+}
@@ -0,0 +1,235 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dfanso/commit-msg/internal/git"
+	"github.com/dfanso/commit-msg/internal/llm"
+	"github.com/dfanso/commit-msg/internal/openaicompat"
+	"github.com/dfanso/commit-msg/internal/postprocess"
+	"github.com/dfanso/commit-msg/internal/scrubber"
+	"github.com/dfanso/commit-msg/pkg/types"
+)
+
+// ciProviderEnv names the environment variable selecting the provider for
+// commit ci when --provider is not given.
+const ciProviderEnv = "COMMIT_MSG_PROVIDER"
+
+// defaultCITimeout bounds a commit ci run when --timeout is not given.
+const defaultCITimeout = 2 * time.Minute
+
+// ciCredentialEnv names the environment variable each built-in provider
+// reads its credential from.
+var ciCredentialEnv = map[types.LLMProvider]string{
+	types.ProviderOpenAI: "OPENAI_API_KEY",
+	types.ProviderClaude: "CLAUDE_API_KEY",
+	types.ProviderGemini: "GEMINI_API_KEY",
+	types.ProviderGrok:   "GROK_API_KEY",
+	types.ProviderGroq:   "GROQ_API_KEY",
+	types.ProviderOllama: "OLLAMA_URL",
+
+	types.ProviderHuggingFace: "HF_TOKEN",
+	types.ProviderVertex:      "GOOGLE_APPLICATION_CREDENTIALS",
+	types.ProviderCohere:      "COHERE_API_KEY",
+	types.ProviderTogether:    openaicompat.Together.KeyEnv,
+	types.ProviderFireworks:   openaicompat.Fireworks.KeyEnv,
+}
+
+// CIOptions controls commit ci.
+type CIOptions struct {
+	// Provider is the LLM provider to use; empty means $COMMIT_MSG_PROVIDER.
+	Provider string
+	// DiffFile is read as the changes instead of the repository; "-" reads
+	// standard input.
+	DiffFile string
+	// Range selects the changes of a commit or a range such as
+	// "origin/main..HEAD" instead of the working tree.
+	Range string
+	// RepoPath is the repository to work in; empty means the current
+	// directory.
+	RepoPath string
+	// Timeout bounds the whole run; zero means defaultCITimeout.
+	Timeout time.Duration
+	// Instruction is added to the prompt as style guidance.
+	Instruction string
+}
+
+// ciError is the JSON written by commit ci when it fails.
+type ciError struct {
+	Error    string `json:"error"`
+	ExitCode int    `json:"exit_code"`
+}
+
+// RunCI generates a commit message without any interaction and prints the
+// result as JSON. Credentials come only from the environment; the keyring
+// and saved providers are never read. The process exits with one of the
+// documented Exit* codes, and failures are reported as JSON too.
+func RunCI(opts CIOptions) {
+	setQuietMode(true)
+
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = defaultCITimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	type outcome struct {
+		result *types.GenerationResult
+		code   int
+		err    error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		result, code, err := generateForCI(ctx, opts)
+		done <- outcome{result: result, code: code, err: err}
+	}()
+
+	// Not every provider honors the context, so the deadline is enforced
+	// here as well.
+	select {
+	case out := <-done:
+		if out.err != nil {
+			ciFail(out.code, out.err)
+		}
+		ciPrint(out.result)
+	case <-ctx.Done():
+		ciFail(ExitTimeout, fmt.Errorf("timed out after %s", timeout))
+	}
+}
+
+func generateForCI(ctx context.Context, opts CIOptions) (*types.GenerationResult, int, error) {
+	name := strings.TrimSpace(opts.Provider)
+	if name == "" {
+		name = strings.TrimSpace(os.Getenv(ciProviderEnv))
+	}
+	if name == "" {
+		return nil, ExitError, fmt.Errorf("no provider selected; use --provider or set %s", ciProviderEnv)
+	}
+	providerType, ok := parseProviderName(name)
+	if !ok {
+		return nil, ExitError, fmt.Errorf("unsupported provider %q (supported: %s)", name, strings.Join(types.GetSupportedProviderStrings(), ", "))
+	}
+
+	dir, err := resolveRepoPath(opts.RepoPath)
+	if err != nil {
+		return nil, ExitError, err
+	}
+
+	changes, code, err := ciChanges(dir, opts)
+	if err != nil {
+		return nil, code, err
+	}
+	if strings.TrimSpace(changes) == "" {
+		return nil, ExitNoChanges, errors.New("no changes to describe")
+	}
+	changes = truncateLargeDiff(condenseChanges(dir, scrubber.ScrubDiff(changes)))
+
+	provider, err := llm.NewProvider(providerType, llm.ProviderOptions{
+		Config: providerConfig(),
+	})
+	if err != nil {
+		if env, ok := ciCredentialEnv[providerType]; ok && errors.Is(err, llm.ErrMissingCredential) {
+			return nil, ExitError, fmt.Errorf("%w; set %s", err, env)
+		}
+		return nil, ExitError, err
+	}
+
+	var styleOpts *types.GenerationOptions
+	if instruction := strings.TrimSpace(opts.Instruction); instruction != "" {
+		styleOpts = &types.GenerationOptions{StyleInstruction: instruction}
+	}
+	prompt := newPromptContext(dir, nil, nil)
+	generate := func(genOpts *types.GenerationOptions) (*types.GenerationResult, error) {
+		result, err := llm.Generate(ctx, provider, changes, genOpts)
+		if err != nil {
+			return nil, err
+		}
+		result.Message = postprocess.Apply(result.Message, loadPostProcessOptions())
+		result.ScrubFindings = scrubber.Findings(changes)
+		return result, nil
+	}
+	generate = withBlocklist(withCorrections(dir, generate))
+
+	result, err := generate(prompt.apply(withAttempt(styleOpts, 1)))
+	if err != nil {
+		return nil, ExitProviderError, fmt.Errorf("%s: %w", providerType, err)
+	}
+	result.Message = formatMessage(result.Message)
+	if result.Message == "" {
+		return nil, ExitProviderError, fmt.Errorf("%s returned an empty commit message", providerType)
+	}
+	return result, ExitSuccess, nil
+}
+
+// parseProviderName is like types.ParseLLMProvider but ignores case, so
+// "openai" selects OpenAI.
+func parseProviderName(name string) (types.LLMProvider, bool) {
+	for _, provider := range types.GetSupportedProviders() {
+		if strings.EqualFold(provider.String(), name) {
+			return provider, true
+		}
+	}
+	return types.ParseLLMProvider(name)
+}
+
+// ciChanges returns the changes commit ci describes: the diff file, the
+// range, or the working tree of the repository at dir. They are not
+// scrubbed yet.
+func ciChanges(dir string, opts CIOptions) (string, int, error) {
+	if opts.DiffFile != "" {
+		var data []byte
+		var err error
+		if opts.DiffFile == "-" {
+			data, err = io.ReadAll(os.Stdin)
+		} else {
+			data, err = os.ReadFile(opts.DiffFile)
+		}
+		if err != nil {
+			return "", ExitError, fmt.Errorf("failed to read diff: %w", err)
+		}
+		return string(data), ExitSuccess, nil
+	}
+
+	if !git.IsRepository(dir) {
+		return "", ExitNotRepository, fmt.Errorf("not a Git repository: %s", dir)
+	}
+	config := &types.RepoConfig{Path: dir, Limits: loadContentLimits()}
+	if opts.Range != "" {
+		changes, err := git.RangeChanges(config, opts.Range)
+		if err != nil {
+			return "", ExitError, err
+		}
+		return changes, ExitSuccess, nil
+	}
+	changes, err := git.GetChanges(config)
+	if err != nil {
+		return "", ExitError, fmt.Errorf("failed to get Git changes: %w", err)
+	}
+	return changes, ExitSuccess, nil
+}
+
+// ciPrint prints result as JSON on stdout, scrubbing the messages first.
+func ciPrint(result *types.GenerationResult) {
+	result.Message = scrubber.ScrubDiff(result.Message)
+	result.Alternatives = scrubber.ScrubAll(result.Alternatives)
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		ciFail(ExitError, err)
+	}
+	fmt.Println(string(data))
+}
+
+// ciFail prints err as JSON on stdout and exits with code.
+func ciFail(code int, err error) {
+	data, _ := json.MarshalIndent(ciError{Error: scrubber.ScrubDiff(err.Error()), ExitCode: code}, "", "  ")
+	fmt.Println(string(data))
+	exit(code)
+}
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dfanso/commit-msg/cmd/cli/store"
+	"github.com/dfanso/commit-msg/internal/cache"
+	"github.com/dfanso/commit-msg/internal/examples"
+	"github.com/dfanso/commit-msg/internal/feedback"
+	"github.com/dfanso/commit-msg/internal/history"
+	"github.com/dfanso/commit-msg/internal/pricing"
+	"github.com/dfanso/commit-msg/internal/sqlstore"
+	"github.com/dfanso/commit-msg/internal/telemetry"
+	StoreUtils "github.com/dfanso/commit-msg/utils"
+	"github.com/pterm/pterm"
+)
+
+// RepairConfig recovers an unreadable config.json from its backup or, when
+// there is none, from the providers that have saved credentials.
+func RepairConfig(Store *store.StoreMethods) error {
+	result, err := Store.RepairConfig()
+	if err != nil {
+		return err
+	}
+
+	if result.CorruptPath != "" {
+		pterm.Info.Printf("Moved the unreadable config to %s\n", result.CorruptPath)
+	}
+	names := make([]string, len(result.Providers))
+	for i, provider := range result.Providers {
+		names[i] = provider.String()
+	}
+
+	switch result.Action {
+	case store.RepairNotNeeded:
+		pterm.Success.Println("Config is readable, nothing to repair")
+	case store.RepairRestoredBackup:
+		pterm.Success.Println("Restored the config from its backup")
+	case store.RepairRebuilt:
+		pterm.Success.Printf("Rebuilt the config with the providers that have saved credentials: %s\n", strings.Join(names, ", "))
+		pterm.Info.Printf("%s is the default. Other settings could not be recovered; see the .corrupt file\n", names[0])
+	}
+	return nil
+}
+
+// configLocation is one of the paths printed by commit config path.
+type configLocation struct {
+	name string
+	path func() (string, error)
+}
+
+// inDir returns a path function for file in the directory of the path
+// returned by base.
+func inDir(base func() (string, error), file string) func() (string, error) {
+	return func() (string, error) {
+		path, err := base()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(filepath.Dir(path), file), nil
+	}
+}
+
+// configLocations lists every file and directory commit-msg uses, for the
+// selected profile.
+func configLocations() []configLocation {
+	return []configLocation{
+		{"config-dir", StoreUtils.GetConfigDir},
+		{"config", StoreUtils.GetConfigPath},
+		{"config-backup", func() (string, error) {
+			path, err := StoreUtils.GetConfigPath()
+			return StoreUtils.BackupPath(path), err
+		}},
+		{"credentials", store.CredentialsDir},
+		{"cache-dir", StoreUtils.GetCacheDir},
+		{"cache", cache.DefaultPath},
+		{"history", history.DefaultPath},
+		{"database", sqlstore.DefaultPath},
+		{"feedback", feedback.DefaultPath},
+		{"examples", examples.DefaultPath},
+		{"telemetry", telemetry.DefaultPath},
+		{"pricing", inDir(StoreUtils.GetConfigPath, pricing.OverrideFileName)},
+	}
+}
+
+// ShowConfigPaths prints where commit-msg keeps its files. With a name it
+// prints only that path, for use in scripts.
+func ShowConfigPaths(name string) error {
+	locations := configLocations()
+
+	if name != "" {
+		for _, location := range locations {
+			if location.name == name {
+				path, err := location.path()
+				if err != nil {
+					return err
+				}
+				fmt.Println(path)
+				return nil
+			}
+		}
+		names := make([]string, len(locations))
+		for i, location := range locations {
+			names[i] = location.name
+		}
+		return fmt.Errorf("unknown location %q, use one of: %s", name, strings.Join(names, ", "))
+	}
+
+	tableData := [][]string{{"Name", "Path"}}
+	for _, location := range locations {
+		path, err := location.path()
+		if err != nil {
+			path = pterm.Red(err.Error())
+		}
+		tableData = append(tableData, []string{location.name, path})
+	}
+	if err := pterm.DefaultTable.WithHasHeader().WithData(tableData).Render(); err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(os.Getenv(StoreUtils.ConfigDirEnv)) != "" {
+		dir, _ := StoreUtils.GetConfigDir()
+		pterm.Println()
+		pterm.Info.Printf("%s is set; every location is under %s\n", StoreUtils.ConfigDirEnv, dir)
+	}
+	return nil
+}
@@ -6,97 +6,657 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
-	"runtime"
+	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 
-	"github.com/atotto/clipboard"
 	"github.com/dfanso/commit-msg/cmd/cli/store"
+	"github.com/dfanso/commit-msg/internal/cache"
+	"github.com/dfanso/commit-msg/internal/chatgpt"
+	"github.com/dfanso/commit-msg/internal/claude"
+	"github.com/dfanso/commit-msg/internal/config"
 	"github.com/dfanso/commit-msg/internal/display"
+	"github.com/dfanso/commit-msg/internal/gemini"
+	"github.com/dfanso/commit-msg/internal/generated"
 	"github.com/dfanso/commit-msg/internal/git"
+	"github.com/dfanso/commit-msg/internal/grok"
+	httpClient "github.com/dfanso/commit-msg/internal/http"
+	"github.com/dfanso/commit-msg/internal/huggingface"
+	"github.com/dfanso/commit-msg/internal/issues"
+	"github.com/dfanso/commit-msg/internal/lint"
 	"github.com/dfanso/commit-msg/internal/llm"
+	"github.com/dfanso/commit-msg/internal/llmerr"
+	"github.com/dfanso/commit-msg/internal/lockfile"
+	"github.com/dfanso/commit-msg/internal/logging"
+	"github.com/dfanso/commit-msg/internal/monorepo"
+	"github.com/dfanso/commit-msg/internal/msgfmt"
+	"github.com/dfanso/commit-msg/internal/ollama"
+	"github.com/dfanso/commit-msg/internal/openaicompat"
+	"github.com/dfanso/commit-msg/internal/platform"
+	"github.com/dfanso/commit-msg/internal/postprocess"
+	"github.com/dfanso/commit-msg/internal/pricing"
+	"github.com/dfanso/commit-msg/internal/projectctx"
+	"github.com/dfanso/commit-msg/internal/scrubber"
 	"github.com/dfanso/commit-msg/internal/stats"
+	"github.com/dfanso/commit-msg/internal/symbols"
+	"github.com/dfanso/commit-msg/internal/telemetry"
+	"github.com/dfanso/commit-msg/internal/testrun"
+	"github.com/dfanso/commit-msg/internal/tui"
+	"github.com/dfanso/commit-msg/internal/vcr"
+	"github.com/dfanso/commit-msg/internal/vcs"
+	"github.com/dfanso/commit-msg/internal/vertex"
 	"github.com/dfanso/commit-msg/pkg/types"
-	"github.com/google/shlex"
 	"github.com/pterm/pterm"
 )
 
+// CreateOptions controls a single commit message generation run.
+type CreateOptions struct {
+	// DryRun displays the prompt without making an API call.
+	DryRun bool
+	// AutoCommit runs git commit with the accepted message.
+	AutoCommit bool
+	// Yes commits with AutoCommit without showing the final message and
+	// asking for confirmation first.
+	Yes bool
+	// SignOff adds a Signed-off-by trailer for the committer, like git
+	// commit --signoff.
+	SignOff bool
+	// Quiet suppresses all decoration, skips the interactive review, and
+	// prints only the generated message to stdout.
+	Quiet bool
+	// StageAll runs git add -A before collecting changes.
+	StageAll bool
+	// StageTracked runs git add -u before collecting changes.
+	StageTracked bool
+	// RepoPath is the repository to generate a message for. When empty the
+	// current directory is used.
+	RepoPath string
+	// WithTests runs the project's quick test command and adds a pass/fail
+	// summary to the prompt.
+	WithTests bool
+	// TestCommand overrides the detected test command.
+	TestCommand string
+	// WithIssue fetches the issue referenced by the branch name and adds
+	// its title and description to the prompt.
+	WithIssue bool
+	// PerPackage generates a separate message for each monorepo package
+	// touched by the changes instead of asking.
+	PerPackage bool
+	// Offline refuses network providers, using a local Ollama endpoint or
+	// the rule-based generator instead.
+	Offline bool
+	// NoLLM builds the message with the rule-based generator only; no
+	// provider needs to be configured.
+	NoLLM bool
+	// Editor overrides the editor used to edit the message in the review.
+	Editor string
+	// Provider names a saved provider to use for this run instead of the
+	// default.
+	Provider string
+	// Style names the style preset the message is generated in.
+	Style string
+	// Instruction is custom style guidance, added to Style's instruction
+	// when both are set.
+	Instruction string
+	// Temperature overrides the provider's sampling temperature when set.
+	Temperature *float64
+	// Seed requests deterministic sampling from providers that support it.
+	Seed *int64
+	// Candidates is the number of messages requested in one call for the
+	// interactive review; providers that cannot return several produce one.
+	Candidates int
+	// Oneline restricts the message to a single subject line of at most
+	// SubjectLimit characters, or the max_subject_length lint rule when
+	// SubjectLimit is zero.
+	Oneline      bool
+	SubjectLimit int
+	// WithNote asks for a longer explanation of the change after
+	// AutoCommit and attaches it to the new commit as a git note.
+	WithNote bool
+}
+
+// maxCandidates bounds --candidates; each candidate is billed as output.
+const maxCandidates = 5
+
 // CreateCommitMsg launches the interactive flow for reviewing, regenerating,
-// editing, and accepting AI-generated commit messages in the current repo.
-// If dryRun is true, it displays the prompt without making an API call.
-func CreateCommitMsg(Store *store.StoreMethods, dryRun bool, autoCommit bool) {
-	// Validate COMMIT_LLM and required API keys
-	useLLM, err := Store.DefaultLLMKey()
+// editing, and accepting AI-generated commit messages in the current repo
+// (or opts.RepoPath when set).
+// The process exits with one of the documented Exit* codes on failure.
+func CreateCommitMsg(Store *store.StoreMethods, opts CreateOptions) {
+	dryRun := opts.DryRun
+	autoCommit := opts.AutoCommit
+	setQuietMode(opts.Quiet)
+
+	style, err := resolveStyle(opts.Style, opts.Instruction)
 	if err != nil {
-		pterm.Error.Printf("No LLM configured. Run: commit llm setup\n")
-		os.Exit(1)
+		exitf(ExitError, "%v\n", err)
 	}
 
-	commitLLM := useLLM.LLM
-	apiKey := useLLM.APIKey
+	// Validate COMMIT_LLM and required API keys
+	var commitLLM types.LLMProvider
+	var apiKey string
+	useLLM, err := savedLLM(Store, opts.Provider)
+	switch {
+	case opts.NoLLM, err != nil && opts.Offline && opts.Provider == "":
+		commitLLM = ruleBasedProvider
+	case err != nil && opts.Provider != "":
+		exitf(ExitError, "%v\n", err)
+	case err != nil:
+		exitf(ExitError, "No LLM configured. Run: commit llm setup\n")
+	case opts.Offline:
+		commitLLM, apiKey = offlineProvider(Store, useLLM)
+	default:
+		commitLLM, apiKey = useLLM.LLM, useLLM.APIKey
+	}
 
-	// Get current directory
-	currentDir, err := os.Getwd()
+	currentDir, err := resolveRepoPath(opts.RepoPath)
 	if err != nil {
-		pterm.Error.Printf("Failed to get current directory: %v\n", err)
-		os.Exit(1)
+		exitf(ExitError, "%v\n", err)
 	}
 
-	// Check if current directory is a git repository
-	if !git.IsRepository(currentDir) {
-		pterm.Error.Printf("Current directory is not a Git repository: %s\n", currentDir)
-		os.Exit(1)
+	// Check if the target directory is a git, jj, or hg repository
+	backend, err := vcs.Detect(currentDir)
+	if err != nil {
+		if opts.RepoPath != "" {
+			exitf(ExitNotRepository, "Not a Git, Jujutsu, or Mercurial repository: %s\n", currentDir)
+		}
+		exitf(ExitNotRepository, "Current directory is not a Git, Jujutsu, or Mercurial repository: %s\n", currentDir)
 	}
-
-	config := &types.Config{
-		GrokAPI: "https://api.x.ai/v1/chat/completions",
+	logging.Debug("detected repository", "backend", backend.Name(), "path", backend.Path())
+	if opts.WithNote && backend.Name() != "git" {
+		exitf(ExitError, "--with-note is only supported in Git repositories\n")
 	}
 
-	repoConfig := types.RepoConfig{Path: currentDir}
+	config := providerConfig()
 
-	fileStats, err := stats.GetFileStatistics(&repoConfig)
-	if err != nil {
-		pterm.Error.Printf("Failed to get file statistics: %v\n", err)
-		os.Exit(1)
+	repoConfig := types.RepoConfig{Path: currentDir, Limits: loadContentLimits()}
+	if limiter, ok := backend.(vcs.Limiter); ok {
+		limiter.SetLimits(repoConfig.Limits)
+	}
+
+	if opts.StageAll || opts.StageTracked {
+		stager, ok := backend.(vcs.Stager)
+		if !ok {
+			exitf(ExitError, "%s repositories have no staging area; drop --add-all/--update\n", backend.Name())
+		}
+		// A dry run must leave the index alone. The preview already covers
+		// unstaged and untracked changes, so it shows what would be staged.
+		switch {
+		case dryRun && opts.StageAll:
+			pterm.Info.Println("--dry-run: nothing is staged; the preview includes the unstaged and untracked changes --add-all would stage.")
+		case dryRun:
+			pterm.Info.Println("--dry-run: nothing is staged; the preview includes the unstaged changes --update would stage, plus untracked files it would leave out.")
+		default:
+			if err := stager.Stage(!opts.StageAll); err != nil {
+				exitf(ExitError, "Failed to stage changes: %v\n", err)
+			}
+		}
 	}
 
 	pterm.DefaultHeader.WithFullWidth().
 		WithBackgroundStyle(pterm.NewStyle(pterm.BgCyan)).
 		WithTextStyle(pterm.NewStyle(pterm.FgBlack, pterm.Bold)).
 		Println("Commit Message Generator")
+	pterm.Println()
+
+	progress := &pipelineProgress{}
+	progress.start(stageCollect)
+	fileStats, err := backend.Statistics()
+	if err != nil {
+		progress.fail("Failed to collect changes")
+		exitf(ExitError, "Failed to get file statistics: %v\n", err)
+	}
+	changes, err := backend.Changes()
+	if err != nil {
+		progress.fail("Failed to collect changes")
+		exitf(ExitError, "Failed to get %s changes: %v\n", backend.Name(), err)
+	}
+	var pick *git.CherryPick
+	if backend.Name() == "git" {
+		pick, err = git.InProgressCherryPick(&repoConfig)
+		if err != nil {
+			progress.fail("Failed to collect changes")
+			exitf(ExitError, "Failed to read the cherry-pick in progress: %v\n", err)
+		}
+		if pick != nil && len(pick.Unmerged) > 0 {
+			progress.fail("Cherry-pick has unresolved conflicts")
+			exitf(ExitError, "Resolve and stage the conflicts in %s before generating a message.\n", strings.Join(pick.Unmerged, ", "))
+		}
+	}
+	progress.start(stageScrub)
+	changes = scrubber.ScrubDiff(changes)
+	progress.finish(true, "")
 
 	pterm.Println()
 	display.ShowFileStatistics(fileStats)
 
-	if fileStats.TotalFiles == 0 {
+	if fileStats.TotalFiles == 0 || len(changes) == 0 {
 		pterm.Warning.Println("No changes detected in the Git repository.")
 		pterm.Info.Println("Tips:")
 		pterm.Info.Println("  - Stage your changes with: git add .")
 		pterm.Info.Println("  - Check repository status with: git status")
 		pterm.Info.Println("  - Make sure you're in the correct Git repository")
+		exit(ExitNoChanges)
+	}
+	if pick != nil {
+		pterm.Info.Printf("Cherry-pick of %s in progress; the message will reference it.\n", pick.ShortHash())
+	}
+
+	// Tests show their own spinner, so they run before the prompt stage.
+	testSummary := ""
+	if opts.WithTests {
+		testSummary = runTestsForPrompt(backend.Path(), opts.TestCommand)
+	}
+
+	progress.start(stageBuildPrompt)
+	// Lead with the structured summaries so they survive truncation. Each
+	// one goes before those added earlier.
+	var summaries []string
+	lead := func(summary string) {
+		if summary != "" {
+			summaries = append([]string{summary}, summaries...)
+		}
+	}
+	if backend.Name() == "git" {
+		lead(goSymbolSummary(currentDir))
+	}
+	lead(testSummary)
+	if opts.WithIssue && opts.Offline {
+		pterm.Warning.Println("Skipping --with-issue: fetching the issue needs network access.")
+	} else if opts.WithIssue && backend.Name() == "git" {
+		lead(issueContextForPrompt(Store, currentDir))
+	}
+	lead(stats.SummarizeLanguages(fileStats.Languages))
+	if pick != nil {
+		lead(cherryPickSummary(&repoConfig, pick))
+	}
+	if len(summaries) > 0 {
+		// Test output, source, and commit messages can hold secrets too.
+		changes = scrubber.ScrubDiff(strings.Join(summaries, "\n")) + "\n" + changes
+	}
+	changes = truncateLargeDiff(condenseChanges(currentDir, changes))
+
+	var workspace *monorepo.Workspace
+	var changedPackages []string
+	if backend.Name() == "git" {
+		workspace, changedPackages = detectChangedPackages(&repoConfig)
+	}
+	prompt := newPromptContext(currentDir, workspace, changedPackages)
+	progress.finish(true, "")
+
+	subjectLimit := opts.subjectLimit()
+
+	// Handle dry-run mode: display what would be sent to LLM without making API call
+	if dryRun {
+		dryRunOpts := prompt.apply(withAttempt(styleOptions(style), 1))
+		dryRunOpts.Temperature = opts.Temperature
+		dryRunOpts.Seed = opts.Seed
+		if opts.Oneline {
+			dryRunOpts = withInstruction(dryRunOpts, onelineInstruction(subjectLimit))
+		}
+		if pick != nil {
+			dryRunOpts = withInstruction(dryRunOpts, cherryPickInstruction(pick))
+		}
+		if quietMode {
+			fmt.Println(dryRunPrompt(changes, dryRunOpts))
+			return
+		}
+		pterm.Println()
+		displayDryRunInfo(commitLLM, config, changes, apiKey, dryRunOpts)
 		return
 	}
 
-	changes, err := git.GetChanges(&repoConfig)
+	ctx := context.Background()
+
+	var providerInstance llm.Provider
+	if commitLLM == ruleBasedProvider {
+		providerInstance = &ruleBasedGenerator{files: ruleBasedFiles(backend, fileStats)}
+	} else {
+		providerInstance, err = llm.NewProvider(commitLLM, llm.ProviderOptions{
+			Credential: apiKey,
+			Config:     config,
+		})
+		if err != nil {
+			displayProviderError(commitLLM, err)
+			exit(ExitProviderError)
+		}
+	}
+
+	// The rule-based generator only sees the whole file list, so it always
+	// writes a single message.
+	if pick == nil && len(changedPackages) > 1 && commitLLM != ruleBasedProvider && (opts.PerPackage || (!quietMode && confirmPerPackage(workspace, changedPackages))) {
+		generatePerPackage(ctx, providerInstance, Store, commitLLM, workspace, changedPackages, fileStats, opts, style)
+		return
+	}
+
+	generate := func(genOpts *types.GenerationOptions) (*types.GenerationResult, error) {
+		progress.start(stageProvider)
+		progress.update(fmt.Sprintf("%s (%s)...", stageProvider, commitLLM))
+		result, err := generateMessageWithCache(ctx, providerInstance, Store, commitLLM, changes, genOpts)
+		if err == nil {
+			progress.start(stagePostProcess)
+		}
+		return result, err
+	}
+	generate = withCorrections(currentDir, generate)
+	if pick != nil {
+		generate = withCherryPick(pick, generate)
+	}
+	warnings := commitMessageLengthWarnings
+	if opts.Oneline {
+		generate = withOneline(subjectLimit, generate)
+		warnings = onelineWarnings(subjectLimit)
+	}
+	generate = withBlocklist(generate)
+
+	pterm.Println()
+	attempt := 1
+	firstOpts := prompt.apply(withAttempt(styleOptions(style), attempt))
+	firstOpts.Temperature = opts.Temperature
+	firstOpts.Seed = opts.Seed
+	if !quietMode {
+		firstOpts.Candidates = opts.Candidates
+	}
+	firstOpts.Progress = spinnerProgress(progress.update, fmt.Sprintf("%s (%s)", stageProvider, commitLLM))
+	generated, err := generate(firstOpts)
+	if delay, ok := retryDelay(err); ok {
+		logging.Debug("retrying after a transient provider error", "delay", delay, "error", err)
+		progress.update(fmt.Sprintf("%s (%s) failed; retrying in %s...", stageProvider, commitLLM, delay))
+		time.Sleep(delay)
+		generated, err = generate(firstOpts)
+	}
 	if err != nil {
-		pterm.Error.Printf("Failed to get Git changes: %v\n", err)
-		os.Exit(1)
+		progress.fail("Failed to generate commit message")
+		displayProviderError(commitLLM, err)
+		// Only transient failures are worth falling back from: bad keys,
+		// configuration mistakes, and prompts refused for sensitive data
+		// would fail the same way again and need fixing first. Scripts rely
+		// on the provider error exit code, so only the interactive flow
+		// falls back to a rule-based message for review.
+		if quietMode || commitLLM == ruleBasedProvider || !llm.Retryable(err) {
+			exit(ExitProviderError)
+		}
+
+		// The providers listed under "fallback" in config.json are tried
+		// best rated first (see commit usage); --offline keeps to the
+		// rule-based generator. A local provider was likely chosen to keep
+		// the code on this machine, so leaving it for a remote one needs
+		// the user's go-ahead.
+		recovered := false
+		if !opts.Offline {
+			local := llm.IsLocal(commitLLM, apiKey)
+			for _, next := range fallbackProviders(commitLLM) {
+				saved, err := Store.LLMKey(next)
+				if err != nil {
+					logging.Debug("skipping fallback provider", "provider", next, "error", err)
+					continue
+				}
+				if local && !llm.IsLocal(next, saved.APIKey) && !confirmRemoteFallback(commitLLM, next) {
+					continue
+				}
+				instance, err := llm.NewProvider(next, llm.ProviderOptions{Credential: saved.APIKey, Config: config})
+				if err != nil {
+					logging.Debug("skipping fallback provider", "provider", next, "error", err)
+					continue
+				}
+				pterm.Warning.Printf("Falling back to %s.\n", next)
+				commitLLM, providerInstance = next, instance
+				if generated, err = generate(firstOpts); err == nil {
+					recovered = true
+					break
+				}
+				progress.fail("Failed to generate commit message")
+				displayProviderError(next, err)
+			}
+		}
+		if !recovered {
+			pterm.Warning.Println("Falling back to a rule-based message; review it before committing.")
+			commitLLM = ruleBasedProvider
+			providerInstance = &ruleBasedGenerator{files: ruleBasedFiles(backend, fileStats)}
+			generated, err = generate(prompt.apply(withAttempt(nil, attempt)))
+			if err != nil {
+				exitf(ExitProviderError, "Failed to build a rule-based message: %v\n", err)
+			}
+		} else {
+			progress.succeed("Commit message generated (" + display.GenerationSummary(generated) + ")")
+		}
+	} else {
+		progress.succeed("Commit message generated (" + display.GenerationSummary(generated) + ")")
 	}
 
-	if len(changes) == 0 {
-		pterm.Warning.Println("No changes detected in the Git repository.")
-		pterm.Info.Println("Tips:")
-		pterm.Info.Println("  - Stage your changes with: git add .")
-		pterm.Info.Println("  - Check repository status with: git status")
-		pterm.Info.Println("  - Make sure you're in the correct Git repository")
+	currentMessage := strings.TrimSpace(generated.Message)
+	if quietMode {
+		if currentMessage == "" {
+			exitf(ExitProviderError, "Generated commit message is empty\n")
+		}
+		currentMessage = withAttribution(currentDir, commitLLM, withCherryPickReference(pick, formatMessage(currentMessage)))
+		if opts.SignOff {
+			if currentMessage, err = withSignOff(currentDir, currentMessage); err != nil {
+				exitf(ExitError, "Failed to sign off: %v\n", err)
+			}
+		}
+		fmt.Println(currentMessage)
+		if autoCommit && !dryRun {
+			if err := runAutoCommit(backend, currentMessage); err != nil {
+				exitf(ExitError, "Failed to commit: %v\n", err)
+			}
+			if opts.WithNote {
+				attachNote(ctx, providerInstance, commitLLM, currentDir, changes, currentMessage, prompt)
+			}
+		}
 		return
 	}
 
-	//  Large diff handling
-	const maxDiffChars = 8000 // can change as needed
-	const maxDiffLines = 300
+	result, err := tui.Run(tui.Config{
+		Diff:         changes,
+		Message:      currentMessage,
+		Alternatives: generated.Alternatives,
+		Styles:       loadStylePresets(),
+		Style:        style,
+		Temperature:  opts.Temperature,
+		Generate: func(genOpts *types.GenerationOptions) (string, error) {
+			genOpts = prompt.apply(genOpts)
+			genOpts.Seed = opts.Seed
+			generated, err := generate(genOpts)
+			if err != nil {
+				return "", err
+			}
+			return generated.Message, nil
+		},
+		EditorCommand: editorCommandFor(currentDir, opts.Editor),
+		PagerCommand:  pagerCommand,
+		Warnings:      warnings,
+	})
+	if err != nil {
+		exitf(ExitError, "Failed to run interactive review: %v\n", err)
+	}
+
+	recordHistory(currentDir, commitLLM, result)
+	if !result.Accepted {
+		pterm.Info.Println("Exiting without copying commit message.")
+		exit(ExitCancelled)
+	}
+
+	finalMessage := formatMessage(result.Message)
+	rememberAcceptedMessage(currentDir, finalMessage)
+	finalMessage = withAttribution(currentDir, commitLLM, withCherryPickReference(pick, finalMessage))
+	if opts.SignOff || result.SignOff {
+		if finalMessage, err = withSignOff(currentDir, finalMessage); err != nil {
+			exitf(ExitError, "Failed to sign off: %v\n", err)
+		}
+	}
+	pterm.Println()
+	display.ShowCommitMessage(finalMessage)
+	validateCommitMessageLength(finalMessage, warnings)
+
+	if err := platform.CopyToClipboard(finalMessage); err != nil {
+		pterm.Warning.Printf("Could not copy to clipboard: %v\n", err)
+	} else {
+		pterm.Success.Println("Commit message copied to clipboard!")
+	}
+
+	pterm.Println()
+	display.ShowChangesPreview(fileStats)
+
+	if autoCommit && !dryRun && !opts.Yes {
+		pterm.Println()
+		display.ShowCommitConfirmation(result.Message, finalMessage)
+		if !confirmAutoCommit("Commit with this message?") {
+			pterm.Info.Println("Commit cancelled.")
+			exit(ExitCancelled)
+		}
+	}
+
+	// Commit if the flag is set or the message was accepted with commit
+	// (cross-platform compatible)
+	if (autoCommit || result.Commit) && !dryRun {
+		text := "Automatically committing with generated message..."
+		if !autoCommit {
+			text = "Committing with the accepted message..."
+		}
+		pterm.Println()
+		spinner, err := pterm.DefaultSpinner.
+			WithSequence("⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏").
+			Start(text)
+		if err != nil {
+			exitf(ExitError, "Failed to start spinner: %v\n", err)
+		}
+
+		if err := runAutoCommit(backend, finalMessage); err != nil {
+			spinner.Fail("Commit failed")
+			exitf(ExitError, "Failed to commit: %v\n", err)
+		}
+
+		spinner.Success("Committed successfully!")
+
+		if opts.WithNote {
+			attachNote(ctx, providerInstance, commitLLM, currentDir, changes, finalMessage, prompt)
+		}
+	}
+}
+
+// resolveRepoPath returns the absolute directory to operate on: path when
+// given, otherwise the current working directory.
+func resolveRepoPath(path string) (string, error) {
+	if strings.TrimSpace(path) == "" {
+		dir, err := os.Getwd()
+		if err != nil {
+			return "", fmt.Errorf("failed to get current directory: %w", err)
+		}
+		return dir, nil
+	}
+
+	dir, err := filepath.Abs(platform.ResolvePath(path))
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve repository path %q: %w", path, err)
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return "", fmt.Errorf("repository path %q is not accessible: %w", path, err)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("repository path %q is not a directory", path)
+	}
+	return dir, nil
+}
+
+// attributionConfigKey is the git config key holding the attribution
+// trailer. Setting it in a repository's .git/config enables attribution for
+// that repository only.
+const attributionConfigKey = "commitmsg.trailer"
+
+// defaultAttributionTrailer is used when attributionConfigKey is "true".
+const defaultAttributionTrailer = "Commit-Generated-By: gocommit/{provider}"
+
+// withAttribution appends the attribution trailer configured for the
+// repository at dir to an accepted message. {provider} and {model} in the
+// trailer are replaced with the provider and model that wrote the message.
+// Rule-based messages are not attributed.
+func withAttribution(dir string, providerType types.LLMProvider, message string) string {
+	trailer := git.ConfigValue(&types.RepoConfig{Path: dir}, attributionConfigKey)
+	switch strings.ToLower(trailer) {
+	case "", "false", "no", "off", "0":
+		return message
+	case "true", "yes", "on", "1":
+		trailer = defaultAttributionTrailer
+	}
+	if providerType == ruleBasedProvider {
+		return message
+	}
+
+	trailer = strings.NewReplacer(
+		"{provider}", providerType.String(),
+		"{model}", llm.ModelFor(providerType),
+	).Replace(trailer)
+	return postprocess.AddTrailer(message, trailer)
+}
+
+// confirmAutoCommit asks question before --auto commits, after the final
+// message has been shown.
+func confirmAutoCommit(question string) bool {
+	confirm, err := pterm.DefaultInteractiveConfirm.
+		WithDefaultValue(true).
+		Show(question)
+	if err != nil {
+		exitf(ExitError, "Failed to get confirmation: %v\n", err)
+	}
+	return confirm
+}
+
+// withSignOff adds the Signed-off-by trailer for the committer of the
+// repository at dir to message.
+func withSignOff(dir, message string) (string, error) {
+	trailer, err := git.SignOffTrailer(&types.RepoConfig{Path: dir})
+	if err != nil {
+		return "", err
+	}
+	return postprocess.AddTrailer(message, trailer), nil
+}
+
+// runAutoCommit commits the pending changes with message. When paths are
+// given only those files are committed. The VCS's own output is echoed as
+// info so users see the resulting commit summary.
+func runAutoCommit(backend vcs.Backend, message string, paths ...string) error {
+	output, err := backend.Commit(message, paths...)
+	if err != nil {
+		return err
+	}
+
+	if output != "" {
+		pterm.Info.Println(scrubber.ScrubDiff(output))
+	}
+	return nil
+}
+
+// condenseChanges replaces diffs that say little about intent with short
+// notes: lockfiles become the dependency versions they change, and generated
+// files in the repository at dir a one-line summary.
+func condenseChanges(dir, changes string) string {
+	root, err := git.RepoRoot(dir)
+	if err != nil {
+		root = dir
+	}
+	condensed := generated.New(root, loadGeneratedPatterns()).Collapse(lockfile.Summarize(changes))
+	if len(condensed) < len(changes) {
+		logging.Debug("condensed changes", "chars_before", len(changes), "chars_after", len(condensed))
+		return condensed
+	}
+	return changes
+}
+
+// truncateLargeDiff trims changes that would likely exceed the LLM's context
+// window, warning the user when it does so. The budget comes from the
+// max_total_prompt_bytes limit, and a marker tells the LLM the diff is cut.
+func truncateLargeDiff(changes string) string {
+	// The line cap scales with the byte budget: 300 lines at the default.
+	const defaultMaxDiffLines = 300
+	maxDiffChars := loadContentLimits().MaxTotalPromptBytes
+	maxDiffLines := maxDiffChars * defaultMaxDiffLines / types.DefaultMaxTotalPromptBytes
 
 	diffLines := strings.Split(changes, "\n")
 	diffTooLarge := len(changes) > maxDiffChars || len(diffLines) > maxDiffLines
+	logging.Debug("collected changes", "lines", len(diffLines), "chars", len(changes), "truncate", diffTooLarge)
 
 	if diffTooLarge {
 		pterm.Warning.Println("The diff is very large and may exceed the LLM's context window.")
@@ -119,200 +679,127 @@ func CreateCommitMsg(Store *store.StoreMethods, dryRun bool, autoCommit bool) {
 			totalChars += lineLen
 		}
 
-		changes = strings.Join(truncatedLines, "\n")
 		actualLineCount := len(truncatedLines)
+		truncatedLines = append(truncatedLines, fmt.Sprintf("[... diff truncated: %d of %d lines shown (max_total_prompt_bytes=%d)]",
+			actualLineCount, len(diffLines), maxDiffChars))
+		changes = strings.Join(truncatedLines, "\n")
 
 		pterm.Info.Printf("Truncated diff to %d lines, %d characters.\n", actualLineCount, len(changes))
 		pterm.Info.Println("Consider committing smaller changes for more accurate commit messages.")
 	}
 
-	// Handle dry-run mode: display what would be sent to LLM without making API call
-	if dryRun {
-		pterm.Println()
-		displayDryRunInfo(commitLLM, config, changes, apiKey)
-		return
-	}
+	return changes
+}
 
-	ctx := context.Background()
+// runTestsForPrompt runs the project's quick tests in dir and returns the
+// pass/fail summary for the prompt. Problems running the tests are reported
+// as warnings and never stop generation.
+func runTestsForPrompt(dir, command string) string {
+	if command == "" {
+		command = testrun.DetectCommand(dir)
+	}
+	if command == "" {
+		pterm.Warning.Printf("No test command detected; set %s or pass --test-cmd.\n", testrun.CommandEnv)
+		return ""
+	}
 
-	providerInstance, err := llm.NewProvider(commitLLM, llm.ProviderOptions{
-		Credential: apiKey,
-		Config:     config,
-	})
+	spinner, err := pterm.DefaultSpinner.Start("Running tests: " + command)
 	if err != nil {
-		displayProviderError(commitLLM, err)
-		os.Exit(1)
+		exitf(ExitError, "Failed to start spinner: %v\n", err)
 	}
 
-	pterm.Println()
-	spinnerGenerating, err := pterm.DefaultSpinner.
-		WithSequence("⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏").
-		Start("Generating commit message with " + commitLLM.String() + "...")
-	if err != nil {
-		pterm.Error.Printf("Failed to start spinner: %v\n", err)
-		os.Exit(1)
+	result, err := testrun.Run(context.Background(), dir, command, testrun.DefaultTimeout)
+	switch {
+	case err != nil:
+		spinner.Warning(fmt.Sprintf("Could not run tests: %v", err))
+		return ""
+	case result.TimedOut:
+		spinner.Warning(fmt.Sprintf("Tests timed out after %s", testrun.DefaultTimeout))
+	case result.Passed:
+		spinner.Success(fmt.Sprintf("Tests passed in %s", result.Duration.Round(100*time.Millisecond)))
+	default:
+		spinner.Warning(fmt.Sprintf("Tests failed (%d failing tests recognised)", len(result.Failures)))
 	}
+	return result.Summary()
+}
 
-	attempt := 1
-	commitMsg, err := generateMessageWithCache(ctx, providerInstance, Store, commitLLM, changes, withAttempt(nil, attempt))
+// goSymbolSummary lists the Go functions, methods, and types added,
+// modified, or removed by the pending changes compared with HEAD.
+func goSymbolSummary(dir string) string {
+	root, err := git.RepoRoot(dir)
 	if err != nil {
-		spinnerGenerating.Fail("Failed to generate commit message")
-		displayProviderError(commitLLM, err)
-		os.Exit(1)
+		logging.Debug("symbol extraction skipped", "error", err)
+		return ""
 	}
+	config := &types.RepoConfig{Path: root}
 
-	spinnerGenerating.Success("Commit message generated successfully!")
-
-	currentMessage := strings.TrimSpace(commitMsg)
-	validateCommitMessageLength(currentMessage)
-	currentStyleLabel := stylePresets[0].Label
-	var currentStyleOpts *types.GenerationOptions
-	accepted := false
-	finalMessage := ""
+	files, err := git.ChangedFiles(config)
+	if err != nil {
+		logging.Debug("symbol extraction skipped", "error", err)
+		return ""
+	}
 
-interactionLoop:
-	for {
-		pterm.Println()
-		display.ShowCommitMessage(currentMessage)
+	var changes []symbols.FileChange
+	for _, file := range files {
+		if !symbols.Supported(file) {
+			continue
+		}
 
-		action, err := promptActionSelection()
+		oldSrc, _ := git.FileAtRevision(config, "HEAD", file)
+		newSrc, err := os.ReadFile(filepath.Join(root, file))
 		if err != nil {
-			pterm.Error.Printf("Failed to read selection: %v\n", err)
-			return
+			newSrc = nil
 		}
 
-		switch action {
-		case actionAcceptOption:
-			finalMessage = strings.TrimSpace(currentMessage)
-			if finalMessage == "" {
-				pterm.Warning.Println("Commit message is empty; please edit or regenerate before accepting.")
-				continue
-			}
-			if err := clipboard.WriteAll(finalMessage); err != nil {
-				pterm.Warning.Printf("Could not copy to clipboard: %v\n", err)
-			} else {
-				pterm.Success.Println("Commit message copied to clipboard!")
-			}
-			accepted = true
-			break interactionLoop
-		case actionRegenerateOption:
-			opts, styleLabel, err := promptStyleSelection(currentStyleLabel, currentStyleOpts)
-			if errors.Is(err, errSelectionCancelled) {
-				continue
-			}
-			if err != nil {
-				pterm.Error.Printf("Failed to select style: %v\n", err)
-				continue
-			}
-			if styleLabel != "" {
-				currentStyleLabel = styleLabel
-			}
-			currentStyleOpts = opts
-			nextAttempt := attempt + 1
-			generationOpts := withAttempt(currentStyleOpts, nextAttempt)
-			spinner, err := pterm.DefaultSpinner.
-				WithSequence("⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏").
-				Start(fmt.Sprintf("Regenerating commit message (%s)...", currentStyleLabel))
-			if err != nil {
-				pterm.Error.Printf("Failed to start spinner: %v\n", err)
-				continue
-			}
-			updatedMessage, genErr := generateMessageWithCache(ctx, providerInstance, Store, commitLLM, changes, generationOpts)
-			if genErr != nil {
-				spinner.Fail("Regeneration failed")
-				displayProviderError(commitLLM, genErr)
-				continue
-			}
-			spinner.Success("Commit message regenerated!")
-			attempt = nextAttempt
-			currentMessage = strings.TrimSpace(updatedMessage)
-			validateCommitMessageLength(currentMessage)
-		case actionEditOption:
-			edited, editErr := editCommitMessage(currentMessage)
-			if editErr != nil {
-				pterm.Error.Printf("Failed to edit commit message: %v\n", editErr)
-				continue
-			}
-			if strings.TrimSpace(edited) == "" {
-				pterm.Warning.Println("Edited commit message is empty; keeping previous message.")
-				continue
-			}
-			currentMessage = strings.TrimSpace(edited)
-			validateCommitMessageLength(currentMessage)
-		case actionExitOption:
-			pterm.Info.Println("Exiting without copying commit message.")
-			return
-		default:
-			pterm.Warning.Printf("Unknown selection: %s\n", action)
+		change, err := symbols.DiffGo(file, oldSrc, newSrc)
+		if err != nil {
+			logging.Debug("symbol extraction failed", "file", file, "error", err)
+			continue
 		}
+		changes = append(changes, change)
 	}
+	return symbols.Summarize(changes)
+}
 
-	if !accepted {
-		return
+var (
+	// builtinStyles are offered before the presets saved with commit style
+	// add. --style selects them by name.
+	builtinStyles = []namedStyle{
+		{name: "default", preset: tui.StylePreset{Label: "Concise conventional (default)", Instruction: ""}},
+		{name: "detailed", preset: tui.StylePreset{Label: "Detailed summary (adds bullet list)", Instruction: "Produce a conventional commit subject line followed by a blank line and bullet points summarizing the key changes."}},
+		{name: "casual", preset: tui.StylePreset{Label: "Casual tone", Instruction: "Write the commit message in a friendly, conversational tone while still clearly explaining the changes."}},
+		{name: "bugfix", preset: tui.StylePreset{Label: "Bug fix emphasis", Instruction: "Highlight the bug being fixed, reference the root cause when possible, and describe the remedy in the body."}},
 	}
 
-	pterm.Println()
-	display.ShowChangesPreview(fileStats)
+	priceTableOnce sync.Once
+	priceTable     pricing.Table
 
-	// Auto-commit if flag is set (cross-platform compatible)
-	if autoCommit && !dryRun {
-		pterm.Println()
-		spinner, err := pterm.DefaultSpinner.
-			WithSequence("⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏").
-			Start("Automatically committing with generated message...")
-		if err != nil {
-			pterm.Error.Printf("Failed to start spinner: %v\n", err)
-			return
-		}
+	contentLimitsOnce sync.Once
+	contentLimits     types.ContentLimits
 
-		cmd := exec.Command("git", "commit", "-m", finalMessage)
-		cmd.Dir = currentDir
-		// Ensure git command works across all platforms
-		cmd.Env = os.Environ()
+	promptTemplateOnce sync.Once
+	promptTemplate     string
 
-		output, err := cmd.CombinedOutput()
-		if err != nil {
-			spinner.Fail("Commit failed")
-			pterm.Error.Printf("Failed to commit: %v\n", err)
-			if len(output) > 0 {
-				pterm.Error.Println(string(output))
-			}
-			return
-		}
+	providerConfigOnce     sync.Once
+	providerConfigSettings types.Config
 
-		spinner.Success("Committed successfully!")
-		if len(output) > 0 {
-			pterm.Info.Println(strings.TrimSpace(string(output)))
-		}
-	}
-}
+	postProcessOnce    sync.Once
+	postProcessOptions postprocess.Options
 
-type styleOption struct {
-	Label       string
-	Instruction string
-}
+	lintRulesOnce sync.Once
+	lintRules     lint.Rules
 
-const (
-	actionAcceptOption     = "Accept and copy commit message"
-	actionRegenerateOption = "Regenerate with different tone/style"
-	actionEditOption       = "Edit message in editor"
-	actionExitOption       = "Discard and exit"
-	customStyleOption      = "Custom instructions (enter your own)"
-	styleBackOption        = "Back to actions"
-)
+	generatedPatternsOnce sync.Once
+	generatedPatterns     []string
 
-var (
-	actionOptions = []string{actionAcceptOption, actionRegenerateOption, actionEditOption, actionExitOption}
-	stylePresets  = []styleOption{
-		{Label: "Concise conventional (default)", Instruction: ""},
-		{Label: "Detailed summary (adds bullet list)", Instruction: "Produce a conventional commit subject line followed by a blank line and bullet points summarizing the key changes."},
-		{Label: "Casual tone", Instruction: "Write the commit message in a friendly, conversational tone while still clearly explaining the changes."},
-		{Label: "Bug fix emphasis", Instruction: "Highlight the bug being fixed, reference the root cause when possible, and describe the remedy in the body."},
-	}
-	errSelectionCancelled = errors.New("selection cancelled")
+	projectContextOnce     sync.Once
+	projectContextSettings projectctx.Settings
+
+	cacheSettingsOnce sync.Once
+	cacheSettings     cache.Settings
 )
 
-// resolveOllamaConfig returns the URL and model for Ollama, using environment variables as fallbacks
+// resolveOllamaConfig returns the URL and model for Ollama, using environment variables and the saved model as fallbacks
 func resolveOllamaConfig(apiKey string) (url, model string) {
 	url = apiKey
 	if strings.TrimSpace(url) == "" {
@@ -321,10 +808,7 @@ func resolveOllamaConfig(apiKey string) (url, model string) {
 			url = "http://localhost:11434/api/generate"
 		}
 	}
-	model = os.Getenv("OLLAMA_MODEL")
-	if model == "" {
-		model = "llama3.1"
-	}
+	model = llm.ModelFor(types.ProviderOllama)
 	return url, model
 }
 
@@ -333,179 +817,232 @@ func generateMessage(ctx context.Context, provider llm.Provider, changes string,
 }
 
 // generateMessageWithCache generates a commit message with caching support.
-func generateMessageWithCache(ctx context.Context, provider llm.Provider, store *store.StoreMethods, providerType types.LLMProvider, changes string, opts *types.GenerationOptions) (string, error) {
-	// Check cache first (only for first attempt to avoid caching regenerations)
+func generateMessageWithCache(ctx context.Context, provider llm.Provider, store *store.StoreMethods, providerType types.LLMProvider, changes string, opts *types.GenerationOptions) (*types.GenerationResult, error) {
+	// Rule-based messages are free and instant; caching or rating them
+	// would only skew the statistics.
+	if providerType == ruleBasedProvider {
+		return llm.Generate(ctx, provider, changes, opts)
+	}
+
+	findings := scrubber.Findings(changes)
+
+	// Check cache first (only for first attempt to avoid caching regenerations).
+	// A hit is a single message; candidates are only requested on a miss.
 	if opts == nil || opts.Attempt <= 1 {
 		if cachedEntry, found := store.GetCachedMessage(providerType, changes, opts); found {
+			logging.Debug("cache hit", "provider", providerType, "created_at", cachedEntry.CreatedAt)
 			pterm.Info.Printf("Using cached commit message (saved $%.4f)\n", cachedEntry.Cost)
-			return cachedEntry.Message, nil
+			message := postprocess.Apply(cachedEntry.Message, loadPostProcessOptions())
+			recordGeneration(providerType, changes, opts, message)
+			recordTelemetry(telemetry.Event{Provider: providerType, Model: llm.ModelFor(providerType), CacheHit: true})
+			return &types.GenerationResult{
+				Message:       message,
+				Provider:      providerType,
+				Model:         llm.ModelFor(providerType),
+				Tokens:        cachedEntry.Tokens,
+				Cost:          cachedEntry.Cost,
+				CacheHit:      true,
+				ScrubFindings: findings,
+			}, nil
 		}
+		logging.Debug("cache miss", "provider", providerType)
+	} else {
+		logging.Debug("cache skipped for regeneration", "attempt", opts.Attempt)
 	}
 
 	// Generate new message
-	message, err := provider.Generate(ctx, changes, opts)
+	model := llm.ModelFor(providerType)
+	prompt := types.BuildCommitPrompt(changes, opts)
+	logging.Debug("provider request", "provider", providerType, "model", model, "prompt_chars", len(prompt), "prompt_tokens_est", estimateTokens(prompt))
+	regeneration := opts != nil && opts.Attempt > 1
+	start := time.Now()
+	result, err := llm.Generate(ctx, provider, changes, opts)
 	if err != nil {
-		return "", err
+		logging.Debug("provider error", "provider", providerType, "elapsed", time.Since(start).Round(time.Millisecond), "error", err)
+		recordTelemetry(telemetry.Event{Provider: providerType, Model: model, Latency: time.Since(start), Err: err, Regeneration: regeneration})
+		return nil, err
+	}
+	logging.Debug("provider response", "provider", providerType, "elapsed", result.Duration.Round(time.Millisecond), "response_chars", len(result.Message))
+	recordTelemetry(telemetry.Event{Provider: providerType, Model: model, Latency: result.Duration, Regeneration: regeneration})
+	result.Message = postprocess.Apply(result.Message, loadPostProcessOptions())
+	for i, alternative := range result.Alternatives {
+		result.Alternatives[i] = postprocess.Apply(alternative, loadPostProcessOptions())
+	}
+	result.ScrubFindings = findings
+
+	// Estimate cost, preferring the provider's own token counts
+	result.Cost = estimateCost(providerType, model, estimateTokens(prompt), 100)
+	if result.Tokens != nil {
+		result.Cost = estimateCost(providerType, model, result.Tokens.PromptTokens, result.Tokens.CompletionTokens)
 	}
 
 	// Cache the result (only for first attempt)
 	if opts == nil || opts.Attempt <= 1 {
-		// Estimate cost for caching
-		cost := estimateCost(providerType, estimateTokens(types.BuildCommitPrompt(changes, opts)), 100)
-
-		// Store in cache
-		if cacheErr := store.SetCachedMessage(providerType, changes, opts, message, cost, nil); cacheErr != nil {
+		if cacheErr := store.SetCachedMessage(providerType, changes, opts, result.Message, result.Cost, result.Tokens); cacheErr != nil {
 			// Log cache error but don't fail the generation
-			fmt.Printf("Warning: Failed to cache message: %v\n", cacheErr)
+			pterm.Warning.Printf("Failed to cache message: %v\n", cacheErr)
 		}
 	}
 
-	return message, nil
-}
-
-func promptActionSelection() (string, error) {
-	return pterm.DefaultInteractiveSelect.
-		WithOptions(actionOptions).
-		WithDefaultOption(actionAcceptOption).
-		Show()
+	recordGeneration(providerType, changes, opts, result.Message)
+	return result, nil
 }
 
-func promptStyleSelection(currentLabel string, currentOpts *types.GenerationOptions) (*types.GenerationOptions, string, error) {
-	options := make([]string, 0, len(stylePresets)+3)
-	foundCurrent := false
-	for _, preset := range stylePresets {
-		options = append(options, preset.Label)
-		if preset.Label == currentLabel {
-			foundCurrent = true
+// spinnerProgress returns a GenerationOptions.Progress callback that shows
+// the latest line of streamed output next to the spinner through update, so
+// slow local models visibly make progress.
+func spinnerProgress(update func(string), label string) func(string) {
+	return func(partial string) {
+		lines := strings.Split(strings.TrimSpace(partial), "\n")
+		last := strings.TrimSpace(lines[len(lines)-1])
+		if runes := []rune(last); len(runes) > 60 {
+			last = "…" + string(runes[len(runes)-59:])
 		}
+		update(fmt.Sprintf("%s (%d chars): %s", label, len(partial), last))
 	}
-	if currentOpts != nil && currentLabel != "" && !foundCurrent {
-		options = append(options, currentLabel)
-	}
-	options = append(options, customStyleOption, styleBackOption)
-
-	selector := pterm.DefaultInteractiveSelect.WithOptions(options)
-	if currentLabel != "" {
-		selector = selector.WithDefaultOption(currentLabel)
-	}
+}
 
-	choice, err := selector.Show()
-	if err != nil {
-		return currentOpts, currentLabel, err
-	}
+// editorCommandFor returns a function building the command that opens a file
+// in the user's editor. The editor is the first one set among override (the
+// --editor flag), GIT_EDITOR, git's core.editor for dir, VISUAL, EDITOR, and
+// the editor used last time, in the order git itself uses. The chosen editor
+// is remembered in config.json.
+func editorCommandFor(dir, override string) func(path string) (*exec.Cmd, error) {
+	return func(path string) (*exec.Cmd, error) {
+		var lastEditor string
+		if cfg, err := store.ListSavedModels(); err == nil {
+			lastEditor = cfg.LastEditor
+		}
 
-	switch choice {
-	case styleBackOption:
-		return currentOpts, currentLabel, errSelectionCancelled
-	case customStyleOption:
-		text, err := pterm.DefaultInteractiveTextInput.
-			WithDefaultText("Describe the tone or style you're looking for").
-			Show()
-		if err != nil {
-			return currentOpts, currentLabel, err
+		candidates := []string{
+			override,
+			os.Getenv("GIT_EDITOR"),
+			git.ConfigValue(&types.RepoConfig{Path: dir}, "core.editor"),
+			os.Getenv("VISUAL"),
+			os.Getenv("EDITOR"),
+			lastEditor,
 		}
-		text = strings.TrimSpace(text)
-		if text == "" {
-			return currentOpts, currentLabel, errSelectionCancelled
+		command, args, err := platform.EditorCommand(candidates...)
+		if err != nil {
+			return nil, err
 		}
-		return &types.GenerationOptions{StyleInstruction: text}, formatCustomStyleLabel(text), nil
-	default:
-		for _, preset := range stylePresets {
-			if choice == preset.Label {
-				if strings.TrimSpace(preset.Instruction) == "" {
-					return nil, preset.Label, nil
+
+		for _, candidate := range candidates {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "" {
+				continue
+			}
+			if candidate != lastEditor {
+				if err := store.SaveLastEditor(candidate); err != nil {
+					logging.Debug("failed to remember editor", "error", err)
 				}
-				return &types.GenerationOptions{StyleInstruction: preset.Instruction}, preset.Label, nil
 			}
+			break
 		}
-		if currentOpts != nil && choice == currentLabel {
-			clone := *currentOpts
-			return &clone, currentLabel, nil
-		}
-	}
-
-	if currentOpts != nil && currentLabel != "" {
-		clone := *currentOpts
-		return &clone, currentLabel, nil
+		return exec.Command(command, append(args, path)...), nil
 	}
-	return nil, currentLabel, nil
 }
 
-func editCommitMessage(initial string) (string, error) {
-	command, args, err := resolveEditorCommand()
-	if err != nil {
-		return "", err
+// pagerCommand builds the command the review screen pipes the changes to
+// when viewing them, taken from PAGER. It returns nil when PAGER is unset so
+// the built-in pager is used.
+func pagerCommand() (*exec.Cmd, error) {
+	command, args, err := platform.PagerCommand(os.Getenv("PAGER"))
+	if err != nil || command == "" {
+		return nil, err
 	}
+	return exec.Command(command, args...), nil
+}
 
-	tmpFile, err := os.CreateTemp("", "commit-msg-*.txt")
-	if err != nil {
-		return "", err
-	}
-	defer os.Remove(tmpFile.Name())
+// promptContext carries the repository details exposed to prompt templates
+// into every generation request.
+type promptContext struct {
+	scope         string
+	cacheScope    string
+	branch        string
+	upstream      string
+	repository    string
+	recentCommits string
+	template      string
+	examples      []string
+	variables     map[string]string
+	project       string
+}
 
-	if _, err := tmpFile.WriteString(strings.TrimSpace(initial) + "\n"); err != nil {
-		tmpFile.Close()
-		return "", err
+// newPromptContext collects the name, branch, upstream, and recent commits
+// of the repository at dir along with the configured prompt template, the
+// project context files, and the variables style instructions may
+// reference. packages are the changed packages of workspace, which may be
+// nil. Lookup failures leave the corresponding fields empty. Everything
+// except the template is scrubbed, since it ends up in the prompt.
+func newPromptContext(dir string, workspace *monorepo.Workspace, packages []string) promptContext {
+	prompt := promptContext{
+		scope:    packageScopeInstruction(workspace, packages),
+		template: loadPromptTemplate(),
 	}
 
-	if err := tmpFile.Close(); err != nil {
-		return "", err
+	config := &types.RepoConfig{Path: dir, Limits: loadContentLimits()}
+	if branch, err := git.CurrentBranch(config); err == nil {
+		prompt.branch = branch
 	}
-
-	cmdArgs := append(args, tmpFile.Name())
-	cmd := exec.Command(command, cmdArgs...)
-	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-
-	if err := cmd.Run(); err != nil {
-		return "", fmt.Errorf("editor exited with error: %w", err)
+	var ticket string
+	if ref, ok := issues.DetectRef(prompt.branch); ok {
+		ticket = ref.String()
 	}
-
-	content, err := os.ReadFile(tmpFile.Name())
-	if err != nil {
-		return "", err
+	prompt.variables = map[string]string{
+		types.StyleVarBranch: prompt.branch,
+		types.StyleVarTicket: ticket,
+		types.StyleVarScope:  packageScopeName(packages),
+		types.StyleVarAuthor: git.ConfigValue(config, "user.name"),
 	}
-
-	return strings.TrimSpace(string(content)), nil
-}
-
-func resolveEditorCommand() (string, []string, error) {
-	candidates := []string{
-		os.Getenv("GIT_EDITOR"),
-		os.Getenv("VISUAL"),
-		os.Getenv("EDITOR"),
+	prompt.upstream = git.Upstream(config)
+	prompt.repository = git.RepoName(config)
+	prompt.cacheScope = repoCacheScope(config)
+	if commits, err := git.RecentHistory(config); err == nil {
+		prompt.recentCommits = strings.TrimSpace(commits)
 	}
-
-	for _, candidate := range candidates {
-		candidate = strings.TrimSpace(candidate)
-		if candidate == "" {
-			continue
-		}
-		parts, err := shlex.Split(candidate)
-		if err != nil {
-			return "", nil, fmt.Errorf("failed to parse editor command %q: %w", candidate, err)
-		}
-		if len(parts) == 0 {
-			continue
-		}
-		return parts[0], parts[1:], nil
+	prompt.examples = acceptedExamples(dir)
+	if root, err := git.RepoRoot(dir); err == nil {
+		prompt.project = projectctx.Read(root, loadProjectContext())
 	}
+	return prompt.scrubbed()
+}
 
-	if runtime.GOOS == "windows" {
-		return "notepad", nil, nil
+// scrubbed returns a copy of p with sensitive data removed from the
+// repository details, recent commits, examples, variables, and project
+// context.
+func (p promptContext) scrubbed() promptContext {
+	p.branch = scrubber.ScrubDiff(p.branch)
+	p.upstream = scrubber.ScrubDiff(p.upstream)
+	p.repository = scrubber.ScrubDiff(p.repository)
+	p.recentCommits = scrubber.ScrubDiff(p.recentCommits)
+	p.examples = scrubber.ScrubAll(p.examples)
+	p.project = scrubber.ScrubDiff(p.project)
+	variables := make(map[string]string, len(p.variables))
+	for name, value := range p.variables {
+		variables[name] = scrubber.ScrubDiff(value)
 	}
-
-	return "nano", nil, nil
+	p.variables = variables
+	return p
 }
 
-func formatCustomStyleLabel(instruction string) string {
-	trimmed := strings.TrimSpace(instruction)
-	runes := []rune(trimmed)
-	if len(runes) > 40 {
-		return fmt.Sprintf("Custom: %s…", string(runes[:37]))
+// apply returns a copy of opts carrying the prompt context.
+func (p promptContext) apply(opts *types.GenerationOptions) *types.GenerationOptions {
+	clone := types.GenerationOptions{}
+	if opts != nil {
+		clone = *opts
 	}
-	return fmt.Sprintf("Custom: %s", trimmed)
+	clone.Scope = p.scope
+	clone.Branch = p.branch
+	clone.Upstream = p.upstream
+	clone.Repository = p.repository
+	clone.RecentCommits = p.recentCommits
+	clone.Template = p.template
+	clone.Examples = p.examples
+	clone.Variables = p.variables
+	clone.ProjectContext = p.project
+	clone.CacheScope = p.cacheScope
+	return &clone
 }
 
 func withAttempt(styleOpts *types.GenerationOptions, attempt int) *types.GenerationOptions {
@@ -518,10 +1055,20 @@ func withAttempt(styleOpts *types.GenerationOptions, attempt int) *types.Generat
 }
 
 func displayProviderError(provider types.LLMProvider, err error) {
+	if quietMode {
+		fmt.Fprintf(os.Stderr, "%s error: %v\n", provider, err)
+		return
+	}
+
 	if errors.Is(err, llm.ErrMissingCredential) {
 		displayMissingCredentialHint(provider)
 		return
 	}
+	if hint := providerErrorHint(provider, err); hint != "" {
+		pterm.Error.Printf("%v\n", err)
+		pterm.Info.Println(hint)
+		return
+	}
 
 	switch provider {
 	case types.ProviderGemini:
@@ -536,11 +1083,84 @@ func displayProviderError(provider types.LLMProvider, err error) {
 		pterm.Error.Printf("Grok API error: %v. Check your GROK_API_KEY environment variable or run: commit llm setup\n", err)
 	case types.ProviderOllama:
 		pterm.Error.Printf("Ollama error: %v. Verify the Ollama service URL or run: commit llm setup\n", err)
+	case types.ProviderHuggingFace:
+		pterm.Error.Printf("Hugging Face error: %v. Check your HF_TOKEN environment variable and endpoint, or run: commit llm setup\n", err)
+	case types.ProviderCohere:
+		pterm.Error.Printf("Cohere API error: %v. Check your COHERE_API_KEY environment variable or run: commit llm setup\n", err)
+	case types.ProviderVertex:
+		pterm.Error.Printf("Vertex AI error: %v. Check your Google Cloud credentials, project, and location, or run: commit llm setup\n", err)
+	case types.ProviderTogether, types.ProviderFireworks:
+		preset, _ := openaicompat.Lookup(provider)
+		pterm.Error.Printf("%s API error: %v. Check your %s environment variable or run: commit llm setup\n", preset.Name, err, preset.KeyEnv)
 	default:
 		pterm.Error.Printf("LLM error: %v\n", err)
 	}
 }
 
+// confirmRemoteFallback asks whether to send the changes to the remote
+// provider next after failed, a local provider, could not generate a
+// message. It defaults to no.
+func confirmRemoteFallback(failed, next types.LLMProvider) bool {
+	confirm, err := pterm.DefaultInteractiveConfirm.
+		WithDefaultValue(false).
+		Show(fmt.Sprintf("%s runs locally, but %s would send your changes to a remote service. Fall back to %s?", failed, next, next))
+	if err != nil {
+		logging.Debug("fallback confirmation failed", "error", err)
+		return false
+	}
+	return confirm
+}
+
+// maxRetryDelay bounds how long a transient provider failure is waited out
+// before the single retry; longer Retry-After requests are not retried.
+const maxRetryDelay = 10 * time.Second
+
+// retryDelay reports whether err is worth retrying once, and after how long:
+// the provider's Retry-After when it gives one, otherwise two seconds.
+func retryDelay(err error) (time.Duration, bool) {
+	if err == nil || !llm.Retryable(err) {
+		return 0, false
+	}
+	var apiErr *llmerr.Error
+	if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+		return apiErr.RetryAfter, apiErr.RetryAfter <= maxRetryDelay
+	}
+	return 2 * time.Second, true
+}
+
+// providerErrorHint returns the remediation for a classified provider
+// error, or "" when err did not match a known category.
+func providerErrorHint(provider types.LLMProvider, err error) string {
+	var apiErr *llmerr.Error
+	retryAfter := ""
+	if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+		retryAfter = fmt.Sprintf(" The provider asked to wait %s.", apiErr.RetryAfter)
+	}
+
+	switch {
+	case errors.Is(err, llm.ErrAuth) && provider == types.ProviderVertex:
+		return "Google rejected the Vertex AI credentials. Run: gcloud auth application-default login, or check the service account key and its Vertex AI User role."
+	case errors.Is(err, llm.ErrAuth):
+		return fmt.Sprintf("The %s API key was rejected. Update it with: commit llm update", provider)
+	case errors.Is(err, llm.ErrQuotaExceeded):
+		return fmt.Sprintf("Your %s account is out of quota or credit. Check its billing settings, or switch providers with: commit llm update", provider)
+	case errors.Is(err, llm.ErrRateLimited):
+		return "Too many requests were sent. Wait a moment and try again." + retryAfter
+	case errors.Is(err, llm.ErrModelNotFound):
+		if provider == types.ProviderOllama {
+			return "The model is not installed. Pull it with: ollama pull " + llm.ModelFor(provider)
+		}
+		return fmt.Sprintf("The model %q is not available to your %s account.", llm.ModelFor(provider), provider)
+	case errors.Is(err, llm.ErrSensitiveData):
+		return "Nothing was sent. A commit subject, branch name, file name, or project context file in the prompt looks like a secret; review it with --dry-run and remove the value before retrying."
+	case errors.Is(err, llm.ErrContextTooLarge):
+		return "The changes are too large for the model. Commit fewer files at a time or lower max_total_prompt_bytes in config.json."
+	case llm.Retryable(err):
+		return fmt.Sprintf("%s is having problems. Try again shortly.", provider) + retryAfter
+	}
+	return ""
+}
+
 func displayMissingCredentialHint(provider types.LLMProvider) {
 	switch provider {
 	case types.ProviderGemini:
@@ -555,13 +1175,29 @@ func displayMissingCredentialHint(provider types.LLMProvider) {
 		pterm.Error.Println("Grok requires an API key. Run: commit llm setup or set GROK_API_KEY.")
 	case types.ProviderOllama:
 		pterm.Error.Println("Ollama requires a reachable service URL. Run: commit llm setup or set OLLAMA_URL.")
+	case types.ProviderHuggingFace:
+		pterm.Error.Println("Hugging Face requires an access token. Run: commit llm setup or set HF_TOKEN.")
+	case types.ProviderCohere:
+		pterm.Error.Println("Cohere requires an API key. Run: commit llm setup or set COHERE_API_KEY.")
+	case types.ProviderVertex:
+		pterm.Error.Println("Vertex AI requires Google Cloud credentials. Run: gcloud auth application-default login or set GOOGLE_APPLICATION_CREDENTIALS.")
+	case types.ProviderTogether, types.ProviderFireworks:
+		preset, _ := openaicompat.Lookup(provider)
+		pterm.Error.Printf("%s requires an API key. Run: commit llm setup or set %s.\n", preset.Name, preset.KeyEnv)
 	default:
 		pterm.Error.Printf("%s is missing credentials. Run: commit llm setup.\n", provider)
 	}
 }
 
+// dryRunPrompt renders the prompt --dry-run displays. It is scrubbed again
+// as a whole because style instructions and custom templates come from the
+// user rather than the repository.
+func dryRunPrompt(changes string, opts *types.GenerationOptions) string {
+	return scrubber.ScrubDiff(types.BuildCommitPrompt(changes, opts))
+}
+
 // displayDryRunInfo shows what would be sent to the LLM without making an API call
-func displayDryRunInfo(provider types.LLMProvider, config *types.Config, changes string, apiKey string) {
+func displayDryRunInfo(provider types.LLMProvider, config *types.Config, changes string, apiKey string, opts *types.GenerationOptions) {
 	pterm.DefaultHeader.WithFullWidth().
 		WithBackgroundStyle(pterm.NewStyle(pterm.BgBlue)).
 		WithTextStyle(pterm.NewStyle(pterm.FgWhite, pterm.Bold)).
@@ -576,6 +1212,9 @@ func displayDryRunInfo(provider types.LLMProvider, config *types.Config, changes
 	providerInfo := [][]string{
 		{"Provider", provider.String()},
 	}
+	if model := llm.ModelFor(provider); model != "" && provider != types.ProviderOllama {
+		providerInfo = append(providerInfo, []string{"Model", model})
+	}
 
 	// Add provider-specific info
 	switch provider {
@@ -583,20 +1222,43 @@ func displayDryRunInfo(provider types.LLMProvider, config *types.Config, changes
 		url, model := resolveOllamaConfig(apiKey)
 		providerInfo = append(providerInfo, []string{"Ollama URL", url})
 		providerInfo = append(providerInfo, []string{"Model", model})
+	case ruleBasedProvider:
+		providerInfo = append(providerInfo, []string{"Mode", "Offline, no network access"})
+	case types.ProviderVertex:
+		credentials := "Application Default Credentials"
+		if strings.TrimSpace(apiKey) != "" {
+			credentials = apiKey
+		}
+		providerInfo = append(providerInfo, []string{"Credentials", credentials})
+		providerInfo = append(providerInfo, []string{"Location", vertex.Location()})
+	case types.ProviderOpenAI:
+		providerInfo = append(providerInfo, []string{"API Endpoint", chatgpt.BaseURL(config)})
+		providerInfo = append(providerInfo, []string{"API Key", maskAPIKey(apiKey)})
+	case types.ProviderClaude:
+		providerInfo = append(providerInfo, []string{"API Endpoint", claude.Endpoint(config)})
+		providerInfo = append(providerInfo, []string{"API Key", maskAPIKey(apiKey)})
+	case types.ProviderGemini:
+		providerInfo = append(providerInfo, []string{"API Endpoint", gemini.APIBase(config)})
+		providerInfo = append(providerInfo, []string{"API Key", maskAPIKey(apiKey)})
 	case types.ProviderGrok:
-		providerInfo = append(providerInfo, []string{"API Endpoint", config.GrokAPI})
+		providerInfo = append(providerInfo, []string{"API Endpoint", grok.Endpoint(config)})
 		providerInfo = append(providerInfo, []string{"API Key", maskAPIKey(apiKey)})
 	default:
 		providerInfo = append(providerInfo, []string{"API Key", maskAPIKey(apiKey)})
 	}
+	if opts != nil && opts.Temperature != nil {
+		providerInfo = append(providerInfo, []string{"Temperature", fmt.Sprintf("%g", *opts.Temperature)})
+	}
+	if opts != nil && opts.Seed != nil {
+		providerInfo = append(providerInfo, []string{"Seed", fmt.Sprintf("%d", *opts.Seed)})
+	}
 
 	pterm.DefaultTable.WithHasHeader(false).WithData(providerInfo).Render()
 
 	pterm.Println()
 
 	// Build and display the prompt
-	opts := &types.GenerationOptions{Attempt: 1}
-	prompt := types.BuildCommitPrompt(changes, opts)
+	prompt := dryRunPrompt(changes, opts)
 
 	pterm.DefaultSection.Println("Prompt That Would Be Sent")
 	pterm.Println()
@@ -617,7 +1279,7 @@ func displayDryRunInfo(provider types.LLMProvider, config *types.Config, changes
 	inputTokens := estimateTokens(prompt)
 	// Estimate output tokens (typically 50-200 for commit messages)
 	outputTokens := 100
-	estimatedCost := estimateCost(provider, inputTokens, outputTokens)
+	estimatedCost := estimateCost(provider, llm.ModelFor(provider), inputTokens, outputTokens)
 	minTime, maxTime := estimateProcessingTime(provider)
 
 	statsData := [][]string{
@@ -661,31 +1323,210 @@ func estimateTokens(text string) int {
 	return len(text) / 4
 }
 
-// estimateCost calculates the estimated cost for a given provider and token count
-func estimateCost(provider types.LLMProvider, inputTokens, outputTokens int) float64 {
-	// Pricing per 1M tokens (as of 2024, approximate)
-	switch provider {
-	case types.ProviderOpenAI:
-		// GPT-4o pricing: ~$2.50/M input, ~$10/M output
-		return float64(inputTokens)*2.50/1000000 + float64(outputTokens)*10.00/1000000
-	case types.ProviderClaude:
-		// Claude pricing: ~$3/M input, ~$15/M output
-		return float64(inputTokens)*3.00/1000000 + float64(outputTokens)*15.00/1000000
-	case types.ProviderGemini:
-		// Gemini pricing: ~$0.15/M input, ~$0.60/M output
-		return float64(inputTokens)*0.15/1000000 + float64(outputTokens)*0.60/1000000
-	case types.ProviderGrok:
-		// Grok pricing: ~$5/M input, ~$15/M output
-		return float64(inputTokens)*5.00/1000000 + float64(outputTokens)*15.00/1000000
-	case types.ProviderGroq:
-		// Groq pricing: similar to OpenAI ~$2.50/M input, ~$10/M output
-		return float64(inputTokens)*2.50/1000000 + float64(outputTokens)*10.00/1000000
-	case types.ProviderOllama:
-		// Local model - no cost
-		return 0.0
-	default:
-		return 0.0
+// estimateCost calculates the estimated cost for a given provider, model and token count
+func estimateCost(provider types.LLMProvider, model string, inputTokens, outputTokens int) float64 {
+	return loadPriceTable().Estimate(provider, model, inputTokens, outputTokens)
+}
+
+// loadPriceTable reads the pricing table (including user overrides) once per run.
+func loadPriceTable() pricing.Table {
+	priceTableOnce.Do(func() {
+		table, err := pricing.Load()
+		if err != nil {
+			pterm.Warning.Printf("Failed to load pricing overrides: %v\n", err)
+		}
+		priceTable = table
+	})
+	return priceTable
+}
+
+// loadContentLimits reads the content limits from config.json once per run.
+func loadContentLimits() types.ContentLimits {
+	contentLimitsOnce.Do(func() {
+		limits, err := config.LoadLimits()
+		if err != nil {
+			pterm.Warning.Printf("Failed to load content limits: %v\n", err)
+		}
+		contentLimits = limits
+	})
+	return contentLimits
+}
+
+// loadPromptTemplate reads the prompt template override once per run. An
+// invalid template is reported and the built-in prompt is used instead.
+func loadPromptTemplate() string {
+	promptTemplateOnce.Do(func() {
+		text, err := config.LoadPromptTemplate()
+		if err != nil {
+			pterm.Warning.Printf("Ignoring prompt template: %v\n", err)
+		}
+		promptTemplate = text
+	})
+	return promptTemplate
+}
+
+// providerConfig returns the provider endpoints read from config.json, once
+// per run. Invalid endpoints are reported and the providers' own are used
+// instead.
+func providerConfig() *types.Config {
+	providerConfigOnce.Do(func() {
+		cfg, err := config.LoadProviderConfig()
+		if err != nil {
+			pterm.Warning.Printf("Ignoring provider endpoints: %v\n", err)
+		}
+		providerConfigSettings = *cfg
+	})
+	cfg := providerConfigSettings
+	return &cfg
+}
+
+// loadPostProcessOptions reads the post-processing options once per run.
+func loadPostProcessOptions() postprocess.Options {
+	postProcessOnce.Do(func() {
+		opts, err := config.LoadPostProcess()
+		if err != nil {
+			pterm.Warning.Printf("Failed to load post-processing options: %v\n", err)
+		}
+		postProcessOptions = opts
+	})
+	return postProcessOptions
+}
+
+// loadLintRules reads the lint rules once per run. They also set the limits
+// used to shape generated messages.
+func loadLintRules() lint.Rules {
+	lintRulesOnce.Do(func() {
+		rules, err := config.LoadLint()
+		if err != nil {
+			pterm.Warning.Printf("Using default lint rules: %v\n", err)
+		}
+		lintRules = rules
+	})
+	return lintRules
+}
+
+// loadGeneratedPatterns reads the generated-file patterns from config.json
+// once per run.
+func loadGeneratedPatterns() []string {
+	generatedPatternsOnce.Do(func() {
+		settings, err := config.LoadGenerated()
+		if err != nil {
+			pterm.Warning.Printf("Ignoring generated file patterns: %v\n", err)
+		}
+		generatedPatterns = settings.Patterns
+	})
+	return generatedPatterns
+}
+
+// loadProjectContext returns the project_context settings, warning once
+// when they cannot be read.
+func loadProjectContext() projectctx.Settings {
+	projectContextOnce.Do(func() {
+		settings, err := config.LoadProjectContext()
+		if err != nil {
+			pterm.Warning.Printf("Ignoring project context files: %v\n", err)
+		}
+		projectContextSettings = settings
+	})
+	return projectContextSettings
+}
+
+// loadCacheSettings returns the cache settings, warning once when they
+// cannot be read.
+func loadCacheSettings() cache.Settings {
+	cacheSettingsOnce.Do(func() {
+		settings, err := config.LoadCache()
+		if err != nil {
+			pterm.Warning.Printf("Ignoring cache settings: %v\n", err)
+		}
+		cacheSettings = settings
+	})
+	return cacheSettings
+}
+
+// repoCacheScope returns the cache scope of the repository at config, or ""
+// when the "cache" section of config.json shares messages across
+// repositories.
+func repoCacheScope(config *types.RepoConfig) string {
+	if loadCacheSettings().ShareAcrossRepos {
+		return ""
+	}
+	remote, _ := git.RemoteURL(config, "origin")
+	root, err := git.RepoRoot(config.Path)
+	if err != nil {
+		root = config.Path
+	}
+	return cache.RepoScope(remote, root)
+}
+
+// formatMessage lays out an accepted message for committing, wrapping the
+// body at the max_body_line_length lint rule.
+func formatMessage(message string) string {
+	return msgfmt.Format(message, loadLintRules().MaxBodyLineLength)
+}
+
+// configureHTTPClients applies the HTTP client settings from config.json and
+// the environment before any provider client is created.
+func configureHTTPClients() {
+	settings, err := config.LoadHTTPSettings()
+	if err != nil {
+		pterm.Warning.Printf("Ignoring HTTP settings: %v\n", err)
+	}
+	wrap, err := vcr.FromEnv()
+	if err != nil {
+		pterm.Warning.Printf("Ignoring request recording: %v\n", err)
+	}
+	settings.Wrap = wrap
+	httpClient.Configure(settings)
+}
+
+// configureOllama applies the "ollama" generation options from config.json.
+func configureOllama() {
+	opts, err := config.LoadOllama()
+	if err != nil {
+		pterm.Warning.Printf("Ignoring Ollama settings: %v\n", err)
+	}
+	ollama.Configure(opts)
+}
+
+// configureHuggingFace applies the "huggingface" section of config.json to
+// the Hugging Face provider.
+func configureHuggingFace() {
+	opts, err := config.LoadHuggingFace()
+	if err != nil {
+		pterm.Warning.Printf("Ignoring Hugging Face settings: %v\n", err)
+	}
+	huggingface.Configure(opts)
+}
+
+// configureVertex applies the "vertex" section of config.json to the
+// Vertex AI provider.
+func configureVertex() {
+	opts, err := config.LoadVertex()
+	if err != nil {
+		pterm.Warning.Printf("Ignoring Vertex AI settings: %v\n", err)
+	}
+	vertex.Configure(opts)
+}
+
+// configureRedaction applies the "redaction" section of config.json to the
+// scrubber before any changes are collected.
+func configureRedaction() {
+	settings, err := config.LoadRedaction()
+	if err != nil {
+		pterm.Warning.Printf("Ignoring redaction settings: %v\n", err)
+	}
+	scrubber.Configure(settings)
+}
+
+// configureProviderModels applies the per-provider models saved by
+// `commit llm setup`. A missing or unreadable config leaves the defaults.
+func configureProviderModels() {
+	cfg, err := store.ListSavedModels()
+	if err != nil {
+		return
 	}
+	llm.ConfigureModels(cfg.Models)
 }
 
 // estimateProcessingTime returns estimated processing time in seconds for a provider
@@ -694,7 +1535,8 @@ func estimateProcessingTime(provider types.LLMProvider) (minTime, maxTime int) {
 	case types.ProviderOllama:
 		// Local models take longer
 		return 10, 30
-	case types.ProviderOpenAI, types.ProviderClaude, types.ProviderGemini, types.ProviderGrok, types.ProviderGroq:
+	case types.ProviderOpenAI, types.ProviderClaude, types.ProviderGemini, types.ProviderGrok, types.ProviderGroq, types.ProviderHuggingFace, types.ProviderVertex, types.ProviderCohere,
+		types.ProviderTogether, types.ProviderFireworks:
 		// Cloud providers are faster
 		return 5, 15
 	default:
@@ -702,16 +1544,24 @@ func estimateProcessingTime(provider types.LLMProvider) (minTime, maxTime int) {
 	}
 }
 
-// validateCommitMessageLength checks if the commit message exceeds recommended length limits
-// and displays appropriate warnings
-func validateCommitMessageLength(message string) {
+// validateCommitMessageLength displays the warnings returned for message,
+// such as commitMessageLengthWarnings or the stricter onelineWarnings.
+func validateCommitMessageLength(message string, warnings func(string) []string) {
+	for _, warning := range warnings(message) {
+		pterm.Warning.Println(warning)
+	}
+}
+
+// commitMessageLengthWarnings returns a warning for each recommended length
+// limit the commit message subject line exceeds.
+func commitMessageLengthWarnings(message string) []string {
 	if message == "" {
-		return
+		return nil
 	}
 
 	lines := strings.Split(message, "\n")
 	if len(lines) == 0 {
-		return
+		return nil
 	}
 
 	subjectLine := strings.TrimSpace(lines[0])
@@ -722,9 +1572,12 @@ func validateCommitMessageLength(message string) {
 	const maxAllowedLength = 72
 
 	if subjectLength > maxAllowedLength {
-		pterm.Warning.Printf("Commit message subject line is %d characters (exceeds %d character limit)\n", subjectLength, maxAllowedLength)
-		pterm.Info.Println("Consider shortening the subject line for better readability")
+		return []string{
+			fmt.Sprintf("Commit message subject line is %d characters (exceeds %d character limit)", subjectLength, maxAllowedLength),
+			"Consider shortening the subject line for better readability",
+		}
 	} else if subjectLength > maxRecommendedLength {
-		pterm.Warning.Printf("Commit message subject line is %d characters (recommended limit is %d)\n", subjectLength, maxRecommendedLength)
+		return []string{fmt.Sprintf("Commit message subject line is %d characters (recommended limit is %d)", subjectLength, maxRecommendedLength)}
 	}
+	return nil
 }
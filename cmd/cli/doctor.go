@@ -0,0 +1,242 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dfanso/commit-msg/cmd/cli/store"
+	"github.com/dfanso/commit-msg/internal/buildinfo"
+	"github.com/dfanso/commit-msg/internal/cache"
+	"github.com/dfanso/commit-msg/internal/config"
+	"github.com/dfanso/commit-msg/internal/doctor"
+	"github.com/dfanso/commit-msg/internal/history"
+	"github.com/dfanso/commit-msg/internal/llm"
+	"github.com/dfanso/commit-msg/internal/platform"
+	"github.com/dfanso/commit-msg/internal/sqlstore"
+	StoreUtils "github.com/dfanso/commit-msg/utils"
+	"github.com/pterm/pterm"
+)
+
+// RunDoctor checks the environment commit-msg runs in and prints a report
+// to paste into bug reports. The saved providers are contacted unless
+// offline is set. With asJSON the report is printed as JSON. Failed checks
+// exit with ExitError.
+func RunDoctor(Store *store.StoreMethods, offline, asJSON bool) error {
+	report := diagnose(Store, offline, !asJSON)
+
+	if asJSON {
+		raw, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(raw))
+	} else if err := renderDoctorReport(report); err != nil {
+		return err
+	}
+
+	if failed := report.Count(doctor.Fail); failed > 0 {
+		exitf(ExitError, "%d of %d checks failed\n", failed, len(report.Checks))
+	}
+	return nil
+}
+
+// diagnose runs every check in the order they are shown. spinner shows
+// progress while providers are contacted.
+func diagnose(Store *store.StoreMethods, offline, spinner bool) *doctor.Report {
+	report := &doctor.Report{}
+	info := buildinfo.Get()
+
+	report.Add("Version", doctor.OK, info.String())
+	runtimeDetail := fmt.Sprintf("%s %s/%s", info.GoVersion, info.OS, info.Arch)
+	if platform.IsWSL() {
+		runtimeDetail += " (WSL)"
+	}
+	report.Add("Go runtime", doctor.OK, runtimeDetail)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	gitVersion, err := doctor.GitVersion(ctx)
+	cancel()
+	if err != nil {
+		report.Add("Git", doctor.Fail, err.Error())
+	} else {
+		report.Add("Git", doctor.OK, gitVersion)
+	}
+
+	profile, err := StoreUtils.Profile()
+	switch {
+	case err != nil:
+		report.Add("Profile", doctor.Fail, err.Error())
+	case profile == "":
+		report.Add("Profile", doctor.OK, "default")
+	default:
+		report.Add("Profile", doctor.OK, profile)
+	}
+
+	report.Checks = append(report.Checks, credentialsCheck())
+
+	configPath, err := StoreUtils.GetConfigPath()
+	if err != nil {
+		report.Add("Config", doctor.Fail, err.Error())
+	} else {
+		check := doctor.JSONFile("Config", configPath)
+		if check.Status == doctor.OK {
+			if err := config.ValidateFile(configPath); err != nil {
+				check.Status = doctor.Fail
+				check.Detail = strings.ReplaceAll(err.Error(), "\n", "; ")
+			}
+		}
+		report.Checks = append(report.Checks, check)
+	}
+
+	report.Checks = append(report.Checks, storageChecks(Store)...)
+
+	if tool, ok := platform.ClipboardTool(); ok {
+		report.Add("Clipboard", doctor.OK, tool)
+	} else {
+		report.Add("Clipboard", doctor.Warn, "no clipboard tool found; install wl-clipboard, xclip, or xsel")
+	}
+
+	if offline {
+		report.Add("Providers", doctor.OK, "skipped (--offline)")
+		return report
+	}
+	report.Checks = append(report.Checks, providerChecks(Store, spinner)...)
+	return report
+}
+
+// credentialsCheck reports where provider keys are kept and whether an OS
+// keyring is there to keep them.
+func credentialsCheck() doctor.Check {
+	backend, err := store.ConfiguredBackend()
+	if err != nil {
+		return doctor.Check{Name: "Credentials", Status: doctor.Fail, Detail: err.Error()}
+	}
+	if backend == store.BackendFile {
+		return doctor.Check{Name: "Credentials", Status: doctor.OK, Detail: store.BackendDescription(backend)}
+	}
+
+	keyrings := store.Keyrings()
+	if len(keyrings) == 0 {
+		return doctor.Check{Name: "Credentials", Status: doctor.Warn, Detail: "no OS keyring found; run: commit llm migrate --to file"}
+	}
+	return doctor.Check{Name: "Credentials", Status: doctor.OK, Detail: fmt.Sprintf("%s (%s)", store.BackendDescription(backend), strings.Join(keyrings, ", "))}
+}
+
+// storageChecks reports where the cache and history are kept and whether
+// they can be read.
+func storageChecks(Store *store.StoreMethods) []doctor.Check {
+	settings, err := config.LoadStorage()
+	if err != nil {
+		return []doctor.Check{{Name: "Storage", Status: doctor.Fail, Detail: err.Error()}}
+	}
+
+	if settings.UseSQLite() {
+		path, _ := sqlstore.DefaultPath()
+		switch {
+		case !sqlstore.Available():
+			return []doctor.Check{{Name: "Storage", Status: doctor.Warn, Detail: "SQLite selected but this build lacks the driver; using the JSON files"}}
+		case Store == nil || Store.Database() == nil:
+			return []doctor.Check{{Name: "Storage", Status: doctor.Fail, Detail: path + ": could not be opened; using the JSON files"}}
+		}
+		if err := Store.Database().Ping(); err != nil {
+			return []doctor.Check{{Name: "Storage", Status: doctor.Fail, Detail: fmt.Sprintf("%s: %v", path, err)}}
+		}
+		return []doctor.Check{{Name: "Storage", Status: doctor.OK, Detail: "SQLite " + path}}
+	}
+
+	checks := []doctor.Check{{Name: "Storage", Status: doctor.OK, Detail: "JSON files"}}
+	if path, err := cache.DefaultPath(); err != nil {
+		checks = append(checks, doctor.Check{Name: "Cache", Status: doctor.Fail, Detail: err.Error()})
+	} else {
+		checks = append(checks, doctor.JSONFile("Cache", path))
+	}
+	if path, err := history.DefaultPath(); err != nil {
+		checks = append(checks, doctor.Check{Name: "History", Status: doctor.Fail, Detail: err.Error()})
+	} else {
+		checks = append(checks, doctor.JSONFile("History", path))
+	}
+	return checks
+}
+
+// providerChecks checks that each saved provider is reachable and accepts
+// its key, like commit llm status.
+func providerChecks(Store *store.StoreMethods, spinner bool) []doctor.Check {
+	cfg, err := store.ListSavedModels()
+	if err != nil {
+		return []doctor.Check{{Name: "Providers", Status: doctor.Warn, Detail: err.Error()}}
+	}
+	if len(cfg.LLMProviders) == 0 {
+		return []doctor.Check{{Name: "Providers", Status: doctor.Warn, Detail: "none configured; run: commit llm setup"}}
+	}
+
+	checker := llm.HealthChecker{Config: providerConfig()}
+	var checks []doctor.Check
+	for _, provider := range cfg.LLMProviders {
+		name := "Provider " + provider.String()
+		if provider == cfg.Default {
+			name += " (default)"
+		}
+
+		saved, err := Store.LLMKey(provider)
+		if err != nil {
+			checks = append(checks, doctor.Check{Name: name, Status: doctor.Fail, Detail: err.Error()})
+			continue
+		}
+
+		var sp *pterm.SpinnerPrinter
+		if spinner {
+			sp, _ = pterm.DefaultSpinner.WithRemoveWhenDone().Start("Checking " + provider.String() + "...")
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), healthCheckTimeout)
+		health := checker.Check(ctx, provider, saved.APIKey)
+		cancel()
+		if sp != nil {
+			_ = sp.Stop()
+		}
+
+		if !health.OK {
+			checks = append(checks, doctor.Check{Name: name, Status: doctor.Fail, Detail: health.Problem})
+			continue
+		}
+		checks = append(checks, doctor.Check{Name: name, Status: doctor.OK, Detail: fmt.Sprintf("%s, %s", llm.ModelFor(provider), health.Latency.Round(time.Millisecond))})
+	}
+	return checks
+}
+
+func renderDoctorReport(report *doctor.Report) error {
+	pterm.DefaultHeader.WithFullWidth().
+		WithBackgroundStyle(pterm.NewStyle(pterm.BgBlue)).
+		WithTextStyle(pterm.NewStyle(pterm.FgWhite, pterm.Bold)).
+		Println("commit-msg Doctor")
+
+	pterm.Println()
+
+	tableData := [][]string{{"Check", "Status", "Details"}}
+	for _, c := range report.Checks {
+		status := pterm.Green(string(c.Status))
+		switch c.Status {
+		case doctor.Warn:
+			status = pterm.Yellow(string(c.Status))
+		case doctor.Fail:
+			status = pterm.Red(string(c.Status))
+		}
+		tableData = append(tableData, []string{c.Name, status, c.Detail})
+	}
+	if err := pterm.DefaultTable.WithHasHeader().WithData(tableData).Render(); err != nil {
+		return err
+	}
+
+	pterm.Println()
+	if report.Count(doctor.Fail) == 0 {
+		if warnings := report.Count(doctor.Warn); warnings > 0 {
+			pterm.Warning.Printf("All checks passed with %d warning(s).\n", warnings)
+		} else {
+			pterm.Success.Println("All checks passed.")
+		}
+	}
+	pterm.Info.Println("Include this report, or the output of commit doctor --json, when filing a bug.")
+	return nil
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/dfanso/commit-msg/internal/logging"
+	"github.com/pterm/pterm"
+)
+
+// Exit codes returned by commit so wrappers and hooks can branch on the result.
+const (
+	ExitSuccess       = 0
+	ExitError         = 1
+	ExitNoChanges     = 2
+	ExitProviderError = 3
+	ExitNotRepository = 4
+	ExitCancelled     = 5
+	ExitTimeout       = 6
+)
+
+// quietMode suppresses all decorated output; only the final message and
+// plain error lines on stderr are printed.
+var quietMode bool
+
+// setQuietMode toggles quiet output for the current run.
+func setQuietMode(quiet bool) {
+	quietMode = quiet
+	if quiet {
+		pterm.DisableOutput()
+	} else {
+		pterm.EnableOutput()
+	}
+}
+
+// exitf reports an error and terminates the process with the given exit code.
+// In quiet mode the message is written to stderr without decoration.
+func exitf(code int, format string, args ...any) {
+	if quietMode {
+		fmt.Fprintf(os.Stderr, format, args...)
+	} else {
+		pterm.Error.Printf(format, args...)
+	}
+	exit(code)
+}
+
+// exit saves pending cache statistics, closes the debug log, and terminates
+// the process with code. Use it instead of os.Exit so hits and misses counted
+// this run are kept and the log file is complete: PersistentPostRun does not
+// run when a command exits early.
+func exit(code int) {
+	flushCache()
+	logging.Debug("command exited", "code", code)
+	logging.Close()
+	os.Exit(code)
+}
+
+// flushCache writes cache statistics recorded this run, warning on failure.
+func flushCache() {
+	if Store == nil {
+		return
+	}
+	if err := Store.FlushCache(); err != nil && !quietMode {
+		pterm.Warning.Printf("Failed to save cache statistics: %v\n", err)
+	}
+}
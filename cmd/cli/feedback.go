@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/dfanso/commit-msg/cmd/cli/store"
+	"github.com/dfanso/commit-msg/internal/config"
+	"github.com/dfanso/commit-msg/internal/feedback"
+	"github.com/dfanso/commit-msg/internal/llm"
+	"github.com/dfanso/commit-msg/internal/logging"
+	"github.com/dfanso/commit-msg/pkg/types"
+	"github.com/pterm/pterm"
+)
+
+// recordGeneration remembers the latest generated message so that
+// commit feedback can rate it. Failures are logged and otherwise ignored.
+func recordGeneration(providerType types.LLMProvider, changes string, opts *types.GenerationOptions, message string) {
+	path, err := feedback.DefaultPath()
+	if err != nil {
+		logging.Debug("feedback unavailable", "error", err)
+		return
+	}
+
+	err = feedback.NewLog(path).RecordGeneration(feedback.Generation{
+		Provider:   providerType,
+		Model:      llm.ModelFor(providerType),
+		PromptHash: feedback.PromptHash(types.BuildCommitPrompt(changes, opts)),
+		Message:    strings.TrimSpace(message),
+	})
+	if err != nil {
+		logging.Debug("failed to record generation", "error", err)
+	}
+}
+
+// RateLastMessage stores the user's rating of the most recently generated
+// commit message.
+func RateLastMessage(value string) error {
+	rating, ok := feedback.ParseRating(strings.ToLower(value))
+	if !ok {
+		return fmt.Errorf("invalid rating %q: use good or bad", value)
+	}
+
+	path, err := feedback.DefaultPath()
+	if err != nil {
+		return err
+	}
+
+	generation, err := feedback.NewLog(path).RateLast(rating)
+	if errors.Is(err, feedback.ErrNoGeneration) {
+		return fmt.Errorf("%w; run: commit .", err)
+	}
+	if err != nil {
+		return err
+	}
+
+	subject, _, _ := strings.Cut(generation.Message, "\n")
+	pterm.Success.Printf("Rated %s message from %s (%s): %s\n", rating, generation.Provider, generation.Model, subject)
+	return nil
+}
+
+// ShowUsage displays how often each provider was used and how its messages
+// were rated.
+func ShowUsage() error {
+	path, err := feedback.DefaultPath()
+	if err != nil {
+		return err
+	}
+
+	qualities, err := feedback.NewLog(path).Summary()
+	if err != nil {
+		return err
+	}
+
+	pterm.DefaultHeader.WithFullWidth().
+		WithBackgroundStyle(pterm.NewStyle(pterm.BgBlue)).
+		WithTextStyle(pterm.NewStyle(pterm.FgWhite, pterm.Bold)).
+		Println("Provider Usage and Quality")
+
+	pterm.Println()
+
+	if len(qualities) == 0 {
+		pterm.Info.Println("No messages generated yet. Rate messages with: commit feedback good|bad")
+		return nil
+	}
+
+	tableData := [][]string{{"Provider", "Generated", "Good", "Bad", "Quality"}}
+	for _, q := range qualities {
+		quality := "-"
+		if q.Good+q.Bad > 0 {
+			quality = fmt.Sprintf("%.0f%%", q.Score()*100)
+		}
+		tableData = append(tableData, []string{
+			q.Provider.String(),
+			fmt.Sprintf("%d", q.Generations),
+			fmt.Sprintf("%d", q.Good),
+			fmt.Sprintf("%d", q.Bad),
+			quality,
+		})
+	}
+	if err := pterm.DefaultTable.WithHasHeader().WithData(tableData).Render(); err != nil {
+		return err
+	}
+
+	cfg, err := store.ListSavedModels()
+	if err != nil || cfg.Default == "" {
+		return nil
+	}
+	if fallbacks := fallbackProviders(cfg.Default); len(fallbacks) > 0 {
+		names := make([]string, len(fallbacks))
+		for i, p := range fallbacks {
+			names[i] = p.String()
+		}
+		pterm.Println()
+		pterm.Info.Printf("When %s fails, these are tried in order: %s\n", cfg.Default, strings.Join(names, " > "))
+	} else if len(cfg.LLMProviders) > 1 {
+		pterm.Println()
+		pterm.Info.Printf("When %s fails, no other provider is tried. List the ones that may be in the \"fallback\" key of config.json.\n", cfg.Default)
+	}
+	return nil
+}
+
+// fallbackProviders returns the providers listed in the "fallback" key of
+// config.json that are saved and differ from failed, best rated first, in
+// the order they are tried when failed cannot generate a message.
+func fallbackProviders(failed types.LLMProvider) []types.LLMProvider {
+	listed, err := config.LoadFallback()
+	if err != nil {
+		logging.Debug("fallback providers unavailable", "error", err)
+		return nil
+	}
+	cfg, err := store.ListSavedModels()
+	if err != nil {
+		return nil
+	}
+	saved := make(map[types.LLMProvider]bool, len(cfg.LLMProviders))
+	for _, p := range cfg.LLMProviders {
+		saved[p] = true
+	}
+	var providers []types.LLMProvider
+	for _, p := range listed {
+		if p != failed && saved[p] {
+			providers = append(providers, p)
+		}
+	}
+	if len(providers) == 0 {
+		return nil
+	}
+
+	var qualities []feedback.ProviderQuality
+	if path, err := feedback.DefaultPath(); err == nil {
+		if qualities, err = feedback.NewLog(path).Summary(); err != nil {
+			logging.Debug("feedback unavailable", "error", err)
+		}
+	}
+	return feedback.Rank(providers, qualities)
+}
@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/dfanso/commit-msg/cmd/cli/store"
+	"github.com/dfanso/commit-msg/internal/display"
+	"github.com/dfanso/commit-msg/internal/git"
+	"github.com/dfanso/commit-msg/internal/llm"
+	"github.com/dfanso/commit-msg/internal/platform"
+	"github.com/dfanso/commit-msg/internal/vcs"
+	"github.com/dfanso/commit-msg/pkg/types"
+	"github.com/pterm/pterm"
+)
+
+// FixupOptions controls commit fixup.
+type FixupOptions struct {
+	// Target is the commit to fix: a revision, or text searched for in the
+	// messages of the commits reachable from HEAD.
+	Target string
+	// Squash writes a squash! commit, whose body is kept when squashed,
+	// instead of a fixup! commit.
+	Squash bool
+	// Commit commits the staged changes with the message.
+	Commit bool
+	// RepoPath is the repository to work in; empty means the current
+	// directory.
+	RepoPath string
+	// DryRun displays the prompt without making an API call.
+	DryRun bool
+	// Quiet prints only the message.
+	Quiet bool
+}
+
+// CreateFixupMsg writes a fixup! or squash! message for the staged changes
+// that git rebase --autosquash folds into the target commit. The subject
+// names the target as git commit --fixup does, and the body generated by
+// the default provider explains what the fix changes. The process exits
+// with one of the documented Exit* codes on failure.
+func CreateFixupMsg(Store *store.StoreMethods, opts FixupOptions) {
+	setQuietMode(opts.Quiet)
+
+	dir, err := resolveRepoPath(opts.RepoPath)
+	if err != nil {
+		exitf(ExitError, "%v\n", err)
+	}
+	if !git.IsRepository(dir) {
+		exitf(ExitNotRepository, "Not a Git repository: %s\n", dir)
+	}
+
+	repoConfig := &types.RepoConfig{Path: dir, Limits: loadContentLimits()}
+	hash, err := git.FindCommit(repoConfig, opts.Target)
+	if err != nil {
+		exitf(ExitError, "%v\n", err)
+	}
+	shortHash := hash[:min(len(hash), 7)]
+	targetMessage, err := git.CommitMessage(repoConfig, hash)
+	if err != nil {
+		exitf(ExitError, "Failed to read commit message: %v\n", err)
+	}
+	if !git.IsAncestor(repoConfig, hash) {
+		pterm.Warning.Printf("%s is not an ancestor of HEAD; git rebase --autosquash will not find it.\n", shortHash)
+	}
+
+	prefix, kind := git.FixupPrefix, "fixup"
+	if opts.Squash {
+		prefix, kind = git.SquashPrefix, "squash"
+	}
+	subject := git.AutosquashSubject(prefix, targetMessage)
+	pterm.Info.Printf("Target: %s %s\n", shortHash, strings.TrimPrefix(subject, prefix))
+
+	set, err := git.CollectChanges(repoConfig)
+	if err != nil {
+		exitf(ExitError, "Failed to get Git changes: %v\n", err)
+	}
+	if len(set.Staged) == 0 {
+		exitf(ExitNoChanges, "No staged changes; stage the fix for %s first.\n", shortHash)
+	}
+	changes := truncateLargeDiff(condenseChanges(dir, set.StagedOnly().Prompt()))
+
+	prompt := newPromptContext(dir, nil, nil)
+	genOpts := withInstruction(prompt.apply(withAttempt(nil, 1)), fixupInstruction(kind, shortHash, targetMessage))
+
+	useLLM, err := Store.DefaultLLMKey()
+	if err != nil {
+		exitf(ExitError, "No LLM configured. Run: commit llm setup\n")
+	}
+	config := providerConfig()
+
+	if opts.DryRun {
+		if quietMode {
+			fmt.Println(dryRunPrompt(changes, genOpts))
+			return
+		}
+		displayDryRunInfo(useLLM.LLM, config, changes, useLLM.APIKey, genOpts)
+		return
+	}
+
+	provider, err := llm.NewProvider(useLLM.LLM, llm.ProviderOptions{
+		Credential: useLLM.APIKey,
+		Config:     config,
+	})
+	if err != nil {
+		displayProviderError(useLLM.LLM, err)
+		exit(ExitProviderError)
+	}
+
+	spinner, err := pterm.DefaultSpinner.Start(fmt.Sprintf("Writing the %s message for %s with %s...", kind, shortHash, useLLM.LLM))
+	if err != nil {
+		exitf(ExitError, "Failed to start spinner: %v\n", err)
+	}
+	generate := withBlocklist(func(genOpts *types.GenerationOptions) (*types.GenerationResult, error) {
+		return generateMessageWithCache(context.Background(), provider, Store, useLLM.LLM, changes, genOpts)
+	})
+	generated, err := generate(genOpts)
+	if err != nil {
+		spinner.Fail("Failed to generate the " + kind + " message")
+		displayProviderError(useLLM.LLM, err)
+		exit(ExitProviderError)
+	}
+	spinner.Success("Message generated (" + display.GenerationSummary(generated) + ")")
+
+	message := subject
+	if body := formatMessage(correctMessage(dir, generated.Message)); body != "" {
+		message += "\n\n" + body
+	}
+
+	if quietMode {
+		fmt.Println(message)
+	} else {
+		pterm.Println()
+		display.ShowCommitMessage(message)
+	}
+
+	if !opts.Commit {
+		if quietMode {
+			return
+		}
+		if err := platform.CopyToClipboard(message); err != nil {
+			pterm.Warning.Printf("Could not copy to clipboard: %v\n", err)
+		} else {
+			pterm.Success.Println("Commit message copied to clipboard!")
+		}
+		pterm.Info.Println("Commit it with --commit, then fold it in with: git rebase -i --autosquash " + shortHash + "~1")
+		return
+	}
+
+	output, err := vcs.NewGit(dir).Commit(message)
+	if err != nil {
+		exitf(ExitError, "Failed to commit: %v\n%s\n", err, output)
+	}
+	pterm.Success.Printf("Committed %s for %s\n", kind, shortHash)
+}
+
+// fixupInstruction asks for the body of a fixup! or squash! commit only,
+// since the subject is fixed by the target.
+func fixupInstruction(kind, shortHash, targetMessage string) string {
+	subject, _, _ := strings.Cut(strings.TrimSpace(targetMessage), "\n")
+	instruction := fmt.Sprintf("These changes are a %s for the earlier commit %s %q. Do not write a subject line: "+
+		"reply with only a short body of one to three lines explaining what the changes fix or add to that commit.", kind, shortHash, subject)
+	if kind == "squash" {
+		instruction += " The body will be kept in the squashed commit, so describe the change for a future reader."
+	}
+	return instruction
+}
@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/dfanso/commit-msg/internal/config"
+	"github.com/dfanso/commit-msg/internal/display"
+	"github.com/dfanso/commit-msg/internal/history"
+	"github.com/dfanso/commit-msg/internal/logging"
+	"github.com/dfanso/commit-msg/internal/platform"
+	"github.com/dfanso/commit-msg/internal/tui"
+	"github.com/dfanso/commit-msg/pkg/types"
+	"github.com/pterm/pterm"
+)
+
+// HistoryOptions controls commit history.
+type HistoryOptions struct {
+	// Search keeps only messages containing this text.
+	Search string
+	// Limit is the most messages listed; zero lists all of them.
+	Limit int
+	// Copy copies the message with this number in the listing to the
+	// clipboard instead of listing.
+	Copy int
+	// Rejected also lists messages that were not accepted.
+	Rejected bool
+	// Clear removes every recorded message.
+	Clear bool
+}
+
+// historyStore returns the message history store and its settings, or nil
+// when its location cannot be determined.
+func historyStore() (*history.Store, history.Settings) {
+	settings, err := config.LoadHistory()
+	if err != nil {
+		logging.Debug("failed to load history settings", "error", err)
+	}
+	if Store != nil && Store.Database() != nil {
+		return history.NewSQLiteStore(Store.Database(), settings.MaxEntries), settings
+	}
+	path, err := history.DefaultPath()
+	if err != nil {
+		logging.Debug("history unavailable", "error", err)
+		return nil, settings
+	}
+	return history.NewStore(path, settings.MaxEntries), settings
+}
+
+// recordHistory adds the outcome of a review session to the message
+// history. Rejected candidates are kept only when the "history" section of
+// config.json asks for them. Failures are logged and otherwise ignored.
+func recordHistory(dir string, providerType types.LLMProvider, result tui.Result) {
+	store, settings := historyStore()
+	if store == nil || settings.Disabled {
+		return
+	}
+
+	repo := exampleRepoKey(dir)
+	var entries []history.Entry
+	if settings.RecordRejected {
+		for _, message := range result.Rejected {
+			entries = append(entries, history.Entry{Message: message, Repo: repo, Provider: providerType.String(), Status: history.Rejected})
+		}
+	}
+	if result.Accepted {
+		entries = append(entries, history.Entry{Message: result.Message, Repo: repo, Provider: providerType.String(), Status: history.Accepted})
+	}
+	if err := store.Add(entries...); err != nil {
+		logging.Debug("failed to record history", "error", err)
+	}
+}
+
+// ShowHistory lists previously generated messages, newest first, or copies
+// one of them to the clipboard again.
+func ShowHistory(opts HistoryOptions) error {
+	store, _ := historyStore()
+	if store == nil {
+		return fmt.Errorf("message history is unavailable")
+	}
+
+	if opts.Clear {
+		if err := store.Clear(); err != nil {
+			return err
+		}
+		pterm.Success.Println("Message history cleared")
+		return nil
+	}
+
+	entries, err := store.Search(opts.Search, opts.Rejected)
+	if err != nil {
+		return err
+	}
+
+	if opts.Copy > 0 {
+		if opts.Copy > len(entries) {
+			return fmt.Errorf("no message #%d in the history; run commit history to list them", opts.Copy)
+		}
+		message := entries[opts.Copy-1].Message
+		if quietMode {
+			fmt.Println(message)
+		} else {
+			display.ShowCommitMessage(message)
+		}
+		if err := platform.CopyToClipboard(message); err != nil {
+			pterm.Warning.Printf("Could not copy to clipboard: %v\n", err)
+		} else if !quietMode {
+			pterm.Success.Println("Commit message copied to clipboard!")
+		}
+		return nil
+	}
+
+	if opts.Limit > 0 && len(entries) > opts.Limit {
+		entries = entries[:opts.Limit]
+	}
+	if len(entries) == 0 {
+		if opts.Search != "" {
+			pterm.Info.Printf("No messages in the history match %q\n", opts.Search)
+		} else {
+			pterm.Info.Println("No messages in the history yet. Accepted messages are recorded by: commit .")
+		}
+		return nil
+	}
+
+	tableData := [][]string{{"#", "Date", "Repository", "Provider", "Message"}}
+	for i, entry := range entries {
+		subject, _, _ := strings.Cut(entry.Message, "\n")
+		if entry.Status == history.Rejected {
+			subject += " (rejected)"
+		}
+		tableData = append(tableData, []string{
+			fmt.Sprintf("%d", i+1),
+			entry.CreatedAt.Local().Format("2006-01-02 15:04"),
+			filepath.Base(entry.Repo),
+			entry.Provider,
+			subject,
+		})
+	}
+	if err := pterm.DefaultTable.WithHasHeader().WithData(tableData).Render(); err != nil {
+		return err
+	}
+
+	pterm.Println()
+	// Numbers refer to this listing, so the hint repeats its filters.
+	command := "commit history"
+	if opts.Search != "" {
+		command += fmt.Sprintf(" --search %q", opts.Search)
+	}
+	if opts.Rejected {
+		command += " --rejected"
+	}
+	hint := fmt.Sprintf("Copy a message again with: %s --copy <#>", command)
+	pterm.Info.Println(hint)
+	return nil
+}
@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/dfanso/commit-msg/cmd/cli/store"
+	"github.com/dfanso/commit-msg/internal/git"
+	httpClient "github.com/dfanso/commit-msg/internal/http"
+	"github.com/dfanso/commit-msg/internal/issues"
+	"github.com/dfanso/commit-msg/internal/logging"
+	"github.com/dfanso/commit-msg/internal/scrubber"
+	"github.com/dfanso/commit-msg/pkg/types"
+	"github.com/manifoldco/promptui"
+	"github.com/pterm/pterm"
+)
+
+// SetupIssueTracker prompts for an issue tracker and stores its API token
+// (and, for Jira, the site URL and account email) in the keyring.
+func SetupIssueTracker(Store *store.StoreMethods) error {
+	tracker, err := selectTracker("Select issue tracker")
+	if err != nil {
+		return err
+	}
+
+	var cred issues.Credential
+
+	if tracker == issues.Jira {
+		urlPrompt := promptui.Prompt{
+			Label: "Enter Jira URL (e.g. https://example.atlassian.net)",
+		}
+		cred.BaseURL, err = urlPrompt.Run()
+		if err != nil {
+			return fmt.Errorf("failed to read Jira URL: %w", err)
+		}
+
+		emailPrompt := promptui.Prompt{
+			Label: "Enter account email (leave empty for a personal access token)",
+		}
+		cred.Email, err = emailPrompt.Run()
+		if err != nil {
+			return fmt.Errorf("failed to read email: %w", err)
+		}
+	}
+
+	tokenPrompt := promptui.Prompt{
+		Label: "Enter API Token",
+		Mask:  '*',
+	}
+	cred.Token, err = tokenPrompt.Run()
+	if err != nil {
+		return fmt.Errorf("failed to read API Token: %w", err)
+	}
+
+	cred.BaseURL = strings.TrimSpace(cred.BaseURL)
+	cred.Email = strings.TrimSpace(cred.Email)
+	cred.Token = strings.TrimSpace(cred.Token)
+
+	if err := Store.SaveTrackerCredential(tracker, cred); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s credentials saved\n", tracker)
+	return nil
+}
+
+// RemoveIssueTracker deletes the stored credentials for a tracker.
+func RemoveIssueTracker(Store *store.StoreMethods) error {
+	tracker, err := selectTracker("Select issue tracker to remove")
+	if err != nil {
+		return err
+	}
+
+	if err := Store.DeleteTrackerCredential(tracker); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s credentials removed\n", tracker)
+	return nil
+}
+
+func selectTracker(label string) (issues.Tracker, error) {
+	trackers := issues.Trackers()
+	items := make([]string, len(trackers))
+	for i, tracker := range trackers {
+		items[i] = string(tracker)
+	}
+
+	prompt := promptui.Select{
+		Label: label,
+		Items: items,
+	}
+	index, _, err := prompt.Run()
+	if err != nil {
+		return "", fmt.Errorf("prompt failed")
+	}
+	return trackers[index], nil
+}
+
+// issueContextForPrompt looks for a ticket reference in the current branch
+// and returns the issue title and description for the prompt, scrubbed like
+// the diff since issues often quote logs and tokens. Lookup failures are
+// reported as warnings and never stop generation.
+func issueContextForPrompt(Store *store.StoreMethods, dir string) string {
+	config := &types.RepoConfig{Path: dir}
+
+	branch, err := git.CurrentBranch(config)
+	if err != nil {
+		logging.Debug("issue lookup skipped", "error", err)
+		return ""
+	}
+
+	ref, ok := issues.DetectRef(branch)
+	if !ok {
+		pterm.Info.Printf("No issue reference found in branch %q.\n", branch)
+		return ""
+	}
+
+	var remote issues.Remote
+	if remoteURL, err := git.RemoteURL(config, "origin"); err == nil {
+		remote, err = issues.ParseRemote(remoteURL)
+		if err != nil {
+			logging.Debug("failed to parse remote", "error", err)
+		}
+	}
+
+	tracker := issues.TrackerFor(ref, remote)
+	if tracker != issues.Jira && remote.Path == "" {
+		pterm.Warning.Println("Cannot look up the issue: the repository has no usable origin remote.")
+		return ""
+	}
+
+	cred, found, err := Store.TrackerCredential(tracker)
+	if err != nil {
+		pterm.Warning.Printf("Could not read %s credentials: %v\n", tracker, err)
+		return ""
+	}
+	if !found && tracker != issues.GitHub {
+		pterm.Warning.Printf("No %s credentials configured. Run: commit issue setup\n", tracker)
+		return ""
+	}
+
+	logging.Debug("fetching issue", "tracker", tracker, "key", ref.Key)
+	client := &issues.Client{HTTP: httpClient.GetClient()}
+	issue, err := client.Fetch(context.Background(), tracker, ref, remote, cred)
+	if err != nil {
+		pterm.Warning.Printf("Could not fetch issue %s from %s: %v\n", ref.Key, tracker, err)
+		return ""
+	}
+
+	pterm.Info.Printf("Using issue %s: %s\n", issue.Key, issue.Title)
+	return scrubber.ScrubDiff(issue.Summary())
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/dfanso/commit-msg/internal/config"
+	"github.com/dfanso/commit-msg/internal/git"
+	"github.com/dfanso/commit-msg/internal/lint"
+	"github.com/dfanso/commit-msg/internal/platform"
+	"github.com/dfanso/commit-msg/pkg/types"
+	"github.com/pterm/pterm"
+)
+
+// LintOptions selects the message checked by commit lint.
+type LintOptions struct {
+	// File is read when set; "-" reads standard input.
+	File string
+	// Last checks the message of HEAD.
+	Last bool
+	// RepoPath is the repository used with Last.
+	RepoPath string
+}
+
+// LintMessage checks a commit message against the configured lint rules and
+// exits with ExitError when any rule is violated, so it can run as a
+// commit-msg hook.
+func LintMessage(opts LintOptions) {
+	message, source, err := readLintMessage(opts)
+	if err != nil {
+		exitf(ExitError, "Failed to read commit message: %v\n", err)
+	}
+
+	rules, err := config.LoadLint()
+	if err != nil {
+		pterm.Warning.Printf("Using default lint rules: %v\n", err)
+	}
+
+	issues := lint.Check(lint.Clean(message), rules)
+	if len(issues) == 0 {
+		if !quietMode {
+			pterm.Success.Printf("%s passed all lint rules\n", source)
+		}
+		return
+	}
+
+	// Issues go to stderr so git shows them when the hook rejects a commit.
+	fmt.Fprintf(os.Stderr, "%s has %d lint issue(s):\n", source, len(issues))
+	for _, issue := range issues {
+		fmt.Fprintf(os.Stderr, "  %s\n", issue)
+	}
+	exit(ExitError)
+}
+
+// readLintMessage returns the message selected by opts and a description of
+// where it came from. Without --file or --last the message is read from
+// standard input.
+func readLintMessage(opts LintOptions) (string, string, error) {
+	switch {
+	case opts.Last:
+		repoPath := opts.RepoPath
+		if repoPath == "" {
+			repoPath = "."
+		}
+		if !git.IsRepository(repoPath) {
+			return "", "", fmt.Errorf("%s is not a git repository", repoPath)
+		}
+		message, err := git.CommitMessage(&types.RepoConfig{Path: repoPath}, "HEAD")
+		return message, "HEAD commit message", err
+	case opts.File != "" && opts.File != "-":
+		data, err := os.ReadFile(platform.ResolvePath(opts.File))
+		return string(data), opts.File, err
+	default:
+		data, err := io.ReadAll(os.Stdin)
+		return string(data), "Commit message", err
+	}
+}
@@ -1,12 +1,26 @@
 package cmd
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/dfanso/commit-msg/cmd/cli/store"
+	"github.com/dfanso/commit-msg/internal/cohere"
+	"github.com/dfanso/commit-msg/internal/grok"
+	"github.com/dfanso/commit-msg/internal/groq"
+	"github.com/dfanso/commit-msg/internal/huggingface"
+	"github.com/dfanso/commit-msg/internal/llm"
+	"github.com/dfanso/commit-msg/internal/ollama"
+	"github.com/dfanso/commit-msg/internal/openaicompat"
+	"github.com/dfanso/commit-msg/internal/vertex"
 	"github.com/dfanso/commit-msg/pkg/types"
 	"github.com/manifoldco/promptui"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
 )
 
 // SetupLLM walks the user through selecting an LLM provider and storing the
@@ -14,6 +28,9 @@ import (
 func SetupLLM(Store *store.StoreMethods) error {
 
 	providers := types.GetSupportedProviderStrings()
+	for _, name := range llm.DiscoverPlugins() {
+		providers = append(providers, types.PluginProvider(name).String())
+	}
 	prompt := promptui.Select{
 		Label: "Select LLM",
 		Items: providers,
@@ -28,6 +45,9 @@ func SetupLLM(Store *store.StoreMethods) error {
 	if !valid {
 		return fmt.Errorf("invalid LLM provider: %s", modelStr)
 	}
+	if model.IsPlugin() {
+		return SetupPlugin(Store, model.PluginName())
+	}
 
 	var apiKey string
 
@@ -40,13 +60,22 @@ func SetupLLM(Store *store.StoreMethods) error {
 	switch model {
 	case types.ProviderOllama:
 		urlPrompt := promptui.Prompt{
-			Label: "Enter URL",
+			Label:    "Enter URL",
+			Validate: validateOllamaURL,
 		}
 		apiKey, err = urlPrompt.Run()
 		if err != nil {
 			return fmt.Errorf("failed to read Url: %w", err)
 		}
 
+	case types.ProviderVertex:
+		keyFilePrompt := vertexKeyFilePrompt()
+		apiKey, err = keyFilePrompt.Run()
+		if err != nil {
+			return fmt.Errorf("failed to read key file: %w", err)
+		}
+		apiKey = strings.TrimSpace(apiKey)
+
 	default:
 		apiKey, err = apiKeyPrompt.Run()
 		if err != nil {
@@ -65,10 +94,543 @@ func SetupLLM(Store *store.StoreMethods) error {
 		return err
 	}
 
+	if model == types.ProviderOllama {
+		warnPublicOllamaHost(llm.ResolveOllamaURL(apiKey))
+		ollamaModel, err := selectOllamaModel(llm.ResolveOllamaURL(apiKey))
+		if err != nil {
+			return err
+		}
+		if ollamaModel != "" {
+			if err := store.SaveProviderModel(types.ProviderOllama, ollamaModel); err != nil {
+				return err
+			}
+			fmt.Printf("Using Ollama model %s\n", ollamaModel)
+		}
+	}
+
+	if model == types.ProviderGroq {
+		groqModel, err := selectGroqModel(apiKey)
+		if err != nil {
+			return err
+		}
+		if groqModel != "" {
+			if err := store.SaveProviderModel(types.ProviderGroq, groqModel); err != nil {
+				return err
+			}
+			fmt.Printf("Using Groq model %s\n", groqModel)
+		}
+	}
+
+	if model == types.ProviderGrok {
+		grokModel, err := selectGrokModel(apiKey)
+		if err != nil {
+			return err
+		}
+		if grokModel != "" {
+			if err := store.SaveProviderModel(types.ProviderGrok, grokModel); err != nil {
+				return err
+			}
+			fmt.Printf("Using Grok model %s\n", grokModel)
+		}
+	}
+
+	if model == types.ProviderCohere {
+		cohereModel, err := selectCohereModel(apiKey)
+		if err != nil {
+			return err
+		}
+		if cohereModel != "" {
+			if err := store.SaveProviderModel(types.ProviderCohere, cohereModel); err != nil {
+				return err
+			}
+			fmt.Printf("Using Cohere model %s\n", cohereModel)
+		}
+	}
+
+	if preset, ok := openaicompat.Lookup(model); ok {
+		presetModel, err := selectPresetModel(preset)
+		if err != nil {
+			return err
+		}
+		if err := store.SaveProviderModel(model, presetModel); err != nil {
+			return err
+		}
+		fmt.Printf("Using %s model %s\n", preset.Name, presetModel)
+	}
+
+	if model == types.ProviderHuggingFace {
+		if err := setupHuggingFace(); err != nil {
+			return err
+		}
+	}
+
+	if model == types.ProviderVertex {
+		if err := setupVertex(apiKey); err != nil {
+			return err
+		}
+	}
+
 	fmt.Println("LLM model added")
 	return nil
 }
 
+// setupHuggingFace asks for a dedicated Inference Endpoint and the API it
+// speaks, or for a model to request from the serverless router when no
+// endpoint is given.
+func setupHuggingFace() error {
+	endpointPrompt := promptui.Prompt{
+		Label: "Inference Endpoint URL (leave empty for the serverless router)",
+		Validate: func(input string) error {
+			if strings.TrimSpace(input) == "" {
+				return nil
+			}
+			return huggingface.ValidateEndpoint(input)
+		},
+	}
+	endpoint, err := endpointPrompt.Run()
+	if err != nil {
+		return fmt.Errorf("failed to read endpoint: %w", err)
+	}
+	endpoint = strings.TrimSpace(endpoint)
+
+	api := ""
+	if endpoint != "" {
+		apiPrompt := promptui.Select{
+			Label: "Endpoint API",
+			Items: []string{
+				"chat (OpenAI-compatible, for TGI and vLLM with a chat template)",
+				"text-generation (raw prompt, for base models)",
+			},
+		}
+		i, _, err := apiPrompt.Run()
+		if err != nil {
+			return fmt.Errorf("failed to select API: %w", err)
+		}
+		if i == 1 {
+			api = huggingface.APITextGeneration
+		}
+	}
+	if err := store.SaveHuggingFaceEndpoint(endpoint, api); err != nil {
+		return err
+	}
+	if endpoint != "" {
+		fmt.Printf("Using the Inference Endpoint at %s\n", endpoint)
+		return nil
+	}
+
+	modelPrompt := promptui.Prompt{
+		Label:   "Model to request",
+		Default: huggingface.DefaultModel,
+	}
+	hfModel, err := modelPrompt.Run()
+	if err != nil {
+		return fmt.Errorf("failed to read model: %w", err)
+	}
+	if hfModel = strings.TrimSpace(hfModel); hfModel != "" {
+		if err := store.SaveProviderModel(types.ProviderHuggingFace, hfModel); err != nil {
+			return err
+		}
+		fmt.Printf("Using Hugging Face model %s\n", hfModel)
+	}
+	return nil
+}
+
+// vertexCredentialsTimeout bounds the token check and project lookup
+// during Vertex AI setup.
+const vertexCredentialsTimeout = 10 * time.Second
+
+// vertexKeyFilePrompt asks for the service account key or workload identity
+// federation configuration Vertex AI authenticates with, saved in place of
+// an API key.
+func vertexKeyFilePrompt() promptui.Prompt {
+	return promptui.Prompt{
+		Label: "Service account key or credential configuration file (leave empty for Application Default Credentials)",
+		Validate: func(input string) error {
+			input = strings.TrimSpace(input)
+			if input == "" {
+				return nil
+			}
+			info, err := os.Stat(input)
+			if err != nil {
+				return err
+			}
+			if info.IsDir() {
+				return fmt.Errorf("%s is a directory", input)
+			}
+			return nil
+		},
+	}
+}
+
+// setupVertex checks that the credentials in keyFile, or the Application
+// Default Credentials, can get a token, then asks for the project,
+// location, and model. The project suggested is the one recorded with the
+// credentials.
+func setupVertex(keyFile string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), vertexCredentialsTimeout)
+	defer cancel()
+
+	suggested := ""
+	creds, err := vertex.LoadCredentials(keyFile)
+	if err == nil {
+		_, err = creds.Token(ctx)
+	}
+	if err != nil {
+		pterm.Warning.Printf("Could not get a Google Cloud access token: %v\n", err)
+	} else {
+		pterm.Info.Printf("Using Google Cloud credentials from %s\n", creds.Source)
+		suggested = creds.ProjectID(ctx)
+	}
+
+	projectPrompt := promptui.Prompt{
+		Label:   "Google Cloud project ID",
+		Default: suggested,
+		Validate: func(input string) error {
+			if strings.TrimSpace(input) == "" {
+				return errors.New("a project ID is required")
+			}
+			return nil
+		},
+	}
+	project, err := projectPrompt.Run()
+	if err != nil {
+		return fmt.Errorf("failed to read project: %w", err)
+	}
+	project = strings.TrimSpace(project)
+
+	locationPrompt := promptui.Prompt{
+		Label:    "Location",
+		Default:  vertex.DefaultLocation,
+		Validate: vertex.ValidateLocation,
+	}
+	location, err := locationPrompt.Run()
+	if err != nil {
+		return fmt.Errorf("failed to read location: %w", err)
+	}
+	location = strings.TrimSpace(location)
+
+	// Keep the project only when it differs from the credentials', so
+	// switching credentials later also switches the project.
+	savedProject := project
+	if project == suggested {
+		savedProject = ""
+	}
+	if err := store.SaveVertexSettings(savedProject, location); err != nil {
+		return err
+	}
+
+	modelPrompt := promptui.Prompt{
+		Label:   "Model to request",
+		Default: vertex.DefaultModel,
+	}
+	vertexModel, err := modelPrompt.Run()
+	if err != nil {
+		return fmt.Errorf("failed to read model: %w", err)
+	}
+	if vertexModel = strings.TrimSpace(vertexModel); vertexModel != "" {
+		if err := store.SaveProviderModel(types.ProviderVertex, vertexModel); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("Using %s in %s/%s\n", vertex.ResolveModel(vertexModel), project, location)
+	return nil
+}
+
+// SetupPlugin registers the external provider executable
+// commit-provider-<name> found on PATH, storing an optional credential that
+// is passed to the plugin with every request.
+func SetupPlugin(Store *store.StoreMethods, name string) error {
+	path, err := llm.FindPlugin(name)
+	if err != nil {
+		return err
+	}
+	pterm.Info.Printf("Found plugin %s at %s\n", name, path)
+
+	credentialPrompt := promptui.Prompt{
+		Label: "Enter API Key (leave empty if the plugin needs none)",
+		Mask:  '*',
+	}
+	credential, err := credentialPrompt.Run()
+	if err != nil {
+		return fmt.Errorf("failed to read API Key: %w", err)
+	}
+
+	err = Store.Save(store.LLMProvider{
+		LLM:    types.PluginProvider(name),
+		APIKey: credential,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Plugin %s added\n", name)
+	return nil
+}
+
+// ollamaListTimeout bounds the installed-model lookup during setup.
+const ollamaListTimeout = 5 * time.Second
+
+// validateOllamaURL checks the URL entered for Ollama. An empty answer is
+// accepted and falls back to OLLAMA_URL or the local default.
+func validateOllamaURL(input string) error {
+	if strings.TrimSpace(input) == "" {
+		return nil
+	}
+	return ollama.ValidateURL(input)
+}
+
+// warnPublicOllamaHost warns when the Ollama endpoint is on a public host,
+// where prompts leave this machine and its network.
+func warnPublicOllamaHost(endpoint string) {
+	ctx, cancel := context.WithTimeout(context.Background(), ollamaListTimeout)
+	defer cancel()
+	if host, public := ollama.PublicHost(ctx, endpoint); public {
+		pterm.Warning.Printf("%s is a public host, so your changes leave your network whenever a message is generated.\n", host)
+		pterm.Info.Println("Prefer https, and add the host to ollama.allowed_hosts in config.json if this is intended.")
+	}
+}
+
+// selectOllamaModel lists the models installed on the Ollama server at
+// endpoint and lets the user pick one, offering to pull the recommended
+// model when none are installed. It returns "" when the server cannot be
+// reached or the user declines, leaving OLLAMA_MODEL or the default in
+// effect.
+func selectOllamaModel(endpoint string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), ollamaListTimeout)
+	models, err := ollama.ListModels(ctx, endpoint)
+	cancel()
+	if err != nil {
+		pterm.Warning.Printf("Could not list Ollama models at %s: %v\n", endpoint, err)
+		pterm.Info.Println("Start Ollama and run 'commit llm setup' again to pick a model.")
+		return "", nil
+	}
+
+	if len(models) == 0 {
+		confirm, err := pterm.DefaultInteractiveConfirm.
+			WithDefaultValue(true).
+			Show(fmt.Sprintf("No Ollama models are installed. Pull %s now?", ollama.RecommendedModel))
+		if err != nil || !confirm {
+			return "", nil
+		}
+		if err := pullOllamaModel(endpoint, ollama.RecommendedModel); err != nil {
+			return "", err
+		}
+		return ollama.RecommendedModel, nil
+	}
+
+	names := make([]string, len(models))
+	for i, m := range models {
+		names[i] = m.Name
+	}
+	prompt := promptui.Select{
+		Label: "Select Ollama model",
+		Items: names,
+	}
+	_, name, err := prompt.Run()
+	if err != nil {
+		return "", fmt.Errorf("failed to select model: %w", err)
+	}
+	return name, nil
+}
+
+// groqListTimeout bounds the Groq model lookups during setup and status
+// checks.
+const groqListTimeout = 10 * time.Second
+
+// cohereListTimeout bounds the Cohere model lookup during setup.
+const cohereListTimeout = 10 * time.Second
+
+// grokListTimeout bounds the xAI model lookup during setup.
+const grokListTimeout = 10 * time.Second
+
+// selectGroqModel lists the models Groq offers to apiKey and lets the user
+// pick one, starting on the default model. It returns "" when the list
+// cannot be fetched, leaving GROQ_MODEL or the default in effect, and warns
+// when GROQ_MODEL names a model that is not offered.
+func selectGroqModel(apiKey string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), groqListTimeout)
+	models, err := groq.ListModels(ctx, apiKey)
+	cancel()
+	if err != nil {
+		pterm.Warning.Printf("Could not list Groq models: %v\n", err)
+		pterm.Info.Printf("Using %s; run 'commit llm setup' again to pick a model.\n", groq.ResolveModel(""))
+		return "", nil
+	}
+	if len(models) == 0 {
+		return "", nil
+	}
+
+	if override := strings.TrimSpace(os.Getenv("GROQ_MODEL")); override != "" {
+		if err := groq.CheckModel(override, models); err != nil {
+			pterm.Warning.Printf("GROQ_MODEL is set to %s, which Groq does not offer to this account; it overrides the model chosen here.\n", override)
+		}
+	}
+
+	names := make([]string, len(models))
+	cursor := 0
+	for i, m := range models {
+		names[i] = m.ID
+		if m.ID == groq.DefaultModel {
+			cursor = i
+		}
+	}
+	prompt := promptui.Select{
+		Label:     "Select Groq model",
+		Items:     names,
+		CursorPos: cursor,
+		Size:      10,
+	}
+	_, name, err := prompt.Run()
+	if err != nil {
+		return "", fmt.Errorf("failed to select model: %w", err)
+	}
+	return name, nil
+}
+
+// checkGroqModel reports an error when the Groq model in use, from
+// GROQ_MODEL or setup, is not offered to apiKey.
+func checkGroqModel(apiKey string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), groqListTimeout)
+	defer cancel()
+	models, err := groq.ListModels(ctx, apiKey)
+	if err != nil {
+		return err
+	}
+	return groq.CheckModel(llm.ModelFor(types.ProviderGroq), models)
+}
+
+// selectCohereModel lists the chat models Cohere offers to apiKey and lets
+// the user pick one, starting on the default model. It returns "" when the
+// list cannot be fetched, leaving COHERE_MODEL or the default in effect.
+func selectCohereModel(apiKey string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), cohereListTimeout)
+	models, err := cohere.ListModels(ctx, apiKey)
+	cancel()
+	if err != nil {
+		pterm.Warning.Printf("Could not list Cohere models: %v\n", err)
+		pterm.Info.Printf("Using %s; run 'commit llm setup' again to pick a model.\n", cohere.ResolveModel(""))
+		return "", nil
+	}
+	if len(models) == 0 {
+		return "", nil
+	}
+
+	names := make([]string, len(models))
+	cursor := 0
+	for i, m := range models {
+		names[i] = m.Name
+		if m.Name == cohere.DefaultModel {
+			cursor = i
+		}
+	}
+	prompt := promptui.Select{
+		Label:     "Select Cohere model",
+		Items:     names,
+		CursorPos: cursor,
+		Size:      10,
+	}
+	_, name, err := prompt.Run()
+	if err != nil {
+		return "", fmt.Errorf("failed to select model: %w", err)
+	}
+	return name, nil
+}
+
+// selectGrokModel lists the models xAI offers to apiKey and lets the user
+// pick one, starting on the default model. It returns "" when the list
+// cannot be fetched, leaving GROK_MODEL or the default in effect.
+func selectGrokModel(apiKey string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), grokListTimeout)
+	models, err := grok.ListModels(ctx, providerConfig(), apiKey)
+	cancel()
+	if err != nil {
+		pterm.Warning.Printf("Could not list Grok models: %v\n", err)
+		pterm.Info.Printf("Using %s; run 'commit llm setup' again to pick a model.\n", grok.ResolveModel(""))
+		return "", nil
+	}
+	if len(models) == 0 {
+		return "", nil
+	}
+
+	cursor := 0
+	for i, name := range models {
+		if name == grok.DefaultModel {
+			cursor = i
+		}
+	}
+	prompt := promptui.Select{
+		Label:     "Select Grok model",
+		Items:     models,
+		CursorPos: cursor,
+		Size:      10,
+	}
+	_, name, err := prompt.Run()
+	if err != nil {
+		return "", fmt.Errorf("failed to select model: %w", err)
+	}
+	return name, nil
+}
+
+// selectPresetModel offers the curated models of an OpenAI-compatible
+// preset, plus the choice of typing any other model the service hosts.
+func selectPresetModel(preset openaicompat.Preset) (string, error) {
+	items := make([]string, 0, len(preset.Models)+1)
+	for _, m := range preset.Models {
+		items = append(items, fmt.Sprintf("%s (%s)", m.ID, m.Description))
+	}
+	items = append(items, "Other model")
+
+	prompt := promptui.Select{
+		Label: "Select " + preset.Name + " model",
+		Items: items,
+		Size:  10,
+	}
+	i, _, err := prompt.Run()
+	if err != nil {
+		return "", fmt.Errorf("failed to select model: %w", err)
+	}
+	if i < len(preset.Models) {
+		return preset.Models[i].ID, nil
+	}
+
+	otherPrompt := promptui.Prompt{
+		Label: "Model ID",
+		Validate: func(input string) error {
+			if strings.TrimSpace(input) == "" {
+				return errors.New("a model ID is required")
+			}
+			return nil
+		},
+	}
+	other, err := otherPrompt.Run()
+	if err != nil {
+		return "", fmt.Errorf("failed to read model: %w", err)
+	}
+	return strings.TrimSpace(other), nil
+}
+
+// pullOllamaModel downloads model, showing the streamed progress.
+func pullOllamaModel(endpoint, model string) error {
+	spinner, err := pterm.DefaultSpinner.Start("Pulling " + model + "...")
+	if err != nil {
+		return err
+	}
+
+	err = ollama.Pull(context.Background(), endpoint, model, func(p ollama.PullProgress) {
+		if p.Total > 0 {
+			spinner.UpdateText(fmt.Sprintf("Pulling %s: %s (%d%%)", model, p.Status, p.Completed*100/p.Total))
+			return
+		}
+		spinner.UpdateText(fmt.Sprintf("Pulling %s: %s", model, p.Status))
+	})
+	if err != nil {
+		spinner.Fail("Failed to pull " + model)
+		return fmt.Errorf("failed to pull %s: %w", model, err)
+	}
+	spinner.Success("Pulled " + model)
+	return nil
+}
+
 // UpdateLLM lets the user switch defaults, rotate API keys, or delete stored
 // LLM provider configurations.
 func UpdateLLM(Store *store.StoreMethods) error {
@@ -117,10 +679,19 @@ func UpdateLLM(Store *store.StoreMethods) error {
 		}
 
 		apiKeyPrompt = promptui.Prompt{
-			Label: "Enter URL",
+			Label:    "Enter URL",
+			Validate: validateOllamaURL,
 		}
 	}
 
+	if model == types.ProviderVertex.String() {
+		prompt = promptui.Select{
+			Label: "Select Option",
+			Items: []string{"Set Default", "Change Key File", "Delete"},
+		}
+		apiKeyPrompt = vertexKeyFilePrompt()
+	}
+
 	opNo, _, err := prompt.Run()
 	if err != nil {
 		return err
@@ -153,6 +724,10 @@ func UpdateLLM(Store *store.StoreMethods) error {
 		event := "API Key"
 		if model == types.ProviderOllama.String() {
 			event = "URL"
+			warnPublicOllamaHost(llm.ResolveOllamaURL(apiKey))
+		}
+		if model == types.ProviderVertex.String() {
+			event = "Key File"
 		}
 		fmt.Printf("%s %s Updated", model, event)
 	case 2:
@@ -160,12 +735,179 @@ func UpdateLLM(Store *store.StoreMethods) error {
 		if !valid {
 			return fmt.Errorf("invalid LLM provider: %s", model)
 		}
-		err := Store.DeleteModel(modelProvider)
+		newDefault, err := chooseReplacementDefault(SavedModels, modelProvider)
+		if err != nil {
+			return err
+		}
+		err = Store.DeleteModel(modelProvider, newDefault)
 		if err != nil {
 			return err
 		}
 		fmt.Printf("%s model deleted", model)
+		if newDefault != "" {
+			fmt.Printf(", %s is now the default", newDefault)
+		}
+	}
+
+	return nil
+}
+
+// savedLLM returns the saved credential of the provider named name, or of
+// the default provider when name is empty.
+func savedLLM(Store *store.StoreMethods, name string) (*store.LLMProvider, error) {
+	if name == "" {
+		return Store.DefaultLLMKey()
+	}
+	provider, ok := parseProviderName(name)
+	if !ok {
+		return nil, fmt.Errorf("unsupported provider %q (supported: %s)", name, strings.Join(types.GetSupportedProviderStrings(), ", "))
+	}
+	return Store.LLMKey(provider)
+}
+
+// UseLLM makes the saved provider named name the default without going
+// through the update menu.
+func UseLLM(name string) error {
+	provider, ok := parseProviderName(name)
+	if !ok {
+		return fmt.Errorf("unsupported provider %q (supported: %s)", name, strings.Join(types.GetSupportedProviderStrings(), ", "))
+	}
+	if err := store.ChangeDefault(provider); err != nil {
+		return err
+	}
+	pterm.Success.Printf("%s is now the default provider\n", provider)
+	return nil
+}
+
+// savedProviderNames completes provider arguments with the saved providers.
+func savedProviderNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	cfg, err := store.ListSavedModels()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var names []string
+	for _, p := range cfg.LLMProviders {
+		names = append(names, p.String())
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// chooseReplacementDefault asks which provider becomes the default when the
+// default provider is deleted. With only one other provider saved it is
+// chosen without asking; when deleted is not the default, or is the last
+// provider, there is nothing to choose and it returns "".
+func chooseReplacementDefault(cfg *store.Config, deleted types.LLMProvider) (types.LLMProvider, error) {
+	if deleted != cfg.Default {
+		return "", nil
+	}
+	remaining := store.RemainingProviders(cfg, deleted)
+	switch len(remaining) {
+	case 0:
+		return "", nil
+	case 1:
+		return remaining[0], nil
+	}
+
+	items := make([]string, len(remaining))
+	for i, p := range remaining {
+		items[i] = p.String()
+	}
+	prompt := promptui.Select{
+		Label: deleted.String() + " is the default, select the new default",
+		Items: items,
+	}
+	i, _, err := prompt.Run()
+	if err != nil {
+		return "", err
+	}
+	return remaining[i], nil
+}
+
+// healthCheckTimeout bounds each provider's status check.
+const healthCheckTimeout = 10 * time.Second
+
+// CheckLLMStatus pings every saved provider and reports reachability,
+// latency, remaining quota, and credential problems. It exits with
+// ExitProviderError when any provider fails so scripts can catch a bad key
+// before it blocks a commit.
+func CheckLLMStatus(Store *store.StoreMethods) error {
+	cfg, err := store.ListSavedModels()
+	if err != nil {
+		return err
+	}
+	if len(cfg.LLMProviders) == 0 {
+		pterm.Info.Println("No LLM providers configured. Run: commit llm setup")
+		return nil
+	}
+
+	checker := llm.HealthChecker{Config: providerConfig()}
+	tableData := [][]string{{"Provider", "Status", "Latency", "Details"}}
+	failed := 0
+
+	for _, provider := range cfg.LLMProviders {
+		name := provider.String()
+		if provider == cfg.Default {
+			name += " (default)"
+		}
+
+		saved, err := Store.LLMKey(provider)
+		if err != nil {
+			failed++
+			tableData = append(tableData, []string{name, pterm.Red("error"), "-", err.Error()})
+			continue
+		}
+
+		spinner, err := pterm.DefaultSpinner.WithRemoveWhenDone().Start("Checking " + provider.String() + "...")
+		if err != nil {
+			return fmt.Errorf("failed to start spinner: %w", err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), healthCheckTimeout)
+		health := checker.Check(ctx, provider, saved.APIKey)
+		cancel()
+		if health.OK && provider == types.ProviderGroq {
+			if err := checkGroqModel(saved.APIKey); err != nil {
+				health.OK = false
+				health.Problem = err.Error() + "; run: commit llm setup"
+			}
+		}
+		_ = spinner.Stop()
+
+		status, latency, details := pterm.Green("ok"), "-", health.QuotaSummary()
+		if health.Reachable {
+			latency = health.Latency.Round(time.Millisecond).String()
+		}
+		if !health.OK {
+			failed++
+			status = pterm.Red("failed")
+			details = health.Problem
+		}
+		tableData = append(tableData, []string{name, status, latency, details})
 	}
 
+	if err := pterm.DefaultTable.WithHasHeader().WithData(tableData).Render(); err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		exitf(ExitProviderError, "%d of %d providers failed the status check\n", failed, len(cfg.LLMProviders))
+	}
+	return nil
+}
+
+// MigrateCredentials moves the current profile's provider keys and tracker
+// credentials to another backend, either the OS keyring or an encrypted
+// file.
+func MigrateCredentials(Store *store.StoreMethods, to string) error {
+	moved, err := Store.Migrate(to)
+	if moved == 0 && err != nil {
+		return err
+	}
+	pterm.Success.Printf("Moved %d credential(s) to %s\n", moved, store.BackendDescription(to))
+	if err != nil {
+		pterm.Warning.Printf("Some entries could not be removed from the old backend: %v\n", err)
+	}
+	if to == store.BackendFile {
+		pterm.Info.Printf("Set %s to unlock the file without a prompt\n", store.CredentialsPasswordEnv)
+	}
 	return nil
 }
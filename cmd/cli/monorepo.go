@@ -0,0 +1,311 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dfanso/commit-msg/cmd/cli/store"
+	"github.com/dfanso/commit-msg/internal/display"
+	"github.com/dfanso/commit-msg/internal/git"
+	"github.com/dfanso/commit-msg/internal/llm"
+	"github.com/dfanso/commit-msg/internal/logging"
+	"github.com/dfanso/commit-msg/internal/monorepo"
+	"github.com/dfanso/commit-msg/internal/platform"
+	"github.com/dfanso/commit-msg/internal/scrubber"
+	"github.com/dfanso/commit-msg/internal/tui"
+	"github.com/dfanso/commit-msg/internal/vcs"
+	"github.com/dfanso/commit-msg/pkg/types"
+	"github.com/pterm/pterm"
+)
+
+// rootPackageLabel names the group of changed files outside every package.
+const rootPackageLabel = "(workspace root)"
+
+// packageMessage is a generated message for one monorepo package.
+type packageMessage struct {
+	pkg     string
+	message string
+	// reviewed is the message as accepted in the review, before
+	// post-processing.
+	reviewed string
+	// commit is set when the message was accepted with the commit action.
+	commit bool
+}
+
+// detectChangedPackages reports the monorepo workspace containing the repo
+// and the sorted package directories touched by the current changes. The
+// "" entry stands for files outside every package. Detection failures are
+// logged and treated as "not a monorepo".
+func detectChangedPackages(config *types.RepoConfig) (*monorepo.Workspace, []string) {
+	root, err := git.RepoRoot(config.Path)
+	if err != nil {
+		logging.Debug("monorepo detection skipped", "error", err)
+		return nil, nil
+	}
+
+	workspace, err := monorepo.Detect(root)
+	if err != nil {
+		logging.Debug("monorepo detection failed", "error", err)
+		return nil, nil
+	}
+	if workspace == nil {
+		return nil, nil
+	}
+
+	files, err := git.ChangedFiles(&types.RepoConfig{Path: root})
+	if err != nil {
+		logging.Debug("monorepo detection failed", "error", err)
+		return nil, nil
+	}
+
+	groups := workspace.Group(files)
+	packages := make([]string, 0, len(groups))
+	for pkg := range groups {
+		packages = append(packages, pkg)
+	}
+	sort.Strings(packages)
+
+	logging.Debug("monorepo detected", "kind", workspace.Kind, "packages", len(workspace.Packages), "changed", packages)
+	return workspace, packages
+}
+
+// packageScopeInstruction builds the prompt hint that tells the LLM which
+// workspace packages the changes belong to.
+func packageScopeInstruction(workspace *monorepo.Workspace, packages []string) string {
+	if workspace == nil || len(packages) == 0 {
+		return ""
+	}
+
+	if len(packages) == 1 {
+		if packages[0] == "" {
+			return fmt.Sprintf("These changes touch shared files at the root of a %s workspace rather than a single package.", workspace.Kind)
+		}
+		return fmt.Sprintf("All changes are in the %s package of a %s workspace; use %q as the conventional commit scope.",
+			packages[0], workspace.Kind, monorepo.ScopeName(packages[0]))
+	}
+
+	return fmt.Sprintf("The changes span several packages of a %s workspace (%s); mention the affected packages and only use a scope if one package clearly dominates.",
+		workspace.Kind, strings.Join(packageLabels(packages), ", "))
+}
+
+// packageScopeName returns the conventional commit scope of the single
+// package packages holds, or "" when the changes are not confined to one.
+func packageScopeName(packages []string) string {
+	if len(packages) != 1 || packages[0] == "" {
+		return ""
+	}
+	return monorepo.ScopeName(packages[0])
+}
+
+// confirmPerPackage asks whether to generate one message per package.
+func confirmPerPackage(workspace *monorepo.Workspace, packages []string) bool {
+	pterm.Println()
+	pterm.Info.Printf("Detected a %s workspace. Changes span %d packages: %s\n",
+		workspace.Kind, len(packages), strings.Join(packageLabels(packages), ", "))
+
+	confirm, err := pterm.DefaultInteractiveConfirm.
+		WithDefaultValue(false).
+		Show("Generate a separate commit message for each package?")
+	if err != nil {
+		logging.Debug("per-package confirmation failed", "error", err)
+		return false
+	}
+	return confirm
+}
+
+// generatePerPackage generates, reviews, and optionally commits a separate
+// message for every changed package in the workspace.
+func generatePerPackage(ctx context.Context, provider llm.Provider, store *store.StoreMethods, providerType types.LLMProvider, workspace *monorepo.Workspace, packages []string, fileStats *display.FileStatistics, opts CreateOptions, style tui.StylePreset) {
+	rootConfig := types.RepoConfig{Path: workspace.Root, Limits: loadContentLimits()}
+	var accepted []packageMessage
+
+	subjectLimit := opts.subjectLimit()
+	warnings := commitMessageLengthWarnings
+	if opts.Oneline {
+		warnings = onelineWarnings(subjectLimit)
+	}
+
+	for _, pkg := range packages {
+		label := packageLabel(pkg)
+
+		changes, err := git.GetChangesInPaths(&rootConfig, packagePathspec(workspace, pkg)...)
+		if err != nil {
+			exitf(ExitError, "Failed to get Git changes for %s: %v\n", label, err)
+		}
+		if strings.TrimSpace(changes) == "" {
+			continue
+		}
+		changes = truncateLargeDiff(condenseChanges(workspace.Root, scrubber.ScrubDiff(changes)))
+		prompt := newPromptContext(workspace.Root, workspace, []string{pkg})
+
+		pterm.Println()
+		pterm.DefaultSection.Println(label)
+		spinner, err := pterm.DefaultSpinner.Start("Generating commit message for " + label + "...")
+		if err != nil {
+			exitf(ExitError, "Failed to start spinner: %v\n", err)
+		}
+
+		genOpts := prompt.apply(withAttempt(styleOptions(style), 1))
+		genOpts.Temperature = opts.Temperature
+		genOpts.Seed = opts.Seed
+		if !quietMode {
+			genOpts.Candidates = opts.Candidates
+		}
+		generate := func(genOpts *types.GenerationOptions) (*types.GenerationResult, error) {
+			return generateMessageWithCache(ctx, provider, store, providerType, changes, genOpts)
+		}
+		generate = withCorrections(workspace.Root, generate)
+		if opts.Oneline {
+			generate = withOneline(subjectLimit, generate)
+		}
+		generate = withBlocklist(generate)
+		generated, err := generate(genOpts)
+		if err != nil {
+			spinner.Fail("Failed to generate commit message for " + label)
+			displayProviderError(providerType, err)
+			exit(ExitProviderError)
+		}
+		spinner.Success("Commit message generated for " + label + " (" + display.GenerationSummary(generated) + ")")
+
+		message := strings.TrimSpace(generated.Message)
+		if quietMode {
+			if message == "" {
+				exitf(ExitProviderError, "Generated commit message for %s is empty\n", label)
+			}
+			message = withAttribution(workspace.Root, providerType, formatMessage(message))
+			if opts.SignOff {
+				if message, err = withSignOff(workspace.Root, message); err != nil {
+					exitf(ExitError, "Failed to sign off: %v\n", err)
+				}
+			}
+			accepted = append(accepted, packageMessage{pkg: pkg, message: message})
+			continue
+		}
+
+		result, err := tui.Run(tui.Config{
+			Diff:         changes,
+			Message:      message,
+			Alternatives: generated.Alternatives,
+			Styles:       loadStylePresets(),
+			Style:        style,
+			Temperature:  opts.Temperature,
+			Generate: func(genOpts *types.GenerationOptions) (string, error) {
+				genOpts = prompt.apply(genOpts)
+				genOpts.Seed = opts.Seed
+				generated, err := generate(genOpts)
+				if err != nil {
+					return "", err
+				}
+				return generated.Message, nil
+			},
+			EditorCommand: editorCommandFor(workspace.Root, opts.Editor),
+			PagerCommand:  pagerCommand,
+			Warnings:      warnings,
+		})
+		if err != nil {
+			exitf(ExitError, "Failed to run interactive review: %v\n", err)
+		}
+		recordHistory(workspace.Root, providerType, result)
+		if !result.Accepted {
+			pterm.Info.Printf("Skipped %s.\n", label)
+			continue
+		}
+		reviewed := result.Message
+		message = formatMessage(result.Message)
+		rememberAcceptedMessage(workspace.Root, message)
+		message = withAttribution(workspace.Root, providerType, message)
+		if opts.SignOff || result.SignOff {
+			if message, err = withSignOff(workspace.Root, message); err != nil {
+				exitf(ExitError, "Failed to sign off: %v\n", err)
+			}
+		}
+		accepted = append(accepted, packageMessage{pkg: pkg, message: message, reviewed: reviewed, commit: result.Commit})
+	}
+
+	if len(accepted) == 0 {
+		pterm.Info.Println("No package messages accepted.")
+		exit(ExitCancelled)
+	}
+
+	if quietMode {
+		for i, pm := range accepted {
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Println(pm.message)
+		}
+	} else {
+		var combined []string
+		for _, pm := range accepted {
+			pterm.Println()
+			pterm.DefaultSection.Println(packageLabel(pm.pkg))
+			display.ShowCommitMessage(pm.message)
+			validateCommitMessageLength(pm.message, warnings)
+			combined = append(combined, pm.message)
+		}
+
+		if err := platform.CopyToClipboard(strings.Join(combined, "\n\n")); err != nil {
+			pterm.Warning.Printf("Could not copy to clipboard: %v\n", err)
+		} else {
+			pterm.Success.Println("Commit messages copied to clipboard!")
+		}
+	}
+
+	if opts.AutoCommit && !quietMode && !opts.Yes {
+		for _, pm := range accepted {
+			pterm.Println()
+			pterm.DefaultSection.Println(packageLabel(pm.pkg))
+			display.ShowCommitConfirmation(pm.reviewed, pm.message)
+		}
+		if !confirmAutoCommit(fmt.Sprintf("Commit %d package(s) with these messages?", len(accepted))) {
+			pterm.Info.Println("Commit cancelled.")
+			exit(ExitCancelled)
+		}
+	}
+
+	staged := workspace.Group(fileStats.StagedFiles)
+	for _, pm := range accepted {
+		if !opts.AutoCommit && !pm.commit {
+			continue
+		}
+		files := staged[pm.pkg]
+		if len(files) == 0 {
+			pterm.Warning.Printf("No staged files in %s; skipping commit.\n", packageLabel(pm.pkg))
+			continue
+		}
+		if err := runAutoCommit(vcs.NewGit(workspace.Root), pm.message, files...); err != nil {
+			exitf(ExitError, "Failed to commit %s: %v\n", packageLabel(pm.pkg), err)
+		}
+		pterm.Success.Printf("Committed %s.\n", packageLabel(pm.pkg))
+	}
+}
+
+// packagePathspec returns the git pathspecs selecting pkg's files. The root
+// group selects everything except the workspace packages.
+func packagePathspec(workspace *monorepo.Workspace, pkg string) []string {
+	if pkg != "" {
+		return []string{pkg}
+	}
+	paths := []string{"."}
+	for _, p := range workspace.Packages {
+		paths = append(paths, ":(exclude)"+p)
+	}
+	return paths
+}
+
+func packageLabel(pkg string) string {
+	if pkg == "" {
+		return rootPackageLabel
+	}
+	return pkg
+}
+
+func packageLabels(packages []string) []string {
+	labels := make([]string, len(packages))
+	for i, pkg := range packages {
+		labels[i] = packageLabel(pkg)
+	}
+	return labels
+}
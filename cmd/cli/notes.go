@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/dfanso/commit-msg/internal/git"
+	"github.com/dfanso/commit-msg/internal/llm"
+	"github.com/dfanso/commit-msg/internal/logging"
+	"github.com/dfanso/commit-msg/pkg/types"
+	"github.com/pterm/pterm"
+)
+
+// noteTemplate is the prompt for the explanation attached with --with-note.
+// It replaces the commit prompt, which asks for a concise message.
+const noteTemplate = `Explain the change below in more depth than its commit message does. The
+explanation is attached to the commit as a git note for reviewers and future
+readers.
+
+Write a few short plain-text paragraphs covering why the change was made, how
+it works, and any decisions, trade-offs, or follow-ups worth knowing about. Do
+not repeat the commit message, and do not add a title, headings, or Markdown.{{with .Repository}}
+
+Repository: {{.}}{{end}}
+
+The change was committed with this message:
+---
+{{.PreviousMessage}}
+---
+
+{{.Changes}}`
+
+// attachNote asks provider for a longer explanation of the commit just made
+// with message and attaches it to HEAD under git.NotesRef. Failures are
+// reported as warnings since the commit itself has succeeded.
+func attachNote(ctx context.Context, provider llm.Provider, providerType types.LLMProvider, dir, changes, message string, prompt promptContext) {
+	if providerType == ruleBasedProvider {
+		pterm.Warning.Println("Skipping --with-note: the rule-based generator cannot explain changes.")
+		return
+	}
+
+	spinner, err := pterm.DefaultSpinner.Start(fmt.Sprintf("Writing the explanation note with %s...", providerType))
+	if err != nil {
+		exitf(ExitError, "Failed to start spinner: %v\n", err)
+	}
+	opts := prompt.apply(withAttempt(nil, 1))
+	opts.Template = noteTemplate
+	opts.PreviousMessage = message
+	result, err := llm.Generate(ctx, provider, changes, opts)
+	if err != nil {
+		spinner.Fail("Failed to write the explanation note")
+		logging.Debug("note generation failed", "error", err)
+		pterm.Warning.Printf("The commit has no note: %v\n", err)
+		return
+	}
+
+	note := strings.TrimSpace(result.Message)
+	if note == "" {
+		spinner.Warning("The provider returned an empty explanation; no note attached")
+		return
+	}
+	if err := git.AddNote(&types.RepoConfig{Path: dir}, "HEAD", note); err != nil {
+		spinner.Fail("Failed to attach the explanation note")
+		pterm.Warning.Printf("%v\n", err)
+		return
+	}
+	spinner.Success("Explanation attached as a note in " + git.NotesRef + " (read it with: commit notes show)")
+}
+
+// ShowNote prints the explanation note attached to rev, HEAD when empty, in
+// the repository at repoPath.
+func ShowNote(repoPath, rev string) error {
+	dir, err := resolveRepoPath(repoPath)
+	if err != nil {
+		return err
+	}
+	if !git.IsRepository(dir) {
+		exitf(ExitNotRepository, "Not a Git repository: %s\n", dir)
+	}
+	if rev == "" {
+		rev = "HEAD"
+	}
+
+	note, ok, err := git.Note(&types.RepoConfig{Path: dir}, rev)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		pterm.Info.Printf("%s has no note in %s.\n", rev, git.NotesRef)
+		return nil
+	}
+	fmt.Println(note)
+	return nil
+}
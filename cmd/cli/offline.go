@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+
+	"github.com/dfanso/commit-msg/cmd/cli/store"
+	"github.com/dfanso/commit-msg/internal/display"
+	"github.com/dfanso/commit-msg/internal/git"
+	"github.com/dfanso/commit-msg/internal/heuristic"
+	"github.com/dfanso/commit-msg/internal/llm"
+	"github.com/dfanso/commit-msg/internal/logging"
+	"github.com/dfanso/commit-msg/internal/vcs"
+	"github.com/dfanso/commit-msg/pkg/types"
+	"github.com/pterm/pterm"
+)
+
+// ruleBasedProvider names the generator that builds messages from the file
+// list without an LLM. It is never offered by commit llm setup.
+const ruleBasedProvider types.LLMProvider = "Rule-based"
+
+// ruleBasedGenerator adapts internal/heuristic to the llm.Provider interface
+// so the review loop works unchanged.
+type ruleBasedGenerator struct {
+	files []heuristic.File
+}
+
+func (g *ruleBasedGenerator) Name() types.LLMProvider {
+	return ruleBasedProvider
+}
+
+func (g *ruleBasedGenerator) Generate(_ context.Context, _ string, _ *types.GenerationOptions) (string, error) {
+	message := heuristic.Generate(g.files)
+	if message == "" {
+		return "", errors.New("no changed files to describe")
+	}
+	return message, nil
+}
+
+// ruleBasedFiles collects the rule-based generator's input. Git repositories
+// report statuses and line counts; other backends fall back to the file lists
+// in fileStats.
+func ruleBasedFiles(backend vcs.Backend, fileStats *display.FileStatistics) []heuristic.File {
+	if backend.Name() != "git" {
+		return heuristicFiles(fileStats)
+	}
+
+	stats, err := git.FileStats(&types.RepoConfig{Path: backend.Path()})
+	if err != nil {
+		logging.Debug("numstat unavailable; using file lists", "error", err)
+		return heuristicFiles(fileStats)
+	}
+
+	files := make([]heuristic.File, 0, len(stats))
+	for _, stat := range stats {
+		file := heuristic.File{
+			Path:       stat.Path,
+			OldPath:    stat.OldPath,
+			Insertions: stat.Insertions,
+			Deletions:  stat.Deletions,
+		}
+		switch stat.Status {
+		case 'A', 'C':
+			file.Status = heuristic.Added
+		case 'D':
+			file.Status = heuristic.Deleted
+		case 'R':
+			file.Status = heuristic.Renamed
+		default:
+			file.Status = heuristic.Modified
+		}
+		files = append(files, file)
+	}
+	return files
+}
+
+// heuristicFiles converts the collected file statistics into the rule-based
+// generator's input. Untracked files count as added.
+func heuristicFiles(fileStats *display.FileStatistics) []heuristic.File {
+	seen := make(map[string]bool)
+	var files []heuristic.File
+	add := func(paths []string, status heuristic.Status) {
+		for _, path := range paths {
+			if path == "" || seen[path] {
+				continue
+			}
+			seen[path] = true
+			files = append(files, heuristic.File{Path: path, Status: status})
+		}
+	}
+
+	add(fileStats.StagedFiles, heuristic.Modified)
+	add(fileStats.UnstagedFiles, heuristic.Modified)
+	add(fileStats.UntrackedFiles, heuristic.Added)
+	return files
+}
+
+// offlineProvider picks the provider for --offline: the configured provider
+// when it runs locally, otherwise a saved local Ollama, otherwise the
+// rule-based generator.
+func offlineProvider(Store *store.StoreMethods, current *store.LLMProvider) (types.LLMProvider, string) {
+	if llm.IsLocal(current.LLM, current.APIKey) {
+		return current.LLM, current.APIKey
+	}
+
+	if ollama, err := Store.LLMKey(types.ProviderOllama); err == nil && llm.IsLocal(ollama.LLM, ollama.APIKey) {
+		pterm.Info.Printf("Offline: using local Ollama instead of %s.\n", current.LLM)
+		return ollama.LLM, ollama.APIKey
+	} else if err != nil {
+		logging.Debug("no local provider saved", "error", err)
+	}
+
+	pterm.Info.Printf("Offline: %s needs network access; using the rule-based generator.\n", current.LLM)
+	return ruleBasedProvider, ""
+}
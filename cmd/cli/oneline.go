@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dfanso/commit-msg/internal/logging"
+	"github.com/dfanso/commit-msg/internal/postprocess"
+	"github.com/dfanso/commit-msg/pkg/types"
+)
+
+// onelineRetries is how many times the provider is asked to shorten a
+// subject over the limit before it is cut at a word boundary.
+const onelineRetries = 2
+
+// generateFunc produces a commit message for the supplied options.
+type generateFunc func(opts *types.GenerationOptions) (*types.GenerationResult, error)
+
+// onelineLimit returns the subject limit for --oneline: limit when positive,
+// otherwise the max_subject_length lint rule.
+func onelineLimit(limit int) int {
+	if limit > 0 {
+		return limit
+	}
+	return loadLintRules().MaxSubjectLength
+}
+
+// withOneline wraps generate so every message is a single subject line of at
+// most limit characters. The prompt asks for one line; a longer subject is
+// sent back to the provider to be shortened, and cut at a word boundary if
+// it is still too long after onelineRetries attempts.
+func withOneline(limit int, generate generateFunc) generateFunc {
+	return func(opts *types.GenerationOptions) (*types.GenerationResult, error) {
+		request := withInstruction(opts, onelineInstruction(limit))
+		result, err := generate(request)
+		if err != nil {
+			return nil, err
+		}
+
+		subject := postprocess.Subject(result.Message)
+		for retry := 1; retry <= onelineRetries && tooLong(subject, limit); retry++ {
+			logging.Debug("shortening subject", "length", utf8.RuneCountInString(subject), "limit", limit, "retry", retry)
+			shorten := withInstruction(request, fmt.Sprintf(
+				"The subject %q is %d characters long. Rewrite it in at most %d characters, keeping its meaning.",
+				subject, utf8.RuneCountInString(subject), limit))
+			// A later attempt number keeps the shortened message out of
+			// the cache, which holds the first answer for these changes.
+			shorten.Attempt = max(request.Attempt, 1) + retry
+			shorter, err := generate(shorten)
+			if err != nil {
+				logging.Debug("shortening failed", "error", err)
+				break
+			}
+			subject = postprocess.Subject(shorter.Message)
+			result = shorter
+		}
+
+		result.Message = postprocess.ShortenSubject(subject, limit)
+		for i, alternative := range result.Alternatives {
+			result.Alternatives[i] = postprocess.ShortenSubject(postprocess.Subject(alternative), limit)
+		}
+		return result, nil
+	}
+}
+
+func onelineInstruction(limit int) string {
+	if limit <= 0 {
+		return "Reply with a single subject line and no body."
+	}
+	return fmt.Sprintf("Reply with a single subject line of at most %d characters and no body.", limit)
+}
+
+func tooLong(subject string, limit int) bool {
+	return limit > 0 && utf8.RuneCountInString(subject) > limit
+}
+
+// withInstruction returns a copy of opts with instruction added to its style
+// instruction.
+func withInstruction(opts *types.GenerationOptions, instruction string) *types.GenerationOptions {
+	clone := types.GenerationOptions{}
+	if opts != nil {
+		clone = *opts
+	}
+	clone.StyleInstruction = strings.TrimSpace(strings.TrimSpace(clone.StyleInstruction) + "\n" + instruction)
+	return &clone
+}
+
+// subjectLimit returns the subject limit --oneline enforces, or zero when
+// it is not set.
+func (o CreateOptions) subjectLimit() int {
+	if !o.Oneline {
+		return 0
+	}
+	return onelineLimit(o.SubjectLimit)
+}
+
+// onelineWarnings returns the review warning for a subject edited past the
+// --oneline limit.
+func onelineWarnings(limit int) func(string) []string {
+	return func(message string) []string {
+		if subject := postprocess.Subject(message); tooLong(subject, limit) {
+			return []string{fmt.Sprintf("Commit message subject line is %d characters (--oneline limit is %d)", utf8.RuneCountInString(subject), limit)}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/dfanso/commit-msg/internal/logging"
+	"github.com/pterm/pterm"
+)
+
+// Stages of generating a commit message, in the order they run.
+const (
+	stageCollect     = "Collecting changes"
+	stageScrub       = "Scrubbing sensitive data"
+	stageBuildPrompt = "Building prompt"
+	stageProvider    = "Calling provider"
+	stagePostProcess = "Post-processing"
+)
+
+var pipelineStages = []string{stageCollect, stageScrub, stageBuildPrompt, stageProvider, stagePostProcess}
+
+// pipelineProgress shows each stage of generation as a numbered step with
+// its own spinner, so users can see which stage is slow. With --verbose the
+// time taken by each stage is shown and logged too.
+type pipelineProgress struct {
+	spinner *pterm.SpinnerPrinter
+	stage   string
+	started time.Time
+	closed  bool
+}
+
+// start completes the running stage, if any, and starts stage. A stage may
+// run again, as when a blocked message is regenerated.
+func (p *pipelineProgress) start(stage string) {
+	if p.closed {
+		return
+	}
+	p.finish(true, "")
+
+	p.stage, p.started = stage, time.Now()
+	spinner, err := pterm.DefaultSpinner.
+		WithSequence("⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏").
+		Start(p.step(stage + "..."))
+	if err != nil {
+		exitf(ExitError, "Failed to start spinner: %v\n", err)
+	}
+	p.spinner = spinner
+}
+
+// update replaces the text next to the spinner of the running stage,
+// keeping its step number.
+func (p *pipelineProgress) update(text string) {
+	if p.spinner != nil {
+		p.spinner.UpdateText(p.step(text))
+	}
+}
+
+// step prefixes text with the position of the running stage, as in
+// "[2/5] Scrubbing sensitive data".
+func (p *pipelineProgress) step(text string) string {
+	return fmt.Sprintf("[%d/%d] %s", slices.Index(pipelineStages, p.stage)+1, len(pipelineStages), text)
+}
+
+// succeed completes the running stage, showing text instead of the stage
+// name when it is not empty, and stops showing later stages.
+func (p *pipelineProgress) succeed(text string) {
+	p.finish(true, text)
+	p.closed = true
+}
+
+// fail marks the running stage as failed with text and stops showing later
+// stages.
+func (p *pipelineProgress) fail(text string) {
+	p.finish(false, text)
+	p.closed = true
+}
+
+func (p *pipelineProgress) finish(ok bool, text string) {
+	if p.spinner == nil {
+		return
+	}
+	elapsed := time.Since(p.started)
+	logging.Debug("stage finished", "stage", p.stage, "ok", ok, "elapsed", elapsed.Round(time.Millisecond))
+
+	if text == "" {
+		text = p.step(p.stage)
+	}
+	if logging.Enabled() {
+		text += fmt.Sprintf(" [%s]", formatStageDuration(elapsed))
+	}
+	if ok {
+		p.spinner.Success(text)
+	} else {
+		p.spinner.Fail(text)
+	}
+	p.spinner = nil
+}
+
+func formatStageDuration(d time.Duration) string {
+	if d < time.Second {
+		return d.Round(time.Millisecond).String()
+	}
+	return d.Round(10 * time.Millisecond).String()
+}
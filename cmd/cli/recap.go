@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/dfanso/commit-msg/cmd/cli/store"
+	"github.com/dfanso/commit-msg/internal/git"
+	"github.com/dfanso/commit-msg/internal/llm"
+	"github.com/dfanso/commit-msg/internal/platform"
+	"github.com/dfanso/commit-msg/pkg/types"
+	"github.com/pterm/pterm"
+)
+
+// recapTemplate is the prompt for commit recap. It replaces the commit
+// prompt, which asks for a single message.
+const recapTemplate = `Write a short standup-style report of the work below{{with .Repository}} in the {{.}} repository{{end}}.
+
+Use these sections, leaving out any with nothing to say:
+Done: one bullet per piece of work finished, grouping related commits.
+In progress: one bullet per piece of uncommitted work.
+Notes: anything a teammate should know, such as follow-ups or risks.
+
+Describe the work in plain words for teammates rather than listing commit
+hashes or file names, and keep each bullet to one line. Reply with only the
+report.{{with .Instructions}}
+
+Additional instructions:
+{{.}}{{end}}
+
+{{.Changes}}`
+
+// RecapOptions controls commit recap.
+type RecapOptions struct {
+	// Since and Until bound the period in any date format git log accepts.
+	Since string
+	Until string
+	// Author limits the report to commits by a matching author; empty means
+	// the configured user.email.
+	Author string
+	// AllAuthors reports commits by everyone.
+	AllAuthors bool
+	// Uncommitted adds the staged, unstaged, and untracked changes.
+	Uncommitted bool
+	// Instruction is extra guidance for the report.
+	Instruction string
+	// RepoPath is the repository to work in; empty means the current
+	// directory.
+	RepoPath string
+	// DryRun displays the prompt without making an API call.
+	DryRun bool
+	// Quiet prints only the report.
+	Quiet bool
+}
+
+// RecapWork asks the default provider for a standup-style report of the
+// commits made in a period, and optionally the uncommitted work, and copies
+// it to the clipboard. The process exits with one of the documented Exit*
+// codes on failure.
+func RecapWork(Store *store.StoreMethods, opts RecapOptions) {
+	setQuietMode(opts.Quiet)
+
+	dir, err := resolveRepoPath(opts.RepoPath)
+	if err != nil {
+		exitf(ExitError, "%v\n", err)
+	}
+	if !git.IsRepository(dir) {
+		exitf(ExitNotRepository, "Not a Git repository: %s\n", dir)
+	}
+
+	repoConfig := &types.RepoConfig{Path: dir, Limits: loadContentLimits()}
+	period := git.Period{Since: opts.Since, Until: opts.Until, Author: opts.Author}
+	switch {
+	case opts.AllAuthors:
+		period.Author = ""
+	case period.Author == "":
+		period.Author = git.ConfigValue(repoConfig, "user.email")
+	}
+
+	count, err := git.CountCommits(repoConfig, period)
+	if err != nil {
+		exitf(ExitError, "Failed to list commits: %v\n", err)
+	}
+
+	var changes strings.Builder
+	if count > 0 {
+		log, err := git.PeriodChanges(repoConfig, period)
+		if err != nil {
+			exitf(ExitError, "Failed to read commits: %v\n", err)
+		}
+		fmt.Fprintf(&changes, "Commits %s (%d):\n%s\n", describePeriod(period), count, log)
+	}
+	uncommitted := false
+	if opts.Uncommitted {
+		set, err := git.CollectChanges(repoConfig)
+		if err != nil {
+			exitf(ExitError, "Failed to get Git changes: %v\n", err)
+		}
+		set.History = ""
+		if len(set.Staged) > 0 || len(set.Unstaged) > 0 || len(set.Untracked) > 0 {
+			uncommitted = true
+			changes.WriteString("Uncommitted work in progress:\n" + set.Prompt())
+		}
+	}
+	if count == 0 && !uncommitted {
+		exitf(ExitNoChanges, "No commits %s.\n", describePeriod(period))
+	}
+	summary := fmt.Sprintf("Summarizing %d commits %s", count, describePeriod(period))
+	if uncommitted {
+		summary += " and uncommitted work"
+	}
+	pterm.Info.Println(summary + ".")
+
+	prompt := truncateLargeDiff(condenseChanges(dir, changes.String()))
+	genOpts := newPromptContext(dir, nil, nil).apply(withAttempt(nil, 1))
+	genOpts.Template = recapTemplate
+	if opts.Instruction != "" {
+		genOpts = withInstruction(genOpts, opts.Instruction)
+	}
+
+	useLLM, err := Store.DefaultLLMKey()
+	if err != nil {
+		exitf(ExitError, "No LLM configured. Run: commit llm setup\n")
+	}
+	config := providerConfig()
+
+	if opts.DryRun {
+		if quietMode {
+			fmt.Println(dryRunPrompt(prompt, genOpts))
+			return
+		}
+		displayDryRunInfo(useLLM.LLM, config, prompt, useLLM.APIKey, genOpts)
+		return
+	}
+
+	provider, err := llm.NewProvider(useLLM.LLM, llm.ProviderOptions{
+		Credential: useLLM.APIKey,
+		Config:     config,
+	})
+	if err != nil {
+		displayProviderError(useLLM.LLM, err)
+		exit(ExitProviderError)
+	}
+
+	spinner, err := pterm.DefaultSpinner.Start(fmt.Sprintf("Writing the recap with %s...", useLLM.LLM))
+	if err != nil {
+		exitf(ExitError, "Failed to start spinner: %v\n", err)
+	}
+	result, err := llm.Generate(context.Background(), provider, prompt, genOpts)
+	if err != nil {
+		spinner.Fail("Failed to write the recap")
+		displayProviderError(useLLM.LLM, err)
+		exit(ExitProviderError)
+	}
+	report := strings.TrimSpace(result.Message)
+	if report == "" {
+		spinner.Fail("Failed to write the recap")
+		exitf(ExitProviderError, "Generated recap is empty\n")
+	}
+	spinner.Success("Recap written")
+
+	if quietMode {
+		fmt.Println(report)
+		return
+	}
+	pterm.Println()
+	pterm.DefaultSection.Println("Recap")
+	fmt.Println(report)
+	pterm.Println()
+	if err := platform.CopyToClipboard(report); err != nil {
+		pterm.Warning.Printf("Could not copy to clipboard: %v\n", err)
+	} else {
+		pterm.Success.Println("Recap copied to clipboard!")
+	}
+}
+
+// describePeriod renders period for messages, as in "since 9am by
+// jane@example.com".
+func describePeriod(period git.Period) string {
+	var parts []string
+	if period.Since != "" {
+		parts = append(parts, "since "+period.Since)
+	}
+	if period.Until != "" {
+		parts = append(parts, "until "+period.Until)
+	}
+	if period.Author != "" {
+		parts = append(parts, "by "+period.Author)
+	}
+	if len(parts) == 0 {
+		return "on this branch"
+	}
+	return strings.Join(parts, " ")
+}
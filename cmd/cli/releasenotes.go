@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/dfanso/commit-msg/cmd/cli/store"
+	"github.com/dfanso/commit-msg/internal/git"
+	"github.com/dfanso/commit-msg/internal/llm"
+	"github.com/dfanso/commit-msg/internal/platform"
+	"github.com/dfanso/commit-msg/pkg/types"
+	"github.com/pterm/pterm"
+)
+
+// releaseNotesTemplate is the prompt for commit release-notes. It replaces
+// the commit prompt, which asks for a single message.
+const releaseNotesTemplate = `Write polished release notes in Markdown for the release made of the commits below{{with .Repository}} in the {{.}} repository{{end}}.
+
+Open with a short "Highlights" section of the two or three most important
+changes, then group the rest under headings such as "New features",
+"Improvements", "Bug fixes", and "Breaking changes", leaving out empty ones.
+Merge related commits into a single entry and reply with only the notes.{{with .Instructions}}
+
+Additional instructions:
+{{.}}{{end}}
+
+{{.Changes}}`
+
+// releaseAudiences holds the guidance for each --audience of
+// commit release-notes.
+var releaseAudiences = map[string]string{
+	"users": "Write for the people who use the software: describe what they can now do or what works better, in plain language " +
+		"without code, file names, or commit hashes. Leave out refactoring, tests, CI, and other changes they cannot notice, " +
+		"and call out anything they must do when upgrading.",
+	"developers": "Write for developers who build on or contribute to the project: cover API, configuration, dependency, and build " +
+		"changes and internal refactors worth knowing, name the affected packages or functions where it helps, and give " +
+		"migration steps for breaking changes.",
+}
+
+// releaseAudienceNames returns the accepted values of --audience.
+func releaseAudienceNames() []string {
+	audiences := make([]string, 0, len(releaseAudiences))
+	for audience := range releaseAudiences {
+		audiences = append(audiences, audience)
+	}
+	slices.Sort(audiences)
+	return audiences
+}
+
+// ReleaseNotesOptions controls commit release-notes.
+type ReleaseNotesOptions struct {
+	// Range selects the commits of the release, such as "v1.4.0..v1.5.0".
+	// A single revision means the commits since it.
+	Range string
+	// Audience is a key of releaseAudiences.
+	Audience string
+	// Instruction is extra guidance for the notes.
+	Instruction string
+	// Output is a file the notes are written to, if set.
+	Output string
+	// RepoPath is the repository to work in; empty means the current
+	// directory.
+	RepoPath string
+	// DryRun displays the prompt without making an API call.
+	DryRun bool
+	// Quiet prints only the notes.
+	Quiet bool
+}
+
+// CreateReleaseNotes asks the default provider for release notes covering
+// the commits in a range, written for users or developers, and prints them,
+// copies them to the clipboard, or writes them to a file. The process exits
+// with one of the documented Exit* codes on failure.
+func CreateReleaseNotes(Store *store.StoreMethods, opts ReleaseNotesOptions) {
+	setQuietMode(opts.Quiet)
+
+	guidance, ok := releaseAudiences[opts.Audience]
+	if !ok {
+		exitf(ExitError, "Unknown audience %q; choose one of: %s\n", opts.Audience, strings.Join(releaseAudienceNames(), ", "))
+	}
+
+	dir, err := resolveRepoPath(opts.RepoPath)
+	if err != nil {
+		exitf(ExitError, "%v\n", err)
+	}
+	if !git.IsRepository(dir) {
+		exitf(ExitNotRepository, "Not a Git repository: %s\n", dir)
+	}
+
+	repoConfig := &types.RepoConfig{Path: dir, Limits: loadContentLimits()}
+	log, count, err := git.ReleaseLog(repoConfig, opts.Range)
+	if err != nil {
+		exitf(ExitError, "Failed to read commits: %v\n", err)
+	}
+	if count == 0 {
+		exitf(ExitNoChanges, "No commits in %s.\n", opts.Range)
+	}
+	pterm.Info.Printf("Writing %s release notes for %d commits in %s.\n", opts.Audience, count, opts.Range)
+
+	changes := truncateLargeDiff(fmt.Sprintf("Commits in %s (%d):\n%s", opts.Range, count, log))
+	genOpts := newPromptContext(dir, nil, nil).apply(withAttempt(nil, 1))
+	genOpts.Template = releaseNotesTemplate
+	genOpts = withInstruction(genOpts, guidance)
+	if opts.Instruction != "" {
+		genOpts = withInstruction(genOpts, opts.Instruction)
+	}
+
+	useLLM, err := Store.DefaultLLMKey()
+	if err != nil {
+		exitf(ExitError, "No LLM configured. Run: commit llm setup\n")
+	}
+	config := providerConfig()
+
+	if opts.DryRun {
+		if quietMode {
+			fmt.Println(dryRunPrompt(changes, genOpts))
+			return
+		}
+		displayDryRunInfo(useLLM.LLM, config, changes, useLLM.APIKey, genOpts)
+		return
+	}
+
+	provider, err := llm.NewProvider(useLLM.LLM, llm.ProviderOptions{
+		Credential: useLLM.APIKey,
+		Config:     config,
+	})
+	if err != nil {
+		displayProviderError(useLLM.LLM, err)
+		exit(ExitProviderError)
+	}
+
+	spinner, err := pterm.DefaultSpinner.Start(fmt.Sprintf("Writing release notes with %s...", useLLM.LLM))
+	if err != nil {
+		exitf(ExitError, "Failed to start spinner: %v\n", err)
+	}
+	result, err := llm.Generate(context.Background(), provider, changes, genOpts)
+	if err != nil {
+		spinner.Fail("Failed to write release notes")
+		displayProviderError(useLLM.LLM, err)
+		exit(ExitProviderError)
+	}
+	notes := strings.TrimSpace(result.Message)
+	if notes == "" {
+		spinner.Fail("Failed to write release notes")
+		exitf(ExitProviderError, "Generated release notes are empty\n")
+	}
+	spinner.Success("Release notes written")
+
+	if opts.Output != "" {
+		if err := os.WriteFile(platform.ResolvePath(opts.Output), []byte(notes+"\n"), 0o644); err != nil {
+			exitf(ExitError, "Failed to write %s: %v\n", opts.Output, err)
+		}
+		pterm.Success.Printf("Release notes written to %s\n", opts.Output)
+		return
+	}
+	if quietMode {
+		fmt.Println(notes)
+		return
+	}
+	pterm.Println()
+	pterm.DefaultSection.Println("Release Notes")
+	fmt.Println(notes)
+	pterm.Println()
+	if err := platform.CopyToClipboard(notes); err != nil {
+		pterm.Warning.Printf("Could not copy to clipboard: %v\n", err)
+	} else {
+		pterm.Success.Println("Release notes copied to clipboard!")
+	}
+}
@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/dfanso/commit-msg/cmd/cli/store"
+	"github.com/dfanso/commit-msg/internal/display"
+	"github.com/dfanso/commit-msg/internal/git"
+	"github.com/dfanso/commit-msg/internal/llm"
+	"github.com/dfanso/commit-msg/pkg/types"
+	"github.com/pterm/pterm"
+)
+
+// RewriteOptions controls commit rewrite.
+type RewriteOptions struct {
+	// Rev is the commit whose message is improved; empty means HEAD.
+	Rev string
+	// RepoPath is the repository to work in; empty means the current
+	// directory.
+	RepoPath string
+	// Yes rewords the commit without asking.
+	Yes bool
+	// DryRun displays the prompt without making an API call.
+	DryRun bool
+	// Quiet prints only the improved message.
+	Quiet bool
+}
+
+// RewriteCommitMsg asks the default provider to improve the message of an
+// existing commit, shows the old and new messages side by side, and rewords
+// the commit when confirmed. The process exits with one of the documented
+// Exit* codes on failure.
+func RewriteCommitMsg(Store *store.StoreMethods, opts RewriteOptions) {
+	setQuietMode(opts.Quiet)
+
+	dir, err := resolveRepoPath(opts.RepoPath)
+	if err != nil {
+		exitf(ExitError, "%v\n", err)
+	}
+	if !git.IsRepository(dir) {
+		exitf(ExitNotRepository, "Not a Git repository: %s\n", dir)
+	}
+
+	rev := opts.Rev
+	if rev == "" {
+		rev = "HEAD"
+	}
+	repoConfig := &types.RepoConfig{Path: dir}
+	hash, err := git.ResolveCommit(repoConfig, rev)
+	if err != nil {
+		exitf(ExitError, "%v\n", err)
+	}
+	shortHash := hash[:min(len(hash), 7)]
+
+	oldMessage, err := git.CommitMessage(repoConfig, hash)
+	if err != nil {
+		exitf(ExitError, "Failed to read commit message: %v\n", err)
+	}
+	changes, err := git.CommitChanges(repoConfig, hash)
+	if err != nil {
+		exitf(ExitError, "Failed to read commit changes: %v\n", err)
+	}
+	changes = truncateLargeDiff(condenseChanges(dir, changes))
+
+	prompt := newPromptContext(dir, nil, nil)
+	genOpts := prompt.apply(withAttempt(nil, 1))
+	genOpts.PreviousMessage = oldMessage
+
+	useLLM, err := Store.DefaultLLMKey()
+	if err != nil {
+		exitf(ExitError, "No LLM configured. Run: commit llm setup\n")
+	}
+	config := providerConfig()
+
+	if opts.DryRun {
+		if quietMode {
+			fmt.Println(dryRunPrompt(changes, genOpts))
+			return
+		}
+		displayDryRunInfo(useLLM.LLM, config, changes, useLLM.APIKey, genOpts)
+		return
+	}
+
+	provider, err := llm.NewProvider(useLLM.LLM, llm.ProviderOptions{
+		Credential: useLLM.APIKey,
+		Config:     config,
+	})
+	if err != nil {
+		displayProviderError(useLLM.LLM, err)
+		exit(ExitProviderError)
+	}
+
+	spinner, err := pterm.DefaultSpinner.Start(fmt.Sprintf("Rewriting the message of %s with %s...", shortHash, useLLM.LLM))
+	if err != nil {
+		exitf(ExitError, "Failed to start spinner: %v\n", err)
+	}
+	generate := withBlocklist(func(genOpts *types.GenerationOptions) (*types.GenerationResult, error) {
+		return generateMessageWithCache(context.Background(), provider, Store, useLLM.LLM, changes, genOpts)
+	})
+	generated, err := generate(genOpts)
+	if err != nil {
+		spinner.Fail("Failed to rewrite commit message")
+		displayProviderError(useLLM.LLM, err)
+		exit(ExitProviderError)
+	}
+	spinner.Success("Commit message rewritten (" + display.GenerationSummary(generated) + ")")
+
+	newMessage := formatMessage(correctMessage(dir, generated.Message))
+	if newMessage == "" {
+		exitf(ExitProviderError, "Generated commit message is empty\n")
+	}
+	if quietMode {
+		fmt.Println(newMessage)
+	} else {
+		pterm.Println()
+		display.ShowMessageDiff(oldMessage, newMessage)
+		pterm.Println()
+	}
+
+	if newMessage == strings.TrimSpace(oldMessage) {
+		pterm.Info.Println("The message is already in good shape; nothing to change.")
+		return
+	}
+
+	if !opts.Yes {
+		if quietMode {
+			return
+		}
+		question := fmt.Sprintf("Reword %s with the new message?", shortHash)
+		if head, err := git.ResolveCommit(repoConfig, "HEAD"); err == nil && head != hash {
+			question = fmt.Sprintf("Reword %s? Every later commit will be rewritten.", shortHash)
+		}
+		confirm, err := pterm.DefaultInteractiveConfirm.
+			WithDefaultValue(false).
+			Show(question)
+		if err != nil {
+			exitf(ExitError, "Failed to get confirmation: %v\n", err)
+		}
+		if !confirm {
+			pterm.Info.Println("Left the commit unchanged.")
+			exit(ExitCancelled)
+		}
+	}
+
+	if err := git.Reword(repoConfig, hash, newMessage); err != nil {
+		exitf(ExitError, "Failed to reword %s: %v\n", shortHash, err)
+	}
+	pterm.Success.Printf("Reworded %s\n", shortHash)
+}
@@ -1,9 +1,16 @@
 package cmd
 
 import (
-	"os"
+	"fmt"
 
 	"github.com/dfanso/commit-msg/cmd/cli/store"
+	"github.com/dfanso/commit-msg/internal/apiserver"
+	"github.com/dfanso/commit-msg/internal/bench"
+	"github.com/dfanso/commit-msg/internal/buildinfo"
+	"github.com/dfanso/commit-msg/internal/logging"
+	"github.com/dfanso/commit-msg/internal/testrun"
+	"github.com/dfanso/commit-msg/internal/watch"
+	StoreUtils "github.com/dfanso/commit-msg/utils"
 	"github.com/spf13/cobra"
 )
 
@@ -29,7 +36,48 @@ var rootCmd = &cobra.Command{
 
 	# Generate a commit message and automatically commit it
 	commit . --auto
+
+	# Generate a commit message for another repository
+	commit . ../other-repo
+	commit . --repo ../other-repo
 `,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		profile, err := cmd.Flags().GetString("profile")
+		if err != nil {
+			return err
+		}
+		StoreUtils.SetProfile(profile)
+		if _, err := StoreUtils.Profile(); err != nil {
+			return err
+		}
+
+		configureHTTPClients()
+		configureProviderModels()
+		configureOllama()
+		configureHuggingFace()
+		configureVertex()
+		configureRedaction()
+
+		verbose, err := cmd.Flags().GetBool("verbose")
+		if err != nil {
+			return err
+		}
+		logFile, err := cmd.Flags().GetString("log-file")
+		if err != nil {
+			return err
+		}
+		if !verbose && logFile == "" {
+			return nil
+		}
+		if err := logging.Init(logFile); err != nil {
+			return fmt.Errorf("failed to open log file: %w", err)
+		}
+		logging.Debug("command started", "command", cmd.CommandPath(), "args", args)
+		return nil
+	},
+	PersistentPostRun: func(cmd *cobra.Command, args []string) {
+		logging.Close()
+	},
 	// Uncomment the following line if your bare application
 	// has an action associated with it:
 	// Run: func(cmd *cobra.Command, args []string) { },
@@ -40,8 +88,9 @@ var rootCmd = &cobra.Command{
 func Execute() {
 	err := rootCmd.Execute()
 	if err != nil {
-		os.Exit(1)
+		exit(1)
 	}
+	flushCache()
 }
 
 var llmCmd = &cobra.Command{
@@ -53,6 +102,13 @@ var llmSetupCmd = &cobra.Command{
 	Use:   "setup",
 	Short: "Setup your LLM provider and API key",
 	RunE: func(cmd *cobra.Command, args []string) error {
+		plugin, err := cmd.Flags().GetString("plugin")
+		if err != nil {
+			return err
+		}
+		if plugin != "" {
+			return SetupPlugin(Store, plugin)
+		}
 		return SetupLLM(Store)
 	},
 }
@@ -65,6 +121,89 @@ var llmUpdateCmd = &cobra.Command{
 	},
 }
 
+var llmStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Check that each saved provider is reachable and its key is valid",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return CheckLLMStatus(Store)
+	},
+}
+
+var llmUseCmd = &cobra.Command{
+	Use:   "use <provider>",
+	Short: "Make a saved provider the default",
+	Example: `  commit llm use groq
+  commit . --provider ollama   # or just for one run`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: savedProviderNames,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return UseLLM(args[0])
+	},
+}
+
+var llmMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Move saved credentials between the OS keyring and an encrypted file",
+	Long: `Move the current profile's provider keys and issue tracker credentials to
+another backend. Every entry is copied and read back before the profile
+switches to the new backend, and only then are the originals removed.`,
+	Example: `  commit llm migrate --to file
+  commit llm migrate --to keyring --profile work`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		to, err := cmd.Flags().GetString("to")
+		if err != nil {
+			return err
+		}
+		return MigrateCredentials(Store, to)
+	},
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Maintain the config file",
+}
+
+var configRepairCmd = &cobra.Command{
+	Use:   "repair",
+	Short: "Recover an unreadable config from its backup or from saved credentials",
+	Long: `Every change to config.json keeps the previous version in config.json.bak.
+If config.json is missing, empty, or unreadable, repair restores the backup,
+or when there is none rebuilds the list of providers from the credentials
+saved in the keyring. The broken file is kept as config.json.corrupt.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return RepairConfig(Store)
+	},
+}
+
+var configPathCmd = &cobra.Command{
+	Use:   "path [name]",
+	Short: "Print where config, cache, and other files are kept",
+	Long: `Print the resolved location of every file and directory commit-msg uses
+for the selected profile. Given a name, such as config or cache, only that
+path is printed, for use in scripts.
+
+The config lives in %LOCALAPPDATA%\commit-msg on Windows,
+~/Library/Application Support/commit-msg on macOS, and
+$XDG_CONFIG_HOME/commit-msg elsewhere. Cached messages live in the user
+cache directory ($XDG_CACHE_HOME/commit-msg, ~/Library/Caches/commit-msg, or
+%LOCALAPPDATA%\commit-msg\cache). Set COMMIT_MSG_CONFIG_DIR to keep
+everything in one directory, such as for a portable install.`,
+	Example: `  commit config path
+  commit config path cache
+  cd "$(commit config path config-dir)"`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := ""
+		if len(args) == 1 {
+			name = args[0]
+		}
+		return ShowConfigPaths(name)
+	},
+}
+
 var cacheCmd = &cobra.Command{
 	Use:   "cache",
 	Short: "Manage commit message cache",
@@ -75,6 +214,16 @@ var cacheStatsCmd = &cobra.Command{
 	Use:   "stats",
 	Short: "Show cache statistics",
 	RunE: func(cmd *cobra.Command, args []string) error {
+		reset, err := cmd.Flags().GetBool("reset-stats")
+		if err != nil {
+			return err
+		}
+
+		if reset {
+			if err := ResetCacheStats(Store); err != nil {
+				return err
+			}
+		}
 		return ShowCacheStats(Store)
 	},
 }
@@ -87,6 +236,19 @@ var cacheClearCmd = &cobra.Command{
 	},
 }
 
+var cacheMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Copy the cache and history into the SQLite store",
+	Long: `Copy cache.json and history.json into commit-msg.db next to config.json,
+replacing what the database held. Then set "backend" to "sqlite" in the
+"storage" section of config.json to keep the cache and history there, which
+stays fast with thousands of entries. Requires a build made with -tags sqlite.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return MigrateStorage()
+	},
+}
+
 var cacheCleanupCmd = &cobra.Command{
 	Use:   "cleanup",
 	Short: "Remove old cached messages",
@@ -95,46 +257,1088 @@ var cacheCleanupCmd = &cobra.Command{
 	},
 }
 
-var creatCommitMsg = &cobra.Command{
-	Use:   ".",
-	Short: "Create Commit Message",
+var issueCmd = &cobra.Command{
+	Use:   "issue",
+	Short: "Manage issue tracker integration",
+	Long: `Store API tokens for GitHub, GitLab, or Jira so that --with-issue can add
+the title and description of the issue referenced by the branch name to the
+prompt.`,
+}
+
+var issueSetupCmd = &cobra.Command{
+	Use:   "setup",
+	Short: "Setup an issue tracker API token",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		dryRun, err := cmd.Flags().GetBool("dry-run")
+		return SetupIssueTracker(Store)
+	},
+}
+
+var issueRemoveCmd = &cobra.Command{
+	Use:   "remove",
+	Short: "Remove stored issue tracker credentials",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return RemoveIssueTracker(Store)
+	},
+}
+
+var styleCmd = &cobra.Command{
+	Use:   "style",
+	Short: "Manage style presets and the style learned from your accepted messages",
+	Long: `Messages you accept in the review screen are remembered locally, and the
+best recent ones are shown to the LLM as examples so new messages match your
+style.
+
+Named style presets saved with commit style add appear in the review screen's
+style menu and can be selected with commit . --style <name>.`,
+}
+
+var styleAddCmd = &cobra.Command{
+	Use:     "add <name> <instruction>",
+	Short:   "Save a named style preset",
+	Example: `  commit style add jira "Prefix the subject with {{ticket}}: and keep it under 60 characters."`,
+	Args:    cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return AddStyle(args[0], args[1])
+	},
+}
+
+var styleListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the built-in and saved style presets",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return ListStyles()
+	},
+}
+
+var styleRemoveCmd = &cobra.Command{
+	Use:   "remove <name>",
+	Short: "Remove a saved style preset",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return RemoveStyle(args[0])
+	},
+}
+
+var styleResetCmd = &cobra.Command{
+	Use:   "reset",
+	Short: "Forget all learned example messages",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return ResetStyle()
+	},
+}
+
+var feedbackCmd = &cobra.Command{
+	Use:       "feedback good|bad",
+	Short:     "Rate the last generated commit message",
+	Long:      `Rate the most recently generated commit message. Ratings are stored locally with the provider, model, and prompt hash and summarised by: commit usage`,
+	Args:      cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	ValidArgs: []string{"good", "bad"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return RateLastMessage(args[0])
+	},
+}
+
+var usageCmd = &cobra.Command{
+	Use:   "usage",
+	Short: "Show per-provider usage and message quality ratings",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return ShowUsage()
+	},
+}
+
+var telemetryCmd = &cobra.Command{
+	Use:   "telemetry",
+	Short: "Show opt-in local metrics on provider latency and failures",
+	Long: `Show the metrics recorded for each provider: request latency, failure
+rate, how often messages were regenerated, and cache hits. Telemetry is off
+until enabled with: commit telemetry enable
+
+Metrics stay in telemetry.json next to config.json and are never sent
+anywhere. Only the provider, the model, timings, and counts are kept; diffs,
+prompts, messages, and repository names are not.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return ShowTelemetry()
+	},
+}
+
+var telemetryEnableCmd = &cobra.Command{
+	Use:   "enable",
+	Short: "Start recording provider metrics locally",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return SetTelemetry(true)
+	},
+}
+
+var telemetryDisableCmd = &cobra.Command{
+	Use:   "disable",
+	Short: "Stop recording provider metrics",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return SetTelemetry(false)
+	},
+}
+
+var telemetryExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Write the recorded metrics as JSON",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output, err := cmd.Flags().GetString("output")
 		if err != nil {
 			return err
 		}
+		return ExportTelemetry(output)
+	},
+}
 
-		autoCommit, err := cmd.Flags().GetBool("auto")
+var telemetryResetCmd = &cobra.Command{
+	Use:   "reset",
+	Short: "Delete the recorded metrics",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return ResetTelemetry()
+	},
+}
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the environment and print a report for bug reports",
+	Long: `Print the commit-msg version, Go runtime, and git version, check that the
+OS keyring, config.json, cache, history, and clipboard work, and check that
+each saved provider is reachable and accepts its key. Paste the report into
+bug reports; it contains no API keys.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		offline, err := cmd.Flags().GetBool("offline")
+		if err != nil {
+			return err
+		}
+
+		asJSON, err := cmd.Flags().GetBool("json")
+		if err != nil {
+			return err
+		}
+
+		return RunDoctor(Store, offline, asJSON)
+	},
+}
+
+var undoCmd = &cobra.Command{
+	Use:   "undo",
+	Short: "Undo the last commit made with --auto",
+	Long: `Undo the HEAD commit when it was made by commit --auto, like
+git reset --soft HEAD~1: the changes stay staged and the message is copied to
+the clipboard so you can edit it and commit again. Commits made any other way,
+and commits already on a remote branch, are refused unless --force is given.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		repoPath, err := cmd.Flags().GetString("repo")
+		if err != nil {
+			return err
+		}
+
+		force, err := cmd.Flags().GetBool("force")
+		if err != nil {
+			return err
+		}
+
+		quiet, err := cmd.Flags().GetBool("quiet")
 		if err != nil {
 			return err
 		}
-		CreateCommitMsg(Store, dryRun, autoCommit)
+
+		setQuietMode(quiet)
+		UndoAutoCommit(UndoOptions{RepoPath: repoPath, Force: force})
 		return nil
 	},
 }
 
-func init() {
-	// Here you will define your flags and configuration settings.
-	// Cobra supports persistent flags, which, if defined here,
-	// will be global for your application.
+var benchCmd = &cobra.Command{
+	Use:    "bench",
+	Short:  "Time prompt building and scrubbing on a synthetic repository",
+	Hidden: true,
+	Long: `Write a synthetic repository with --files changed files of --lines changed
+lines each, then time collecting the changes, scrubbing them, hashing them for
+the cache, and building the prompt, reporting the median of --runs runs. Each
+stage has a budget that grows with the size of the diff; the command exits
+with status 1 when any stage is over it. No provider is contacted.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		files, err := cmd.Flags().GetInt("files")
+		if err != nil {
+			return err
+		}
 
-	// rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.commit-msg.yaml)")
+		lines, err := cmd.Flags().GetInt("lines")
+		if err != nil {
+			return err
+		}
 
-	// Cobra also supports local flags, which will only run
-	// when this action is called directly.
+		runs, err := cmd.Flags().GetInt("runs")
+		if err != nil {
+			return err
+		}
 
-	rootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+		dir, err := cmd.Flags().GetString("dir")
+		if err != nil {
+			return err
+		}
 
-	// Add --dry-run and --auto as persistent flags so they show in top-level help
-	rootCmd.PersistentFlags().Bool("dry-run", false, "Preview the prompt that would be sent to the LLM without making an API call")
-	rootCmd.PersistentFlags().Bool("auto", false, "Automatically commit with the generated message")
+		RunBenchmark(bench.Options{Files: files, Lines: lines, Runs: runs, Dir: dir})
+		return nil
+	},
+}
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Browse and copy previously generated commit messages",
+	Long: `List the commit messages you accepted, newest first, with when, where, and
+by which provider they were generated. Use --copy with a number from the
+listing to put that message on the clipboard again, for example after the
+commit that used it was amended away. Set "record_rejected" in the "history"
+section of config.json to also keep the candidates you did not accept.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		search, err := cmd.Flags().GetString("search")
+		if err != nil {
+			return err
+		}
+
+		limit, err := cmd.Flags().GetInt("limit")
+		if err != nil {
+			return err
+		}
+
+		copyNumber, err := cmd.Flags().GetInt("copy")
+		if err != nil {
+			return err
+		}
+
+		rejected, err := cmd.Flags().GetBool("rejected")
+		if err != nil {
+			return err
+		}
+
+		clearHistory, err := cmd.Flags().GetBool("clear")
+		if err != nil {
+			return err
+		}
+
+		quiet, err := cmd.Flags().GetBool("quiet")
+		if err != nil {
+			return err
+		}
+
+		setQuietMode(quiet)
+		return ShowHistory(HistoryOptions{
+			Search:   search,
+			Limit:    limit,
+			Copy:     copyNumber,
+			Rejected: rejected,
+			Clear:    clearHistory,
+		})
+	},
+}
+
+var lintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Check a commit message against the lint rules",
+	Long: `Check a commit message from --file, --last (the HEAD commit), or standard
+input against the rules in the "lint" section of config.json: subject and body
+line length, imperative mood, trailing period, and allowed conventional commit
+types. Exits with status 1 when a rule is violated, so it works as a
+commit-msg hook:
+
+	echo 'commit lint --file "$1"' >> .git/hooks/commit-msg`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		file, err := cmd.Flags().GetString("file")
+		if err != nil {
+			return err
+		}
+
+		last, err := cmd.Flags().GetBool("last")
+		if err != nil {
+			return err
+		}
+
+		repoPath, err := cmd.Flags().GetString("repo")
+		if err != nil {
+			return err
+		}
+
+		quiet, err := cmd.Flags().GetBool("quiet")
+		if err != nil {
+			return err
+		}
+
+		setQuietMode(quiet)
+		LintMessage(LintOptions{File: file, Last: last, RepoPath: repoPath})
+		return nil
+	},
+}
+
+var rewriteCmd = &cobra.Command{
+	Use:   "rewrite [commit]",
+	Short: "Improve the message of an existing commit",
+	Long: `Send the changes and message of an existing commit (HEAD by default) to the
+default LLM and ask for a better message that follows your prompt template and
+learned style. The old and new messages are shown as a diff, and the commit is
+reworded when you confirm: HEAD is amended, and older commits are reworded
+with a rebase that rewrites every later commit.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		repoPath, err := cmd.Flags().GetString("repo")
+		if err != nil {
+			return err
+		}
+
+		yes, err := cmd.Flags().GetBool("yes")
+		if err != nil {
+			return err
+		}
+
+		dryRun, err := cmd.Flags().GetBool("dry-run")
+		if err != nil {
+			return err
+		}
+
+		quiet, err := cmd.Flags().GetBool("quiet")
+		if err != nil {
+			return err
+		}
+
+		rev := ""
+		if len(args) == 1 {
+			rev = args[0]
+		}
+
+		RewriteCommitMsg(Store, RewriteOptions{
+			Rev:      rev,
+			RepoPath: repoPath,
+			Yes:      yes,
+			DryRun:   dryRun,
+			Quiet:    quiet,
+		})
+		return nil
+	},
+}
+
+var fixupCmd = &cobra.Command{
+	Use:   "fixup <commit|search>",
+	Short: "Write a fixup! or squash! message for an earlier commit",
+	Long: `Write a message for the staged changes that fixes an earlier commit, named
+by a revision or by text from its message (the most recent match wins). The
+subject is "fixup! <target subject>" (or "squash! ..." with --squash) so that
+git rebase -i --autosquash folds the commit into its target, and the default
+LLM writes a short body explaining the fix. With --commit the staged changes
+are committed with the message.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		repoPath, err := cmd.Flags().GetString("repo")
+		if err != nil {
+			return err
+		}
+
+		squash, err := cmd.Flags().GetBool("squash")
+		if err != nil {
+			return err
+		}
+
+		commit, err := cmd.Flags().GetBool("commit")
+		if err != nil {
+			return err
+		}
+
+		dryRun, err := cmd.Flags().GetBool("dry-run")
+		if err != nil {
+			return err
+		}
+
+		quiet, err := cmd.Flags().GetBool("quiet")
+		if err != nil {
+			return err
+		}
+
+		CreateFixupMsg(Store, FixupOptions{
+			Target:   args[0],
+			Squash:   squash,
+			Commit:   commit,
+			RepoPath: repoPath,
+			DryRun:   dryRun,
+			Quiet:    quiet,
+		})
+		return nil
+	},
+}
+
+var stashCmd = &cobra.Command{
+	Use:   "stash",
+	Short: "Stash your changes under a generated description",
+	Long: `Ask the default LLM for a one-line description of your staged and unstaged
+changes and stash them with git stash push -m "<description>", so git stash
+list says what each entry holds. Untracked files are stashed too with
+--include-untracked.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		repoPath, err := cmd.Flags().GetString("repo")
+		if err != nil {
+			return err
+		}
+
+		includeUntracked, err := cmd.Flags().GetBool("include-untracked")
+		if err != nil {
+			return err
+		}
+
+		dryRun, err := cmd.Flags().GetBool("dry-run")
+		if err != nil {
+			return err
+		}
+
+		quiet, err := cmd.Flags().GetBool("quiet")
+		if err != nil {
+			return err
+		}
+
+		StashChanges(Store, StashOptions{
+			IncludeUntracked: includeUntracked,
+			RepoPath:         repoPath,
+			DryRun:           dryRun,
+			Quiet:            quiet,
+		})
+		return nil
+	},
+}
+
+var notesCmd = &cobra.Command{
+	Use:   "notes",
+	Short: "Read the explanations attached to commits",
+	Long: `Read the longer explanations that commit . --auto --with-note attaches to
+commits as git notes in refs/notes/gocommit.`,
+}
+
+var notesShowCmd = &cobra.Command{
+	Use:   "show [commit]",
+	Short: "Show the explanation attached to a commit",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		repoPath, err := cmd.Flags().GetString("repo")
+		if err != nil {
+			return err
+		}
+
+		rev := ""
+		if len(args) == 1 {
+			rev = args[0]
+		}
+		return ShowNote(repoPath, rev)
+	},
+}
+
+var recapCmd = &cobra.Command{
+	Use:   "recap",
+	Short: "Summarize recent work as a standup-style report",
+	Long: `Send the commits you made in a period (today by default) to the default LLM
+and get back a short standup-style report of what was done, copied to the
+clipboard. --since and --until take any date git log understands, such as
+"9am", "yesterday", or "2 days ago". Add --uncommitted to include the work
+not yet committed.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		since, err := cmd.Flags().GetString("since")
+		if err != nil {
+			return err
+		}
+
+		until, err := cmd.Flags().GetString("until")
+		if err != nil {
+			return err
+		}
+
+		author, err := cmd.Flags().GetString("author")
+		if err != nil {
+			return err
+		}
+
+		allAuthors, err := cmd.Flags().GetBool("all-authors")
+		if err != nil {
+			return err
+		}
+
+		uncommitted, err := cmd.Flags().GetBool("uncommitted")
+		if err != nil {
+			return err
+		}
+
+		instruction, err := cmd.Flags().GetString("instruction")
+		if err != nil {
+			return err
+		}
+
+		repoPath, err := cmd.Flags().GetString("repo")
+		if err != nil {
+			return err
+		}
+
+		dryRun, err := cmd.Flags().GetBool("dry-run")
+		if err != nil {
+			return err
+		}
+
+		quiet, err := cmd.Flags().GetBool("quiet")
+		if err != nil {
+			return err
+		}
+
+		RecapWork(Store, RecapOptions{
+			Since:       since,
+			Until:       until,
+			Author:      author,
+			AllAuthors:  allAuthors,
+			Uncommitted: uncommitted,
+			Instruction: instruction,
+			RepoPath:    repoPath,
+			DryRun:      dryRun,
+			Quiet:       quiet,
+		})
+		return nil
+	},
+}
+
+var releaseNotesCmd = &cobra.Command{
+	Use:   "release-notes <range>",
+	Short: "Write release notes for the commits between two refs",
+	Long: `Send the messages and file statistics of the commits in <range>, such as
+v1.4.0..v1.5.0, to the default LLM and get back polished Markdown release
+notes with highlights and categorized changes. A single ref means the
+commits since it. --audience users (the default) keeps the notes to visible
+changes in plain language; --audience developers covers APIs, configuration,
+and migration steps.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		audience, err := cmd.Flags().GetString("audience")
+		if err != nil {
+			return err
+		}
+
+		instruction, err := cmd.Flags().GetString("instruction")
+		if err != nil {
+			return err
+		}
+
+		output, err := cmd.Flags().GetString("output")
+		if err != nil {
+			return err
+		}
+
+		repoPath, err := cmd.Flags().GetString("repo")
+		if err != nil {
+			return err
+		}
+
+		dryRun, err := cmd.Flags().GetBool("dry-run")
+		if err != nil {
+			return err
+		}
+
+		quiet, err := cmd.Flags().GetBool("quiet")
+		if err != nil {
+			return err
+		}
+
+		CreateReleaseNotes(Store, ReleaseNotesOptions{
+			Range:       args[0],
+			Audience:    audience,
+			Instruction: instruction,
+			Output:      output,
+			RepoPath:    repoPath,
+			DryRun:      dryRun,
+			Quiet:       quiet,
+		})
+		return nil
+	},
+}
+
+var watchCmd = &cobra.Command{
+	Use:   "watch [path]",
+	Short: "Keep a draft commit message updated as you work",
+	Long: `Watch the working tree and regenerate a draft commit message whenever it
+changes. The draft is written to .git/COMMIT_DRAFT (or --output) so it is ready
+when you are: git commit -F .git/COMMIT_DRAFT`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		debounce, err := cmd.Flags().GetDuration("debounce")
+		if err != nil {
+			return err
+		}
+
+		output, err := cmd.Flags().GetString("output")
+		if err != nil {
+			return err
+		}
+
+		repoPath := ""
+		if len(args) == 1 {
+			repoPath = args[0]
+		}
+
+		return WatchDraft(Store, WatchOptions{
+			RepoPath: repoPath,
+			Debounce: debounce,
+			Output:   output,
+		})
+	},
+}
+
+var ciCmd = &cobra.Command{
+	Use:   "ci",
+	Short: "Generate a message non-interactively for CI jobs and bots",
+	Long: `Generate a commit message for automated workflows, such as commits made by
+dependency-update bots. The changes come from --diff, --range, or the working
+tree. Credentials are read only from the environment (OPENAI_API_KEY,
+CLAUDE_API_KEY, GEMINI_API_KEY, GROK_API_KEY, GROQ_API_KEY, HF_TOKEN,
+COHERE_API_KEY, TOGETHER_API_KEY, FIREWORKS_API_KEY,
+GOOGLE_APPLICATION_CREDENTIALS, or OLLAMA_URL), nothing is ever prompted for, and the result or error is printed as JSON.
+The run is abandoned after --timeout with exit code 6.`,
+	Example: `  COMMIT_MSG_PROVIDER=openai commit ci --range origin/main..HEAD
+  git diff --cached | commit ci --provider groq --diff - | jq -r .message`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		provider, err := cmd.Flags().GetString("provider")
+		if err != nil {
+			return err
+		}
+
+		diffFile, err := cmd.Flags().GetString("diff")
+		if err != nil {
+			return err
+		}
+
+		revRange, err := cmd.Flags().GetString("range")
+		if err != nil {
+			return err
+		}
+
+		repoPath, err := cmd.Flags().GetString("repo")
+		if err != nil {
+			return err
+		}
+
+		timeout, err := cmd.Flags().GetDuration("timeout")
+		if err != nil {
+			return err
+		}
+
+		instruction, err := cmd.Flags().GetString("instruction")
+		if err != nil {
+			return err
+		}
+
+		RunCI(CIOptions{
+			Provider:    provider,
+			DiffFile:    diffFile,
+			Range:       revRange,
+			RepoPath:    repoPath,
+			Timeout:     timeout,
+			Instruction: instruction,
+		})
+		return nil
+	},
+}
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Run commit-msg as a server for editors and agents",
+	Long: `Expose commit-msg to other tools. With --mcp, the Model Context Protocol is
+served over stdin/stdout with the tools generate_commit_message, get_changes,
+and scrub_diff. With --http, a local JSON API is served with the endpoints
+POST /generate, GET /changes, and GET /cache/stats.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		mcp, err := cmd.Flags().GetBool("mcp")
+		if err != nil {
+			return err
+		}
+
+		httpAddr, err := cmd.Flags().GetString("http")
+		if err != nil {
+			return err
+		}
+
+		token, err := cmd.Flags().GetString("token")
+		if err != nil {
+			return err
+		}
+
+		allowRemote, err := cmd.Flags().GetBool("allow-remote")
+		if err != nil {
+			return err
+		}
+
+		repoPath, err := cmd.Flags().GetString("repo")
+		if err != nil {
+			return err
+		}
+
+		return Serve(Store, ServeOptions{
+			MCP:         mcp,
+			HTTPAddr:    httpAddr,
+			Token:       token,
+			AllowRemote: allowRemote,
+			RepoPath:    repoPath,
+		})
+	},
+}
+
+var creatCommitMsg = &cobra.Command{
+	Use:   ". [path]",
+	Short: "Create Commit Message",
+	Long: `Create a commit message for the repository in the current directory, or
+for the repository at [path] (or --repo) when given.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		repoPath, err := cmd.Flags().GetString("repo")
+		if err != nil {
+			return err
+		}
+		if len(args) == 1 {
+			if repoPath != "" && repoPath != args[0] {
+				return fmt.Errorf("conflicting repository paths: %q and --repo %q", args[0], repoPath)
+			}
+			repoPath = args[0]
+		}
+
+		dryRun, err := cmd.Flags().GetBool("dry-run")
+		if err != nil {
+			return err
+		}
+
+		autoCommit, err := cmd.Flags().GetBool("auto")
+		if err != nil {
+			return err
+		}
+
+		quiet, err := cmd.Flags().GetBool("quiet")
+		if err != nil {
+			return err
+		}
+
+		stageAll, err := cmd.Flags().GetBool("add-all")
+		if err != nil {
+			return err
+		}
+
+		stageTracked, err := cmd.Flags().GetBool("update")
+		if err != nil {
+			return err
+		}
+
+		perPackage, err := cmd.Flags().GetBool("per-package")
+		if err != nil {
+			return err
+		}
+
+		withTests, err := cmd.Flags().GetBool("with-tests")
+		if err != nil {
+			return err
+		}
+
+		testCommand, err := cmd.Flags().GetString("test-cmd")
+		if err != nil {
+			return err
+		}
+
+		withIssue, err := cmd.Flags().GetBool("with-issue")
+		if err != nil {
+			return err
+		}
+
+		offline, err := cmd.Flags().GetBool("offline")
+		if err != nil {
+			return err
+		}
+
+		noLLM, err := cmd.Flags().GetBool("no-llm")
+		if err != nil {
+			return err
+		}
+
+		editor, err := cmd.Flags().GetString("editor")
+		if err != nil {
+			return err
+		}
+
+		provider, err := cmd.Flags().GetString("provider")
+		if err != nil {
+			return err
+		}
+
+		style, err := cmd.Flags().GetString("style")
+		if err != nil {
+			return err
+		}
+
+		instruction, err := cmd.Flags().GetString("instruction")
+		if err != nil {
+			return err
+		}
+
+		var temperature *float64
+		if cmd.Flags().Changed("temperature") {
+			value, err := cmd.Flags().GetFloat64("temperature")
+			if err != nil {
+				return err
+			}
+			if value < 0 || value > 2 {
+				return fmt.Errorf("--temperature must be between 0 and 2, got %g", value)
+			}
+			temperature = &value
+		}
+
+		candidates, err := cmd.Flags().GetInt("candidates")
+		if err != nil {
+			return err
+		}
+		if candidates < 1 || candidates > maxCandidates {
+			return fmt.Errorf("--candidates must be between 1 and %d, got %d", maxCandidates, candidates)
+		}
+
+		oneline, err := cmd.Flags().GetBool("oneline")
+		if err != nil {
+			return err
+		}
+
+		subjectLimit, err := cmd.Flags().GetInt("subject-limit")
+		if err != nil {
+			return err
+		}
+		if subjectLimit < 0 {
+			return fmt.Errorf("--subject-limit must not be negative, got %d", subjectLimit)
+		}
+
+		withNote, err := cmd.Flags().GetBool("with-note")
+		if err != nil {
+			return err
+		}
+		if withNote && !autoCommit {
+			return fmt.Errorf("--with-note needs --auto: the note is attached to the new commit")
+		}
+
+		signOff, err := cmd.Flags().GetBool("signoff")
+		if err != nil {
+			return err
+		}
+
+		yes, err := cmd.Flags().GetBool("yes")
+		if err != nil {
+			return err
+		}
+		if yes && !autoCommit {
+			return fmt.Errorf("--yes needs --auto: it skips the confirmation before --auto commits")
+		}
+
+		var seed *int64
+		if cmd.Flags().Changed("seed") {
+			value, err := cmd.Flags().GetInt64("seed")
+			if err != nil {
+				return err
+			}
+			seed = &value
+		}
+
+		CreateCommitMsg(Store, CreateOptions{
+			DryRun:       dryRun,
+			AutoCommit:   autoCommit,
+			Quiet:        quiet,
+			StageAll:     stageAll,
+			StageTracked: stageTracked,
+			PerPackage:   perPackage,
+			RepoPath:     repoPath,
+			WithTests:    withTests || testCommand != "",
+			TestCommand:  testCommand,
+			WithIssue:    withIssue,
+			Offline:      offline,
+			NoLLM:        noLLM,
+			Editor:       editor,
+			Provider:     provider,
+			Style:        style,
+			Instruction:  instruction,
+			Temperature:  temperature,
+			Seed:         seed,
+			Candidates:   candidates,
+			Oneline:      oneline || subjectLimit > 0,
+			SubjectLimit: subjectLimit,
+			WithNote:     withNote,
+			SignOff:      signOff,
+			Yes:          yes,
+		})
+		return nil
+	},
+}
+
+func init() {
+	// Here you will define your flags and configuration settings.
+	// Cobra supports persistent flags, which, if defined here,
+	// will be global for your application.
+
+	// rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.commit-msg.yaml)")
+
+	// Cobra also supports local flags, which will only run
+	// when this action is called directly.
+
+	rootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	rootCmd.Version = buildinfo.Get().String()
+
+	// Add --dry-run and --auto as persistent flags so they show in top-level help
+	rootCmd.PersistentFlags().Bool("dry-run", false, "Preview the prompt that would be sent to the LLM without making an API call")
+	rootCmd.PersistentFlags().Bool("auto", false, "Automatically commit with the generated message")
+	rootCmd.PersistentFlags().BoolP("quiet", "q", false, "Suppress decoration and print only the generated message (non-interactive; see exit codes in README)")
+	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "Log git commands, prompt sizes, provider calls, and cache decisions to stderr")
+	rootCmd.PersistentFlags().String("profile", "", "Use this configuration profile, with its own config file and credentials (default $"+StoreUtils.ProfileEnv+")")
+	rootCmd.PersistentFlags().String("log-file", "", "Write verbose logs to this file instead of stderr (implies --verbose)")
+
+	creatCommitMsg.Flags().BoolP("add-all", "a", false, "Stage all changes, including untracked files (git add -A), before generating")
+	creatCommitMsg.Flags().BoolP("update", "u", false, "Stage modified and deleted tracked files (git add -u) before generating")
+	creatCommitMsg.MarkFlagsMutuallyExclusive("add-all", "update")
+	creatCommitMsg.Flags().String("repo", "", "Generate a message for the repository at this path instead of the current directory")
+	watchCmd.Flags().Duration("debounce", watch.DefaultDebounce, "How long the working tree must stay quiet before regenerating the draft")
+	watchCmd.Flags().StringP("output", "o", "", "Write the draft to this file instead of .git/COMMIT_DRAFT")
+
+	lintCmd.Flags().StringP("file", "f", "", "Read the message from this file (\"-\" for standard input)")
+	lintCmd.Flags().Bool("last", false, "Check the message of the HEAD commit")
+	lintCmd.Flags().String("repo", "", "Repository used with --last instead of the current directory")
+	lintCmd.MarkFlagsMutuallyExclusive("file", "last")
+
+	undoCmd.Flags().String("repo", "", "Repository to work in instead of the current directory")
+	undoCmd.Flags().Bool("force", false, "Undo HEAD even if it was not made by --auto or was already pushed")
+
+	cacheStatsCmd.Flags().Bool("reset-stats", false, "Zero the hit and miss counters before showing the statistics")
+
+	benchCmd.Flags().Int("files", 200, "Changed files in the synthetic repository")
+	benchCmd.Flags().Int("lines", 50, "Changed lines in each file")
+	benchCmd.Flags().Int("runs", 5, "Times to run each stage; the median is reported")
+	benchCmd.Flags().String("dir", "", "Write the synthetic repository to this new directory and keep it (default: a temporary directory)")
+
+	historyCmd.Flags().StringP("search", "s", "", "Only list messages containing this text")
+	historyCmd.Flags().IntP("limit", "n", 20, "Most messages to list (0 lists all)")
+	historyCmd.Flags().Int("copy", 0, "Copy the message with this number in the listing to the clipboard")
+	historyCmd.Flags().Bool("rejected", false, "Also list recorded messages that were not accepted")
+	historyCmd.Flags().Bool("clear", false, "Remove every recorded message")
+	historyCmd.MarkFlagsMutuallyExclusive("copy", "clear")
+
+	rewriteCmd.Flags().String("repo", "", "Rewrite a commit in the repository at this path instead of the current directory")
+	rewriteCmd.Flags().BoolP("yes", "y", false, "Reword the commit without asking for confirmation")
+	fixupCmd.Flags().String("repo", "", "Work in the repository at this path instead of the current directory")
+	fixupCmd.Flags().Bool("squash", false, "Write a squash! commit, whose body is kept when squashed, instead of fixup!")
+	fixupCmd.Flags().Bool("commit", false, "Commit the staged changes with the message")
+	stashCmd.Flags().String("repo", "", "Stash changes in the repository at this path instead of the current directory")
+	stashCmd.Flags().BoolP("include-untracked", "u", false, "Stash untracked files too")
+	notesShowCmd.Flags().String("repo", "", "Read notes in the repository at this path instead of the current directory")
+	recapCmd.Flags().String("since", "midnight", "Start of the period, in any date format git log accepts, such as \"9am\" or \"yesterday\"")
+	recapCmd.Flags().String("until", "", "End of the period (default: now)")
+	recapCmd.Flags().String("author", "", "Only include commits by this author (default: your user.email)")
+	recapCmd.Flags().Bool("all-authors", false, "Include commits by every author")
+	recapCmd.MarkFlagsMutuallyExclusive("author", "all-authors")
+	recapCmd.Flags().BoolP("uncommitted", "w", false, "Include staged, unstaged, and untracked work not yet committed")
+	recapCmd.Flags().String("instruction", "", "Add custom guidance for the report, such as \"Write it in German\"")
+	recapCmd.Flags().String("repo", "", "Summarize the repository at this path instead of the current directory")
+	releaseNotesCmd.Flags().String("audience", "users", "Who the notes are for: users or developers")
+	releaseNotesCmd.Flags().String("instruction", "", "Add custom guidance for the notes, such as \"Mention the new logo\"")
+	releaseNotesCmd.Flags().StringP("output", "o", "", "Write the notes to this file instead of the clipboard")
+	doctorCmd.Flags().Bool("offline", false, "Skip contacting the saved providers")
+	doctorCmd.Flags().Bool("json", false, "Print the report as JSON")
+	telemetryExportCmd.Flags().StringP("output", "o", "", "Write the metrics to this file instead of stdout")
+	releaseNotesCmd.Flags().String("repo", "", "Read commits from the repository at this path instead of the current directory")
+
+	llmMigrateCmd.Flags().String("to", "", "Backend to move credentials to: keyring or file")
+	_ = llmMigrateCmd.MarkFlagRequired("to")
+	llmSetupCmd.Flags().String("plugin", "", "Register the provider executable commit-provider-<name> found on PATH")
+
+	serveCmd.Flags().Bool("mcp", false, "Serve the Model Context Protocol over stdin/stdout")
+	serveCmd.Flags().String("http", "", "Serve the HTTP API on this address (default "+apiserver.DefaultAddr+" when given without a value)")
+	serveCmd.Flags().Lookup("http").NoOptDefVal = apiserver.DefaultAddr
+	serveCmd.Flags().String("token", "", "Bearer token required by the HTTP API (default $"+apiTokenEnv+" or a generated token)")
+	serveCmd.Flags().Bool("allow-remote", false, "Allow the HTTP API to listen on non-loopback addresses")
+	serveCmd.MarkFlagsMutuallyExclusive("mcp", "http")
+	serveCmd.Flags().String("repo", "", "Default repository for requests that do not specify a path")
+
+	ciCmd.Flags().String("provider", "", "Provider to use (default $"+ciProviderEnv+")")
+	ciCmd.Flags().String("diff", "", "Describe the diff in this file (\"-\" for standard input) instead of the repository")
+	ciCmd.Flags().String("range", "", "Describe a commit or a range such as origin/main..HEAD instead of the working tree")
+	ciCmd.MarkFlagsMutuallyExclusive("diff", "range")
+	ciCmd.Flags().String("repo", "", "Repository to work in instead of the current directory")
+	ciCmd.Flags().Duration("timeout", defaultCITimeout, "Give up and exit with code 6 after this long")
+	ciCmd.Flags().String("instruction", "", "Add custom style guidance to the prompt")
+
+	creatCommitMsg.Flags().Bool("with-tests", false, "Run the project's quick tests and include a pass/fail summary in the prompt")
+	creatCommitMsg.Flags().String("test-cmd", "", "Test command for --with-tests (default $"+testrun.CommandEnv+" or detected from the project; implies --with-tests)")
+	creatCommitMsg.Flags().Bool("with-issue", false, "Add the title and description of the issue referenced by the branch name (see: commit issue setup)")
+	creatCommitMsg.Flags().Bool("per-package", false, "In a monorepo, generate a separate message for each changed package without asking")
+	creatCommitMsg.Flags().Bool("offline", false, "Never contact a network provider; use a local Ollama endpoint or a rule-based message")
+	creatCommitMsg.Flags().Bool("no-llm", false, "Build a rule-based conventional commit message from the changed files without any LLM")
+	creatCommitMsg.Flags().String("style", "", "Generate the message in this style preset: default, detailed, casual, bugfix, or a saved preset (see: commit style list)")
+	creatCommitMsg.Flags().String("instruction", "", "Add custom style guidance to the prompt, such as \"Mention the migration in the body\"")
+	creatCommitMsg.Flags().Float64("temperature", 0, "Sampling temperature from 0 to 2; higher values give more varied messages (default: the provider's own)")
+	creatCommitMsg.Flags().Int64("seed", 0, "Ask providers that support it (OpenAI, Groq, Ollama) for the same message on every run over the same changes")
+	creatCommitMsg.Flags().Int("candidates", 3, "Messages to request in one call from providers that support it (OpenAI) to pick from in the review screen; 1 asks for a single message")
+	creatCommitMsg.Flags().Bool("oneline", false, "Generate only a subject line, shortening it until it fits --subject-limit")
+	creatCommitMsg.Flags().Int("subject-limit", 0, "Longest subject --oneline accepts, such as 50 (default: the max_subject_length lint rule, 72; implies --oneline)")
+	creatCommitMsg.Flags().BoolP("yes", "y", false, "With --auto, commit without showing the final message and asking for confirmation")
+	creatCommitMsg.Flags().BoolP("signoff", "s", false, "Add a Signed-off-by trailer for the committer to the message, like git commit --signoff")
+	creatCommitMsg.Flags().Bool("with-note", false, "With --auto, attach a longer explanation of the change to the commit as a git note")
+	creatCommitMsg.Flags().String("provider", "", "Use this saved provider for this run instead of the default (see: commit llm use)")
+	creatCommitMsg.MarkFlagsMutuallyExclusive("provider", "no-llm")
+	_ = creatCommitMsg.RegisterFlagCompletionFunc("provider", savedProviderNames)
+	creatCommitMsg.Flags().String("editor", "", "Editor command used to edit the message during review (overrides GIT_EDITOR and core.editor)")
 
 	rootCmd.AddCommand(creatCommitMsg)
 	rootCmd.AddCommand(llmCmd)
+	rootCmd.AddCommand(configCmd)
 	rootCmd.AddCommand(cacheCmd)
+	rootCmd.AddCommand(watchCmd)
+	rootCmd.AddCommand(serveCmd)
+	rootCmd.AddCommand(ciCmd)
+	rootCmd.AddCommand(issueCmd)
+	rootCmd.AddCommand(styleCmd)
+	rootCmd.AddCommand(feedbackCmd)
+	rootCmd.AddCommand(usageCmd)
+	rootCmd.AddCommand(telemetryCmd)
+	rootCmd.AddCommand(doctorCmd)
+	rootCmd.AddCommand(lintCmd)
+	rootCmd.AddCommand(rewriteCmd)
+	rootCmd.AddCommand(fixupCmd)
+	rootCmd.AddCommand(stashCmd)
+	rootCmd.AddCommand(notesCmd)
+	rootCmd.AddCommand(recapCmd)
+	rootCmd.AddCommand(releaseNotesCmd)
+	rootCmd.AddCommand(historyCmd)
+	rootCmd.AddCommand(undoCmd)
+	rootCmd.AddCommand(benchCmd)
 	llmCmd.AddCommand(llmSetupCmd)
 	llmCmd.AddCommand(llmUpdateCmd)
+	llmCmd.AddCommand(llmStatusCmd)
+	llmCmd.AddCommand(llmUseCmd)
+	llmCmd.AddCommand(llmMigrateCmd)
+	configCmd.AddCommand(configRepairCmd)
+	configCmd.AddCommand(configPathCmd)
+	notesCmd.AddCommand(notesShowCmd)
 	cacheCmd.AddCommand(cacheStatsCmd)
 	cacheCmd.AddCommand(cacheClearCmd)
 	cacheCmd.AddCommand(cacheCleanupCmd)
+	cacheCmd.AddCommand(cacheMigrateCmd)
+	issueCmd.AddCommand(issueSetupCmd)
+	issueCmd.AddCommand(issueRemoveCmd)
+	styleCmd.AddCommand(styleResetCmd)
+	styleCmd.AddCommand(styleAddCmd)
+	styleCmd.AddCommand(styleListCmd)
+	styleCmd.AddCommand(styleRemoveCmd)
+	telemetryCmd.AddCommand(telemetryEnableCmd)
+	telemetryCmd.AddCommand(telemetryDisableCmd)
+	telemetryCmd.AddCommand(telemetryExportCmd)
+	telemetryCmd.AddCommand(telemetryResetCmd)
 }
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/dfanso/commit-msg/cmd/cli/store"
+	"github.com/dfanso/commit-msg/internal/apiserver"
+	"github.com/dfanso/commit-msg/internal/llm"
+	"github.com/dfanso/commit-msg/internal/mcpserver"
+	"github.com/dfanso/commit-msg/pkg/types"
+	"github.com/pterm/pterm"
+)
+
+// apiTokenEnv names the environment variable holding the HTTP API token.
+const apiTokenEnv = "COMMIT_MSG_API_TOKEN"
+
+// ServeOptions controls the integration server started by commit serve.
+type ServeOptions struct {
+	// MCP serves the Model Context Protocol over stdin/stdout.
+	MCP bool
+	// HTTPAddr serves the HTTP API on this address when set.
+	HTTPAddr string
+	// Token is the HTTP API bearer token. When empty COMMIT_MSG_API_TOKEN
+	// is used, and failing that a random token is generated and printed.
+	Token string
+	// AllowRemote permits the HTTP API to bind to non-loopback addresses.
+	AllowRemote bool
+	// RepoPath is the default repository for requests that omit a path.
+	RepoPath string
+}
+
+// Serve runs commit-msg as a long-lived server for editors and agents.
+func Serve(Store *store.StoreMethods, opts ServeOptions) error {
+	if opts.MCP == (opts.HTTPAddr != "") {
+		return fmt.Errorf("choose exactly one server mode: --mcp or --http")
+	}
+
+	repoPath, err := resolveRepoPath(opts.RepoPath)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	Store.StartCacheCleanup(ctx)
+
+	if opts.MCP {
+		// stdout carries the protocol; keep decorated output off it.
+		setQuietMode(true)
+
+		s := mcpserver.New(mcpserver.Options{
+			DefaultPath: repoPath,
+			Generate:    serverGenerator(Store),
+		})
+		return mcpserver.ServeStdio(ctx, s, os.Stdin, os.Stdout)
+	}
+
+	addr, err := apiserver.ResolveAddr(opts.HTTPAddr, opts.AllowRemote)
+	if err != nil {
+		return err
+	}
+
+	token := opts.Token
+	if token == "" {
+		token = os.Getenv(apiTokenEnv)
+	}
+	if token == "" {
+		token, err = apiserver.NewToken()
+		if err != nil {
+			return err
+		}
+		pterm.Info.Printf("Generated API token (set %s to choose your own):\n", apiTokenEnv)
+		pterm.Println(token)
+	}
+
+	pterm.Success.Printf("Serving HTTP API on http://%s (default repo: %s). Press Ctrl+C to stop.\n", addr, repoPath)
+
+	// Per-request output would interleave across concurrent requests.
+	generate := serverGenerator(Store)
+	pterm.DisableOutput()
+	defer pterm.EnableOutput()
+
+	return apiserver.ListenAndServe(ctx, addr, apiserver.Options{
+		Token:       token,
+		DefaultPath: repoPath,
+		Generate:    generate,
+		CacheStats:  Store.GetCacheStats,
+	})
+}
+
+// serverGenerator returns the generation callback shared by the server
+// modes. It applies the same truncation, monorepo scoping, and caching as
+// the interactive flow.
+func serverGenerator(Store *store.StoreMethods) func(ctx context.Context, repoPath, changes string, opts *types.GenerationOptions) (*types.GenerationResult, error) {
+	return func(ctx context.Context, repoPath, changes string, opts *types.GenerationOptions) (*types.GenerationResult, error) {
+		useLLM, err := Store.DefaultLLMKey()
+		if err != nil {
+			return nil, fmt.Errorf("no LLM configured, run: commit llm setup")
+		}
+
+		providerInstance, err := llm.NewProvider(useLLM.LLM, llm.ProviderOptions{
+			Credential: useLLM.APIKey,
+			Config:     providerConfig(),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", useLLM.LLM, err)
+		}
+
+		changes = truncateLargeDiff(condenseChanges(repoPath, changes))
+		workspace, changedPackages := detectChangedPackages(&types.RepoConfig{Path: repoPath})
+		prompt := newPromptContext(repoPath, workspace, changedPackages)
+
+		return generateMessageWithCache(ctx, providerInstance, Store, useLLM.LLM, changes, prompt.apply(opts))
+	}
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"path/filepath"
+	"sync"
+
+	"github.com/dfanso/commit-msg/internal/config"
+	"github.com/dfanso/commit-msg/internal/git"
+	"github.com/dfanso/commit-msg/internal/logging"
+	"github.com/dfanso/commit-msg/internal/spellcheck"
+	"github.com/dfanso/commit-msg/pkg/types"
+	"github.com/pterm/pterm"
+)
+
+var (
+	spellcheckerOnce sync.Once
+	spellchecker     *spellcheck.Checker
+)
+
+// loadSpellchecker builds the checker once per run from the "spellcheck"
+// section of config.json and the .commit-terms file of the repository at
+// dir.
+func loadSpellchecker(dir string) *spellcheck.Checker {
+	spellcheckerOnce.Do(func() {
+		settings, err := config.LoadSpellcheck()
+		if err != nil {
+			pterm.Warning.Printf("Ignoring spellcheck settings: %v\n", err)
+		}
+
+		terms := settings.Terms
+		root, err := git.RepoRoot(dir)
+		if err != nil {
+			root = dir
+		}
+		repoTerms, err := spellcheck.LoadTerms(filepath.Join(root, spellcheck.TermsFile))
+		if err != nil {
+			pterm.Warning.Printf("Ignoring %s: %v\n", spellcheck.TermsFile, err)
+		}
+		spellchecker = spellcheck.New(settings.Enabled, append(terms, repoTerms...))
+	})
+	return spellchecker
+}
+
+// correctMessage fixes misspellings and term casing in a generated message
+// for the repository at dir.
+func correctMessage(dir, message string) string {
+	corrected, corrections := loadSpellchecker(dir).Correct(message)
+	if len(corrections) > 0 {
+		logging.Debug("spelling corrected", "corrections", corrections)
+	}
+	return corrected
+}
+
+// withCorrections wraps generate so every message and candidate is
+// spell-checked before it is shown.
+func withCorrections(dir string, generate generateFunc) generateFunc {
+	return func(opts *types.GenerationOptions) (*types.GenerationResult, error) {
+		result, err := generate(opts)
+		if err != nil {
+			return nil, err
+		}
+		result.Message = correctMessage(dir, result.Message)
+		for i, alternative := range result.Alternatives {
+			result.Alternatives[i] = correctMessage(dir, alternative)
+		}
+		return result, nil
+	}
+}
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dfanso/commit-msg/cmd/cli/store"
+	"github.com/dfanso/commit-msg/internal/display"
+	"github.com/dfanso/commit-msg/internal/git"
+	"github.com/dfanso/commit-msg/internal/llm"
+	"github.com/dfanso/commit-msg/internal/postprocess"
+	"github.com/dfanso/commit-msg/pkg/types"
+	"github.com/pterm/pterm"
+)
+
+// stashInstruction keeps stash descriptions about the work itself rather
+// than the fact that it is unfinished.
+const stashInstruction = "These changes are being stashed as work in progress. Describe what they do so the stash is easy to find later; do not mention that they are unfinished."
+
+// StashOptions controls commit stash.
+type StashOptions struct {
+	// IncludeUntracked stashes untracked files too.
+	IncludeUntracked bool
+	// RepoPath is the repository to work in; empty means the current
+	// directory.
+	RepoPath string
+	// DryRun displays the prompt without making an API call.
+	DryRun bool
+	// Quiet prints only the description.
+	Quiet bool
+}
+
+// StashChanges asks the default provider for a one-line description of the
+// pending changes and stashes them under it with git stash push -m, so
+// git stash list stays readable. The process exits with one of the
+// documented Exit* codes on failure.
+func StashChanges(Store *store.StoreMethods, opts StashOptions) {
+	setQuietMode(opts.Quiet)
+
+	dir, err := resolveRepoPath(opts.RepoPath)
+	if err != nil {
+		exitf(ExitError, "%v\n", err)
+	}
+	if !git.IsRepository(dir) {
+		exitf(ExitNotRepository, "Not a Git repository: %s\n", dir)
+	}
+
+	repoConfig := &types.RepoConfig{Path: dir, Limits: loadContentLimits()}
+	set, err := git.CollectChanges(repoConfig)
+	if err != nil {
+		exitf(ExitError, "Failed to get Git changes: %v\n", err)
+	}
+	if !opts.IncludeUntracked {
+		set.Untracked = nil
+	}
+	if len(set.Staged) == 0 && len(set.Unstaged) == 0 && len(set.Untracked) == 0 {
+		exitf(ExitNoChanges, "No local changes to stash.\n")
+	}
+	changes := truncateLargeDiff(condenseChanges(dir, set.Prompt()))
+
+	limit := onelineLimit(0)
+	prompt := newPromptContext(dir, nil, nil)
+	genOpts := prompt.apply(withAttempt(nil, 1))
+
+	useLLM, err := Store.DefaultLLMKey()
+	if err != nil {
+		exitf(ExitError, "No LLM configured. Run: commit llm setup\n")
+	}
+	config := providerConfig()
+
+	if opts.DryRun {
+		dryRunOpts := withInstruction(withInstruction(genOpts, stashInstruction), onelineInstruction(limit))
+		if quietMode {
+			fmt.Println(dryRunPrompt(changes, dryRunOpts))
+			return
+		}
+		displayDryRunInfo(useLLM.LLM, config, changes, useLLM.APIKey, dryRunOpts)
+		return
+	}
+
+	provider, err := llm.NewProvider(useLLM.LLM, llm.ProviderOptions{
+		Credential: useLLM.APIKey,
+		Config:     config,
+	})
+	if err != nil {
+		displayProviderError(useLLM.LLM, err)
+		exit(ExitProviderError)
+	}
+
+	spinner, err := pterm.DefaultSpinner.Start(fmt.Sprintf("Describing the changes with %s...", useLLM.LLM))
+	if err != nil {
+		exitf(ExitError, "Failed to start spinner: %v\n", err)
+	}
+	generate := withBlocklist(withOneline(limit, withCorrections(dir, func(genOpts *types.GenerationOptions) (*types.GenerationResult, error) {
+		return generateMessageWithCache(context.Background(), provider, Store, useLLM.LLM, changes, genOpts)
+	})))
+	generated, err := generate(withInstruction(genOpts, stashInstruction))
+	if err != nil {
+		spinner.Fail("Failed to describe the changes")
+		displayProviderError(useLLM.LLM, err)
+		exit(ExitProviderError)
+	}
+	spinner.Success("Description generated (" + display.GenerationSummary(generated) + ")")
+
+	description := postprocess.Subject(generated.Message)
+	if description == "" {
+		exitf(ExitProviderError, "Generated description is empty\n")
+	}
+
+	output, err := git.Stash(repoConfig, description, opts.IncludeUntracked)
+	if err != nil {
+		exitf(ExitError, "Failed to stash: %v\n", err)
+	}
+	if quietMode {
+		fmt.Println(description)
+		return
+	}
+	pterm.Success.Println(output)
+}
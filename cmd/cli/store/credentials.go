@@ -0,0 +1,235 @@
+package store
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/99designs/keyring"
+
+	StoreUtils "github.com/dfanso/commit-msg/utils"
+)
+
+// Credential backends that can hold provider keys and tracker tokens.
+const (
+	// BackendKeyring is the operating system's credential store.
+	BackendKeyring = "keyring"
+	// BackendFile is an encrypted file in the config directory, for machines
+	// without a usable OS keyring.
+	BackendFile = "file"
+)
+
+// CredentialsPasswordEnv names the environment variable holding the
+// password of the encrypted credentials file. Without it the password is
+// asked for on the terminal.
+const CredentialsPasswordEnv = "COMMIT_CREDENTIALS_PASSWORD"
+
+const serviceName = "commit-msg"
+
+// ValidBackend reports whether name is a known credential backend.
+func ValidBackend(name string) bool {
+	return name == BackendKeyring || name == BackendFile
+}
+
+// BackendDescription names a backend for messages.
+func BackendDescription(name string) string {
+	if name == BackendFile {
+		dir, err := CredentialsDir()
+		if err != nil {
+			return "the encrypted credentials file"
+		}
+		return "the encrypted credentials file in " + dir
+	}
+	return "the OS keyring"
+}
+
+// Keyrings lists the OS credential stores the keyring backend can try on
+// this machine, in the order it tries them, such as "keychain" or
+// "secret-service". The encrypted file is always available and not listed.
+func Keyrings() []string {
+	var names []string
+	for _, backend := range keyring.AvailableBackends() {
+		if backend != keyring.FileBackend {
+			names = append(names, string(backend))
+		}
+	}
+	return names
+}
+
+// CredentialsDir is where the file backend keeps its encrypted entries. It
+// is shared by all profiles, whose keys are namespaced.
+func CredentialsDir() (string, error) {
+	dir, err := StoreUtils.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "credentials"), nil
+}
+
+// openBackend opens the named credential backend.
+func openBackend(name string) (keyring.Keyring, error) {
+	switch name {
+	case "", BackendKeyring:
+		ring, err := keyring.Open(keyring.Config{
+			ServiceName: serviceName,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to open keyring: %w", err)
+		}
+		return ring, nil
+	case BackendFile:
+		dir, err := CredentialsDir()
+		if err != nil {
+			return nil, err
+		}
+		ring, err := keyring.Open(keyring.Config{
+			ServiceName:      serviceName,
+			AllowedBackends:  []keyring.BackendType{keyring.FileBackend},
+			FileDir:          dir,
+			FilePasswordFunc: filePassword,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to open credentials file: %w", err)
+		}
+		return ring, nil
+	default:
+		return nil, fmt.Errorf("unknown credential backend %q, use %q or %q", name, BackendKeyring, BackendFile)
+	}
+}
+
+func filePassword(prompt string) (string, error) {
+	if password := os.Getenv(CredentialsPasswordEnv); password != "" {
+		return password, nil
+	}
+	return keyring.TerminalPrompt("Password for the commit-msg credentials file")
+}
+
+// ConfiguredBackend returns the credential backend recorded in the
+// profile's config, defaulting to the OS keyring.
+func ConfiguredBackend() (string, error) {
+	cfg, err := readConfig()
+	if err != nil {
+		return "", err
+	}
+	if cfg.CredentialBackend == "" {
+		return BackendKeyring, nil
+	}
+	return cfg.CredentialBackend, nil
+}
+
+// openRing opens the profile's credential backend on first use, after the
+// command line has selected the profile.
+func (s *StoreMethods) openRing() (keyring.Keyring, error) {
+	s.ringMu.Lock()
+	defer s.ringMu.Unlock()
+
+	if s.ring != nil {
+		return s.ring, nil
+	}
+	backend, err := ConfiguredBackend()
+	if err != nil {
+		return nil, err
+	}
+	ring, err := openBackend(backend)
+	if err != nil {
+		return nil, err
+	}
+	s.ring = ring
+	return ring, nil
+}
+
+// profilePrefix namespaces keyring keys by profile. The default profile
+// keeps the bare keys used before profiles existed.
+func profilePrefix() string {
+	name, err := StoreUtils.Profile()
+	if err != nil || name == "" {
+		return ""
+	}
+	return name + "/"
+}
+
+// credentialKey is the keyring key for name in the selected profile.
+func credentialKey(name string) string {
+	return profilePrefix() + name
+}
+
+// inProfile reports whether a stored key belongs to the selected profile.
+func inProfile(key string) bool {
+	prefix := profilePrefix()
+	if prefix == "" {
+		return !strings.Contains(key, "/")
+	}
+	return strings.HasPrefix(key, prefix)
+}
+
+// Migrate moves the selected profile's credentials to the backend named
+// to. Every entry is copied and read back before the config switches to
+// the new backend, and only then are the originals removed, so a failure
+// part way leaves the old backend working. It returns the number of
+// entries moved.
+func (s *StoreMethods) Migrate(to string) (int, error) {
+	if !ValidBackend(to) {
+		return 0, fmt.Errorf("unknown credential backend %q, use %q or %q", to, BackendKeyring, BackendFile)
+	}
+	from, err := ConfiguredBackend()
+	if err != nil {
+		return 0, err
+	}
+	if from == to {
+		return 0, fmt.Errorf("credentials are already stored in %s", BackendDescription(to))
+	}
+
+	source, err := s.openRing()
+	if err != nil {
+		return 0, err
+	}
+	target, err := openBackend(to)
+	if err != nil {
+		return 0, err
+	}
+
+	keys, err := source.Keys()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list credentials in %s: %w", BackendDescription(from), err)
+	}
+	var moved []string
+	for _, key := range keys {
+		if !inProfile(key) {
+			continue
+		}
+		item, err := source.Get(key)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read %s from %s: %w", key, BackendDescription(from), err)
+		}
+		if err := target.Set(keyring.Item{Key: key, Data: item.Data}); err != nil {
+			return 0, fmt.Errorf("failed to write %s to %s: %w", key, BackendDescription(to), err)
+		}
+		copied, err := target.Get(key)
+		if err != nil || !bytes.Equal(copied.Data, item.Data) {
+			return 0, fmt.Errorf("%s did not read back from %s; nothing was removed", key, BackendDescription(to))
+		}
+		moved = append(moved, key)
+	}
+
+	if err := updateConfig(func(cfg *Config) error {
+		cfg.CredentialBackend = to
+		return nil
+	}); err != nil {
+		return 0, fmt.Errorf("credentials were copied but the config was not updated; nothing was removed: %w", err)
+	}
+
+	s.ringMu.Lock()
+	s.ring = target
+	s.ringMu.Unlock()
+
+	var errs []error
+	for _, key := range moved {
+		if err := source.Remove(key); err != nil && !errors.Is(err, keyring.ErrKeyNotFound) {
+			errs = append(errs, fmt.Errorf("failed to remove %s from %s: %w", key, BackendDescription(from), err))
+		}
+	}
+	return len(moved), errors.Join(errs...)
+}
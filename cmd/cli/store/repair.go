@@ -0,0 +1,162 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/dfanso/commit-msg/pkg/types"
+	StoreUtils "github.com/dfanso/commit-msg/utils"
+)
+
+// RepairAction describes what RepairConfig did.
+type RepairAction int
+
+const (
+	// RepairNotNeeded means the config was readable and left alone.
+	RepairNotNeeded RepairAction = iota
+	// RepairRestoredBackup means the config was replaced by its backup.
+	RepairRestoredBackup
+	// RepairRebuilt means the config was rebuilt from the providers that
+	// have saved credentials.
+	RepairRebuilt
+)
+
+// RepairResult reports the outcome of RepairConfig.
+type RepairResult struct {
+	Action RepairAction
+	// Providers lists the providers in the repaired config.
+	Providers []types.LLMProvider
+	// CorruptPath is where the unreadable config was moved, if there was
+	// one.
+	CorruptPath string
+}
+
+// RepairConfig recovers a missing, empty, or unreadable config. It restores
+// the backup kept by the last successful write when that is readable, and
+// otherwise rebuilds the provider list from the credentials saved for the
+// profile. Settings other than the providers cannot be rebuilt. The broken
+// file is kept next to the config with a .corrupt suffix.
+func (s *StoreMethods) RepairConfig() (*RepairResult, error) {
+	configPath, err := StoreUtils.GetConfigPath()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &RepairResult{}
+	data, err := os.ReadFile(configPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if cfg, ok := parseConfig(data); err == nil && ok {
+		result.Providers = cfg.LLMProviders
+		return result, nil
+	}
+
+	if err == nil {
+		result.CorruptPath = configPath + ".corrupt"
+		if err := os.Rename(configPath, result.CorruptPath); err != nil {
+			return nil, fmt.Errorf("failed to set the broken config aside: %w", err)
+		}
+	}
+
+	// The ring may have been opened with a backend read from the broken
+	// config, so reopen it once the config is repaired.
+	s.ringMu.Lock()
+	s.ring = nil
+	s.ringMu.Unlock()
+
+	backup, err := os.ReadFile(StoreUtils.BackupPath(configPath))
+	if err == nil {
+		if cfg, ok := parseConfig(backup); ok && len(backup) > 2 {
+			if err := StoreUtils.WriteConfigFile(configPath, backup); err != nil {
+				return nil, err
+			}
+			result.Action = RepairRestoredBackup
+			result.Providers = cfg.LLMProviders
+			return result, nil
+		}
+	}
+
+	cfg, err := rebuildConfig()
+	if err != nil {
+		return nil, err
+	}
+	data, err = json.MarshalIndent(cfg, "", " ")
+	if err != nil {
+		return nil, err
+	}
+	if err := StoreUtils.WriteConfigFile(configPath, data); err != nil {
+		return nil, err
+	}
+	result.Action = RepairRebuilt
+	result.Providers = cfg.LLMProviders
+	return result, nil
+}
+
+// parseConfig reports whether data is a usable config. An empty file, as
+// left by an interrupted write, is not.
+func parseConfig(data []byte) (*Config, bool) {
+	var cfg Config
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return nil, false
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, false
+	}
+	return &cfg, true
+}
+
+// rebuildConfig lists the providers with credentials saved for the
+// profile, looking in the OS keyring and then, if it exists, the encrypted
+// credentials file.
+func rebuildConfig() (*Config, error) {
+	backends := []string{BackendKeyring}
+	if dir, err := CredentialsDir(); err == nil {
+		if _, err := os.Stat(dir); err == nil {
+			backends = append(backends, BackendFile)
+		}
+	}
+
+	var errs []error
+	for _, backend := range backends {
+		ring, err := openBackend(backend)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		keys, err := ring.Keys()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to list credentials in %s: %w", BackendDescription(backend), err))
+			continue
+		}
+
+		var providers []types.LLMProvider
+		for _, key := range keys {
+			if !inProfile(key) {
+				continue
+			}
+			if provider, ok := types.ParseLLMProvider(strings.TrimPrefix(key, profilePrefix())); ok {
+				providers = append(providers, provider)
+			}
+		}
+		if len(providers) == 0 {
+			continue
+		}
+		sort.Slice(providers, func(i, j int) bool { return providers[i] < providers[j] })
+
+		cfg := &Config{Default: providers[0], LLMProviders: providers}
+		if backend != BackendKeyring {
+			cfg.CredentialBackend = backend
+		}
+		return cfg, nil
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("no readable backup, and saved credentials could not be read: %w", err)
+	}
+	return nil, errors.New("no readable backup and no saved credentials, run 'commit llm setup' to start over")
+}
@@ -2,31 +2,46 @@
 package store
 
 import (
+	"context"
+	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
-
 	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/99designs/keyring"
 
 	"github.com/dfanso/commit-msg/internal/cache"
+	"github.com/dfanso/commit-msg/internal/config"
+	"github.com/dfanso/commit-msg/internal/issues"
+	"github.com/dfanso/commit-msg/internal/sqlstore"
 	"github.com/dfanso/commit-msg/pkg/types"
 	StoreUtils "github.com/dfanso/commit-msg/utils"
 )
 
 type StoreMethods struct {
-	ring  keyring.Keyring
-	cache *cache.CacheManager
+	// ring is opened on first use by openRing, since the profile and its
+	// credential backend are only known once flags are parsed.
+	ringMu sync.Mutex
+	ring   keyring.Keyring
+	cache  *cache.CacheManager
+	// db holds the cache and history when the "storage" section of
+	// config.json selects SQLite; nil means the JSON files.
+	db *sql.DB
 }
 
 // NewStoreMethods creates a new StoreMethods instance with cache support.
 func NewStoreMethods() (*StoreMethods, error) {
-	ring, err := keyring.Open(keyring.Config{
-		ServiceName: "commit-msg",
-	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to open keyring: %w", err)
+	db, path := openDatabase()
+	if db != nil {
+		return &StoreMethods{
+			cache: cache.NewCacheManagerWithBackend(path, cache.NewSQLiteBackend(db)),
+			db:    db,
+		}, nil
 	}
 
 	cacheManager, err := cache.NewCacheManager()
@@ -35,11 +50,42 @@ func NewStoreMethods() (*StoreMethods, error) {
 	}
 
 	return &StoreMethods{
-		ring:  ring,
 		cache: cacheManager,
 	}, nil
 }
 
+// openDatabase opens the SQLite database and returns it with its path when
+// the "storage" section of config.json selects it. Otherwise, or when it
+// cannot be opened, it returns nil and the JSON files are used.
+func openDatabase() (*sql.DB, string) {
+	settings, err := config.LoadStorage()
+	if err != nil {
+		fmt.Printf("Warning: Ignoring storage settings: %v\n", err)
+		return nil, ""
+	}
+	if !settings.UseSQLite() {
+		return nil, ""
+	}
+
+	path, err := sqlstore.DefaultPath()
+	if err != nil {
+		fmt.Printf("Warning: Failed to locate the SQLite store, using the JSON files: %v\n", err)
+		return nil, ""
+	}
+	db, err := sqlstore.Open(path)
+	if err != nil {
+		fmt.Printf("Warning: Failed to open the SQLite store, using the JSON files: %v\n", err)
+		return nil, ""
+	}
+	return db, path
+}
+
+// Database returns the SQLite database holding the cache and history, or
+// nil when they are kept in JSON files.
+func (s *StoreMethods) Database() *sql.DB {
+	return s.db
+}
+
 // KeyringInit initializes a StoreMethods instance with both keyring and cache support.
 // This function is kept for backward compatibility with main.go.
 func KeyringInit() (*StoreMethods, error) {
@@ -56,6 +102,90 @@ type LLMProvider struct {
 type Config struct {
 	Default      types.LLMProvider   `json:"default"`
 	LLMProviders []types.LLMProvider `json:"models"`
+	// Models holds the model chosen for each provider during setup.
+	Models map[types.LLMProvider]string `json:"provider_models,omitempty"`
+	// Styles holds the style presets saved with commit style add.
+	Styles []types.StylePreset `json:"styles,omitempty"`
+	// LastEditor is the editor command last used to edit a message, tried
+	// when no editor is configured in the environment or in git.
+	LastEditor string `json:"last_editor,omitempty"`
+	// CredentialBackend is where this profile's credentials live, "keyring"
+	// (the default) or "file"; commit llm migrate changes it.
+	CredentialBackend string `json:"credential_backend,omitempty"`
+	// Sections holds every other key of config.json, such as the sections
+	// read by internal/config, so rewriting the file preserves them.
+	Sections map[string]json.RawMessage `json:"-"`
+}
+
+// configFields has the fields of Config without its JSON methods, so they
+// can decode and encode the keys store owns the usual way.
+type configFields Config
+
+// ownedKeys are the config.json keys decoded into the fields of Config
+// rather than kept in Sections.
+var ownedKeys = func() map[string]bool {
+	keys := map[string]bool{}
+	t := reflect.TypeOf(configFields{})
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			keys[name] = true
+		}
+	}
+	return keys
+}()
+
+// UnmarshalJSON decodes the keys store owns into their fields and keeps
+// the rest in Sections.
+func (c *Config) UnmarshalJSON(data []byte) error {
+	var fields configFields
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	var sections map[string]json.RawMessage
+	if err := json.Unmarshal(data, &sections); err != nil {
+		return err
+	}
+	for key := range sections {
+		if ownedKeys[key] {
+			delete(sections, key)
+		}
+	}
+	if len(sections) > 0 {
+		fields.Sections = sections
+	}
+	*c = Config(fields)
+	return nil
+}
+
+// MarshalJSON encodes the fields of c together with its Sections.
+func (c Config) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(configFields(c))
+	if err != nil || len(c.Sections) == 0 {
+		return data, err
+	}
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, err
+	}
+	for key, raw := range c.Sections {
+		if !ownedKeys[key] && len(raw) > 0 {
+			all[key] = raw
+		}
+	}
+	return json.Marshal(all)
+}
+
+// setSection replaces the section named key, removing it when raw is empty.
+func (c *Config) setSection(key string, raw json.RawMessage) {
+	if len(raw) == 0 {
+		delete(c.Sections, key)
+		return
+	}
+	if c.Sections == nil {
+		c.Sections = map[string]json.RawMessage{}
+	}
+	c.Sections[key] = raw
 }
 
 // Save persists or updates an LLM provider entry, marking it as the default.
@@ -88,16 +218,21 @@ func (s *StoreMethods) Save(LLMConfig LLMProvider) error {
 		if err != nil {
 			// If unmarshal fails, it might be due to old config format
 			// Reset to empty config to allow fresh setup
-			return fmt.Errorf("config file format error: %w. Please delete the config and run setup again", err)
+			return fmt.Errorf("config file format error: %w. Run 'commit config repair' to restore it", err)
 		}
 	}
 
+	ring, err := s.openRing()
+	if err != nil {
+		return err
+	}
+
 	// If Model already present in config, update the apiKey
 	updated := false
 	for _, p := range cfg.LLMProviders {
 		if p == LLMConfig.LLM {
-			err := s.ring.Set(keyring.Item{ //save apiKey using keychain to OS credentials
-				Key:  string(LLMConfig.LLM),
+			err := ring.Set(keyring.Item{ //save apiKey using keychain to OS credentials
+				Key:  credentialKey(string(LLMConfig.LLM)),
 				Data: []byte(LLMConfig.APIKey),
 			})
 			if err != nil {
@@ -111,8 +246,8 @@ func (s *StoreMethods) Save(LLMConfig LLMProvider) error {
 	// If fresh Model is saved, means model not exists in config file
 	if !updated {
 		cfg.LLMProviders = append(cfg.LLMProviders, LLMConfig.LLM)
-		err := s.ring.Set(keyring.Item{ //save apiKey using keychain to OS credentials
-			Key:  string(LLMConfig.LLM),
+		err := ring.Set(keyring.Item{ //save apiKey using keychain to OS credentials
+			Key:  credentialKey(string(LLMConfig.LLM)),
 			Data: []byte(LLMConfig.APIKey),
 		})
 		if err != nil {
@@ -127,7 +262,7 @@ func (s *StoreMethods) Save(LLMConfig LLMProvider) error {
 		return err
 	}
 
-	return os.WriteFile(configPath, data, 0600)
+	return StoreUtils.WriteConfigFile(configPath, data)
 }
 
 // DefaultLLMKey returns the currently selected default LLM provider, if any.
@@ -155,7 +290,7 @@ func (s *StoreMethods) DefaultLLMKey() (*LLMProvider, error) {
 		err = json.Unmarshal(data, &cfg)
 		if err != nil {
 			// If unmarshal fails, it might be due to old config format
-			return nil, fmt.Errorf("config file format error: %w. Please delete the config and run setup again", err)
+			return nil, fmt.Errorf("config file format error: %w. Run 'commit config repair' to restore it", err)
 		}
 	} else {
 		return nil, errors.New("config file is empty, run 'commit llm setup' to add your first LLM provider")
@@ -163,10 +298,15 @@ func (s *StoreMethods) DefaultLLMKey() (*LLMProvider, error) {
 
 	defaultLLM := cfg.Default
 
+	ring, err := s.openRing()
+	if err != nil {
+		return nil, err
+	}
+
 	for i, p := range cfg.LLMProviders {
 		if p == defaultLLM {
-			useModel.LLM = cfg.LLMProviders[i]         // Fetches default Model from config json
-			i, err := s.ring.Get(string(useModel.LLM)) //Fetches apiKey from OS credential for default model
+			useModel.LLM = cfg.LLMProviders[i]                      // Fetches default Model from config json
+			i, err := ring.Get(credentialKey(string(useModel.LLM))) //Fetches apiKey from OS credential for default model
 			if err != nil {
 				return nil, err
 			}
@@ -177,6 +317,30 @@ func (s *StoreMethods) DefaultLLMKey() (*LLMProvider, error) {
 	return nil, fmt.Errorf("default model '%s' not found in saved providers, run 'commit llm setup' to configure it", defaultLLM)
 }
 
+// LLMKey returns the saved credential for model, which need not be the
+// default provider.
+func (s *StoreMethods) LLMKey(model types.LLMProvider) (*LLMProvider, error) {
+	cfg, err := ListSavedModels()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range cfg.LLMProviders {
+		if p == model {
+			ring, err := s.openRing()
+			if err != nil {
+				return nil, err
+			}
+			item, err := ring.Get(credentialKey(string(model)))
+			if err != nil {
+				return nil, err
+			}
+			return &LLMProvider{LLM: model, APIKey: string(item.Data)}, nil
+		}
+	}
+	return nil, fmt.Errorf("%s is not configured, run 'commit llm setup' to add it", model)
+}
+
 // ListSavedModels loads all persisted LLM provider configurations.
 func ListSavedModels() (*Config, error) {
 
@@ -201,7 +365,7 @@ func ListSavedModels() (*Config, error) {
 		err = json.Unmarshal(data, &cfg)
 		if err != nil {
 			// If unmarshal fails, it might be due to old config format
-			return nil, fmt.Errorf("config file format error: %w. Please delete the config and run setup again", err)
+			return nil, fmt.Errorf("config file format error: %w. Run 'commit config repair' to restore it", err)
 		}
 	} else {
 		return nil, errors.New("config file is empty, run 'commit llm setup' to add your first LLM provider")
@@ -235,7 +399,7 @@ func ChangeDefault(Model types.LLMProvider) error {
 		err = json.Unmarshal(data, &cfg)
 		if err != nil {
 			// If unmarshal fails, it might be due to old config format
-			return fmt.Errorf("config file format error: %w. Please delete the config and run setup again", err)
+			return fmt.Errorf("config file format error: %w. Run 'commit config repair' to restore it", err)
 		}
 	}
 
@@ -257,14 +421,230 @@ func ChangeDefault(Model types.LLMProvider) error {
 		return err
 	}
 
-	return os.WriteFile(configPath, data, 0600)
+	return StoreUtils.WriteConfigFile(configPath, data)
+}
+
+// SaveProviderModel records the model to use for a saved provider.
+func SaveProviderModel(provider types.LLMProvider, model string) error {
+
+	var cfg Config
+
+	configPath, err := StoreUtils.GetConfigPath()
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return err
+	}
+
+	if len(data) > 2 {
+		err = json.Unmarshal(data, &cfg)
+		if err != nil {
+			// If unmarshal fails, it might be due to old config format
+			return fmt.Errorf("config file format error: %w. Run 'commit config repair' to restore it", err)
+		}
+	}
+
+	if cfg.Models == nil {
+		cfg.Models = make(map[types.LLMProvider]string)
+	}
+	cfg.Models[provider] = model
+
+	data, err = json.MarshalIndent(cfg, "", " ")
+	if err != nil {
+		return err
+	}
+
+	return StoreUtils.WriteConfigFile(configPath, data)
+}
+
+// SaveHuggingFaceEndpoint records the dedicated Inference Endpoint and the
+// API it speaks in the "huggingface" section, keeping its other settings.
+// An empty endpoint switches back to the serverless router.
+func SaveHuggingFaceEndpoint(endpoint, api string) error {
+	return updateConfig(func(cfg *Config) error {
+		section, err := mergeSection("huggingface", cfg.Sections["huggingface"], map[string]string{"endpoint": endpoint, "api": api})
+		if err != nil {
+			return err
+		}
+		cfg.setSection("huggingface", section)
+		return nil
+	})
+}
+
+// SaveVertexSettings records the Google Cloud project and location in the
+// "vertex" section. Empty values are removed so the environment and the
+// credentials decide.
+func SaveVertexSettings(project, location string) error {
+	return updateConfig(func(cfg *Config) error {
+		section, err := mergeSection("vertex", cfg.Sections["vertex"], map[string]string{"project": project, "location": location})
+		if err != nil {
+			return err
+		}
+		cfg.setSection("vertex", section)
+		return nil
+	})
+}
+
+// SaveTelemetryEnabled turns telemetry on or off in the "telemetry"
+// section, keeping its other settings.
+func SaveTelemetryEnabled(enabled bool) error {
+	return updateConfig(func(cfg *Config) error {
+		section := map[string]json.RawMessage{}
+		if raw := cfg.Sections["telemetry"]; len(raw) > 0 {
+			if err := json.Unmarshal(raw, &section); err != nil {
+				return fmt.Errorf("invalid telemetry section: %w", err)
+			}
+		}
+		section["enabled"] = json.RawMessage(strconv.FormatBool(enabled))
+		raw, err := json.Marshal(section)
+		if err != nil {
+			return err
+		}
+		cfg.setSection("telemetry", raw)
+		return nil
+	})
+}
+
+// mergeSection sets the string values in the config section named name,
+// deleting the keys whose value is empty and keeping the section's other
+// settings. It returns nil when the section ends up empty.
+func mergeSection(name string, raw json.RawMessage, values map[string]string) (json.RawMessage, error) {
+	section := map[string]json.RawMessage{}
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &section); err != nil {
+			return nil, fmt.Errorf("invalid %s section: %w", name, err)
+		}
+	}
+	for key, value := range values {
+		if value == "" {
+			delete(section, key)
+			continue
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		section[key] = encoded
+	}
+	if len(section) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(section)
+}
+
+// SaveLastEditor records the editor command last used to edit a message.
+func SaveLastEditor(editor string) error {
+	return updateConfig(func(cfg *Config) error {
+		cfg.LastEditor = editor
+		return nil
+	})
+}
+
+// SaveStylePreset adds a named style preset, replacing any preset with the
+// same name.
+func SaveStylePreset(preset types.StylePreset) error {
+	return updateConfig(func(cfg *Config) error {
+		for i, existing := range cfg.Styles {
+			if strings.EqualFold(existing.Name, preset.Name) {
+				cfg.Styles[i] = preset
+				return nil
+			}
+		}
+		cfg.Styles = append(cfg.Styles, preset)
+		return nil
+	})
+}
+
+// RemoveStylePreset deletes the named style preset.
+func RemoveStylePreset(name string) error {
+	return updateConfig(func(cfg *Config) error {
+		for i, existing := range cfg.Styles {
+			if strings.EqualFold(existing.Name, name) {
+				cfg.Styles = append(cfg.Styles[:i], cfg.Styles[i+1:]...)
+				return nil
+			}
+		}
+		return fmt.Errorf("no style preset named %q", name)
+	})
+}
+
+// readConfig loads the profile's config, returning an empty one when the
+// file does not exist yet.
+func readConfig() (*Config, error) {
+	var cfg Config
+
+	configPath, err := StoreUtils.GetConfigPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(configPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return &cfg, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if len(data) > 2 {
+		if err := json.Unmarshal(data, &cfg); err != nil {
+			return nil, fmt.Errorf("config file format error: %w. Run 'commit config repair' to restore it", err)
+		}
+	}
+	return &cfg, nil
+}
+
+// updateConfig applies update to config.json, creating the file when it
+// does not exist yet.
+func updateConfig(update func(cfg *Config) error) error {
+
+	var cfg Config
+
+	configPath, err := StoreUtils.GetConfigPath()
+	if err != nil {
+		return err
+	}
+
+	if err := StoreUtils.CreateConfigFile(configPath); err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(configPath)
+	if errors.Is(err, os.ErrNotExist) {
+		data = []byte("{}")
+	} else if err != nil {
+		return err
+	}
+
+	if len(data) > 2 {
+		err = json.Unmarshal(data, &cfg)
+		if err != nil {
+			// If unmarshal fails, it might be due to old config format
+			return fmt.Errorf("config file format error: %w. Run 'commit config repair' to restore it", err)
+		}
+	}
+
+	if err := update(&cfg); err != nil {
+		return err
+	}
+
+	data, err = json.MarshalIndent(cfg, "", " ")
+	if err != nil {
+		return err
+	}
+
+	return StoreUtils.WriteConfigFile(configPath, data)
 }
 
 // DeleteModel removes the specified provider from the saved configuration.
-func (s *StoreMethods) DeleteModel(Model types.LLMProvider) error {
+// When it is the default, newDefault becomes the default instead; it may be
+// empty when exactly one other provider remains, which is then chosen. Other
+// settings are kept even when the last provider is removed.
+func (s *StoreMethods) DeleteModel(Model types.LLMProvider, newDefault types.LLMProvider) error {
 
 	var cfg Config
-	var newCfg Config
 
 	configPath, err := StoreUtils.GetConfigPath()
 	if err != nil {
@@ -285,42 +665,63 @@ func (s *StoreMethods) DeleteModel(Model types.LLMProvider) error {
 		err = json.Unmarshal(data, &cfg)
 		if err != nil {
 			// If unmarshal fails, it might be due to old config format
-			return fmt.Errorf("config file format error: %w. Please delete the config and run setup again", err)
+			return fmt.Errorf("config file format error: %w. Run 'commit config repair' to restore it", err)
 		}
 	}
 
+	remaining := RemainingProviders(&cfg, Model)
+	if len(remaining) == len(cfg.LLMProviders) {
+		return fmt.Errorf("no saved entry for %s to delete", Model.String())
+	}
+
 	if Model == cfg.Default {
-		if len(cfg.LLMProviders) > 1 {
-			return fmt.Errorf("cannot delete %s while it is default, set other model default first", Model.String())
-		} else {
-			err := s.ring.Remove(string(Model)) // Removes the apiKey from OS credentials
-			if err != nil {
-				return err
+		if newDefault == "" && len(remaining) == 1 {
+			newDefault = remaining[0]
+		}
+		found := len(remaining) == 0 && newDefault == ""
+		for _, p := range remaining {
+			if p == newDefault {
+				found = true
+				break
 			}
-			return os.WriteFile(configPath, []byte("{}"), 0600)
 		}
-	} else {
-
-		for _, p := range cfg.LLMProviders {
-
-			if p != Model {
-				newCfg.LLMProviders = append(newCfg.LLMProviders, p)
+		if !found {
+			if newDefault == "" {
+				return fmt.Errorf("%s is the default, choose which remaining provider replaces it", Model.String())
 			}
+			return fmt.Errorf("cannot set default to %s: no saved entry", newDefault.String())
 		}
+		cfg.Default = newDefault
+	}
 
-		err := s.ring.Remove(string(Model)) //Remove the apiKey from OS credentials
-		if err != nil {
-			return err
-		}
-		newCfg.Default = cfg.Default
+	ring, err := s.openRing()
+	if err != nil {
+		return err
+	}
+	err = ring.Remove(credentialKey(string(Model))) //Remove the apiKey from OS credentials
+	if err != nil && !errors.Is(err, keyring.ErrKeyNotFound) {
+		return err
+	}
 
-		data, err = json.MarshalIndent(newCfg, "", " ")
-		if err != nil {
-			return err
-		}
-		return os.WriteFile(configPath, data, 0600)
+	cfg.LLMProviders = remaining
+	delete(cfg.Models, Model)
+
+	data, err = json.MarshalIndent(cfg, "", " ")
+	if err != nil {
+		return err
+	}
+	return StoreUtils.WriteConfigFile(configPath, data)
+}
 
+// RemainingProviders returns the saved providers other than model.
+func RemainingProviders(cfg *Config, model types.LLMProvider) []types.LLMProvider {
+	var remaining []types.LLMProvider
+	for _, p := range cfg.LLMProviders {
+		if p != model {
+			remaining = append(remaining, p)
+		}
 	}
+	return remaining
 }
 
 // UpdateAPIKey rotates the credential for an existing provider entry.
@@ -347,15 +748,20 @@ func (s *StoreMethods) UpdateAPIKey(Model types.LLMProvider, APIKey string) erro
 		err = json.Unmarshal(data, &cfg)
 		if err != nil {
 			// If unmarshal fails, it might be due to old config format
-			return fmt.Errorf("config file format error: %w. Please delete the config and run setup again", err)
+			return fmt.Errorf("config file format error: %w. Run 'commit config repair' to restore it", err)
 		}
 	}
 
+	ring, err := s.openRing()
+	if err != nil {
+		return err
+	}
+
 	updated := false
 	for _, p := range cfg.LLMProviders {
 		if p == Model {
-			err := s.ring.Set(keyring.Item{ // Update the apiKey in OS credential
-				Key:  string(Model),
+			err := ring.Set(keyring.Item{ // Update the apiKey in OS credential
+				Key:  credentialKey(string(Model)),
 				Data: []byte(APIKey),
 			})
 			if err != nil {
@@ -374,8 +780,74 @@ func (s *StoreMethods) UpdateAPIKey(Model types.LLMProvider, APIKey string) erro
 		return err
 	}
 
-	return os.WriteFile(configPath, data, 0600)
+	return StoreUtils.WriteConfigFile(configPath, data)
+
+}
+
+// issueTrackerKey is the keyring key holding credentials for tracker.
+func issueTrackerKey(tracker issues.Tracker) string {
+	return credentialKey("issue-tracker-" + string(tracker))
+}
+
+// SaveTrackerCredential stores the credential for an issue tracker in the
+// OS keyring.
+func (s *StoreMethods) SaveTrackerCredential(tracker issues.Tracker, cred issues.Credential) error {
+	data, err := json.Marshal(cred)
+	if err != nil {
+		return err
+	}
+
+	ring, err := s.openRing()
+	if err != nil {
+		return err
+	}
+
+	err = ring.Set(keyring.Item{
+		Key:  issueTrackerKey(tracker),
+		Data: data,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store credentials in keyring: %w", err)
+	}
+	return nil
+}
+
+// TrackerCredential returns the stored credential for an issue tracker. The
+// boolean is false when none has been configured.
+func (s *StoreMethods) TrackerCredential(tracker issues.Tracker) (issues.Credential, bool, error) {
+	var cred issues.Credential
+
+	ring, err := s.openRing()
+	if err != nil {
+		return cred, false, err
+	}
+
+	item, err := ring.Get(issueTrackerKey(tracker))
+	if errors.Is(err, keyring.ErrKeyNotFound) {
+		return cred, false, nil
+	}
+	if err != nil {
+		return cred, false, err
+	}
+
+	if err := json.Unmarshal(item.Data, &cred); err != nil {
+		return cred, false, fmt.Errorf("stored %s credentials are invalid: %w", tracker, err)
+	}
+	return cred, true, nil
+}
+
+// DeleteTrackerCredential removes the stored credential for an issue tracker.
+func (s *StoreMethods) DeleteTrackerCredential(tracker issues.Tracker) error {
+	ring, err := s.openRing()
+	if err != nil {
+		return err
+	}
 
+	err = ring.Remove(issueTrackerKey(tracker))
+	if err != nil && !errors.Is(err, keyring.ErrKeyNotFound) {
+		return fmt.Errorf("failed to remove credentials from keyring: %w", err)
+	}
+	return nil
 }
 
 // Cache management methods
@@ -405,6 +877,23 @@ func (s *StoreMethods) GetCacheStats() *types.CacheStats {
 	return s.cache.GetStats()
 }
 
+// StartCacheCleanup removes expired cache entries on the configured
+// interval until ctx is done.
+func (s *StoreMethods) StartCacheCleanup(ctx context.Context) {
+	s.cache.StartCleanup(ctx)
+}
+
+// ResetCacheStats zeroes the cache hit and miss counters.
+func (s *StoreMethods) ResetCacheStats() error {
+	return s.cache.ResetStats()
+}
+
+// FlushCache saves cache statistics recorded since the cache was last
+// written.
+func (s *StoreMethods) FlushCache() error {
+	return s.cache.Flush()
+}
+
 // CleanupCache removes old entries from the cache.
 func (s *StoreMethods) CleanupCache() error {
 	return s.cache.Cleanup()
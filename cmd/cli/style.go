@@ -0,0 +1,213 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/dfanso/commit-msg/cmd/cli/store"
+	"github.com/dfanso/commit-msg/internal/config"
+	"github.com/dfanso/commit-msg/internal/examples"
+	"github.com/dfanso/commit-msg/internal/git"
+	"github.com/dfanso/commit-msg/internal/logging"
+	"github.com/dfanso/commit-msg/internal/tui"
+	"github.com/dfanso/commit-msg/pkg/types"
+	"github.com/pterm/pterm"
+)
+
+// exampleStore returns the store of accepted messages, or nil when its
+// location cannot be determined.
+func exampleStore() *examples.Store {
+	path, err := examples.DefaultPath()
+	if err != nil {
+		logging.Debug("examples unavailable", "error", err)
+		return nil
+	}
+	return examples.NewStore(path)
+}
+
+// exampleRepoKey identifies the repository at dir in the examples file.
+func exampleRepoKey(dir string) string {
+	if root, err := git.RepoRoot(dir); err == nil {
+		return root
+	}
+	return dir
+}
+
+// acceptedExamples returns the best recent accepted messages to use as
+// few-shot examples for the repository at dir.
+func acceptedExamples(dir string) []string {
+	store := exampleStore()
+	if store == nil {
+		return nil
+	}
+
+	selected, err := store.Select(exampleRepoKey(dir), examples.DefaultCount)
+	if err != nil {
+		logging.Debug("failed to load examples", "error", err)
+		return nil
+	}
+	logging.Debug("loaded examples", "count", len(selected))
+	return selected
+}
+
+// rememberAcceptedMessage records a message the user accepted in the review
+// screen. Failures are logged and otherwise ignored.
+func rememberAcceptedMessage(dir, message string) {
+	store := exampleStore()
+	if store == nil {
+		return
+	}
+	if err := store.Add(message, exampleRepoKey(dir)); err != nil {
+		logging.Debug("failed to record accepted message", "error", err)
+	}
+}
+
+// ResetStyle forgets every learned example message.
+func ResetStyle() error {
+	path, err := examples.DefaultPath()
+	if err != nil {
+		return err
+	}
+	if err := examples.NewStore(path).Reset(); err != nil {
+		return err
+	}
+
+	fmt.Println("Learned commit message examples cleared")
+	return nil
+}
+
+// namedStyle is a built-in style preset and the short name --style selects
+// it by.
+type namedStyle struct {
+	name   string
+	preset tui.StylePreset
+}
+
+var (
+	stylePresetsOnce sync.Once
+	stylePresets     []tui.StylePreset
+)
+
+// loadStylePresets returns the built-in style presets followed by the ones
+// saved in config.json, read once per run.
+func loadStylePresets() []tui.StylePreset {
+	stylePresetsOnce.Do(func() {
+		for _, builtin := range builtinStyles {
+			stylePresets = append(stylePresets, builtin.preset)
+		}
+		saved, err := config.LoadStyles()
+		if err != nil {
+			pterm.Warning.Printf("Ignoring saved style presets: %v\n", err)
+		}
+		for _, preset := range saved {
+			stylePresets = append(stylePresets, tui.StylePreset{Label: preset.Name, Instruction: preset.Instruction})
+		}
+	})
+	return stylePresets
+}
+
+// resolveStyle returns the style selected with --style and --instruction.
+// name is matched ignoring case against the names of the built-in presets
+// and the labels of all presets; an empty name selects the default style.
+// instruction is added to the preset's instruction.
+func resolveStyle(name, instruction string) (tui.StylePreset, error) {
+	preset, err := findStyle(strings.TrimSpace(name))
+	if err != nil {
+		return tui.StylePreset{}, err
+	}
+
+	instruction = strings.TrimSpace(instruction)
+	switch {
+	case instruction == "":
+	case strings.TrimSpace(preset.Instruction) == "":
+		// An unlabelled instruction is shown as custom in the review screen.
+		preset = tui.StylePreset{Instruction: instruction}
+	default:
+		preset.Instruction = strings.TrimSpace(preset.Instruction) + "\n" + instruction
+	}
+	return preset, nil
+}
+
+func findStyle(name string) (tui.StylePreset, error) {
+	if name == "" {
+		return tui.StylePreset{}, nil
+	}
+
+	var names []string
+	for _, builtin := range builtinStyles {
+		if strings.EqualFold(builtin.name, name) {
+			return builtin.preset, nil
+		}
+		names = append(names, builtin.name)
+	}
+	presets := loadStylePresets()
+	for _, preset := range presets {
+		if strings.EqualFold(preset.Label, name) {
+			return preset, nil
+		}
+	}
+	for _, preset := range presets[len(builtinStyles):] {
+		names = append(names, preset.Label)
+	}
+	return tui.StylePreset{}, fmt.Errorf("unknown style %q; available styles: %s", name, strings.Join(names, ", "))
+}
+
+// styleOptions returns the generation options carrying preset's
+// instruction, or nil for the default style.
+func styleOptions(preset tui.StylePreset) *types.GenerationOptions {
+	if strings.TrimSpace(preset.Instruction) == "" {
+		return nil
+	}
+	return &types.GenerationOptions{StyleInstruction: preset.Instruction}
+}
+
+// AddStyle saves a named style preset, replacing one with the same name.
+func AddStyle(name, instruction string) error {
+	name = strings.TrimSpace(name)
+	instruction = strings.TrimSpace(instruction)
+	if name == "" || instruction == "" {
+		return fmt.Errorf("a style preset needs a name and an instruction")
+	}
+	for _, builtin := range builtinStyles {
+		if strings.EqualFold(builtin.name, name) || strings.EqualFold(builtin.preset.Label, name) {
+			return fmt.Errorf("%q is a built-in style", name)
+		}
+	}
+
+	if err := store.SaveStylePreset(types.StylePreset{Name: name, Instruction: instruction}); err != nil {
+		return err
+	}
+	pterm.Success.Printf("Saved style %q\n", name)
+	return nil
+}
+
+// RemoveStyle deletes a saved style preset.
+func RemoveStyle(name string) error {
+	if err := store.RemoveStylePreset(strings.TrimSpace(name)); err != nil {
+		return err
+	}
+	pterm.Success.Printf("Removed style %q\n", name)
+	return nil
+}
+
+// ListStyles shows the built-in and saved style presets.
+func ListStyles() error {
+	saved, err := config.LoadStyles()
+	if err != nil {
+		return err
+	}
+
+	tableData := [][]string{{"Style", "Instruction"}}
+	for _, builtin := range builtinStyles {
+		instruction := builtin.preset.Instruction
+		if instruction == "" {
+			instruction = "(none)"
+		}
+		tableData = append(tableData, []string{builtin.name, instruction})
+	}
+	for _, preset := range saved {
+		tableData = append(tableData, []string{preset.Name, preset.Instruction})
+	}
+	return pterm.DefaultTable.WithHasHeader().WithData(tableData).Render()
+}
@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/dfanso/commit-msg/cmd/cli/store"
+	"github.com/dfanso/commit-msg/internal/config"
+	"github.com/dfanso/commit-msg/internal/logging"
+	"github.com/dfanso/commit-msg/internal/telemetry"
+	"github.com/pterm/pterm"
+)
+
+var (
+	telemetrySettingsOnce sync.Once
+	telemetrySettings     telemetry.Settings
+)
+
+// loadTelemetrySettings reads the "telemetry" section of config.json once
+// per run.
+func loadTelemetrySettings() telemetry.Settings {
+	telemetrySettingsOnce.Do(func() {
+		settings, err := config.LoadTelemetry()
+		if err != nil {
+			pterm.Warning.Printf("Ignoring telemetry settings: %v\n", err)
+		}
+		telemetrySettings = settings
+	})
+	return telemetrySettings
+}
+
+// recordTelemetry adds e to the local metrics when telemetry is enabled.
+// Requests the user cancelled say nothing about the provider and are not
+// recorded.
+func recordTelemetry(e telemetry.Event) {
+	if !loadTelemetrySettings().Enabled || errors.Is(e.Err, context.Canceled) {
+		return
+	}
+
+	path, err := telemetry.DefaultPath()
+	if err != nil {
+		logging.Debug("telemetry unavailable", "error", err)
+		return
+	}
+	if err := telemetry.NewLog(path).Record(e); err != nil {
+		logging.Debug("failed to record telemetry", "error", err)
+	}
+}
+
+// SetTelemetry turns local telemetry on or off.
+func SetTelemetry(enabled bool) error {
+	if err := store.SaveTelemetryEnabled(enabled); err != nil {
+		return fmt.Errorf("failed to save telemetry setting: %w", err)
+	}
+	if enabled {
+		pterm.Success.Println("Telemetry enabled. Provider latency, failures, and regenerations are recorded locally; no diffs or messages are kept.")
+	} else {
+		pterm.Success.Println("Telemetry disabled. Recorded metrics are kept until: commit telemetry reset")
+	}
+	return nil
+}
+
+// ShowTelemetry displays the recorded per-provider metrics.
+func ShowTelemetry() error {
+	path, err := telemetry.DefaultPath()
+	if err != nil {
+		return err
+	}
+
+	metrics, since, err := telemetry.NewLog(path).Summary()
+	if err != nil {
+		return err
+	}
+
+	pterm.DefaultHeader.WithFullWidth().
+		WithBackgroundStyle(pterm.NewStyle(pterm.BgBlue)).
+		WithTextStyle(pterm.NewStyle(pterm.FgWhite, pterm.Bold)).
+		Println("Provider Telemetry")
+
+	pterm.Println()
+
+	if !loadTelemetrySettings().Enabled {
+		pterm.Info.Println("Telemetry is off. Turn it on with: commit telemetry enable")
+	}
+	if len(metrics) == 0 {
+		pterm.Info.Println("No metrics recorded yet.")
+		return nil
+	}
+
+	tableData := [][]string{{"Provider", "Model", "Requests", "Median", "p95", "Failure Rate", "Regenerated", "Cache Hits"}}
+	for _, m := range metrics {
+		model := m.Model
+		if model == "" {
+			model = "-"
+		}
+		tableData = append(tableData, []string{
+			m.Provider.String(),
+			model,
+			fmt.Sprintf("%d", m.Requests),
+			formatLatency(m.Latency(50)),
+			formatLatency(m.Latency(95)),
+			fmt.Sprintf("%.0f%%", m.FailureRate()*100),
+			fmt.Sprintf("%.0f%%", m.RegenerationRate()*100),
+			fmt.Sprintf("%d", m.CacheHits),
+		})
+	}
+	if err := pterm.DefaultTable.WithHasHeader().WithData(tableData).Render(); err != nil {
+		return err
+	}
+
+	pterm.Println()
+	pterm.Info.Printf("Recorded since %s. Export with: commit telemetry export\n", since.Local().Format("2006-01-02"))
+	return nil
+}
+
+func formatLatency(d time.Duration) string {
+	if d == 0 {
+		return "-"
+	}
+	return d.Round(10 * time.Millisecond).String()
+}
+
+// ExportTelemetry writes the recorded metrics as JSON to output, or to
+// stdout when output is empty.
+func ExportTelemetry(output string) error {
+	path, err := telemetry.DefaultPath()
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if output != "" {
+		f, err := os.OpenFile(output, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", output, err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if err := telemetry.NewLog(path).Export(w); err != nil {
+		return err
+	}
+	if output != "" {
+		pterm.Success.Printf("Telemetry exported to %s\n", output)
+	}
+	return nil
+}
+
+// ResetTelemetry deletes the recorded metrics.
+func ResetTelemetry() error {
+	path, err := telemetry.DefaultPath()
+	if err != nil {
+		return err
+	}
+	if err := telemetry.NewLog(path).Reset(); err != nil {
+		return err
+	}
+	pterm.Success.Println("Telemetry metrics deleted.")
+	return nil
+}
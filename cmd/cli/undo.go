@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dfanso/commit-msg/internal/display"
+	"github.com/dfanso/commit-msg/internal/git"
+	"github.com/dfanso/commit-msg/internal/platform"
+	"github.com/dfanso/commit-msg/pkg/types"
+	"github.com/pterm/pterm"
+)
+
+// UndoOptions controls commit undo.
+type UndoOptions struct {
+	// RepoPath is the repository to work in; empty means the current
+	// directory.
+	RepoPath string
+	// Force undoes HEAD even when it was not made by --auto or was already
+	// pushed.
+	Force bool
+}
+
+// UndoAutoCommit undoes the commit made by the last commit --auto, keeping
+// its changes staged and putting its message back on the clipboard. The
+// process exits with one of the documented Exit* codes on failure.
+func UndoAutoCommit(opts UndoOptions) {
+	dir, err := resolveRepoPath(opts.RepoPath)
+	if err != nil {
+		exitf(ExitError, "%v\n", err)
+	}
+	if !git.IsRepository(dir) {
+		exitf(ExitNotRepository, "Not a Git repository: %s\n", dir)
+	}
+	repoConfig := &types.RepoConfig{Path: dir}
+
+	hash, err := git.ResolveCommit(repoConfig, "HEAD")
+	if err != nil {
+		exitf(ExitError, "Nothing to undo: the repository has no commits\n")
+	}
+	shortHash := hash[:min(len(hash), 7)]
+
+	if !opts.Force {
+		auto, err := git.IsAutoCommit(repoConfig)
+		if err != nil {
+			exitf(ExitError, "%v\n", err)
+		}
+		if !auto {
+			exitf(ExitError, "HEAD (%s) was not created by commit --auto; pass --force to undo it anyway\n", shortHash)
+		}
+		pushed, err := git.IsPushed(repoConfig)
+		if err != nil {
+			exitf(ExitError, "%v\n", err)
+		}
+		if pushed {
+			exitf(ExitError, "HEAD (%s) is already on a remote branch; pass --force to undo it anyway\n", shortHash)
+		}
+	}
+
+	message, err := git.CommitMessage(repoConfig, hash)
+	if err != nil {
+		exitf(ExitError, "Failed to read commit message: %v\n", err)
+	}
+	message = strings.TrimSpace(message)
+
+	if err := git.UndoCommit(repoConfig); err != nil {
+		exitf(ExitError, "Failed to undo %s: %v\n", shortHash, err)
+	}
+
+	if quietMode {
+		fmt.Println(message)
+	} else {
+		pterm.Success.Printf("Undid %s; its changes are still staged\n", shortHash)
+		pterm.Println()
+		display.ShowCommitMessage(message)
+	}
+	if err := platform.CopyToClipboard(message); err != nil {
+		pterm.Warning.Printf("Could not copy to clipboard: %v\n", err)
+	} else if !quietMode {
+		pterm.Success.Println("Commit message copied to clipboard!")
+	}
+}
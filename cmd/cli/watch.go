@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dfanso/commit-msg/cmd/cli/store"
+	"github.com/dfanso/commit-msg/internal/git"
+	"github.com/dfanso/commit-msg/internal/llm"
+	"github.com/dfanso/commit-msg/internal/stats"
+	"github.com/dfanso/commit-msg/internal/watch"
+	"github.com/dfanso/commit-msg/pkg/types"
+	"github.com/pterm/pterm"
+)
+
+// draftFileName is the default draft location inside the git directory.
+const draftFileName = "COMMIT_DRAFT"
+
+// WatchOptions controls a watch session.
+type WatchOptions struct {
+	// RepoPath is the repository to watch. When empty the current directory
+	// is used.
+	RepoPath string
+	// Debounce is how long the tree must stay quiet before regenerating.
+	Debounce time.Duration
+	// Output is the draft file to keep updated. When empty the draft is
+	// written to COMMIT_DRAFT in the git directory.
+	Output string
+}
+
+// WatchDraft monitors the working tree and keeps a draft commit message up
+// to date until interrupted.
+func WatchDraft(Store *store.StoreMethods, opts WatchOptions) error {
+	useLLM, err := Store.DefaultLLMKey()
+	if err != nil {
+		return fmt.Errorf("no LLM configured, run: commit llm setup")
+	}
+	commitLLM := useLLM.LLM
+
+	dir, err := resolveRepoPath(opts.RepoPath)
+	if err != nil {
+		return err
+	}
+	if !git.IsRepository(dir) {
+		return fmt.Errorf("not a Git repository: %s", dir)
+	}
+
+	root, err := git.RepoRoot(dir)
+	if err != nil {
+		return err
+	}
+
+	output := opts.Output
+	if output == "" {
+		gitDir, err := git.GitDir(root)
+		if err != nil {
+			return err
+		}
+		output = filepath.Join(gitDir, draftFileName)
+	}
+	output, err = filepath.Abs(output)
+	if err != nil {
+		return fmt.Errorf("failed to resolve draft path: %w", err)
+	}
+
+	providerInstance, err := llm.NewProvider(commitLLM, llm.ProviderOptions{
+		Credential: useLLM.APIKey,
+		Config:     providerConfig(),
+	})
+	if err != nil {
+		displayProviderError(commitLLM, err)
+		return fmt.Errorf("failed to initialise %s provider", commitLLM)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	Store.StartCacheCleanup(ctx)
+
+	repoConfig := types.RepoConfig{Path: root, Limits: loadContentLimits()}
+	lastChanges := ""
+
+	update := func() {
+		set, err := git.CollectChanges(&repoConfig)
+		if err != nil {
+			pterm.Warning.Printf("Failed to get Git changes: %v\n", err)
+			return
+		}
+		fileStats := stats.FromChangeSet(set)
+		if fileStats.TotalFiles == 0 {
+			if lastChanges != "" {
+				if err := os.Remove(output); err != nil && !os.IsNotExist(err) {
+					pterm.Warning.Printf("Failed to clear draft: %v\n", err)
+				}
+				lastChanges = ""
+			}
+			pterm.Info.Printf("[%s] No changes; waiting...\n", time.Now().Format("15:04:05"))
+			return
+		}
+
+		changes := truncateLargeDiff(condenseChanges(root, set.Prompt()))
+		if changes == lastChanges {
+			return
+		}
+
+		workspace, changedPackages := detectChangedPackages(&repoConfig)
+		prompt := newPromptContext(root, workspace, changedPackages)
+
+		generate := withBlocklist(func(genOpts *types.GenerationOptions) (*types.GenerationResult, error) {
+			return generateMessageWithCache(ctx, providerInstance, Store, commitLLM, changes, genOpts)
+		})
+		generated, err := generate(prompt.apply(withAttempt(nil, 1)))
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			displayProviderError(commitLLM, err)
+			return
+		}
+
+		message := formatMessage(correctMessage(root, generated.Message))
+		if err := os.WriteFile(output, []byte(message+"\n"), 0o644); err != nil {
+			pterm.Warning.Printf("Failed to write draft: %v\n", err)
+			return
+		}
+		lastChanges = changes
+
+		subject, _, _ := strings.Cut(message, "\n")
+		pterm.Success.Printf("[%s] Draft updated: %s\n", time.Now().Format("15:04:05"), subject)
+	}
+
+	pterm.Info.Printf("Watching %s (draft: %s). Press Ctrl+C to stop.\n", root, output)
+	pterm.Info.Printf("Commit with: git commit -F %s\n", output)
+	update()
+
+	return watch.Run(ctx, watch.Options{
+		Root:     root,
+		Debounce: opts.Debounce,
+		Ignore:   []string{output},
+	}, update)
+}
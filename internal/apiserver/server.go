@@ -0,0 +1,292 @@
+package apiserver
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dfanso/commit-msg/internal/git"
+	"github.com/dfanso/commit-msg/internal/logging"
+	"github.com/dfanso/commit-msg/internal/scrubber"
+	"github.com/dfanso/commit-msg/pkg/types"
+)
+
+// DefaultAddr is the address used by commit serve --http without a value.
+const DefaultAddr = "127.0.0.1:7345"
+
+// maxRequestBytes bounds request bodies; requests only carry a path and a
+// style instruction.
+const maxRequestBytes = 64 << 10
+
+// GenerateFunc produces a commit message for the scrubbed changes of the
+// repository at repoPath.
+type GenerateFunc func(ctx context.Context, repoPath, changes string, opts *types.GenerationOptions) (*types.GenerationResult, error)
+
+// Options configures the HTTP API.
+type Options struct {
+	// Token is the bearer token every request must present.
+	Token string
+	// DefaultPath is the repository used when a request omits "path".
+	DefaultPath string
+	// Generate backs POST /generate.
+	Generate GenerateFunc
+	// CacheStats backs GET /cache/stats.
+	CacheStats func() *types.CacheStats
+}
+
+// GenerateRequest is the body accepted by POST /generate.
+type GenerateRequest struct {
+	Path  string `json:"path,omitempty"`
+	Style string `json:"style,omitempty"`
+}
+
+// GenerateResponse is returned by POST /generate. Alongside the message it
+// reports the provider, model, token usage, cost, duration, whether the
+// message came from the cache, and what was scrubbed from the diff.
+type GenerateResponse struct {
+	types.GenerationResult
+}
+
+// ChangesResponse is returned by GET /changes.
+type ChangesResponse struct {
+	Files   []string `json:"files"`
+	Changes string   `json:"changes"`
+}
+
+// ErrorResponse is the body of every non-2xx response.
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// NewToken returns a random token suitable for Options.Token.
+func NewToken() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate API token: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// ResolveAddr normalises addr and enforces loopback-only binding unless
+// allowRemote is set. A bare ":port" binds to 127.0.0.1.
+func ResolveAddr(addr string, allowRemote bool) (string, error) {
+	if strings.TrimSpace(addr) == "" {
+		return DefaultAddr, nil
+	}
+
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", fmt.Errorf("invalid listen address %q: %w", addr, err)
+	}
+	if host == "" {
+		host = "127.0.0.1"
+	}
+
+	if !allowRemote && !isLoopback(host) {
+		return "", fmt.Errorf("refusing to listen on non-loopback address %q; pass --allow-remote to override", addr)
+	}
+	return net.JoinHostPort(host, port), nil
+}
+
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// Handler returns the HTTP handler serving the API.
+func Handler(opts Options) http.Handler {
+	s := &apiServer{opts: opts}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/generate", s.handleGenerate)
+	mux.HandleFunc("/changes", s.handleChanges)
+	mux.HandleFunc("/cache/stats", s.handleCacheStats)
+
+	return s.authenticate(mux)
+}
+
+// ListenAndServe serves the API on addr until ctx is cancelled.
+func ListenAndServe(ctx context.Context, addr string, opts Options) error {
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           Handler(opts),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			return err
+		}
+		if err := <-errCh; err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	}
+}
+
+type apiServer struct {
+	opts Options
+}
+
+// authenticate rejects requests without the configured bearer token.
+func (s *apiServer) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || s.opts.Token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(s.opts.Token)) != 1 {
+			writeError(w, http.StatusUnauthorized, "missing or invalid API token")
+			return
+		}
+		logging.Debug("api request", "method", r.Method, "path", r.URL.Path)
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *apiServer) handleGenerate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "use POST")
+		return
+	}
+	if s.opts.Generate == nil {
+		writeError(w, http.StatusNotImplemented, "commit message generation is not configured")
+		return
+	}
+
+	var req GenerateRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBytes)).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid JSON body: "+err.Error())
+			return
+		}
+	}
+
+	repoPath, status, err := s.repoPath(req.Path)
+	if err != nil {
+		writeError(w, status, err.Error())
+		return
+	}
+
+	config := &types.RepoConfig{Path: repoPath}
+	files, err := git.ChangedFiles(config)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if len(files) == 0 {
+		writeError(w, http.StatusUnprocessableEntity, "no changes detected in the Git repository")
+		return
+	}
+
+	changes, err := git.GetChanges(config)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	result, err := s.opts.Generate(r.Context(), repoPath, changes, &types.GenerationOptions{
+		StyleInstruction: strings.TrimSpace(req.Style),
+		Attempt:          1,
+	})
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+
+	result.Message = scrubber.ScrubDiff(strings.TrimSpace(result.Message))
+	result.Alternatives = scrubber.ScrubAll(result.Alternatives)
+	writeJSON(w, http.StatusOK, GenerateResponse{GenerationResult: *result})
+}
+
+func (s *apiServer) handleChanges(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "use GET")
+		return
+	}
+
+	repoPath, status, err := s.repoPath(r.URL.Query().Get("path"))
+	if err != nil {
+		writeError(w, status, err.Error())
+		return
+	}
+
+	config := &types.RepoConfig{Path: repoPath}
+	files, err := git.ChangedFiles(config)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	changes, err := git.GetChanges(config)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if files == nil {
+		files = []string{}
+	}
+	writeJSON(w, http.StatusOK, ChangesResponse{Files: scrubber.ScrubAll(files), Changes: changes})
+}
+
+func (s *apiServer) handleCacheStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "use GET")
+		return
+	}
+	if s.opts.CacheStats == nil {
+		writeError(w, http.StatusNotImplemented, "cache statistics are not available")
+		return
+	}
+	writeJSON(w, http.StatusOK, s.opts.CacheStats())
+}
+
+// repoPath resolves a requested repository path, returning the HTTP status
+// to use when it is invalid.
+func (s *apiServer) repoPath(path string) (string, int, error) {
+	if strings.TrimSpace(path) == "" {
+		path = s.opts.DefaultPath
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", http.StatusBadRequest, fmt.Errorf("invalid path: %w", err)
+	}
+	if !git.IsRepository(abs) {
+		return "", http.StatusBadRequest, fmt.Errorf("not a Git repository: %s", abs)
+	}
+	return abs, http.StatusOK, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logging.Debug("failed to write API response", "error", err)
+	}
+}
+
+// writeError writes an ErrorResponse. message is scrubbed, since errors
+// from git and providers can quote the changes.
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, ErrorResponse{Error: scrubber.ScrubDiff(message)})
+}
@@ -0,0 +1,198 @@
+package apiserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dfanso/commit-msg/pkg/types"
+)
+
+const testToken = "secret-token"
+
+func newRepo(t *testing.T) string {
+	t.Helper()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git executable not available")
+	}
+
+	dir := t.TempDir()
+	cmd := exec.Command("git", "init", dir)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("failed to init git repo: %v: %s", err, string(output))
+	}
+	return dir
+}
+
+func do(t *testing.T, handler http.Handler, method, target, body string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestResolveAddr(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		addr        string
+		allowRemote bool
+		want        string
+		wantErr     bool
+	}{
+		{addr: "", want: DefaultAddr},
+		{addr: ":7345", want: "127.0.0.1:7345"},
+		{addr: "localhost:8080", want: "localhost:8080"},
+		{addr: "[::1]:8080", want: "[::1]:8080"},
+		{addr: "0.0.0.0:7345", wantErr: true},
+		{addr: "0.0.0.0:7345", allowRemote: true, want: "0.0.0.0:7345"},
+		{addr: "7345", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ResolveAddr(tt.addr, tt.allowRemote)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ResolveAddr(%q, %v) = %q, want error", tt.addr, tt.allowRemote, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ResolveAddr(%q, %v) returned error: %v", tt.addr, tt.allowRemote, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ResolveAddr(%q, %v) = %q, want %q", tt.addr, tt.allowRemote, got, tt.want)
+		}
+	}
+}
+
+func TestAuthentication(t *testing.T) {
+	t.Parallel()
+
+	handler := Handler(Options{Token: testToken, CacheStats: func() *types.CacheStats { return &types.CacheStats{} }})
+
+	for _, header := range []string{"", "Bearer wrong", testToken} {
+		req := httptest.NewRequest(http.MethodGet, "/cache/stats", nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("Authorization %q: status = %d, want %d", header, rec.Code, http.StatusUnauthorized)
+		}
+	}
+
+	if rec := do(t, handler, http.MethodGet, "/cache/stats", ""); rec.Code != http.StatusOK {
+		t.Fatalf("status with valid token = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	// An unset token must never authenticate anyone.
+	open := Handler(Options{})
+	req := httptest.NewRequest(http.MethodGet, "/cache/stats", nil)
+	req.Header.Set("Authorization", "Bearer ")
+	rec := httptest.NewRecorder()
+	open.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("empty token status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestCacheStats(t *testing.T) {
+	t.Parallel()
+
+	handler := Handler(Options{
+		Token:      testToken,
+		CacheStats: func() *types.CacheStats { return &types.CacheStats{TotalEntries: 3, TotalHits: 2} },
+	})
+
+	rec := do(t, handler, http.MethodGet, "/cache/stats", "")
+	var stats types.CacheStats
+	if err := json.Unmarshal(rec.Body.Bytes(), &stats); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if stats.TotalEntries != 3 || stats.TotalHits != 2 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestChangesAndGenerate(t *testing.T) {
+	t.Parallel()
+
+	dir := newRepo(t)
+
+	var gotPath, gotStyle string
+	handler := Handler(Options{
+		Token:       testToken,
+		DefaultPath: dir,
+		Generate: func(ctx context.Context, repoPath, changes string, opts *types.GenerationOptions) (*types.GenerationResult, error) {
+			gotPath = repoPath
+			gotStyle = opts.StyleInstruction
+			return &types.GenerationResult{
+				Message:  "feat: add greeting\n",
+				Provider: types.ProviderOllama,
+				Model:    "llama3.1",
+				CacheHit: true,
+			}, nil
+		},
+	})
+
+	if rec := do(t, handler, http.MethodPost, "/generate", ""); rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("generate without changes status = %d, want %d", rec.Code, http.StatusUnprocessableEntity)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "hello.txt"), []byte("hello\n"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	rec := do(t, handler, http.MethodGet, "/changes", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("changes status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var changes ChangesResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &changes); err != nil {
+		t.Fatalf("failed to decode changes: %v", err)
+	}
+	if len(changes.Files) != 1 || changes.Files[0] != "hello.txt" {
+		t.Fatalf("changes files = %v, want [hello.txt]", changes.Files)
+	}
+
+	rec = do(t, handler, http.MethodPost, "/generate", `{"style":"Be terse."}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("generate status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var generated GenerateResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &generated); err != nil {
+		t.Fatalf("failed to decode generate response: %v", err)
+	}
+	if generated.Message != "feat: add greeting" {
+		t.Fatalf("message = %q, want %q", generated.Message, "feat: add greeting")
+	}
+	if generated.Provider != types.ProviderOllama || generated.Model != "llama3.1" || !generated.CacheHit {
+		t.Fatalf("metadata = %+v, want provider, model, and cache hit", generated.GenerationResult)
+	}
+	if gotStyle != "Be terse." {
+		t.Fatalf("style = %q, want %q", gotStyle, "Be terse.")
+	}
+	if gotPath == "" {
+		t.Fatal("expected generator to receive the repository path")
+	}
+
+	if rec := do(t, handler, http.MethodGet, "/generate", ""); rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("GET /generate status = %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+	if rec := do(t, handler, http.MethodGet, "/changes?path="+t.TempDir(), ""); rec.Code != http.StatusBadRequest {
+		t.Fatalf("changes for non-repo status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
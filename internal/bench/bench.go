@@ -0,0 +1,155 @@
+// Package bench measures the local stages of commit message generation —
+// collecting, scrubbing, hashing, and prompting over the changes — on a
+// synthetic repository, and checks each against a time budget that scales
+// with the size of the diff.
+package bench
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/dfanso/commit-msg/internal/benchdata"
+	"github.com/dfanso/commit-msg/internal/cache"
+	"github.com/dfanso/commit-msg/internal/git"
+	"github.com/dfanso/commit-msg/internal/scrubber"
+	"github.com/dfanso/commit-msg/pkg/types"
+)
+
+// Stage names, in the order Run reports them.
+const (
+	StageCollect = "collect changes"
+	StageScrub   = "scrub"
+	StageHash    = "hash"
+	StagePrompt  = "build prompt"
+	StageTotal   = "total"
+)
+
+// Budget is the longest a stage may take: Floor plus PerMB for every
+// mebibyte of collected diff.
+type Budget struct {
+	Floor time.Duration
+	PerMB time.Duration
+}
+
+// For returns the budget for a diff of size bytes.
+func (b Budget) For(size int) time.Duration {
+	return b.Floor + time.Duration(float64(b.PerMB)*float64(size)/(1<<20))
+}
+
+// Budgets are about three times what each stage takes on a modest machine,
+// so they catch a stage growing faster than the diff rather than noise.
+var Budgets = map[string]Budget{
+	StageCollect: {Floor: time.Second, PerMB: 6 * time.Second},
+	StageScrub:   {Floor: 250 * time.Millisecond, PerMB: 6 * time.Second},
+	StageHash:    {Floor: 50 * time.Millisecond, PerMB: 250 * time.Millisecond},
+	StagePrompt:  {Floor: 50 * time.Millisecond, PerMB: 100 * time.Millisecond},
+}
+
+// Options controls a run.
+type Options struct {
+	// Files and Lines size the synthetic repository: Files changed files
+	// with Lines changed lines each.
+	Files int
+	Lines int
+	// Runs is how many times each stage is timed; the median is reported.
+	Runs int
+	// Dir is where the synthetic repository is written; empty uses a
+	// temporary directory that is removed afterwards.
+	Dir string
+}
+
+// Result is the median time of one stage.
+type Result struct {
+	Stage  string
+	Median time.Duration
+	Budget time.Duration
+}
+
+// OverBudget reports whether the stage took longer than its budget.
+func (r Result) OverBudget() bool {
+	return r.Median > r.Budget
+}
+
+// Run builds the synthetic repository and times each stage over it,
+// returning one result per stage followed by their total, and the size of
+// the collected diff in bytes.
+func Run(opts Options) ([]Result, int, error) {
+	if opts.Files <= 0 || opts.Lines <= 0 {
+		return nil, 0, errors.New("bench: files and lines must be positive")
+	}
+	if opts.Runs <= 0 {
+		opts.Runs = 1
+	}
+
+	dir := opts.Dir
+	if dir == "" {
+		tmp, err := os.MkdirTemp("", "commit-bench-")
+		if err != nil {
+			return nil, 0, err
+		}
+		defer os.RemoveAll(tmp)
+		dir = filepath.Join(tmp, "repo")
+	}
+	if err := benchdata.WriteRepo(dir, opts.Files, opts.Lines); err != nil {
+		return nil, 0, err
+	}
+
+	repo := &types.RepoConfig{Path: dir}
+	hasher := cache.NewDiffHasher()
+	var changes, scrubbed string
+	stages := []struct {
+		name string
+		run  func() error
+	}{
+		{StageCollect, func() (err error) {
+			changes, err = git.GetChanges(repo)
+			return err
+		}},
+		{StageScrub, func() error {
+			scrubbed = scrubber.ScrubDiff(changes)
+			return nil
+		}},
+		{StageHash, func() error {
+			hasher.GenerateHash(scrubbed, nil)
+			return nil
+		}},
+		{StagePrompt, func() error {
+			types.BuildCommitPrompt(scrubbed, nil)
+			return nil
+		}},
+	}
+
+	samples := make([][]time.Duration, len(stages))
+	for run := 0; run < opts.Runs; run++ {
+		for i, stage := range stages {
+			start := time.Now()
+			if err := stage.run(); err != nil {
+				return nil, 0, err
+			}
+			samples[i] = append(samples[i], time.Since(start))
+		}
+	}
+
+	results := make([]Result, 0, len(stages)+1)
+	total := Result{Stage: StageTotal}
+	for i, stage := range stages {
+		result := Result{
+			Stage:  stage.name,
+			Median: median(samples[i]),
+			Budget: Budgets[stage.name].For(len(changes)),
+		}
+		total.Median += result.Median
+		total.Budget += result.Budget
+		results = append(results, result)
+	}
+	return append(results, total), len(changes), nil
+}
+
+func median(samples []time.Duration) time.Duration {
+	sorted := append([]time.Duration(nil), samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted[len(sorted)/2]
+}
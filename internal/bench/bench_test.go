@@ -0,0 +1,55 @@
+package bench
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRun(t *testing.T) {
+	results, size, err := Run(Options{Files: 5, Lines: 10, Runs: 3, Dir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if size == 0 {
+		t.Fatal("expected the synthetic repository to have changes")
+	}
+
+	want := []string{StageCollect, StageScrub, StageHash, StagePrompt, StageTotal}
+	if len(results) != len(want) {
+		t.Fatalf("expected %d results, got %+v", len(want), results)
+	}
+	var sum time.Duration
+	for i, result := range results {
+		if result.Stage != want[i] {
+			t.Errorf("result %d is %q, want %q", i, result.Stage, want[i])
+		}
+		if result.Budget <= 0 {
+			t.Errorf("%s has no budget", result.Stage)
+		}
+		if result.Stage != StageTotal {
+			sum += result.Median
+		}
+	}
+	if total := results[len(results)-1]; total.Median != sum {
+		t.Errorf("total %v is not the sum of the stages, %v", total.Median, sum)
+	}
+}
+
+func TestRunRejectsEmptySizes(t *testing.T) {
+	if _, _, err := Run(Options{Files: 0, Lines: 10}); err == nil {
+		t.Fatal("expected zero files to be rejected")
+	}
+}
+
+func TestBudgetScalesWithSize(t *testing.T) {
+	budget := Budget{Floor: time.Second, PerMB: 2 * time.Second}
+	if got := budget.For(0); got != time.Second {
+		t.Errorf("For(0) = %v, want the floor", got)
+	}
+	if got := budget.For(1 << 19); got != 2*time.Second {
+		t.Errorf("For(512KiB) = %v, want 2s", got)
+	}
+	if !(Result{Median: 3 * time.Second, Budget: 2 * time.Second}).OverBudget() {
+		t.Error("expected a stage slower than its budget to be over budget")
+	}
+}
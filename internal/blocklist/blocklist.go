@@ -0,0 +1,74 @@
+// Package blocklist finds words and phrases that must not appear in a commit
+// message, such as profanity or internal code names.
+package blocklist
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Settings is the "blocklist" section of config.json.
+type Settings struct {
+	// Phrases lists the words and phrases generated messages may not
+	// contain. Matching ignores case and runs of whitespace.
+	Phrases []string `json:"phrases"`
+}
+
+// List matches messages against a set of blocked phrases.
+type List struct {
+	phrases  []string
+	patterns []*regexp.Regexp
+}
+
+// New returns a List blocking phrases. Blank phrases are ignored.
+func New(phrases []string) *List {
+	l := &List{}
+	for _, phrase := range phrases {
+		fields := strings.Fields(phrase)
+		if len(fields) == 0 {
+			continue
+		}
+		normalized := strings.Join(fields, " ")
+		quoted := make([]string, len(fields))
+		for i, field := range fields {
+			quoted[i] = regexp.QuoteMeta(field)
+		}
+		pattern := strings.Join(quoted, `\s+`)
+		// Only require a word boundary where the phrase itself starts or
+		// ends with a word character, so "f***" or "@oncall" still match.
+		if first, _ := utf8.DecodeRuneInString(normalized); isWordRune(first) {
+			pattern = `\b` + pattern
+		}
+		if last, _ := utf8.DecodeLastRuneInString(normalized); isWordRune(last) {
+			pattern += `\b`
+		}
+		l.phrases = append(l.phrases, normalized)
+		l.patterns = append(l.patterns, regexp.MustCompile(`(?i)`+pattern))
+	}
+	return l
+}
+
+// Empty reports whether the list blocks nothing.
+func (l *List) Empty() bool {
+	return l == nil || len(l.phrases) == 0
+}
+
+// Find returns the blocked phrases found in message, in list order.
+func (l *List) Find(message string) []string {
+	if l == nil {
+		return nil
+	}
+	var found []string
+	for i, pattern := range l.patterns {
+		if pattern.MatchString(message) {
+			found = append(found, l.phrases[i])
+		}
+	}
+	return found
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,45 @@
+package blocklist
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFind(t *testing.T) {
+	t.Parallel()
+
+	list := New([]string{"damn", "Project Falcon", "  ", "@oncall"})
+	tests := []struct {
+		name    string
+		message string
+		want    []string
+	}{
+		{name: "clean", message: "fix: handle empty config", want: nil},
+		{name: "word", message: "fix: DAMN race in worker", want: []string{"damn"}},
+		{name: "part of a word", message: "fix: update damnation docs", want: nil},
+		{name: "phrase across whitespace", message: "feat: ship project\nfalcon beta", want: []string{"Project Falcon"}},
+		{name: "symbol phrase", message: "chore: page @oncall on failure", want: []string{"@oncall"}},
+		{name: "several", message: "fix: damn project falcon", want: []string{"damn", "Project Falcon"}},
+	}
+
+	for _, tt := range tests {
+		if got := list.Find(tt.message); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Find(%q) = %q, want %q", tt.name, tt.message, got, tt.want)
+		}
+	}
+}
+
+func TestEmpty(t *testing.T) {
+	t.Parallel()
+
+	if !New(nil).Empty() || !New([]string{" "}).Empty() {
+		t.Fatal("expected lists without phrases to be empty")
+	}
+	var list *List
+	if !list.Empty() || list.Find("anything") != nil {
+		t.Fatal("expected a nil list to block nothing")
+	}
+	if New([]string{"wip"}).Empty() {
+		t.Fatal("expected a list with a phrase not to be empty")
+	}
+}
@@ -0,0 +1,83 @@
+// Package buildinfo describes the running commit-msg binary: its version,
+// the commit it was built from, and the Go toolchain and platform.
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+// Version is set at release time with
+// -ldflags "-X github.com/dfanso/commit-msg/internal/buildinfo.Version=v1.2.3".
+// When empty, the module version recorded by go install is used.
+var Version string
+
+// Info describes a build.
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	Modified  bool   `json:"modified,omitempty"`
+	GoVersion string `json:"go_version"`
+	OS        string `json:"os"`
+	Arch      string `json:"arch"`
+}
+
+// Get returns the build information of the running binary.
+func Get() Info {
+	bi, _ := debug.ReadBuildInfo()
+	return fromBuildInfo(Version, bi)
+}
+
+func fromBuildInfo(version string, bi *debug.BuildInfo) Info {
+	info := Info{
+		Version:   version,
+		GoVersion: runtime.Version(),
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+	}
+	if bi == nil {
+		if info.Version == "" {
+			info.Version = "dev"
+		}
+		return info
+	}
+
+	if info.Version == "" && bi.Main.Version != "" && bi.Main.Version != "(devel)" {
+		info.Version = bi.Main.Version
+	}
+	if info.Version == "" {
+		info.Version = "dev"
+	}
+	if bi.GoVersion != "" {
+		info.GoVersion = bi.GoVersion
+	}
+	for _, setting := range bi.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			info.Commit = setting.Value
+			if len(info.Commit) > 12 {
+				info.Commit = info.Commit[:12]
+			}
+		case "vcs.modified":
+			info.Modified = setting.Value == "true"
+		}
+	}
+	return info
+}
+
+// String formats the version with the commit it was built from, as shown by
+// commit --version.
+func (i Info) String() string {
+	s := i.Version
+	// Versions stamped by the go command since Go 1.24 already name the
+	// commit and whether the tree was modified.
+	if i.Commit != "" && !strings.Contains(s, i.Commit) {
+		s += " (" + i.Commit
+		if i.Modified {
+			s += ", modified"
+		}
+		s += ")"
+	}
+	return s
+}
@@ -0,0 +1,45 @@
+package buildinfo
+
+import (
+	"runtime/debug"
+	"testing"
+)
+
+func TestFromBuildInfo(t *testing.T) {
+	t.Parallel()
+
+	bi := &debug.BuildInfo{
+		GoVersion: "go1.24.7",
+		Main:      debug.Module{Path: "github.com/dfanso/commit-msg", Version: "v1.4.0"},
+		Settings: []debug.BuildSetting{
+			{Key: "vcs.revision", Value: "0123456789abcdef0123"},
+			{Key: "vcs.modified", Value: "true"},
+		},
+	}
+
+	info := fromBuildInfo("", bi)
+	if info.Version != "v1.4.0" || info.GoVersion != "go1.24.7" || info.Commit != "0123456789ab" || !info.Modified {
+		t.Fatalf("unexpected info: %+v", info)
+	}
+	if got := info.String(); got != "v1.4.0 (0123456789ab, modified)" {
+		t.Fatalf("String() = %q", got)
+	}
+
+	if got := fromBuildInfo("v2.0.0", bi).Version; got != "v2.0.0" {
+		t.Fatalf("expected the release version to win, got %q", got)
+	}
+
+	bi.Main.Version = "v0.0.0-20261016153105-0123456789ab+dirty"
+	if got := fromBuildInfo("", bi).String(); got != bi.Main.Version {
+		t.Fatalf("expected the commit not to be repeated, got %q", got)
+	}
+
+	bi.Main.Version = "(devel)"
+	bi.Settings = nil
+	if got := fromBuildInfo("", bi).String(); got != "dev" {
+		t.Fatalf("expected a development build, got %q", got)
+	}
+	if got := fromBuildInfo("", nil); got.Version != "dev" || got.GoVersion == "" || got.OS == "" {
+		t.Fatalf("unexpected info without build info: %+v", got)
+	}
+}
@@ -0,0 +1,244 @@
+package cache
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/dfanso/commit-msg/pkg/types"
+)
+
+// Backend persists the cache entries and statistics.
+type Backend interface {
+	// Load returns the stored entries and statistics; an empty store
+	// yields no entries and nil statistics.
+	Load() (map[string]*types.CacheEntry, *types.CacheStats, error)
+	// Save writes snapshot.
+	Save(snapshot Snapshot) error
+	// Clear removes every entry and the statistics.
+	Clear() error
+	// Size returns the bytes the cache takes in storage.
+	Size() int64
+}
+
+// Snapshot is the cache state handed to Backend.Save.
+type Snapshot struct {
+	Entries map[string]*types.CacheEntry
+	Stats   *types.CacheStats
+	Config  *types.CacheConfig
+	// Changed and Removed name the entries written or deleted since the
+	// last save. Backends that store entries individually write only
+	// these; the JSON file is always rewritten from Entries.
+	Changed map[string]bool
+	Removed map[string]bool
+}
+
+// cacheFile is the layout of cache.json.
+type cacheFile struct {
+	Entries map[string]*types.CacheEntry `json:"entries"`
+	Stats   *types.CacheStats            `json:"stats"`
+	Config  *types.CacheConfig           `json:"config"`
+}
+
+// jsonBackend keeps the whole cache in one JSON file.
+type jsonBackend struct {
+	path string
+}
+
+// NewJSONBackend returns a backend that keeps the cache in the JSON file at
+// path.
+func NewJSONBackend(path string) Backend {
+	return jsonBackend{path: path}
+}
+
+func (b jsonBackend) Load() (map[string]*types.CacheEntry, *types.CacheStats, error) {
+	data, err := os.ReadFile(b.path)
+	if os.IsNotExist(err) {
+		return nil, nil, nil // No cache file exists yet
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read cache file: %w", err)
+	}
+
+	var cacheData cacheFile
+	if err := json.Unmarshal(data, &cacheData); err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal cache data: %w", err)
+	}
+	return cacheData.Entries, cacheData.Stats, nil
+}
+
+func (b jsonBackend) Save(snapshot Snapshot) error {
+	// Ensure directory exists
+	if err := os.MkdirAll(filepath.Dir(b.path), 0700); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(cacheFile{
+		Entries: snapshot.Entries,
+		Stats:   snapshot.Stats,
+		Config:  snapshot.Config,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache data: %w", err)
+	}
+
+	if err := os.WriteFile(b.path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write cache file: %w", err)
+	}
+	return nil
+}
+
+func (b jsonBackend) Clear() error {
+	if err := os.Remove(b.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove cache file: %w", err)
+	}
+	return nil
+}
+
+func (b jsonBackend) Size() int64 {
+	if stat, err := os.Stat(b.path); err == nil {
+		return stat.Size()
+	}
+	return 0
+}
+
+// sqliteBackend keeps one row per entry in the database opened by
+// sqlstore.Open, so a save writes only what changed.
+type sqliteBackend struct {
+	db *sql.DB
+}
+
+// statsRow names the cache_meta row holding the statistics.
+const statsRow = "stats"
+
+// NewSQLiteBackend returns a backend that keeps the cache in db, a database
+// opened with sqlstore.Open.
+func NewSQLiteBackend(db *sql.DB) Backend {
+	return sqliteBackend{db: db}
+}
+
+func (b sqliteBackend) Load() (map[string]*types.CacheEntry, *types.CacheStats, error) {
+	rows, err := b.db.Query(`SELECT key, entry FROM cache_entries`)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read cache entries: %w", err)
+	}
+	defer rows.Close()
+
+	entries := make(map[string]*types.CacheEntry)
+	for rows.Next() {
+		var key, data string
+		if err := rows.Scan(&key, &data); err != nil {
+			return nil, nil, fmt.Errorf("failed to read cache entry: %w", err)
+		}
+		var entry types.CacheEntry
+		if err := json.Unmarshal([]byte(data), &entry); err != nil {
+			return nil, nil, fmt.Errorf("failed to unmarshal cache entry %s: %w", key, err)
+		}
+		entries[key] = &entry
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to read cache entries: %w", err)
+	}
+
+	var data string
+	err = b.db.QueryRow(`SELECT value FROM cache_meta WHERE name = ?`, statsRow).Scan(&data)
+	if err == sql.ErrNoRows {
+		return entries, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read cache statistics: %w", err)
+	}
+	var stats types.CacheStats
+	if err := json.Unmarshal([]byte(data), &stats); err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal cache statistics: %w", err)
+	}
+	return entries, &stats, nil
+}
+
+func (b sqliteBackend) Save(snapshot Snapshot) (err error) {
+	tx, err := b.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start cache transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	for key := range snapshot.Removed {
+		if _, err := tx.Exec(`DELETE FROM cache_entries WHERE key = ?`, key); err != nil {
+			return fmt.Errorf("failed to delete cache entry: %w", err)
+		}
+	}
+	for key := range snapshot.Changed {
+		entry, ok := snapshot.Entries[key]
+		if !ok {
+			continue
+		}
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return fmt.Errorf("failed to marshal cache entry: %w", err)
+		}
+		if _, err := tx.Exec(`INSERT INTO cache_entries (key, entry) VALUES (?, ?)
+			ON CONFLICT(key) DO UPDATE SET entry = excluded.entry`, key, string(data)); err != nil {
+			return fmt.Errorf("failed to write cache entry: %w", err)
+		}
+	}
+	if snapshot.Stats != nil {
+		data, err := json.Marshal(snapshot.Stats)
+		if err != nil {
+			return fmt.Errorf("failed to marshal cache statistics: %w", err)
+		}
+		if _, err := tx.Exec(`INSERT INTO cache_meta (name, value) VALUES (?, ?)
+			ON CONFLICT(name) DO UPDATE SET value = excluded.value`, statsRow, string(data)); err != nil {
+			return fmt.Errorf("failed to write cache statistics: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit cache transaction: %w", err)
+	}
+	return nil
+}
+
+func (b sqliteBackend) Clear() error {
+	for _, statement := range []string{`DELETE FROM cache_entries`, `DELETE FROM cache_meta`} {
+		if _, err := b.db.Exec(statement); err != nil {
+			return fmt.Errorf("failed to clear cache: %w", err)
+		}
+	}
+	return nil
+}
+
+func (b sqliteBackend) Size() int64 {
+	var size sql.NullInt64
+	if err := b.db.QueryRow(`SELECT SUM(LENGTH(key) + LENGTH(entry)) FROM cache_entries`).Scan(&size); err != nil {
+		return 0
+	}
+	return size.Int64
+}
+
+// Migrate copies every entry and the statistics from one backend to
+// another, replacing what the destination held, and returns how many
+// entries were copied.
+func Migrate(from, to Backend) (int, error) {
+	entries, stats, err := from.Load()
+	if err != nil {
+		return 0, err
+	}
+	if err := to.Clear(); err != nil {
+		return 0, err
+	}
+
+	changed := make(map[string]bool, len(entries))
+	for key := range entries {
+		changed[key] = true
+	}
+	if err := to.Save(Snapshot{Entries: entries, Stats: stats, Changed: changed}); err != nil {
+		return 0, err
+	}
+	return len(entries), nil
+}
@@ -0,0 +1,100 @@
+package cache
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dfanso/commit-msg/pkg/types"
+)
+
+// recordingBackend keeps the last snapshot saved to it in memory.
+type recordingBackend struct {
+	saved   []Snapshot
+	cleared int
+}
+
+func (b *recordingBackend) Load() (map[string]*types.CacheEntry, *types.CacheStats, error) {
+	return nil, nil, nil
+}
+
+func (b *recordingBackend) Save(snapshot Snapshot) error {
+	b.saved = append(b.saved, snapshot)
+	return nil
+}
+
+func (b *recordingBackend) Clear() error {
+	b.cleared++
+	return nil
+}
+
+func (b *recordingBackend) Size() int64 { return 0 }
+
+func (b *recordingBackend) last() Snapshot {
+	return b.saved[len(b.saved)-1]
+}
+
+func TestCacheManager_SavesOnlyWhatChanged(t *testing.T) {
+	backend := &recordingBackend{}
+	cm := NewCacheManagerWithBackend("memory", backend)
+	opts := &types.GenerationOptions{Attempt: 1}
+
+	if err := cm.Set(types.ProviderOpenAI, "first", opts, "feat: one", 0, nil); err != nil {
+		t.Fatalf("Failed to set cache entry: %v", err)
+	}
+	first := cm.hasher.GenerateCacheKey(types.ProviderOpenAI, "first", opts)
+	if got := backend.last(); len(got.Changed) != 1 || !got.Changed[first] || len(got.Removed) != 0 {
+		t.Fatalf("expected only the new entry to be written, got %+v", got)
+	}
+
+	if err := cm.Set(types.ProviderOpenAI, "second", opts, "feat: two", 0, nil); err != nil {
+		t.Fatalf("Failed to set cache entry: %v", err)
+	}
+	if got := backend.last(); len(got.Changed) != 1 || got.Changed[first] {
+		t.Fatalf("expected the earlier entry not to be written again, got %+v", got.Changed)
+	}
+
+	cm.entries[first].CreatedAt = time.Now().Add(-40 * 24 * time.Hour).Format(time.RFC3339)
+	if err := cm.Cleanup(); err != nil {
+		t.Fatalf("Cleanup returned error: %v", err)
+	}
+	if got := backend.last(); len(got.Changed) != 0 || !got.Removed[first] {
+		t.Fatalf("expected the expired entry to be deleted, got %+v", got)
+	}
+
+	if err := cm.Clear(); err != nil {
+		t.Fatalf("Clear returned error: %v", err)
+	}
+	if backend.cleared != 1 {
+		t.Errorf("expected Clear to reach the backend")
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	dir := t.TempDir()
+	source := newTestManager(t, 30, 24)
+	source.filePath = filepath.Join(dir, "cache.json")
+	opts := &types.GenerationOptions{Attempt: 1}
+	for _, diff := range []string{"one", "two"} {
+		if err := source.Set(types.ProviderOpenAI, diff, opts, "feat: "+diff, 0, nil); err != nil {
+			t.Fatalf("Failed to set cache entry: %v", err)
+		}
+	}
+	source.Get(types.ProviderOpenAI, "one", opts)
+	if err := source.Flush(); err != nil {
+		t.Fatalf("Flush returned error: %v", err)
+	}
+
+	backend := &recordingBackend{}
+	copied, err := Migrate(NewJSONBackend(source.filePath), backend)
+	if err != nil {
+		t.Fatalf("Migrate returned error: %v", err)
+	}
+	if copied != 2 || backend.cleared != 1 {
+		t.Fatalf("expected 2 entries copied into a cleared backend, got %d (cleared %d)", copied, backend.cleared)
+	}
+	got := backend.last()
+	if len(got.Changed) != 2 || len(got.Entries) != 2 || got.Stats == nil || got.Stats.TotalHits != 1 {
+		t.Fatalf("unexpected migrated snapshot: %+v", got)
+	}
+}
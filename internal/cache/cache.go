@@ -1,6 +1,7 @@
 package cache
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -9,10 +10,18 @@ import (
 	"sync"
 	"time"
 
+	"github.com/dfanso/commit-msg/internal/scrubber"
 	"github.com/dfanso/commit-msg/pkg/types"
 	StoreUtils "github.com/dfanso/commit-msg/utils"
 )
 
+// FileName is the cache file stored in the cache directory.
+const FileName = "cache.json"
+
+// DefaultMaxSizeBytes bounds the entries kept in cache.json; the least
+// recently used are evicted beyond it.
+const DefaultMaxSizeBytes = 5 << 20
+
 // CacheManager handles commit message caching operations.
 type CacheManager struct {
 	config   *types.CacheConfig
@@ -21,31 +30,49 @@ type CacheManager struct {
 	mutex    sync.RWMutex
 	filePath string
 	hasher   *DiffHasher
+	// backend stores the cache; nil means the JSON file at filePath.
+	backend Backend
+	// changed and removed name the entries written or deleted since the
+	// cache was last saved.
+	changed map[string]bool
+	removed map[string]bool
+	// dirty is set when hits and misses were counted since the cache was
+	// last written; Flush saves them.
+	dirty bool
 }
 
-// NewCacheManager creates a new cache manager instance.
+// NewCacheManager creates a new cache manager instance backed by cache.json.
 func NewCacheManager() (*CacheManager, error) {
+	// Get cache file path
+	cachePath, err := DefaultPath()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cache file path: %w", err)
+	}
+	if legacy, err := legacyPath(); err == nil {
+		moveLegacyCache(legacy, cachePath)
+	}
+	return NewCacheManagerWithBackend(cachePath, NewJSONBackend(cachePath)), nil
+}
+
+// NewCacheManagerWithBackend creates a cache manager that keeps its entries
+// in backend; path is where backend stores them, for display.
+func NewCacheManagerWithBackend(path string, backend Backend) *CacheManager {
 	config := &types.CacheConfig{
 		Enabled:         true,
 		MaxEntries:      1000,
 		MaxAgeDays:      30,
+		MaxSizeBytes:    DefaultMaxSizeBytes,
 		CleanupInterval: 24, // 24 hours
-		CacheFilePath:   "",
-	}
-
-	// Get cache file path
-	cachePath, err := getCacheFilePath()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get cache file path: %w", err)
+		CacheFilePath:   path,
 	}
-	config.CacheFilePath = cachePath
 
 	cm := &CacheManager{
 		config:   config,
 		entries:  make(map[string]*types.CacheEntry),
 		stats:    &types.CacheStats{},
-		filePath: cachePath,
+		filePath: path,
 		hasher:   NewDiffHasher(),
+		backend:  backend,
 	}
 
 	// Load existing cache
@@ -54,22 +81,60 @@ func NewCacheManager() (*CacheManager, error) {
 		fmt.Printf("Warning: Failed to load cache: %v\n", err)
 	}
 
-	return cm, nil
+	// Catch up on a cleanup missed while no process was running
+	if cm.cleanupDue(time.Now()) {
+		if err := cm.Cleanup(); err != nil {
+			fmt.Printf("Warning: Failed to clean up cache: %v\n", err)
+		}
+	}
+
+	return cm
+}
+
+// StartCleanup removes expired entries every CleanupInterval hours until
+// ctx is done. It is meant for long-running commands; short ones rely on
+// the cleanup NewCacheManager runs when one is overdue.
+func (cm *CacheManager) StartCleanup(ctx context.Context) {
+	if cm.config.CleanupInterval <= 0 {
+		return
+	}
+	interval := time.Duration(cm.config.CleanupInterval) * time.Hour
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := cm.Cleanup(); err != nil {
+					fmt.Printf("Warning: Failed to clean up cache: %v\n", err)
+				}
+			}
+		}
+	}()
 }
 
-// Get retrieves a cached commit message if it exists.
+// Get retrieves a cached commit message if it exists and is younger than
+// MaxAgeDays. An expired entry counts as a miss and is dropped.
 func (cm *CacheManager) Get(provider types.LLMProvider, diff string, opts *types.GenerationOptions) (*types.CacheEntry, bool) {
 	key := cm.hasher.GenerateCacheKey(provider, diff, opts)
 
 	// Phase 1: Read with RLock to check existence and copy the entry
 	cm.mutex.RLock()
 	entry, exists := cm.entries[key]
-	if !exists {
+	if !exists || cm.isExpired(entry, time.Now()) {
 		cm.mutex.RUnlock()
 		// Update miss statistics with write lock
 		cm.mutex.Lock()
+		if exists && cm.entries[key] == entry {
+			cm.remove(key)
+			cm.stats.TotalEntries = len(cm.entries)
+		}
 		cm.stats.TotalMisses++
 		cm.updateHitRate()
+		cm.dirty = true
 		cm.mutex.Unlock()
 		return nil, false
 	}
@@ -81,28 +146,31 @@ func (cm *CacheManager) Get(provider types.LLMProvider, diff string, opts *types
 	// Phase 2: Update shared stats and entry with write lock
 	cm.mutex.Lock()
 	// Update access statistics on the original entry
-	entry.LastAccessedAt = time.Now().Format(time.RFC3339)
+	entry.LastAccessedAt = time.Now().Format(time.RFC3339Nano)
 	entry.AccessCount++
+	cm.markChanged(key)
 	cm.stats.TotalHits++
 	cm.updateHitRate()
+	cm.dirty = true
 	cm.mutex.Unlock()
 
 	return &entryCopy, true
 }
 
-// Set stores a commit message in the cache.
+// Set stores a commit message in the cache. The message and style
+// instruction are scrubbed before they are written to disk.
 func (cm *CacheManager) Set(provider types.LLMProvider, diff string, opts *types.GenerationOptions, message string, cost float64, tokens *types.UsageInfo) error {
 	cm.mutex.Lock()
 	defer cm.mutex.Unlock()
 
 	key := cm.hasher.GenerateCacheKey(provider, diff, opts)
-	now := time.Now().Format(time.RFC3339)
+	now := time.Now().Format(time.RFC3339Nano)
 
 	entry := &types.CacheEntry{
-		Message:          message,
+		Message:          scrubber.ScrubDiff(message),
 		Provider:         provider,
 		DiffHash:         cm.hasher.GenerateHash(diff, opts),
-		StyleInstruction: getStyleInstruction(opts),
+		StyleInstruction: scrubber.ScrubDiff(getStyleInstruction(opts)),
 		Attempt:          getAttempt(opts),
 		CreatedAt:        now,
 		LastAccessedAt:   now,
@@ -112,10 +180,11 @@ func (cm *CacheManager) Set(provider types.LLMProvider, diff string, opts *types
 	}
 
 	cm.entries[key] = entry
+	cm.markChanged(key)
 	cm.stats.TotalEntries = len(cm.entries)
 
-	// Cleanup if we exceed max entries
-	if len(cm.entries) > cm.config.MaxEntries {
+	// Cleanup if we exceed max entries or size
+	if cm.overLimits() {
 		cm.cleanupOldEntries()
 	}
 
@@ -130,13 +199,10 @@ func (cm *CacheManager) Clear() error {
 
 	cm.entries = make(map[string]*types.CacheEntry)
 	cm.stats = &types.CacheStats{}
+	cm.changed, cm.removed, cm.dirty = nil, nil, false
 
-	// Remove cache file
-	if err := os.Remove(cm.filePath); err != nil && !os.IsNotExist(err) {
-		return fmt.Errorf("failed to remove cache file: %w", err)
-	}
-
-	return nil
+	// Remove the stored cache
+	return cm.store().Clear()
 }
 
 // GetStats returns cache statistics.
@@ -152,6 +218,30 @@ func (cm *CacheManager) GetStats() *types.CacheStats {
 	return &statsCopy
 }
 
+// ResetStats zeroes the hit and miss counters and saves the cache.
+func (cm *CacheManager) ResetStats() error {
+	cm.mutex.Lock()
+	defer cm.mutex.Unlock()
+
+	cm.stats.TotalHits = 0
+	cm.stats.TotalMisses = 0
+	cm.stats.HitRate = 0
+
+	return cm.saveCache()
+}
+
+// Flush saves the hits, misses, and access times recorded by Get since the
+// cache was last written. It is a no-op when nothing changed.
+func (cm *CacheManager) Flush() error {
+	cm.mutex.Lock()
+	defer cm.mutex.Unlock()
+
+	if !cm.dirty {
+		return nil
+	}
+	return cm.saveCache()
+}
+
 // Cleanup removes old entries based on age and access count.
 func (cm *CacheManager) Cleanup() error {
 	cm.mutex.Lock()
@@ -170,29 +260,16 @@ func (cm *CacheManager) Cleanup() error {
 	return nil
 }
 
-// loadCache loads the cache from disk.
+// loadCache loads the cache from its backend.
 func (cm *CacheManager) loadCache() error {
-	if _, err := os.Stat(cm.filePath); os.IsNotExist(err) {
-		return nil // No cache file exists yet
-	}
-
-	data, err := os.ReadFile(cm.filePath)
+	entries, stats, err := cm.store().Load()
 	if err != nil {
-		return fmt.Errorf("failed to read cache file: %w", err)
-	}
-
-	var cacheData struct {
-		Entries map[string]*types.CacheEntry `json:"entries"`
-		Stats   *types.CacheStats            `json:"stats"`
+		return err
 	}
 
-	if err := json.Unmarshal(data, &cacheData); err != nil {
-		return fmt.Errorf("failed to unmarshal cache data: %w", err)
-	}
-
-	cm.entries = cacheData.Entries
-	if cacheData.Stats != nil {
-		cm.stats = cacheData.Stats
+	cm.entries = entries
+	if stats != nil {
+		cm.stats = stats
 	}
 	if cm.entries == nil {
 		cm.entries = make(map[string]*types.CacheEntry)
@@ -205,110 +282,163 @@ func (cm *CacheManager) loadCache() error {
 	return nil
 }
 
-// saveCache saves the cache to disk.
+// saveCache writes the cache to its backend.
 func (cm *CacheManager) saveCache() error {
-	// Ensure directory exists
-	if err := os.MkdirAll(filepath.Dir(cm.filePath), 0700); err != nil {
-		return fmt.Errorf("failed to create cache directory: %w", err)
-	}
-
-	cacheData := struct {
-		Entries map[string]*types.CacheEntry `json:"entries"`
-		Stats   *types.CacheStats            `json:"stats"`
-		Config  *types.CacheConfig           `json:"config"`
-	}{
+	err := cm.store().Save(Snapshot{
 		Entries: cm.entries,
 		Stats:   cm.stats,
 		Config:  cm.config,
+		Changed: cm.changed,
+		Removed: cm.removed,
+	})
+	if err != nil {
+		return err
 	}
+	cm.changed, cm.removed, cm.dirty = nil, nil, false
 
-	data, err := json.MarshalIndent(cacheData, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal cache data: %w", err)
+	return nil
+}
+
+// store returns the backend, defaulting to the JSON file at filePath.
+func (cm *CacheManager) store() Backend {
+	if cm.backend != nil {
+		return cm.backend
 	}
+	return NewJSONBackend(cm.filePath)
+}
 
-	if err := os.WriteFile(cm.filePath, data, 0600); err != nil {
-		return fmt.Errorf("failed to write cache file: %w", err)
+// markChanged records that the entry at key must be written on save.
+func (cm *CacheManager) markChanged(key string) {
+	if cm.changed == nil {
+		cm.changed = make(map[string]bool)
 	}
+	cm.changed[key] = true
+	delete(cm.removed, key)
+}
 
-	return nil
+// remove deletes the entry at key and records that it must be deleted from
+// the backend on save.
+func (cm *CacheManager) remove(key string) {
+	delete(cm.entries, key)
+	delete(cm.changed, key)
+	if cm.removed == nil {
+		cm.removed = make(map[string]bool)
+	}
+	cm.removed[key] = true
 }
 
-// cleanupOldEntries removes old entries based on age and access count.
+// cleanupOldEntries removes expired entries, then evicts the least recently
+// accessed ones until the cache fits MaxEntries and MaxSizeBytes.
 func (cm *CacheManager) cleanupOldEntries() error {
 	now := time.Now()
-	maxAge := time.Duration(cm.config.MaxAgeDays) * 24 * time.Hour
-
-	var keysToDelete []string
 
 	for key, entry := range cm.entries {
-		createdAt, err := time.Parse(time.RFC3339, entry.CreatedAt)
-		if err != nil {
-			// If we can't parse the date, consider it old
-			keysToDelete = append(keysToDelete, key)
-			continue
+		if cm.isExpired(entry, now) {
+			cm.remove(key)
 		}
-
-		// Remove entries older than max age
-		if now.Sub(createdAt) > maxAge {
-			keysToDelete = append(keysToDelete, key)
-			continue
-		}
-	}
-
-	// If we still have too many entries, remove least recently accessed
-	if len(cm.entries)-len(keysToDelete) > cm.config.MaxEntries {
-		keysToDelete = cm.removeLeastAccessed(keysToDelete)
 	}
 
-	// Delete the selected entries
-	for _, key := range keysToDelete {
-		delete(cm.entries, key)
-	}
+	cm.evictLeastAccessed()
 
 	cm.stats.TotalEntries = len(cm.entries)
+	cm.stats.LastCleanup = now.Format(time.RFC3339)
 
 	return nil
 }
 
-// removeLeastAccessed removes the least recently accessed entries.
-func (cm *CacheManager) removeLeastAccessed(existingKeysToDelete []string) []string {
-	type entryWithKey struct {
-		key   string
-		entry *types.CacheEntry
+// isExpired reports whether entry is older than MaxAgeDays at now. Entries
+// whose creation time cannot be parsed are treated as expired.
+func (cm *CacheManager) isExpired(entry *types.CacheEntry, now time.Time) bool {
+	createdAt, err := time.Parse(time.RFC3339, entry.CreatedAt)
+	if err != nil {
+		return true
+	}
+	maxAge := time.Duration(cm.config.MaxAgeDays) * 24 * time.Hour
+	return now.Sub(createdAt) > maxAge
+}
+
+// cleanupDue reports whether CleanupInterval hours have passed since the
+// last cleanup, or no cleanup was ever recorded for a non-empty cache.
+func (cm *CacheManager) cleanupDue(now time.Time) bool {
+	if cm.config.CleanupInterval <= 0 || len(cm.entries) == 0 {
+		return false
 	}
+	last, err := time.Parse(time.RFC3339, cm.stats.LastCleanup)
+	if err != nil {
+		return true
+	}
+	return now.Sub(last) >= time.Duration(cm.config.CleanupInterval)*time.Hour
+}
 
-	var entries []entryWithKey
+// overLimits reports whether the cache holds more than MaxEntries entries or
+// more than MaxSizeBytes of them. A non-positive limit is not enforced.
+func (cm *CacheManager) overLimits() bool {
+	if cm.config.MaxEntries > 0 && len(cm.entries) > cm.config.MaxEntries {
+		return true
+	}
+	if cm.config.MaxSizeBytes <= 0 {
+		return false
+	}
+	var size int64
 	for key, entry := range cm.entries {
-		// Skip entries already marked for deletion
-		skip := false
-		for _, existingKey := range existingKeysToDelete {
-			if key == existingKey {
-				skip = true
-				break
-			}
-		}
-		if skip {
-			continue
-		}
+		size += entrySize(key, entry)
+	}
+	return size > cm.config.MaxSizeBytes
+}
+
+// evictLeastAccessed deletes entries, least recently accessed first, until
+// the cache fits MaxEntries and MaxSizeBytes.
+func (cm *CacheManager) evictLeastAccessed() {
+	type entryWithKey struct {
+		key          string
+		entry        *types.CacheEntry
+		lastAccessed time.Time
+		size         int64
+	}
 
-		entries = append(entries, entryWithKey{key: key, entry: entry})
+	entries := make([]entryWithKey, 0, len(cm.entries))
+	var totalSize int64
+	for key, entry := range cm.entries {
+		// Unparseable times sort first, as the oldest
+		lastAccessed, _ := time.Parse(time.RFC3339, entry.LastAccessedAt)
+		size := entrySize(key, entry)
+		totalSize += size
+		entries = append(entries, entryWithKey{key: key, entry: entry, lastAccessed: lastAccessed, size: size})
 	}
 
-	// Sort by last accessed time (oldest first)
+	// Oldest access first; on a tie (older entries only record whole
+	// seconds) the less used entry goes first, and the key keeps the order
+	// stable.
 	sort.Slice(entries, func(i, j int) bool {
-		timeI, _ := time.Parse(time.RFC3339, entries[i].entry.LastAccessedAt)
-		timeJ, _ := time.Parse(time.RFC3339, entries[j].entry.LastAccessedAt)
-		return timeI.Before(timeJ)
+		if !entries[i].lastAccessed.Equal(entries[j].lastAccessed) {
+			return entries[i].lastAccessed.Before(entries[j].lastAccessed)
+		}
+		if entries[i].entry.AccessCount != entries[j].entry.AccessCount {
+			return entries[i].entry.AccessCount < entries[j].entry.AccessCount
+		}
+		return entries[i].key < entries[j].key
 	})
 
-	// Add the oldest entries to deletion list
-	entriesToRemove := len(entries) - (cm.config.MaxEntries - len(existingKeysToDelete))
-	for i := 0; i < entriesToRemove && i < len(entries); i++ {
-		existingKeysToDelete = append(existingKeysToDelete, entries[i].key)
+	remaining := len(entries)
+	for _, e := range entries {
+		overCount := cm.config.MaxEntries > 0 && remaining > cm.config.MaxEntries
+		overSize := cm.config.MaxSizeBytes > 0 && totalSize > cm.config.MaxSizeBytes
+		if !overCount && !overSize {
+			break
+		}
+		cm.remove(e.key)
+		remaining--
+		totalSize -= e.size
 	}
+}
 
-	return existingKeysToDelete
+// entrySize approximates the bytes key and entry take in the cache file.
+func entrySize(key string, entry *types.CacheEntry) int64 {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return int64(len(key))
+	}
+	return int64(len(key) + len(data))
 }
 
 // updateHitRate calculates and updates the hit rate.
@@ -352,22 +482,47 @@ func (cm *CacheManager) calculateStats() {
 
 	cm.stats.TotalCostSaved = totalCost
 
-	// Calculate cache file size
-	if stat, err := os.Stat(cm.filePath); err == nil {
-		cm.stats.CacheSizeBytes = stat.Size()
+	// Calculate the stored cache size
+	cm.stats.CacheSizeBytes = cm.store().Size()
+}
+
+// DefaultPath returns the path to the cache file, in the cache directory
+// rather than next to config.json.
+func DefaultPath() (string, error) {
+	cacheDir, err := StoreUtils.GetCacheDir()
+	if err != nil {
+		return "", err
 	}
+	return filepath.Join(cacheDir, FileName), nil
 }
 
-// getCacheFilePath returns the path to the cache file.
-func getCacheFilePath() (string, error) {
+// legacyPath returns where the cache file was kept before it moved to the
+// cache directory: next to config.json.
+func legacyPath() (string, error) {
 	configPath, err := StoreUtils.GetConfigPath()
 	if err != nil {
 		return "", err
 	}
+	return filepath.Join(filepath.Dir(configPath), FileName), nil
+}
 
-	// Use the same directory as config but with cache.json filename
-	configDir := filepath.Dir(configPath)
-	return filepath.Join(configDir, "cache.json"), nil
+// moveLegacyCache moves the cache file at from to to, unless to already
+// exists. The cache can always be rebuilt, so a move that fails leaves the
+// old file behind and starts an empty cache.
+func moveLegacyCache(from, to string) {
+	if from == to {
+		return
+	}
+	if _, err := os.Stat(to); err == nil {
+		return
+	}
+	if _, err := os.Stat(from); err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(to), 0o700); err != nil {
+		return
+	}
+	_ = os.Rename(from, to)
 }
 
 // Helper functions
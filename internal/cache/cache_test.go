@@ -1,8 +1,12 @@
 package cache
 
 import (
+	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/dfanso/commit-msg/pkg/types"
 )
@@ -293,3 +297,342 @@ index 1234567..abcdefg 100644
 		t.Errorf("Normalized diff should contain added line")
 	}
 }
+
+func TestDiffHasher_SamplingOptions(t *testing.T) {
+	hasher := NewDiffHasher()
+	diff := "+added line"
+
+	warm := 0.9
+	base := hasher.GenerateHash(diff, &types.GenerationOptions{Attempt: 1})
+	withTemperature := hasher.GenerateHash(diff, &types.GenerationOptions{Attempt: 1, Temperature: &warm})
+	if base == withTemperature {
+		t.Errorf("Hash should change when a temperature is requested")
+	}
+
+	seed, otherSeed := int64(1), int64(2)
+	withSeed := hasher.GenerateHash(diff, &types.GenerationOptions{Attempt: 1, Seed: &seed})
+	if withSeed == base || withSeed == hasher.GenerateHash(diff, &types.GenerationOptions{Attempt: 1, Seed: &otherSeed}) {
+		t.Errorf("Hash should depend on the requested seed")
+	}
+}
+
+func TestCacheManager_SetScrubsEntries(t *testing.T) {
+	tempDir := t.TempDir()
+	cm := &CacheManager{
+		config: &types.CacheConfig{
+			Enabled:       true,
+			MaxEntries:    1000,
+			MaxAgeDays:    30,
+			CacheFilePath: filepath.Join(tempDir, "test-cache.json"),
+		},
+		entries:  make(map[string]*types.CacheEntry),
+		stats:    &types.CacheStats{},
+		filePath: filepath.Join(tempDir, "test-cache.json"),
+		hasher:   NewDiffHasher(),
+	}
+
+	secret := "abcdefghijklmnopqrstuvwxyz123"
+	opts := &types.GenerationOptions{StyleInstruction: "mention api_key=" + secret, Attempt: 1}
+	if err := cm.Set(types.ProviderOpenAI, "diff", opts, "fix: rotate api_key="+secret, 0, nil); err != nil {
+		t.Fatalf("Failed to set cache entry: %v", err)
+	}
+
+	data, err := os.ReadFile(cm.filePath)
+	if err != nil {
+		t.Fatalf("Failed to read cache file: %v", err)
+	}
+	if strings.Contains(string(data), secret) {
+		t.Fatalf("expected the secret to be redacted from the cache file, got %s", data)
+	}
+}
+
+func newTestManager(t *testing.T, maxAgeDays, cleanupHours int) *CacheManager {
+	t.Helper()
+	cacheFile := filepath.Join(t.TempDir(), "test-cache.json")
+	return &CacheManager{
+		config: &types.CacheConfig{
+			Enabled:         true,
+			MaxEntries:      1000,
+			MaxAgeDays:      maxAgeDays,
+			CleanupInterval: cleanupHours,
+			CacheFilePath:   cacheFile,
+		},
+		entries:  make(map[string]*types.CacheEntry),
+		stats:    &types.CacheStats{},
+		filePath: cacheFile,
+		hasher:   NewDiffHasher(),
+	}
+}
+
+func TestCacheManager_GetExpiresOldEntries(t *testing.T) {
+	cm := newTestManager(t, 30, 24)
+	opts := &types.GenerationOptions{Attempt: 1}
+
+	if err := cm.Set(types.ProviderOpenAI, "diff", opts, "feat: add", 0, nil); err != nil {
+		t.Fatalf("Failed to set cache entry: %v", err)
+	}
+	if _, found := cm.Get(types.ProviderOpenAI, "diff", opts); !found {
+		t.Fatal("expected a fresh entry to be returned")
+	}
+
+	key := cm.hasher.GenerateCacheKey(types.ProviderOpenAI, "diff", opts)
+	cm.entries[key].CreatedAt = time.Now().Add(-31 * 24 * time.Hour).Format(time.RFC3339)
+
+	if _, found := cm.Get(types.ProviderOpenAI, "diff", opts); found {
+		t.Fatal("expected an entry older than MaxAgeDays to be a miss")
+	}
+	if _, exists := cm.entries[key]; exists {
+		t.Error("expected the expired entry to be dropped")
+	}
+	if cm.stats.TotalMisses != 1 || cm.stats.TotalEntries != 0 {
+		t.Errorf("unexpected stats after expiry: %+v", cm.stats)
+	}
+}
+
+func TestCacheManager_CleanupDue(t *testing.T) {
+	cm := newTestManager(t, 30, 24)
+	now := time.Now()
+
+	if cm.cleanupDue(now) {
+		t.Error("an empty cache never needs cleanup")
+	}
+
+	cm.entries["key"] = &types.CacheEntry{CreatedAt: now.Format(time.RFC3339)}
+	if !cm.cleanupDue(now) {
+		t.Error("expected a cache that was never cleaned up to be due")
+	}
+
+	if err := cm.Cleanup(); err != nil {
+		t.Fatalf("Cleanup returned error: %v", err)
+	}
+	if cm.cleanupDue(now) {
+		t.Error("expected no cleanup to be due right after one ran")
+	}
+	if !cm.cleanupDue(now.Add(25 * time.Hour)) {
+		t.Error("expected a cleanup to be due once the interval has passed")
+	}
+
+	cm.config.CleanupInterval = 0
+	if cm.cleanupDue(now.Add(25 * time.Hour)) {
+		t.Error("a zero interval disables scheduled cleanup")
+	}
+}
+
+func TestCacheManager_CleanupRemovesExpiredAndPersists(t *testing.T) {
+	cm := newTestManager(t, 30, 24)
+	now := time.Now()
+	cm.entries["fresh"] = &types.CacheEntry{CreatedAt: now.Format(time.RFC3339)}
+	cm.entries["stale"] = &types.CacheEntry{CreatedAt: now.Add(-40 * 24 * time.Hour).Format(time.RFC3339)}
+	cm.entries["broken"] = &types.CacheEntry{CreatedAt: "yesterday"}
+
+	if err := cm.Cleanup(); err != nil {
+		t.Fatalf("Cleanup returned error: %v", err)
+	}
+	if len(cm.entries) != 1 || cm.entries["fresh"] == nil {
+		t.Fatalf("expected only the fresh entry to remain, got %v", cm.entries)
+	}
+
+	reloaded := newTestManager(t, 30, 24)
+	reloaded.filePath = cm.filePath
+	if err := reloaded.loadCache(); err != nil {
+		t.Fatalf("Failed to load cache: %v", err)
+	}
+	if reloaded.stats.LastCleanup == "" || reloaded.cleanupDue(now) {
+		t.Errorf("expected the cleanup time to be persisted, got %q", reloaded.stats.LastCleanup)
+	}
+}
+
+func TestCacheManager_EvictsLeastRecentlyUsedOnOverflow(t *testing.T) {
+	cm := newTestManager(t, 30, 24)
+	cm.config.MaxEntries = 3
+	now := time.Now()
+
+	// Seed entries accessed at distinct times so the LRU order is known.
+	for i, key := range []string{"a", "b", "c"} {
+		at := now.Add(time.Duration(i-10) * time.Minute).Format(time.RFC3339)
+		cm.entries[key] = &types.CacheEntry{CreatedAt: at, LastAccessedAt: at, AccessCount: 1}
+	}
+
+	if err := cm.Set(types.ProviderOpenAI, "diff", &types.GenerationOptions{Attempt: 1}, "feat: add", 0, nil); err != nil {
+		t.Fatalf("Failed to set cache entry: %v", err)
+	}
+	if len(cm.entries) != 3 || cm.stats.TotalEntries != 3 {
+		t.Fatalf("expected 3 entries after overflow, got %d (stats %d)", len(cm.entries), cm.stats.TotalEntries)
+	}
+	if _, exists := cm.entries["a"]; exists {
+		t.Error("expected the least recently accessed entry to be evicted")
+	}
+	for _, key := range []string{"b", "c"} {
+		if _, exists := cm.entries[key]; !exists {
+			t.Errorf("expected %q to be kept", key)
+		}
+	}
+}
+
+func TestCacheManager_EvictionCountsExpiredEntries(t *testing.T) {
+	cm := newTestManager(t, 30, 24)
+	cm.config.MaxEntries = 3
+	now := time.Now()
+	stale := now.Add(-40 * 24 * time.Hour).Format(time.RFC3339)
+	cm.entries["stale"] = &types.CacheEntry{CreatedAt: stale, LastAccessedAt: stale}
+	for i, key := range []string{"a", "b", "c"} {
+		at := now.Add(time.Duration(i-10) * time.Minute).Format(time.RFC3339)
+		cm.entries[key] = &types.CacheEntry{CreatedAt: at, LastAccessedAt: at}
+	}
+
+	if err := cm.Cleanup(); err != nil {
+		t.Fatalf("Cleanup returned error: %v", err)
+	}
+	// Dropping the expired entry is enough; no live entry may be evicted.
+	if len(cm.entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(cm.entries))
+	}
+	if _, exists := cm.entries["stale"]; exists {
+		t.Error("expected the expired entry to be removed")
+	}
+}
+
+func TestCacheManager_EvictsBeyondMaxSize(t *testing.T) {
+	cm := newTestManager(t, 30, 24)
+	message := strings.Repeat("x", 1000)
+	opts := &types.GenerationOptions{Attempt: 1}
+
+	if err := cm.Set(types.ProviderOpenAI, "diff 0", opts, message, 0, nil); err != nil {
+		t.Fatalf("Failed to set cache entry: %v", err)
+	}
+	var one int64
+	for key, entry := range cm.entries {
+		one = entrySize(key, entry)
+	}
+	cm.config.MaxSizeBytes = 3*one + one/2
+
+	for i := 1; i < 10; i++ {
+		if err := cm.Set(types.ProviderOpenAI, "diff "+strings.Repeat("+", i), opts, message, 0, nil); err != nil {
+			t.Fatalf("Failed to set cache entry: %v", err)
+		}
+	}
+	if len(cm.entries) != 3 {
+		t.Fatalf("expected the size bound to keep 3 entries, got %d", len(cm.entries))
+	}
+	if _, found := cm.Get(types.ProviderOpenAI, "diff "+strings.Repeat("+", 9), opts); !found {
+		t.Error("expected the newest entry to survive eviction")
+	}
+}
+
+func TestCacheManager_FlushPersistsHitsAndMisses(t *testing.T) {
+	cm := newTestManager(t, 30, 24)
+	opts := &types.GenerationOptions{Attempt: 1}
+	if err := cm.Set(types.ProviderOpenAI, "diff", opts, "feat: add", 0, nil); err != nil {
+		t.Fatalf("Failed to set cache entry: %v", err)
+	}
+
+	cm.Get(types.ProviderOpenAI, "diff", opts)
+	cm.Get(types.ProviderOpenAI, "other", opts)
+	if err := cm.Flush(); err != nil {
+		t.Fatalf("Flush returned error: %v", err)
+	}
+	if cm.dirty {
+		t.Error("expected Flush to clear the pending write")
+	}
+
+	reloaded := newTestManager(t, 30, 24)
+	reloaded.filePath = cm.filePath
+	if err := reloaded.loadCache(); err != nil {
+		t.Fatalf("Failed to load cache: %v", err)
+	}
+	if reloaded.stats.TotalHits != 1 || reloaded.stats.TotalMisses != 1 || reloaded.stats.HitRate != 0.5 {
+		t.Errorf("expected the hit and miss to survive a restart, got %+v", reloaded.stats)
+	}
+}
+
+func TestCacheManager_FlushSkipsCleanCache(t *testing.T) {
+	cm := newTestManager(t, 30, 24)
+	if err := cm.Flush(); err != nil {
+		t.Fatalf("Flush returned error: %v", err)
+	}
+	if _, err := os.Stat(cm.filePath); !os.IsNotExist(err) {
+		t.Errorf("expected no cache file to be written, got %v", err)
+	}
+}
+
+func TestCacheManager_ResetStats(t *testing.T) {
+	cm := newTestManager(t, 30, 24)
+	opts := &types.GenerationOptions{Attempt: 1}
+	if err := cm.Set(types.ProviderOpenAI, "diff", opts, "feat: add", 0.5, nil); err != nil {
+		t.Fatalf("Failed to set cache entry: %v", err)
+	}
+	cm.Get(types.ProviderOpenAI, "diff", opts)
+	cm.Get(types.ProviderOpenAI, "other", opts)
+
+	if err := cm.ResetStats(); err != nil {
+		t.Fatalf("ResetStats returned error: %v", err)
+	}
+	stats := cm.GetStats()
+	if stats.TotalHits != 0 || stats.TotalMisses != 0 || stats.HitRate != 0 {
+		t.Errorf("expected the counters to be zeroed, got %+v", stats)
+	}
+	if stats.TotalEntries != 1 || stats.TotalCostSaved != 0.5 {
+		t.Errorf("expected the entries to be kept, got %+v", stats)
+	}
+}
+
+func TestCacheManager_ConcurrentGetCountsEveryLookup(t *testing.T) {
+	cm := newTestManager(t, 30, 24)
+	opts := &types.GenerationOptions{Attempt: 1}
+	if err := cm.Set(types.ProviderOpenAI, "diff", opts, "feat: add", 0, nil); err != nil {
+		t.Fatalf("Failed to set cache entry: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cm.Get(types.ProviderOpenAI, "diff", opts)
+			cm.Get(types.ProviderOpenAI, "other", opts)
+		}()
+	}
+	wg.Wait()
+
+	stats := cm.GetStats()
+	if stats.TotalHits != 50 || stats.TotalMisses != 50 {
+		t.Errorf("expected 50 hits and 50 misses, got %+v", stats)
+	}
+}
+
+func TestMoveLegacyCache(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	legacy := filepath.Join(dir, "config", FileName)
+	moved := filepath.Join(dir, "cache", FileName)
+
+	// Nothing to move.
+	moveLegacyCache(legacy, moved)
+	if _, err := os.Stat(moved); !os.IsNotExist(err) {
+		t.Fatalf("expected no cache file, got %v", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(legacy), 0o700); err != nil {
+		t.Fatalf("failed to create config dir: %v", err)
+	}
+	if err := os.WriteFile(legacy, []byte(`{"entries":{}}`), 0o600); err != nil {
+		t.Fatalf("failed to write legacy cache: %v", err)
+	}
+	moveLegacyCache(legacy, moved)
+	if data, err := os.ReadFile(moved); err != nil || string(data) != `{"entries":{}}` {
+		t.Fatalf("expected the legacy cache to be moved, got %q, %v", data, err)
+	}
+	if _, err := os.Stat(legacy); !os.IsNotExist(err) {
+		t.Fatalf("expected the legacy cache to be gone, got %v", err)
+	}
+
+	// An existing cache is never overwritten.
+	if err := os.WriteFile(legacy, []byte(`{"old":true}`), 0o600); err != nil {
+		t.Fatalf("failed to write legacy cache: %v", err)
+	}
+	moveLegacyCache(legacy, moved)
+	if data, _ := os.ReadFile(moved); string(data) != `{"entries":{}}` {
+		t.Fatalf("expected the current cache to be kept, got %q", data)
+	}
+}
@@ -4,7 +4,8 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
-	"sort"
+	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/dfanso/commit-msg/pkg/types"
@@ -32,69 +33,143 @@ func (h *DiffHasher) GenerateHash(diff string, opts *types.GenerationOptions) st
 	return hex.EncodeToString(hash[:])
 }
 
-// normalizeDiff removes variable content from git diff to create consistent hashes
-// for similar changes across different repositories or time periods.
+// normalizeDiff removes the parts of a diff that change without the change
+// itself changing — blob hashes on index lines, similarity scores, hunk line
+// numbers, and header timestamps — so the same edit hashes the same when it
+// is made again on another base. File paths, hunk content, indentation, and
+// line order are kept, since each of them can change what the commit
+// message should say.
 func (h *DiffHasher) normalizeDiff(diff string) string {
-	lines := strings.Split(diff, "\n")
-	var normalizedLines []string
+	var normalized []string
+	var hunk hunkCounter
+	header := false
 
-	for _, line := range lines {
-		// Skip lines that contain variable information
-		if h.shouldSkipLine(line) {
+	for _, line := range strings.Split(diff, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+
+		if hunk.active() {
+			hunk.consume(line)
+			normalized = append(normalized, line)
 			continue
 		}
 
-		// Normalize the line content
-		normalizedLine := h.normalizeLine(line)
-		if normalizedLine != "" {
-			normalizedLines = append(normalizedLines, normalizedLine)
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			header = true
+			normalized = append(normalized, "file "+diffGitPath(line))
+		case strings.HasPrefix(line, "@@"):
+			header = false
+			hunk = parseHunkHeader(line)
+			normalized = append(normalized, "@@"+hunkHeading(line))
+		case header || strings.HasPrefix(line, "--- ") || strings.HasPrefix(line, "+++ "):
+			// File headers: keep what they say about the file, not the
+			// hashes and scores that vary with its history.
+			header = true
+			if kept := normalizeHeaderLine(line); kept != "" {
+				normalized = append(normalized, kept)
+			}
+		case strings.TrimSpace(line) != "":
+			normalized = append(normalized, line)
 		}
 	}
 
-	// Sort lines to ensure consistent ordering regardless of git output order
-	sort.Strings(normalizedLines)
+	return strings.Join(normalized, "\n")
+}
 
-	return strings.Join(normalizedLines, "\n")
+// normalizeHeaderLine returns the part of a file header line worth hashing,
+// or "" to drop it.
+func normalizeHeaderLine(line string) string {
+	switch {
+	case strings.HasPrefix(line, "index "),
+		strings.HasPrefix(line, "similarity index "),
+		strings.HasPrefix(line, "dissimilarity index "),
+		strings.HasPrefix(line, "--- "):
+		return ""
+	case strings.HasPrefix(line, "+++ "):
+		// The path is already recorded from the diff --git line; plain
+		// unified diffs have only this one, often followed by a timestamp.
+		path, _, _ := strings.Cut(strings.TrimPrefix(line, "+++ "), "\t")
+		return "file " + strings.TrimPrefix(strings.TrimSpace(path), "b/")
+	default:
+		return strings.TrimSpace(line)
+	}
 }
 
-// shouldSkipLine determines if a line should be excluded from the hash calculation.
-func (h *DiffHasher) shouldSkipLine(line string) bool {
-	// Skip empty lines
-	if strings.TrimSpace(line) == "" {
-		return true
+// diffGitPath returns the new path from a "diff --git a/old b/new" line.
+func diffGitPath(line string) string {
+	rest := strings.TrimPrefix(line, "diff --git ")
+	if i := strings.LastIndex(rest, " b/"); i >= 0 {
+		return rest[i+3:]
 	}
+	return rest
+}
 
-	// Skip git diff headers that contain timestamps or file paths
-	if strings.HasPrefix(line, "diff --git") ||
-		strings.HasPrefix(line, "index ") ||
-		strings.HasPrefix(line, "+++") ||
-		strings.HasPrefix(line, "---") ||
-		strings.HasPrefix(line, "@@") {
-		return true
+// hunkHeading returns the function or section name git appends to a hunk
+// header, with a leading space, or "" when there is none.
+func hunkHeading(line string) string {
+	if i := strings.Index(line[2:], "@@"); i >= 0 {
+		if heading := strings.TrimSpace(line[i+4:]); heading != "" {
+			return " " + heading
+		}
 	}
+	return ""
+}
 
-	return false
+// hunkCounter tracks how many old and new lines of the current hunk are
+// still to come, so content lines that look like headers ("--- x" for a
+// removed "-- x") are not mistaken for them.
+type hunkCounter struct {
+	old, new int
 }
 
-// normalizeLine normalizes a single line of diff content.
-func (h *DiffHasher) normalizeLine(line string) string {
-	// Remove leading/trailing whitespace
-	line = strings.TrimSpace(line)
+// parseHunkHeader reads the line counts from "@@ -a,b +c,d @@". A missing
+// count means one line.
+func parseHunkHeader(line string) hunkCounter {
+	fields := strings.Fields(line)
+	var hunk hunkCounter
+	for _, field := range fields[1:] {
+		switch {
+		case strings.HasPrefix(field, "-"):
+			hunk.old = hunkLength(field[1:])
+		case strings.HasPrefix(field, "+"):
+			hunk.new = hunkLength(field[1:])
+		case field == "@@":
+			return hunk
+		}
+	}
+	return hunk
+}
 
-	// If it's a diff line (+ or -), normalize the content
-	if strings.HasPrefix(line, "+") || strings.HasPrefix(line, "-") {
-		// Keep the + or - prefix but normalize the rest
-		prefix := line[:1]
-		content := line[1:]
+func hunkLength(rangeSpec string) int {
+	_, count, found := strings.Cut(rangeSpec, ",")
+	if !found {
+		return 1
+	}
+	n, err := strconv.Atoi(count)
+	if err != nil {
+		return 0
+	}
+	return n
+}
 
-		// Remove line numbers and other variable content
-		// This is a simple approach - in practice, you might want more sophisticated normalization
-		content = strings.TrimSpace(content)
+func (c *hunkCounter) active() bool {
+	return c.old > 0 || c.new > 0
+}
 
-		return prefix + content
+// consume counts line against the hunk.
+func (c *hunkCounter) consume(line string) {
+	switch {
+	case strings.HasPrefix(line, "-"):
+		c.old--
+	case strings.HasPrefix(line, "+"):
+		c.new--
+	case strings.HasPrefix(line, "\\"):
+		// "\ No newline at end of file" belongs to the previous line
+	default:
+		// Context lines, including ones whose leading space was stripped
+		c.old--
+		c.new--
 	}
-
-	return line
 }
 
 // buildHashInput creates the input string for hashing by combining
@@ -106,8 +181,25 @@ func (h *DiffHasher) buildHashInput(normalizedDiff string, opts *types.Generatio
 	parts = append(parts, normalizedDiff)
 
 	// Add style instruction if present
-	if opts != nil && opts.StyleInstruction != "" {
-		parts = append(parts, "style:"+strings.TrimSpace(opts.StyleInstruction))
+	if style := strings.TrimSpace(opts.Style()); style != "" {
+		parts = append(parts, "style:"+style)
+	}
+
+	// Add scope hint and prompt template, which also change the prompt
+	if opts != nil && opts.Scope != "" {
+		parts = append(parts, "scope:"+strings.TrimSpace(opts.Scope))
+	}
+	if opts != nil && opts.Template != "" {
+		parts = append(parts, "template:"+opts.Template)
+	}
+	if opts != nil && opts.PreviousMessage != "" {
+		parts = append(parts, "previous:"+opts.PreviousMessage)
+	}
+	if opts != nil && opts.Temperature != nil {
+		parts = append(parts, fmt.Sprintf("temperature:%g", *opts.Temperature))
+	}
+	if opts != nil && opts.Seed != nil {
+		parts = append(parts, fmt.Sprintf("seed:%d", *opts.Seed))
 	}
 
 	// Add attempt number (but only if it's the first attempt, as we want to cache
@@ -119,8 +211,71 @@ func (h *DiffHasher) buildHashInput(normalizedDiff string, opts *types.Generatio
 	return strings.Join(parts, "|")
 }
 
-// GenerateCacheKey creates a complete cache key including provider information.
+// GenerateCacheKey creates a complete cache key including provider
+// information and, when opts carries one, the repository's cache scope.
 func (h *DiffHasher) GenerateCacheKey(provider types.LLMProvider, diff string, opts *types.GenerationOptions) string {
 	diffHash := h.GenerateHash(diff, opts)
+	if opts != nil && opts.CacheScope != "" {
+		return fmt.Sprintf("%s:%s:%s", provider.String(), opts.CacheScope, diffHash)
+	}
 	return fmt.Sprintf("%s:%s", provider.String(), diffHash)
 }
+
+// RepoScope returns the cache scope of a repository: a short hash of its
+// origin URL, so clones of the same project share cached messages, or of
+// its root directory when it has no remote. It returns "" when both are
+// empty.
+func RepoScope(remoteURL, root string) string {
+	var identity string
+	if remote := normalizeRemote(remoteURL); remote != "" {
+		identity = "remote:" + remote
+	} else if root = strings.TrimSpace(root); root != "" {
+		identity = "path:" + filepath.Clean(root)
+	} else {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(identity))
+	return hex.EncodeToString(sum[:8])
+}
+
+// normalizeRemote makes the HTTPS and SSH spellings of a remote, with or
+// without credentials and a .git suffix, compare equal.
+func normalizeRemote(remoteURL string) string {
+	remote := strings.ToLower(strings.TrimSpace(remoteURL))
+	if remote == "" {
+		return ""
+	}
+	if i := strings.Index(remote, "://"); i >= 0 {
+		remote = remote[i+3:]
+	}
+	if at := strings.LastIndex(remote, "@"); at >= 0 {
+		remote = remote[at+1:]
+	}
+	// scp-style "host:owner/repo" becomes "host/owner/repo"
+	if colon := strings.Index(remote, ":"); colon >= 0 && !strings.Contains(remote[:colon], "/") {
+		rest := remote[colon+1:]
+		if port := strings.Index(rest, "/"); port > 0 && isDigits(rest[:port]) {
+			rest = rest[port+1:]
+		}
+		remote = remote[:colon] + "/" + rest
+	}
+	remote = strings.TrimSuffix(strings.TrimSuffix(remote, "/"), ".git")
+	return remote
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// Settings controls how cached messages are shared. The JSON names are the
+// keys of the "cache" section in config.json.
+type Settings struct {
+	// ShareAcrossRepos reuses cached messages for the same changes in any
+	// repository instead of only the one they were generated in.
+	ShareAcrossRepos bool `json:"share_across_repos"`
+}
@@ -0,0 +1,51 @@
+//go:build sqlite
+
+package cache
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/dfanso/commit-msg/internal/sqlstore"
+	"github.com/dfanso/commit-msg/pkg/types"
+)
+
+func TestSQLiteBackendRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), sqlstore.FileName)
+	db, err := sqlstore.Open(path)
+	if err != nil {
+		t.Fatalf("Open returned error: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	cm := NewCacheManagerWithBackend(path, NewSQLiteBackend(db))
+	opts := &types.GenerationOptions{Attempt: 1}
+	for _, diff := range []string{"one", "two"} {
+		if err := cm.Set(types.ProviderOpenAI, diff, opts, "feat: "+diff, 0.25, nil); err != nil {
+			t.Fatalf("Failed to set cache entry: %v", err)
+		}
+	}
+	cm.Get(types.ProviderOpenAI, "one", opts)
+	cm.Get(types.ProviderOpenAI, "missing", opts)
+	if err := cm.Flush(); err != nil {
+		t.Fatalf("Flush returned error: %v", err)
+	}
+
+	reloaded := NewCacheManagerWithBackend(path, NewSQLiteBackend(db))
+	entry, found := reloaded.Get(types.ProviderOpenAI, "two", opts)
+	if !found || entry.Message != "feat: two" {
+		t.Fatalf("expected the entry to be read back, got %+v, %v", entry, found)
+	}
+	stats := reloaded.GetStats()
+	if stats.TotalEntries != 2 || stats.TotalHits != 2 || stats.TotalMisses != 1 || stats.CacheSizeBytes == 0 {
+		t.Fatalf("unexpected statistics: %+v", stats)
+	}
+
+	if err := reloaded.Clear(); err != nil {
+		t.Fatalf("Clear returned error: %v", err)
+	}
+	entries, _, err := NewSQLiteBackend(db).Load()
+	if err != nil || len(entries) != 0 {
+		t.Fatalf("expected no entries after Clear, got %d, %v", len(entries), err)
+	}
+}
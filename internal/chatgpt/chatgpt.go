@@ -2,37 +2,137 @@ package chatgpt
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"os"
+	"strings"
 
 	openai "github.com/openai/openai-go/v3"
 	"github.com/openai/openai-go/v3/option"
 
+	httpClient "github.com/dfanso/commit-msg/internal/http"
+	"github.com/dfanso/commit-msg/internal/llmerr"
 	"github.com/dfanso/commit-msg/pkg/types"
 )
 
 const (
-	chatgptModel = openai.ChatModelGPT4o
+	// DefaultModel is the OpenAI model used to generate commit messages.
+	DefaultModel = openai.ChatModelGPT4o
+	// DefaultBaseURL is the OpenAI API root used when OPENAI_BASE_URL is unset.
+	DefaultBaseURL = "https://api.openai.com/v1"
 )
 
+// BaseURL returns the API root requests are sent to: OPENAI_BASE_URL, then
+// the openai_api setting carried in config, then DefaultBaseURL. Either
+// points the client at a proxy or OpenAI-compatible gateway such as LiteLLM.
+func BaseURL(config *types.Config) string {
+	if base := strings.TrimSpace(os.Getenv("OPENAI_BASE_URL")); base != "" {
+		return strings.TrimRight(base, "/")
+	}
+	if config != nil && strings.TrimSpace(config.OpenAIAPI) != "" {
+		return strings.TrimRight(strings.TrimSpace(config.OpenAIAPI), "/")
+	}
+	return DefaultBaseURL
+}
+
+// firstEnv returns the first non-empty value among the named variables.
+func firstEnv(names ...string) string {
+	for _, name := range names {
+		if value := strings.TrimSpace(os.Getenv(name)); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// clientOptions builds the request options for apiKey, honouring the base
+// URL from config and the environment, and the organization and project
+// settings from the environment.
+func clientOptions(config *types.Config, apiKey string) []option.RequestOption {
+	opts := []option.RequestOption{
+		option.WithAPIKey(apiKey),
+		option.WithHTTPClient(httpClient.ClientFor(types.ProviderOpenAI)),
+		option.WithBaseURL(BaseURL(config) + "/"),
+	}
+	if org := firstEnv("OPENAI_ORG", "OPENAI_ORG_ID"); org != "" {
+		opts = append(opts, option.WithOrganization(org))
+	}
+	if project := firstEnv("OPENAI_PROJECT", "OPENAI_PROJECT_ID"); project != "" {
+		opts = append(opts, option.WithProject(project))
+	}
+	return opts
+}
+
 // GenerateCommitMessage calls OpenAI's chat completions API to turn the provided
 // repository changes into a polished git commit message.
 func GenerateCommitMessage(config *types.Config, changes string, apiKey string, opts *types.GenerationOptions) (string, error) {
+	messages, err := GenerateCandidates(config, changes, apiKey, opts, 1)
+	if err != nil {
+		return "", err
+	}
+	return messages[0], nil
+}
+
+// GenerateCandidates asks for n alternative commit messages in a single
+// request using the API's n parameter, which costs one prompt rather than n.
+func GenerateCandidates(config *types.Config, changes string, apiKey string, opts *types.GenerationOptions, n int) ([]string, error) {
+	if strings.TrimSpace(apiKey) == "" {
+		return nil, errors.New("openai: API key is required")
+	}
+	if changes == "" {
+		return nil, fmt.Errorf("no changes provided for commit message generation")
+	}
 
-	client := openai.NewClient(option.WithAPIKey(apiKey))
+	client := openai.NewClient(clientOptions(config, apiKey)...)
 
 	prompt := types.BuildCommitPrompt(changes, opts)
 
-	resp, err := client.Chat.Completions.New(context.TODO(), openai.ChatCompletionNewParams{
+	params := openai.ChatCompletionNewParams{
 		Messages: []openai.ChatCompletionMessageParamUnion{
 			openai.UserMessage(prompt),
 		},
-		Model: chatgptModel,
-	})
+		Model: DefaultModel,
+	}
+	if opts != nil {
+		if opts.Temperature != nil {
+			params.Temperature = openai.Float(*opts.Temperature)
+		}
+		if opts.Seed != nil {
+			params.Seed = openai.Int(*opts.Seed)
+		}
+		if opts.MaxTokens > 0 {
+			params.MaxCompletionTokens = openai.Int(int64(opts.MaxTokens))
+		}
+	}
+	if n > 1 {
+		params.N = openai.Int(int64(n))
+	}
+
+	resp, err := client.Chat.Completions.New(context.TODO(), params)
 	if err != nil {
-		return "", fmt.Errorf("OpenAI error: %w", err)
+		var apiErr *openai.Error
+		if errors.As(err, &apiErr) {
+			var header http.Header
+			if apiErr.Response != nil {
+				header = apiErr.Response.Header
+			}
+			return nil, llmerr.New(types.ProviderOpenAI, apiErr.StatusCode, header, llmerr.Payload{
+				Type:    apiErr.Type,
+				Code:    apiErr.Code,
+				Message: apiErr.Message,
+			})
+		}
+		return nil, fmt.Errorf("OpenAI error: %w", err)
 	}
 
-	// Extract and return the commit message
-	commitMsg := resp.Choices[0].Message.Content
-	return commitMsg, nil
+	if len(resp.Choices) == 0 {
+		return nil, fmt.Errorf("no response generated")
+	}
+
+	messages := make([]string, 0, len(resp.Choices))
+	for _, choice := range resp.Choices {
+		messages = append(messages, choice.Message.Content)
+	}
+	return messages, nil
 }
@@ -4,28 +4,46 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"os"
+	"strconv"
+	"strings"
 
 	httpClient "github.com/dfanso/commit-msg/internal/http"
+	"github.com/dfanso/commit-msg/internal/llmerr"
 	"github.com/dfanso/commit-msg/pkg/types"
 )
 
 const (
-	claudeModel        = "claude-3-5-sonnet-20241022"
-	claudeMaxTokens    = 200
-	claudeAPIEndpoint  = "https://api.anthropic.com/v1/messages"
-	claudeAPIVersion   = "2023-06-01"
-	contentTypeJSON    = "application/json"
+	// DefaultModel is the Claude model used to generate commit messages.
+	DefaultModel = "claude-3-5-sonnet-20241022"
+	// DefaultMaxTokens leaves room for a subject plus a detailed body.
+	DefaultMaxTokens = 1024
+	// DefaultEndpoint is Anthropic's messages endpoint.
+	DefaultEndpoint = "https://api.anthropic.com/v1/messages"
+	maxTemperature  = 1.0
+	// APIVersion is the default anthropic-version header sent with every
+	// request; CLAUDE_API_VERSION overrides it.
+	APIVersion             = "2023-06-01"
+	contentTypeJSON        = "application/json"
 	anthropicVersionHeader = "anthropic-version"
-	xAPIKeyHeader      = "x-api-key"
+	xAPIKeyHeader          = "x-api-key"
 )
 
+// DefaultSystemPrompt frames the task for Claude; CLAUDE_SYSTEM_PROMPT
+// replaces it.
+const DefaultSystemPrompt = "You write clear, accurate git commit messages. Reply with the commit message only, without commentary or formatting."
+
 // ClaudeRequest describes the payload sent to Anthropic's Claude messages API.
 type ClaudeRequest struct {
-	Model     string          `json:"model"`
-	Messages  []types.Message `json:"messages"`
-	MaxTokens int             `json:"max_tokens"`
+	Model       string          `json:"model"`
+	System      string          `json:"system,omitempty"`
+	Messages    []types.Message `json:"messages"`
+	MaxTokens   int             `json:"max_tokens"`
+	Temperature *float64        `json:"temperature,omitempty"`
 }
 
 // ClaudeResponse captures the subset of fields used from Anthropic responses.
@@ -36,16 +54,94 @@ type ClaudeResponse struct {
 		Type string `json:"type"`
 		Text string `json:"text"`
 	} `json:"content"`
+	StopReason string `json:"stop_reason"`
+	Usage      struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+}
+
+// ResolveModel returns the model requested via CLAUDE_MODEL, or DefaultModel.
+func ResolveModel() string {
+	if model := strings.TrimSpace(os.Getenv("CLAUDE_MODEL")); model != "" {
+		return model
+	}
+	return DefaultModel
+}
+
+// Endpoint returns the messages endpoint: CLAUDE_API_URL, then the
+// claude_api setting carried in config, then DefaultEndpoint.
+func Endpoint(config *types.Config) string {
+	if custom := strings.TrimSpace(os.Getenv("CLAUDE_API_URL")); custom != "" {
+		return custom
+	}
+	if config != nil && strings.TrimSpace(config.ClaudeAPI) != "" {
+		return strings.TrimSpace(config.ClaudeAPI)
+	}
+	return DefaultEndpoint
+}
+
+// ModelsURL returns the model listing next to the messages endpoint.
+func ModelsURL(config *types.Config) string {
+	return strings.TrimSuffix(strings.TrimSuffix(Endpoint(config), "/"), "/messages") + "/models"
+}
+
+// Version returns the anthropic-version header value, honouring
+// CLAUDE_API_VERSION so newer API revisions can be used without a release.
+func Version() string {
+	if version := strings.TrimSpace(os.Getenv("CLAUDE_API_VERSION")); version != "" {
+		return version
+	}
+	return APIVersion
+}
+
+// resolveMaxTokens picks the max_tokens value: the per-request option first,
+// then CLAUDE_MAX_TOKENS, then DefaultMaxTokens.
+func resolveMaxTokens(opts *types.GenerationOptions) int {
+	if opts != nil && opts.MaxTokens > 0 {
+		return opts.MaxTokens
+	}
+	if value := strings.TrimSpace(os.Getenv("CLAUDE_MAX_TOKENS")); value != "" {
+		if n, err := strconv.Atoi(value); err == nil && n > 0 {
+			return n
+		}
+	}
+	return DefaultMaxTokens
+}
+
+func resolveSystemPrompt() string {
+	if prompt := strings.TrimSpace(os.Getenv("CLAUDE_SYSTEM_PROMPT")); prompt != "" {
+		return prompt
+	}
+	return DefaultSystemPrompt
 }
 
 // GenerateCommitMessage produces a commit summary using Anthropic's Claude API.
 func GenerateCommitMessage(config *types.Config, changes string, apiKey string, opts *types.GenerationOptions) (string, error) {
+	message, _, err := GenerateWithUsage(config, changes, apiKey, opts)
+	return message, err
+}
+
+// GenerateWithUsage is GenerateCommitMessage that also returns the token usage
+// Anthropic reported for the request.
+func GenerateWithUsage(config *types.Config, changes string, apiKey string, opts *types.GenerationOptions) (string, *types.UsageInfo, error) {
+	return generate(context.Background(), httpClient.ClientFor(types.ProviderClaude), Endpoint(config), changes, apiKey, opts)
+}
+
+func generate(ctx context.Context, client *http.Client, endpoint, changes, apiKey string, opts *types.GenerationOptions) (string, *types.UsageInfo, error) {
+	if strings.TrimSpace(apiKey) == "" {
+		return "", nil, errors.New("claude: API key is required")
+	}
+	if changes == "" {
+		return "", nil, fmt.Errorf("no changes provided for commit message generation")
+	}
 
 	prompt := types.BuildCommitPrompt(changes, opts)
 
 	reqBody := ClaudeRequest{
-		Model:     claudeModel,
-		MaxTokens: claudeMaxTokens,
+		Model:     ResolveModel(),
+		System:    resolveSystemPrompt(),
+		MaxTokens: resolveMaxTokens(opts),
 		Messages: []types.Message{
 			{
 				Role:    "user",
@@ -53,42 +149,61 @@ func GenerateCommitMessage(config *types.Config, changes string, apiKey string,
 			},
 		},
 	}
+	if opts != nil && opts.Temperature != nil {
+		// The messages API rejects temperatures above 1, which other
+		// providers accept.
+		temperature := min(*opts.Temperature, maxTemperature)
+		reqBody.Temperature = &temperature
+	}
 
 	jsonData, err := json.Marshal(reqBody)
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 
-	ctx := context.Background()
-	req, err := http.NewRequestWithContext(ctx, "POST", claudeAPIEndpoint, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewBuffer(jsonData))
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 
 	req.Header.Set("Content-Type", contentTypeJSON)
 	req.Header.Set(xAPIKeyHeader, apiKey)
-	req.Header.Set(anthropicVersionHeader, claudeAPIVersion)
+	req.Header.Set(anthropicVersionHeader, Version())
 
-	client := httpClient.GetClient()
 	resp, err := client.Do(req)
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("claude AI response %d", resp.StatusCode)
+		body, _ := io.ReadAll(resp.Body)
+		return "", nil, llmerr.FromResponse(types.ProviderClaude, resp.StatusCode, resp.Header, body)
 	}
 
 	var claudeResponse ClaudeResponse
 	if err := json.NewDecoder(resp.Body).Decode(&claudeResponse); err != nil {
-		return "", err
+		return "", nil, err
 	}
 
-	if len(claudeResponse.Content) == 0 {
-		return "", fmt.Errorf("no response generated")
+	var text strings.Builder
+	for _, block := range claudeResponse.Content {
+		if block.Type == "" || block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+	if text.Len() == 0 {
+		return "", nil, fmt.Errorf("no response generated")
+	}
+
+	usage := &types.UsageInfo{
+		PromptTokens:     claudeResponse.Usage.InputTokens,
+		CompletionTokens: claudeResponse.Usage.OutputTokens,
+		TotalTokens:      claudeResponse.Usage.InputTokens + claudeResponse.Usage.OutputTokens,
+	}
+	if usage.TotalTokens == 0 {
+		usage = nil
 	}
 
-	commitMsg := claudeResponse.Content[0].Text
-	return commitMsg, nil
+	return text.String(), usage, nil
 }
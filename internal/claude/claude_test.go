@@ -1,12 +1,18 @@
 package claude
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
 	"strings"
 	"testing"
 
+	httpClient "github.com/dfanso/commit-msg/internal/http"
+	"github.com/dfanso/commit-msg/internal/llmerr"
+	"github.com/dfanso/commit-msg/internal/vcr"
 	"github.com/dfanso/commit-msg/pkg/types"
 )
 
@@ -79,33 +85,27 @@ func TestGenerateCommitMessageWithMockServer(t *testing.T) {
 		}))
 		t.Cleanup(server.Close)
 
-		// Override the API URL for testing
-		originalURL := "https://api.anthropic.com/v1/messages"
-
-		// This would require modifying the function to accept a URL parameter
-		// For now, we'll test the error handling path
-		_, err := GenerateCommitMessage(&types.Config{}, "some changes", "invalid-key", nil)
-		if err == nil {
-			t.Fatal("expected error for invalid API key")
+		message, _, err := generate(context.Background(), server.Client(), server.URL, "some changes", "test-key", nil)
+		if err != nil {
+			t.Fatalf("generate: %v", err)
+		}
+		if message != "feat: add new feature" {
+			t.Fatalf("expected message 'feat: add new feature', got %q", message)
 		}
-
-		_ = originalURL // Avoid unused variable warning
 	})
 
 	t.Run("API error response", func(t *testing.T) {
 		t.Parallel()
 
 		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			w.WriteHeader(http.StatusBadRequest)
-			w.Write([]byte(`{"error": "invalid request"}`))
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"type":"error","error":{"type":"authentication_error","message":"invalid x-api-key"}}`))
 		}))
 		t.Cleanup(server.Close)
 
-		// This would require modifying the function to accept a URL parameter
-		// For now, we'll test the error handling path
-		_, err := GenerateCommitMessage(&types.Config{}, "some changes", "invalid-key", nil)
-		if err == nil {
-			t.Fatal("expected error for invalid API key")
+		_, err := GenerateCommitMessage(&types.Config{ClaudeAPI: server.URL}, "some changes", "invalid-key", nil)
+		if !errors.Is(err, llmerr.ErrAuth) {
+			t.Fatalf("expected ErrAuth, got %v", err)
 		}
 	})
 
@@ -127,11 +127,9 @@ func TestGenerateCommitMessageWithMockServer(t *testing.T) {
 		}))
 		t.Cleanup(server.Close)
 
-		// This would require modifying the function to accept a URL parameter
-		// For now, we'll test the error handling path
-		_, err := GenerateCommitMessage(&types.Config{}, "some changes", "invalid-key", nil)
+		_, err := GenerateCommitMessage(&types.Config{ClaudeAPI: server.URL}, "some changes", "test-key", nil)
 		if err == nil {
-			t.Fatal("expected error for invalid API key")
+			t.Fatal("expected error for a response without text")
 		}
 	})
 }
@@ -139,16 +137,29 @@ func TestGenerateCommitMessageWithMockServer(t *testing.T) {
 func TestGenerateCommitMessageIncludesStyleInstructions(t *testing.T) {
 	t.Parallel()
 
-	// Test that style instructions are included in the prompt
+	var req ClaudeRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"msg_1","type":"message","content":[{"type":"text","text":"chore: tidy things up"}]}`))
+	}))
+	t.Cleanup(server.Close)
+
 	opts := &types.GenerationOptions{
 		StyleInstruction: "Use a casual tone",
 		Attempt:          2,
 	}
-
-	// Test with invalid key to verify the function processes the options
-	_, err := GenerateCommitMessage(&types.Config{}, "some changes", "invalid-key", opts)
-	if err == nil {
-		t.Fatal("expected error for invalid API key")
+	message, err := GenerateCommitMessage(&types.Config{ClaudeAPI: server.URL}, "some changes", "test-key", opts)
+	if err != nil {
+		t.Fatalf("GenerateCommitMessage: %v", err)
+	}
+	if message != "chore: tidy things up" {
+		t.Fatalf("unexpected message %q", message)
+	}
+	if len(req.Messages) != 1 || !strings.Contains(req.Messages[0].Content, "Use a casual tone") {
+		t.Fatalf("expected the style instruction in the prompt, got %+v", req.Messages)
 	}
 }
 
@@ -239,23 +250,164 @@ func TestGenerateCommitMessageWithInvalidJSON(t *testing.T) {
 	}))
 	t.Cleanup(server.Close)
 
-	// This would require modifying the function to accept a URL parameter
-	// For now, we'll test the error handling path
-	_, err := GenerateCommitMessage(&types.Config{}, "some changes", "invalid-key", nil)
+	_, err := GenerateCommitMessage(&types.Config{ClaudeAPI: server.URL}, "some changes", "test-key", nil)
 	if err == nil {
-		t.Fatal("expected error for invalid API key")
+		t.Fatal("expected error for invalid JSON")
 	}
 }
 
 func TestGenerateCommitMessageWithLongPrompt(t *testing.T) {
 	t.Parallel()
 
-	// Create a very long prompt
 	longChanges := strings.Repeat("This is a test change. ", 1000)
 
-	// Test with invalid key to verify the function handles long prompts
-	_, err := GenerateCommitMessage(&types.Config{}, longChanges, "invalid-key", nil)
-	if err == nil {
-		t.Fatal("expected error for invalid API key")
+	var req ClaudeRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"msg_1","type":"message","content":[{"type":"text","text":"test: add many changes"}]}`))
+	}))
+	t.Cleanup(server.Close)
+
+	if _, err := GenerateCommitMessage(&types.Config{ClaudeAPI: server.URL}, longChanges, "test-key", nil); err != nil {
+		t.Fatalf("GenerateCommitMessage: %v", err)
+	}
+	if len(req.Messages) != 1 || !strings.Contains(req.Messages[0].Content, longChanges) {
+		t.Fatal("expected the whole change set in the prompt")
+	}
+}
+
+func TestGenerateSendsConfiguredRequest(t *testing.T) {
+	t.Setenv("CLAUDE_MODEL", "claude-sonnet-4-5")
+	t.Setenv("CLAUDE_MAX_TOKENS", "2048")
+	t.Setenv("CLAUDE_API_VERSION", "2024-01-01")
+
+	var req ClaudeRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("anthropic-version"); got != "2024-01-01" {
+			t.Errorf("expected anthropic-version '2024-01-01', got %s", got)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"msg_1","type":"message","content":[{"type":"text","text":"fix: retry uploads"}],"usage":{"input_tokens":120,"output_tokens":8}}`))
+	}))
+	t.Cleanup(server.Close)
+
+	_, usage, err := generate(context.Background(), server.Client(), server.URL, "some changes", "test-key", nil)
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+
+	if req.Model != "claude-sonnet-4-5" {
+		t.Fatalf("expected model override, got %s", req.Model)
+	}
+	if req.MaxTokens != 2048 {
+		t.Fatalf("expected max tokens 2048, got %d", req.MaxTokens)
+	}
+	if req.System != DefaultSystemPrompt {
+		t.Fatalf("expected default system prompt, got %q", req.System)
+	}
+	if usage == nil || usage.PromptTokens != 120 || usage.CompletionTokens != 8 || usage.TotalTokens != 128 {
+		t.Fatalf("unexpected usage %+v", usage)
+	}
+}
+
+func TestResolveMaxTokensPrefersOptions(t *testing.T) {
+	t.Setenv("CLAUDE_MAX_TOKENS", "2048")
+
+	if got := resolveMaxTokens(&types.GenerationOptions{MaxTokens: 300}); got != 300 {
+		t.Fatalf("expected option to win, got %d", got)
+	}
+
+	t.Setenv("CLAUDE_MAX_TOKENS", "lots")
+	if got := resolveMaxTokens(nil); got != DefaultMaxTokens {
+		t.Fatalf("expected default for invalid value, got %d", got)
+	}
+}
+
+func TestGenerateCapsTemperature(t *testing.T) {
+	var req ClaudeRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"msg_1","type":"message","content":[{"type":"text","text":"fix: retry uploads"}]}`))
+	}))
+	t.Cleanup(server.Close)
+
+	temperature := 1.6
+	opts := &types.GenerationOptions{Temperature: &temperature}
+	if _, _, err := generate(context.Background(), server.Client(), server.URL, "some changes", "test-key", opts); err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	if req.Temperature == nil || *req.Temperature != 1 {
+		t.Fatalf("expected temperature capped at 1, got %v", req.Temperature)
+	}
+	if temperature != 1.6 {
+		t.Fatalf("expected the caller's options to be left alone, got %v", temperature)
+	}
+}
+
+// replay answers Claude requests from the named cassette in testdata.
+func replay(t *testing.T, name string) *vcr.Replayer {
+	t.Helper()
+
+	replayer, err := vcr.LoadReplayer(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("failed to load cassette: %v", err)
+	}
+	httpClient.Configure(httpClient.Settings{Wrap: replayer.Wrap})
+	t.Cleanup(func() { httpClient.Configure(httpClient.Settings{}) })
+	return replayer
+}
+
+func TestGenerateWithUsageReplaysRecordedResponses(t *testing.T) {
+	t.Setenv("CLAUDE_MODEL", "")
+	replayer := replay(t, "messages.json")
+
+	message, usage, err := GenerateWithUsage(&types.Config{}, "diff --git a/README.md b/README.md", "sk-ant-test", nil)
+	if err != nil {
+		t.Fatalf("GenerateWithUsage returned error: %v", err)
+	}
+	if message != "docs(readme): document the record and replay variables" {
+		t.Fatalf("unexpected message: %q", message)
+	}
+	if usage == nil || usage.PromptTokens != 388 || usage.CompletionTokens != 12 || usage.TotalTokens != 400 {
+		t.Fatalf("unexpected usage: %+v", usage)
+	}
+
+	_, _, err = GenerateWithUsage(&types.Config{}, "diff --git a/README.md b/README.md", "sk-ant-test", nil)
+	if !errors.Is(err, llmerr.ErrRateLimited) {
+		t.Fatalf("expected ErrRateLimited, got %v", err)
+	}
+	var apiErr *llmerr.Error
+	if !errors.As(err, &apiErr) || apiErr.RetryAfter.Seconds() != 12 {
+		t.Fatalf("expected Retry-After to be honoured, got %+v", err)
+	}
+	if replayer.Remaining() != 0 {
+		t.Fatalf("expected every recorded interaction to be used, %d remain", replayer.Remaining())
+	}
+}
+
+func TestEndpoint(t *testing.T) {
+	t.Setenv("CLAUDE_API_URL", "")
+	if got := Endpoint(nil); got != DefaultEndpoint {
+		t.Fatalf("Endpoint(nil) = %q", got)
+	}
+	config := &types.Config{ClaudeAPI: "https://gateway.example.com/anthropic/v1/messages"}
+	if got := Endpoint(config); got != config.ClaudeAPI {
+		t.Fatalf("expected claude_api to be honoured, got %q", got)
+	}
+	if got := ModelsURL(config); got != "https://gateway.example.com/anthropic/v1/models" {
+		t.Fatalf("unexpected models URL %q", got)
+	}
+	t.Setenv("CLAUDE_API_URL", "http://localhost:8080/v1/messages")
+	if got := Endpoint(config); got != "http://localhost:8080/v1/messages" {
+		t.Fatalf("expected CLAUDE_API_URL to win, got %q", got)
 	}
 }
@@ -0,0 +1,198 @@
+// Package cohere generates commit messages with Cohere's Command models
+// through the v2 chat API.
+package cohere
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	internalHTTP "github.com/dfanso/commit-msg/internal/http"
+	"github.com/dfanso/commit-msg/internal/llmerr"
+	"github.com/dfanso/commit-msg/pkg/types"
+)
+
+// DefaultModel is a fast, inexpensive Command model that handles commit
+// messages well. It applies only when no model was chosen during setup and
+// COHERE_MODEL is unset.
+const DefaultModel = "command-r-08-2024"
+
+const (
+	cohereTemperature         = 0.2
+	cohereMaxTokens           = 200
+	cohereSystemMessage       = "You are an assistant that writes clear, concise git commit messages."
+	cohereContentType         = "application/json"
+	cohereAuthorizationPrefix = "Bearer "
+)
+
+var (
+	// allow overrides in tests
+	baseURL = "https://api.cohere.com/v2/chat"
+	// httpClient can be overridden in tests; nil uses the shared Cohere client
+	httpClient *http.Client
+)
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatRequest struct {
+	Model       string        `json:"model"`
+	Messages    []chatMessage `json:"messages"`
+	Temperature float64       `json:"temperature"`
+	MaxTokens   int           `json:"max_tokens"`
+	Seed        *int64        `json:"seed,omitempty"`
+}
+
+type tokenCounts struct {
+	InputTokens  float64 `json:"input_tokens"`
+	OutputTokens float64 `json:"output_tokens"`
+}
+
+type chatResponse struct {
+	FinishReason string `json:"finish_reason"`
+	Message      struct {
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+	} `json:"message"`
+	Usage struct {
+		BilledUnits tokenCounts `json:"billed_units"`
+		Tokens      tokenCounts `json:"tokens"`
+	} `json:"usage"`
+}
+
+// errorResponse is the body Cohere returns for failed requests.
+type errorResponse struct {
+	Message string `json:"message"`
+}
+
+// ResolveModel returns the model requested via COHERE_MODEL, then saved (the
+// model chosen during setup), then DefaultModel.
+func ResolveModel(saved string) string {
+	if model := strings.TrimSpace(os.Getenv("COHERE_MODEL")); model != "" {
+		return model
+	}
+	if saved = strings.TrimSpace(saved); saved != "" {
+		return saved
+	}
+	return DefaultModel
+}
+
+// chatURL returns the chat endpoint, honouring COHERE_API_URL.
+func chatURL() string {
+	if customEndpoint := strings.TrimSpace(os.Getenv("COHERE_API_URL")); customEndpoint != "" {
+		return customEndpoint
+	}
+	return baseURL
+}
+
+func client() *http.Client {
+	if httpClient != nil {
+		return httpClient
+	}
+	return internalHTTP.ClientFor(types.ProviderCohere)
+}
+
+// GenerateCommitMessage asks Cohere's chat API for a commit message using
+// model, or the model ResolveModel picks when model is empty. It also
+// returns the billed token usage.
+func GenerateCommitMessage(ctx context.Context, changes string, apiKey string, model string, opts *types.GenerationOptions) (string, *types.UsageInfo, error) {
+	if changes == "" {
+		return "", nil, fmt.Errorf("no changes provided for commit message generation")
+	}
+	if model == "" {
+		model = ResolveModel("")
+	}
+
+	payload := chatRequest{
+		Model:       model,
+		Temperature: opts.TemperatureOr(cohereTemperature),
+		MaxTokens:   cohereMaxTokens,
+		Messages: []chatMessage{
+			{Role: "system", Content: cohereSystemMessage},
+			{Role: "user", Content: types.BuildCommitPrompt(changes, opts)},
+		},
+	}
+	if opts != nil {
+		payload.Seed = opts.Seed
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to marshal Cohere request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, chatURL(), bytes.NewReader(body))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create Cohere request: %w", err)
+	}
+	req.Header.Set("Content-Type", cohereContentType)
+	req.Header.Set("Authorization", cohereAuthorizationPrefix+apiKey)
+
+	resp, err := client().Do(req)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to call Cohere API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	responseBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read Cohere response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, responseError(resp, responseBody)
+	}
+
+	var completion chatResponse
+	if err := json.Unmarshal(responseBody, &completion); err != nil {
+		return "", nil, fmt.Errorf("failed to decode Cohere response: %w", err)
+	}
+
+	var text strings.Builder
+	for _, block := range completion.Message.Content {
+		if block.Type == "" || block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+	if strings.TrimSpace(text.String()) == "" {
+		return "", nil, fmt.Errorf("empty response from Cohere API")
+	}
+
+	return text.String(), usageFrom(completion), nil
+}
+
+// usageFrom reports the billed units, which is what Cohere charges for,
+// falling back to the raw token counts.
+func usageFrom(completion chatResponse) *types.UsageInfo {
+	counts := completion.Usage.BilledUnits
+	if counts.InputTokens == 0 && counts.OutputTokens == 0 {
+		counts = completion.Usage.Tokens
+	}
+	usage := &types.UsageInfo{
+		PromptTokens:     int(counts.InputTokens),
+		CompletionTokens: int(counts.OutputTokens),
+	}
+	usage.TotalTokens = usage.PromptTokens + usage.CompletionTokens
+	if usage.TotalTokens == 0 {
+		return nil
+	}
+	return usage
+}
+
+// responseError classifies a failed response. Cohere puts the reason in a
+// top-level message rather than the error object other providers use.
+func responseError(resp *http.Response, body []byte) error {
+	var failure errorResponse
+	if err := json.Unmarshal(body, &failure); err == nil && failure.Message != "" {
+		return llmerr.New(types.ProviderCohere, resp.StatusCode, resp.Header, llmerr.Payload{Message: failure.Message})
+	}
+	return llmerr.FromResponse(types.ProviderCohere, resp.StatusCode, resp.Header, body)
+}
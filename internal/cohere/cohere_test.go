@@ -0,0 +1,103 @@
+package cohere
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dfanso/commit-msg/internal/llmerr"
+)
+
+func withTestServer(t *testing.T, handler http.HandlerFunc) {
+	t.Helper()
+
+	t.Setenv("COHERE_MODEL", "")
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	t.Setenv("COHERE_API_URL", srv.URL+"/v2/chat")
+	prevClient := httpClient
+	httpClient = srv.Client()
+	t.Cleanup(func() { httpClient = prevClient })
+}
+
+func TestGenerateCommitMessage(t *testing.T) {
+	withTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer co_test" {
+			t.Fatalf("unexpected authorization header: %s", got)
+		}
+		var payload chatRequest
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+		if payload.Model != DefaultModel || len(payload.Messages) != 2 || payload.Messages[0].Role != "system" {
+			t.Fatalf("unexpected request: %+v", payload)
+		}
+		_, _ = w.Write([]byte(`{
+			"finish_reason": "COMPLETE",
+			"message": {"role": "assistant", "content": [{"type": "text", "text": "fix: trim trailing whitespace"}]},
+			"usage": {"billed_units": {"input_tokens": 120, "output_tokens": 8}, "tokens": {"input_tokens": 180, "output_tokens": 8}}
+		}`))
+	})
+
+	msg, usage, err := GenerateCommitMessage(context.Background(), "diff", "co_test", "", nil)
+	if err != nil {
+		t.Fatalf("GenerateCommitMessage returned error: %v", err)
+	}
+	if msg != "fix: trim trailing whitespace" {
+		t.Fatalf("unexpected message: %q", msg)
+	}
+	if usage == nil || usage.PromptTokens != 120 || usage.CompletionTokens != 8 || usage.TotalTokens != 128 {
+		t.Fatalf("expected billed usage, got %+v", usage)
+	}
+}
+
+func TestGenerateCommitMessageClassifiesErrors(t *testing.T) {
+	withTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"id":"abc","message":"invalid api token"}`))
+	})
+
+	_, _, err := GenerateCommitMessage(context.Background(), "diff", "bad", "", nil)
+	if !errors.Is(err, llmerr.ErrAuth) {
+		t.Fatalf("expected ErrAuth, got %v", err)
+	}
+	var apiErr *llmerr.Error
+	if !errors.As(err, &apiErr) || apiErr.Message != "invalid api token" {
+		t.Fatalf("expected Cohere's message, got %v", err)
+	}
+}
+
+func TestListModels(t *testing.T) {
+	withTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/models" || r.URL.Query().Get("endpoint") != "chat" {
+			t.Fatalf("unexpected request: %s", r.URL)
+		}
+		_, _ = w.Write([]byte(`{"models":[{"name":"command-r-plus-08-2024","endpoints":["chat"]},{"name":"command-a-03-2025","endpoints":["chat"]},{"name":""}]}`))
+	})
+
+	models, err := ListModels(context.Background(), "co_test")
+	if err != nil {
+		t.Fatalf("ListModels returned error: %v", err)
+	}
+	if len(models) != 2 || models[0].Name != "command-a-03-2025" {
+		t.Fatalf("unexpected models: %+v", models)
+	}
+}
+
+func TestResolveModel(t *testing.T) {
+	t.Setenv("COHERE_MODEL", "")
+	if got := ResolveModel(""); got != DefaultModel {
+		t.Fatalf("expected default model, got %q", got)
+	}
+	if got := ResolveModel("command-a-03-2025"); got != "command-a-03-2025" {
+		t.Fatalf("expected saved model, got %q", got)
+	}
+	t.Setenv("COHERE_MODEL", "command-r7b-12-2024")
+	if got := ResolveModel("command-a-03-2025"); got != "command-r7b-12-2024" {
+		t.Fatalf("expected COHERE_MODEL to win, got %q", got)
+	}
+}
@@ -0,0 +1,72 @@
+package cohere
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Model is a model listed by Cohere's /v1/models endpoint.
+type Model struct {
+	Name          string   `json:"name"`
+	Endpoints     []string `json:"endpoints"`
+	ContextLength float64  `json:"context_length"`
+}
+
+// ModelsURL returns the listing of models that serve the chat endpoint. When
+// COHERE_API_URL points at a compatible gateway the listing is looked up on
+// the same host.
+func ModelsURL() string {
+	base := "https://api.cohere.com"
+	if customEndpoint := strings.TrimSpace(os.Getenv("COHERE_API_URL")); customEndpoint != "" {
+		if u, err := url.Parse(customEndpoint); err == nil && u.Host != "" {
+			base = u.Scheme + "://" + u.Host
+		}
+	}
+	return base + "/v1/models?endpoint=chat&page_size=1000"
+}
+
+// ListModels returns the chat models available to apiKey, sorted by name.
+func ListModels(ctx context.Context, apiKey string) ([]Model, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ModelsURL(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Cohere request: %w", err)
+	}
+	req.Header.Set("Authorization", cohereAuthorizationPrefix+apiKey)
+
+	resp, err := client().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call Cohere API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Cohere response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, responseError(resp, body)
+	}
+
+	var list struct {
+		Models []Model `json:"models"`
+	}
+	if err := json.Unmarshal(body, &list); err != nil {
+		return nil, fmt.Errorf("failed to decode Cohere model list: %w", err)
+	}
+
+	var models []Model
+	for _, m := range list.Models {
+		if m.Name != "" {
+			models = append(models, m)
+		}
+	}
+	sort.Slice(models, func(i, j int) bool { return models[i].Name < models[j].Name })
+	return models, nil
+}
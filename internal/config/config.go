@@ -0,0 +1,671 @@
+// Package config reads user-tunable settings from config.json.
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dfanso/commit-msg/internal/blocklist"
+	"github.com/dfanso/commit-msg/internal/cache"
+	"github.com/dfanso/commit-msg/internal/generated"
+	"github.com/dfanso/commit-msg/internal/history"
+	httpClient "github.com/dfanso/commit-msg/internal/http"
+	"github.com/dfanso/commit-msg/internal/huggingface"
+	"github.com/dfanso/commit-msg/internal/lint"
+	"github.com/dfanso/commit-msg/internal/ollama"
+	"github.com/dfanso/commit-msg/internal/postprocess"
+	"github.com/dfanso/commit-msg/internal/projectctx"
+	"github.com/dfanso/commit-msg/internal/scrubber"
+	"github.com/dfanso/commit-msg/internal/spellcheck"
+	"github.com/dfanso/commit-msg/internal/sqlstore"
+	"github.com/dfanso/commit-msg/internal/telemetry"
+	"github.com/dfanso/commit-msg/internal/vertex"
+	"github.com/dfanso/commit-msg/pkg/types"
+	StoreUtils "github.com/dfanso/commit-msg/utils"
+)
+
+// httpFile is the "http" section of config.json. Durations are Go duration
+// strings such as "45s" or "10m"; pinned certificates are SHA-256
+// fingerprints keyed by provider.
+type httpFile struct {
+	Timeout            string            `json:"timeout"`
+	MaxIdleConns       int               `json:"max_idle_conns"`
+	IdleConnTimeout    string            `json:"idle_conn_timeout"`
+	KeepAlive          string            `json:"keep_alive"`
+	ProviderTimeouts   map[string]string `json:"provider_timeouts"`
+	PinnedCertificates map[string]string `json:"pinned_certificates"`
+}
+
+// Environment variables that override the "http" section.
+const (
+	HTTPTimeoutEnv         = "COMMIT_HTTP_TIMEOUT"
+	HTTPMaxIdleConnsEnv    = "COMMIT_HTTP_MAX_IDLE_CONNS"
+	HTTPIdleConnTimeoutEnv = "COMMIT_HTTP_IDLE_CONN_TIMEOUT"
+	HTTPKeepAliveEnv       = "COMMIT_HTTP_KEEP_ALIVE"
+	OllamaTimeoutEnv       = "COMMIT_OLLAMA_TIMEOUT"
+)
+
+// readSections returns the top-level keys of the config at path. A missing
+// or empty file has none.
+func readSections(path string) (map[string]json.RawMessage, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) || (err == nil && len(data) <= 2) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	var sections map[string]json.RawMessage
+	if err := json.Unmarshal(data, &sections); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	return sections, nil
+}
+
+// decodeSection decodes the section named key over defaults, so keys the
+// section omits keep their default, then checks it with validate when that
+// is not nil. A missing or null section yields defaults, as does any error.
+// defaults must not share maps or slices with other values, since decoding
+// may write to them.
+func decodeSection[T any](sections map[string]json.RawMessage, path, key string, defaults T, validate func(*T) error) (T, error) {
+	raw, ok := sections[key]
+	if !ok || string(raw) == "null" {
+		return defaults, nil
+	}
+
+	value := defaults
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return defaults, fmt.Errorf("failed to parse config %s: %s: %w", path, key, err)
+	}
+	if validate != nil {
+		if err := validate(&value); err != nil {
+			return defaults, fmt.Errorf("invalid %q section in %s: %w", key, path, err)
+		}
+	}
+	return value, nil
+}
+
+// loadSection reads the section named key of the config at path, as
+// decodeSection does. A missing or empty file yields defaults.
+func loadSection[T any](path, key string, defaults T, validate func(*T) error) (T, error) {
+	sections, err := readSections(path)
+	if err != nil {
+		return defaults, err
+	}
+	return decodeSection(sections, path, key, defaults, validate)
+}
+
+// LoadLimits returns the content limits from the user's config.json with
+// defaults filled in. A missing file or "limits" section is not an error.
+func LoadLimits() (types.ContentLimits, error) {
+	path, err := StoreUtils.GetConfigPath()
+	if err != nil {
+		return types.ContentLimits{}.WithDefaults(), err
+	}
+	return LoadLimitsFile(path)
+}
+
+// LoadLimitsFile is like LoadLimits but reads the config at path.
+func LoadLimitsFile(path string) (types.ContentLimits, error) {
+	limits, err := loadSection(path, "limits", types.ContentLimits{}, func(limits *types.ContentLimits) error {
+		if timeout := limits.UntrackedReadTimeout; timeout != "" {
+			if d, err := time.ParseDuration(timeout); err != nil || d <= 0 {
+				return fmt.Errorf("invalid untracked_read_timeout %q", timeout)
+			}
+		}
+		return nil
+	})
+	return limits.WithDefaults(), err
+}
+
+// LoadPromptTemplate returns the prompt template configured by the
+// "prompt_template" key of config.json, which names a text/template file.
+// Relative paths are resolved against the config directory. It returns ""
+// when no override is configured.
+func LoadPromptTemplate() (string, error) {
+	path, err := StoreUtils.GetConfigPath()
+	if err != nil {
+		return "", err
+	}
+	return LoadPromptTemplateFile(path)
+}
+
+// LoadPromptTemplateFile is like LoadPromptTemplate but reads the config at
+// path. The template is validated before it is returned.
+func LoadPromptTemplateFile(path string) (string, error) {
+	templatePath, err := loadSection(path, "prompt_template", "", nil)
+	if err != nil || templatePath == "" {
+		return "", err
+	}
+
+	if !filepath.IsAbs(templatePath) {
+		templatePath = filepath.Join(filepath.Dir(path), templatePath)
+	}
+
+	text, err := os.ReadFile(templatePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read prompt template: %w", err)
+	}
+	if err := types.ValidatePromptTemplate(string(text)); err != nil {
+		return "", fmt.Errorf("invalid prompt template %s: %w", templatePath, err)
+	}
+	return string(text), nil
+}
+
+// LoadProviderConfig returns the provider endpoints configured by the
+// "openai_api", "claude_api", "gemini_api", and "grok_api" keys of
+// config.json, such as a gateway in front of a provider. Unset keys are
+// empty, leaving the provider's default in effect.
+func LoadProviderConfig() (*types.Config, error) {
+	path, err := StoreUtils.GetConfigPath()
+	if err != nil {
+		return &types.Config{}, err
+	}
+	return LoadProviderConfigFile(path)
+}
+
+// LoadProviderConfigFile is like LoadProviderConfig but reads the config at
+// path.
+func LoadProviderConfigFile(path string) (*types.Config, error) {
+	sections, err := readSections(path)
+	if err != nil {
+		return &types.Config{}, err
+	}
+
+	cfg := &types.Config{}
+	endpoints := []struct {
+		key string
+		dst *string
+	}{
+		{"openai_api", &cfg.OpenAIAPI},
+		{"claude_api", &cfg.ClaudeAPI},
+		{"gemini_api", &cfg.GeminiAPI},
+		{"grok_api", &cfg.GrokAPI},
+	}
+	for _, endpoint := range endpoints {
+		value, err := decodeSection(sections, path, endpoint.key, "", nil)
+		if err != nil {
+			return &types.Config{}, err
+		}
+		if err := validateEndpoint(endpoint.key, value); err != nil {
+			return &types.Config{}, err
+		}
+		*endpoint.dst = strings.TrimSpace(value)
+	}
+	return cfg, nil
+}
+
+// validateEndpoint reports whether value, the setting named key, is empty
+// or an http or https URL.
+func validateEndpoint(key, value string) error {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid %s %q: use an http or https URL", key, value)
+	}
+	return nil
+}
+
+// LoadPostProcess returns the post-processing options from the "postprocess"
+// section of config.json. Keys that are omitted keep their default (enabled).
+func LoadPostProcess() (postprocess.Options, error) {
+	path, err := StoreUtils.GetConfigPath()
+	if err != nil {
+		return postprocess.DefaultOptions(), err
+	}
+	return LoadPostProcessFile(path)
+}
+
+// LoadPostProcessFile is like LoadPostProcess but reads the config at path.
+func LoadPostProcessFile(path string) (postprocess.Options, error) {
+	return loadSection(path, "postprocess", postprocess.DefaultOptions(), nil)
+}
+
+// LoadLint returns the rules checked by commit lint from the "lint" section
+// of config.json. Keys that are omitted keep their default.
+func LoadLint() (lint.Rules, error) {
+	path, err := StoreUtils.GetConfigPath()
+	if err != nil {
+		return lint.DefaultRules(), err
+	}
+	return LoadLintFile(path)
+}
+
+// LoadLintFile is like LoadLint but reads the config at path.
+func LoadLintFile(path string) (lint.Rules, error) {
+	return loadSection(path, "lint", lint.DefaultRules(), func(rules *lint.Rules) error {
+		if rules.MaxSubjectLength < 0 || rules.MaxBodyLineLength < 0 {
+			return errors.New("lengths must not be negative")
+		}
+		return nil
+	})
+}
+
+// LoadHistory returns the message history settings from the "history"
+// section of config.json. A missing file or section records accepted
+// messages only.
+func LoadHistory() (history.Settings, error) {
+	path, err := StoreUtils.GetConfigPath()
+	if err != nil {
+		return history.Settings{}, err
+	}
+	return LoadHistoryFile(path)
+}
+
+// LoadHistoryFile is like LoadHistory but reads the config at path.
+func LoadHistoryFile(path string) (history.Settings, error) {
+	return loadSection(path, "history", history.Settings{}, func(settings *history.Settings) error {
+		if settings.MaxEntries < 0 {
+			return errors.New("max_entries must not be negative")
+		}
+		return nil
+	})
+}
+
+// LoadCache returns the "cache" section of config.json. A missing file or
+// section keeps cached messages to the repository they were generated in.
+func LoadCache() (cache.Settings, error) {
+	path, err := StoreUtils.GetConfigPath()
+	if err != nil {
+		return cache.Settings{}, err
+	}
+	return LoadCacheFile(path)
+}
+
+// LoadCacheFile is like LoadCache but reads the config at path.
+func LoadCacheFile(path string) (cache.Settings, error) {
+	return loadSection(path, "cache", cache.Settings{}, nil)
+}
+
+// LoadStorage returns the "storage" section of config.json, which chooses
+// between the JSON files and a SQLite database for the cache and history.
+// A missing file or section keeps the JSON files.
+func LoadStorage() (sqlstore.Settings, error) {
+	path, err := StoreUtils.GetConfigPath()
+	if err != nil {
+		return sqlstore.Settings{}, err
+	}
+	return LoadStorageFile(path)
+}
+
+// LoadStorageFile is like LoadStorage but reads the config at path.
+func LoadStorageFile(path string) (sqlstore.Settings, error) {
+	return loadSection(path, "storage", sqlstore.Settings{}, func(settings *sqlstore.Settings) error {
+		return settings.Validate()
+	})
+}
+
+// LoadSpellcheck returns the "spellcheck" section of config.json. The
+// misspelling dictionary is off unless enabled there.
+func LoadSpellcheck() (spellcheck.Settings, error) {
+	path, err := StoreUtils.GetConfigPath()
+	if err != nil {
+		return spellcheck.Settings{}, err
+	}
+	return LoadSpellcheckFile(path)
+}
+
+// LoadSpellcheckFile is like LoadSpellcheck but reads the config at path.
+func LoadSpellcheckFile(path string) (spellcheck.Settings, error) {
+	return loadSection(path, "spellcheck", spellcheck.Settings{}, nil)
+}
+
+// LoadBlocklist returns the "blocklist" section of config.json. No phrases
+// are blocked by default.
+func LoadBlocklist() (blocklist.Settings, error) {
+	path, err := StoreUtils.GetConfigPath()
+	if err != nil {
+		return blocklist.Settings{}, err
+	}
+	return LoadBlocklistFile(path)
+}
+
+// LoadBlocklistFile is like LoadBlocklist but reads the config at path.
+func LoadBlocklistFile(path string) (blocklist.Settings, error) {
+	return loadSection(path, "blocklist", blocklist.Settings{}, nil)
+}
+
+// LoadGenerated returns the "generated" section of config.json, whose
+// patterns add to generated.DefaultPatterns.
+func LoadGenerated() (generated.Settings, error) {
+	path, err := StoreUtils.GetConfigPath()
+	if err != nil {
+		return generated.Settings{}, err
+	}
+	return LoadGeneratedFile(path)
+}
+
+// LoadGeneratedFile is like LoadGenerated but reads the config at path.
+func LoadGeneratedFile(path string) (generated.Settings, error) {
+	return loadSection(path, "generated", generated.Settings{}, nil)
+}
+
+// LoadProjectContext returns the "project_context" section of config.json.
+// No project files are included by default.
+func LoadProjectContext() (projectctx.Settings, error) {
+	path, err := StoreUtils.GetConfigPath()
+	if err != nil {
+		return projectctx.Settings{}, err
+	}
+	return LoadProjectContextFile(path)
+}
+
+// LoadProjectContextFile is like LoadProjectContext but reads the config at
+// path.
+func LoadProjectContextFile(path string) (projectctx.Settings, error) {
+	return loadSection(path, "project_context", projectctx.Settings{}, func(settings *projectctx.Settings) error {
+		if settings.MaxLines < 0 {
+			return errors.New("max_lines must not be negative")
+		}
+		return nil
+	})
+}
+
+// LoadRedaction returns the "redaction" section of config.json. Secrets are
+// replaced with scrubber.DefaultPlaceholder by default.
+func LoadRedaction() (scrubber.Settings, error) {
+	path, err := StoreUtils.GetConfigPath()
+	if err != nil {
+		return scrubber.Settings{}, err
+	}
+	return LoadRedactionFile(path)
+}
+
+// LoadRedactionFile is like LoadRedaction but reads the config at path.
+func LoadRedactionFile(path string) (scrubber.Settings, error) {
+	return loadSection(path, "redaction", scrubber.Settings{}, func(settings *scrubber.Settings) error {
+		return settings.Validate()
+	})
+}
+
+// LoadTelemetry returns the "telemetry" section of config.json. A missing
+// file or section leaves telemetry off.
+func LoadTelemetry() (telemetry.Settings, error) {
+	path, err := StoreUtils.GetConfigPath()
+	if err != nil {
+		return telemetry.Settings{}, err
+	}
+	return LoadTelemetryFile(path)
+}
+
+// LoadTelemetryFile is like LoadTelemetry but reads the config at path.
+func LoadTelemetryFile(path string) (telemetry.Settings, error) {
+	return loadSection(path, "telemetry", telemetry.Settings{}, nil)
+}
+
+// LoadStyles returns the style presets saved in the "styles" section of
+// config.json.
+func LoadStyles() ([]types.StylePreset, error) {
+	path, err := StoreUtils.GetConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	return LoadStylesFile(path)
+}
+
+// LoadStylesFile is like LoadStyles but reads the config at path.
+func LoadStylesFile(path string) ([]types.StylePreset, error) {
+	return loadSection(path, "styles", []types.StylePreset(nil), func(styles *[]types.StylePreset) error {
+		for _, preset := range *styles {
+			if strings.TrimSpace(preset.Name) == "" || strings.TrimSpace(preset.Instruction) == "" {
+				return errors.New("every style preset needs a name and an instruction")
+			}
+		}
+		return nil
+	})
+}
+
+// LoadFallback returns the providers listed in the "fallback" key of
+// config.json, which may be tried when the chosen provider fails with a
+// transient error. None are tried unless listed, since falling back sends
+// the changes to another service.
+func LoadFallback() ([]types.LLMProvider, error) {
+	path, err := StoreUtils.GetConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	return LoadFallbackFile(path)
+}
+
+// LoadFallbackFile is like LoadFallback but reads the config at path.
+func LoadFallbackFile(path string) ([]types.LLMProvider, error) {
+	var providers []types.LLMProvider
+	_, err := loadSection(path, "fallback", []string(nil), func(names *[]string) error {
+		for _, name := range *names {
+			provider, ok := types.ParseLLMProvider(name)
+			if !ok {
+				return fmt.Errorf("unknown provider %q", name)
+			}
+			providers = append(providers, provider)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return providers, nil
+}
+
+// LoadOllama returns the Ollama generation options from the "ollama" section
+// of config.json. A missing file or section yields the zero Options, which
+// leaves every setting at the model's default.
+func LoadOllama() (ollama.Options, error) {
+	path, err := StoreUtils.GetConfigPath()
+	if err != nil {
+		return ollama.Options{}, err
+	}
+	return LoadOllamaFile(path)
+}
+
+// LoadOllamaFile is like LoadOllama but reads the config at path.
+func LoadOllamaFile(path string) (ollama.Options, error) {
+	return loadSection(path, "ollama", ollama.Options{}, func(opts *ollama.Options) error {
+		if opts.NumCtx < 0 {
+			return fmt.Errorf("num_ctx %d must not be negative", opts.NumCtx)
+		}
+		return nil
+	})
+}
+
+// LoadHuggingFace returns the Hugging Face settings from the "huggingface"
+// section of config.json. A missing file or section yields the zero Options,
+// which uses the serverless router.
+func LoadHuggingFace() (huggingface.Options, error) {
+	path, err := StoreUtils.GetConfigPath()
+	if err != nil {
+		return huggingface.Options{}, err
+	}
+	return LoadHuggingFaceFile(path)
+}
+
+// LoadHuggingFaceFile is like LoadHuggingFace but reads the config at path.
+func LoadHuggingFaceFile(path string) (huggingface.Options, error) {
+	return loadSection(path, "huggingface", huggingface.Options{}, func(opts *huggingface.Options) error {
+		return opts.Validate()
+	})
+}
+
+// LoadVertex returns the Vertex AI settings from the "vertex" section of
+// config.json. A missing file or section yields the zero Options, which
+// leaves the project and location to the environment and the credentials.
+func LoadVertex() (vertex.Options, error) {
+	path, err := StoreUtils.GetConfigPath()
+	if err != nil {
+		return vertex.Options{}, err
+	}
+	return LoadVertexFile(path)
+}
+
+// LoadVertexFile is like LoadVertex but reads the config at path.
+func LoadVertexFile(path string) (vertex.Options, error) {
+	return loadSection(path, "vertex", vertex.Options{}, func(opts *vertex.Options) error {
+		return opts.Validate()
+	})
+}
+
+// LoadHTTPSettings returns the HTTP client settings from the "http" section
+// of config.json, overridden by the COMMIT_HTTP_* environment variables.
+// Unset values are left zero so the http package applies its defaults.
+func LoadHTTPSettings() (httpClient.Settings, error) {
+	path, err := StoreUtils.GetConfigPath()
+	if err != nil {
+		return httpClient.Settings{}, err
+	}
+	return LoadHTTPSettingsFile(path)
+}
+
+// LoadHTTPSettingsFile is like LoadHTTPSettings but reads the config at path.
+func LoadHTTPSettingsFile(path string) (httpClient.Settings, error) {
+	section, err := loadSection(path, "http", httpFile{}, nil)
+	if err != nil {
+		return httpClient.Settings{}, err
+	}
+
+	overrideString(&section.Timeout, HTTPTimeoutEnv)
+	overrideString(&section.IdleConnTimeout, HTTPIdleConnTimeoutEnv)
+	overrideString(&section.KeepAlive, HTTPKeepAliveEnv)
+	if value := strings.TrimSpace(os.Getenv(OllamaTimeoutEnv)); value != "" {
+		if section.ProviderTimeouts == nil {
+			section.ProviderTimeouts = make(map[string]string)
+		}
+		section.ProviderTimeouts[string(types.ProviderOllama)] = value
+	}
+	if value := strings.TrimSpace(os.Getenv(HTTPMaxIdleConnsEnv)); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return httpClient.Settings{}, fmt.Errorf("invalid %s %q: %w", HTTPMaxIdleConnsEnv, value, err)
+		}
+		section.MaxIdleConns = n
+	}
+
+	settings := httpClient.Settings{MaxIdleConns: section.MaxIdleConns}
+	for _, d := range []struct {
+		name  string
+		value string
+		dst   *time.Duration
+	}{
+		{"timeout", section.Timeout, &settings.Timeout},
+		{"idle_conn_timeout", section.IdleConnTimeout, &settings.IdleConnTimeout},
+		{"keep_alive", section.KeepAlive, &settings.KeepAlive},
+	} {
+		if err := parseDuration(d.name, d.value, d.dst); err != nil {
+			return httpClient.Settings{}, err
+		}
+	}
+
+	for name, value := range section.ProviderTimeouts {
+		provider, ok := types.ParseLLMProvider(name)
+		if !ok {
+			return httpClient.Settings{}, fmt.Errorf("unknown provider %q in http.provider_timeouts", name)
+		}
+		var timeout time.Duration
+		if err := parseDuration("provider_timeouts."+name, value, &timeout); err != nil {
+			return httpClient.Settings{}, err
+		}
+		if settings.ProviderTimeouts == nil {
+			settings.ProviderTimeouts = make(map[types.LLMProvider]time.Duration)
+		}
+		settings.ProviderTimeouts[provider] = timeout
+	}
+
+	for name, value := range section.PinnedCertificates {
+		provider, ok := types.ParseLLMProvider(name)
+		if !ok {
+			return httpClient.Settings{}, fmt.Errorf("unknown provider %q in http.pinned_certificates", name)
+		}
+		fingerprint, err := httpClient.NormalizeFingerprint(value)
+		if err != nil {
+			return httpClient.Settings{}, fmt.Errorf("http.pinned_certificates.%s: %w", name, err)
+		}
+		if settings.PinnedCertificates == nil {
+			settings.PinnedCertificates = make(map[types.LLMProvider]string)
+		}
+		settings.PinnedCertificates[provider] = fingerprint
+	}
+	return settings, nil
+}
+
+// ValidateFile reads every section of the config at path and returns the
+// problems found, joined. A missing or empty file is valid.
+func ValidateFile(path string) error {
+	if _, err := readSections(path); err != nil {
+		return err
+	}
+
+	var errs []error
+	check := func(err error) {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	_, err := LoadLimitsFile(path)
+	check(err)
+	_, err = LoadPromptTemplateFile(path)
+	check(err)
+	_, err = LoadProviderConfigFile(path)
+	check(err)
+	_, err = LoadPostProcessFile(path)
+	check(err)
+	_, err = LoadLintFile(path)
+	check(err)
+	_, err = LoadHistoryFile(path)
+	check(err)
+	_, err = LoadCacheFile(path)
+	check(err)
+	_, err = LoadStorageFile(path)
+	check(err)
+	_, err = LoadSpellcheckFile(path)
+	check(err)
+	_, err = LoadBlocklistFile(path)
+	check(err)
+	_, err = LoadGeneratedFile(path)
+	check(err)
+	_, err = LoadProjectContextFile(path)
+	check(err)
+	_, err = LoadRedactionFile(path)
+	check(err)
+	_, err = LoadTelemetryFile(path)
+	check(err)
+	_, err = LoadStylesFile(path)
+	check(err)
+	_, err = LoadFallbackFile(path)
+	check(err)
+	_, err = LoadOllamaFile(path)
+	check(err)
+	_, err = LoadHuggingFaceFile(path)
+	check(err)
+	_, err = LoadVertexFile(path)
+	check(err)
+	_, err = LoadHTTPSettingsFile(path)
+	check(err)
+	return errors.Join(errs...)
+}
+
+func overrideString(dst *string, env string) {
+	if value := strings.TrimSpace(os.Getenv(env)); value != "" {
+		*dst = value
+	}
+}
+
+func parseDuration(name, value string, dst *time.Duration) error {
+	if value == "" {
+		return nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return fmt.Errorf("invalid http.%s %q: %w", name, value, err)
+	}
+	*dst = d
+	return nil
+}
@@ -0,0 +1,767 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dfanso/commit-msg/internal/lint"
+	"github.com/dfanso/commit-msg/internal/postprocess"
+	"github.com/dfanso/commit-msg/pkg/types"
+)
+
+func TestLoadLimitsFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	defaults := types.ContentLimits{}.WithDefaults()
+
+	t.Run("missing file uses defaults", func(t *testing.T) {
+		t.Parallel()
+
+		got, err := LoadLimitsFile(filepath.Join(dir, "missing.json"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(got, defaults) {
+			t.Fatalf("got %+v, want %+v", got, defaults)
+		}
+	})
+
+	t.Run("partial limits keep other defaults", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(dir, "partial.json")
+		data := `{"default":"OpenAI","models":["OpenAI"],"limits":{"max_file_bytes":2048,"max_untracked_files":5}}`
+		if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+
+		got, err := LoadLimitsFile(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := types.ContentLimits{
+			MaxFileBytes:         2048,
+			MaxTotalPromptBytes:  types.DefaultMaxTotalPromptBytes,
+			MaxUntrackedFiles:    5,
+			RecentCommits:        types.DefaultRecentCommits,
+			MaxUntrackedBytes:    types.DefaultMaxUntrackedBytes,
+			MaxUntrackedContents: types.DefaultMaxUntrackedContents,
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("got %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("recent commits can be disabled", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(dir, "history.json")
+		if err := os.WriteFile(path, []byte(`{"limits":{"recent_commits":-1,"recent_commit_bodies":true}}`), 0o600); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+
+		got, err := LoadLimitsFile(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got.RecentCommits != -1 || !got.RecentCommitBodies {
+			t.Fatalf("got %+v, want recent commits disabled with bodies", got)
+		}
+	})
+
+	t.Run("invalid json reports error", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(dir, "invalid.json")
+		if err := os.WriteFile(path, []byte("{not json"), 0o600); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+
+		got, err := LoadLimitsFile(path)
+		if err == nil {
+			t.Fatal("expected error")
+		}
+		if !reflect.DeepEqual(got, defaults) {
+			t.Fatalf("got %+v, want defaults on error", got)
+		}
+	})
+
+	t.Run("untracked guards", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(dir, "untracked.json")
+		data := `{"limits":{"max_untracked_bytes":4096,"untracked_read_timeout":"2s","untracked_ignore":["*.log","fixtures/"]}}`
+		if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+
+		got, err := LoadLimitsFile(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got.MaxUntrackedBytes != 4096 || got.MaxUntrackedContents != types.DefaultMaxUntrackedContents ||
+			got.UntrackedTimeout() != 2*time.Second || !reflect.DeepEqual(got.UntrackedIgnore, []string{"*.log", "fixtures/"}) {
+			t.Fatalf("got %+v", got)
+		}
+
+		path = filepath.Join(dir, "bad-timeout.json")
+		if err := os.WriteFile(path, []byte(`{"limits":{"untracked_read_timeout":"soon"}}`), 0o600); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+		got, err = LoadLimitsFile(path)
+		if err == nil {
+			t.Fatal("expected an error for an invalid timeout")
+		}
+		if got.UntrackedTimeout() != types.DefaultUntrackedReadTimeout {
+			t.Fatalf("timeout = %s, want the default", got.UntrackedTimeout())
+		}
+	})
+}
+
+func TestLoadPromptTemplateFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeFile := func(name, content string) string {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+		return path
+	}
+
+	writeFile("team.tmpl", "Branch {{.Branch}}\n{{.Changes}}")
+	writeFile("broken.tmpl", "{{.Missing}}")
+
+	tests := []struct {
+		name    string
+		config  string
+		want    string
+		wantErr bool
+	}{
+		{name: "no override", config: `{"default":"OpenAI"}`, want: ""},
+		{name: "relative path", config: `{"prompt_template":"team.tmpl"}`, want: "Branch {{.Branch}}\n{{.Changes}}"},
+		{name: "missing file", config: `{"prompt_template":"nope.tmpl"}`, wantErr: true},
+		{name: "invalid template", config: `{"prompt_template":"broken.tmpl"}`, wantErr: true},
+	}
+
+	for i, tt := range tests {
+		path := writeFile(fmt.Sprintf("config-%d.json", i), tt.config)
+
+		got, err := LoadPromptTemplateFile(path)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("%s: error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Fatalf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLoadProviderConfigFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		config  string
+		want    types.Config
+		wantErr bool
+	}{
+		{name: "missing file", config: ""},
+		{name: "no override", config: `{"default":"Grok"}`},
+		{
+			name:   "gateways",
+			config: `{"openai_api":"http://localhost:4000/v1","claude_api":"https://gateway.example.com/anthropic/v1/messages","gemini_api":"https://gateway.example.com/gemini/v1beta","grok_api":" https://gateway.example.com/v1/chat/completions "}`,
+			want: types.Config{
+				OpenAIAPI: "http://localhost:4000/v1",
+				ClaudeAPI: "https://gateway.example.com/anthropic/v1/messages",
+				GeminiAPI: "https://gateway.example.com/gemini/v1beta",
+				GrokAPI:   "https://gateway.example.com/v1/chat/completions",
+			},
+		},
+		{name: "not a URL", config: `{"grok_api":"api.x.ai"}`, wantErr: true},
+		{name: "wrong scheme", config: `{"claude_api":"ftp://api.anthropic.com/v1/messages"}`, wantErr: true},
+	}
+
+	for i, tt := range tests {
+		path := filepath.Join(dir, fmt.Sprintf("config-%d.json", i))
+		if tt.config != "" {
+			if err := os.WriteFile(path, []byte(tt.config), 0o600); err != nil {
+				t.Fatalf("failed to write config: %v", err)
+			}
+		}
+
+		got, err := LoadProviderConfigFile(path)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("%s: error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+		if got.OpenAIAPI != tt.want.OpenAIAPI || got.ClaudeAPI != tt.want.ClaudeAPI || got.GeminiAPI != tt.want.GeminiAPI || got.GrokAPI != tt.want.GrokAPI {
+			t.Fatalf("%s: got %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLoadPostProcessFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte(`{"postprocess":{"imperative_mood":false}}`), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	got, err := LoadPostProcessFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := postprocess.DefaultOptions()
+	want.ImperativeMood = false
+	if got != want {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+
+	got, err = LoadPostProcessFile(filepath.Join(dir, "missing.json"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != postprocess.DefaultOptions() {
+		t.Fatalf("got %+v, want defaults", got)
+	}
+}
+
+func TestLoadLintFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte(`{"lint":{"max_subject_length":50,"types":["feat","fix"]}}`), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	got, err := LoadLintFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := lint.DefaultRules()
+	if got.MaxSubjectLength != 50 || got.MaxBodyLineLength != want.MaxBodyLineLength || !got.ImperativeMood {
+		t.Fatalf("got %+v, want defaults with a 50 character subject", got)
+	}
+	if len(got.Types) != 2 || got.Types[1] != "fix" {
+		t.Fatalf("types = %v, want [feat fix]", got.Types)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"lint":{"max_subject_length":-1}}`), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if _, err := LoadLintFile(path); err == nil {
+		t.Fatal("expected an error for a negative length")
+	}
+}
+
+func TestLoadHistoryFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+
+	got, err := LoadHistoryFile(path)
+	if err != nil || got.Disabled || got.RecordRejected || got.MaxEntries != 0 {
+		t.Fatalf("LoadHistoryFile() without a config = %+v, %v", got, err)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"history":{"record_rejected":true,"max_entries":100}}`), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	got, err = LoadHistoryFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !got.RecordRejected || got.MaxEntries != 100 {
+		t.Fatalf("got %+v, want rejected messages recorded and 100 entries kept", got)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"history":{"max_entries":-1}}`), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if _, err := LoadHistoryFile(path); err == nil {
+		t.Fatal("expected an error for a negative max_entries")
+	}
+}
+
+func TestLoadSpellcheckFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+
+	got, err := LoadSpellcheckFile(path)
+	if err != nil || got.Enabled || len(got.Terms) != 0 {
+		t.Fatalf("LoadSpellcheckFile() without a config = %+v, %v", got, err)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"spellcheck":{"enabled":true,"terms":["Acme Cloud"]}}`), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	got, err = LoadSpellcheckFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !got.Enabled || len(got.Terms) != 1 || got.Terms[0] != "Acme Cloud" {
+		t.Fatalf("got %+v, want the dictionary enabled with one term", got)
+	}
+}
+
+func TestLoadBlocklistFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+
+	got, err := LoadBlocklistFile(path)
+	if err != nil || len(got.Phrases) != 0 {
+		t.Fatalf("LoadBlocklistFile() without a config = %+v, %v", got, err)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"blocklist":{"phrases":["Project Falcon"]}}`), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	got, err = LoadBlocklistFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got.Phrases) != 1 || got.Phrases[0] != "Project Falcon" {
+		t.Fatalf("got %+v, want one blocked phrase", got)
+	}
+}
+
+func TestLoadGeneratedFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+
+	got, err := LoadGeneratedFile(path)
+	if err != nil || len(got.Patterns) != 0 {
+		t.Fatalf("LoadGeneratedFile() without a config = %+v, %v", got, err)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"generated":{"patterns":["api/*.ts","vendor/"]}}`), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	got, err = LoadGeneratedFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got.Patterns) != 2 || got.Patterns[1] != "vendor/" {
+		t.Fatalf("got %+v, want two patterns", got)
+	}
+}
+
+func TestLoadProjectContextFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+
+	got, err := LoadProjectContextFile(path)
+	if err != nil || len(got.Files) != 0 {
+		t.Fatalf("LoadProjectContextFile() without a config = %+v, %v", got, err)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"project_context":{"files":["README.md","docs/ARCHITECTURE.md"],"max_lines":15}}`), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	got, err = LoadProjectContextFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got.Files) != 2 || got.Files[1] != "docs/ARCHITECTURE.md" || got.MaxLines != 15 {
+		t.Fatalf("got %+v, want two files and 15 lines", got)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"project_context":{"files":["README.md"],"max_lines":-1}}`), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if _, err := LoadProjectContextFile(path); err == nil {
+		t.Fatal("expected an error for a negative max_lines")
+	}
+}
+
+func TestLoadRedactionFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+
+	got, err := LoadRedactionFile(path)
+	if err != nil || got.Placeholder != "" {
+		t.Fatalf("LoadRedactionFile() without a config = %+v, %v", got, err)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"redaction":{"placeholder":"[SECRET:{{hash}}]"}}`), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	got, err = LoadRedactionFile(path)
+	if err != nil || got.Placeholder != "[SECRET:{{hash}}]" {
+		t.Fatalf("LoadRedactionFile() = %+v, %v", got, err)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"redaction":{"placeholder":"SECRET"}}`), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if _, err := LoadRedactionFile(path); err == nil {
+		t.Fatal("expected an error for a placeholder without punctuation")
+	}
+}
+
+func TestLoadStylesFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte(`{"styles":[{"name":"jira","instruction":"Prefix the subject with {{ticket}}:"}]}`), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	got, err := LoadStylesFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 1 || got[0].Name != "jira" || got[0].Instruction != "Prefix the subject with {{ticket}}:" {
+		t.Fatalf("got %+v, want the jira preset", got)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"styles":[{"name":"empty"}]}`), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if _, err := LoadStylesFile(path); err == nil {
+		t.Fatal("expected an error for a preset without an instruction")
+	}
+}
+
+func TestLoadFallbackFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+
+	got, err := LoadFallbackFile(path)
+	if err != nil || got != nil {
+		t.Fatalf("LoadFallbackFile() without a config = %v, %v, want no fallback", got, err)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"fallback":["Claude","Ollama"]}`), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	got, err = LoadFallbackFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []types.LLMProvider{types.ProviderClaude, types.ProviderOllama}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"fallback":["Claude","Bard"]}`), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if got, err := LoadFallbackFile(path); err == nil || got != nil {
+		t.Fatalf("LoadFallbackFile() = %v, %v, want an error for an unknown provider", got, err)
+	}
+}
+
+func TestLoadOllamaFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte(`{"ollama":{"temperature":0.1,"num_ctx":16384,"keep_alive":"30m"}}`), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	got, err := LoadOllamaFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Temperature == nil || *got.Temperature != 0.1 || got.NumCtx != 16384 || got.KeepAlive != "30m" {
+		t.Fatalf("unexpected options %+v", got)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"ollama":{"num_ctx":-1}}`), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if _, err := LoadOllamaFile(path); err == nil {
+		t.Fatal("expected error for negative num_ctx")
+	}
+
+	got, err = LoadOllamaFile(filepath.Join(dir, "missing.json"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Temperature != nil || got.NumCtx != 0 || got.KeepAlive != "" {
+		t.Fatalf("expected zero options, got %+v", got)
+	}
+}
+
+func TestLoadHuggingFaceFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte(`{"huggingface":{"endpoint":"https://abc.endpoints.huggingface.cloud","api":"text-generation"}}`), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	got, err := LoadHuggingFaceFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Endpoint != "https://abc.endpoints.huggingface.cloud" || got.API != "text-generation" {
+		t.Fatalf("unexpected options %+v", got)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"huggingface":{"api":"completions"}}`), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if _, err := LoadHuggingFaceFile(path); err == nil {
+		t.Fatal("expected error for unknown api")
+	}
+}
+
+func TestLoadVertexFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte(`{"vertex":{"project":"acme-ml","location":"europe-west4"}}`), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	got, err := LoadVertexFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Project != "acme-ml" || got.Location != "europe-west4" {
+		t.Fatalf("unexpected options %+v", got)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"vertex":{"location":"Europe West"}}`), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if _, err := LoadVertexFile(path); err == nil {
+		t.Fatal("expected error for invalid location")
+	}
+}
+
+func TestLoadHTTPSettingsFile(t *testing.T) {
+	t.Setenv(HTTPTimeoutEnv, "")
+	t.Setenv(HTTPMaxIdleConnsEnv, "")
+	t.Setenv(HTTPIdleConnTimeoutEnv, "")
+	t.Setenv(HTTPKeepAliveEnv, "")
+	t.Setenv(OllamaTimeoutEnv, "")
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	data := `{"http":{"timeout":"45s","max_idle_conns":20,"provider_timeouts":{"Claude":"2m"}}}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	got, err := LoadHTTPSettingsFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Timeout != 45*time.Second || got.MaxIdleConns != 20 || got.ProviderTimeouts[types.ProviderClaude] != 2*time.Minute {
+		t.Fatalf("unexpected settings: %+v", got)
+	}
+
+	t.Setenv(HTTPTimeoutEnv, "1m")
+	t.Setenv(OllamaTimeoutEnv, "20m")
+	got, err = LoadHTTPSettingsFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Timeout != time.Minute || got.ProviderTimeouts[types.ProviderOllama] != 20*time.Minute {
+		t.Fatalf("environment overrides not applied: %+v", got)
+	}
+
+	t.Setenv(HTTPTimeoutEnv, "soon")
+	if _, err := LoadHTTPSettingsFile(path); err == nil {
+		t.Fatal("expected an error for an invalid duration")
+	}
+	t.Setenv(HTTPTimeoutEnv, "")
+
+	fingerprint := strings.TrimSuffix(strings.Repeat("AB:", 32), ":")
+	data = `{"http":{"pinned_certificates":{"Ollama":"` + fingerprint + `"}}}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	got, err = LoadHTTPSettingsFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.PinnedCertificates[types.ProviderOllama] != strings.Repeat("ab", 32) {
+		t.Fatalf("pinned certificate not normalized: %+v", got.PinnedCertificates)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"http":{"pinned_certificates":{"Ollama":"abcd"}}}`), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if _, err := LoadHTTPSettingsFile(path); err == nil {
+		t.Fatal("expected an error for a malformed fingerprint")
+	}
+}
+
+func TestLoadCacheFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+
+	got, err := LoadCacheFile(path)
+	if err != nil || got.ShareAcrossRepos {
+		t.Fatalf("LoadCacheFile() without a config = %+v, %v", got, err)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"cache":{"share_across_repos":true}}`), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	got, err = LoadCacheFile(path)
+	if err != nil || !got.ShareAcrossRepos {
+		t.Fatalf("LoadCacheFile() = %+v, %v, want cached messages shared", got, err)
+	}
+}
+
+func TestLoadStorageFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+
+	got, err := LoadStorageFile(path)
+	if err != nil || got.UseSQLite() {
+		t.Fatalf("LoadStorageFile() without a config = %+v, %v", got, err)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"storage":{"backend":"sqlite"}}`), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	got, err = LoadStorageFile(path)
+	if err != nil || !got.UseSQLite() {
+		t.Fatalf("LoadStorageFile() = %+v, %v, want the sqlite backend", got, err)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"storage":{"backend":"redis"}}`), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if _, err := LoadStorageFile(path); err == nil {
+		t.Fatal("expected an error for an unknown backend")
+	}
+}
+
+func TestLoadTelemetryFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+
+	got, err := LoadTelemetryFile(path)
+	if err != nil || got.Enabled {
+		t.Fatalf("LoadTelemetryFile() without a config = %+v, %v, want telemetry off", got, err)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"telemetry":{"enabled":true}}`), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	got, err = LoadTelemetryFile(path)
+	if err != nil || !got.Enabled {
+		t.Fatalf("LoadTelemetryFile() = %+v, %v, want telemetry on", got, err)
+	}
+}
+
+func TestLoadSection(t *testing.T) {
+	t.Parallel()
+
+	type section struct {
+		Name  string `json:"name"`
+		Count int    `json:"count"`
+	}
+	defaults := section{Name: "default", Count: 1}
+	positive := func(s *section) error {
+		if s.Count <= 0 {
+			return errors.New("count must be positive")
+		}
+		return nil
+	}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	got, err := loadSection(path, "demo", defaults, positive)
+	if err != nil || got != defaults {
+		t.Fatalf("loadSection() without a config = %+v, %v", got, err)
+	}
+
+	// A broken neighbouring section does not affect this one.
+	if err := os.WriteFile(path, []byte(`{"demo":{"count":3},"other":{"count":"three"},"null":null}`), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	got, err = loadSection(path, "demo", defaults, positive)
+	if err != nil || got != (section{Name: "default", Count: 3}) {
+		t.Fatalf("loadSection() = %+v, %v, want the count decoded over the defaults", got, err)
+	}
+	if got, err := loadSection(path, "null", defaults, positive); err != nil || got != defaults {
+		t.Fatalf("loadSection() of a null section = %+v, %v, want the defaults", got, err)
+	}
+	if got, err := loadSection(path, "other", defaults, positive); err == nil || got != defaults {
+		t.Fatalf("loadSection() of a malformed section = %+v, %v, want the defaults and an error", got, err)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"demo":{"count":0}}`), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	got, err = loadSection(path, "demo", defaults, positive)
+	if err == nil || !strings.Contains(err.Error(), `"demo"`) || !strings.Contains(err.Error(), path) || got != defaults {
+		t.Fatalf("loadSection() = %+v, %v, want the defaults and an error naming the section and file", got, err)
+	}
+}
+
+func TestValidateFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+
+	if err := ValidateFile(path); err != nil {
+		t.Fatalf("ValidateFile() without a config = %v", err)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"default":"OpenAI","history":{"max_entries":100},"telemetry":{"enabled":true}}`), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if err := ValidateFile(path); err != nil {
+		t.Fatalf("ValidateFile() = %v, want a valid config", err)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"history":{"max_entries":-1},"storage":{"backend":"redis"}}`), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	err := ValidateFile(path)
+	if err == nil || !strings.Contains(err.Error(), "max_entries") || !strings.Contains(err.Error(), "redis") {
+		t.Fatalf("ValidateFile() = %v, want both invalid sections reported", err)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"history":`), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if err := ValidateFile(path); err == nil || strings.Count(err.Error(), "failed to parse") != 1 {
+		t.Fatalf("ValidateFile() = %v, want a single parse error", err)
+	}
+}
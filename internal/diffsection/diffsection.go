@@ -0,0 +1,87 @@
+// Package diffsection splits the changes collected for a prompt into the
+// per-file sections of their unified diffs, so individual files can be
+// summarized in place.
+package diffsection
+
+import "strings"
+
+// ReplaceFunc returns the lines that stand in for the diff lines of the file
+// at path, or false to keep them. The "diff --git" header is always kept.
+type ReplaceFunc func(path string, lines []string) ([]string, bool)
+
+// Replace returns changes with the diff of every file passed through
+// replace. Text outside the diffs is kept as it is.
+func Replace(changes string, replace ReplaceFunc) string {
+	lines := strings.Split(changes, "\n")
+	out := make([]string, 0, len(lines))
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		out = append(out, line)
+		if !strings.HasPrefix(line, "diff --git ") {
+			continue
+		}
+		end := i + 1
+		for end < len(lines) && isDiffLine(lines[end]) {
+			end++
+		}
+		if replacement, ok := replace(Path(line), lines[i+1:end]); ok {
+			out = append(out, replacement...)
+			i = end - 1
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+// Path returns the new path of a "diff --git a/x b/x" header.
+func Path(header string) string {
+	if idx := strings.LastIndex(header, " b/"); idx >= 0 {
+		return header[idx+len(" b/"):]
+	}
+	return ""
+}
+
+// IsChange reports whether a diff line adds or removes a line of the file,
+// as opposed to a context or header line.
+func IsChange(line string) bool {
+	return (strings.HasPrefix(line, "+") && !strings.HasPrefix(line, "+++")) ||
+		(strings.HasPrefix(line, "-") && !strings.HasPrefix(line, "---"))
+}
+
+// Count returns how many lines a diff adds and removes.
+func Count(lines []string) (added, removed int) {
+	for _, line := range lines {
+		if !IsChange(line) {
+			continue
+		}
+		if line[0] == '+' {
+			added++
+		} else {
+			removed++
+		}
+	}
+	return added, removed
+}
+
+// extendedHeaders are the header lines git writes before a file's hunks.
+var extendedHeaders = []string{
+	"index ", "new file mode", "deleted file mode", "old mode", "new mode",
+	"similarity index", "dissimilarity index", "rename from", "rename to",
+	"copy from", "copy to", "Binary files",
+}
+
+// isDiffLine reports whether line belongs to the diff of the current file.
+func isDiffLine(line string) bool {
+	if line == "" {
+		return false
+	}
+	switch line[0] {
+	case '+', '-', ' ', '@', '\\':
+		return true
+	}
+	for _, header := range extendedHeaders {
+		if strings.HasPrefix(line, header) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,39 @@
+package diffsection
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReplace(t *testing.T) {
+	t.Parallel()
+
+	changes := "Staged diff content:\n" +
+		"diff --git a/a.txt b/a.txt\nindex 1..2 100644\n--- a/a.txt\n+++ b/a.txt\n@@ -1 +1 @@\n-old\n+new\n" +
+		"diff --git a/b.txt b/b.txt\n@@ -1 +1 @@\n-x\n+y\n" +
+		"\nUntracked files:\n- c.txt"
+
+	var paths []string
+	got := Replace(changes, func(path string, lines []string) ([]string, bool) {
+		paths = append(paths, path)
+		if path != "a.txt" {
+			return nil, false
+		}
+		added, removed := Count(lines)
+		if added != 1 || removed != 1 {
+			t.Errorf("Count(a.txt) = +%d -%d, want +1 -1", added, removed)
+		}
+		return []string{"summary of a.txt"}, true
+	})
+
+	want := "Staged diff content:\n" +
+		"diff --git a/a.txt b/a.txt\nsummary of a.txt\n" +
+		"diff --git a/b.txt b/b.txt\n@@ -1 +1 @@\n-x\n+y\n" +
+		"\nUntracked files:\n- c.txt"
+	if got != want {
+		t.Fatalf("Replace() =\n%s\nwant\n%s", got, want)
+	}
+	if strings.Join(paths, ",") != "a.txt,b.txt" {
+		t.Fatalf("paths = %q, want a.txt and b.txt", paths)
+	}
+}
@@ -2,7 +2,11 @@ package display
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/dfanso/commit-msg/internal/scrubber"
+	"github.com/dfanso/commit-msg/pkg/types"
 	"github.com/pterm/pterm"
 )
 
@@ -12,6 +16,17 @@ const (
 	MaxUntrackedFiles = 3
 )
 
+// LanguageBarWidth is the width, in cells, of the longest language bar.
+const LanguageBarWidth = 24
+
+// LanguageStat aggregates changed files and lines for one language.
+type LanguageStat struct {
+	Name    string
+	Files   int
+	Added   int
+	Deleted int
+}
+
 // FileStatistics holds statistics about changed files
 type FileStatistics struct {
 	StagedFiles    []string
@@ -20,6 +35,8 @@ type FileStatistics struct {
 	TotalFiles     int
 	LinesAdded     int
 	LinesDeleted   int
+	// Languages breaks the changes down by language, largest first.
+	Languages []LanguageStat
 }
 
 // ShowFileStatistics displays file statistics with colored output
@@ -40,7 +57,7 @@ func ShowFileStatistics(stats *FileStatistics) {
 			if i < MaxStagedFiles { // Show first 5 files
 				bulletItems = append(bulletItems, pterm.BulletListItem{
 					Level: 1,
-					Text:  file,
+					Text:  scrubber.ScrubDiff(file),
 				})
 			}
 		}
@@ -63,7 +80,7 @@ func ShowFileStatistics(stats *FileStatistics) {
 			if i < MaxUnstagedFiles {
 				bulletItems = append(bulletItems, pterm.BulletListItem{
 					Level: 1,
-					Text:  file,
+					Text:  scrubber.ScrubDiff(file),
 				})
 			}
 		}
@@ -86,7 +103,7 @@ func ShowFileStatistics(stats *FileStatistics) {
 			if i < MaxUntrackedFiles {
 				bulletItems = append(bulletItems, pterm.BulletListItem{
 					Level: 1,
-					Text:  file,
+					Text:  scrubber.ScrubDiff(file),
 				})
 			}
 		}
@@ -99,6 +116,50 @@ func ShowFileStatistics(stats *FileStatistics) {
 	}
 
 	pterm.DefaultBulletList.WithItems(bulletItems).Render()
+
+	if len(stats.Languages) > 0 {
+		ShowLanguageBreakdown(stats.Languages)
+	}
+}
+
+// ShowLanguageBreakdown renders a bar per language scaled by changed lines.
+func ShowLanguageBreakdown(languages []LanguageStat) {
+	pterm.DefaultSection.WithLevel(2).Println("By Language")
+
+	for _, line := range LanguageBars(languages) {
+		pterm.Println(line)
+	}
+}
+
+// LanguageBars formats one line per language: name, a bar proportional to
+// the changed lines, and the line counts.
+func LanguageBars(languages []LanguageStat) []string {
+	maxChurn := 0
+	nameWidth := 0
+	for _, lang := range languages {
+		maxChurn = max(maxChurn, lang.Added+lang.Deleted)
+		nameWidth = max(nameWidth, len(lang.Name))
+	}
+
+	lines := make([]string, 0, len(languages))
+	for _, lang := range languages {
+		churn := lang.Added + lang.Deleted
+		width := 1
+		if maxChurn > 0 {
+			width = max(1, churn*LanguageBarWidth/maxChurn)
+		}
+		bar := strings.Repeat("█", width) + strings.Repeat("░", LanguageBarWidth-width)
+
+		files := "files"
+		if lang.Files == 1 {
+			files = "file"
+		}
+		lines = append(lines, fmt.Sprintf("%-*s %s %s %s (%d %s)",
+			nameWidth, lang.Name, pterm.Cyan(bar),
+			pterm.Green(fmt.Sprintf("+%d", lang.Added)), pterm.Red(fmt.Sprintf("-%d", lang.Deleted)),
+			lang.Files, files))
+	}
+	return lines
 }
 
 // ShowCommitMessage displays the commit message in a styled panel
@@ -120,6 +181,130 @@ func ShowCommitMessage(message string) {
 	panel.Println(pterm.LightGreen(message))
 }
 
+// ShowMessageDiff displays the line changes between two commit messages,
+// removals in red and additions in green.
+func ShowMessageDiff(oldMessage, newMessage string) {
+	pterm.DefaultSection.Println("Commit Message Changes")
+	for _, line := range MessageDiff(oldMessage, newMessage) {
+		switch line[0] {
+		case '-':
+			pterm.Println(pterm.Red(line))
+		case '+':
+			pterm.Println(pterm.Green(line))
+		default:
+			pterm.Println(pterm.Gray(line))
+		}
+	}
+}
+
+// ShowCommitConfirmation displays the message about to be committed exactly
+// as it will be passed to the VCS, after the changes post-processing made to
+// the reviewed message.
+func ShowCommitConfirmation(reviewed, final string) {
+	pterm.DefaultSection.Println("Message to Commit")
+	if strings.TrimSpace(reviewed) != strings.TrimSpace(final) {
+		pterm.Info.Println("Changed since review:")
+		for _, line := range MessageDiff(reviewed, final) {
+			switch line[0] {
+			case '-':
+				pterm.Println(pterm.Red(line))
+			case '+':
+				pterm.Println(pterm.Green(line))
+			default:
+				pterm.Println(pterm.Gray(line))
+			}
+		}
+		pterm.Println()
+	}
+	lines := ExactLines(final)
+	for i, line := range lines {
+		pterm.Println(pterm.Gray(fmt.Sprintf("%3d │ ", i+1)) + line)
+	}
+	pterm.Println(pterm.Gray(fmt.Sprintf("%d bytes, %d lines", len(final), len(lines))))
+}
+
+// ExactLines splits message into lines with the whitespace that is hard to
+// see made visible: tabs as →, carriage returns as ␍, and trailing spaces
+// as ·.
+func ExactLines(message string) []string {
+	lines := strings.Split(message, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimRight(line, " ")
+		line = trimmed + strings.Repeat("·", len(line)-len(trimmed))
+		lines[i] = strings.NewReplacer("\t", "→", "\r", "␍").Replace(line)
+	}
+	return lines
+}
+
+// MessageDiff returns a line diff of two messages, each line prefixed with
+// "- " when removed, "+ " when added, or "  " when unchanged.
+func MessageDiff(oldMessage, newMessage string) []string {
+	a := strings.Split(strings.TrimSpace(oldMessage), "\n")
+	b := strings.Split(strings.TrimSpace(newMessage), "\n")
+
+	// lcs[i][j] is the longest common subsequence of a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	lines := make([]string, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, "  "+a[i])
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, "- "+a[i])
+			i++
+		default:
+			lines = append(lines, "+ "+b[j])
+			j++
+		}
+	}
+	return lines
+}
+
+// GenerationSummary describes how a message was generated in one line, for
+// example "Ollama llama3.1, 2.4s, 812 tokens" or "OpenAI gpt-4o, cached".
+func GenerationSummary(result *types.GenerationResult) string {
+	if result == nil {
+		return ""
+	}
+
+	name := result.Provider.String()
+	if result.Model != "" {
+		name += " " + result.Model
+	}
+	parts := []string{strings.TrimSpace(name)}
+	if result.CacheHit {
+		parts = append(parts, "cached")
+	} else if result.Duration > 0 {
+		parts = append(parts, result.Duration.Round(100*time.Millisecond).String())
+	}
+	if result.Tokens != nil && result.Tokens.TotalTokens > 0 {
+		parts = append(parts, fmt.Sprintf("%d tokens", result.Tokens.TotalTokens))
+	}
+	if result.Cost > 0 && !result.CacheHit {
+		parts = append(parts, fmt.Sprintf("$%.4f", result.Cost))
+	}
+	if len(result.ScrubFindings) > 0 {
+		parts = append(parts, fmt.Sprintf("%d secret types redacted", len(result.ScrubFindings)))
+	}
+	return strings.Join(parts, ", ")
+}
+
 // ShowChangesPreview displays a preview of changes with line statistics
 func ShowChangesPreview(stats *FileStatistics) {
 	pterm.DefaultSection.Println("Changes Preview")
@@ -2,7 +2,11 @@ package display
 
 import (
 	"fmt"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/dfanso/commit-msg/pkg/types"
 )
 
 func TestShowFileStatistics(t *testing.T) {
@@ -213,3 +217,103 @@ func TestFileStatisticsStruct(t *testing.T) {
 		t.Fatalf("expected 5 lines deleted, got %d", stats.LinesDeleted)
 	}
 }
+
+func TestLanguageBars(t *testing.T) {
+	t.Parallel()
+
+	lines := LanguageBars([]LanguageStat{
+		{Name: "Go", Files: 3, Added: 90, Deleted: 10},
+		{Name: "YAML", Files: 1, Added: 1, Deleted: 0},
+	})
+
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d", len(lines))
+	}
+
+	full := strings.Repeat("█", LanguageBarWidth)
+	if !strings.Contains(lines[0], full) {
+		t.Fatalf("expected largest language to get a full bar, got %q", lines[0])
+	}
+	if !strings.Contains(lines[0], "(3 files)") {
+		t.Fatalf("expected file count in %q", lines[0])
+	}
+
+	if !strings.Contains(lines[1], "█"+strings.Repeat("░", LanguageBarWidth-1)) {
+		t.Fatalf("expected small language to get a minimal bar, got %q", lines[1])
+	}
+	if !strings.HasPrefix(lines[1], "YAML") || !strings.Contains(lines[1], "(1 file)") {
+		t.Fatalf("unexpected line %q", lines[1])
+	}
+}
+
+func TestGenerationSummary(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		result *types.GenerationResult
+		want   string
+	}{
+		{
+			name: "fresh",
+			result: &types.GenerationResult{
+				Provider: types.ProviderClaude,
+				Model:    "claude-3-5-sonnet",
+				Duration: 2430 * time.Millisecond,
+				Tokens:   &types.UsageInfo{TotalTokens: 812},
+				Cost:     0.0031,
+			},
+			want: "Claude claude-3-5-sonnet, 2.4s, 812 tokens, $0.0031",
+		},
+		{
+			name: "cached",
+			result: &types.GenerationResult{
+				Provider:      types.ProviderOpenAI,
+				Model:         "gpt-4o",
+				Cost:          0.01,
+				CacheHit:      true,
+				ScrubFindings: []string{"OPENAI_KEY"},
+			},
+			want: "OpenAI gpt-4o, cached, 1 secret types redacted",
+		},
+		{
+			name: "nil",
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GenerationSummary(tt.result); got != tt.want {
+				t.Fatalf("GenerationSummary() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMessageDiff(t *testing.T) {
+	t.Parallel()
+
+	got := MessageDiff("fixed stuff\n\nDetails here.", "Fix parser crash\n\nDetails here.\nCloses #12")
+	want := []string{"- fixed stuff", "+ Fix parser crash", "  ", "  Details here.", "+ Closes #12"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("MessageDiff() = %q, want %q", got, want)
+	}
+}
+
+func TestExactLines(t *testing.T) {
+	t.Parallel()
+
+	got := ExactLines("feat: add\tparser  \r\n\nSigned-off-by: A <a@example.com>")
+	want := []string{"feat: add→parser  ␍", "", "Signed-off-by: A <a@example.com>"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("ExactLines() = %q, want %q", got, want)
+	}
+
+	if got := ExactLines("fix: typo  "); got[0] != "fix: typo··" {
+		t.Fatalf("expected trailing spaces to be marked, got %q", got[0])
+	}
+
+	// Just test that the function doesn't panic
+	ShowCommitConfirmation("fix: typo", "fix: typo\n\nSigned-off-by: A <a@example.com>")
+}
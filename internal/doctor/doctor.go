@@ -0,0 +1,90 @@
+// Package doctor collects the environment checks shown by commit doctor,
+// so bug reports carry the version, tools, paths, and provider state that
+// explain most problems.
+package doctor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/dfanso/commit-msg/internal/utils"
+)
+
+// Status is the outcome of a check.
+type Status string
+
+const (
+	OK   Status = "ok"
+	Warn Status = "warn"
+	Fail Status = "fail"
+)
+
+// Check is one line of the report.
+type Check struct {
+	Name   string `json:"name"`
+	Status Status `json:"status"`
+	Detail string `json:"detail"`
+}
+
+// Report is the list of checks in the order they ran.
+type Report struct {
+	Checks []Check `json:"checks"`
+}
+
+// Add appends a check.
+func (r *Report) Add(name string, status Status, detail string) {
+	r.Checks = append(r.Checks, Check{Name: name, Status: status, Detail: detail})
+}
+
+// Count returns how many checks ended with status.
+func (r *Report) Count(status Status) int {
+	n := 0
+	for _, c := range r.Checks {
+		if c.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// GitVersion returns the version of the git found on PATH, such as
+// "2.43.0".
+func GitVersion(ctx context.Context) (string, error) {
+	out, err := exec.CommandContext(ctx, "git", "--version").Output()
+	if err != nil {
+		return "", fmt.Errorf("git not found: %w", err)
+	}
+	return parseGitVersion(string(out)), nil
+}
+
+// parseGitVersion extracts the version from the output of git --version,
+// which looks like "git version 2.39.3 (Apple Git-146)".
+func parseGitVersion(out string) string {
+	fields := strings.Fields(out)
+	for i, field := range fields {
+		if field == "version" && i+1 < len(fields) {
+			return fields[i+1]
+		}
+	}
+	return strings.TrimSpace(out)
+}
+
+// JSONFile checks the JSON file at path that commit-msg keeps, such as the
+// config or the cache. A missing file is fine: it is created on first use.
+func JSONFile(name, path string) Check {
+	data, err := os.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+		return Check{Name: name, Status: OK, Detail: path + " (not created yet)"}
+	case err != nil:
+		return Check{Name: name, Status: Fail, Detail: fmt.Sprintf("%s: %v", path, err)}
+	}
+	if len(data) > 0 && !json.Valid(data) {
+		return Check{Name: name, Status: Fail, Detail: path + ": not valid JSON"}
+	}
+	return Check{Name: name, Status: OK, Detail: fmt.Sprintf("%s (%s)", path, utils.FormatBytes(int64(len(data))))}
+}
@@ -0,0 +1,74 @@
+package doctor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseGitVersion(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"git version 2.43.0\n":                 "2.43.0",
+		"git version 2.39.3 (Apple Git-146)\n": "2.39.3",
+		"git version 2.45.1.windows.1\n":       "2.45.1.windows.1",
+		"something unexpected\n":               "something unexpected",
+	}
+	for out, want := range tests {
+		if got := parseGitVersion(out); got != want {
+			t.Errorf("parseGitVersion(%q) = %q, want %q", out, got, want)
+		}
+	}
+}
+
+func TestGitVersion(t *testing.T) {
+	version, err := GitVersion(context.Background())
+	if err != nil {
+		t.Skipf("git not available: %v", err)
+	}
+	if version == "" || strings.HasPrefix(version, "git") {
+		t.Fatalf("unexpected git version %q", version)
+	}
+}
+
+func TestJSONFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "cache.json")
+
+	if c := JSONFile("Cache", path); c.Status != OK || !strings.Contains(c.Detail, "not created yet") {
+		t.Fatalf("missing file = %+v, want ok", c)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"entries":{}}`), 0o600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if c := JSONFile("Cache", path); c.Status != OK || !strings.Contains(c.Detail, path) {
+		t.Fatalf("valid file = %+v, want ok", c)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"entries":`), 0o600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if c := JSONFile("Cache", path); c.Status != Fail || c.Name != "Cache" {
+		t.Fatalf("truncated file = %+v, want fail", c)
+	}
+}
+
+func TestReportCount(t *testing.T) {
+	t.Parallel()
+
+	var r Report
+	r.Add("Version", OK, "v1.0.0")
+	r.Add("Clipboard", Warn, "no clipboard tool found")
+	r.Add("Config", Fail, "not valid JSON")
+	r.Add("Git", OK, "2.43.0")
+
+	if r.Count(OK) != 2 || r.Count(Warn) != 1 || r.Count(Fail) != 1 {
+		t.Fatalf("unexpected counts for %+v", r.Checks)
+	}
+}
@@ -0,0 +1,168 @@
+// Package examples remembers the commit messages the user accepted so the
+// best recent ones can be shown to the LLM as few-shot demonstrations.
+package examples
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	StoreUtils "github.com/dfanso/commit-msg/utils"
+)
+
+// FileName is the examples file stored alongside config.json.
+const FileName = "examples.json"
+
+const (
+	// MaxStored bounds how many accepted messages are kept.
+	MaxStored = 50
+	// DefaultCount is how many examples are added to the prompt.
+	DefaultCount = 3
+	// maxSubjectLength skips messages whose subject is too long to be a
+	// good example.
+	maxSubjectLength = 72
+)
+
+// Example is a commit message the user accepted.
+type Example struct {
+	Message    string    `json:"message"`
+	Repo       string    `json:"repo,omitempty"`
+	AcceptedAt time.Time `json:"accepted_at"`
+}
+
+// Store persists accepted messages in a JSON file.
+type Store struct {
+	path string
+}
+
+// NewStore returns a store backed by the file at path.
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+// DefaultPath returns the location of the examples file.
+func DefaultPath() (string, error) {
+	configPath, err := StoreUtils.GetConfigPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(configPath), FileName), nil
+}
+
+// Load returns every stored example, oldest first. A missing file yields no
+// examples.
+func (s *Store) Load() ([]Example, error) {
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read examples: %w", err)
+	}
+
+	var examples []Example
+	if err := json.Unmarshal(data, &examples); err != nil {
+		return nil, fmt.Errorf("failed to parse examples %s: %w", s.path, err)
+	}
+	return examples, nil
+}
+
+// Add records an accepted message for repo. Repeated messages are moved to
+// the end rather than stored twice, and only the newest MaxStored are kept.
+func (s *Store) Add(message, repo string) error {
+	message = strings.TrimSpace(message)
+	if message == "" {
+		return nil
+	}
+
+	examples, err := s.Load()
+	if err != nil {
+		return err
+	}
+
+	kept := examples[:0]
+	for _, example := range examples {
+		if example.Message != message {
+			kept = append(kept, example)
+		}
+	}
+	kept = append(kept, Example{Message: message, Repo: repo, AcceptedAt: time.Now().UTC()})
+	if len(kept) > MaxStored {
+		kept = kept[len(kept)-MaxStored:]
+	}
+
+	return s.save(kept)
+}
+
+// Select returns up to n of the most recent well-formed messages, preferring
+// those accepted in repo.
+func (s *Store) Select(repo string, n int) ([]string, error) {
+	examples, err := s.Load()
+	if err != nil || n <= 0 {
+		return nil, err
+	}
+
+	var sameRepo, otherRepos []string
+	for i := len(examples) - 1; i >= 0; i-- {
+		example := examples[i]
+		if !suitable(example.Message) {
+			continue
+		}
+		if repo != "" && example.Repo == repo {
+			sameRepo = append(sameRepo, example.Message)
+		} else {
+			otherRepos = append(otherRepos, example.Message)
+		}
+	}
+
+	selected := append(sameRepo, otherRepos...)
+	if len(selected) > n {
+		selected = selected[:n]
+	}
+	return selected, nil
+}
+
+// Reset removes every stored example.
+func (s *Store) Reset() error {
+	if err := os.Remove(s.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove examples: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) save(examples []Example) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return fmt.Errorf("failed to create examples directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(examples, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal examples: %w", err)
+	}
+
+	if err := os.WriteFile(s.path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write examples: %w", err)
+	}
+	return nil
+}
+
+// suitable filters out messages that would teach the LLM bad habits, such
+// as overlong subjects or autosquash and work-in-progress commits.
+func suitable(message string) bool {
+	subject, _, _ := strings.Cut(message, "\n")
+	subject = strings.TrimSpace(subject)
+	if subject == "" || len([]rune(subject)) > maxSubjectLength {
+		return false
+	}
+
+	lower := strings.ToLower(subject)
+	for _, prefix := range []string{"fixup!", "squash!", "wip"} {
+		if strings.HasPrefix(lower, prefix) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,97 @@
+package examples
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStoreAddAndSelect(t *testing.T) {
+	t.Parallel()
+
+	store := NewStore(filepath.Join(t.TempDir(), FileName))
+
+	for _, add := range []struct{ message, repo string }{
+		{"feat: add login", "/repo/a"},
+		{"fix: handle nil config", "/repo/b"},
+		{"WIP stuff", "/repo/a"},
+		{"fixup! feat: add login", "/repo/a"},
+		{strings.Repeat("x", maxSubjectLength+1), "/repo/a"},
+		{"docs: explain setup\n\nMore detail.", "/repo/a"},
+	} {
+		if err := store.Add(add.message, add.repo); err != nil {
+			t.Fatalf("Add(%q) error: %v", add.message, err)
+		}
+	}
+
+	got, err := store.Select("/repo/a", 3)
+	if err != nil {
+		t.Fatalf("Select error: %v", err)
+	}
+	want := []string{
+		"docs: explain setup\n\nMore detail.",
+		"feat: add login",
+		"fix: handle nil config",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Select() = %q, want %q", got, want)
+	}
+}
+
+func TestStoreAddDeduplicatesAndCaps(t *testing.T) {
+	t.Parallel()
+
+	store := NewStore(filepath.Join(t.TempDir(), FileName))
+
+	for i := 0; i < MaxStored+5; i++ {
+		if err := store.Add(fmt.Sprintf("chore: change %d", i), ""); err != nil {
+			t.Fatalf("Add error: %v", err)
+		}
+	}
+	if err := store.Add("chore: change 10", ""); err != nil {
+		t.Fatalf("Add error: %v", err)
+	}
+
+	examples, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+	if len(examples) != MaxStored {
+		t.Fatalf("stored %d examples, want %d", len(examples), MaxStored)
+	}
+	if last := examples[len(examples)-1].Message; last != "chore: change 10" {
+		t.Fatalf("last example = %q, want the re-added message", last)
+	}
+	count := 0
+	for _, example := range examples {
+		if example.Message == "chore: change 10" {
+			count++
+		}
+	}
+	if count != 1 {
+		t.Fatalf("re-added message stored %d times, want 1", count)
+	}
+}
+
+func TestStoreReset(t *testing.T) {
+	t.Parallel()
+
+	store := NewStore(filepath.Join(t.TempDir(), FileName))
+	if err := store.Reset(); err != nil {
+		t.Fatalf("Reset on missing file error: %v", err)
+	}
+
+	if err := store.Add("feat: add login", ""); err != nil {
+		t.Fatalf("Add error: %v", err)
+	}
+	if err := store.Reset(); err != nil {
+		t.Fatalf("Reset error: %v", err)
+	}
+
+	examples, err := store.Load()
+	if err != nil || len(examples) != 0 {
+		t.Fatalf("Load after reset = %v, %v; want none", examples, err)
+	}
+}
@@ -0,0 +1,252 @@
+// Package feedback records the generated commit messages users rate and
+// aggregates the ratings into a per-provider quality score.
+package feedback
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/dfanso/commit-msg/pkg/types"
+	StoreUtils "github.com/dfanso/commit-msg/utils"
+)
+
+// FileName is the feedback file stored alongside config.json.
+const FileName = "feedback.json"
+
+// maxRatings bounds how many rated generations are kept.
+const maxRatings = 500
+
+// ErrNoGeneration is returned by RateLast before any message was generated.
+var ErrNoGeneration = errors.New("no generated commit message to rate yet")
+
+// Rating is the user's verdict on a generated message.
+type Rating string
+
+const (
+	Good Rating = "good"
+	Bad  Rating = "bad"
+)
+
+// ParseRating converts "good" or "bad" into a Rating.
+func ParseRating(s string) (Rating, bool) {
+	switch Rating(s) {
+	case Good, Bad:
+		return Rating(s), true
+	}
+	return "", false
+}
+
+// Generation is one generated message and, once rated, its rating.
+type Generation struct {
+	Provider    types.LLMProvider `json:"provider"`
+	Model       string            `json:"model"`
+	PromptHash  string            `json:"prompt_hash"`
+	Message     string            `json:"message"`
+	GeneratedAt time.Time         `json:"generated_at"`
+	Rating      Rating            `json:"rating,omitempty"`
+	RatedAt     time.Time         `json:"rated_at,omitempty"`
+}
+
+// ProviderQuality aggregates the ratings of one provider.
+type ProviderQuality struct {
+	Provider    types.LLMProvider
+	Generations int
+	Good        int
+	Bad         int
+}
+
+// Score is the share of good ratings, smoothed so that providers with few
+// ratings sit near 0.5 instead of at the extremes.
+func (q ProviderQuality) Score() float64 {
+	return float64(q.Good+1) / float64(q.Good+q.Bad+2)
+}
+
+// data is the on-disk format.
+type data struct {
+	Last        *Generation               `json:"last,omitempty"`
+	Ratings     []Generation              `json:"ratings"`
+	Generations map[types.LLMProvider]int `json:"generations"`
+}
+
+// Log persists generations and ratings in a JSON file.
+type Log struct {
+	path string
+}
+
+// NewLog returns a log backed by the file at path.
+func NewLog(path string) *Log {
+	return &Log{path: path}
+}
+
+// DefaultPath returns the location of the feedback file.
+func DefaultPath() (string, error) {
+	configPath, err := StoreUtils.GetConfigPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(configPath), FileName), nil
+}
+
+// PromptHash identifies the prompt a message was generated from.
+func PromptHash(prompt string) string {
+	sum := sha256.Sum256([]byte(prompt))
+	return hex.EncodeToString(sum[:8])
+}
+
+// RecordGeneration remembers g as the message the next rating applies to.
+func (l *Log) RecordGeneration(g Generation) error {
+	d, err := l.load()
+	if err != nil {
+		return err
+	}
+	if g.GeneratedAt.IsZero() {
+		g.GeneratedAt = time.Now().UTC()
+	}
+	g.Rating = ""
+	d.Last = &g
+	d.Generations[g.Provider]++
+	return l.save(d)
+}
+
+// RateLast rates the most recently generated message. Rating it again
+// replaces the earlier rating.
+func (l *Log) RateLast(rating Rating) (*Generation, error) {
+	d, err := l.load()
+	if err != nil {
+		return nil, err
+	}
+	if d.Last == nil {
+		return nil, ErrNoGeneration
+	}
+
+	if d.Last.Rating != "" && len(d.Ratings) > 0 {
+		// Replace the previous rating of the same generation.
+		d.Ratings = d.Ratings[:len(d.Ratings)-1]
+	}
+	d.Last.Rating = rating
+	d.Last.RatedAt = time.Now().UTC()
+	d.Ratings = append(d.Ratings, *d.Last)
+	if len(d.Ratings) > maxRatings {
+		d.Ratings = d.Ratings[len(d.Ratings)-maxRatings:]
+	}
+
+	if err := l.save(d); err != nil {
+		return nil, err
+	}
+	return d.Last, nil
+}
+
+// Summary returns the quality of every provider that generated or had a
+// message rated, best first.
+func (l *Log) Summary() ([]ProviderQuality, error) {
+	d, err := l.load()
+	if err != nil {
+		return nil, err
+	}
+
+	byProvider := make(map[types.LLMProvider]*ProviderQuality)
+	get := func(p types.LLMProvider) *ProviderQuality {
+		if q, ok := byProvider[p]; ok {
+			return q
+		}
+		q := &ProviderQuality{Provider: p}
+		byProvider[p] = q
+		return q
+	}
+
+	for provider, count := range d.Generations {
+		get(provider).Generations = count
+	}
+	for _, rated := range d.Ratings {
+		q := get(rated.Provider)
+		switch rated.Rating {
+		case Good:
+			q.Good++
+		case Bad:
+			q.Bad++
+		}
+	}
+
+	qualities := make([]ProviderQuality, 0, len(byProvider))
+	for _, q := range byProvider {
+		qualities = append(qualities, *q)
+	}
+	sortByQuality(qualities)
+	return qualities, nil
+}
+
+// Rank orders providers by quality, best first, for choosing which provider
+// to try first. Unrated providers count as neutral, and ties keep the order
+// they were given in.
+func Rank(providers []types.LLMProvider, qualities []ProviderQuality) []types.LLMProvider {
+	scores := make(map[types.LLMProvider]float64, len(qualities))
+	for _, q := range qualities {
+		scores[q.Provider] = q.Score()
+	}
+
+	ranked := append([]types.LLMProvider(nil), providers...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return score(scores, ranked[i]) > score(scores, ranked[j])
+	})
+	return ranked
+}
+
+func score(scores map[types.LLMProvider]float64, p types.LLMProvider) float64 {
+	if s, ok := scores[p]; ok {
+		return s
+	}
+	return 0.5
+}
+
+func sortByQuality(qualities []ProviderQuality) {
+	sort.Slice(qualities, func(i, j int) bool {
+		si, sj := qualities[i].Score(), qualities[j].Score()
+		if si != sj {
+			return si > sj
+		}
+		return qualities[i].Provider < qualities[j].Provider
+	})
+}
+
+func (l *Log) load() (*data, error) {
+	d := &data{}
+
+	raw, err := os.ReadFile(l.path)
+	switch {
+	case os.IsNotExist(err):
+	case err != nil:
+		return nil, fmt.Errorf("failed to read feedback: %w", err)
+	default:
+		if err := json.Unmarshal(raw, d); err != nil {
+			return nil, fmt.Errorf("failed to parse feedback %s: %w", l.path, err)
+		}
+	}
+
+	if d.Generations == nil {
+		d.Generations = make(map[types.LLMProvider]int)
+	}
+	return d, nil
+}
+
+func (l *Log) save(d *data) error {
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o700); err != nil {
+		return fmt.Errorf("failed to create feedback directory: %w", err)
+	}
+
+	raw, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal feedback: %w", err)
+	}
+
+	if err := os.WriteFile(l.path, raw, 0o600); err != nil {
+		return fmt.Errorf("failed to write feedback: %w", err)
+	}
+	return nil
+}
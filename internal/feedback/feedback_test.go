@@ -0,0 +1,104 @@
+package feedback
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/dfanso/commit-msg/pkg/types"
+)
+
+func TestRateLast(t *testing.T) {
+	t.Parallel()
+
+	log := NewLog(filepath.Join(t.TempDir(), FileName))
+
+	if _, err := log.RateLast(Good); !errors.Is(err, ErrNoGeneration) {
+		t.Fatalf("RateLast without generation error = %v, want ErrNoGeneration", err)
+	}
+
+	gen := Generation{Provider: types.ProviderOpenAI, Model: "gpt-4o", PromptHash: PromptHash("prompt"), Message: "feat: add login"}
+	if err := log.RecordGeneration(gen); err != nil {
+		t.Fatalf("RecordGeneration error: %v", err)
+	}
+
+	rated, err := log.RateLast(Good)
+	if err != nil {
+		t.Fatalf("RateLast error: %v", err)
+	}
+	if rated.Message != gen.Message || rated.Rating != Good {
+		t.Fatalf("unexpected rated generation %+v", rated)
+	}
+
+	// Changing one's mind replaces the rating instead of adding another.
+	if _, err := log.RateLast(Bad); err != nil {
+		t.Fatalf("RateLast error: %v", err)
+	}
+
+	summary, err := log.Summary()
+	if err != nil {
+		t.Fatalf("Summary error: %v", err)
+	}
+	want := []ProviderQuality{{Provider: types.ProviderOpenAI, Generations: 1, Good: 0, Bad: 1}}
+	if !reflect.DeepEqual(summary, want) {
+		t.Fatalf("Summary() = %+v, want %+v", summary, want)
+	}
+}
+
+func TestSummaryAndRank(t *testing.T) {
+	t.Parallel()
+
+	log := NewLog(filepath.Join(t.TempDir(), FileName))
+	rate := func(provider types.LLMProvider, rating Rating) {
+		t.Helper()
+		if err := log.RecordGeneration(Generation{Provider: provider, Message: "msg"}); err != nil {
+			t.Fatalf("RecordGeneration error: %v", err)
+		}
+		if _, err := log.RateLast(rating); err != nil {
+			t.Fatalf("RateLast error: %v", err)
+		}
+	}
+
+	rate(types.ProviderClaude, Good)
+	rate(types.ProviderClaude, Good)
+	rate(types.ProviderGemini, Bad)
+	rate(types.ProviderGemini, Good)
+	rate(types.ProviderGroq, Bad)
+	if err := log.RecordGeneration(Generation{Provider: types.ProviderGroq}); err != nil {
+		t.Fatalf("RecordGeneration error: %v", err)
+	}
+
+	summary, err := log.Summary()
+	if err != nil {
+		t.Fatalf("Summary error: %v", err)
+	}
+	var order []types.LLMProvider
+	for _, q := range summary {
+		order = append(order, q.Provider)
+	}
+	wantOrder := []types.LLMProvider{types.ProviderClaude, types.ProviderGemini, types.ProviderGroq}
+	if !reflect.DeepEqual(order, wantOrder) {
+		t.Fatalf("summary order = %v, want %v", order, wantOrder)
+	}
+	if summary[2].Generations != 2 {
+		t.Fatalf("Groq generations = %d, want 2", summary[2].Generations)
+	}
+
+	ranked := Rank([]types.LLMProvider{types.ProviderGroq, types.ProviderOpenAI, types.ProviderClaude}, summary)
+	wantRank := []types.LLMProvider{types.ProviderClaude, types.ProviderOpenAI, types.ProviderGroq}
+	if !reflect.DeepEqual(ranked, wantRank) {
+		t.Fatalf("Rank() = %v, want %v", ranked, wantRank)
+	}
+}
+
+func TestParseRating(t *testing.T) {
+	t.Parallel()
+
+	if r, ok := ParseRating("good"); !ok || r != Good {
+		t.Fatalf("ParseRating(good) = %q, %v", r, ok)
+	}
+	if _, ok := ParseRating("meh"); ok {
+		t.Fatal("ParseRating(meh) should fail")
+	}
+}
@@ -1,51 +1,232 @@
 package gemini
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
 
-	"github.com/google/generative-ai-go/genai"
-	"google.golang.org/api/option"
-
+	httpClient "github.com/dfanso/commit-msg/internal/http"
+	"github.com/dfanso/commit-msg/internal/llmerr"
 	"github.com/dfanso/commit-msg/pkg/types"
 )
 
 const (
-	geminiModel       = "gemini-2.0-flash"
+	// DefaultModel is the Gemini model used to generate commit messages.
+	DefaultModel = "gemini-2.0-flash"
+	// DefaultAPIBase is the root of Google's Gemini API.
+	DefaultAPIBase    = "https://generativelanguage.googleapis.com/v1beta"
 	geminiTemperature = 0.2
+	geminiContentType = "application/json"
 )
 
+// modelAliases maps the short names accepted in GEMINI_MODEL to full model
+// identifiers.
+var modelAliases = map[string]string{
+	"flash": "gemini-2.0-flash",
+	"pro":   "gemini-2.5-pro",
+}
+
+// safetyCategories lists the harm categories a threshold from
+// GEMINI_SAFETY_THRESHOLD is applied to.
+var safetyCategories = []string{
+	"HARM_CATEGORY_HARASSMENT",
+	"HARM_CATEGORY_HATE_SPEECH",
+	"HARM_CATEGORY_SEXUALLY_EXPLICIT",
+	"HARM_CATEGORY_DANGEROUS_CONTENT",
+}
+
+// safetyThresholds maps the short names accepted in GEMINI_SAFETY_THRESHOLD to
+// API threshold values.
+var safetyThresholds = map[string]string{
+	"none":   "BLOCK_NONE",
+	"high":   "BLOCK_ONLY_HIGH",
+	"medium": "BLOCK_MEDIUM_AND_ABOVE",
+	"low":    "BLOCK_LOW_AND_ABOVE",
+}
+
+type part struct {
+	Text string `json:"text"`
+}
+
+type content struct {
+	Role  string `json:"role,omitempty"`
+	Parts []part `json:"parts"`
+}
+
+type safetySetting struct {
+	Category  string `json:"category"`
+	Threshold string `json:"threshold"`
+}
+
+type generationConfig struct {
+	Temperature float64 `json:"temperature"`
+}
+
+type generateRequest struct {
+	Contents         []content        `json:"contents"`
+	SafetySettings   []safetySetting  `json:"safetySettings,omitempty"`
+	GenerationConfig generationConfig `json:"generationConfig"`
+}
+
+type generateResponse struct {
+	Candidates []struct {
+		Content      content `json:"content"`
+		FinishReason string  `json:"finishReason"`
+	} `json:"candidates"`
+	PromptFeedback struct {
+		BlockReason string `json:"blockReason"`
+	} `json:"promptFeedback"`
+}
+
+// ResolveModel returns the model requested via GEMINI_MODEL, expanding the
+// "flash" and "pro" aliases, or DefaultModel when unset.
+func ResolveModel() string {
+	model := strings.TrimSpace(os.Getenv("GEMINI_MODEL"))
+	if model == "" {
+		return DefaultModel
+	}
+	if full, ok := modelAliases[strings.ToLower(model)]; ok {
+		return full
+	}
+	return model
+}
+
+// safetySettingsFromEnv returns the safety settings requested via
+// GEMINI_SAFETY_THRESHOLD, or nil to use the API defaults. The threshold may
+// be a short name (none, high, medium, low) or an API value such as
+// BLOCK_ONLY_HIGH.
+func safetySettingsFromEnv() ([]safetySetting, error) {
+	value := strings.TrimSpace(os.Getenv("GEMINI_SAFETY_THRESHOLD"))
+	if value == "" {
+		return nil, nil
+	}
+
+	threshold, ok := safetyThresholds[strings.ToLower(value)]
+	if !ok {
+		threshold = strings.ToUpper(value)
+		if !strings.HasPrefix(threshold, "BLOCK_") {
+			return nil, fmt.Errorf("invalid GEMINI_SAFETY_THRESHOLD %q: use none, high, medium, or low", value)
+		}
+	}
+
+	settings := make([]safetySetting, len(safetyCategories))
+	for i, category := range safetyCategories {
+		settings[i] = safetySetting{Category: category, Threshold: threshold}
+	}
+	return settings, nil
+}
+
+// APIBase returns the API root: GEMINI_API_URL, then the gemini_api setting
+// carried in config, then DefaultAPIBase.
+func APIBase(config *types.Config) string {
+	if custom := strings.TrimSpace(os.Getenv("GEMINI_API_URL")); custom != "" {
+		return strings.TrimRight(custom, "/")
+	}
+	if config != nil && strings.TrimSpace(config.GeminiAPI) != "" {
+		return strings.TrimRight(strings.TrimSpace(config.GeminiAPI), "/")
+	}
+	return DefaultAPIBase
+}
+
 // GenerateCommitMessage asks Google Gemini to author a commit message for the
 // supplied repository changes and optional style instructions.
-func GenerateCommitMessage(config *types.Config, changes string, apiKey string, opts *types.GenerationOptions) (string, error) {
-	// Prepare request to Gemini API
-	prompt := types.BuildCommitPrompt(changes, opts)
+func GenerateCommitMessage(ctx context.Context, config *types.Config, changes string, apiKey string, opts *types.GenerationOptions) (string, error) {
+	return generate(ctx, httpClient.ClientFor(types.ProviderGemini), APIBase(config), changes, apiKey, opts)
+}
+
+func generate(ctx context.Context, client *http.Client, baseURL, changes, apiKey string, opts *types.GenerationOptions) (string, error) {
+	if strings.TrimSpace(apiKey) == "" {
+		return "", errors.New("gemini: API key is required")
+	}
+	if changes == "" {
+		return "", fmt.Errorf("no changes provided for commit message generation")
+	}
+
+	endpoint := fmt.Sprintf("%s/models/%s:generateContent", strings.TrimRight(baseURL, "/"), url.PathEscape(ResolveModel()))
+	header := http.Header{}
+	header.Set("x-goog-api-key", apiKey)
+	return GenerateContent(ctx, client, types.ProviderGemini, endpoint, header, changes, opts)
+}
+
+// GenerateContent posts a commit message request to a generateContent
+// endpoint, which the Gemini API and Vertex AI share, sending header for
+// authentication. Failures are reported as coming from provider.
+func GenerateContent(ctx context.Context, client *http.Client, provider types.LLMProvider, endpoint string, header http.Header, changes string, opts *types.GenerationOptions) (string, error) {
+	safety, err := safetySettingsFromEnv()
+	if err != nil {
+		return "", err
+	}
+
+	request := generateRequest{
+		Contents: []content{
+			{
+				Role:  "user",
+				Parts: []part{{Text: types.BuildCommitPrompt(changes, opts)}},
+			},
+		},
+		SafetySettings:   safety,
+		GenerationConfig: generationConfig{Temperature: opts.TemperatureOr(geminiTemperature)},
+	}
 
-	// Create context and client
-	ctx := context.Background()
-	client, err := genai.NewClient(ctx, option.WithAPIKey(apiKey))
+	requestBody, err := json.Marshal(request)
 	if err != nil {
 		return "", err
 	}
-	defer client.Close()
 
-	// Create a GenerativeModel with appropriate settings
-	model := client.GenerativeModel(geminiModel)
-	model.SetTemperature(geminiTemperature) // Lower temperature for more focused responses
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(requestBody))
+	if err != nil {
+		return "", err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Content-Type", geminiContentType)
 
-	// Generate content using the prompt
-	resp, err := model.GenerateContent(ctx, genai.Text(prompt))
+	resp, err := client.Do(req)
 	if err != nil {
 		return "", err
 	}
+	defer resp.Body.Close()
 
-	// Check if we got a valid response
-	if len(resp.Candidates) == 0 || len(resp.Candidates[0].Content.Parts) == 0 {
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", llmerr.FromResponse(provider, resp.StatusCode, resp.Header, body)
+	}
+
+	var response generateResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return "", fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	if reason := response.PromptFeedback.BlockReason; reason != "" {
+		return "", fmt.Errorf("gemini blocked the prompt: %s", reason)
+	}
+	if len(response.Candidates) == 0 {
 		return "", fmt.Errorf("no response generated")
 	}
 
-	// Extract the commit message from the response
-	commitMsg := fmt.Sprintf("%v", resp.Candidates[0].Content.Parts[0])
+	candidate := response.Candidates[0]
+	var text strings.Builder
+	for _, p := range candidate.Content.Parts {
+		text.WriteString(p.Text)
+	}
+	if text.Len() == 0 {
+		if candidate.FinishReason == "SAFETY" {
+			return "", fmt.Errorf("gemini blocked the response for safety; set GEMINI_SAFETY_THRESHOLD to relax the filter")
+		}
+		return "", fmt.Errorf("no response generated")
+	}
 
-	return commitMsg, nil
+	return text.String(), nil
 }
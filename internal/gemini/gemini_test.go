@@ -1,18 +1,59 @@
 package gemini
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	httpClient "github.com/dfanso/commit-msg/internal/http"
+	"github.com/dfanso/commit-msg/internal/llmerr"
+	"github.com/dfanso/commit-msg/internal/vcr"
 	"github.com/dfanso/commit-msg/pkg/types"
 )
 
+// newTestServer answers every request with status and reply, and returns a
+// config pointing the client at it and the last request it received.
+func newTestServer(t *testing.T, status int, reply string) (*types.Config, *generateRequest) {
+	t.Helper()
+
+	received := &generateRequest{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("x-goog-api-key"); got != "test-key" {
+			t.Errorf("x-goog-api-key = %q", got)
+		}
+		if err := json.NewDecoder(r.Body).Decode(received); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(reply))
+	}))
+	t.Cleanup(server.Close)
+	return &types.Config{GeminiAPI: server.URL + "/v1beta"}, received
+}
+
+const okReply = `{"candidates":[{"content":{"parts":[{"text":"feat: add new feature"}],"role":"model"},"finishReason":"STOP"}]}`
+
+// promptOf returns the prompt text of a received request.
+func promptOf(req *generateRequest) string {
+	if len(req.Contents) == 0 || len(req.Contents[0].Parts) == 0 {
+		return ""
+	}
+	return req.Contents[0].Parts[0].Text
+}
+
 func TestGenerateCommitMessage(t *testing.T) {
 	t.Parallel()
 
 	t.Run("returns error for empty API key", func(t *testing.T) {
 		t.Parallel()
 
-		_, err := GenerateCommitMessage(&types.Config{}, "some changes", "", nil)
+		_, err := GenerateCommitMessage(context.Background(), &types.Config{}, "some changes", "", nil)
 		if err == nil {
 			t.Fatal("expected error for empty API key")
 		}
@@ -21,7 +62,7 @@ func TestGenerateCommitMessage(t *testing.T) {
 	t.Run("returns error for empty changes", func(t *testing.T) {
 		t.Parallel()
 
-		_, err := GenerateCommitMessage(&types.Config{}, "", "test-key", nil)
+		_, err := GenerateCommitMessage(context.Background(), &types.Config{}, "", "test-key", nil)
 		if err == nil {
 			t.Fatal("expected error for empty changes")
 		}
@@ -30,9 +71,11 @@ func TestGenerateCommitMessage(t *testing.T) {
 	t.Run("returns error for invalid API key", func(t *testing.T) {
 		t.Parallel()
 
-		_, err := GenerateCommitMessage(&types.Config{}, "some changes", "invalid-key", nil)
-		if err == nil {
-			t.Fatal("expected error for invalid API key")
+		config, _ := newTestServer(t, http.StatusBadRequest, `{"error":{"code":400,"message":"API key not valid. Please pass a valid API key.","status":"INVALID_ARGUMENT"}}`)
+		_, err := GenerateCommitMessage(context.Background(), config, "some changes", "test-key", nil)
+		var apiErr *llmerr.Error
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest {
+			t.Fatalf("expected a classified 400 error, got %v", err)
 		}
 	})
 }
@@ -43,40 +86,48 @@ func TestGenerateCommitMessageWithOptions(t *testing.T) {
 	t.Run("includes style instructions in prompt", func(t *testing.T) {
 		t.Parallel()
 
+		config, received := newTestServer(t, http.StatusOK, okReply)
 		opts := &types.GenerationOptions{
 			StyleInstruction: "Use a casual tone",
 			Attempt:          2,
 		}
 
-		// Test with invalid key to verify the function processes the options
-		_, err := GenerateCommitMessage(&types.Config{}, "some changes", "invalid-key", opts)
-		if err == nil {
-			t.Fatal("expected error for invalid API key")
+		if _, err := GenerateCommitMessage(context.Background(), config, "some changes", "test-key", opts); err != nil {
+			t.Fatalf("GenerateCommitMessage: %v", err)
+		}
+		if !strings.Contains(promptOf(received), "Use a casual tone") {
+			t.Fatalf("expected the style instruction in the prompt, got %q", promptOf(received))
 		}
 	})
 
 	t.Run("handles nil options", func(t *testing.T) {
 		t.Parallel()
 
-		// Test with invalid key and nil options
-		_, err := GenerateCommitMessage(&types.Config{}, "some changes", "invalid-key", nil)
-		if err == nil {
-			t.Fatal("expected error for invalid API key")
+		config, received := newTestServer(t, http.StatusOK, okReply)
+		message, err := GenerateCommitMessage(context.Background(), config, "some changes", "test-key", nil)
+		if err != nil {
+			t.Fatalf("GenerateCommitMessage: %v", err)
+		}
+		if message != "feat: add new feature" {
+			t.Fatalf("unexpected message %q", message)
+		}
+		if received.GenerationConfig.Temperature != geminiTemperature {
+			t.Fatalf("expected the default temperature, got %v", received.GenerationConfig.Temperature)
 		}
 	})
 
-	t.Run("handles empty style instruction", func(t *testing.T) {
+	t.Run("uses the requested temperature", func(t *testing.T) {
 		t.Parallel()
 
-		opts := &types.GenerationOptions{
-			StyleInstruction: "",
-			Attempt:          1,
-		}
+		config, received := newTestServer(t, http.StatusOK, okReply)
+		temperature := 0.9
+		opts := &types.GenerationOptions{Temperature: &temperature}
 
-		// Test with invalid key to verify the function handles empty style instruction
-		_, err := GenerateCommitMessage(&types.Config{}, "some changes", "invalid-key", opts)
-		if err == nil {
-			t.Fatal("expected error for invalid API key")
+		if _, err := GenerateCommitMessage(context.Background(), config, "some changes", "test-key", opts); err != nil {
+			t.Fatalf("GenerateCommitMessage: %v", err)
+		}
+		if received.GenerationConfig.Temperature != 0.9 {
+			t.Fatalf("expected temperature 0.9, got %v", received.GenerationConfig.Temperature)
 		}
 	})
 }
@@ -84,34 +135,32 @@ func TestGenerateCommitMessageWithOptions(t *testing.T) {
 func TestGenerateCommitMessageWithLongChanges(t *testing.T) {
 	t.Parallel()
 
-	// Create a very long changes string
-	longChanges := "This is a test change. "
-	for i := 0; i < 100; i++ {
-		longChanges += "Additional line of changes. "
-	}
+	longChanges := "This is a test change. " + strings.Repeat("Additional line of changes. ", 100)
 
-	// Test with invalid key to verify the function handles long changes
-	_, err := GenerateCommitMessage(&types.Config{}, longChanges, "invalid-key", nil)
-	if err == nil {
-		t.Fatal("expected error for invalid API key")
+	config, received := newTestServer(t, http.StatusOK, okReply)
+	if _, err := GenerateCommitMessage(context.Background(), config, longChanges, "test-key", nil); err != nil {
+		t.Fatalf("GenerateCommitMessage: %v", err)
+	}
+	if !strings.Contains(promptOf(received), longChanges) {
+		t.Fatal("expected the whole change set in the prompt")
 	}
 }
 
 func TestGenerateCommitMessageWithContextCancellation(t *testing.T) {
 	t.Parallel()
 
-	// This test would require modifying the function to accept context
-	// For now, we'll test the basic functionality
-	_, err := GenerateCommitMessage(&types.Config{}, "some changes", "invalid-key", nil)
-	if err == nil {
-		t.Fatal("expected error for invalid API key")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := GenerateCommitMessage(ctx, &types.Config{}, "some changes", "invalid-key", nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
 	}
 }
 
 func TestGenerateCommitMessageWithSpecialCharacters(t *testing.T) {
 	t.Parallel()
 
-	// Test with special characters in changes
 	changes := `Added special characters: !@#$%^&*()_+-=[]{}|;':",./<>?
 Also added unicode: ñáéíóú 🚀 🎉
 And newlines:
@@ -119,58 +168,150 @@ Line 1
 Line 2
 Line 3`
 
-	// Test with invalid key to verify the function handles special characters
-	_, err := GenerateCommitMessage(&types.Config{}, changes, "invalid-key", nil)
-	if err == nil {
-		t.Fatal("expected error for invalid API key")
+	config, received := newTestServer(t, http.StatusOK, okReply)
+	if _, err := GenerateCommitMessage(context.Background(), config, changes, "test-key", nil); err != nil {
+		t.Fatalf("GenerateCommitMessage: %v", err)
+	}
+	if !strings.Contains(promptOf(received), changes) {
+		t.Fatalf("expected the changes to survive encoding, got %q", promptOf(received))
 	}
 }
 
-func TestGenerateCommitMessageWithConfig(t *testing.T) {
+func TestGenerateCommitMessageWithMultipleAttempts(t *testing.T) {
 	t.Parallel()
 
-	config := &types.Config{
-		// Add any config fields that might be relevant
+	config, received := newTestServer(t, http.StatusOK, okReply)
+	opts := &types.GenerationOptions{
+		StyleInstruction: "Use a formal tone",
+		Attempt:          3,
 	}
 
-	// Test with invalid key to verify the function uses config
-	_, err := GenerateCommitMessage(config, "some changes", "invalid-key", nil)
-	if err == nil {
-		t.Fatal("expected error for invalid API key")
+	if _, err := GenerateCommitMessage(context.Background(), config, "some changes", "test-key", opts); err != nil {
+		t.Fatalf("GenerateCommitMessage: %v", err)
+	}
+	if prompt := promptOf(received); !strings.Contains(prompt, "Use a formal tone") || prompt == types.BuildCommitPrompt("some changes", nil) {
+		t.Fatalf("expected the attempt's options in the prompt, got %q", prompt)
 	}
 }
 
-func TestGenerateCommitMessageWithMultipleAttempts(t *testing.T) {
-	t.Parallel()
+func TestAPIBase(t *testing.T) {
+	t.Setenv("GEMINI_API_URL", "")
 
-	opts := &types.GenerationOptions{
-		StyleInstruction: "Use a formal tone",
-		Attempt:          3,
+	if got := APIBase(nil); got != DefaultAPIBase {
+		t.Fatalf("APIBase(nil) = %q", got)
+	}
+	config := &types.Config{GeminiAPI: "https://gateway.example.com/gemini/v1beta/"}
+	if got := APIBase(config); got != "https://gateway.example.com/gemini/v1beta" {
+		t.Fatalf("expected gemini_api to be honoured, got %q", got)
+	}
+	t.Setenv("GEMINI_API_URL", "http://localhost:8080/v1beta")
+	if got := APIBase(config); got != "http://localhost:8080/v1beta" {
+		t.Fatalf("expected GEMINI_API_URL to win, got %q", got)
 	}
+}
+
+func TestGenerateSendsRESTRequest(t *testing.T) {
+	t.Setenv("GEMINI_MODEL", "pro")
+	t.Setenv("GEMINI_SAFETY_THRESHOLD", "high")
+
+	var got generateRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/models/gemini-2.5-pro:generateContent" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		if key := r.Header.Get("x-goog-api-key"); key != "test-key" {
+			t.Errorf("unexpected API key header %q", key)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		_, _ = w.Write([]byte(`{"candidates":[{"content":{"parts":[{"text":"feat: add "},{"text":"login"}]},"finishReason":"STOP"}]}`))
+	}))
+	defer server.Close()
 
-	// Test with invalid key to verify the function processes attempt count
-	_, err := GenerateCommitMessage(&types.Config{}, "some changes", "invalid-key", opts)
-	if err == nil {
-		t.Fatal("expected error for invalid API key")
+	message, err := generate(context.Background(), server.Client(), server.URL, "some changes", "test-key", nil)
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	if message != "feat: add login" {
+		t.Fatalf("message = %q, want %q", message, "feat: add login")
+	}
+	if len(got.Contents) != 1 || !strings.Contains(got.Contents[0].Parts[0].Text, "some changes") {
+		t.Fatalf("prompt not sent: %+v", got.Contents)
+	}
+	if len(got.SafetySettings) != len(safetyCategories) || got.SafetySettings[0].Threshold != "BLOCK_ONLY_HIGH" {
+		t.Fatalf("unexpected safety settings: %+v", got.SafetySettings)
 	}
 }
 
-func TestGenerateCommitMessageWithEmptyConfig(t *testing.T) {
+func TestGenerateClassifiesErrors(t *testing.T) {
 	t.Parallel()
 
-	// Test with empty config
-	_, err := GenerateCommitMessage(&types.Config{}, "some changes", "invalid-key", nil)
-	if err == nil {
-		t.Fatal("expected error for invalid API key")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"error":{"code":429,"message":"Resource has been exhausted","status":"RESOURCE_EXHAUSTED"}}`))
+	}))
+	defer server.Close()
+
+	_, err := generate(context.Background(), server.Client(), server.URL, "some changes", "test-key", nil)
+	if !errors.Is(err, llmerr.ErrQuotaExceeded) {
+		t.Fatalf("expected ErrQuotaExceeded, got %v", err)
 	}
 }
 
-func TestGenerateCommitMessageWithNilConfig(t *testing.T) {
+func TestGenerateReportsSafetyBlock(t *testing.T) {
 	t.Parallel()
 
-	// Test with nil config
-	_, err := GenerateCommitMessage(nil, "some changes", "invalid-key", nil)
-	if err == nil {
-		t.Fatal("expected error for invalid API key")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"candidates":[{"content":{"parts":[]},"finishReason":"SAFETY"}]}`))
+	}))
+	defer server.Close()
+
+	_, err := generate(context.Background(), server.Client(), server.URL, "some changes", "test-key", nil)
+	if err == nil || !strings.Contains(err.Error(), "GEMINI_SAFETY_THRESHOLD") {
+		t.Fatalf("expected safety error, got %v", err)
+	}
+}
+
+func TestSafetySettingsFromEnvRejectsUnknownThreshold(t *testing.T) {
+	t.Setenv("GEMINI_SAFETY_THRESHOLD", "strict")
+
+	if _, err := safetySettingsFromEnv(); err == nil {
+		t.Fatal("expected error for unknown threshold")
+	}
+}
+
+// replay answers Gemini requests from the named cassette in testdata.
+func replay(t *testing.T, name string) *vcr.Replayer {
+	t.Helper()
+
+	replayer, err := vcr.LoadReplayer(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("failed to load cassette: %v", err)
+	}
+	httpClient.Configure(httpClient.Settings{Wrap: replayer.Wrap})
+	t.Cleanup(func() { httpClient.Configure(httpClient.Settings{}) })
+	return replayer
+}
+
+func TestGenerateCommitMessageReplaysRecordedResponses(t *testing.T) {
+	t.Setenv("GEMINI_MODEL", "")
+	t.Setenv("GEMINI_SAFETY_THRESHOLD", "")
+	replayer := replay(t, "generate_content.json")
+
+	message, err := GenerateCommitMessage(context.Background(), &types.Config{}, "diff --git a/main.go b/main.go", "AIza-test", nil)
+	if err != nil {
+		t.Fatalf("GenerateCommitMessage returned error: %v", err)
+	}
+	if message != "feat(cli): add a --dry-run flag" {
+		t.Fatalf("expected the parts to be joined, got %q", message)
+	}
+
+	_, err = GenerateCommitMessage(context.Background(), &types.Config{}, "diff --git a/main.go b/main.go", "AIza-test", nil)
+	if !errors.Is(err, llmerr.ErrQuotaExceeded) {
+		t.Fatalf("expected ErrQuotaExceeded, got %v", err)
+	}
+	if replayer.Remaining() != 0 {
+		t.Fatalf("expected every recorded interaction to be used, %d remain", replayer.Remaining())
 	}
 }
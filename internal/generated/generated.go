@@ -0,0 +1,168 @@
+// Package generated recognizes generated files, such as protobuf code,
+// mocks, and minified assets, so their diffs can be left out of the prompt
+// in favor of the hand-written changes.
+package generated
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dfanso/commit-msg/internal/diffsection"
+)
+
+// Settings is the "generated" section of config.json.
+type Settings struct {
+	// Patterns lists further globs for generated files. A pattern without
+	// a slash matches the file name, one ending in a slash matches a
+	// directory anywhere in the path, and any other pattern matches the
+	// whole path.
+	Patterns []string `json:"patterns"`
+}
+
+// DefaultPatterns match the output of common code generators.
+var DefaultPatterns = []string{
+	"*.pb.go", "*.pb.gw.go", "*_pb2.py", "*_pb2_grpc.py", "*.pb.h", "*.pb.cc",
+	"*_gen.go", "*.gen.go", "*_generated.go", "zz_generated.*.go",
+	"mock_*.go", "*_mock.go", "mocks/",
+	"*.min.js", "*.min.css", "*.js.map", "*.css.map",
+	"*.g.dart", "*.freezed.dart", "*.designer.cs",
+}
+
+// headerLines is how many lines at the top of a file are searched for a
+// generated-code marker.
+const headerLines = 10
+
+// minifiedLineLength is the line length past which a file is treated as
+// minified.
+const minifiedLineLength = 1000
+
+// markers are comments generators leave at the top of their output.
+var markers = []*regexp.Regexp{
+	regexp.MustCompile(`Code generated .* DO NOT EDIT\.`),
+	regexp.MustCompile(`@generated`),
+	regexp.MustCompile(`<auto-generated`),
+	regexp.MustCompile(`\bDO NOT EDIT\b`),
+	regexp.MustCompile(`(?i)this file (is|was|has been) (auto-?)?generated`),
+}
+
+// Detector decides which files in a diff are generated.
+type Detector struct {
+	root     string
+	patterns []string
+}
+
+// New returns a Detector matching DefaultPatterns and patterns. Files are
+// also recognized by a marker near the top of the copy under root, when
+// root is not empty.
+func New(root string, patterns []string) *Detector {
+	all := append(append([]string{}, DefaultPatterns...), patterns...)
+	return &Detector{root: root, patterns: all}
+}
+
+// Collapse returns changes with the diff of every generated file replaced
+// by a one-line note.
+func (d *Detector) Collapse(changes string) string {
+	return diffsection.Replace(changes, func(file string, lines []string) ([]string, bool) {
+		if !d.Generated(file, lines) {
+			return nil, false
+		}
+		added, removed := diffsection.Count(lines)
+		return []string{fmt.Sprintf("Generated file %s: diff omitted (+%d -%d lines).", file, added, removed)}, true
+	})
+}
+
+// Generated reports whether the file at file, whose diff lines are given,
+// is generated: its path matches a pattern, a marker appears at the top of
+// the file or the diff, or it holds minified lines.
+func (d *Detector) Generated(file string, diff []string) bool {
+	if file == "" {
+		return false
+	}
+	for _, pattern := range d.patterns {
+		if Match(pattern, file) {
+			return true
+		}
+	}
+
+	for _, line := range fileHeader(diff) {
+		if hasMarker(line) {
+			return true
+		}
+	}
+	for _, line := range diff {
+		if diffsection.IsChange(line) && utf8.RuneCountInString(line) > minifiedLineLength {
+			return true
+		}
+	}
+	return d.root != "" && headerHasMarker(filepath.Join(d.root, filepath.FromSlash(file)))
+}
+
+// hunkHeader captures the first old and new line numbers of a hunk.
+var hunkHeader = regexp.MustCompile(`^@@ -(\d+)(?:,\d+)? \+(\d+)(?:,\d+)? @@`)
+
+// fileHeader returns the first lines of the file's content shown in diff,
+// when its first hunk starts at the top of the file.
+func fileHeader(diff []string) []string {
+	for i, line := range diff {
+		if !strings.HasPrefix(line, "@@") {
+			continue
+		}
+		match := hunkHeader.FindStringSubmatch(line)
+		if match == nil || (match[1] != "0" && match[1] != "1" && match[2] != "0" && match[2] != "1") {
+			return nil
+		}
+		header := diff[i+1:]
+		return header[:min(len(header), headerLines)]
+	}
+	return nil
+}
+
+// Match reports whether the slash-separated path file matches pattern, as
+// described on Settings.Patterns.
+func Match(pattern, file string) bool {
+	pattern = strings.TrimPrefix(strings.TrimSpace(pattern), "./")
+	switch {
+	case pattern == "":
+		return false
+	case strings.HasSuffix(pattern, "/"):
+		return strings.HasPrefix(file, pattern) || strings.Contains(file, "/"+pattern)
+	case strings.Contains(pattern, "/"):
+		ok, _ := path.Match(pattern, file)
+		return ok
+	default:
+		ok, _ := path.Match(pattern, path.Base(file))
+		return ok
+	}
+}
+
+func hasMarker(line string) bool {
+	for _, marker := range markers {
+		if marker.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
+
+func headerHasMarker(file string) bool {
+	f, err := os.Open(file)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 4096), 64*1024)
+	for i := 0; i < headerLines && scanner.Scan(); i++ {
+		if hasMarker(scanner.Text()) {
+			return true
+		}
+	}
+	return false
+}
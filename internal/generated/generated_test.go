@@ -0,0 +1,94 @@
+package generated
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		pattern string
+		file    string
+		want    bool
+	}{
+		{pattern: "*.pb.go", file: "api/v1/user.pb.go", want: true},
+		{pattern: "*.pb.go", file: "api/v1/user.go", want: false},
+		{pattern: "mocks/", file: "internal/mocks/store.go", want: true},
+		{pattern: "mocks/", file: "mocks/store.go", want: true},
+		{pattern: "mocks/", file: "internal/mocksy/store.go", want: false},
+		{pattern: "web/dist/*.js", file: "web/dist/app.js", want: true},
+		{pattern: "web/dist/*.js", file: "web/src/app.js", want: false},
+		{pattern: "  ", file: "anything", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := Match(tt.pattern, tt.file); got != tt.want {
+			t.Errorf("Match(%q, %q) = %v, want %v", tt.pattern, tt.file, got, tt.want)
+		}
+	}
+}
+
+func TestGenerated(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "schema.go"), []byte("// Code generated by sqlc. DO NOT EDIT.\npackage db\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	d := New(root, []string{"api/openapi.ts"})
+
+	tests := []struct {
+		name string
+		file string
+		diff []string
+		want bool
+	}{
+		{name: "default pattern", file: "user_gen.go", want: true},
+		{name: "configured pattern", file: "api/openapi.ts", want: true},
+		{name: "marker in new file", file: "types.ts", diff: []string{"new file mode 100644", "@@ -0,0 +1,3 @@", "+// @generated by protoc-gen-ts", "+export type A = string"}, want: true},
+		{name: "marker past the top", file: "notes.go", diff: []string{"@@ -40,3 +40,3 @@", "-// DO NOT EDIT this table by hand", "+// DO NOT EDIT this table"}, want: false},
+		{name: "marker on disk", file: "schema.go", diff: []string{"@@ -20,1 +20,1 @@", "-a", "+b"}, want: true},
+		{name: "minified", file: "bundle.js", diff: []string{"@@ -1 +1 @@", "+" + strings.Repeat("a;", 600)}, want: true},
+		{name: "hand-written", file: "main.go", diff: []string{"@@ -1,1 +1,1 @@", "-package old", "+package main"}, want: false},
+	}
+
+	for _, tt := range tests {
+		if got := d.Generated(tt.file, tt.diff); got != tt.want {
+			t.Errorf("%s: Generated(%q) = %v, want %v", tt.name, tt.file, got, tt.want)
+		}
+	}
+}
+
+func TestCollapse(t *testing.T) {
+	t.Parallel()
+
+	changes := `Staged diff content:
+diff --git a/api/user.pb.go b/api/user.pb.go
+index 1111111..2222222 100644
+--- a/api/user.pb.go
++++ b/api/user.pb.go
+@@ -10,2 +10,3 @@
+-	old
++	new
++	more
+diff --git a/main.go b/main.go
+@@ -1 +1 @@
+-package old
++package main
+`
+	want := `Staged diff content:
+diff --git a/api/user.pb.go b/api/user.pb.go
+Generated file api/user.pb.go: diff omitted (+2 -1 lines).
+diff --git a/main.go b/main.go
+@@ -1 +1 @@
+-package old
++package main
+`
+	if got := New("", nil).Collapse(changes); got != want {
+		t.Fatalf("Collapse() =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,174 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/dfanso/commit-msg/internal/logging"
+	"github.com/dfanso/commit-msg/internal/utils"
+	"github.com/dfanso/commit-msg/pkg/types"
+)
+
+// lfsPointerPrefix starts every Git LFS pointer file.
+const lfsPointerPrefix = "version https://git-lfs.github.com/spec/v1"
+
+// maxPointerSize is larger than any Git LFS pointer, so bigger blobs are
+// never read to look for one.
+const maxPointerSize = 1024
+
+// BinaryChange is a changed binary file, whose content is left out of the
+// prompt.
+type BinaryChange struct {
+	// Status is the diff status letter, or '?' for an untracked file.
+	Status  byte
+	Path    string
+	OldPath string
+	// OldSize and NewSize are the sizes of both sides in bytes, or -1 when
+	// that side does not exist or its size is unknown.
+	OldSize int64
+	NewSize int64
+	// LFS is set when the file is stored with Git LFS. Its sizes are those
+	// of the real content, not of the pointer.
+	LFS bool
+}
+
+// String describes the change, as in "logo.png: modified, 12.0 KB -> 15.1 KB".
+func (c BinaryChange) String() string {
+	name := c.Path
+	if c.OldPath != "" {
+		name = c.OldPath + " -> " + c.Path
+	}
+	if c.LFS {
+		name += " (Git LFS)"
+	}
+
+	switch {
+	case c.Status == '?':
+		return fmt.Sprintf("%s: new untracked file, %s", name, sizeOrUnknown(c.NewSize))
+	case c.Status == 'A':
+		return fmt.Sprintf("%s: added, %s", name, sizeOrUnknown(c.NewSize))
+	case c.Status == 'D':
+		return fmt.Sprintf("%s: deleted, was %s", name, sizeOrUnknown(c.OldSize))
+	case c.Status == 'R' && c.OldSize == c.NewSize:
+		return fmt.Sprintf("%s: renamed, %s", name, sizeOrUnknown(c.NewSize))
+	case c.Status == 'R':
+		return fmt.Sprintf("%s: renamed, %s -> %s", name, sizeOrUnknown(c.OldSize), sizeOrUnknown(c.NewSize))
+	}
+	return fmt.Sprintf("%s: modified, %s -> %s", name, sizeOrUnknown(c.OldSize), sizeOrUnknown(c.NewSize))
+}
+
+func sizeOrUnknown(size int64) string {
+	if size < 0 {
+		return "unknown size"
+	}
+	return utils.FormatBytes(size)
+}
+
+// binaryChanges lists the staged, unstaged, and untracked binary files,
+// which the diffs leave out.
+func (c *ChangeSet) binaryChanges() []BinaryChange {
+	var changes []BinaryChange
+	seen := make(map[string]bool)
+	rootConfig := &types.RepoConfig{Path: c.Root}
+
+	for _, files := range [][]FileChange{c.Staged, c.Unstaged} {
+		for _, file := range files {
+			if seen[file.Path] || !file.Binary || file.NewMode == modeSymlink || file.NewMode == modeSubmodule {
+				continue
+			}
+			seen[file.Path] = true
+
+			change := BinaryChange{Status: file.Status, Path: file.Path, OldPath: file.OldPath, OldSize: -1, NewSize: -1}
+			if change.Status != 'R' {
+				change.OldPath = ""
+			}
+			if file.Status != 'A' {
+				change.OldSize, change.LFS = blobSize(rootConfig, file.OldHash)
+			}
+			if file.Status != 'D' {
+				if isZeroHash(file.NewHash) {
+					change.NewSize = fileSize(filepath.Join(c.Root, file.Path))
+				} else {
+					var lfs bool
+					change.NewSize, lfs = blobSize(rootConfig, file.NewHash)
+					change.LFS = change.LFS || lfs
+				}
+			}
+			changes = append(changes, change)
+		}
+	}
+
+	for _, file := range c.Untracked {
+		if seen[file] {
+			continue
+		}
+		full := filepath.Join(c.Root, file)
+		if utils.IsBinaryFile(full) {
+			changes = append(changes, BinaryChange{Status: '?', Path: file, OldSize: -1, NewSize: fileSize(full)})
+		}
+	}
+	return changes
+}
+
+// blobSize returns the size of the blob with hash and whether it is a Git
+// LFS pointer, in which case the size is that of the content it points to.
+func blobSize(config *types.RepoConfig, hash string) (int64, bool) {
+	if hash == "" || isZeroHash(hash) {
+		return -1, false
+	}
+	cmd := exec.Command("git", "-C", config.Path, "cat-file", "-s", hash)
+	logging.Command(cmd)
+	output, err := cmd.Output()
+	if err != nil {
+		return -1, false
+	}
+	size, err := strconv.ParseInt(strings.TrimSpace(string(output)), 10, 64)
+	if err != nil {
+		return -1, false
+	}
+	if size > maxPointerSize {
+		return size, false
+	}
+
+	cmd = exec.Command("git", "-C", config.Path, "cat-file", "blob", hash)
+	logging.Command(cmd)
+	content, err := cmd.Output()
+	if err != nil {
+		return size, false
+	}
+	if pointed, ok := lfsPointerSize(content); ok {
+		return pointed, true
+	}
+	return size, false
+}
+
+// lfsPointerSize returns the size recorded in a Git LFS pointer file.
+func lfsPointerSize(content []byte) (int64, bool) {
+	if !bytes.HasPrefix(content, []byte(lfsPointerPrefix)) {
+		return 0, false
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		if value, ok := strings.CutPrefix(line, "size "); ok {
+			size, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+			return size, err == nil
+		}
+	}
+	return 0, false
+}
+
+func fileSize(path string) int64 {
+	info, err := os.Stat(path)
+	if err != nil {
+		return -1
+	}
+	return info.Size()
+}
+
+func isZeroHash(hash string) bool {
+	return strings.Trim(hash, "0") == ""
+}
@@ -0,0 +1,86 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dfanso/commit-msg/pkg/types"
+)
+
+func TestBinaryChangeString(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		change BinaryChange
+		want   string
+	}{
+		{BinaryChange{Status: 'M', Path: "logo.png", OldSize: 2048, NewSize: 3072}, "logo.png: modified, 2.0 KB -> 3.0 KB"},
+		{BinaryChange{Status: 'A', Path: "intro.mp4", OldSize: -1, NewSize: 5 << 20, LFS: true}, "intro.mp4 (Git LFS): added, 5.0 MB"},
+		{BinaryChange{Status: 'D', Path: "old.bin", OldSize: 10, NewSize: -1}, "old.bin: deleted, was 10 B"},
+		{BinaryChange{Status: 'R', Path: "b.png", OldPath: "a.png", OldSize: 10, NewSize: 10}, "a.png -> b.png: renamed, 10 B"},
+		{BinaryChange{Status: '?', Path: "new.bin", OldSize: -1, NewSize: -1}, "new.bin: new untracked file, unknown size"},
+	}
+	for _, tt := range tests {
+		if got := tt.change.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestLFSPointerSize(t *testing.T) {
+	t.Parallel()
+
+	pointer := []byte("version https://git-lfs.github.com/spec/v1\noid sha256:4d7a\nsize 12345\n")
+	if size, ok := lfsPointerSize(pointer); !ok || size != 12345 {
+		t.Fatalf("lfsPointerSize() = %d, %v, want 12345", size, ok)
+	}
+	if _, ok := lfsPointerSize([]byte("size 12345\n")); ok {
+		t.Fatal("expected plain content not to be read as a pointer")
+	}
+}
+
+func TestBinaryChanges(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration-style test in short mode")
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git executable not available")
+	}
+
+	dir := t.TempDir()
+	runGit(t, dir, "init")
+	runGit(t, dir, "config", "user.name", "Test User")
+	runGit(t, dir, "config", "user.email", "test@example.com")
+	write := func(name string, size int) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), append([]byte{0}, make([]byte, size-1)...), 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	write("logo.png", 100)
+	runGit(t, dir, "add", "logo.png")
+	runGit(t, dir, "commit", "-m", "add logo")
+
+	write("logo.png", 300)
+	runGit(t, dir, "add", "logo.png")
+	write("new.bin", 50)
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("text\n"), 0o644); err != nil {
+		t.Fatalf("failed to write notes.txt: %v", err)
+	}
+
+	set, err := CollectChanges(&types.RepoConfig{Path: dir})
+	if err != nil {
+		t.Fatalf("CollectChanges returned error: %v", err)
+	}
+	var got []string
+	for _, change := range set.binaryChanges() {
+		got = append(got, change.String())
+	}
+	want := "logo.png: modified, 100 B -> 300 B\nnew.bin: new untracked file, 50 B"
+	if strings.Join(got, "\n") != want {
+		t.Fatalf("binaryChanges() = %q, want %q", got, want)
+	}
+}
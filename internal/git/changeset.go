@@ -0,0 +1,223 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/dfanso/commit-msg/internal/logging"
+	"github.com/dfanso/commit-msg/internal/scrubber"
+	"github.com/dfanso/commit-msg/internal/utils"
+	"github.com/dfanso/commit-msg/pkg/types"
+)
+
+// FileChange is one staged or unstaged file of a ChangeSet.
+type FileChange struct {
+	FileEvent
+	// Insertions and Deletions are the line counts from --numstat. Both are
+	// zero for binary files.
+	Insertions int
+	Deletions  int
+	// Binary is set when either side of the file is binary, so its content
+	// is never sent to the LLM.
+	Binary bool
+}
+
+// nameStatus renders the change as a line of git diff --name-status output.
+func (f FileChange) nameStatus() string {
+	status := string(f.Status) + f.Score
+	if f.OldPath != "" {
+		return status + "\t" + f.OldPath + "\t" + f.Path
+	}
+	return status + "\t" + f.Path
+}
+
+// ChangeSet holds the pending changes of a repository, collected once and
+// shared by the prompt and the statistics so both see the same files and
+// agree on which of them are binary.
+type ChangeSet struct {
+	// Root is the repository root that every path is relative to.
+	Root      string
+	Staged    []FileChange
+	Unstaged  []FileChange
+	Untracked []string
+	// StagedDiff and UnstagedDiff are the patches of the text files.
+	StagedDiff   string
+	UnstagedDiff string
+	// History is the recent commit history given to the LLM as context.
+	History string
+
+	limits types.ContentLimits
+}
+
+// CollectChanges gathers the staged, unstaged, and untracked changes of the
+// repository at config.Path. When paths are given only changes under those
+// pathspecs are collected.
+func CollectChanges(config *types.RepoConfig, paths ...string) (*ChangeSet, error) {
+	root, err := RepoRoot(config.Path)
+	if err != nil {
+		root = config.Path
+	}
+	set := &ChangeSet{Root: root, limits: config.Limits}
+
+	set.Unstaged, set.UnstagedDiff, err = collectFileChanges(config, root, paths, false)
+	if err != nil {
+		return nil, err
+	}
+	set.Staged, set.StagedDiff, err = collectFileChanges(config, root, paths, true)
+	if err != nil {
+		return nil, err
+	}
+
+	untracked := exec.Command("git", "-C", config.Path, "ls-files", "--others", "--exclude-standard", "--full-name", "-z")
+	appendPathspec(untracked, paths)
+	logging.Command(untracked)
+	output, err := untracked.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git ls-files failed: %v", err)
+	}
+	set.Untracked = utils.FilterEmpty(strings.Split(string(output), "\x00"))
+
+	if history, err := RecentHistory(config); err == nil {
+		set.History = history
+	}
+	return set, nil
+}
+
+// StagedOnly returns the part of the set that would be committed: the
+// staged files, without unstaged or untracked changes.
+func (c *ChangeSet) StagedOnly() *ChangeSet {
+	return &ChangeSet{
+		Root:       c.Root,
+		Staged:     c.Staged,
+		StagedDiff: c.StagedDiff,
+		History:    c.History,
+		limits:     c.limits,
+	}
+}
+
+// collectFileChanges lists the unstaged or staged files with their line
+// counts from a single diff --raw --numstat run, then diffs the text files.
+func collectFileChanges(config *types.RepoConfig, root string, paths []string, cached bool) ([]FileChange, string, error) {
+	name := "git diff"
+	args := []string{"-C", config.Path, "diff", "--raw", "--numstat", "-z", "--no-abbrev"}
+	if cached {
+		name = "git diff --cached"
+		args = append(args, "--cached")
+	}
+	cmd := exec.Command("git", args...)
+	appendPathspec(cmd, paths)
+	logging.Command(cmd)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, "", fmt.Errorf("%s failed: %v", name, err)
+	}
+
+	events, numstat := splitRawDiffZ(string(output))
+	counts := parseNumstatZ(numstat)
+
+	var files []FileChange
+	var textFiles []string
+	for _, event := range events {
+		file := FileChange{FileEvent: event}
+		names := []string{event.Path}
+		if event.OldPath != "" {
+			names = []string{event.OldPath, event.Path}
+		}
+		for _, name := range names {
+			if utils.IsBinaryFile(filepath.Join(root, name)) {
+				file.Binary = true
+				break
+			}
+		}
+		if !file.Binary {
+			count := counts[event.Path]
+			file.Insertions, file.Deletions = count[0], count[1]
+			textFiles = append(textFiles, names...)
+		}
+		files = append(files, file)
+	}
+	if len(textFiles) == 0 {
+		return files, "", nil
+	}
+
+	// Diff paths are relative to the root, so the patch is taken from there.
+	diffArgs := []string{"-C", root, "diff"}
+	if cached {
+		diffArgs = append(diffArgs, "--cached")
+	}
+	diffCmd := exec.Command("git", append(append(diffArgs, "--"), textFiles...)...)
+	logging.Command(diffCmd)
+	diff, err := diffCmd.Output()
+	if err != nil {
+		return nil, "", fmt.Errorf("%s content failed: %v", name, err)
+	}
+	return files, string(diff), nil
+}
+
+// Prompt renders the changes as the scrubbed description sent to the LLM.
+func (c *ChangeSet) Prompt() string {
+	return scrubber.ScrubDiff(c.Text())
+}
+
+// Text renders the changes like Prompt but without scrubbing them, for
+// callers that scrub the finished prompt themselves.
+func (c *ChangeSet) Text() string {
+	var changes strings.Builder
+
+	// Spell out deletions, mode changes, and symlinks, which otherwise only
+	// show up as status letters or are filtered out with binary files.
+	if events := c.fileEvents(); len(events) > 0 {
+		changes.WriteString("File operations:\n- ")
+		changes.WriteString(strings.Join(events, "\n- "))
+		changes.WriteString("\n\n")
+	}
+
+	// Binary content is never sent, but knowing which binaries changed and
+	// by how much still helps describe the commit.
+	if binaries := c.binaryChanges(); len(binaries) > 0 {
+		changes.WriteString("Binary files (content not shown):\n")
+		for _, binary := range binaries {
+			changes.WriteString("- " + binary.String() + "\n")
+		}
+		changes.WriteString("\n")
+	}
+
+	writeFileChanges(&changes, "Unstaged", c.Unstaged, c.UnstagedDiff)
+	writeFileChanges(&changes, "Staged", c.Staged, c.StagedDiff)
+
+	if len(c.Untracked) > 0 {
+		WriteUntrackedFiles(&changes, c.Root, c.Untracked, c.limits)
+	}
+
+	if c.History != "" {
+		changes.WriteString("Recent commits for context:\n")
+		changes.WriteString(c.History)
+		changes.WriteString("\n")
+	}
+
+	return changes.String()
+}
+
+// writeFileChanges appends the name-status lines and diff of the text files
+// among files under headings starting with label.
+func writeFileChanges(changes *strings.Builder, label string, files []FileChange, diff string) {
+	var lines []string
+	for _, file := range files {
+		if !file.Binary {
+			lines = append(lines, file.nameStatus())
+		}
+	}
+	if len(lines) == 0 {
+		return
+	}
+
+	changes.WriteString(label + " changes:\n")
+	changes.WriteString(strings.Join(lines, "\n"))
+	changes.WriteString("\n\n")
+
+	changes.WriteString(label + " diff content:\n")
+	changes.WriteString(diff)
+	changes.WriteString("\n\n")
+}
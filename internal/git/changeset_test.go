@@ -0,0 +1,87 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dfanso/commit-msg/pkg/types"
+)
+
+func TestCollectChanges(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration-style test in short mode")
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git executable not available")
+	}
+
+	dir := t.TempDir()
+	runGit(t, dir, "init")
+	runGit(t, dir, "config", "user.name", "Test User")
+	runGit(t, dir, "config", "user.email", "test@example.com")
+	write := func(name, content string) {
+		t.Helper()
+		full := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	write("main.go", "package main\n")
+	write("old.txt", "one\ntwo\nthree\n")
+	runGit(t, dir, "add", ".")
+	runGit(t, dir, "commit", "-m", "initial commit")
+
+	write("main.go", "package main\n\nfunc main() {}\n")
+	runGit(t, dir, "mv", "old.txt", "new.txt")
+	write("logo.png", "\x89PNG\x00\x01")
+	runGit(t, dir, "add", "logo.png")
+	write("sub/draft.md", "draft\n")
+
+	// Paths are relative to the root even when collected from a subdirectory.
+	set, err := CollectChanges(&types.RepoConfig{Path: filepath.Join(dir, "sub")})
+	if err != nil {
+		t.Fatalf("CollectChanges returned error: %v", err)
+	}
+
+	if len(set.Unstaged) != 1 || set.Unstaged[0].Path != "main.go" || set.Unstaged[0].Insertions != 2 {
+		t.Fatalf("Unstaged = %+v, want main.go with 2 insertions", set.Unstaged)
+	}
+	if !strings.Contains(set.UnstagedDiff, "+func main() {}") {
+		t.Fatalf("UnstagedDiff = %q", set.UnstagedDiff)
+	}
+
+	var staged []string
+	for _, file := range set.Staged {
+		staged = append(staged, file.nameStatus())
+		if file.Path == "logo.png" && !file.Binary {
+			t.Errorf("expected logo.png to be binary")
+		}
+	}
+	if got, want := strings.Join(staged, ","), "A\tlogo.png,R100\told.txt\tnew.txt"; got != want {
+		t.Fatalf("staged = %q, want %q", got, want)
+	}
+	if strings.Contains(set.StagedDiff, "logo.png") {
+		t.Fatalf("expected the binary file to be left out of the diff, got %q", set.StagedDiff)
+	}
+
+	if strings.Join(set.Untracked, ",") != "sub/draft.md" {
+		t.Fatalf("Untracked = %q, want [sub/draft.md]", set.Untracked)
+	}
+
+	prompt := set.Prompt()
+	for _, fragment := range []string{
+		"Binary files (content not shown):\n- logo.png: added",
+		"Staged changes:\nR100\told.txt\tnew.txt",
+		"Content of new file sub/draft.md:",
+	} {
+		if !strings.Contains(prompt, fragment) {
+			t.Errorf("prompt missing fragment %q\nprompt: %s", fragment, prompt)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/dfanso/commit-msg/internal/logging"
+	"github.com/dfanso/commit-msg/internal/scrubber"
+	"github.com/dfanso/commit-msg/internal/utils"
+	"github.com/dfanso/commit-msg/pkg/types"
+)
+
+// CherryPick describes a cherry-pick that stopped on conflicts and is
+// waiting to be committed.
+type CherryPick struct {
+	// Hash is the full hash of the commit being picked.
+	Hash string
+	// Message is the message of the commit being picked.
+	Message string
+	// Conflicts lists the files git reported conflicts in.
+	Conflicts []string
+	// Unmerged lists the files whose conflicts are not resolved yet.
+	Unmerged []string
+}
+
+// ShortHash returns the abbreviated hash of the picked commit.
+func (p *CherryPick) ShortHash() string {
+	return p.Hash[:min(len(p.Hash), 7)]
+}
+
+// InProgressCherryPick returns the cherry-pick in progress in the repository
+// at config.Path, or nil when there is none.
+func InProgressCherryPick(config *types.RepoConfig) (*CherryPick, error) {
+	cmd := exec.Command("git", "-C", config.Path, "rev-parse", "--verify", "--quiet", "CHERRY_PICK_HEAD")
+	logging.Command(cmd)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, nil
+	}
+	pick := &CherryPick{Hash: strings.TrimSpace(string(output))}
+
+	if pick.Message, err = CommitMessage(config, pick.Hash); err != nil {
+		return nil, err
+	}
+
+	// git lists the conflicted files at the end of the message it prepares
+	// in MERGE_MSG; the list is lost from the index once they are resolved.
+	if gitDir, err := GitDir(config.Path); err == nil {
+		if data, err := os.ReadFile(filepath.Join(gitDir, "MERGE_MSG")); err == nil {
+			pick.Conflicts = parseConflicts(string(data))
+		}
+	}
+
+	unmerged := exec.Command("git", "-C", config.Path, "diff", "--name-only", "--diff-filter=U", "-z")
+	logging.Command(unmerged)
+	output, err = unmerged.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git diff --diff-filter=U failed: %v", err)
+	}
+	pick.Unmerged = utils.FilterEmpty(strings.Split(string(output), "\x00"))
+	return pick, nil
+}
+
+// parseConflicts returns the files listed under "Conflicts:" in a merge
+// message, which git writes commented out ("# Conflicts:") since 2.0 and
+// as plain text before.
+func parseConflicts(message string) []string {
+	var files []string
+	inList := false
+	for _, line := range strings.Split(message, "\n") {
+		trimmed := strings.TrimSpace(strings.TrimPrefix(line, "#"))
+		switch {
+		case trimmed == "Conflicts:":
+			inList = true
+		case !inList:
+		case strings.HasPrefix(strings.TrimPrefix(line, "#"), "\t") && trimmed != "":
+			files = append(files, trimmed)
+		case trimmed == "" && len(files) == 0:
+			// git leaves a "#" line between the heading and the list.
+		default:
+			inList = false
+		}
+	}
+	return files
+}
+
+// OriginalChanges returns the scrubbed diff the picked commit made to the
+// conflicted files, so it can be compared with how they were resolved.
+func (p *CherryPick) OriginalChanges(config *types.RepoConfig) (string, error) {
+	if len(p.Conflicts) == 0 {
+		return "", nil
+	}
+	// The conflicted files are relative to the root, as MERGE_MSG lists them.
+	root, err := RepoRoot(config.Path)
+	if err != nil {
+		root = config.Path
+	}
+	args := append([]string{"-C", root, "diff-tree", "-p", "-r", "--root", "--no-color", "--no-commit-id", p.Hash, "--"}, p.Conflicts...)
+	cmd := exec.Command("git", args...)
+	logging.Command(cmd)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git diff-tree %s failed: %v", p.ShortHash(), err)
+	}
+	return scrubber.ScrubDiff(string(output)), nil
+}
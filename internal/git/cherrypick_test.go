@@ -0,0 +1,124 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dfanso/commit-msg/pkg/types"
+)
+
+func TestInProgressCherryPick(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration-style test in short mode")
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git executable not available")
+	}
+
+	dir := t.TempDir()
+	runGit(t, dir, "init")
+	runGit(t, dir, "config", "user.name", "Test User")
+	runGit(t, dir, "config", "user.email", "test@example.com")
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	write("config.txt", "timeout = 10\n")
+	write("notes.txt", "first\n")
+	runGit(t, dir, "add", ".")
+	runGit(t, dir, "commit", "-m", "initial commit")
+	runGit(t, dir, "branch", "-M", "main")
+
+	config := &types.RepoConfig{Path: dir}
+	if pick, err := InProgressCherryPick(config); err != nil || pick != nil {
+		t.Fatalf("InProgressCherryPick() = %+v, %v before any cherry-pick", pick, err)
+	}
+
+	runGit(t, dir, "checkout", "-b", "release")
+	write("config.txt", "timeout = 30\n")
+	write("notes.txt", "first\nsecond\n")
+	runGit(t, dir, "commit", "-am", "Raise the timeout\n\nSlow mirrors need longer.")
+	picked, err := ResolveCommit(config, "HEAD")
+	if err != nil {
+		t.Fatalf("ResolveCommit returned error: %v", err)
+	}
+
+	runGit(t, dir, "checkout", "main")
+	write("config.txt", "timeout = 20\n")
+	runGit(t, dir, "commit", "-am", "Tune the timeout")
+
+	cherryPick := exec.Command("git", "-C", dir, "cherry-pick", picked)
+	if output, err := cherryPick.CombinedOutput(); err == nil {
+		t.Fatalf("expected the cherry-pick to stop on conflicts:\n%s", output)
+	}
+
+	pick, err := InProgressCherryPick(config)
+	if err != nil {
+		t.Fatalf("InProgressCherryPick returned error: %v", err)
+	}
+	if pick == nil || pick.Hash != picked || pick.Message != "Raise the timeout\n\nSlow mirrors need longer." {
+		t.Fatalf("InProgressCherryPick() = %+v, want the picked commit", pick)
+	}
+	if want := []string{"config.txt"}; !reflect.DeepEqual(pick.Conflicts, want) || !reflect.DeepEqual(pick.Unmerged, want) {
+		t.Fatalf("Conflicts = %q, Unmerged = %q, want %q for both", pick.Conflicts, pick.Unmerged, want)
+	}
+
+	write("config.txt", "timeout = 30\n")
+	runGit(t, dir, "add", "config.txt")
+	pick, err = InProgressCherryPick(config)
+	if err != nil {
+		t.Fatalf("InProgressCherryPick returned error: %v", err)
+	}
+	if len(pick.Unmerged) != 0 || len(pick.Conflicts) != 1 {
+		t.Fatalf("after resolving, Conflicts = %q, Unmerged = %q", pick.Conflicts, pick.Unmerged)
+	}
+
+	original, err := pick.OriginalChanges(config)
+	if err != nil {
+		t.Fatalf("OriginalChanges returned error: %v", err)
+	}
+	if !strings.Contains(original, "+timeout = 30") || strings.Contains(original, "notes.txt") {
+		t.Fatalf("OriginalChanges() = %q, want only the conflicted file", original)
+	}
+}
+
+func TestParseConflicts(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		message string
+		want    []string
+	}{
+		"commented": {
+			message: "Fix bug\n\n# Conflicts:\n#\ta.go\n#\tdocs/b.md\n",
+			want:    []string{"a.go", "docs/b.md"},
+		},
+		"plain": {
+			message: "Fix bug\n\nConflicts:\n\ta.go\n",
+			want:    []string{"a.go"},
+		},
+		"blank comment line": {
+			message: "Fix bug\n\n# Conflicts:\n#\n#\ta.go\n",
+			want:    []string{"a.go"},
+		},
+		"none": {
+			message: "Fix bug\n\nConflicts: none expected\n",
+			want:    nil,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			if got := parseConflicts(tt.message); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("parseConflicts() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dfanso/commit-msg/pkg/types"
+)
+
+// Git file modes as reported by diff --raw.
+const (
+	modeFile       = "100644"
+	modeExecutable = "100755"
+	modeSymlink    = "120000"
+	modeSubmodule  = "160000"
+	modeNone       = "000000"
+)
+
+// FileEvent is one entry of git diff --raw output.
+type FileEvent struct {
+	Status byte
+	// Score is the similarity percentage of a rename or copy, e.g. "090".
+	Score   string
+	OldMode string
+	NewMode string
+	// OldHash and NewHash are the blob hashes of both sides; NewHash is all
+	// zeros for a file in the working tree.
+	OldHash string
+	NewHash string
+	Path    string
+	// OldPath is set for renames and copies.
+	OldPath string
+}
+
+// parseRawDiffZ parses git diff --raw -z output. Each entry is
+// ":oldmode newmode oldsha newsha status" followed by one path, or two for
+// renames and copies.
+func parseRawDiffZ(output string) []FileEvent {
+	events, _ := splitRawDiffZ(output)
+	return events
+}
+
+// splitRawDiffZ parses the --raw entries at the start of output and returns
+// the rest, such as the --numstat entries git writes after them.
+func splitRawDiffZ(output string) ([]FileEvent, string) {
+	var events []FileEvent
+	fields := strings.Split(output, "\x00")
+	for i := 0; i < len(fields); i++ {
+		header := fields[i]
+		if header == "" {
+			continue
+		}
+		if !strings.HasPrefix(header, ":") {
+			return events, strings.Join(fields[i:], "\x00")
+		}
+		parts := strings.Fields(header[1:])
+		if len(parts) < 5 || parts[4] == "" || i+1 >= len(fields) {
+			continue
+		}
+
+		event := FileEvent{
+			Status:  parts[4][0],
+			Score:   parts[4][1:],
+			OldMode: parts[0],
+			NewMode: parts[1],
+			OldHash: parts[2],
+			NewHash: parts[3],
+			Path:    fields[i+1],
+		}
+		i++
+		if (event.Status == 'R' || event.Status == 'C') && i+1 < len(fields) {
+			event.OldPath = event.Path
+			event.Path = fields[i+1]
+			i++
+		}
+		events = append(events, event)
+	}
+	return events, ""
+}
+
+// describe explains the event in words, or returns "" for plain content
+// edits and additions that the diff already shows. target resolves the
+// destination of a symlink.
+func (e FileEvent) describe(target func(path string) string) string {
+	switch e.Status {
+	case 'D':
+		switch e.OldMode {
+		case modeSymlink:
+			return fmt.Sprintf("deleted symlink %s", e.Path)
+		case modeSubmodule:
+			return fmt.Sprintf("removed submodule %s", e.Path)
+		}
+		return fmt.Sprintf("deleted %s", e.Path)
+
+	case 'A':
+		switch e.NewMode {
+		case modeSymlink:
+			return withTarget(fmt.Sprintf("added symlink %s", e.Path), target(e.Path))
+		case modeSubmodule:
+			return fmt.Sprintf("added submodule %s", e.Path)
+		case modeExecutable:
+			return fmt.Sprintf("added executable %s", e.Path)
+		}
+		return ""
+	}
+
+	switch {
+	case e.OldMode == e.NewMode && e.NewMode == modeSymlink && e.Status == 'M':
+		return withTarget(fmt.Sprintf("symlink %s changed", e.Path), target(e.Path))
+	case e.OldMode == e.NewMode, e.OldMode == modeNone, e.NewMode == modeNone:
+		return ""
+	case e.OldMode == modeSymlink:
+		return fmt.Sprintf("replaced symlink %s with a regular file", e.Path)
+	case e.NewMode == modeSymlink:
+		return withTarget(fmt.Sprintf("replaced %s with a symlink", e.Path), target(e.Path))
+	case e.OldMode == modeFile && e.NewMode == modeExecutable:
+		return fmt.Sprintf("made %s executable", e.Path)
+	case e.OldMode == modeExecutable && e.NewMode == modeFile:
+		return fmt.Sprintf("removed executable bit from %s", e.Path)
+	}
+	return fmt.Sprintf("changed mode of %s from %s to %s", e.Path, e.OldMode, e.NewMode)
+}
+
+func withTarget(description, target string) string {
+	if target == "" {
+		return description
+	}
+	return description + " -> " + target
+}
+
+// fileEvents lists the deletions, mode changes, and symlink changes among
+// the staged and unstaged changes, described for the prompt.
+func (c *ChangeSet) fileEvents() []string {
+	var descriptions []string
+	seen := make(map[string]bool)
+
+	for _, cached := range []bool{true, false} {
+		files := c.Unstaged
+		if cached {
+			files = c.Staged
+		}
+
+		// Staged symlinks point where the index says; unstaged ones where the
+		// working tree does.
+		target := func(path string) string {
+			if cached {
+				blob, ok := FileAtRevision(&types.RepoConfig{Path: c.Root}, "", path)
+				if !ok {
+					return ""
+				}
+				return string(blob)
+			}
+			link, err := os.Readlink(filepath.Join(c.Root, path))
+			if err != nil {
+				return ""
+			}
+			return link
+		}
+
+		for _, file := range files {
+			description := file.describe(target)
+			if description == "" || seen[description] {
+				continue
+			}
+			seen[description] = true
+			descriptions = append(descriptions, description)
+		}
+	}
+	return descriptions
+}
@@ -0,0 +1,123 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/dfanso/commit-msg/pkg/types"
+)
+
+func TestParseRawDiffZ(t *testing.T) {
+	t.Parallel()
+
+	output := ":100644 000000 aaa 000 D\x00old.go\x00" +
+		":100644 100755 bbb bbb M\x00run.sh\x00" +
+		":100644 100644 ccc ddd R090\x00a.txt\x00b.txt\x00"
+
+	got := parseRawDiffZ(output)
+	want := []FileEvent{
+		{Status: 'D', OldMode: "100644", NewMode: "000000", OldHash: "aaa", NewHash: "000", Path: "old.go"},
+		{Status: 'M', OldMode: "100644", NewMode: "100755", OldHash: "bbb", NewHash: "bbb", Path: "run.sh"},
+		{Status: 'R', OldMode: "100644", NewMode: "100644", Score: "090", OldHash: "ccc", NewHash: "ddd", Path: "b.txt", OldPath: "a.txt"},
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("parseRawDiffZ returned %d events, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("event %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestFileEventDescribe(t *testing.T) {
+	t.Parallel()
+
+	target := func(string) string { return "docs/README.md" }
+
+	tests := []struct {
+		event FileEvent
+		want  string
+	}{
+		{FileEvent{Status: 'D', OldMode: modeFile, NewMode: modeNone, Path: "legacy.go"}, "deleted legacy.go"},
+		{FileEvent{Status: 'D', OldMode: modeSymlink, NewMode: modeNone, Path: "link"}, "deleted symlink link"},
+		{FileEvent{Status: 'A', OldMode: modeNone, NewMode: modeSymlink, Path: "README"}, "added symlink README -> docs/README.md"},
+		{FileEvent{Status: 'A', OldMode: modeNone, NewMode: modeFile, Path: "new.go"}, ""},
+		{FileEvent{Status: 'M', OldMode: modeFile, NewMode: modeFile, Path: "edit.go"}, ""},
+		{FileEvent{Status: 'M', OldMode: modeFile, NewMode: modeExecutable, Path: "run.sh"}, "made run.sh executable"},
+		{FileEvent{Status: 'M', OldMode: modeExecutable, NewMode: modeFile, Path: "run.sh"}, "removed executable bit from run.sh"},
+		{FileEvent{Status: 'M', OldMode: modeSymlink, NewMode: modeSymlink, Path: "README"}, "symlink README changed -> docs/README.md"},
+		{FileEvent{Status: 'T', OldMode: modeSymlink, NewMode: modeFile, Path: "README"}, "replaced symlink README with a regular file"},
+		{FileEvent{Status: 'A', OldMode: modeNone, NewMode: modeSubmodule, Path: "vendor/lib"}, "added submodule vendor/lib"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.event.describe(target); got != tt.want {
+			t.Errorf("describe(%+v) = %q, want %q", tt.event, got, tt.want)
+		}
+	}
+}
+
+func TestGetChangesDescribesFileOperations(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration-style test in short mode")
+	}
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks and executable bits are not portable to Windows")
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git executable not available")
+	}
+
+	dir := t.TempDir()
+	runGit(t, dir, "init")
+	runGit(t, dir, "config", "user.name", "Test User")
+	runGit(t, dir, "config", "user.email", "test@example.com")
+
+	for name, content := range map[string]string{
+		"legacy_loader.go": "package config\n",
+		"logo.png":         "\x89PNG\x00",
+		"run.sh":           "#!/bin/sh\n",
+		"target.txt":       "target\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	runGit(t, dir, "add", ".")
+	runGit(t, dir, "commit", "-m", "initial commit")
+
+	runGit(t, dir, "rm", "-q", "legacy_loader.go")
+	if err := os.Remove(filepath.Join(dir, "logo.png")); err != nil {
+		t.Fatalf("failed to remove logo.png: %v", err)
+	}
+	if err := os.Chmod(filepath.Join(dir, "run.sh"), 0o755); err != nil {
+		t.Fatalf("failed to chmod run.sh: %v", err)
+	}
+	if err := os.Symlink("target.txt", filepath.Join(dir, "current")); err != nil {
+		t.Fatalf("failed to create symlink: %v", err)
+	}
+	runGit(t, dir, "add", "current")
+
+	output, err := GetChanges(&types.RepoConfig{Path: dir})
+	if err != nil {
+		t.Fatalf("GetChanges returned error: %v", err)
+	}
+
+	for _, fragment := range []string{
+		"File operations:",
+		"- deleted legacy_loader.go",
+		"- deleted logo.png",
+		"- made run.sh executable",
+		"- added symlink current -> target.txt",
+	} {
+		if !strings.Contains(output, fragment) {
+			t.Errorf("output missing fragment %q\noutput: %s", fragment, output)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/dfanso/commit-msg/internal/logging"
+	"github.com/dfanso/commit-msg/pkg/types"
+)
+
+// Autosquash prefixes that git rebase --autosquash recognises.
+const (
+	FixupPrefix  = "fixup! "
+	SquashPrefix = "squash! "
+)
+
+// FindCommit returns the full hash of the commit query refers to: a
+// revision such as a hash or "HEAD~2", or else the most recent commit
+// reachable from HEAD whose message contains query, ignoring case.
+func FindCommit(config *types.RepoConfig, query string) (string, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return "", fmt.Errorf("no commit given")
+	}
+	if hash, err := ResolveCommit(config, query); err == nil {
+		return hash, nil
+	}
+
+	cmd := exec.Command("git", "-C", config.Path, "log", "-1", "--format=%H", "--regexp-ignore-case", "--fixed-strings", "--grep="+query, "HEAD", "--")
+	logging.Command(cmd)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git log failed: %v", err)
+	}
+	hash := strings.TrimSpace(string(output))
+	if hash == "" {
+		return "", fmt.Errorf("no commit matches %q", query)
+	}
+	return hash, nil
+}
+
+// IsAncestor reports whether the commit at rev is HEAD or one of its
+// ancestors, which autosquash needs to find the target of a fixup.
+func IsAncestor(config *types.RepoConfig, rev string) bool {
+	cmd := exec.Command("git", "-C", config.Path, "merge-base", "--is-ancestor", rev, "HEAD")
+	logging.Command(cmd)
+	return cmd.Run() == nil
+}
+
+// AutosquashSubject returns the subject git commit --fixup or --squash gives
+// a commit targeting a commit with message: prefix followed by the
+// target's subject.
+func AutosquashSubject(prefix, message string) string {
+	subject, _, _ := strings.Cut(strings.TrimSpace(message), "\n")
+	return prefix + strings.TrimSpace(subject)
+}
@@ -0,0 +1,73 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/dfanso/commit-msg/pkg/types"
+)
+
+func TestFindCommit(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration-style test in short mode")
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git executable not available")
+	}
+
+	dir := t.TempDir()
+	runGit(t, dir, "init")
+	runGit(t, dir, "config", "user.name", "Test User")
+	runGit(t, dir, "config", "user.email", "test@example.com")
+	for _, subject := range []string{"Add parser", "Fix parser crash", "Add docs"} {
+		if err := os.WriteFile(filepath.Join(dir, "file.txt"), []byte(subject+"\n"), 0o644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+		runGit(t, dir, "add", "file.txt")
+		runGit(t, dir, "commit", "-m", subject)
+	}
+	config := &types.RepoConfig{Path: dir}
+	head, err := ResolveCommit(config, "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parent, err := ResolveCommit(config, "HEAD~1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{query: "HEAD", want: head},
+		{query: parent[:7], want: parent},
+		{query: "PARSER", want: parent},
+		{query: "docs", want: head},
+	}
+	for _, tt := range tests {
+		got, err := FindCommit(config, tt.query)
+		if err != nil || got != tt.want {
+			t.Errorf("FindCommit(%q) = %q, %v, want %q", tt.query, got, err, tt.want)
+		}
+	}
+	if _, err := FindCommit(config, "no such change"); err == nil {
+		t.Fatal("expected an error when nothing matches")
+	}
+	if !IsAncestor(config, parent) {
+		t.Fatal("expected HEAD~1 to be an ancestor of HEAD")
+	}
+}
+
+func TestAutosquashSubject(t *testing.T) {
+	t.Parallel()
+
+	if got := AutosquashSubject(FixupPrefix, "Add parser\n\nWith a body."); got != "fixup! Add parser" {
+		t.Fatalf("AutosquashSubject() = %q", got)
+	}
+	if got := AutosquashSubject(SquashPrefix, "  Add docs  "); got != "squash! Add docs" {
+		t.Fatalf("AutosquashSubject() = %q", got)
+	}
+}
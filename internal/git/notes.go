@@ -0,0 +1,50 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/dfanso/commit-msg/internal/logging"
+	"github.com/dfanso/commit-msg/pkg/types"
+)
+
+// NotesRef holds the explanations attached to commits, apart from the
+// default refs/notes/commits so they do not mix with notes written by hand.
+const NotesRef = "refs/notes/gocommit"
+
+// AddNote attaches note to the commit at rev under NotesRef, replacing any
+// note it already has.
+func AddNote(config *types.RepoConfig, rev, note string) error {
+	cmd := exec.Command("git", "-C", config.Path, "notes", "--ref="+NotesRef, "add", "--force", "--file=-", rev)
+	cmd.Stdin = strings.NewReader(strings.TrimSpace(note) + "\n")
+	logging.Command(cmd)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git notes add failed: %v: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// Note returns the note attached to the commit at rev under NotesRef. ok is
+// false when the commit has none.
+func Note(config *types.RepoConfig, rev string) (note string, ok bool, err error) {
+	hash, err := ResolveCommit(config, rev)
+	if err != nil {
+		return "", false, err
+	}
+
+	// git notes list exits with an error when the commit has no note.
+	list := exec.Command("git", "-C", config.Path, "notes", "--ref="+NotesRef, "list", hash)
+	logging.Command(list)
+	if err := list.Run(); err != nil {
+		return "", false, nil
+	}
+
+	cmd := exec.Command("git", "-C", config.Path, "notes", "--ref="+NotesRef, "show", hash)
+	logging.Command(cmd)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", false, fmt.Errorf("git notes show failed: %v", err)
+	}
+	return strings.TrimRight(string(output), "\n"), true, nil
+}
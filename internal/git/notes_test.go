@@ -0,0 +1,54 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/dfanso/commit-msg/pkg/types"
+)
+
+func TestNotes(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration-style test in short mode")
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git executable not available")
+	}
+
+	dir := t.TempDir()
+	runGit(t, dir, "init")
+	runGit(t, dir, "config", "user.name", "Test User")
+	runGit(t, dir, "config", "user.email", "test@example.com")
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	runGit(t, dir, "add", ".")
+	runGit(t, dir, "commit", "-m", "initial commit")
+
+	config := &types.RepoConfig{Path: dir}
+	if note, ok, err := Note(config, "HEAD"); err != nil || ok || note != "" {
+		t.Fatalf("Note() = %q, %v, %v before adding one", note, ok, err)
+	}
+
+	if err := AddNote(config, "HEAD", "first explanation"); err != nil {
+		t.Fatalf("AddNote returned error: %v", err)
+	}
+	if err := AddNote(config, "HEAD", "Why the change was made.\n\nHow it works.\n"); err != nil {
+		t.Fatalf("AddNote over an existing note returned error: %v", err)
+	}
+	note, ok, err := Note(config, "HEAD")
+	if err != nil || !ok || note != "Why the change was made.\n\nHow it works." {
+		t.Fatalf("Note() = %q, %v, %v", note, ok, err)
+	}
+
+	// Notes live apart from the default notes ref.
+	if output, err := exec.Command("git", "-C", dir, "notes", "list").Output(); err != nil || len(output) != 0 {
+		t.Fatalf("expected no notes in refs/notes/commits, got %q (%v)", output, err)
+	}
+
+	if _, _, err := Note(config, "does-not-exist"); err == nil {
+		t.Fatalf("expected an error for an unknown revision")
+	}
+}
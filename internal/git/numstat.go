@@ -0,0 +1,131 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/dfanso/commit-msg/internal/logging"
+	"github.com/dfanso/commit-msg/internal/utils"
+	"github.com/dfanso/commit-msg/pkg/types"
+)
+
+// emptyTree is the hash of git's empty tree, used as the diff base before
+// the first commit.
+const emptyTree = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+
+// FileStat is one changed file with its line counts relative to HEAD,
+// covering staged, unstaged, and untracked changes.
+type FileStat struct {
+	// Status is the diff status letter (A, M, D, R, ...). Untracked files
+	// are reported as A.
+	Status byte
+	Path   string
+	// OldPath is set for renames.
+	OldPath    string
+	Insertions int
+	Deletions  int
+}
+
+// FileStats lists every pending change in the repository at config.Path
+// with its status and line counts. Binary files have zero counts.
+func FileStats(config *types.RepoConfig) ([]FileStat, error) {
+	root, err := RepoRoot(config.Path)
+	if err != nil {
+		root = config.Path
+	}
+
+	base := "HEAD"
+	verify := exec.Command("git", "-C", root, "rev-parse", "--verify", "--quiet", "HEAD")
+	logging.Command(verify)
+	if err := verify.Run(); err != nil {
+		base = emptyTree
+	}
+
+	raw := exec.Command("git", "-C", root, "diff", base, "--raw", "-z", "-M")
+	logging.Command(raw)
+	rawOutput, err := raw.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git diff --raw failed: %v", err)
+	}
+
+	numstat := exec.Command("git", "-C", root, "diff", base, "--numstat", "-z", "-M")
+	logging.Command(numstat)
+	numstatOutput, err := numstat.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git diff --numstat failed: %v", err)
+	}
+	counts := parseNumstatZ(string(numstatOutput))
+
+	var stats []FileStat
+	for _, event := range parseRawDiffZ(string(rawOutput)) {
+		count := counts[event.Path]
+		stats = append(stats, FileStat{
+			Status:     event.Status,
+			Path:       event.Path,
+			OldPath:    event.OldPath,
+			Insertions: count[0],
+			Deletions:  count[1],
+		})
+	}
+
+	untracked := exec.Command("git", "-C", root, "ls-files", "--others", "--exclude-standard", "-z")
+	logging.Command(untracked)
+	untrackedOutput, err := untracked.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git ls-files failed: %v", err)
+	}
+	for _, path := range strings.Split(string(untrackedOutput), "\x00") {
+		if path == "" {
+			continue
+		}
+		stats = append(stats, FileStat{Status: 'A', Path: path, Insertions: countLines(filepath.Join(root, path))})
+	}
+	return stats, nil
+}
+
+// parseNumstatZ parses git diff --numstat -z output into insertion and
+// deletion counts keyed by the new path. Renames are written as
+// "ins\tdel\t" followed by the old and new paths as separate fields.
+func parseNumstatZ(output string) map[string][2]int {
+	counts := make(map[string][2]int)
+	fields := strings.Split(output, "\x00")
+	for i := 0; i < len(fields); i++ {
+		parts := strings.SplitN(fields[i], "\t", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		// Binary files report "-" for both counts.
+		ins, _ := strconv.Atoi(parts[0])
+		del, _ := strconv.Atoi(parts[1])
+
+		path := parts[2]
+		if path == "" && i+2 < len(fields) {
+			path = fields[i+2]
+			i += 2
+		}
+		counts[path] = [2]int{ins, del}
+	}
+	return counts
+}
+
+// countLines returns the number of lines in a text file, or 0 for binary or
+// unreadable files.
+func countLines(path string) int {
+	if !utils.IsTextFile(path) {
+		return 0
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || len(data) == 0 {
+		return 0
+	}
+	lines := bytes.Count(data, []byte("\n"))
+	if data[len(data)-1] != '\n' {
+		lines++
+	}
+	return lines
+}
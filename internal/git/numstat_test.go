@@ -0,0 +1,74 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/dfanso/commit-msg/pkg/types"
+)
+
+func TestParseNumstatZ(t *testing.T) {
+	t.Parallel()
+
+	output := "3\t1\tmain.go\x00-\t-\tlogo.png\x005\t0\t\x00old.go\x00new.go\x00"
+	got := parseNumstatZ(output)
+	want := map[string][2]int{
+		"main.go":  {3, 1},
+		"logo.png": {0, 0},
+		"new.go":   {5, 0},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("parseNumstatZ() = %v, want %v", got, want)
+	}
+}
+
+func TestFileStats(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration-style test in short mode")
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git executable not available")
+	}
+
+	dir := t.TempDir()
+	runGit(t, dir, "init")
+	runGit(t, dir, "config", "user.name", "Test User")
+	runGit(t, dir, "config", "user.email", "test@example.com")
+
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	write("keep.txt", "one\ntwo\n")
+	write("gone.txt", "bye\n")
+	runGit(t, dir, "add", ".")
+	runGit(t, dir, "commit", "-m", "initial")
+
+	write("keep.txt", "one\nthree\nfour\n")
+	if err := os.Remove(filepath.Join(dir, "gone.txt")); err != nil {
+		t.Fatalf("failed to remove gone.txt: %v", err)
+	}
+	write("new.txt", "a\nb\nc")
+
+	stats, err := FileStats(&types.RepoConfig{Path: dir})
+	if err != nil {
+		t.Fatalf("FileStats returned error: %v", err)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Path < stats[j].Path })
+
+	want := []FileStat{
+		{Status: 'D', Path: "gone.txt", Deletions: 1},
+		{Status: 'M', Path: "keep.txt", Insertions: 2, Deletions: 1},
+		{Status: 'A', Path: "new.txt", Insertions: 3},
+	}
+	if !reflect.DeepEqual(stats, want) {
+		t.Fatalf("FileStats() = %+v, want %+v", stats, want)
+	}
+}
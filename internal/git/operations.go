@@ -5,8 +5,12 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/dfanso/commit-msg/internal/generated"
+	"github.com/dfanso/commit-msg/internal/logging"
 	"github.com/dfanso/commit-msg/internal/scrubber"
 	"github.com/dfanso/commit-msg/internal/utils"
 	"github.com/dfanso/commit-msg/pkg/types"
@@ -15,6 +19,7 @@ import (
 // IsRepository checks if a directory is a git repository
 func IsRepository(path string) bool {
 	cmd := exec.Command("git", "-C", path, "rev-parse", "--is-inside-work-tree")
+	logging.Command(cmd)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return false
@@ -22,238 +27,464 @@ func IsRepository(path string) bool {
 	return strings.TrimSpace(string(output)) == "true"
 }
 
-// parseGitStatusLine represents a parsed git status line
-type parseGitStatusLine struct {
-	status    string
-	filenames []string
+// StageChanges stages working tree changes before generation. When
+// trackedOnly is true only modifications and deletions of tracked files are
+// staged (git add -u); otherwise untracked files are included too (git add -A).
+func StageChanges(config *types.RepoConfig, trackedOnly bool) error {
+	flag := "-A"
+	if trackedOnly {
+		flag = "-u"
+	}
+
+	cmd := exec.Command("git", "-C", config.Path, "add", flag)
+	logging.Command(cmd)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("git add %s failed: %v: %s", flag, err, strings.TrimSpace(string(output)))
+	}
+	return nil
 }
 
-// parseGitNameStatus parses a single line from git diff --name-status output
-// Handles various git status codes including rename (R) and copy (C) operations
-func parseGitNameStatus(line string) parseGitStatusLine {
-	if line == "" {
-		return parseGitStatusLine{}
+// RepoRoot returns the top-level directory of the repository containing path.
+func RepoRoot(path string) (string, error) {
+	cmd := exec.Command("git", "-C", path, "rev-parse", "--show-toplevel")
+	logging.Command(cmd)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git rev-parse --show-toplevel failed: %v", err)
 	}
+	return strings.TrimSpace(string(output)), nil
+}
 
-	// Git uses tabs to separate fields in --name-status output
-	parts := strings.Split(line, "\t")
-	if len(parts) < 2 {
-		return parseGitStatusLine{}
+// GitDir returns the absolute path of the .git directory for the repository
+// containing path. It also resolves linked worktrees, where .git is a file.
+func GitDir(path string) (string, error) {
+	cmd := exec.Command("git", "-C", path, "rev-parse", "--absolute-git-dir")
+	logging.Command(cmd)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git rev-parse --absolute-git-dir failed: %v", err)
 	}
+	return strings.TrimSpace(string(output)), nil
+}
 
-	status := parts[0]
+// CurrentBranch returns the checked-out branch name, or "HEAD" when the
+// repository is in detached HEAD state.
+func CurrentBranch(config *types.RepoConfig) (string, error) {
+	cmd := exec.Command("git", "-C", config.Path, "rev-parse", "--abbrev-ref", "HEAD")
+	logging.Command(cmd)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git rev-parse --abbrev-ref HEAD failed: %v", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
 
-	// Handle rename/copy status codes (e.g., "R100", "C75")
-	if len(status) > 1 && (status[0] == 'R' || status[0] == 'C') {
-		// For rename/copy, we expect: "R100\toldname\tnewname" or "C75\toldname\tnewname"
-		if len(parts) >= 3 {
-			// For renames/copies, both old and new filenames need to be checked
-			oldFile := parts[1]
-			newFile := parts[2]
-			return parseGitStatusLine{
-				status:    status,
-				filenames: []string{oldFile, newFile},
-			}
-		}
+// Upstream describes the branch the current branch tracks, such as
+// "origin/main (2 ahead, 1 behind)". It returns "" when there is no upstream.
+func Upstream(config *types.RepoConfig) string {
+	cmd := exec.Command("git", "-C", config.Path, "rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{upstream}")
+	logging.Command(cmd)
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	upstream := strings.TrimSpace(string(output))
+
+	cmd = exec.Command("git", "-C", config.Path, "rev-list", "--left-right", "--count", "@{upstream}...HEAD")
+	logging.Command(cmd)
+	output, err = cmd.Output()
+	if err != nil {
+		return upstream
+	}
+	behind, ahead, ok := strings.Cut(strings.TrimSpace(string(output)), "\t")
+	if !ok {
+		return upstream
 	}
 
-	// Handle regular status codes (M, A, D, etc.)
-	filename := parts[1]
-	return parseGitStatusLine{
-		status:    status,
-		filenames: []string{filename},
+	var status []string
+	if ahead != "0" {
+		status = append(status, ahead+" ahead")
 	}
+	if behind != "0" {
+		status = append(status, behind+" behind")
+	}
+	if len(status) == 0 {
+		return upstream + " (up to date)"
+	}
+	return upstream + " (" + strings.Join(status, ", ") + ")"
 }
 
-// processGitStatusOutput processes git diff --name-status output and returns filtered results
-func processGitStatusOutput(nameStatusOutput string, returnFilenames bool) ([]string, []string) {
-	if nameStatusOutput == "" {
-		return nil, nil
+// RepoName returns the name of the repository: the last path element of the
+// origin remote URL, or of the repository root when there is no origin.
+func RepoName(config *types.RepoConfig) string {
+	if url, err := RemoteURL(config, "origin"); err == nil && url != "" {
+		url = strings.TrimSuffix(strings.TrimRight(url, "/"), ".git")
+		if i := strings.LastIndexAny(url, "/:"); i >= 0 {
+			url = url[i+1:]
+		}
+		if url != "" {
+			return url
+		}
 	}
+	if root, err := RepoRoot(config.Path); err == nil {
+		return filepath.Base(root)
+	}
+	return ""
+}
 
-	lines := strings.Split(strings.TrimSpace(nameStatusOutput), "\n")
-	var filteredLines []string
-	var nonBinaryFiles []string
+// RecentCommits returns the last n commits in --oneline format.
+func RecentCommits(config *types.RepoConfig, n int) (string, error) {
+	cmd := exec.Command("git", "-C", config.Path, "log", "--oneline", "-n", strconv.Itoa(n))
+	logging.Command(cmd)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git log failed: %v", err)
+	}
+	return string(output), nil
+}
 
-	for _, line := range lines {
-		if line == "" {
-			continue
-		}
+// CommitMessage returns the full message of the commit at rev.
+func CommitMessage(config *types.RepoConfig, rev string) (string, error) {
+	cmd := exec.Command("git", "-C", config.Path, "log", "-1", "--format=%B", rev, "--")
+	logging.Command(cmd)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git log %s failed: %v", rev, err)
+	}
+	return strings.TrimRight(string(output), "\n"), nil
+}
 
-		parsed := parseGitNameStatus(line)
-		if len(parsed.filenames) == 0 {
-			continue
-		}
+// RecentHistory returns the recent commits shown to the LLM for context, as
+// configured by config.Limits: the subjects of the last RecentCommits
+// commits, or their full messages with RecentCommitBodies. It returns "" when
+// the section is disabled.
+func RecentHistory(config *types.RepoConfig) (string, error) {
+	limits := config.Limits.WithDefaults()
+	if limits.RecentCommits < 0 {
+		return "", nil
+	}
+	if !limits.RecentCommitBodies {
+		return RecentCommits(config, limits.RecentCommits)
+	}
 
-		// Check if any of the filenames are binary
-		hasBinaryFile := false
-		for _, filename := range parsed.filenames {
-			if utils.IsBinaryFile(filename) {
-				hasBinaryFile = true
-				break
-			}
-		}
+	// Bodies are indented under their subject so commits stay distinct.
+	cmd := exec.Command("git", "-C", config.Path, "log", "-n", strconv.Itoa(limits.RecentCommits), "--format=%h %s%n%w(0,4,4)%b")
+	logging.Command(cmd)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git log failed: %v", err)
+	}
 
-		// If no binary files found, include this line/files
-		if !hasBinaryFile {
-			filteredLines = append(filteredLines, line)
-			if returnFilenames {
-				nonBinaryFiles = append(nonBinaryFiles, parsed.filenames...)
-			}
+	var lines []string
+	for _, line := range strings.Split(string(output), "\n") {
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, strings.TrimRight(line, " "))
 		}
 	}
-
-	return filteredLines, nonBinaryFiles
+	if len(lines) == 0 {
+		return "", nil
+	}
+	return strings.Join(lines, "\n") + "\n", nil
 }
 
-// filterBinaryFiles filters out binary files from git diff --name-status output
-func filterBinaryFiles(nameStatusOutput string) string {
-	filteredLines, _ := processGitStatusOutput(nameStatusOutput, false)
+// RemoteURL returns the URL configured for the named remote.
+func RemoteURL(config *types.RepoConfig, remote string) (string, error) {
+	cmd := exec.Command("git", "-C", config.Path, "remote", "get-url", remote)
+	logging.Command(cmd)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git remote get-url %s failed: %v", remote, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
 
-	if len(filteredLines) == 0 {
+// ConfigValue returns the value of the git configuration key as seen from the
+// repository, or "" when it is unset.
+func ConfigValue(config *types.RepoConfig, key string) string {
+	cmd := exec.Command("git", "-C", config.Path, "config", "--get", key)
+	logging.Command(cmd)
+	output, err := cmd.Output()
+	if err != nil {
 		return ""
 	}
+	return strings.TrimSpace(string(output))
+}
+
+// SignOffTrailer returns the Signed-off-by trailer git commit --signoff would
+// add, naming the committer identity configured for the repository.
+func SignOffTrailer(config *types.RepoConfig) (string, error) {
+	cmd := exec.Command("git", "-C", config.Path, "var", "GIT_COMMITTER_IDENT")
+	logging.Command(cmd)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git var GIT_COMMITTER_IDENT failed; set user.name and user.email: %v", err)
+	}
+	// The identity is followed by a timestamp and time zone.
+	ident := strings.TrimSpace(string(output))
+	end := strings.LastIndex(ident, ">")
+	if end < 0 {
+		return "", fmt.Errorf("unexpected committer identity %q", ident)
+	}
+	return "Signed-off-by: " + ident[:end+1], nil
+}
+
+// FileAtRevision returns the content of path (relative to the repository
+// root) at rev. The boolean is false when the file does not exist there,
+// including when the repository has no commits yet.
+func FileAtRevision(config *types.RepoConfig, rev, path string) ([]byte, bool) {
+	cmd := exec.Command("git", "-C", config.Path, "cat-file", "blob", rev+":"+path)
+	logging.Command(cmd)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, false
+	}
+	return output, true
+}
 
-	return strings.Join(filteredLines, "\n")
+// ChangedFiles lists every staged, unstaged, and untracked file relative to
+// the repository root. For renames both the old and new paths are returned.
+func ChangedFiles(config *types.RepoConfig) ([]string, error) {
+	cmd := exec.Command("git", "-C", config.Path, "status", "--porcelain", "-z", "--untracked-files=all")
+	logging.Command(cmd)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git status failed: %v", err)
+	}
+	return parsePorcelainZ(string(output)), nil
 }
 
-// extractNonBinaryFiles extracts non-binary filenames from git diff --name-status output
-func extractNonBinaryFiles(nameStatusOutput string) []string {
-	_, nonBinaryFiles := processGitStatusOutput(nameStatusOutput, true)
-	return nonBinaryFiles
+// parsePorcelainZ parses git status --porcelain -z output. Each entry is
+// "XY path", and rename/copy entries are followed by the original path.
+func parsePorcelainZ(output string) []string {
+	var files []string
+	entries := strings.Split(output, "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if len(entry) < 4 {
+			continue
+		}
+		files = append(files, entry[3:])
+		if entry[0] == 'R' || entry[0] == 'C' {
+			if i+1 < len(entries) && entries[i+1] != "" {
+				files = append(files, entries[i+1])
+			}
+			i++
+		}
+	}
+	return files
 }
 
 // GetChanges retrieves all Git changes including staged, unstaged, and untracked files
 func GetChanges(config *types.RepoConfig) (string, error) {
-	var changes strings.Builder
+	return GetChangesInPaths(config)
+}
 
-	// 1. Check for unstaged changes
-	cmd := exec.Command("git", "-C", config.Path, "diff", "--name-status")
-	output, err := cmd.Output()
+// GetChangesInPaths is like GetChanges but limits the diffs and untracked
+// files to the given pathspecs. With no paths it covers the whole repository.
+func GetChangesInPaths(config *types.RepoConfig, paths ...string) (string, error) {
+	set, err := CollectChanges(config, paths...)
 	if err != nil {
-		return "", fmt.Errorf("git diff failed: %v", err)
-	}
-
-	if len(output) > 0 {
-		// Filter out binary files from the name-status output
-		filteredOutput := filterBinaryFiles(string(output))
-
-		if filteredOutput != "" {
-			changes.WriteString("Unstaged changes:\n")
-			changes.WriteString(filteredOutput)
-			changes.WriteString("\n\n")
-
-			// Get the content of these changes (only for non-binary files)
-			nonBinaryFiles := extractNonBinaryFiles(string(output))
-			if len(nonBinaryFiles) > 0 {
-				diffCmd := exec.Command("git", "-C", config.Path, "diff", "--")
-				diffCmd.Args = append(diffCmd.Args, nonBinaryFiles...)
-				diffOutput, err := diffCmd.Output()
-				if err != nil {
-					return "", fmt.Errorf("git diff content failed: %v", err)
-				}
-
-				changes.WriteString("Unstaged diff content:\n")
-				changes.WriteString(string(diffOutput))
-				changes.WriteString("\n\n")
+		return "", err
+	}
+	return set.Prompt(), nil
+}
+
+// WriteUntrackedFiles appends the names of untracked, non-binary files
+// (relative to root) to changes, followed by the content of the small text
+// ones. Files beyond the limits are replaced by a marker so the LLM knows
+// the list or content is incomplete. Files matching limits.UntrackedIgnore
+// are never read, and reading stops once limits.UntrackedReadTimeout has
+// passed, so a slow network mount cannot hang generation.
+func WriteUntrackedFiles(changes *strings.Builder, root string, files []string, limits types.ContentLimits) {
+	limits = limits.WithDefaults()
+	deadline := time.Now().Add(limits.UntrackedTimeout())
+	timedOut := false
+	ignored := func(file string) bool {
+		for _, pattern := range limits.UntrackedIgnore {
+			if generated.Match(pattern, filepath.ToSlash(file)) {
+				return true
 			}
 		}
+		return false
 	}
 
-	// 2. Check for staged changes
-	stagedCmd := exec.Command("git", "-C", config.Path, "diff", "--name-status", "--cached")
-	stagedOutput, err := stagedCmd.Output()
-	if err != nil {
-		return "", fmt.Errorf("git diff --cached failed: %v", err)
-	}
-
-	if len(stagedOutput) > 0 {
-		// Filter out binary files from the staged changes
-		filteredStagedOutput := filterBinaryFiles(string(stagedOutput))
-
-		if filteredStagedOutput != "" {
-			changes.WriteString("Staged changes:\n")
-			changes.WriteString(filteredStagedOutput)
-			changes.WriteString("\n\n")
-
-			// Get the content of these changes (only for non-binary files)
-			nonBinaryStagedFiles := extractNonBinaryFiles(string(stagedOutput))
-			if len(nonBinaryStagedFiles) > 0 {
-				stagedDiffCmd := exec.Command("git", "-C", config.Path, "diff", "--cached", "--")
-				stagedDiffCmd.Args = append(stagedDiffCmd.Args, nonBinaryStagedFiles...)
-				stagedDiffOutput, err := stagedDiffCmd.Output()
-				if err != nil {
-					return "", fmt.Errorf("git diff --cached content failed: %v", err)
-				}
-
-				changes.WriteString("Staged diff content:\n")
-				changes.WriteString(string(stagedDiffOutput))
-				changes.WriteString("\n\n")
+	// Filter out binary files from untracked files. Ignored files, and
+	// every file once the timeout has passed, are listed without a look.
+	var nonBinaryUntrackedFiles []string
+	for _, file := range files {
+		if file == "" {
+			continue
+		}
+		if !timedOut && !ignored(file) {
+			binary, ok := withinDeadline(deadline, func() bool {
+				return utils.IsBinaryFile(filepath.Join(root, file))
+			})
+			if !ok {
+				logging.Debug("untracked file read timed out", "file", file)
+				timedOut = true
+			} else if binary {
+				continue
 			}
 		}
+		nonBinaryUntrackedFiles = append(nonBinaryUntrackedFiles, file)
+	}
+	if len(nonBinaryUntrackedFiles) == 0 {
+		return
 	}
 
-	// 3. Check for untracked files
-	untrackedCmd := exec.Command("git", "-C", config.Path, "ls-files", "--others", "--exclude-standard")
-	untrackedOutput, err := untrackedCmd.Output()
-	if err != nil {
-		return "", fmt.Errorf("git ls-files failed: %v", err)
+	omitted := 0
+	if len(nonBinaryUntrackedFiles) > limits.MaxUntrackedFiles {
+		omitted = len(nonBinaryUntrackedFiles) - limits.MaxUntrackedFiles
+		nonBinaryUntrackedFiles = nonBinaryUntrackedFiles[:limits.MaxUntrackedFiles]
 	}
 
-	if len(untrackedOutput) > 0 {
-		// Filter out binary files from untracked files
-		untrackedFiles := strings.Split(strings.TrimSpace(string(untrackedOutput)), "\n")
-		var nonBinaryUntrackedFiles []string
+	changes.WriteString("Untracked files:\n")
+	changes.WriteString(strings.Join(nonBinaryUntrackedFiles, "\n"))
+	if omitted > 0 {
+		changes.WriteString(fmt.Sprintf("\n[... %d more untracked files not listed (max_untracked_files=%d)]", omitted, limits.MaxUntrackedFiles))
+	}
+	changes.WriteString("\n\n")
+
+	// Try to get content of untracked files (limited to text files and smaller size)
+	var skipped untrackedSkips
+	budget := limits.MaxUntrackedBytes
+	included := 0
+	for _, file := range nonBinaryUntrackedFiles {
+		switch {
+		case ignored(file):
+			skipped.ignored++
+			continue
+		case timedOut:
+			skipped.timedOut++
+			continue
+		case included == limits.MaxUntrackedContents:
+			skipped.overCount++
+			continue
+		}
 
-		for _, file := range untrackedFiles {
-			if file == "" {
-				continue
-			}
-			if !utils.IsBinaryFile(file) {
-				nonBinaryUntrackedFiles = append(nonBinaryUntrackedFiles, file)
-			}
+		content, ok := withinDeadline(deadline, func() untrackedContent {
+			return readUntracked(filepath.Join(root, file), limits.MaxFileBytes, budget)
+		})
+		if !ok {
+			logging.Debug("untracked file read timed out", "file", file)
+			timedOut = true
+			skipped.timedOut++
+			continue
 		}
 
-		if len(nonBinaryUntrackedFiles) > 0 {
-			changes.WriteString("Untracked files:\n")
-			changes.WriteString(strings.Join(nonBinaryUntrackedFiles, "\n"))
-			changes.WriteString("\n\n")
-
-			// Try to get content of untracked files (limited to text files and smaller size)
-			for _, file := range nonBinaryUntrackedFiles {
-				fullPath := filepath.Join(config.Path, file)
-				if utils.IsTextFile(fullPath) && utils.IsSmallFile(fullPath) {
-					fileContent, err := os.ReadFile(fullPath)
-					if err != nil {
-						// Log but don't fail - untracked file may have been deleted or is inaccessible
-						continue
-					}
-					changes.WriteString(fmt.Sprintf("Content of new file %s:\n", file))
-
-					// Use special scrubbing for .env files
-					if strings.HasSuffix(strings.ToLower(file), ".env") ||
-						strings.Contains(strings.ToLower(file), ".env.") {
-						changes.WriteString(scrubber.ScrubEnvFile(string(fileContent)))
-					} else {
-						changes.WriteString(string(fileContent))
-					}
-					changes.WriteString("\n\n")
-				}
-			}
+		switch {
+		case !content.text || content.err != nil:
+			// Log but don't fail - untracked file may have been deleted or is inaccessible
+			continue
+		case content.size > limits.MaxFileBytes:
+			changes.WriteString(fmt.Sprintf("[Content of new file %s omitted: %d bytes exceeds max_file_bytes=%d]\n\n", file, content.size, limits.MaxFileBytes))
+			continue
+		case content.size > budget:
+			skipped.overBytes++
+			continue
+		}
+
+		changes.WriteString(fmt.Sprintf("Content of new file %s:\n", file))
+
+		// Use special scrubbing for .env files
+		if strings.HasSuffix(strings.ToLower(file), ".env") ||
+			strings.Contains(strings.ToLower(file), ".env.") {
+			changes.WriteString(scrubber.ScrubEnvFile(string(content.data)))
+		} else {
+			changes.WriteString(string(content.data))
 		}
+		changes.WriteString("\n\n")
+		included++
+		budget -= int64(len(content.data))
+	}
+
+	if summary := skipped.summary(limits); summary != "" {
+		changes.WriteString(summary + "\n\n")
+	}
+}
+
+// untrackedContent is what readUntracked learns about an untracked file.
+type untrackedContent struct {
+	text bool
+	size int64
+	data []byte
+	err  error
+}
+
+// readUntracked reads the text file at path unless it is larger than
+// maxBytes or budget, in which case only its size is returned.
+func readUntracked(path string, maxBytes, budget int64) untrackedContent {
+	if !utils.IsTextFile(path) {
+		return untrackedContent{}
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return untrackedContent{text: true, err: err}
+	}
+	content := untrackedContent{text: true, size: info.Size()}
+	if content.size > maxBytes || content.size > budget {
+		return content
 	}
+	content.data, content.err = os.ReadFile(path)
+	return content
+}
+
+// untrackedSkips counts the untracked files whose content was left out,
+// by reason.
+type untrackedSkips struct {
+	ignored   int
+	overCount int
+	overBytes int
+	timedOut  int
+}
 
-	// 4. Get recent commits for context
-	recentCommitsCmd := exec.Command("git", "-C", config.Path, "log", "--oneline", "-n", "3")
-	recentCommitsOutput, err := recentCommitsCmd.Output()
-	if err == nil && len(recentCommitsOutput) > 0 {
-		changes.WriteString("Recent commits for context:\n")
-		changes.WriteString(string(recentCommitsOutput))
-		changes.WriteString("\n")
+// summary describes the skipped files for the prompt, or returns "" when
+// none were skipped.
+func (s untrackedSkips) summary(limits types.ContentLimits) string {
+	var reasons []string
+	if s.ignored > 0 {
+		reasons = append(reasons, fmt.Sprintf("%d matched untracked_ignore", s.ignored))
+	}
+	if s.overCount > 0 {
+		reasons = append(reasons, fmt.Sprintf("%d beyond max_untracked_contents=%d", s.overCount, limits.MaxUntrackedContents))
 	}
+	if s.overBytes > 0 {
+		reasons = append(reasons, fmt.Sprintf("%d beyond max_untracked_bytes=%d", s.overBytes, limits.MaxUntrackedBytes))
+	}
+	if s.timedOut > 0 {
+		reasons = append(reasons, fmt.Sprintf("%d not read within untracked_read_timeout=%s", s.timedOut, limits.UntrackedTimeout()))
+	}
+	if len(reasons) == 0 {
+		return ""
+	}
+	total := s.ignored + s.overCount + s.overBytes + s.timedOut
+	return fmt.Sprintf("[Content of %d untracked files skipped: %s]", total, strings.Join(reasons, ", "))
+}
 
-	// Scrub sensitive data before returning
-	scrubbedChanges := scrubber.ScrubDiff(changes.String())
+// withinDeadline runs read and returns its result, or false when deadline
+// passes first. A read still blocked at the deadline, as on an unresponsive
+// network mount, is abandoned.
+func withinDeadline[T any](deadline time.Time, read func() T) (T, bool) {
+	done := make(chan T, 1)
+	go func() { done <- read() }()
+
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+	select {
+	case result := <-done:
+		return result, true
+	case <-timer.C:
+		var zero T
+		return zero, false
+	}
+}
 
-	return scrubbedChanges, nil
+// appendPathspec limits cmd to paths when any are given.
+func appendPathspec(cmd *exec.Cmd, paths []string) {
+	if len(paths) == 0 {
+		return
+	}
+	cmd.Args = append(cmd.Args, "--")
+	cmd.Args = append(cmd.Args, paths...)
 }
@@ -6,7 +6,9 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/dfanso/commit-msg/internal/benchdata"
 	"github.com/dfanso/commit-msg/pkg/types"
 )
 
@@ -120,6 +122,127 @@ func TestGetChangesErrorsOutsideRepo(t *testing.T) {
 	}
 }
 
+func TestStageChanges(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration-style test in short mode")
+	}
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git executable not available")
+	}
+
+	setup := func(t *testing.T) string {
+		dir := t.TempDir()
+		runGit(t, dir, "init")
+		runGit(t, dir, "config", "user.name", "Test User")
+		runGit(t, dir, "config", "user.email", "test@example.com")
+
+		tracked := filepath.Join(dir, "tracked.txt")
+		if err := os.WriteFile(tracked, []byte("first\n"), 0o644); err != nil {
+			t.Fatalf("failed to write tracked file: %v", err)
+		}
+		runGit(t, dir, "add", "tracked.txt")
+		runGit(t, dir, "commit", "-m", "initial commit")
+
+		if err := os.WriteFile(tracked, []byte("second\n"), 0o644); err != nil {
+			t.Fatalf("failed to modify tracked file: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "new.txt"), []byte("new\n"), 0o644); err != nil {
+			t.Fatalf("failed to write untracked file: %v", err)
+		}
+		return dir
+	}
+
+	staged := func(t *testing.T, dir string) string {
+		cmd := exec.Command("git", "-C", dir, "diff", "--cached", "--name-only")
+		output, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("git diff --cached failed: %v", err)
+		}
+		return string(output)
+	}
+
+	t.Run("all", func(t *testing.T) {
+		dir := setup(t)
+		if err := StageChanges(&types.RepoConfig{Path: dir}, false); err != nil {
+			t.Fatalf("StageChanges returned error: %v", err)
+		}
+		got := staged(t, dir)
+		if !strings.Contains(got, "tracked.txt") || !strings.Contains(got, "new.txt") {
+			t.Fatalf("expected tracked and untracked files to be staged, got %q", got)
+		}
+	})
+
+	t.Run("tracked only", func(t *testing.T) {
+		dir := setup(t)
+		if err := StageChanges(&types.RepoConfig{Path: dir}, true); err != nil {
+			t.Fatalf("StageChanges returned error: %v", err)
+		}
+		got := staged(t, dir)
+		if !strings.Contains(got, "tracked.txt") || strings.Contains(got, "new.txt") {
+			t.Fatalf("expected only tracked file to be staged, got %q", got)
+		}
+	})
+}
+
+func TestParsePorcelainZ(t *testing.T) {
+	t.Parallel()
+
+	output := " M pkg/a/main.go\x00?? new.txt\x00R  apps/web/new.ts\x00apps/web/old.ts\x00A  docs/readme.md\x00"
+	got := parsePorcelainZ(output)
+	want := []string{"pkg/a/main.go", "new.txt", "apps/web/new.ts", "apps/web/old.ts", "docs/readme.md"}
+
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("parsePorcelainZ() = %v, want %v", got, want)
+	}
+}
+
+func TestGetChangesInPathsLimitsScope(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration-style test in short mode")
+	}
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git executable not available")
+	}
+
+	dir := t.TempDir()
+	runGit(t, dir, "init")
+	runGit(t, dir, "config", "user.name", "Test User")
+	runGit(t, dir, "config", "user.email", "test@example.com")
+
+	for _, name := range []string{"a/one.txt", "b/two.txt"} {
+		full := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(full, []byte("content\n"), 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	config := &types.RepoConfig{Path: dir}
+
+	files, err := ChangedFiles(config)
+	if err != nil {
+		t.Fatalf("ChangedFiles returned error: %v", err)
+	}
+	if strings.Join(files, ",") != "a/one.txt,b/two.txt" {
+		t.Fatalf("ChangedFiles() = %v, want [a/one.txt b/two.txt]", files)
+	}
+
+	changes, err := GetChangesInPaths(config, "a")
+	if err != nil {
+		t.Fatalf("GetChangesInPaths returned error: %v", err)
+	}
+	if !strings.Contains(changes, "a/one.txt") {
+		t.Fatalf("expected scoped changes to include a/one.txt, got %q", changes)
+	}
+	if strings.Contains(changes, "b/two.txt") {
+		t.Fatalf("expected scoped changes to exclude b/two.txt, got %q", changes)
+	}
+}
+
 func runGit(t *testing.T, dir string, args ...string) {
 	t.Helper()
 
@@ -131,3 +254,239 @@ func runGit(t *testing.T, dir string, args ...string) {
 		t.Fatalf("git %v failed: %v\n%s", args, err, output)
 	}
 }
+
+func TestWriteUntrackedFilesLimits(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"a.txt":   "small",
+		"b.txt":   strings.Repeat("x", 64),
+		"c.txt":   "listed only if the cap allows",
+		"run":     "#!/bin/sh\necho hi\n",
+		"img.bin": "\x00\x01\x02",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	var changes strings.Builder
+	WriteUntrackedFiles(&changes, dir, []string{"a.txt", "b.txt", "run", "img.bin", "c.txt"}, types.ContentLimits{
+		MaxFileBytes:      32,
+		MaxUntrackedFiles: 3,
+	})
+	got := changes.String()
+
+	for _, want := range []string{
+		"Untracked files:\na.txt\nb.txt\nrun\n",
+		"[... 1 more untracked files not listed (max_untracked_files=3)]",
+		"Content of new file a.txt:\nsmall",
+		"[Content of new file b.txt omitted: 64 bytes exceeds max_file_bytes=32]",
+		"Content of new file run:\n#!/bin/sh",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "img.bin") {
+		t.Errorf("binary file should be skipped:\n%s", got)
+	}
+}
+
+func TestWriteUntrackedFilesGuards(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"a.txt":     "first",
+		"b.txt":     "second",
+		"c.txt":     "third file is over the byte budget",
+		"d.txt":     "fourth",
+		"debug.log": "never read",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	var changes strings.Builder
+	WriteUntrackedFiles(&changes, dir, []string{"debug.log", "a.txt", "b.txt", "c.txt", "d.txt"}, types.ContentLimits{
+		MaxUntrackedBytes:    20,
+		MaxUntrackedContents: 2,
+		UntrackedIgnore:      []string{"*.log"},
+	})
+	got := changes.String()
+
+	for _, want := range []string{
+		"Untracked files:\ndebug.log\na.txt",
+		"Content of new file a.txt:\nfirst",
+		"Content of new file b.txt:\nsecond",
+		"[Content of 3 untracked files skipped: 1 matched untracked_ignore, 2 beyond max_untracked_contents=2]",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "never read") {
+		t.Errorf("ignored file was read:\n%s", got)
+	}
+
+	changes.Reset()
+	WriteUntrackedFiles(&changes, dir, []string{"a.txt", "c.txt", "d.txt"}, types.ContentLimits{MaxUntrackedBytes: 12})
+	if want := "[Content of 1 untracked files skipped: 1 beyond max_untracked_bytes=12]"; !strings.Contains(changes.String(), want) {
+		t.Errorf("output missing %q:\n%s", want, changes.String())
+	}
+}
+
+func TestWithinDeadline(t *testing.T) {
+	t.Parallel()
+
+	if got, ok := withinDeadline(time.Now().Add(time.Second), func() int { return 7 }); !ok || got != 7 {
+		t.Fatalf("withinDeadline() = %d, %v, want 7, true", got, ok)
+	}
+
+	block := make(chan struct{})
+	defer close(block)
+	if _, ok := withinDeadline(time.Now().Add(10*time.Millisecond), func() int { <-block; return 1 }); ok {
+		t.Fatal("expected a blocked read to be abandoned")
+	}
+}
+
+func TestRecentHistory(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git executable not available")
+	}
+
+	dir := t.TempDir()
+	runGit(t, dir, "init")
+	runGit(t, dir, "config", "user.name", "Test User")
+	runGit(t, dir, "config", "user.email", "test@example.com")
+	runGit(t, dir, "commit", "--allow-empty", "-m", "First subject", "-m", "First body.")
+	runGit(t, dir, "commit", "--allow-empty", "-m", "Second subject")
+
+	config := &types.RepoConfig{Path: dir, Limits: types.ContentLimits{RecentCommits: 1}}
+	history, err := RecentHistory(config)
+	if err != nil {
+		t.Fatalf("RecentHistory() error = %v", err)
+	}
+	if !strings.Contains(history, "Second subject") || strings.Contains(history, "First") {
+		t.Fatalf("RecentHistory() with one commit = %q", history)
+	}
+
+	config.Limits = types.ContentLimits{RecentCommits: 2, RecentCommitBodies: true}
+	history, err = RecentHistory(config)
+	if err != nil {
+		t.Fatalf("RecentHistory() error = %v", err)
+	}
+	if !strings.Contains(history, "First subject\n    First body.") {
+		t.Fatalf("RecentHistory() with bodies = %q, want an indented body", history)
+	}
+
+	config.Limits = types.ContentLimits{RecentCommits: -1}
+	if history, _ := RecentHistory(config); history != "" {
+		t.Fatalf("RecentHistory() when disabled = %q, want empty", history)
+	}
+}
+
+func TestUpstreamAndRepoName(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git executable not available")
+	}
+
+	remote := filepath.Join(t.TempDir(), "payments.git")
+	runGit(t, filepath.Dir(remote), "init", "--bare", remote)
+
+	dir := t.TempDir()
+	runGit(t, dir, "init")
+	runGit(t, dir, "config", "user.name", "Test User")
+	runGit(t, dir, "config", "user.email", "test@example.com")
+	runGit(t, dir, "checkout", "-b", "hotfix/payment-retry")
+	runGit(t, dir, "commit", "--allow-empty", "-m", "First")
+	config := &types.RepoConfig{Path: dir}
+
+	if upstream := Upstream(config); upstream != "" {
+		t.Fatalf("Upstream() without a remote = %q, want empty", upstream)
+	}
+	if name := RepoName(config); name != filepath.Base(dir) {
+		t.Fatalf("RepoName() without a remote = %q, want %q", name, filepath.Base(dir))
+	}
+
+	runGit(t, dir, "remote", "add", "origin", remote)
+	runGit(t, dir, "push", "-u", "origin", "hotfix/payment-retry")
+	runGit(t, dir, "commit", "--allow-empty", "-m", "Second")
+
+	if upstream, want := Upstream(config), "origin/hotfix/payment-retry (1 ahead)"; upstream != want {
+		t.Fatalf("Upstream() = %q, want %q", upstream, want)
+	}
+	if name := RepoName(config); name != "payments" {
+		t.Fatalf("RepoName() = %q, want payments", name)
+	}
+}
+
+func TestConfigValue(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git executable not available")
+	}
+
+	dir := t.TempDir()
+	runGit(t, dir, "init")
+	config := &types.RepoConfig{Path: dir}
+
+	if value := ConfigValue(config, "commitmsg.unset"); value != "" {
+		t.Fatalf("ConfigValue() for an unset key = %q, want empty", value)
+	}
+
+	runGit(t, dir, "config", "core.editor", "code --wait")
+	if value, want := ConfigValue(config, "core.editor"), "code --wait"; value != want {
+		t.Fatalf("ConfigValue() = %q, want %q", value, want)
+	}
+}
+
+func TestSignOffTrailer(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git executable not available")
+	}
+
+	dir := t.TempDir()
+	runGit(t, dir, "init")
+	t.Setenv("GIT_COMMITTER_NAME", "Ada Lovelace")
+	t.Setenv("GIT_COMMITTER_EMAIL", "ada@example.com")
+
+	trailer, err := SignOffTrailer(&types.RepoConfig{Path: dir})
+	if err != nil {
+		t.Fatalf("SignOffTrailer() error = %v", err)
+	}
+	if want := "Signed-off-by: Ada Lovelace <ada@example.com>"; trailer != want {
+		t.Fatalf("SignOffTrailer() = %q, want %q", trailer, want)
+	}
+}
+
+func BenchmarkGetChanges(b *testing.B) {
+	if _, err := exec.LookPath("git"); err != nil {
+		b.Skip("git executable not available")
+	}
+
+	for _, size := range []struct {
+		name         string
+		files, lines int
+	}{
+		{"10files", 10, 20},
+		{"100files", 100, 50},
+	} {
+		b.Run(size.name, func(b *testing.B) {
+			dir := b.TempDir()
+			if err := benchdata.WriteRepo(dir, size.files, size.lines); err != nil {
+				b.Fatalf("failed to write repository: %v", err)
+			}
+			config := &types.RepoConfig{Path: dir}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := GetChanges(config); err != nil {
+					b.Fatalf("GetChanges returned error: %v", err)
+				}
+			}
+		})
+	}
+}
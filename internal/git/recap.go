@@ -0,0 +1,64 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/dfanso/commit-msg/internal/logging"
+	"github.com/dfanso/commit-msg/internal/scrubber"
+	"github.com/dfanso/commit-msg/pkg/types"
+)
+
+// Period selects the commits reachable from HEAD made in a span of time.
+// Since and Until take any date git log understands, such as "9am" or
+// "yesterday"; empty means unbounded. Author limits the commits to a
+// matching author when set.
+type Period struct {
+	Since  string
+	Until  string
+	Author string
+}
+
+func (p Period) logArgs() []string {
+	var args []string
+	if p.Since != "" {
+		args = append(args, "--since="+p.Since)
+	}
+	if p.Until != "" {
+		args = append(args, "--until="+p.Until)
+	}
+	if p.Author != "" {
+		args = append(args, "--author="+p.Author)
+	}
+	return args
+}
+
+// CountCommits returns the number of commits in period, leaving out merges
+// as PeriodChanges does.
+func CountCommits(config *types.RepoConfig, period Period) (int, error) {
+	args := append([]string{"-C", config.Path, "rev-list", "--count", "--no-merges"}, period.logArgs()...)
+	cmd := exec.Command("git", append(args, "HEAD", "--")...)
+	logging.Command(cmd)
+	output, err := cmd.Output()
+	if err != nil {
+		return 0, fmt.Errorf("git rev-list failed: %v", err)
+	}
+	return strconv.Atoi(strings.TrimSpace(string(output)))
+}
+
+// PeriodChanges returns the scrubbed log of the commits in period other than
+// merges, oldest first, with each commit's date, message, file statistics,
+// and patch.
+func PeriodChanges(config *types.RepoConfig, period Period) (string, error) {
+	args := append([]string{"-C", config.Path, "log", "--reverse", "--no-merges", "--stat", "--patch", "-M", "--no-color",
+		"--date=format:%a %H:%M", "--format=commit %h (%ad)%n%B"}, period.logArgs()...)
+	cmd := exec.Command("git", append(args, "HEAD", "--")...)
+	logging.Command(cmd)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git log failed: %v", err)
+	}
+	return scrubber.ScrubDiff(string(output)), nil
+}
@@ -0,0 +1,70 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dfanso/commit-msg/pkg/types"
+)
+
+func TestPeriodChanges(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration-style test in short mode")
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git executable not available")
+	}
+
+	dir := t.TempDir()
+	runGit(t, dir, "init")
+	runGit(t, dir, "config", "user.name", "Test User")
+	runGit(t, dir, "config", "user.email", "test@example.com")
+	commit := func(name, message, date, author string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(message+"\n"), 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+		runGit(t, dir, "add", name)
+		cmd := exec.Command("git", "-C", dir, "commit", "-m", message, "--date="+date, "--author="+author)
+		cmd.Env = append(os.Environ(), "GIT_COMMITTER_DATE="+date)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git commit failed: %v\n%s", err, output)
+		}
+	}
+	commit("old.txt", "Old work", "2024-03-01T10:00:00", "Test User <test@example.com>")
+	commit("parser.go", "Fix the parser", "2024-03-04T09:30:00", "Test User <test@example.com>")
+	commit("docs.md", "Document the parser", "2024-03-04T11:00:00", "Other Dev <other@example.com>")
+	commit("lexer.go", "Speed up the lexer", "2024-03-04T15:00:00", "Test User <test@example.com>")
+
+	config := &types.RepoConfig{Path: dir}
+	period := Period{Since: "2024-03-04T00:00:00", Until: "2024-03-04T23:59:59", Author: "test@example.com"}
+
+	count, err := CountCommits(config, period)
+	if err != nil || count != 2 {
+		t.Fatalf("CountCommits() = %d, %v, want 2", count, err)
+	}
+
+	changes, err := PeriodChanges(config, period)
+	if err != nil {
+		t.Fatalf("PeriodChanges returned error: %v", err)
+	}
+	parser, lexer := strings.Index(changes, "Fix the parser"), strings.Index(changes, "Speed up the lexer")
+	if parser < 0 || lexer < parser {
+		t.Fatalf("expected both commits oldest first, got %q", changes)
+	}
+	for _, excluded := range []string{"Old work", "Document the parser"} {
+		if strings.Contains(changes, excluded) {
+			t.Fatalf("expected %q to be outside the period, got %q", excluded, changes)
+		}
+	}
+	if !strings.Contains(changes, "+Speed up the lexer") || !strings.Contains(changes, "(Mon 15:00)") {
+		t.Fatalf("expected patches and dates in the log, got %q", changes)
+	}
+
+	if count, err := CountCommits(config, Period{Since: "2030-01-01"}); err != nil || count != 0 {
+		t.Fatalf("CountCommits() for a future period = %d, %v, want 0", count, err)
+	}
+}
@@ -0,0 +1,42 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/dfanso/commit-msg/internal/logging"
+	"github.com/dfanso/commit-msg/internal/scrubber"
+	"github.com/dfanso/commit-msg/pkg/types"
+)
+
+// ReleaseLog returns the scrubbed messages and file statistics of the
+// commits in revRange other than merges, oldest first, along with their
+// number. A single revision selects the commits since it, as in
+// "v1.4.0..HEAD".
+func ReleaseLog(config *types.RepoConfig, revRange string) (string, int, error) {
+	if !strings.Contains(revRange, "..") {
+		revRange += "..HEAD"
+	}
+
+	count := exec.Command("git", "-C", config.Path, "rev-list", "--count", "--no-merges", revRange, "--")
+	logging.Command(count)
+	output, err := count.Output()
+	if err != nil {
+		return "", 0, fmt.Errorf("git rev-list %s failed: %v", revRange, err)
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(string(output)))
+	if err != nil || n == 0 {
+		return "", 0, err
+	}
+
+	cmd := exec.Command("git", "-C", config.Path, "log", "--reverse", "--no-merges", "--stat", "--no-color",
+		"--format=commit %h%n%B", revRange, "--")
+	logging.Command(cmd)
+	output, err = cmd.Output()
+	if err != nil {
+		return "", 0, fmt.Errorf("git log %s failed: %v", revRange, err)
+	}
+	return scrubber.ScrubDiff(string(output)), n, nil
+}
@@ -0,0 +1,59 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dfanso/commit-msg/pkg/types"
+)
+
+func TestReleaseLog(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration-style test in short mode")
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git executable not available")
+	}
+
+	dir := t.TempDir()
+	runGit(t, dir, "init")
+	runGit(t, dir, "config", "user.name", "Test User")
+	runGit(t, dir, "config", "user.email", "test@example.com")
+	commit := func(name, message string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(message+"\n"), 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+		runGit(t, dir, "add", name)
+		runGit(t, dir, "commit", "-m", message)
+	}
+	commit("a.txt", "Initial release")
+	runGit(t, dir, "tag", "v1.0.0")
+	commit("b.txt", "Add dark mode")
+	commit("c.txt", "Fix crash on startup")
+	runGit(t, dir, "tag", "v1.1.0")
+	commit("d.txt", "Start the next feature")
+
+	config := &types.RepoConfig{Path: dir}
+	log, count, err := ReleaseLog(config, "v1.0.0..v1.1.0")
+	if err != nil || count != 2 {
+		t.Fatalf("ReleaseLog() count = %d, err = %v, want 2 commits", count, err)
+	}
+	dark, crash := strings.Index(log, "Add dark mode"), strings.Index(log, "Fix crash on startup")
+	if dark < 0 || crash < dark || strings.Contains(log, "Start the next feature") || !strings.Contains(log, "b.txt | 1 +") {
+		t.Fatalf("unexpected log %q", log)
+	}
+
+	if _, count, err := ReleaseLog(config, "v1.1.0"); err != nil || count != 1 {
+		t.Fatalf("ReleaseLog(v1.1.0) count = %d, err = %v, want the 1 commit since the tag", count, err)
+	}
+	if _, count, err := ReleaseLog(config, "HEAD..HEAD"); err != nil || count != 0 {
+		t.Fatalf("ReleaseLog(HEAD..HEAD) count = %d, err = %v, want 0", count, err)
+	}
+	if _, _, err := ReleaseLog(config, "v9.9.9..HEAD"); err == nil {
+		t.Fatalf("expected an error for an unknown tag")
+	}
+}
@@ -0,0 +1,130 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/dfanso/commit-msg/internal/logging"
+	"github.com/dfanso/commit-msg/internal/scrubber"
+	"github.com/dfanso/commit-msg/pkg/types"
+)
+
+// ResolveCommit returns the full hash of the commit rev refers to.
+func ResolveCommit(config *types.RepoConfig, rev string) (string, error) {
+	cmd := exec.Command("git", "-C", config.Path, "rev-parse", "--verify", "--quiet", rev+"^{commit}")
+	logging.Command(cmd)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("%s is not a commit", rev)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// CommitChanges returns the scrubbed diff introduced by the commit at rev,
+// preceded by its file statistics.
+func CommitChanges(config *types.RepoConfig, rev string) (string, error) {
+	cmd := exec.Command("git", "-C", config.Path, "show", "--format=", "--stat", "--patch", "-M", "--no-color", rev, "--")
+	logging.Command(cmd)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git show %s failed: %v", rev, err)
+	}
+	return scrubber.ScrubDiff("Changes in commit:\n" + string(output)), nil
+}
+
+// RangeChanges returns the scrubbed diff between the two ends of a range such
+// as "main..HEAD", preceded by its file statistics. A single revision selects
+// the changes of that commit, as CommitChanges does.
+func RangeChanges(config *types.RepoConfig, revRange string) (string, error) {
+	if !strings.Contains(revRange, "..") {
+		return CommitChanges(config, revRange)
+	}
+	cmd := exec.Command("git", "-C", config.Path, "diff", "--stat", "--patch", "-M", "--no-color", revRange, "--")
+	logging.Command(cmd)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git diff %s failed: %v", revRange, err)
+	}
+	return scrubber.ScrubDiff("Changes in range " + revRange + ":\n" + string(output)), nil
+}
+
+// Reword replaces the message of the commit at rev, which must be HEAD or
+// one of its ancestors. HEAD is amended in place; older commits are reworded
+// with an interactive rebase driven without an editor, so every later commit
+// is rewritten. History containing merges is refused.
+func Reword(config *types.RepoConfig, rev, message string) error {
+	hash, err := ResolveCommit(config, rev)
+	if err != nil {
+		return err
+	}
+	head, err := ResolveCommit(config, "HEAD")
+	if err != nil {
+		return err
+	}
+
+	if hash == head {
+		cmd := exec.Command("git", "-C", config.Path, "commit", "--amend", "--only", "--allow-empty", "--cleanup=strip", "-F", "-")
+		cmd.Stdin = strings.NewReader(message)
+		return runRewrite(cmd, "git commit --amend")
+	}
+
+	if err := exec.Command("git", "-C", config.Path, "merge-base", "--is-ancestor", hash, head).Run(); err != nil {
+		return fmt.Errorf("%s is not an ancestor of HEAD", rev)
+	}
+	merges, err := exec.Command("git", "-C", config.Path, "rev-list", "--merges", hash+"..HEAD").Output()
+	if err != nil {
+		return fmt.Errorf("git rev-list failed: %v", err)
+	}
+	if len(strings.TrimSpace(string(merges))) > 0 {
+		return fmt.Errorf("cannot reword %s: later history contains merge commits", rev)
+	}
+
+	dir, err := os.MkdirTemp("", "commit-msg-reword-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	messageFile := filepath.Join(dir, "message")
+	if err := os.WriteFile(messageFile, []byte(message+"\n"), 0o600); err != nil {
+		return err
+	}
+
+	// Full hashes in the todo let the sequence editor pick out the target
+	// whatever else the user's rebase settings add to the list; git passes
+	// the file to edit as the last argument of both editors. Local changes
+	// are stashed for the rebase and restored afterwards.
+	base := hash + "^"
+	args := []string{"-C", config.Path, "-c", "core.abbrev=no", "-c", "rebase.updateRefs=false",
+		"rebase", "-i", "--no-rebase-merges", "--no-autosquash", "--autostash"}
+	if _, err := ResolveCommit(config, base); err != nil {
+		args = append(args, "--root")
+	} else {
+		args = append(args, base)
+	}
+	cmd := exec.Command("git", args...)
+	cmd.Env = append(os.Environ(),
+		"GIT_SEQUENCE_EDITOR=sed -i.orig -e 's/^pick "+hash+" /reword "+hash+" /'",
+		"GIT_EDITOR=cp "+shellQuote(messageFile),
+	)
+	if err := runRewrite(cmd, "git rebase"); err != nil {
+		_ = exec.Command("git", "-C", config.Path, "rebase", "--abort").Run()
+		return err
+	}
+	return nil
+}
+
+func runRewrite(cmd *exec.Cmd, name string) error {
+	logging.Command(cmd)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s failed: %v: %s", name, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// shellQuote quotes s for the POSIX shell git runs editor commands with.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,100 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dfanso/commit-msg/pkg/types"
+)
+
+func TestReword(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration-style test in short mode")
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git executable not available")
+	}
+
+	dir := t.TempDir()
+	runGit(t, dir, "init")
+	runGit(t, dir, "config", "user.name", "Test User")
+	runGit(t, dir, "config", "user.email", "test@example.com")
+	for i, name := range []string{"one.txt", "two.txt", "three.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name+"\n"), 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+		runGit(t, dir, "add", name)
+		runGit(t, dir, "commit", "-m", "commit "+string(rune('1'+i)))
+	}
+	config := &types.RepoConfig{Path: dir}
+
+	changes, err := CommitChanges(config, "HEAD~1")
+	if err != nil {
+		t.Fatalf("CommitChanges() error = %v", err)
+	}
+	if !strings.Contains(changes, "two.txt") || strings.Contains(changes, "three.txt") {
+		t.Fatalf("CommitChanges(HEAD~1) = %q, want only two.txt", changes)
+	}
+
+	changes, err = RangeChanges(config, "HEAD~2..HEAD")
+	if err != nil {
+		t.Fatalf("RangeChanges() error = %v", err)
+	}
+	if strings.Contains(changes, "one.txt") || !strings.Contains(changes, "two.txt") || !strings.Contains(changes, "three.txt") {
+		t.Fatalf("RangeChanges(HEAD~2..HEAD) = %q, want two.txt and three.txt", changes)
+	}
+	if _, err := RangeChanges(config, "HEAD..no-such-ref"); err == nil {
+		t.Fatal("expected an error for a range with an unknown revision")
+	}
+
+	if err := Reword(config, "HEAD", "Add three\n\nWith a body."); err != nil {
+		t.Fatalf("Reword(HEAD) error = %v", err)
+	}
+	if got, _ := CommitMessage(config, "HEAD"); got != "Add three\n\nWith a body." {
+		t.Fatalf("HEAD message = %q", got)
+	}
+
+	for _, rev := range []string{"HEAD~1", "HEAD~2"} {
+		if err := Reword(config, rev, "Reworded "+rev); err != nil {
+			t.Fatalf("Reword(%s) error = %v", rev, err)
+		}
+		if got, _ := CommitMessage(config, rev); got != "Reworded "+rev {
+			t.Fatalf("%s message = %q", rev, got)
+		}
+	}
+	if got, _ := CommitMessage(config, "HEAD"); got != "Add three\n\nWith a body." {
+		t.Fatalf("HEAD message after rewording ancestors = %q", got)
+	}
+
+	if _, err := ResolveCommit(config, "no-such-ref"); err == nil {
+		t.Fatal("expected an error for an unknown revision")
+	}
+
+	// Rebase settings that add todo entries before the target, and local
+	// changes, must not get in the way.
+	runGit(t, dir, "config", "rebase.rebaseMerges", "true")
+	runGit(t, dir, "config", "rebase.updateRefs", "true")
+	runGit(t, dir, "branch", "side", "HEAD~1")
+	side, _ := ResolveCommit(config, "side")
+	if err := os.WriteFile(filepath.Join(dir, "one.txt"), []byte("local edit\n"), 0o644); err != nil {
+		t.Fatalf("failed to modify one.txt: %v", err)
+	}
+	if err := Reword(config, "HEAD~1", "Reworded with local changes"); err != nil {
+		t.Fatalf("Reword() with local changes error = %v", err)
+	}
+	if got, _ := CommitMessage(config, "HEAD~1"); got != "Reworded with local changes" {
+		t.Fatalf("HEAD~1 message = %q", got)
+	}
+	if got, _ := CommitMessage(config, "HEAD"); got != "Add three\n\nWith a body." {
+		t.Fatalf("HEAD message after rewording with local changes = %q", got)
+	}
+	if content, _ := os.ReadFile(filepath.Join(dir, "one.txt")); string(content) != "local edit\n" {
+		t.Fatalf("expected local changes to be restored, got %q", content)
+	}
+	if got, _ := ResolveCommit(config, "side"); got != side {
+		t.Fatal("expected other branches to be left alone")
+	}
+}
@@ -0,0 +1,27 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/dfanso/commit-msg/internal/logging"
+	"github.com/dfanso/commit-msg/pkg/types"
+)
+
+// Stash saves the staged and unstaged changes of the repository at
+// config.Path with git stash push -m message, and untracked files too when
+// includeUntracked is set. It returns git's output.
+func Stash(config *types.RepoConfig, message string, includeUntracked bool) (string, error) {
+	args := []string{"-C", config.Path, "stash", "push", "-m", message}
+	if includeUntracked {
+		args = append(args, "--include-untracked")
+	}
+	cmd := exec.Command("git", args...)
+	logging.Command(cmd)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("git stash push failed: %v: %s", err, strings.TrimSpace(string(output)))
+	}
+	return strings.TrimSpace(string(output)), nil
+}
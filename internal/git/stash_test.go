@@ -0,0 +1,60 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dfanso/commit-msg/pkg/types"
+)
+
+func TestStash(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration-style test in short mode")
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git executable not available")
+	}
+
+	dir := t.TempDir()
+	runGit(t, dir, "init")
+	runGit(t, dir, "config", "user.name", "Test User")
+	runGit(t, dir, "config", "user.email", "test@example.com")
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	write("main.go", "package main\n")
+	runGit(t, dir, "add", ".")
+	runGit(t, dir, "commit", "-m", "initial commit")
+	runGit(t, dir, "branch", "-M", "main")
+
+	config := &types.RepoConfig{Path: dir}
+	write("main.go", "package main\n\nfunc main() {}\n")
+	write("draft.md", "draft\n")
+	if _, err := Stash(config, "Add an empty main function", false); err != nil {
+		t.Fatalf("Stash returned error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "draft.md")); err != nil {
+		t.Fatalf("expected the untracked file to stay without includeUntracked: %v", err)
+	}
+
+	if _, err := Stash(config, "Start the draft", true); err != nil {
+		t.Fatalf("Stash returned error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "draft.md")); !os.IsNotExist(err) {
+		t.Fatalf("expected the untracked file to be stashed, stat error = %v", err)
+	}
+
+	list, err := exec.Command("git", "-C", dir, "stash", "list", "--format=%gs").Output()
+	if err != nil {
+		t.Fatalf("git stash list failed: %v", err)
+	}
+	if got, want := strings.TrimSpace(string(list)), "On main: Start the draft\nOn main: Add an empty main function"; got != want {
+		t.Fatalf("stash list = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,57 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/dfanso/commit-msg/internal/logging"
+	"github.com/dfanso/commit-msg/pkg/types"
+)
+
+// AutoCommitReflogAction is the GIT_REFLOG_ACTION used for commits made by
+// commit --auto. It marks them in the reflog without touching the message.
+const AutoCommitReflogAction = "commit (commit-msg)"
+
+// IsAutoCommit reports whether the latest reflog entry of HEAD is a commit
+// made with AutoCommitReflogAction and still points at HEAD.
+func IsAutoCommit(config *types.RepoConfig) (bool, error) {
+	head, err := ResolveCommit(config, "HEAD")
+	if err != nil {
+		return false, err
+	}
+
+	cmd := exec.Command("git", "-C", config.Path, "log", "--walk-reflogs", "-1", "--format=%H%x00%gs", "HEAD")
+	logging.Command(cmd)
+	output, err := cmd.Output()
+	if err != nil {
+		// A repository without a reflog has no record of how HEAD was made.
+		return false, nil
+	}
+	hash, subject, _ := strings.Cut(strings.TrimSpace(string(output)), "\x00")
+	return hash == head && strings.HasPrefix(subject, AutoCommitReflogAction+": "), nil
+}
+
+// IsPushed reports whether HEAD is contained in any remote-tracking branch.
+func IsPushed(config *types.RepoConfig) (bool, error) {
+	cmd := exec.Command("git", "-C", config.Path, "branch", "--remotes", "--contains", "HEAD")
+	logging.Command(cmd)
+	output, err := cmd.Output()
+	if err != nil {
+		return false, fmt.Errorf("git branch --contains failed: %v", err)
+	}
+	return strings.TrimSpace(string(output)) != "", nil
+}
+
+// UndoCommit removes HEAD from the current branch and keeps its changes
+// staged, like git reset --soft HEAD~1. Undoing the root commit leaves the
+// branch unborn.
+func UndoCommit(config *types.RepoConfig) error {
+	var cmd *exec.Cmd
+	if _, err := ResolveCommit(config, "HEAD~1"); err == nil {
+		cmd = exec.Command("git", "-C", config.Path, "reset", "--soft", "HEAD~1")
+	} else {
+		cmd = exec.Command("git", "-C", config.Path, "update-ref", "-d", "HEAD")
+	}
+	return runRewrite(cmd, "git reset --soft")
+}
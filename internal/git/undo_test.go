@@ -0,0 +1,67 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dfanso/commit-msg/pkg/types"
+)
+
+func TestUndoAutoCommit(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration-style test in short mode")
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git executable not available")
+	}
+
+	dir := t.TempDir()
+	runGit(t, dir, "init")
+	runGit(t, dir, "config", "user.name", "Test User")
+	runGit(t, dir, "config", "user.email", "test@example.com")
+	runGit(t, dir, "commit", "--allow-empty", "-m", "first")
+	config := &types.RepoConfig{Path: dir}
+
+	if auto, err := IsAutoCommit(config); err != nil || auto {
+		t.Fatalf("IsAutoCommit() for a manual commit = %v, %v, want false", auto, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "file.txt"), []byte("hello\n"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	runGit(t, dir, "add", "file.txt")
+	cmd := exec.Command("git", "-C", dir, "commit", "-m", "feat: add file")
+	cmd.Env = append(os.Environ(), "GIT_REFLOG_ACTION="+AutoCommitReflogAction)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git commit failed: %v: %s", err, output)
+	}
+
+	if auto, err := IsAutoCommit(config); err != nil || !auto {
+		t.Fatalf("IsAutoCommit() = %v, %v, want true", auto, err)
+	}
+	if pushed, err := IsPushed(config); err != nil || pushed {
+		t.Fatalf("IsPushed() without a remote = %v, %v, want false", pushed, err)
+	}
+
+	if err := UndoCommit(config); err != nil {
+		t.Fatalf("UndoCommit() error = %v", err)
+	}
+	if message, err := CommitMessage(config, "HEAD"); err != nil || strings.TrimSpace(message) != "first" {
+		t.Fatalf("HEAD after undo = %q, %v, want first", message, err)
+	}
+	staged, err := exec.Command("git", "-C", dir, "diff", "--cached", "--name-only").Output()
+	if err != nil {
+		t.Fatalf("git diff --cached failed: %v", err)
+	}
+	if strings.TrimSpace(string(staged)) != "file.txt" {
+		t.Fatalf("staged files after undo = %q, want file.txt", staged)
+	}
+
+	// The reset itself is now the latest reflog entry.
+	if auto, err := IsAutoCommit(config); err != nil || auto {
+		t.Fatalf("IsAutoCommit() after undo = %v, %v, want false", auto, err)
+	}
+}
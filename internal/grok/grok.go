@@ -1,81 +1,92 @@
 package grok
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
-	"io"
 	"net/http"
+	"os"
+	"sort"
+	"strings"
 
 	httpClient "github.com/dfanso/commit-msg/internal/http"
+	"github.com/dfanso/commit-msg/internal/openaicompat"
 	"github.com/dfanso/commit-msg/pkg/types"
 )
 
 const (
-	grokModel          = "grok-3-mini-fast-beta"
-	grokTemperature    = 0
-	grokAPIEndpoint    = "https://api.x.ai/v1/chat/completions"
-	grokContentType    = "application/json"
-	authorizationPrefix = "Bearer "
+	// DefaultModel is the Grok model used when no model was chosen during
+	// setup and GROK_MODEL is unset.
+	DefaultModel = "grok-3-mini"
+	// DefaultEndpoint is xAI's OpenAI-compatible chat completions endpoint.
+	DefaultEndpoint = "https://api.x.ai/v1/chat/completions"
+	grokTemperature = 0
 )
 
-// GenerateCommitMessage calls X.AI's Grok API to create a commit message from
-// the provided Git diff and generation options.
-func GenerateCommitMessage(config *types.Config, changes string, apiKey string, opts *types.GenerationOptions) (string, error) {
-	// Prepare request to X.AI (Grok) API
-	prompt := types.BuildCommitPrompt(changes, opts)
+// client can be overridden in tests; nil uses the shared Grok client
+var client *http.Client
 
-	request := types.GrokRequest{
-
-		Messages: []types.Message{
-			{
-				Role:    "user",
-				Content: prompt,
-			},
-		},
-		Model:       grokModel,
-		Stream:      false,
-		Temperature: grokTemperature,
+func httpClientFor() *http.Client {
+	if client != nil {
+		return client
 	}
+	return httpClient.ClientFor(types.ProviderGrok)
+}
 
-	requestBody, err := json.Marshal(request)
-	if err != nil {
-		return "", err
+// ResolveModel returns the model requested via GROK_MODEL, then saved (the
+// model chosen during setup), then DefaultModel.
+func ResolveModel(saved string) string {
+	if model := strings.TrimSpace(os.Getenv("GROK_MODEL")); model != "" {
+		return model
+	}
+	if saved = strings.TrimSpace(saved); saved != "" {
+		return saved
 	}
+	return DefaultModel
+}
 
-	// Create HTTP request
-	req, err := http.NewRequest("POST", grokAPIEndpoint, bytes.NewBuffer(requestBody))
-	if err != nil {
-		return "", err
+// Endpoint returns the chat completions endpoint: GROK_API_URL, then the
+// grok_api setting carried in config, then DefaultEndpoint.
+func Endpoint(config *types.Config) string {
+	if custom := strings.TrimSpace(os.Getenv("GROK_API_URL")); custom != "" {
+		return custom
 	}
+	if config != nil && strings.TrimSpace(config.GrokAPI) != "" {
+		return strings.TrimSpace(config.GrokAPI)
+	}
+	return DefaultEndpoint
+}
 
-	// Set headers
-	req.Header.Set("Content-Type", grokContentType)
-	req.Header.Set("Authorization", fmt.Sprintf("%s%s", authorizationPrefix, apiKey))
+// ModelsURL returns the model listing next to the chat completions
+// endpoint.
+func ModelsURL(config *types.Config) string {
+	return strings.TrimSuffix(strings.TrimSuffix(Endpoint(config), "/"), "/chat/completions") + "/models"
+}
 
-	client := httpClient.GetClient()
-	resp, err := client.Do(req)
-	if err != nil {
-		return "", err
+// GenerateCommitMessage calls xAI's chat completions API with model, or the
+// model ResolveModel picks when model is empty, and returns the message and
+// the reported token usage.
+func GenerateCommitMessage(ctx context.Context, config *types.Config, changes string, apiKey string, model string, opts *types.GenerationOptions) (string, *types.UsageInfo, error) {
+	if strings.TrimSpace(apiKey) == "" {
+		return "", nil, errors.New("grok: API key is required")
 	}
-	defer resp.Body.Close()
-
-	// Check response status
-	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(bodyBytes))
+	if changes == "" {
+		return "", nil, fmt.Errorf("no changes provided for commit message generation")
 	}
-
-	// Parse response
-	var grokResponse types.GrokResponse
-	if err := json.NewDecoder(resp.Body).Decode(&grokResponse); err != nil {
-		return "", err
+	if model == "" {
+		model = ResolveModel("")
 	}
 
-	// Check if the response follows the expected structure
-	if grokResponse.Message.Content == "" && grokResponse.Choices != nil && len(grokResponse.Choices) > 0 {
-		return grokResponse.Choices[0].Message.Content, nil
-	}
+	request := openaicompat.NewChatRequest(model, changes, grokTemperature, 0, opts)
+	return openaicompat.Complete(ctx, httpClientFor(), types.ProviderGrok, Endpoint(config), apiKey, request)
+}
 
-	return grokResponse.Message.Content, nil
+// ListModels returns the models xAI offers to apiKey, sorted by ID.
+func ListModels(ctx context.Context, config *types.Config, apiKey string) ([]string, error) {
+	models, err := openaicompat.ListModels(ctx, httpClientFor(), types.ProviderGrok, ModelsURL(config), apiKey)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(models)
+	return models, nil
 }
@@ -1,334 +1,182 @@
 package grok
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
-	"strings"
 	"testing"
 
+	"github.com/dfanso/commit-msg/internal/llmerr"
+	"github.com/dfanso/commit-msg/internal/openaicompat"
 	"github.com/dfanso/commit-msg/pkg/types"
 )
 
-func TestGenerateCommitMessage(t *testing.T) {
-	t.Parallel()
-
-	t.Run("returns error for empty API key", func(t *testing.T) {
-		t.Parallel()
+// withTestServer points the grok_api setting at a test server and returns the
+// config to pass along.
+func withTestServer(t *testing.T, handler http.HandlerFunc) *types.Config {
+	t.Helper()
 
-		_, err := GenerateCommitMessage(&types.Config{}, "some changes", "", nil)
-		if err == nil {
-			t.Fatal("expected error for empty API key")
-		}
-	})
+	t.Setenv("GROK_API_URL", "")
+	t.Setenv("GROK_MODEL", "")
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
 
-	t.Run("returns error for empty changes", func(t *testing.T) {
-		t.Parallel()
+	prevClient := client
+	client = srv.Client()
+	t.Cleanup(func() { client = prevClient })
 
-		_, err := GenerateCommitMessage(&types.Config{}, "", "test-key", nil)
-		if err == nil {
-			t.Fatal("expected error for empty changes")
-		}
-	})
+	return &types.Config{GrokAPI: srv.URL + "/v1/chat/completions"}
 }
 
-func TestGenerateCommitMessageWithMockServer(t *testing.T) {
-	t.Parallel()
-
-	t.Run("successful response with message content", func(t *testing.T) {
-		t.Parallel()
-
-		expectedResponse := types.GrokResponse{
-			Message: types.Message{
-				Role:    "assistant",
-				Content: "feat: add new feature",
-			},
-		}
-
-		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			if r.Method != http.MethodPost {
-				t.Fatalf("expected POST method, got %s", r.Method)
-			}
-
-			if got := r.Header.Get("Authorization"); got != "Bearer test-key" {
-				t.Fatalf("expected 'Bearer test-key', got %s", got)
-			}
-
-			if got := r.Header.Get("Content-Type"); got != "application/json" {
-				t.Fatalf("expected 'application/json', got %s", got)
-			}
-
-			var req types.GrokRequest
-			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-				t.Fatalf("failed to decode request: %v", err)
-			}
-
-			if req.Model != "grok-3-mini-fast-beta" {
-				t.Fatalf("expected model 'grok-3-mini-fast-beta', got %s", req.Model)
-			}
-
-			if req.Temperature != 0 {
-				t.Fatalf("expected temperature 0, got %f", req.Temperature)
-			}
-
-			if req.Stream != false {
-				t.Fatalf("expected stream false, got %v", req.Stream)
-			}
-
-			w.Header().Set("Content-Type", "application/json")
-			json.NewEncoder(w).Encode(expectedResponse)
-		}))
-		t.Cleanup(server.Close)
-
-		// This would require modifying the function to accept a URL parameter
-		// For now, we'll test the error handling path
-		_, err := GenerateCommitMessage(&types.Config{}, "some changes", "invalid-key", nil)
-		if err == nil {
-			t.Fatal("expected error for invalid API key")
-		}
-	})
-
-	t.Run("successful response with choices", func(t *testing.T) {
-		t.Parallel()
+func TestGenerateCommitMessageValidatesInput(t *testing.T) {
+	if _, _, err := GenerateCommitMessage(context.Background(), &types.Config{}, "some changes", "", "", nil); err == nil {
+		t.Fatal("expected error for empty API key")
+	}
+	if _, _, err := GenerateCommitMessage(context.Background(), &types.Config{}, "", "test-key", "", nil); err == nil {
+		t.Fatal("expected error for empty changes")
+	}
+}
 
-		expectedResponse := types.GrokResponse{
-			Choices: []types.Choice{
-				{
-					Message: types.Message{
-						Role:    "assistant",
-						Content: "feat: add another feature",
-					},
-				},
-			},
+func TestGenerateCommitMessage(t *testing.T) {
+	config := withTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/chat/completions" {
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL)
 		}
-
-		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			w.Header().Set("Content-Type", "application/json")
-			json.NewEncoder(w).Encode(expectedResponse)
-		}))
-		t.Cleanup(server.Close)
-
-		// This would require modifying the function to accept a URL parameter
-		// For now, we'll test the error handling path
-		_, err := GenerateCommitMessage(&types.Config{}, "some changes", "invalid-key", nil)
-		if err == nil {
-			t.Fatal("expected error for invalid API key")
+		if got := r.Header.Get("Authorization"); got != "Bearer test-key" {
+			t.Fatalf("expected 'Bearer test-key', got %s", got)
 		}
-	})
-
-	t.Run("API error response", func(t *testing.T) {
-		t.Parallel()
-
-		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			w.WriteHeader(http.StatusBadRequest)
-			w.Write([]byte(`{"error": "invalid request"}`))
-		}))
-		t.Cleanup(server.Close)
-
-		// This would require modifying the function to accept a URL parameter
-		// For now, we'll test the error handling path
-		_, err := GenerateCommitMessage(&types.Config{}, "some changes", "invalid-key", nil)
-		if err == nil {
-			t.Fatal("expected error for invalid API key")
+		var req openaicompat.ChatRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
 		}
-	})
-
-	t.Run("invalid JSON response", func(t *testing.T) {
-		t.Parallel()
-
-		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			w.Header().Set("Content-Type", "application/json")
-			w.Write([]byte(`{invalid json}`))
-		}))
-		t.Cleanup(server.Close)
-
-		// This would require modifying the function to accept a URL parameter
-		// For now, we'll test the error handling path
-		_, err := GenerateCommitMessage(&types.Config{}, "some changes", "invalid-key", nil)
-		if err == nil {
-			t.Fatal("expected error for invalid API key")
+		if req.Model != "grok-4" || req.Stream || len(req.Messages) != 2 || req.Messages[1].Role != "user" {
+			t.Fatalf("unexpected request: %+v", req)
 		}
+		_, _ = w.Write([]byte(`{
+			"id": "chatcmpl-1",
+			"object": "chat.completion",
+			"choices": [{"index": 0, "message": {"role": "assistant", "content": "feat: add new feature"}, "finish_reason": "stop"}],
+			"usage": {"prompt_tokens": 200, "completion_tokens": 6, "total_tokens": 206}
+		}`))
 	})
 
-	t.Run("empty response content", func(t *testing.T) {
-		t.Parallel()
+	msg, usage, err := GenerateCommitMessage(context.Background(), config, "diff", "test-key", "grok-4", nil)
+	if err != nil {
+		t.Fatalf("GenerateCommitMessage returned error: %v", err)
+	}
+	if msg != "feat: add new feature" {
+		t.Fatalf("unexpected message: %q", msg)
+	}
+	if usage == nil || usage.PromptTokens != 200 || usage.CompletionTokens != 6 || usage.TotalTokens != 206 {
+		t.Fatalf("unexpected usage: %+v", usage)
+	}
+}
 
-		expectedResponse := types.GrokResponse{
-			Message: types.Message{
-				Role:    "assistant",
-				Content: "",
-			},
+func TestGenerateCommitMessageUsesResolvedModel(t *testing.T) {
+	config := withTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var req openaicompat.ChatRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
 		}
-
-		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			w.Header().Set("Content-Type", "application/json")
-			json.NewEncoder(w).Encode(expectedResponse)
-		}))
-		t.Cleanup(server.Close)
-
-		// This would require modifying the function to accept a URL parameter
-		// For now, we'll test the error handling path
-		_, err := GenerateCommitMessage(&types.Config{}, "some changes", "invalid-key", nil)
-		if err == nil {
-			t.Fatal("expected error for invalid API key")
+		if req.Model != DefaultModel {
+			t.Fatalf("expected model %q, got %q", DefaultModel, req.Model)
 		}
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"fix: bug"}}]}`))
 	})
-}
 
-func TestGenerateCommitMessageIncludesStyleInstructions(t *testing.T) {
-	t.Parallel()
-
-	opts := &types.GenerationOptions{
-		StyleInstruction: "Use a casual tone",
-		Attempt:          2,
-	}
-
-	// Test with invalid key to verify the function processes the options
-	_, err := GenerateCommitMessage(&types.Config{}, "some changes", "invalid-key", opts)
-	if err == nil {
-		t.Fatal("expected error for invalid API key")
+	if _, _, err := GenerateCommitMessage(context.Background(), config, "diff", "test-key", "", nil); err != nil {
+		t.Fatalf("GenerateCommitMessage returned error: %v", err)
 	}
 }
 
-func TestGenerateCommitMessageWithLongChanges(t *testing.T) {
-	t.Parallel()
-
-	// Create a very long changes string
-	longChanges := strings.Repeat("This is a test change. ", 1000)
-
-	// Test with invalid key to verify the function handles long changes
-	_, err := GenerateCommitMessage(&types.Config{}, longChanges, "invalid-key", nil)
-	if err == nil {
-		t.Fatal("expected error for invalid API key")
+func TestGenerateCommitMessageErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   error
+	}{
+		{"unauthorized", http.StatusUnauthorized, `{"code":"Client specified an invalid argument","error":"Incorrect API key provided"}`, llmerr.ErrAuth},
+		{"rate limited", http.StatusTooManyRequests, `{"error":"rate limit exceeded"}`, llmerr.ErrRateLimited},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := withTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			})
+
+			_, _, err := GenerateCommitMessage(context.Background(), config, "diff", "test-key", "", nil)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, err)
+			}
+		})
 	}
 }
 
-func TestGenerateCommitMessageWithSpecialCharacters(t *testing.T) {
-	t.Parallel()
-
-	// Test with special characters in changes
-	changes := `Added special characters: !@#$%^&*()_+-=[]{}|;':",./<>?
-Also added unicode: ñáéíóú 🚀 🎉
-And newlines:
-Line 1
-Line 2
-Line 3`
+func TestGenerateCommitMessageEmptyChoices(t *testing.T) {
+	config := withTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"choices":[]}`))
+	})
 
-	// Test with invalid key to verify the function handles special characters
-	_, err := GenerateCommitMessage(&types.Config{}, changes, "invalid-key", nil)
-	if err == nil {
-		t.Fatal("expected error for invalid API key")
+	if _, _, err := GenerateCommitMessage(context.Background(), config, "diff", "test-key", "", nil); err == nil {
+		t.Fatal("expected error for a response without choices")
 	}
 }
 
-func TestGenerateCommitMessageRequestSerialization(t *testing.T) {
-	t.Parallel()
-
-	req := types.GrokRequest{
-		Messages: []types.Message{
-			{
-				Role:    "user",
-				Content: "test prompt",
-			},
-		},
-		Model:       "grok-3-mini-fast-beta",
-		Stream:      false,
-		Temperature: 0,
-	}
+func TestListModels(t *testing.T) {
+	config := withTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/v1/models" {
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL)
+		}
+		_, _ = w.Write([]byte(`{"object":"list","data":[{"id":"grok-4"},{"id":"grok-3-mini"},{"id":"grok-code-fast-1"}]}`))
+	})
 
-	data, err := json.Marshal(req)
+	models, err := ListModels(context.Background(), config, "test-key")
 	if err != nil {
-		t.Fatalf("failed to marshal request: %v", err)
-	}
-
-	var unmarshaled types.GrokRequest
-	if err := json.Unmarshal(data, &unmarshaled); err != nil {
-		t.Fatalf("failed to unmarshal request: %v", err)
-	}
-
-	if unmarshaled.Model != req.Model {
-		t.Fatalf("expected model %s, got %s", req.Model, unmarshaled.Model)
+		t.Fatalf("ListModels returned error: %v", err)
 	}
-
-	if unmarshaled.Stream != req.Stream {
-		t.Fatalf("expected stream %v, got %v", req.Stream, unmarshaled.Stream)
-	}
-
-	if unmarshaled.Temperature != req.Temperature {
-		t.Fatalf("expected temperature %f, got %f", req.Temperature, unmarshaled.Temperature)
+	want := []string{"grok-3-mini", "grok-4", "grok-code-fast-1"}
+	if len(models) != len(want) {
+		t.Fatalf("unexpected models: %v", models)
 	}
-
-	if len(unmarshaled.Messages) != len(req.Messages) {
-		t.Fatalf("expected %d messages, got %d", len(req.Messages), len(unmarshaled.Messages))
-	}
-}
-
-func TestGenerateCommitMessageResponseDeserialization(t *testing.T) {
-	t.Parallel()
-
-	// Test response with message content
-	jsonData1 := `{
-		"message": {
-			"role": "assistant",
-			"content": "feat: add new feature"
+	for i := range want {
+		if models[i] != want[i] {
+			t.Fatalf("unexpected models: %v", models)
 		}
-	}`
-
-	var resp1 types.GrokResponse
-	if err := json.Unmarshal([]byte(jsonData1), &resp1); err != nil {
-		t.Fatalf("failed to unmarshal response with message: %v", err)
-	}
-
-	if resp1.Message.Content != "feat: add new feature" {
-		t.Fatalf("expected content 'feat: add new feature', got %s", resp1.Message.Content)
 	}
+}
 
-	// Test response with choices
-	jsonData2 := `{
-		"choices": [
-			{
-				"message": {
-					"role": "assistant",
-					"content": "feat: add another feature"
-				}
-			}
-		]
-	}`
-
-	var resp2 types.GrokResponse
-	if err := json.Unmarshal([]byte(jsonData2), &resp2); err != nil {
-		t.Fatalf("failed to unmarshal response with choices: %v", err)
+func TestResolveModel(t *testing.T) {
+	t.Setenv("GROK_MODEL", "")
+	if got := ResolveModel(""); got != DefaultModel {
+		t.Fatalf("expected default model, got %q", got)
 	}
-
-	if len(resp2.Choices) != 1 {
-		t.Fatalf("expected 1 choice, got %d", len(resp2.Choices))
+	if got := ResolveModel("grok-4"); got != "grok-4" {
+		t.Fatalf("expected saved model, got %q", got)
 	}
-
-	if resp2.Choices[0].Message.Content != "feat: add another feature" {
-		t.Fatalf("expected content 'feat: add another feature', got %s", resp2.Choices[0].Message.Content)
+	t.Setenv("GROK_MODEL", "grok-code-fast-1")
+	if got := ResolveModel("grok-4"); got != "grok-code-fast-1" {
+		t.Fatalf("expected GROK_MODEL to win, got %q", got)
 	}
 }
 
-func TestGenerateCommitMessageWithConfig(t *testing.T) {
-	t.Parallel()
-
-	config := &types.Config{
-		// Add any config fields that might be relevant
+func TestEndpoint(t *testing.T) {
+	t.Setenv("GROK_API_URL", "")
+	if got := Endpoint(nil); got != DefaultEndpoint {
+		t.Fatalf("expected default endpoint, got %q", got)
 	}
-
-	// Test with invalid key to verify the function uses config
-	_, err := GenerateCommitMessage(config, "some changes", "invalid-key", nil)
-	if err == nil {
-		t.Fatal("expected error for invalid API key")
+	config := &types.Config{GrokAPI: "https://gateway.example.com/v1/chat/completions"}
+	if got := Endpoint(config); got != config.GrokAPI {
+		t.Fatalf("expected grok_api to be honoured, got %q", got)
 	}
-}
-
-func TestGenerateCommitMessageWithNilConfig(t *testing.T) {
-	t.Parallel()
-
-	// Test with nil config
-	_, err := GenerateCommitMessage(nil, "some changes", "invalid-key", nil)
-	if err == nil {
-		t.Fatal("expected error for invalid API key")
+	if got := ModelsURL(config); got != "https://gateway.example.com/v1/models" {
+		t.Fatalf("unexpected models URL: %q", got)
+	}
+	t.Setenv("GROK_API_URL", "http://localhost:8080/v1/chat/completions")
+	if got := Endpoint(config); got != "http://localhost:8080/v1/chat/completions" {
+		t.Fatalf("expected GROK_API_URL to win, got %q", got)
 	}
 }
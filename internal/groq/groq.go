@@ -9,6 +9,7 @@ import (
 	"os"
 
 	internalHTTP "github.com/dfanso/commit-msg/internal/http"
+	"github.com/dfanso/commit-msg/internal/llmerr"
 	"github.com/dfanso/commit-msg/pkg/types"
 )
 
@@ -22,6 +23,7 @@ type chatRequest struct {
 	Messages    []chatMessage `json:"messages"`
 	Temperature float64       `json:"temperature"`
 	MaxTokens   int           `json:"max_tokens"`
+	Seed        *int64        `json:"seed,omitempty"`
 }
 
 type chatChoice struct {
@@ -32,9 +34,10 @@ type chatResponse struct {
 	Choices []chatChoice `json:"choices"`
 }
 
-// defaultModel uses Groq's recommended general-purpose model as of Oct 2025.
-// If Groq updates their defaults again, override via GROQ_MODEL.
-const defaultModel = "llama-3.3-70b-versatile"
+// DefaultModel uses Groq's recommended general-purpose model as of Oct 2025.
+// It applies only when no model was chosen during setup and GROQ_MODEL is
+// unset.
+const DefaultModel = "llama-3.3-70b-versatile"
 
 const (
 	groqTemperature         = 0.2
@@ -47,30 +50,26 @@ const (
 var (
 	// allow overrides in tests
 	baseURL = "https://api.groq.com/openai/v1/chat/completions"
-	// httpClient can be overridden in tests; defaults to the internal http client
+	// httpClient can be overridden in tests; nil uses the shared Groq client
 	httpClient *http.Client
 )
 
-func init() {
-	httpClient = internalHTTP.GetClient()
-}
-
-// GenerateCommitMessage calls Groq's OpenAI-compatible chat completions API.
-func GenerateCommitMessage(_ *types.Config, changes string, apiKey string, opts *types.GenerationOptions) (string, error) {
+// GenerateCommitMessage calls Groq's OpenAI-compatible chat completions API
+// with model, or the model ResolveModel picks when model is empty.
+func GenerateCommitMessage(_ *types.Config, changes string, apiKey string, model string, opts *types.GenerationOptions) (string, error) {
 	if changes == "" {
 		return "", fmt.Errorf("no changes provided for commit message generation")
 	}
 
 	prompt := types.BuildCommitPrompt(changes, opts)
 
-	model := os.Getenv("GROQ_MODEL")
 	if model == "" {
-		model = defaultModel
+		model = ResolveModel("")
 	}
 
 	payload := chatRequest{
 		Model:       model,
-		Temperature: groqTemperature,
+		Temperature: opts.TemperatureOr(groqTemperature),
 		MaxTokens:   groqMaxTokens,
 		Messages: []chatMessage{
 			{Role: "system", Content: groqSystemMessage},
@@ -78,6 +77,10 @@ func GenerateCommitMessage(_ *types.Config, changes string, apiKey string, opts
 		},
 	}
 
+	if opts != nil {
+		payload.Seed = opts.Seed
+	}
+
 	body, err := json.Marshal(payload)
 	if err != nil {
 		return "", fmt.Errorf("failed to marshal Groq request: %w", err)
@@ -96,7 +99,11 @@ func GenerateCommitMessage(_ *types.Config, changes string, apiKey string, opts
 	req.Header.Set("Content-Type", groqContentType)
 	req.Header.Set("Authorization", fmt.Sprintf("%s%s", groqAuthorizationPrefix, apiKey))
 
-	resp, err := httpClient.Do(req)
+	client := httpClient
+	if client == nil {
+		client = internalHTTP.ClientFor(types.ProviderGroq)
+	}
+	resp, err := client.Do(req)
 	if err != nil {
 		return "", fmt.Errorf("failed to call Groq API: %w", err)
 	}
@@ -108,7 +115,7 @@ func GenerateCommitMessage(_ *types.Config, changes string, apiKey string, opts
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("groq API returned status %d: %s", resp.StatusCode, string(responseBody))
+		return "", llmerr.FromResponse(types.ProviderGroq, resp.StatusCode, resp.Header, responseBody)
 	}
 
 	var completion chatResponse
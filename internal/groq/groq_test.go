@@ -74,7 +74,7 @@ func TestGenerateCommitMessageSuccess(t *testing.T) {
 			t.Fatalf("failed to write response: %v", err)
 		}
 	}, func() {
-		msg, err := GenerateCommitMessage(&types.Config{}, "diff", "test-key", nil)
+		msg, err := GenerateCommitMessage(&types.Config{}, "diff", "test-key", "", nil)
 		if err != nil {
 			t.Fatalf("GenerateCommitMessage returned error: %v", err)
 		}
@@ -90,7 +90,7 @@ func TestGenerateCommitMessageNonOK(t *testing.T) {
 	withTestServer(t, func(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, `{"error":"bad things"}`, http.StatusBadGateway)
 	}, func() {
-		_, err := GenerateCommitMessage(&types.Config{}, "changes", "key", nil)
+		_, err := GenerateCommitMessage(&types.Config{}, "changes", "key", "", nil)
 		if err == nil {
 			t.Fatal("expected error but got nil")
 		}
@@ -101,7 +101,7 @@ func TestGenerateCommitMessageEmptyChanges(t *testing.T) {
 	t.Setenv("GROQ_MODEL", "")
 	t.Setenv("GROQ_API_URL", "")
 
-	if _, err := GenerateCommitMessage(&types.Config{}, "", "key", nil); err == nil {
+	if _, err := GenerateCommitMessage(&types.Config{}, "", "key", "", nil); err == nil {
 		t.Fatal("expected error for empty changes")
 	}
 }
@@ -128,7 +128,7 @@ func TestGenerateCommitMessageIncludesStyleInstruction(t *testing.T) {
 		}
 	}, func() {
 		opts := &types.GenerationOptions{StyleInstruction: "Use a casual tone.", Attempt: 2}
-		if _, err := GenerateCommitMessage(&types.Config{}, "diff", "key", opts); err != nil {
+		if _, err := GenerateCommitMessage(&types.Config{}, "diff", "key", "", opts); err != nil {
 			t.Fatalf("GenerateCommitMessage returned error: %v", err)
 		}
 	})
@@ -143,3 +143,39 @@ func TestGenerateCommitMessageIncludesStyleInstruction(t *testing.T) {
 		t.Fatalf("expected request payload to contain regeneration context, got: %q", recorded)
 	}
 }
+
+func TestGenerateCommitMessageTemperature(t *testing.T) {
+	var temperatures []float64
+
+	withTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var payload capturedRequest
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+		temperatures = append(temperatures, payload.Temperature)
+
+		resp := chatResponse{
+			Choices: []chatChoice{
+				{Message: chatMessage{Role: "assistant", Content: "fix: handle empty input"}},
+			},
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			t.Fatalf("failed to write response: %v", err)
+		}
+	}, func() {
+		if _, err := GenerateCommitMessage(&types.Config{}, "diff", "key", "", nil); err != nil {
+			t.Fatalf("GenerateCommitMessage returned error: %v", err)
+		}
+		temperature := 0.9
+		opts := &types.GenerationOptions{Temperature: &temperature}
+		if _, err := GenerateCommitMessage(&types.Config{}, "diff", "key", "", opts); err != nil {
+			t.Fatalf("GenerateCommitMessage returned error: %v", err)
+		}
+	})
+
+	if len(temperatures) != 2 || temperatures[0] != groqTemperature || temperatures[1] != 0.9 {
+		t.Fatalf("expected temperatures [%v 0.9], got %v", groqTemperature, temperatures)
+	}
+}
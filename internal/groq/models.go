@@ -0,0 +1,109 @@
+package groq
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+
+	internalHTTP "github.com/dfanso/commit-msg/internal/http"
+	"github.com/dfanso/commit-msg/internal/llmerr"
+	"github.com/dfanso/commit-msg/pkg/types"
+)
+
+// Model is a model listed by Groq's /models endpoint.
+type Model struct {
+	ID            string `json:"id"`
+	OwnedBy       string `json:"owned_by"`
+	ContextWindow int    `json:"context_window"`
+	// Active is false for models Groq has retired but still lists.
+	Active *bool `json:"active,omitempty"`
+}
+
+// ResolveModel returns the model requested via GROQ_MODEL, then saved (the
+// model chosen during setup), then DefaultModel.
+func ResolveModel(saved string) string {
+	if model := strings.TrimSpace(os.Getenv("GROQ_MODEL")); model != "" {
+		return model
+	}
+	if saved = strings.TrimSpace(saved); saved != "" {
+		return saved
+	}
+	return DefaultModel
+}
+
+// ModelsURL returns the model listing next to the chat completions endpoint,
+// honouring GROQ_API_URL.
+func ModelsURL() string {
+	endpoint := baseURL
+	if customEndpoint := os.Getenv("GROQ_API_URL"); customEndpoint != "" {
+		endpoint = customEndpoint
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(endpoint, "/"), "/chat/completions") + "/models"
+}
+
+// ListModels returns the active chat models available to apiKey, sorted by
+// ID. Speech models such as Whisper are left out since they cannot write
+// commit messages.
+func ListModels(ctx context.Context, apiKey string) ([]Model, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ModelsURL(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Groq request: %w", err)
+	}
+	req.Header.Set("Authorization", groqAuthorizationPrefix+apiKey)
+
+	client := httpClient
+	if client == nil {
+		client = internalHTTP.ClientFor(types.ProviderGroq)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call Groq API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Groq response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, llmerr.FromResponse(types.ProviderGroq, resp.StatusCode, resp.Header, body)
+	}
+
+	var list struct {
+		Data []Model `json:"data"`
+	}
+	if err := json.Unmarshal(body, &list); err != nil {
+		return nil, fmt.Errorf("failed to decode Groq model list: %w", err)
+	}
+
+	var models []Model
+	for _, m := range list.Data {
+		if m.ID == "" || (m.Active != nil && !*m.Active) || isSpeechModel(m.ID) {
+			continue
+		}
+		models = append(models, m)
+	}
+	sort.Slice(models, func(i, j int) bool { return models[i].ID < models[j].ID })
+	return models, nil
+}
+
+func isSpeechModel(id string) bool {
+	id = strings.ToLower(id)
+	return strings.Contains(id, "whisper") || strings.Contains(id, "tts")
+}
+
+// CheckModel returns an error wrapping llmerr.ErrModelNotFound when model is
+// not among models.
+func CheckModel(model string, models []Model) error {
+	for _, m := range models {
+		if m.ID == model {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: Groq does not offer %q to this account", llmerr.ErrModelNotFound, model)
+}
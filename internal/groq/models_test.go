@@ -0,0 +1,74 @@
+package groq
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/dfanso/commit-msg/internal/llmerr"
+)
+
+func TestListModels(t *testing.T) {
+	withTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/models" {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer test-key" {
+			t.Fatalf("unexpected authorization header: %s", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"object":"list","data":[
+			{"id":"qwen-qwq-32b","owned_by":"Alibaba Cloud","active":true,"context_window":131072},
+			{"id":"llama-3.3-70b-versatile","owned_by":"Meta","active":true,"context_window":131072},
+			{"id":"whisper-large-v3","owned_by":"OpenAI","active":true},
+			{"id":"mixtral-8x7b-32768","owned_by":"Mistral AI","active":false}
+		]}`))
+	}, func() {
+		models, err := ListModels(context.Background(), "test-key")
+		if err != nil {
+			t.Fatalf("ListModels returned error: %v", err)
+		}
+		if len(models) != 2 || models[0].ID != "llama-3.3-70b-versatile" || models[1].ID != "qwen-qwq-32b" {
+			t.Fatalf("ListModels() = %+v, want the two active chat models sorted", models)
+		}
+
+		if err := CheckModel("qwen-qwq-32b", models); err != nil {
+			t.Fatalf("CheckModel() error = %v", err)
+		}
+		if err := CheckModel("mixtral-8x7b-32768", models); !errors.Is(err, llmerr.ErrModelNotFound) {
+			t.Fatalf("CheckModel() error = %v, want ErrModelNotFound", err)
+		}
+	})
+}
+
+func TestListModelsRejectedKey(t *testing.T) {
+	withTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":{"message":"Invalid API Key","type":"invalid_request_error","code":"invalid_api_key"}}`, http.StatusUnauthorized)
+	}, func() {
+		if _, err := ListModels(context.Background(), "bad-key"); !errors.Is(err, llmerr.ErrAuth) {
+			t.Fatalf("ListModels() error = %v, want ErrAuth", err)
+		}
+	})
+}
+
+func TestModelsURL(t *testing.T) {
+	t.Setenv("GROQ_API_URL", "https://proxy.example.com/openai/v1/chat/completions")
+	if got := ModelsURL(); got != "https://proxy.example.com/openai/v1/models" {
+		t.Fatalf("ModelsURL() = %q", got)
+	}
+}
+
+func TestResolveModel(t *testing.T) {
+	t.Setenv("GROQ_MODEL", "")
+	if got := ResolveModel(""); got != DefaultModel {
+		t.Fatalf("ResolveModel(\"\") = %q, want %q", got, DefaultModel)
+	}
+	if got := ResolveModel("qwen-qwq-32b"); got != "qwen-qwq-32b" {
+		t.Fatalf("ResolveModel() = %q, want the saved model", got)
+	}
+	t.Setenv("GROQ_MODEL", "gemma2-9b-it")
+	if got := ResolveModel("qwen-qwq-32b"); got != "gemma2-9b-it" {
+		t.Fatalf("ResolveModel() = %q, want GROQ_MODEL", got)
+	}
+}
@@ -0,0 +1,298 @@
+// Package heuristic builds a conventional commit message from the list of
+// changed files alone, without asking an LLM. The result is deterministic: the
+// same files always produce the same message.
+package heuristic
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Status describes what happened to a file.
+type Status int
+
+const (
+	// Modified files existed before and still exist.
+	Modified Status = iota
+	// Added files are new, including untracked files.
+	Added
+	// Deleted files were removed.
+	Deleted
+	// Renamed files were moved from OldPath, possibly with edits.
+	Renamed
+)
+
+// File is one changed file. Paths are slash-separated and relative to the
+// repository root. Insertions and Deletions are line counts from
+// git diff --numstat; zero when unknown.
+type File struct {
+	Path       string
+	OldPath    string
+	Status     Status
+	Insertions int
+	Deletions  int
+}
+
+// Conventional commit types the generator can choose.
+const (
+	typeFeat     = "feat"
+	typeRefactor = "refactor"
+	typeTest     = "test"
+	typeDocs     = "docs"
+	typeCI       = "ci"
+	typeBuild    = "build"
+	typeChore    = "chore"
+)
+
+// maxBodyFiles caps the per-file list in the message body.
+const maxBodyFiles = 10
+
+// genericDirs are too broad to make a useful scope on their own.
+var genericDirs = map[string]bool{
+	"src": true, "internal": true, "pkg": true, "lib": true, "app": true,
+}
+
+// Generate returns a commit message for files: a conventional subject with
+// a type inferred from the paths, a scope from their common directory, and,
+// for several files, a body listing each file with its line counts. It
+// returns "" when files is empty.
+func Generate(files []File) string {
+	if len(files) == 0 {
+		return ""
+	}
+
+	sorted := sortFiles(files)
+
+	header := CommitType(sorted)
+	// CI and build files live in fixed places that make poor scopes.
+	if scope := Scope(sorted); scope != "" && scope != header && header != typeCI && header != typeBuild {
+		header += "(" + scope + ")"
+	}
+
+	message := header + ": " + subject(sorted)
+	if body := body(sorted); body != "" {
+		message += "\n\n" + body
+	}
+	return message
+}
+
+// sortFiles orders files so the most significant lead the subject: source
+// files before tests and docs, then by lines changed, then by path.
+func sortFiles(files []File) []File {
+	sorted := append([]File(nil), files...)
+	sort.Slice(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if sa, sb := category(a.Path) == "", category(b.Path) == ""; sa != sb {
+			return sa
+		}
+		if ca, cb := a.Insertions+a.Deletions, b.Insertions+b.Deletions; ca != cb {
+			return ca > cb
+		}
+		return a.Path < b.Path
+	})
+	return sorted
+}
+
+// CommitType infers the conventional commit type from the changed paths.
+// When every file falls into one category (docs, tests, CI, build) that
+// category wins; otherwise the type follows what happened to source files.
+func CommitType(files []File) string {
+	kinds := make(map[string]bool)
+	for _, f := range files {
+		kinds[category(f.Path)] = true
+	}
+
+	if len(kinds) == 1 {
+		for kind := range kinds {
+			if kind != "" {
+				return kind
+			}
+		}
+	}
+
+	// Mixed changes are classified by their source files, ignoring the
+	// tests, docs, and config that usually accompany them.
+	added, deleted, modified, renamed := 0, 0, 0, 0
+	for _, f := range files {
+		if category(f.Path) != "" {
+			continue
+		}
+		switch f.Status {
+		case Added:
+			added++
+		case Deleted:
+			deleted++
+		case Renamed:
+			renamed++
+		default:
+			modified++
+		}
+	}
+
+	switch {
+	case added > 0:
+		return typeFeat
+	case modified == 0 && deleted+renamed > 0:
+		return typeRefactor
+	default:
+		return typeChore
+	}
+}
+
+// Scope returns the last element of the deepest directory shared by the
+// source files (or by all files when none are source), or "" when they only
+// share the repository root or a generic directory such as src.
+func Scope(files []File) string {
+	var dirs []string
+	for _, f := range files {
+		if category(f.Path) == "" {
+			dirs = append(dirs, path.Dir(f.Path))
+		}
+	}
+	if len(dirs) == 0 {
+		for _, f := range files {
+			dirs = append(dirs, path.Dir(f.Path))
+		}
+	}
+
+	common := strings.Split(dirs[0], "/")
+	for _, dir := range dirs[1:] {
+		parts := strings.Split(dir, "/")
+		n := 0
+		for n < len(common) && n < len(parts) && common[n] == parts[n] {
+			n++
+		}
+		common = common[:n]
+	}
+
+	if len(common) == 0 {
+		return ""
+	}
+	scope := strings.TrimPrefix(common[len(common)-1], ".")
+	if scope == "" || genericDirs[scope] {
+		return ""
+	}
+	return strings.ToLower(scope)
+}
+
+// category returns the commit type implied by a path on its own, or "" for
+// ordinary source files.
+func category(p string) string {
+	p = strings.ToLower(p)
+	base := path.Base(p)
+	ext := path.Ext(base)
+
+	switch {
+	case strings.HasPrefix(p, ".github/workflows/"), strings.HasPrefix(p, ".circleci/"),
+		base == ".gitlab-ci.yml", base == ".travis.yml", base == "jenkinsfile", base == "azure-pipelines.yml":
+		return typeCI
+	case isTestPath(p, base):
+		return typeTest
+	case ext == ".md" || ext == ".mdx" || ext == ".rst" || ext == ".adoc" || ext == ".txt",
+		strings.HasPrefix(p, "docs/"), strings.Contains(p, "/docs/"),
+		base == "license", base == "authors", base == "contributors":
+		return typeDocs
+	case buildFiles[base], strings.HasPrefix(base, "dockerfile"), strings.HasPrefix(base, "docker-compose"):
+		return typeBuild
+	case base == ".gitignore", base == ".gitattributes", base == ".editorconfig",
+		strings.HasPrefix(base, ".eslintrc"), strings.HasPrefix(base, ".prettierrc"), base == ".golangci.yml":
+		return typeChore
+	}
+	return ""
+}
+
+// buildFiles are manifests and build scripts.
+var buildFiles = map[string]bool{
+	"go.mod": true, "go.sum": true, "makefile": true, "package.json": true,
+	"package-lock.json": true, "yarn.lock": true, "pnpm-lock.yaml": true,
+	"cargo.toml": true, "cargo.lock": true, "pyproject.toml": true,
+	"requirements.txt": true, "setup.py": true, "pom.xml": true,
+	"build.gradle": true, "build.gradle.kts": true, "gemfile": true,
+	"gemfile.lock": true, "composer.json": true, ".goreleaser.yml": true,
+	".goreleaser.yaml": true,
+}
+
+func isTestPath(p, base string) bool {
+	name := strings.TrimSuffix(base, path.Ext(base))
+	return strings.HasSuffix(name, "_test") || strings.HasSuffix(name, ".test") ||
+		strings.HasSuffix(name, ".spec") || strings.HasPrefix(name, "test_") ||
+		strings.HasPrefix(p, "test/") || strings.HasPrefix(p, "tests/") ||
+		strings.Contains(p, "/test/") || strings.Contains(p, "/tests/") ||
+		strings.Contains(p, "__tests__/") || strings.HasPrefix(p, "testdata/") || strings.Contains(p, "/testdata/")
+}
+
+// subject describes the files: a verb chosen from their statuses followed by
+// up to two file names.
+func subject(files []File) string {
+	if len(files) == 1 && files[0].Status == Renamed && files[0].OldPath != "" {
+		return fmt.Sprintf("rename %s to %s", path.Base(files[0].OldPath), path.Base(files[0].Path))
+	}
+
+	verb := "update"
+	if status, same := commonStatus(files); same {
+		switch status {
+		case Added:
+			verb = "add"
+		case Deleted:
+			verb = "remove"
+		case Renamed:
+			verb = "move"
+		}
+	}
+
+	switch len(files) {
+	case 1:
+		return fmt.Sprintf("%s %s", verb, path.Base(files[0].Path))
+	case 2:
+		return fmt.Sprintf("%s %s and %s", verb, path.Base(files[0].Path), path.Base(files[1].Path))
+	default:
+		return fmt.Sprintf("%s %s and %d other files", verb, path.Base(files[0].Path), len(files)-1)
+	}
+}
+
+// body lists each file with what happened to it when the subject only
+// names the first, so "and N other files" is spelled out.
+func body(files []File) string {
+	if len(files) < 3 {
+		return ""
+	}
+
+	var lines []string
+	for i, f := range files {
+		if i == maxBodyFiles {
+			lines = append(lines, fmt.Sprintf("- ... and %d more", len(files)-maxBodyFiles))
+			break
+		}
+		lines = append(lines, "- "+describe(f))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func describe(f File) string {
+	var line string
+	switch f.Status {
+	case Added:
+		line = "add " + f.Path
+	case Deleted:
+		line = "remove " + f.Path
+	case Renamed:
+		line = fmt.Sprintf("rename %s to %s", f.OldPath, f.Path)
+	default:
+		line = "update " + f.Path
+	}
+	if f.Insertions > 0 || f.Deletions > 0 {
+		line += fmt.Sprintf(" (+%d -%d)", f.Insertions, f.Deletions)
+	}
+	return line
+}
+
+func commonStatus(files []File) (Status, bool) {
+	for _, f := range files[1:] {
+		if f.Status != files[0].Status {
+			return Modified, false
+		}
+	}
+	return files[0].Status, true
+}
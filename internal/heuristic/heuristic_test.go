@@ -0,0 +1,115 @@
+package heuristic
+
+import "testing"
+
+func TestGenerate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		files []File
+		want  string
+	}{
+		{
+			name:  "no files",
+			files: nil,
+			want:  "",
+		},
+		{
+			name:  "docs only",
+			files: []File{{Path: "README.md"}, {Path: "docs/setup.md"}},
+			want:  "docs: update README.md and setup.md",
+		},
+		{
+			name:  "tests only",
+			files: []File{{Path: "internal/git/operations_test.go", Status: Added}},
+			want:  "test(git): add operations_test.go",
+		},
+		{
+			name:  "ci workflow",
+			files: []File{{Path: ".github/workflows/build.yml"}},
+			want:  "ci: update build.yml",
+		},
+		{
+			name:  "dependencies",
+			files: []File{{Path: "go.mod"}, {Path: "go.sum"}},
+			want:  "build: update go.mod and go.sum",
+		},
+		{
+			name: "new source with tests is a feature",
+			files: []File{
+				{Path: "README.md", Insertions: 12},
+				{Path: "internal/heuristic/heuristic_test.go", Status: Added, Insertions: 80},
+				{Path: "internal/heuristic/heuristic.go", Status: Added, Insertions: 200},
+			},
+			want: "feat(heuristic): update heuristic.go and 2 other files\n\n" +
+				"- add internal/heuristic/heuristic.go (+200 -0)\n" +
+				"- add internal/heuristic/heuristic_test.go (+80 -0)\n" +
+				"- update README.md (+12 -0)",
+		},
+		{
+			name: "largest change leads",
+			files: []File{
+				{Path: "cmd/cli/root.go", Insertions: 2, Deletions: 1},
+				{Path: "cmd/cli/createMsg.go", Insertions: 40, Deletions: 10},
+			},
+			want: "chore(cli): update createMsg.go and root.go",
+		},
+		{
+			name:  "removed source is a refactor",
+			files: []File{{Path: "legacy/old.go", Status: Deleted}},
+			want:  "refactor(legacy): remove old.go",
+		},
+		{
+			name:  "rename",
+			files: []File{{Path: "pkg/types/prompt.go", OldPath: "pkg/types/prompts.go", Status: Renamed}},
+			want:  "refactor(types): rename prompts.go to prompt.go",
+		},
+		{
+			name:  "root file has no scope",
+			files: []File{{Path: "main.go"}},
+			want:  "chore: update main.go",
+		},
+	}
+
+	for _, tt := range tests {
+		if got := Generate(tt.files); got != tt.want {
+			t.Errorf("%s: Generate() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestScope(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		paths []string
+		want  string
+	}{
+		{[]string{"internal/git/a.go", "internal/git/b.go"}, "git"},
+		{[]string{"internal/git/a.go", "internal/llm/b.go"}, ""},
+		{[]string{"src/main.rs"}, ""},
+		{[]string{"api/handler.go", "api/handler_test.go", "README.md"}, "api"},
+		{[]string{"docs/setup.md", "docs/usage.md"}, "docs"},
+	}
+
+	for _, tt := range tests {
+		files := make([]File, len(tt.paths))
+		for i, p := range tt.paths {
+			files[i] = File{Path: p}
+		}
+		if got := Scope(files); got != tt.want {
+			t.Errorf("Scope(%v) = %q, want %q", tt.paths, got, tt.want)
+		}
+	}
+}
+
+func TestGenerateIsDeterministic(t *testing.T) {
+	t.Parallel()
+
+	a := []File{{Path: "b.go"}, {Path: "a.go"}, {Path: "c.go"}}
+	b := []File{{Path: "c.go"}, {Path: "a.go"}, {Path: "b.go"}}
+	if Generate(a) != Generate(b) {
+		t.Fatalf("order changed the message: %q vs %q", Generate(a), Generate(b))
+	}
+}
@@ -0,0 +1,255 @@
+// Package history keeps a local log of the commit messages generated by
+// commit-msg so they can be found and copied again after the commit that
+// used them is amended away.
+package history
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	StoreUtils "github.com/dfanso/commit-msg/utils"
+)
+
+// FileName is the history file stored alongside config.json.
+const FileName = "history.json"
+
+// DefaultMaxEntries is how many messages are kept when config.json does not
+// say otherwise.
+const DefaultMaxEntries = 500
+
+// Status records what the user did with a message.
+type Status string
+
+const (
+	// Accepted messages were accepted in the review screen.
+	Accepted Status = "accepted"
+	// Rejected messages were generated but regenerated or discarded.
+	Rejected Status = "rejected"
+)
+
+// Settings controls what is recorded. The JSON names are the keys of the
+// "history" section in config.json.
+type Settings struct {
+	// Disabled turns history off entirely.
+	Disabled bool `json:"disabled"`
+	// RecordRejected also keeps candidates that were not accepted.
+	RecordRejected bool `json:"record_rejected"`
+	// MaxEntries bounds how many messages are kept; older ones are dropped.
+	// Zero means DefaultMaxEntries.
+	MaxEntries int `json:"max_entries"`
+}
+
+// Entry is a single recorded message.
+type Entry struct {
+	Message   string    `json:"message"`
+	Repo      string    `json:"repo,omitempty"`
+	Provider  string    `json:"provider,omitempty"`
+	Status    Status    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Store persists history entries in a JSON file, or in the history table
+// of a SQLite database.
+type Store struct {
+	path       string
+	db         *sql.DB
+	maxEntries int
+}
+
+// NewStore returns a store backed by the file at path that keeps at most
+// maxEntries messages. A non-positive maxEntries means DefaultMaxEntries.
+func NewStore(path string, maxEntries int) *Store {
+	if maxEntries <= 0 {
+		maxEntries = DefaultMaxEntries
+	}
+	return &Store{path: path, maxEntries: maxEntries}
+}
+
+// NewSQLiteStore returns a store backed by the history table of db, a
+// database opened with sqlstore.Open, that keeps at most maxEntries
+// messages. A non-positive maxEntries means DefaultMaxEntries.
+func NewSQLiteStore(db *sql.DB, maxEntries int) *Store {
+	store := NewStore("", maxEntries)
+	store.db = db
+	return store
+}
+
+// DefaultPath returns the location of the history file.
+func DefaultPath() (string, error) {
+	configPath, err := StoreUtils.GetConfigPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(configPath), FileName), nil
+}
+
+// Load returns every stored entry, oldest first. A missing file yields no
+// entries.
+func (s *Store) Load() ([]Entry, error) {
+	if s.db != nil {
+		return s.loadRows()
+	}
+
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+
+	var entries []Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse history %s: %w", s.path, err)
+	}
+	return entries, nil
+}
+
+// Add appends entries, skipping empty messages and filling in CreatedAt
+// when unset. Only the newest entries up to the store's limit are kept.
+func (s *Store) Add(entries ...Entry) error {
+	var added []Entry
+	for _, entry := range entries {
+		entry.Message = strings.TrimSpace(entry.Message)
+		if entry.Message == "" {
+			continue
+		}
+		if entry.CreatedAt.IsZero() {
+			entry.CreatedAt = time.Now().UTC()
+		}
+		added = append(added, entry)
+	}
+	if len(added) == 0 {
+		return nil
+	}
+	if s.db != nil {
+		return s.insertRows(added)
+	}
+
+	stored, err := s.Load()
+	if err != nil {
+		return err
+	}
+	stored = append(stored, added...)
+	if len(stored) > s.maxEntries {
+		stored = stored[len(stored)-s.maxEntries:]
+	}
+	return s.save(stored)
+}
+
+// Search returns the entries whose message, repository, or provider
+// contains query, ignoring case, newest first. An empty query matches every
+// entry. Rejected entries are left out unless includeRejected is set.
+func (s *Store) Search(query string, includeRejected bool) ([]Entry, error) {
+	entries, err := s.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	query = strings.ToLower(strings.TrimSpace(query))
+	var matches []Entry
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		if entry.Status == Rejected && !includeRejected {
+			continue
+		}
+		if query != "" &&
+			!strings.Contains(strings.ToLower(entry.Message), query) &&
+			!strings.Contains(strings.ToLower(entry.Repo), query) &&
+			!strings.Contains(strings.ToLower(entry.Provider), query) {
+			continue
+		}
+		matches = append(matches, entry)
+	}
+	return matches, nil
+}
+
+// Clear removes every stored entry.
+func (s *Store) Clear() error {
+	if s.db != nil {
+		if _, err := s.db.Exec(`DELETE FROM history`); err != nil {
+			return fmt.Errorf("failed to clear history: %w", err)
+		}
+		return nil
+	}
+	if err := os.Remove(s.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove history: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) save(entries []Entry) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal history: %w", err)
+	}
+
+	if err := os.WriteFile(s.path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) loadRows() ([]Entry, error) {
+	rows, err := s.db.Query(`SELECT message, repo, provider, status, created_at FROM history ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []Entry
+	for rows.Next() {
+		var entry Entry
+		var createdAt string
+		if err := rows.Scan(&entry.Message, &entry.Repo, &entry.Provider, &entry.Status, &createdAt); err != nil {
+			return nil, fmt.Errorf("failed to read history: %w", err)
+		}
+		entry.CreatedAt, err = time.Parse(time.RFC3339Nano, createdAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse history timestamp %q: %w", createdAt, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	return entries, nil
+}
+
+// insertRows appends entries and drops all but the newest maxEntries in one
+// transaction.
+func (s *Store) insertRows(entries []Entry) (err error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start history transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	for _, entry := range entries {
+		if _, err := tx.Exec(`INSERT INTO history (message, repo, provider, status, created_at) VALUES (?, ?, ?, ?, ?)`,
+			entry.Message, entry.Repo, entry.Provider, string(entry.Status), entry.CreatedAt.Format(time.RFC3339Nano)); err != nil {
+			return fmt.Errorf("failed to write history: %w", err)
+		}
+	}
+	if _, err := tx.Exec(`DELETE FROM history WHERE id NOT IN (SELECT id FROM history ORDER BY id DESC LIMIT ?)`, s.maxEntries); err != nil {
+		return fmt.Errorf("failed to trim history: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit history transaction: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package history
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStoreAddAndSearch(t *testing.T) {
+	t.Parallel()
+
+	store := NewStore(filepath.Join(t.TempDir(), FileName), 0)
+	base := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	if err := store.Add(
+		Entry{Message: "feat: add login", Repo: "/repo/a", Provider: "OpenAI", Status: Accepted, CreatedAt: base},
+		Entry{Message: "  ", Repo: "/repo/a", Status: Accepted},
+		Entry{Message: "fix: login redirect", Repo: "/repo/b", Provider: "Claude", Status: Rejected, CreatedAt: base.Add(time.Minute)},
+		Entry{Message: "docs: explain setup", Repo: "/repo/b", Provider: "Claude", Status: Accepted, CreatedAt: base.Add(2 * time.Minute)},
+	); err != nil {
+		t.Fatalf("Add error: %v", err)
+	}
+
+	all, err := store.Search("", false)
+	if err != nil {
+		t.Fatalf("Search error: %v", err)
+	}
+	if len(all) != 2 || all[0].Message != "docs: explain setup" || all[1].Message != "feat: add login" {
+		t.Fatalf("Search(\"\") = %+v, want accepted entries newest first", all)
+	}
+
+	login, err := store.Search("LOGIN", true)
+	if err != nil {
+		t.Fatalf("Search error: %v", err)
+	}
+	if len(login) != 2 || login[0].Status != Rejected || login[1].Message != "feat: add login" {
+		t.Fatalf("Search(LOGIN) = %+v", login)
+	}
+
+	claude, err := store.Search("claude", false)
+	if err != nil {
+		t.Fatalf("Search error: %v", err)
+	}
+	if len(claude) != 1 || claude[0].Message != "docs: explain setup" {
+		t.Fatalf("Search(claude) = %+v", claude)
+	}
+}
+
+func TestStoreKeepsNewestEntries(t *testing.T) {
+	t.Parallel()
+
+	store := NewStore(filepath.Join(t.TempDir(), FileName), 2)
+	for _, message := range []string{"one", "two", "three"} {
+		if err := store.Add(Entry{Message: message, Status: Accepted}); err != nil {
+			t.Fatalf("Add(%q) error: %v", message, err)
+		}
+	}
+
+	entries, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Message != "two" || entries[1].Message != "three" {
+		t.Fatalf("Load() = %+v, want the two newest entries", entries)
+	}
+	if entries[1].CreatedAt.IsZero() {
+		t.Fatal("expected CreatedAt to be filled in")
+	}
+
+	if err := store.Clear(); err != nil {
+		t.Fatalf("Clear error: %v", err)
+	}
+	if entries, err := store.Load(); err != nil || len(entries) != 0 {
+		t.Fatalf("Load() after Clear = %+v, %v", entries, err)
+	}
+}
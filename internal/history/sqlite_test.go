@@ -0,0 +1,50 @@
+//go:build sqlite
+
+package history
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dfanso/commit-msg/internal/sqlstore"
+)
+
+func TestSQLiteStoreAddAndTrim(t *testing.T) {
+	db, err := sqlstore.Open(filepath.Join(t.TempDir(), sqlstore.FileName))
+	if err != nil {
+		t.Fatalf("Open error: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	store := NewSQLiteStore(db, 2)
+	base := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := store.Add(
+		Entry{Message: "feat: one", Status: Accepted, CreatedAt: base},
+		Entry{Message: " ", Status: Accepted},
+		Entry{Message: "feat: two", Repo: "/repo", Provider: "OpenAI", Status: Rejected, CreatedAt: base.Add(time.Minute)},
+	); err != nil {
+		t.Fatalf("Add error: %v", err)
+	}
+	if err := store.Add(Entry{Message: "feat: three", Status: Accepted, CreatedAt: base.Add(2 * time.Minute)}); err != nil {
+		t.Fatalf("Add error: %v", err)
+	}
+
+	entries, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Message != "feat: two" || entries[1].Message != "feat: three" {
+		t.Fatalf("Load() = %+v, want the two newest entries oldest first", entries)
+	}
+	if entries[0].Repo != "/repo" || entries[0].Status != Rejected || !entries[0].CreatedAt.Equal(base.Add(time.Minute)) {
+		t.Fatalf("entry fields were not kept: %+v", entries[0])
+	}
+
+	if err := store.Clear(); err != nil {
+		t.Fatalf("Clear error: %v", err)
+	}
+	if entries, err := store.Load(); err != nil || len(entries) != 0 {
+		t.Fatalf("Load() after Clear = %+v, %v", entries, err)
+	}
+}
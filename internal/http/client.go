@@ -1,51 +1,189 @@
 package http
 
 import (
+	"crypto/sha256"
 	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/dfanso/commit-msg/pkg/types"
 )
 
-var (
-	clientOnce   sync.Once
-	sharedClient *http.Client
+// Defaults used when no settings are configured.
+const (
+	DefaultTimeout         = 30 * time.Second
+	DefaultOllamaTimeout   = 10 * time.Minute
+	DefaultMaxIdleConns    = 10
+	DefaultIdleConnTimeout = 30 * time.Second
+	DefaultKeepAlive       = 30 * time.Second
+)
+
+// Settings tunes the HTTP clients used to reach LLM providers and issue
+// trackers. Zero fields use the defaults above.
+type Settings struct {
+	// Timeout bounds a whole request for cloud providers.
+	Timeout time.Duration
+	// MaxIdleConns caps the idle connections kept in each client's pool.
+	MaxIdleConns int
+	// IdleConnTimeout is how long an idle pooled connection is kept.
+	IdleConnTimeout time.Duration
+	// KeepAlive is the TCP keep-alive period of new connections.
+	KeepAlive time.Duration
+	// ProviderTimeouts overrides Timeout for individual providers. Ollama
+	// defaults to DefaultOllamaTimeout because local inference is slow.
+	ProviderTimeouts map[types.LLMProvider]time.Duration
+	// PinnedCertificates maps a provider to the SHA-256 fingerprint, as
+	// returned by NormalizeFingerprint, of the certificate its server must
+	// present. A pinned certificate is trusted even when it is self-signed,
+	// as on a remote Ollama server, and any other certificate is refused.
+	PinnedCertificates map[types.LLMProvider]string
+	// Wrap, when set, wraps the transport of each client, as request
+	// recording and replay do. It is set by the program, not read from
+	// config.json.
+	Wrap func(provider types.LLMProvider, next http.RoundTripper) http.RoundTripper
+}
 
-	ollamaClientOnce sync.Once
-	ollamaClient     *http.Client
+// NormalizeFingerprint returns a SHA-256 certificate fingerprint as 64
+// lowercase hex digits, accepting the colon-separated form printed by
+// openssl x509 -noout -fingerprint -sha256.
+func NormalizeFingerprint(fingerprint string) (string, error) {
+	normalized := strings.ToLower(strings.NewReplacer(":", "", " ", "").Replace(strings.TrimSpace(fingerprint)))
+	if decoded, err := hex.DecodeString(normalized); err != nil || len(decoded) != sha256.Size {
+		return "", fmt.Errorf("invalid SHA-256 fingerprint %q: want 64 hex digits", fingerprint)
+	}
+	return normalized, nil
+}
+
+// WithDefaults returns s with zero fields replaced by the defaults.
+func (s Settings) WithDefaults() Settings {
+	if s.Timeout <= 0 {
+		s.Timeout = DefaultTimeout
+	}
+	if s.MaxIdleConns <= 0 {
+		s.MaxIdleConns = DefaultMaxIdleConns
+	}
+	if s.IdleConnTimeout <= 0 {
+		s.IdleConnTimeout = DefaultIdleConnTimeout
+	}
+	if s.KeepAlive <= 0 {
+		s.KeepAlive = DefaultKeepAlive
+	}
+	return s
+}
+
+// TimeoutFor returns the request timeout for provider.
+func (s Settings) TimeoutFor(provider types.LLMProvider) time.Duration {
+	s = s.WithDefaults()
+	if timeout, ok := s.ProviderTimeouts[provider]; ok && timeout > 0 {
+		return timeout
+	}
+	if provider == types.ProviderOllama {
+		return DefaultOllamaTimeout
+	}
+	return s.Timeout
+}
+
+var (
+	clientsMu sync.Mutex
+	settings  Settings
+	clients   = map[types.LLMProvider]*http.Client{}
 )
 
-// createTransport creates a shared HTTP transport with optimized settings
-func createTransport() *http.Transport {
-	return &http.Transport{
+// Configure replaces the settings used by ClientFor. Clients handed out
+// earlier keep their old settings; it is meant to be called once at startup.
+func Configure(s Settings) {
+	clientsMu.Lock()
+	defer clientsMu.Unlock()
+	settings = s
+	clients = map[types.LLMProvider]*http.Client{}
+}
+
+// ClientFor returns the HTTP client for provider, creating it on first use.
+// Each provider gets its own client and connection pool. The empty provider
+// names the general-purpose client used for everything else.
+func ClientFor(provider types.LLMProvider) *http.Client {
+	clientsMu.Lock()
+	defer clientsMu.Unlock()
+
+	if client, ok := clients[provider]; ok {
+		return client
+	}
+	client := NewClient(settings, provider)
+	clients[provider] = client
+	return client
+}
+
+// NewClient builds a client for provider from s without caching it.
+func NewClient(s Settings, provider types.LLMProvider) *http.Client {
+	s = s.WithDefaults()
+	var transport http.RoundTripper = createTransport(s, provider)
+	if s.Wrap != nil {
+		transport = s.Wrap(provider, transport)
+	}
+	return &http.Client{
+		Timeout:   s.TimeoutFor(provider),
+		Transport: transport,
+	}
+}
+
+// createTransport creates an HTTP transport with the pool settings from s
+// and the certificate pinned for provider, if any.
+func createTransport(s Settings, provider types.LLMProvider) *http.Transport {
+	transport := &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: s.KeepAlive,
+		}).DialContext,
 		TLSHandshakeTimeout: 10 * time.Second,
-		MaxIdleConns:        10,
-		IdleConnTimeout:     30 * time.Second,
+		MaxIdleConns:        s.MaxIdleConns,
+		IdleConnTimeout:     s.IdleConnTimeout,
 		DisableCompression:  true,
 		TLSClientConfig: &tls.Config{
 			InsecureSkipVerify: false,
 		},
 	}
+	if fingerprint, ok := s.PinnedCertificates[provider]; ok {
+		transport.TLSClientConfig = pinnedTLSConfig(fingerprint)
+	}
+	return transport
 }
 
-// GetClient returns a shared HTTP client with optimized settings for cloud APIs
+// errCertificateMismatch is returned when a server presents a certificate
+// other than the pinned one.
+var errCertificateMismatch = errors.New("server certificate does not match the pinned fingerprint")
+
+// pinnedTLSConfig trusts exactly the certificate with the given fingerprint.
+// Chain verification is replaced by the pin, so self-signed certificates
+// work.
+func pinnedTLSConfig(fingerprint string) *tls.Config {
+	return &tls.Config{
+		InsecureSkipVerify: true,
+		VerifyConnection: func(state tls.ConnectionState) error {
+			if len(state.PeerCertificates) == 0 {
+				return errCertificateMismatch
+			}
+			sum := sha256.Sum256(state.PeerCertificates[0].Raw)
+			if hex.EncodeToString(sum[:]) != fingerprint {
+				return errCertificateMismatch
+			}
+			return nil
+		},
+	}
+}
+
+// GetClient returns the general-purpose client for cloud APIs.
 func GetClient() *http.Client {
-	clientOnce.Do(func() {
-		sharedClient = &http.Client{
-			Timeout:   30 * time.Second,
-			Transport: createTransport(),
-		}
-	})
-	return sharedClient
+	return ClientFor("")
 }
 
-// GetOllamaClient returns an HTTP client with extended timeout for local Ollama inference
+// GetOllamaClient returns the client with the extended timeout for local
+// Ollama inference.
 func GetOllamaClient() *http.Client {
-	ollamaClientOnce.Do(func() {
-		ollamaClient = &http.Client{
-			Timeout:   10 * time.Minute, // 10 minutes for local inference
-			Transport: createTransport(),
-		}
-	})
-	return ollamaClient
+	return ClientFor(types.ProviderOllama)
 }
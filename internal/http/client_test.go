@@ -1,10 +1,16 @@
 package http
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"net/http"
+	"net/http/httptest"
+	"strings"
 	"sync"
 	"testing"
 	"time"
+
+	"github.com/dfanso/commit-msg/pkg/types"
 )
 
 func TestGetClient(t *testing.T) {
@@ -341,3 +347,132 @@ func TestClientTimeoutValues(t *testing.T) {
 		t.Fatal("expected regular client timeout to be shorter than ollama client timeout")
 	}
 }
+
+func TestClientForIsPerProvider(t *testing.T) {
+	t.Parallel()
+
+	claude := ClientFor(types.ProviderClaude)
+	if claude != ClientFor(types.ProviderClaude) {
+		t.Fatal("expected the same client for repeated calls with one provider")
+	}
+	if claude == ClientFor(types.ProviderGroq) {
+		t.Fatal("expected separate clients for different providers")
+	}
+	if claude.Transport == ClientFor(types.ProviderGroq).Transport {
+		t.Fatal("expected separate connection pools for different providers")
+	}
+}
+
+func TestNewClientSettings(t *testing.T) {
+	t.Parallel()
+
+	settings := Settings{
+		Timeout:         45 * time.Second,
+		MaxIdleConns:    4,
+		IdleConnTimeout: time.Minute,
+		ProviderTimeouts: map[types.LLMProvider]time.Duration{
+			types.ProviderClaude: 2 * time.Minute,
+		},
+	}
+
+	tests := []struct {
+		provider types.LLMProvider
+		want     time.Duration
+	}{
+		{types.ProviderOpenAI, 45 * time.Second},
+		{types.ProviderClaude, 2 * time.Minute},
+		{types.ProviderOllama, DefaultOllamaTimeout},
+	}
+	for _, tt := range tests {
+		client := NewClient(settings, tt.provider)
+		if client.Timeout != tt.want {
+			t.Errorf("%s timeout = %v, want %v", tt.provider, client.Timeout, tt.want)
+		}
+		transport := client.Transport.(*http.Transport)
+		if transport.MaxIdleConns != 4 || transport.IdleConnTimeout != time.Minute {
+			t.Errorf("%s pool = %d/%v, want 4/1m", tt.provider, transport.MaxIdleConns, transport.IdleConnTimeout)
+		}
+	}
+
+	if got := NewClient(Settings{}, types.ProviderOpenAI).Timeout; got != DefaultTimeout {
+		t.Fatalf("default timeout = %v, want %v", got, DefaultTimeout)
+	}
+}
+
+func TestNewClientWrapsTransport(t *testing.T) {
+	t.Parallel()
+
+	var wrapped types.LLMProvider
+	settings := Settings{
+		Wrap: func(provider types.LLMProvider, next http.RoundTripper) http.RoundTripper {
+			wrapped = provider
+			if _, ok := next.(*http.Transport); !ok {
+				t.Errorf("expected the pooled transport to be wrapped, got %T", next)
+			}
+			return roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				return next.RoundTrip(req)
+			})
+		},
+	}
+
+	client := NewClient(settings, types.ProviderClaude)
+	if wrapped != types.ProviderClaude {
+		t.Fatalf("expected the Claude transport to be wrapped, got %q", wrapped)
+	}
+	if _, ok := client.Transport.(roundTripFunc); !ok {
+		t.Fatalf("expected the wrapping transport, got %T", client.Transport)
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestNormalizeFingerprint(t *testing.T) {
+	t.Parallel()
+
+	want := strings.Repeat("ab", 32)
+	for _, input := range []string{want, strings.ToUpper(want), strings.TrimSuffix(strings.Repeat("AB:", 32), ":")} {
+		got, err := NormalizeFingerprint(input)
+		if err != nil || got != want {
+			t.Errorf("NormalizeFingerprint(%q) = %q, %v; want %q", input, got, err, want)
+		}
+	}
+	for _, input := range []string{"", "abcd", strings.Repeat("zz", 32)} {
+		if _, err := NormalizeFingerprint(input); err == nil {
+			t.Errorf("NormalizeFingerprint(%q) succeeded, want an error", input)
+		}
+	}
+}
+
+func TestPinnedCertificate(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	sum := sha256.Sum256(server.Certificate().Raw)
+	pinned := NewClient(Settings{PinnedCertificates: map[types.LLMProvider]string{
+		types.ProviderOllama: hex.EncodeToString(sum[:]),
+	}}, types.ProviderOllama)
+	resp, err := pinned.Get(server.URL)
+	if err != nil {
+		t.Fatalf("request to the pinned self-signed server failed: %v", err)
+	}
+	resp.Body.Close()
+
+	wrong := NewClient(Settings{PinnedCertificates: map[types.LLMProvider]string{
+		types.ProviderOllama: strings.Repeat("00", 32),
+	}}, types.ProviderOllama)
+	if _, err := wrong.Get(server.URL); err == nil {
+		t.Fatal("expected a certificate that does not match the pin to be refused")
+	}
+
+	if _, err := NewClient(Settings{}, types.ProviderOllama).Get(server.URL); err == nil {
+		t.Fatal("expected an unpinned self-signed certificate to be refused")
+	}
+}
@@ -0,0 +1,293 @@
+// Package huggingface generates commit messages with models served by
+// Hugging Face, either through the serverless Inference Providers router or
+// a dedicated Inference Endpoint such as a fine-tuned commit-message model.
+package huggingface
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+
+	internalHTTP "github.com/dfanso/commit-msg/internal/http"
+	"github.com/dfanso/commit-msg/internal/llmerr"
+	"github.com/dfanso/commit-msg/pkg/types"
+)
+
+const (
+	// DefaultModel is requested from the serverless router when no model
+	// was chosen during setup and HF_MODEL is unset.
+	DefaultModel = "meta-llama/Llama-3.3-70B-Instruct"
+	// DefaultEndpoint is the OpenAI-compatible serverless router.
+	DefaultEndpoint = "https://router.huggingface.co/v1"
+
+	hfTemperature         = 0.2
+	hfMaxTokens           = 200
+	hfSystemMessage       = "You are an assistant that writes clear, concise git commit messages."
+	hfContentType         = "application/json"
+	hfAuthorizationPrefix = "Bearer "
+)
+
+// The APIs an endpoint can speak.
+const (
+	// APIChat is the OpenAI-compatible chat completions API served by the
+	// router and by endpoints running Text Generation Inference or vLLM.
+	APIChat = "chat"
+	// APITextGeneration is the raw text-generation task API, for endpoints
+	// serving base models without a chat template.
+	APITextGeneration = "text-generation"
+)
+
+// Options are the settings read from the "huggingface" section of
+// config.json.
+type Options struct {
+	// Endpoint is the URL of a dedicated Inference Endpoint; empty uses the
+	// serverless router. HF_ENDPOINT_URL overrides it.
+	Endpoint string `json:"endpoint,omitempty"`
+	// API selects APIChat (the default) or APITextGeneration.
+	API string `json:"api,omitempty"`
+	// MaxNewTokens bounds the response; zero uses 200.
+	MaxNewTokens int `json:"max_new_tokens,omitempty"`
+}
+
+// Validate reports settings that cannot work.
+func (o Options) Validate() error {
+	switch o.API {
+	case "", APIChat, APITextGeneration:
+	default:
+		return fmt.Errorf("invalid huggingface.api %q: use %q or %q", o.API, APIChat, APITextGeneration)
+	}
+	if o.Endpoint != "" {
+		if err := ValidateEndpoint(o.Endpoint); err != nil {
+			return err
+		}
+	}
+	if o.MaxNewTokens < 0 {
+		return fmt.Errorf("invalid huggingface.max_new_tokens %d: must not be negative", o.MaxNewTokens)
+	}
+	return nil
+}
+
+// ValidateEndpoint checks that raw is an http or https URL with a host.
+func ValidateEndpoint(raw string) error {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return fmt.Errorf("invalid Hugging Face endpoint %q: %w", raw, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid Hugging Face endpoint %q: use an http or https URL", raw)
+	}
+	return nil
+}
+
+var (
+	optionsMu sync.RWMutex
+	options   Options
+	// httpClient can be overridden in tests; nil uses the shared client
+	httpClient *http.Client
+)
+
+// Configure sets the options applied to every subsequent request.
+func Configure(opts Options) {
+	optionsMu.Lock()
+	defer optionsMu.Unlock()
+	options = opts
+}
+
+func configured() Options {
+	optionsMu.RLock()
+	defer optionsMu.RUnlock()
+	return options
+}
+
+// ResolveModel returns the model requested via HF_MODEL, then saved (the
+// model chosen during setup), then DefaultModel.
+func ResolveModel(saved string) string {
+	if model := strings.TrimSpace(os.Getenv("HF_MODEL")); model != "" {
+		return model
+	}
+	if saved = strings.TrimSpace(saved); saved != "" {
+		return saved
+	}
+	return DefaultModel
+}
+
+// endpoint returns the configured endpoint, overridden by HF_ENDPOINT_URL.
+func endpoint(opts Options) string {
+	if custom := strings.TrimSpace(os.Getenv("HF_ENDPOINT_URL")); custom != "" {
+		return custom
+	}
+	if opts.Endpoint != "" {
+		return opts.Endpoint
+	}
+	return DefaultEndpoint
+}
+
+// chatURL turns an endpoint into its chat completions URL. Dedicated
+// endpoints are usually given as their base URL, which serves the API
+// under /v1.
+func chatURL(endpoint string) string {
+	endpoint = strings.TrimRight(endpoint, "/")
+	switch {
+	case strings.HasSuffix(endpoint, "/chat/completions"):
+		return endpoint
+	case strings.HasSuffix(endpoint, "/v1"):
+		return endpoint + "/chat/completions"
+	default:
+		return endpoint + "/v1/chat/completions"
+	}
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatRequest struct {
+	Model       string        `json:"model"`
+	Messages    []chatMessage `json:"messages"`
+	Temperature float64       `json:"temperature"`
+	MaxTokens   int           `json:"max_tokens"`
+	Seed        *int64        `json:"seed,omitempty"`
+}
+
+type chatResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+}
+
+type textGenerationParameters struct {
+	MaxNewTokens   int     `json:"max_new_tokens"`
+	Temperature    float64 `json:"temperature,omitempty"`
+	Seed           *int64  `json:"seed,omitempty"`
+	ReturnFullText bool    `json:"return_full_text"`
+}
+
+type textGenerationRequest struct {
+	Inputs     string                   `json:"inputs"`
+	Parameters textGenerationParameters `json:"parameters"`
+}
+
+type textGenerationOutput struct {
+	GeneratedText string `json:"generated_text"`
+}
+
+// GenerateCommitMessage asks the configured Hugging Face endpoint for a
+// commit message, using model, or the model ResolveModel picks when model is
+// empty. Dedicated endpoints serve a single model and ignore it.
+func GenerateCommitMessage(_ *types.Config, changes string, token string, model string, opts *types.GenerationOptions) (string, error) {
+	if changes == "" {
+		return "", fmt.Errorf("no changes provided for commit message generation")
+	}
+	if model == "" {
+		model = ResolveModel("")
+	}
+
+	cfg := configured()
+	maxTokens := cfg.MaxNewTokens
+	if maxTokens == 0 {
+		maxTokens = hfMaxTokens
+	}
+	var seed *int64
+	if opts != nil {
+		seed = opts.Seed
+	}
+	prompt := types.BuildCommitPrompt(changes, opts)
+
+	var target string
+	var payload interface{}
+	if cfg.API == APITextGeneration {
+		target = endpoint(cfg)
+		payload = textGenerationRequest{
+			Inputs: hfSystemMessage + "\n\n" + prompt,
+			Parameters: textGenerationParameters{
+				MaxNewTokens: maxTokens,
+				Temperature:  opts.TemperatureOr(hfTemperature),
+				Seed:         seed,
+			},
+		}
+	} else {
+		target = chatURL(endpoint(cfg))
+		payload = chatRequest{
+			Model:       model,
+			Temperature: opts.TemperatureOr(hfTemperature),
+			MaxTokens:   maxTokens,
+			Seed:        seed,
+			Messages: []chatMessage{
+				{Role: "system", Content: hfSystemMessage},
+				{Role: "user", Content: prompt},
+			},
+		}
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal Hugging Face request: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create Hugging Face request: %w", err)
+	}
+	req.Header.Set("Content-Type", hfContentType)
+	req.Header.Set("Authorization", hfAuthorizationPrefix+token)
+
+	client := httpClient
+	if client == nil {
+		client = internalHTTP.ClientFor(types.ProviderHuggingFace)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to call Hugging Face API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	responseBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read Hugging Face response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", llmerr.FromResponse(types.ProviderHuggingFace, resp.StatusCode, resp.Header, responseBody)
+	}
+
+	var message string
+	if cfg.API == APITextGeneration {
+		message, err = decodeTextGeneration(responseBody)
+	} else {
+		var completion chatResponse
+		if err = json.Unmarshal(responseBody, &completion); err == nil && len(completion.Choices) > 0 {
+			message = completion.Choices[0].Message.Content
+		}
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to decode Hugging Face response: %w", err)
+	}
+	if strings.TrimSpace(message) == "" {
+		return "", fmt.Errorf("empty response from Hugging Face API")
+	}
+	return message, nil
+}
+
+// decodeTextGeneration reads a text-generation response, which is a list
+// of outputs from the Inference API and a single object from some
+// endpoints.
+func decodeTextGeneration(body []byte) (string, error) {
+	var outputs []textGenerationOutput
+	if err := json.Unmarshal(body, &outputs); err == nil {
+		if len(outputs) == 0 {
+			return "", nil
+		}
+		return outputs[0].GeneratedText, nil
+	}
+	var output textGenerationOutput
+	if err := json.Unmarshal(body, &output); err != nil {
+		return "", err
+	}
+	return output.GeneratedText, nil
+}
@@ -0,0 +1,124 @@
+package huggingface
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dfanso/commit-msg/pkg/types"
+)
+
+func withTestServer(t *testing.T, opts Options, handler http.HandlerFunc) {
+	t.Helper()
+
+	t.Setenv("HF_MODEL", "")
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	t.Setenv("HF_ENDPOINT_URL", srv.URL)
+	prevClient := httpClient
+	httpClient = srv.Client()
+	Configure(opts)
+	t.Cleanup(func() {
+		httpClient = prevClient
+		Configure(Options{})
+	})
+}
+
+func TestGenerateCommitMessageChat(t *testing.T) {
+	withTestServer(t, Options{}, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer hf_test" {
+			t.Fatalf("unexpected authorization header: %s", got)
+		}
+		var payload chatRequest
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+		if payload.Model != "acme/commit-llama" || len(payload.Messages) != 2 {
+			t.Fatalf("unexpected request: %+v", payload)
+		}
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"fix: handle empty input"}}]}`))
+	})
+
+	msg, err := GenerateCommitMessage(&types.Config{}, "diff", "hf_test", "acme/commit-llama", nil)
+	if err != nil {
+		t.Fatalf("GenerateCommitMessage returned error: %v", err)
+	}
+	if msg != "fix: handle empty input" {
+		t.Fatalf("unexpected message: %q", msg)
+	}
+}
+
+func TestGenerateCommitMessageTextGeneration(t *testing.T) {
+	for name, response := range map[string]string{
+		"list":   `[{"generated_text":"feat: add export"}]`,
+		"object": `{"generated_text":"feat: add export"}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			withTestServer(t, Options{API: APITextGeneration, MaxNewTokens: 64}, func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/" {
+					t.Fatalf("unexpected path: %s", r.URL.Path)
+				}
+				var payload textGenerationRequest
+				if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+					t.Fatalf("failed to decode request: %v", err)
+				}
+				if payload.Inputs == "" || payload.Parameters.MaxNewTokens != 64 || payload.Parameters.ReturnFullText {
+					t.Fatalf("unexpected request: %+v", payload)
+				}
+				_, _ = w.Write([]byte(response))
+			})
+
+			msg, err := GenerateCommitMessage(&types.Config{}, "diff", "hf_test", "", nil)
+			if err != nil {
+				t.Fatalf("GenerateCommitMessage returned error: %v", err)
+			}
+			if msg != "feat: add export" {
+				t.Fatalf("unexpected message: %q", msg)
+			}
+		})
+	}
+}
+
+func TestGenerateCommitMessageNonOK(t *testing.T) {
+	withTestServer(t, Options{}, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":"Model is currently loading"}`, http.StatusServiceUnavailable)
+	})
+
+	if _, err := GenerateCommitMessage(&types.Config{}, "diff", "hf_test", "", nil); err == nil {
+		t.Fatal("expected error but got nil")
+	}
+}
+
+func TestChatURL(t *testing.T) {
+	t.Parallel()
+
+	for endpoint, want := range map[string]string{
+		DefaultEndpoint: "https://router.huggingface.co/v1/chat/completions",
+		"https://abc.us-east-1.aws.endpoints.huggingface.cloud/":                    "https://abc.us-east-1.aws.endpoints.huggingface.cloud/v1/chat/completions",
+		"https://abc.us-east-1.aws.endpoints.huggingface.cloud/v1/chat/completions": "https://abc.us-east-1.aws.endpoints.huggingface.cloud/v1/chat/completions",
+	} {
+		if got := chatURL(endpoint); got != want {
+			t.Errorf("chatURL(%q) = %q, want %q", endpoint, got, want)
+		}
+	}
+}
+
+func TestOptionsValidate(t *testing.T) {
+	t.Parallel()
+
+	for _, opts := range []Options{{}, {API: APIChat}, {Endpoint: "https://example.com", API: APITextGeneration}} {
+		if err := opts.Validate(); err != nil {
+			t.Errorf("Validate(%+v) error = %v", opts, err)
+		}
+	}
+	for _, opts := range []Options{{API: "completions"}, {Endpoint: "example.com"}, {MaxNewTokens: -1}} {
+		if err := opts.Validate(); err == nil {
+			t.Errorf("Validate(%+v) succeeded, want an error", opts)
+		}
+	}
+}
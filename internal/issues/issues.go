@@ -0,0 +1,281 @@
+package issues
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Tracker identifies an issue tracking service.
+type Tracker string
+
+const (
+	GitHub Tracker = "github"
+	GitLab Tracker = "gitlab"
+	Jira   Tracker = "jira"
+)
+
+// Trackers lists the supported trackers in display order.
+func Trackers() []Tracker {
+	return []Tracker{GitHub, GitLab, Jira}
+}
+
+// maxDescription bounds how much of an issue body is added to the prompt.
+const maxDescription = 1500
+
+// Credential holds what is needed to query a tracker. BaseURL and Email are
+// only used by Jira.
+type Credential struct {
+	Token   string `json:"token"`
+	BaseURL string `json:"base_url,omitempty"`
+	Email   string `json:"email,omitempty"`
+}
+
+// Ref is an issue reference found in a branch name.
+type Ref struct {
+	// Key is the Jira key (e.g. "PROJ-123") or the issue number.
+	Key string
+	// Jira reports whether Key is a Jira key rather than a number.
+	Jira bool
+}
+
+// String returns the key as it is written in commit messages: "PROJ-123"
+// for Jira and "#42" for numbered issues.
+func (r Ref) String() string {
+	if r.Jira {
+		return r.Key
+	}
+	return "#" + r.Key
+}
+
+// Issue is the tracker data added to the prompt.
+type Issue struct {
+	Key         string
+	Title       string
+	Description string
+	URL         string
+}
+
+var (
+	jiraKeyPattern = regexp.MustCompile(`\b([A-Z][A-Z0-9]+-\d+)\b`)
+	numberPattern  = regexp.MustCompile(`(?i)(?:^|[/_-])(?:issue|gh)?-?#?(\d+)(?:[/_-]|$)`)
+)
+
+// DetectRef extracts an issue reference from a branch name such as
+// "feature/PROJ-123-login", "fix/issue-42", or "42-crash-on-start".
+func DetectRef(branch string) (Ref, bool) {
+	if match := jiraKeyPattern.FindStringSubmatch(branch); match != nil {
+		return Ref{Key: match[1], Jira: true}, true
+	}
+	if match := numberPattern.FindStringSubmatch(branch); match != nil {
+		return Ref{Key: match[1]}, true
+	}
+	return Ref{}, false
+}
+
+// Remote is a parsed git remote URL.
+type Remote struct {
+	Host string
+	// Path is the repository path without ".git", e.g. "owner/repo".
+	Path string
+}
+
+// ParseRemote parses SSH ("git@host:owner/repo.git") and URL-style remotes.
+func ParseRemote(remote string) (Remote, error) {
+	remote = strings.TrimSpace(remote)
+	if remote == "" {
+		return Remote{}, fmt.Errorf("empty remote URL")
+	}
+
+	if !strings.Contains(remote, "://") {
+		// scp-like syntax: [user@]host:path
+		hostPart, path, ok := strings.Cut(remote, ":")
+		if !ok {
+			return Remote{}, fmt.Errorf("unrecognised remote URL %q", remote)
+		}
+		if _, host, found := strings.Cut(hostPart, "@"); found {
+			hostPart = host
+		}
+		return Remote{Host: hostPart, Path: cleanRepoPath(path)}, nil
+	}
+
+	u, err := url.Parse(remote)
+	if err != nil {
+		return Remote{}, fmt.Errorf("unrecognised remote URL %q: %w", remote, err)
+	}
+	return Remote{Host: u.Hostname(), Path: cleanRepoPath(u.Path)}, nil
+}
+
+func cleanRepoPath(path string) string {
+	return strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+}
+
+// TrackerFor picks the tracker for ref given the repository's remote host.
+// Jira keys always go to Jira; numbers go to GitLab when the host looks
+// like GitLab and to GitHub otherwise.
+func TrackerFor(ref Ref, remote Remote) Tracker {
+	switch {
+	case ref.Jira:
+		return Jira
+	case strings.Contains(remote.Host, "gitlab"):
+		return GitLab
+	default:
+		return GitHub
+	}
+}
+
+// Client fetches issues from trackers.
+type Client struct {
+	HTTP *http.Client
+	// GitHubAPI and GitLabAPI override the API base URLs, mainly for tests.
+	GitHubAPI string
+	GitLabAPI string
+}
+
+// Fetch retrieves the issue for ref from tracker.
+func (c *Client) Fetch(ctx context.Context, tracker Tracker, ref Ref, remote Remote, cred Credential) (*Issue, error) {
+	switch tracker {
+	case GitHub:
+		return c.fetchGitHub(ctx, ref, remote, cred)
+	case GitLab:
+		return c.fetchGitLab(ctx, ref, remote, cred)
+	case Jira:
+		return c.fetchJira(ctx, ref, cred)
+	default:
+		return nil, fmt.Errorf("unsupported issue tracker %q", tracker)
+	}
+}
+
+func (c *Client) fetchGitHub(ctx context.Context, ref Ref, remote Remote, cred Credential) (*Issue, error) {
+	base := c.GitHubAPI
+	if base == "" {
+		base = "https://api.github.com"
+		if remote.Host != "" && remote.Host != "github.com" {
+			// GitHub Enterprise Server
+			base = "https://" + remote.Host + "/api/v3"
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/repos/%s/issues/%s", strings.TrimRight(base, "/"), remote.Path, ref.Key), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if cred.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+cred.Token)
+	}
+
+	var body struct {
+		Number  int    `json:"number"`
+		Title   string `json:"title"`
+		Body    string `json:"body"`
+		HTMLURL string `json:"html_url"`
+	}
+	if err := c.do(req, &body); err != nil {
+		return nil, err
+	}
+	return &Issue{Key: "#" + ref.Key, Title: body.Title, Description: body.Body, URL: body.HTMLURL}, nil
+}
+
+func (c *Client) fetchGitLab(ctx context.Context, ref Ref, remote Remote, cred Credential) (*Issue, error) {
+	base := c.GitLabAPI
+	if base == "" {
+		base = "https://" + remote.Host + "/api/v4"
+	}
+
+	endpoint := fmt.Sprintf("%s/projects/%s/issues/%s", strings.TrimRight(base, "/"), url.PathEscape(remote.Path), ref.Key)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	if cred.Token != "" {
+		req.Header.Set("PRIVATE-TOKEN", cred.Token)
+	}
+
+	var body struct {
+		Title       string `json:"title"`
+		Description string `json:"description"`
+		WebURL      string `json:"web_url"`
+	}
+	if err := c.do(req, &body); err != nil {
+		return nil, err
+	}
+	return &Issue{Key: "#" + ref.Key, Title: body.Title, Description: body.Description, URL: body.WebURL}, nil
+}
+
+func (c *Client) fetchJira(ctx context.Context, ref Ref, cred Credential) (*Issue, error) {
+	if cred.BaseURL == "" {
+		return nil, fmt.Errorf("jira base URL is not configured")
+	}
+	base := strings.TrimRight(cred.BaseURL, "/")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/rest/api/2/issue/%s?fields=summary,description", base, url.PathEscape(ref.Key)), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	switch {
+	case cred.Email != "":
+		// Jira Cloud: email + API token
+		req.SetBasicAuth(cred.Email, cred.Token)
+	case cred.Token != "":
+		// Jira Data Center: personal access token
+		req.Header.Set("Authorization", "Bearer "+cred.Token)
+	}
+
+	var body struct {
+		Key    string `json:"key"`
+		Fields struct {
+			Summary     string `json:"summary"`
+			Description string `json:"description"`
+		} `json:"fields"`
+	}
+	if err := c.do(req, &body); err != nil {
+		return nil, err
+	}
+	return &Issue{Key: ref.Key, Title: body.Fields.Summary, Description: body.Fields.Description, URL: base + "/browse/" + ref.Key}, nil
+}
+
+func (c *Client) do(req *http.Request, target any) error {
+	client := c.HTTP
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s returned %s: %s", req.URL.Host, resp.Status, strings.TrimSpace(string(body)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(target); err != nil {
+		return fmt.Errorf("failed to decode issue: %w", err)
+	}
+	return nil
+}
+
+// Summary renders issue as a prompt section.
+func (i *Issue) Summary() string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("Related issue %s: %s\n", i.Key, strings.TrimSpace(i.Title)))
+
+	description := strings.TrimSpace(i.Description)
+	if description != "" {
+		if runes := []rune(description); len(runes) > maxDescription {
+			description = string(runes[:maxDescription]) + "..."
+		}
+		b.WriteString("Issue description:\n")
+		b.WriteString(description)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
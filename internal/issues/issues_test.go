@@ -0,0 +1,196 @@
+package issues
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDetectRef(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		branch string
+		want   Ref
+		ok     bool
+	}{
+		{"feature/PROJ-123-login", Ref{Key: "PROJ-123", Jira: true}, true},
+		{"ABC2-7", Ref{Key: "ABC2-7", Jira: true}, true},
+		{"fix/issue-42", Ref{Key: "42"}, true},
+		{"42-crash-on-start", Ref{Key: "42"}, true},
+		{"gh-9_typo", Ref{Key: "9"}, true},
+		{"feature/17", Ref{Key: "17"}, true},
+		{"main", Ref{}, false},
+		{"v2-cleanup", Ref{}, false},
+		{"release/1.2", Ref{}, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := DetectRef(tt.branch)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("DetectRef(%q) = %+v, %v; want %+v, %v", tt.branch, got, ok, tt.want, tt.ok)
+		}
+	}
+
+	if got := (Ref{Key: "PROJ-123", Jira: true}).String(); got != "PROJ-123" {
+		t.Errorf("Jira Ref.String() = %q, want PROJ-123", got)
+	}
+	if got := (Ref{Key: "42"}).String(); got != "#42" {
+		t.Errorf("numbered Ref.String() = %q, want #42", got)
+	}
+}
+
+func TestParseRemote(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		remote string
+		want   Remote
+	}{
+		{"git@github.com:owner/repo.git", Remote{Host: "github.com", Path: "owner/repo"}},
+		{"https://github.com/owner/repo", Remote{Host: "github.com", Path: "owner/repo"}},
+		{"ssh://git@gitlab.example.com:2222/group/sub/repo.git", Remote{Host: "gitlab.example.com", Path: "group/sub/repo"}},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseRemote(tt.remote)
+		if err != nil {
+			t.Fatalf("ParseRemote(%q) error: %v", tt.remote, err)
+		}
+		if got != tt.want {
+			t.Errorf("ParseRemote(%q) = %+v, want %+v", tt.remote, got, tt.want)
+		}
+	}
+
+	if _, err := ParseRemote(""); err == nil {
+		t.Error("ParseRemote(\"\") expected error")
+	}
+}
+
+func TestTrackerFor(t *testing.T) {
+	t.Parallel()
+
+	if got := TrackerFor(Ref{Key: "A-1", Jira: true}, Remote{Host: "github.com"}); got != Jira {
+		t.Errorf("Jira key: got %s", got)
+	}
+	if got := TrackerFor(Ref{Key: "1"}, Remote{Host: "gitlab.com"}); got != GitLab {
+		t.Errorf("gitlab host: got %s", got)
+	}
+	if got := TrackerFor(Ref{Key: "1"}, Remote{Host: "github.com"}); got != GitHub {
+		t.Errorf("github host: got %s", got)
+	}
+}
+
+func TestFetchGitHub(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/owner/repo/issues/42" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			t.Errorf("Authorization = %q", got)
+		}
+		w.Write([]byte(`{"number":42,"title":"Crash on start","body":"Stack trace...","html_url":"https://github.com/owner/repo/issues/42"}`))
+	}))
+	defer server.Close()
+
+	client := &Client{HTTP: server.Client(), GitHubAPI: server.URL}
+	issue, err := client.Fetch(context.Background(), GitHub, Ref{Key: "42"}, Remote{Host: "github.com", Path: "owner/repo"}, Credential{Token: "secret"})
+	if err != nil {
+		t.Fatalf("Fetch error: %v", err)
+	}
+	if issue.Key != "#42" || issue.Title != "Crash on start" || issue.Description != "Stack trace..." {
+		t.Fatalf("unexpected issue %+v", issue)
+	}
+}
+
+func TestFetchGitLab(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != "/projects/group%2Frepo/issues/7" {
+			t.Errorf("unexpected path %s", r.URL.EscapedPath())
+		}
+		if got := r.Header.Get("PRIVATE-TOKEN"); got != "secret" {
+			t.Errorf("PRIVATE-TOKEN = %q", got)
+		}
+		w.Write([]byte(`{"title":"Add export","description":"CSV please"}`))
+	}))
+	defer server.Close()
+
+	client := &Client{HTTP: server.Client(), GitLabAPI: server.URL}
+	issue, err := client.Fetch(context.Background(), GitLab, Ref{Key: "7"}, Remote{Host: "gitlab.com", Path: "group/repo"}, Credential{Token: "secret"})
+	if err != nil {
+		t.Fatalf("Fetch error: %v", err)
+	}
+	if issue.Title != "Add export" || issue.Description != "CSV please" {
+		t.Fatalf("unexpected issue %+v", issue)
+	}
+}
+
+func TestFetchJira(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rest/api/2/issue/PROJ-5" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "me@example.com" || pass != "secret" {
+			t.Errorf("unexpected basic auth %q %q %v", user, pass, ok)
+		}
+		w.Write([]byte(`{"key":"PROJ-5","fields":{"summary":"Login fails","description":"SSO users cannot log in"}}`))
+	}))
+	defer server.Close()
+
+	client := &Client{HTTP: server.Client()}
+	cred := Credential{Token: "secret", Email: "me@example.com", BaseURL: server.URL}
+	issue, err := client.Fetch(context.Background(), Jira, Ref{Key: "PROJ-5", Jira: true}, Remote{}, cred)
+	if err != nil {
+		t.Fatalf("Fetch error: %v", err)
+	}
+	if issue.Title != "Login fails" || issue.URL != server.URL+"/browse/PROJ-5" {
+		t.Fatalf("unexpected issue %+v", issue)
+	}
+
+	if _, err := client.Fetch(context.Background(), Jira, Ref{Key: "PROJ-5", Jira: true}, Remote{}, Credential{Token: "x"}); err == nil {
+		t.Fatal("expected error without Jira base URL")
+	}
+}
+
+func TestFetchReportsHTTPErrors(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "not found", http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := &Client{HTTP: server.Client(), GitHubAPI: server.URL}
+	_, err := client.Fetch(context.Background(), GitHub, Ref{Key: "1"}, Remote{Path: "o/r"}, Credential{})
+	if err == nil || !strings.Contains(err.Error(), "404") {
+		t.Fatalf("expected 404 error, got %v", err)
+	}
+}
+
+func TestIssueSummary(t *testing.T) {
+	t.Parallel()
+
+	issue := &Issue{Key: "PROJ-1", Title: "Title", Description: strings.Repeat("x", maxDescription+10)}
+	summary := issue.Summary()
+
+	if !strings.HasPrefix(summary, "Related issue PROJ-1: Title\n") {
+		t.Fatalf("unexpected summary header: %q", summary[:40])
+	}
+	if !strings.Contains(summary, strings.Repeat("x", maxDescription)+"...") {
+		t.Fatal("expected truncated description")
+	}
+
+	short := (&Issue{Key: "#2", Title: "Only title"}).Summary()
+	if strings.Contains(short, "Issue description") {
+		t.Fatalf("unexpected description section: %q", short)
+	}
+}
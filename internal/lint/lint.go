@@ -0,0 +1,170 @@
+// Package lint checks commit messages against configurable style rules so
+// hand-written and generated messages can be held to the same standard.
+package lint
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dfanso/commit-msg/internal/postprocess"
+)
+
+// Rules selects the checks run by Check. The JSON names are the keys of the
+// "lint" section in config.json.
+type Rules struct {
+	// MaxSubjectLength is the longest allowed subject line, in characters.
+	// Zero disables the check.
+	MaxSubjectLength int `json:"max_subject_length"`
+	// MaxBodyLineLength is the longest allowed body line, in characters.
+	// Zero disables the check. Lines without spaces, such as URLs, are
+	// exempt.
+	MaxBodyLineLength int `json:"max_body_line_length"`
+	// ImperativeMood rejects subjects starting with "Added", "Fixes", and
+	// similar forms instead of "Add" or "Fix".
+	ImperativeMood bool `json:"imperative_mood"`
+	// NoTrailingPeriod rejects subjects ending with a period.
+	NoTrailingPeriod bool `json:"no_trailing_period"`
+	// RequireType requires a conventional commit prefix such as "feat:".
+	RequireType bool `json:"require_type"`
+	// Types lists the allowed conventional commit types. An empty list
+	// allows any type.
+	Types []string `json:"types"`
+}
+
+// DefaultRules returns the rules used when config.json has no "lint"
+// section: a 72 character subject and body width, imperative mood, and no
+// trailing period.
+func DefaultRules() Rules {
+	return Rules{
+		MaxSubjectLength:  72,
+		MaxBodyLineLength: 72,
+		ImperativeMood:    true,
+		NoTrailingPeriod:  true,
+	}
+}
+
+// Issue is a single rule violation.
+type Issue struct {
+	// Line is the 1-based line of the message the issue was found on.
+	Line int
+	// Rule names the violated rule, for example "subject-length".
+	Rule    string
+	Message string
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("line %d: %s (%s)", i.Line, i.Message, i.Rule)
+}
+
+// conventionalPrefix captures the type of a "type(scope)!: " subject prefix.
+var conventionalPrefix = regexp.MustCompile(`^([A-Za-z]+)(\([^)]*\))?!?: `)
+
+// exemptSubjects are prefixes of subjects written by git itself, which are
+// only checked for length.
+var exemptSubjects = []string{"Merge ", "Revert ", "fixup! ", "squash! ", "amend! "}
+
+// Clean strips what git strips from a message before committing: comment
+// lines starting with "#", everything below a scissors line, and leading and
+// trailing blank lines.
+func Clean(message string) string {
+	lines := strings.Split(strings.ReplaceAll(message, "\r\n", "\n"), "\n")
+	kept := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if strings.HasPrefix(line, "# ------------------------ >8 ------------------------") {
+			break
+		}
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		kept = append(kept, strings.TrimRight(line, " \t"))
+	}
+	return strings.Trim(strings.Join(kept, "\n"), "\n")
+}
+
+// Check returns the issues found in message, which should already be
+// cleaned with Clean. An empty message is itself an issue.
+func Check(message string, rules Rules) []Issue {
+	if strings.TrimSpace(message) == "" {
+		return []Issue{{Line: 1, Rule: "empty", Message: "commit message is empty"}}
+	}
+
+	lines := strings.Split(message, "\n")
+	subject := lines[0]
+	issues := checkSubject(subject, rules)
+
+	if len(lines) > 1 && strings.TrimSpace(lines[1]) != "" {
+		issues = append(issues, Issue{Line: 2, Rule: "blank-line", Message: "separate the subject from the body with a blank line"})
+	}
+
+	if rules.MaxBodyLineLength > 0 {
+		for i, line := range lines[1:] {
+			length := utf8.RuneCountInString(line)
+			if length > rules.MaxBodyLineLength && strings.Contains(strings.TrimSpace(line), " ") {
+				issues = append(issues, Issue{
+					Line:    i + 2,
+					Rule:    "body-line-length",
+					Message: fmt.Sprintf("body line is %d characters (limit %d)", length, rules.MaxBodyLineLength),
+				})
+			}
+		}
+	}
+	return issues
+}
+
+func checkSubject(subject string, rules Rules) []Issue {
+	var issues []Issue
+	if length := utf8.RuneCountInString(subject); rules.MaxSubjectLength > 0 && length > rules.MaxSubjectLength {
+		issues = append(issues, Issue{
+			Line:    1,
+			Rule:    "subject-length",
+			Message: fmt.Sprintf("subject is %d characters (limit %d)", length, rules.MaxSubjectLength),
+		})
+	}
+
+	for _, prefix := range exemptSubjects {
+		if strings.HasPrefix(subject, prefix) {
+			return issues
+		}
+	}
+
+	description := subject
+	if match := conventionalPrefix.FindStringSubmatch(subject); match != nil {
+		description = subject[len(match[0]):]
+		if len(rules.Types) > 0 && !allowedType(match[1], rules.Types) {
+			issues = append(issues, Issue{
+				Line:    1,
+				Rule:    "type",
+				Message: fmt.Sprintf("type %q is not one of: %s", match[1], strings.Join(rules.Types, ", ")),
+			})
+		}
+	} else if rules.RequireType {
+		issues = append(issues, Issue{Line: 1, Rule: "type", Message: `subject needs a conventional commit type, as in "feat: ..."`})
+	}
+
+	if rules.ImperativeMood {
+		word, _, _ := strings.Cut(description, " ")
+		if base, ok := postprocess.BaseForm(word); ok {
+			issues = append(issues, Issue{
+				Line:    1,
+				Rule:    "imperative-mood",
+				Message: fmt.Sprintf("use the imperative mood: %q instead of %q", base, word),
+			})
+		}
+	}
+
+	if rules.NoTrailingPeriod && strings.HasSuffix(subject, ".") && !strings.HasSuffix(subject, "..") {
+		issues = append(issues, Issue{Line: 1, Rule: "trailing-period", Message: "subject ends with a period"})
+	}
+	return issues
+}
+
+func allowedType(commitType string, types []string) bool {
+	for _, allowed := range types {
+		if strings.EqualFold(strings.TrimSpace(allowed), commitType) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,63 @@
+package lint
+
+import (
+	"strings"
+	"testing"
+)
+
+func rules(issues []Issue) []string {
+	names := make([]string, len(issues))
+	for i, issue := range issues {
+		names[i] = issue.Rule
+	}
+	return names
+}
+
+func TestCheck(t *testing.T) {
+	t.Parallel()
+
+	typed := DefaultRules()
+	typed.Types = []string{"feat", "fix"}
+	strict := DefaultRules()
+	strict.RequireType = true
+
+	tests := []struct {
+		name    string
+		message string
+		rules   Rules
+		want    []string
+	}{
+		{name: "clean", message: "feat: add parser\n\nExplain why.", rules: DefaultRules()},
+		{name: "empty", message: "  ", rules: DefaultRules(), want: []string{"empty"}},
+		{name: "long subject", message: "fix: " + strings.Repeat("a", 70), rules: DefaultRules(), want: []string{"subject-length"}},
+		{name: "mood", message: "Added parser", rules: DefaultRules(), want: []string{"imperative-mood"}},
+		{name: "mood after type", message: "feat(cli): fixes flag parsing", rules: DefaultRules(), want: []string{"imperative-mood"}},
+		{name: "period", message: "Add parser.", rules: DefaultRules(), want: []string{"trailing-period"}},
+		{name: "ellipsis", message: "Add parser...", rules: DefaultRules()},
+		{name: "missing blank line", message: "Add parser\nbody", rules: DefaultRules(), want: []string{"blank-line"}},
+		{name: "long body line", message: "Add parser\n\n" + strings.Repeat("word ", 20), rules: DefaultRules(), want: []string{"body-line-length"}},
+		{name: "long url", message: "Add parser\n\nhttps://example.com/" + strings.Repeat("a", 80), rules: DefaultRules()},
+		{name: "disallowed type", message: "chore: bump deps", rules: typed, want: []string{"type"}},
+		{name: "allowed type", message: "Fix: handle nil", rules: typed},
+		{name: "missing type", message: "Add parser", rules: strict, want: []string{"type"}},
+		{name: "merge exempt", message: "Merge branch 'main' into feature.", rules: strict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := rules(Check(tt.message, tt.rules))
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Fatalf("Check(%q) rules = %v, want %v", tt.message, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClean(t *testing.T) {
+	t.Parallel()
+
+	message := "\nAdd parser\n\nBody  \n# Please enter the commit message\n# ------------------------ >8 ------------------------\ndiff --git a/x b/x\n"
+	if got, want := Clean(message), "Add parser\n\nBody"; got != want {
+		t.Fatalf("Clean() = %q, want %q", got, want)
+	}
+}
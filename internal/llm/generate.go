@@ -0,0 +1,79 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dfanso/commit-msg/internal/scrubber"
+	"github.com/dfanso/commit-msg/pkg/types"
+)
+
+// ErrSensitiveData signals that the assembled prompt still matched a
+// sensitive-data pattern, so nothing was sent to the provider.
+var ErrSensitiveData = errors.New("llm: prompt still contains sensitive data")
+
+// Generate asks provider for a commit message and returns it together with
+// the model, token usage, and time taken. Tokens is only set when the
+// provider implements UsageReporter and reported usage. When opts asks for
+// several candidates and provider implements CandidateGenerator, the others
+// are returned as Alternatives; other providers produce a single message.
+//
+// The prompt is checked with VerifyPrompt first, and an error wrapping
+// ErrSensitiveData is returned without contacting provider if it fails.
+func Generate(ctx context.Context, provider Provider, changes string, opts *types.GenerationOptions) (*types.GenerationResult, error) {
+	if err := VerifyPrompt(changes, opts); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	messages, err := generateCandidates(ctx, provider, changes, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &types.GenerationResult{
+		Message:      messages[0],
+		Alternatives: messages[1:],
+		Provider:     provider.Name(),
+		Model:        ModelFor(provider.Name()),
+		Duration:     time.Since(start),
+	}
+	if reporter, ok := provider.(UsageReporter); ok {
+		result.Tokens = reporter.LastUsage()
+	}
+	return result, nil
+}
+
+func generateCandidates(ctx context.Context, provider Provider, changes string, opts *types.GenerationOptions) ([]string, error) {
+	if generator, ok := provider.(CandidateGenerator); ok && opts != nil && opts.Candidates > 1 {
+		messages, err := generator.GenerateCandidates(ctx, changes, opts, opts.Candidates)
+		if err != nil {
+			return nil, err
+		}
+		if len(messages) > 0 {
+			return messages, nil
+		}
+	}
+
+	message, err := provider.Generate(ctx, changes, opts)
+	if err != nil {
+		return nil, err
+	}
+	return []string{message}, nil
+}
+
+// VerifyPrompt re-runs sensitive-data detection on the complete prompt built
+// from changes and opts. The diff is scrubbed when it is collected, but the
+// prompt also carries recent commit subjects, branch names, project context,
+// and instructions, any of which could hold a secret. The returned error
+// wraps ErrSensitiveData and names the matching patterns.
+func VerifyPrompt(changes string, opts *types.GenerationOptions) error {
+	patterns := scrubber.Unredacted(types.BuildCommitPrompt(changes, opts))
+	if len(patterns) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w (%s)", ErrSensitiveData, strings.Join(patterns, ", "))
+}
@@ -0,0 +1,135 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/dfanso/commit-msg/pkg/types"
+)
+
+type usageProvider struct {
+	message string
+	usage   *types.UsageInfo
+	err     error
+}
+
+func (p usageProvider) Name() types.LLMProvider {
+	return types.ProviderClaude
+}
+
+func (p usageProvider) Generate(context.Context, string, *types.GenerationOptions) (string, error) {
+	return p.message, p.err
+}
+
+func (p usageProvider) LastUsage() *types.UsageInfo {
+	return p.usage
+}
+
+type candidateProvider struct {
+	usageProvider
+	candidates []string
+	requested  int
+}
+
+func (p *candidateProvider) GenerateCandidates(_ context.Context, _ string, _ *types.GenerationOptions, n int) ([]string, error) {
+	p.requested = n
+	return p.candidates, nil
+}
+
+func TestGenerateReturnsMetadata(t *testing.T) {
+	t.Setenv("CLAUDE_MODEL", "claude-test")
+
+	usage := &types.UsageInfo{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15}
+	result, err := Generate(context.Background(), usageProvider{message: "feat: add thing", usage: usage}, "diff", nil)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if result.Message != "feat: add thing" {
+		t.Fatalf("Message = %q, want %q", result.Message, "feat: add thing")
+	}
+	if result.Provider != types.ProviderClaude || result.Model != "claude-test" {
+		t.Fatalf("Provider/Model = %s/%s, want claude/claude-test", result.Provider, result.Model)
+	}
+	if result.Tokens != usage {
+		t.Fatalf("Tokens = %+v, want %+v", result.Tokens, usage)
+	}
+	if result.CacheHit {
+		t.Fatal("expected a fresh result not to be marked as a cache hit")
+	}
+}
+
+func TestGenerateWithoutUsageReporter(t *testing.T) {
+	t.Parallel()
+
+	result, err := Generate(context.Background(), fakeProvider{name: types.ProviderGrok}, "diff", nil)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if result.Tokens != nil {
+		t.Fatalf("Tokens = %+v, want nil", result.Tokens)
+	}
+}
+
+func TestGenerateReturnsProviderError(t *testing.T) {
+	t.Parallel()
+
+	want := errors.New("boom")
+	if _, err := Generate(context.Background(), usageProvider{err: want}, "diff", nil); !errors.Is(err, want) {
+		t.Fatalf("Generate() error = %v, want %v", err, want)
+	}
+}
+
+func TestGenerateReturnsAlternatives(t *testing.T) {
+	t.Parallel()
+
+	provider := &candidateProvider{
+		usageProvider: usageProvider{message: "feat: single"},
+		candidates:    []string{"feat: first", "feat: second", "feat: third"},
+	}
+
+	result, err := Generate(context.Background(), provider, "diff", &types.GenerationOptions{Candidates: 3})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if provider.requested != 3 {
+		t.Fatalf("requested %d candidates, want 3", provider.requested)
+	}
+	if result.Message != "feat: first" || len(result.Alternatives) != 2 || result.Alternatives[1] != "feat: third" {
+		t.Fatalf("unexpected result %q with alternatives %q", result.Message, result.Alternatives)
+	}
+
+	result, err = Generate(context.Background(), provider, "diff", nil)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if result.Message != "feat: single" || len(result.Alternatives) != 0 {
+		t.Fatalf("expected a single message without Candidates, got %q and %q", result.Message, result.Alternatives)
+	}
+}
+
+func TestGenerateRefusesUnscrubbedPrompt(t *testing.T) {
+	t.Parallel()
+
+	provider := &candidateProvider{usageProvider: usageProvider{message: "feat: leaked"}}
+	opts := &types.GenerationOptions{
+		PreviousMessage: "chore: rotate api_key=abcdefghijklmnopqrstuvwxyz123",
+		Candidates:      2,
+	}
+
+	_, err := Generate(context.Background(), provider, "diff", opts)
+	if !errors.Is(err, ErrSensitiveData) {
+		t.Fatalf("Generate() error = %v, want ErrSensitiveData", err)
+	}
+	if !strings.Contains(err.Error(), "Generic API Key") {
+		t.Fatalf("error %q does not name the matching pattern", err)
+	}
+	if provider.requested != 0 {
+		t.Fatal("expected the provider not to be called")
+	}
+
+	if err := VerifyPrompt(`api_key="[REDACTED_API_KEY]"`, nil); err != nil {
+		t.Fatalf("VerifyPrompt() on scrubbed content error = %v", err)
+	}
+}
@@ -0,0 +1,240 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dfanso/commit-msg/internal/chatgpt"
+	"github.com/dfanso/commit-msg/internal/claude"
+	"github.com/dfanso/commit-msg/internal/gemini"
+	"github.com/dfanso/commit-msg/internal/grok"
+	httpClient "github.com/dfanso/commit-msg/internal/http"
+	"github.com/dfanso/commit-msg/internal/ollama"
+	"github.com/dfanso/commit-msg/internal/openaicompat"
+	"github.com/dfanso/commit-msg/internal/vertex"
+	"github.com/dfanso/commit-msg/pkg/types"
+)
+
+// healthEndpoints are cheap authenticated GETs (model listings) that prove a
+// credential works without spending tokens. Providers whose endpoint can be
+// changed in config.json are resolved by HealthChecker.endpoint instead.
+var healthEndpoints = map[types.LLMProvider]string{
+	types.ProviderGroq:   "https://api.groq.com/openai/v1/models",
+	types.ProviderCohere: "https://api.cohere.com/v1/models?page_size=1",
+	// Dedicated endpoints have no listing, so check the token itself.
+	types.ProviderHuggingFace: "https://huggingface.co/api/whoami-v2",
+}
+
+// Health is the result of checking one provider.
+type Health struct {
+	Provider types.LLMProvider
+	// Reachable is true when the endpoint answered at all.
+	Reachable bool
+	// OK is true when the endpoint accepted the credential.
+	OK         bool
+	StatusCode int
+	Latency    time.Duration
+	// Problem explains a failed check in terms of what to do next.
+	Problem string
+	// Quota holds the rate-limit headers returned by the provider, keyed by
+	// lower-case header name.
+	Quota map[string]string
+}
+
+// HealthChecker checks provider reachability and credentials.
+type HealthChecker struct {
+	// HTTP is the client used for checks; nil uses the shared client.
+	HTTP *http.Client
+	// Endpoints overrides the default health endpoints, primarily for tests.
+	Endpoints map[types.LLMProvider]string
+	// Config carries the endpoint overrides from config.json, so a gateway
+	// is checked rather than the provider behind it; nil uses the defaults.
+	Config *types.Config
+}
+
+// Check pings provider's health endpoint with credential and reports the
+// outcome. Failures are described in the result rather than returned.
+func (c HealthChecker) Check(ctx context.Context, provider types.LLMProvider, credential string) Health {
+	health := Health{Provider: provider}
+
+	if provider.IsPlugin() {
+		if _, err := FindPlugin(provider.PluginName()); err != nil {
+			health.Problem = err.Error()
+			return health
+		}
+		health.Reachable = true
+		health.OK = true
+		return health
+	}
+
+	req, err := c.request(ctx, provider, strings.TrimSpace(credential))
+	if err != nil {
+		health.Problem = err.Error()
+		return health
+	}
+
+	client := c.HTTP
+	if client == nil {
+		client = httpClient.ClientFor(provider)
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	health.Latency = time.Since(start)
+	if err != nil {
+		health.Problem = fmt.Sprintf("unreachable: %v", err)
+		return health
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	health.Reachable = true
+	health.StatusCode = resp.StatusCode
+	health.Quota = quotaHeaders(resp.Header)
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		health.OK = true
+	case resp.StatusCode == http.StatusForbidden && provider == types.ProviderVertex:
+		health.Problem = "credentials lack access; grant the Vertex AI User role and enable the Vertex AI API in the project"
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		health.Problem = "API key is invalid, expired, or lacks access; run: commit llm update"
+	case resp.StatusCode == http.StatusBadRequest && provider == types.ProviderGemini:
+		// Gemini reports bad keys as 400 API_KEY_INVALID.
+		health.Problem = "API key is invalid or expired; run: commit llm update"
+	case resp.StatusCode == http.StatusTooManyRequests:
+		health.Problem = "rate limited or out of quota; check your plan and billing"
+	default:
+		health.Problem = fmt.Sprintf("unexpected status %d", resp.StatusCode)
+	}
+	return health
+}
+
+func (c HealthChecker) endpoint(provider types.LLMProvider, credential string) (string, bool) {
+	if endpoint, ok := c.Endpoints[provider]; ok {
+		return endpoint, true
+	}
+	if provider == types.ProviderOllama {
+		return ollamaTagsURL(resolveOllamaURL(credential))
+	}
+	switch provider {
+	case types.ProviderOpenAI:
+		return chatgpt.BaseURL(c.Config) + "/models", true
+	case types.ProviderClaude:
+		return claude.ModelsURL(c.Config), true
+	case types.ProviderGemini:
+		return gemini.APIBase(c.Config) + "/models", true
+	case types.ProviderGrok:
+		return grok.ModelsURL(c.Config), true
+	}
+	if preset, ok := openaicompat.Lookup(provider); ok {
+		return preset.APIBase() + "/models", true
+	}
+	if provider == types.ProviderVertex {
+		return vertex.ModelURL(vertex.Location(), ModelFor(types.ProviderVertex)), true
+	}
+	endpoint, ok := healthEndpoints[provider]
+	return endpoint, ok
+}
+
+func (c HealthChecker) request(ctx context.Context, provider types.LLMProvider, credential string) (*http.Request, error) {
+	endpoint, ok := c.endpoint(provider, credential)
+	if !ok {
+		return nil, fmt.Errorf("no health check for %s", provider)
+	}
+	if provider != types.ProviderOllama && provider != types.ProviderVertex && credential == "" {
+		return nil, fmt.Errorf("no API key saved; run: commit llm setup")
+	}
+
+	if provider == types.ProviderGemini {
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("invalid health endpoint: %w", err)
+		}
+		q := u.Query()
+		q.Set("key", credential)
+		u.RawQuery = q.Encode()
+		endpoint = u.String()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid health endpoint: %w", err)
+	}
+
+	switch provider {
+	case types.ProviderVertex:
+		// The credential is an optional service account key file, so
+		// exchange it (or the Application Default Credentials) for a token.
+		creds, err := vertex.LoadCredentials(credential)
+		if err != nil {
+			return nil, err
+		}
+		token, err := creds.Token(ctx)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	case types.ProviderClaude:
+		req.Header.Set("x-api-key", credential)
+		req.Header.Set("anthropic-version", claude.Version())
+	case types.ProviderOpenAI, types.ProviderGrok, types.ProviderGroq, types.ProviderHuggingFace, types.ProviderCohere,
+		types.ProviderTogether, types.ProviderFireworks:
+		req.Header.Set("Authorization", "Bearer "+credential)
+	}
+	return req, nil
+}
+
+// ollamaTagsURL turns a configured generate URL into the /api/tags URL of the
+// same server.
+func ollamaTagsURL(generateURL string) (string, bool) {
+	endpoint, err := ollama.EndpointURL(generateURL, "/api/tags")
+	return endpoint, err == nil
+}
+
+// quotaHeaders collects rate-limit headers such as x-ratelimit-remaining-
+// requests (OpenAI, Groq, Grok) and anthropic-ratelimit-tokens-remaining.
+func quotaHeaders(header http.Header) map[string]string {
+	quota := make(map[string]string)
+	for name, values := range header {
+		lower := strings.ToLower(name)
+		if strings.Contains(lower, "ratelimit") && len(values) > 0 {
+			quota[lower] = values[0]
+		}
+	}
+	if len(quota) == 0 {
+		return nil
+	}
+	return quota
+}
+
+// QuotaSummary renders the remaining request and token counts from Quota,
+// or "" when the provider sent none.
+func (h Health) QuotaSummary() string {
+	var parts []string
+	for name, value := range h.Quota {
+		if !strings.Contains(name, "remaining") {
+			continue
+		}
+		label := name
+		switch {
+		case strings.Contains(name, "request"):
+			label = "requests left"
+		case strings.Contains(name, "input-token"):
+			label = "input tokens left"
+		case strings.Contains(name, "output-token"):
+			label = "output tokens left"
+		case strings.Contains(name, "token"):
+			label = "tokens left"
+		}
+		parts = append(parts, fmt.Sprintf("%s: %s", label, value))
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,109 @@
+package llm
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dfanso/commit-msg/pkg/types"
+)
+
+func TestHealthCheckerCheck(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Header.Get("Authorization") {
+		case "Bearer good":
+			w.Header().Set("X-Ratelimit-Remaining-Requests", "99")
+			w.Header().Set("X-Ratelimit-Remaining-Tokens", "5000")
+			w.WriteHeader(http.StatusOK)
+		case "Bearer limited":
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+
+	checker := HealthChecker{
+		HTTP:      server.Client(),
+		Endpoints: map[types.LLMProvider]string{types.ProviderOpenAI: server.URL},
+	}
+
+	ok := checker.Check(context.Background(), types.ProviderOpenAI, "good")
+	if !ok.OK || !ok.Reachable || ok.StatusCode != http.StatusOK {
+		t.Fatalf("expected healthy result, got %+v", ok)
+	}
+	if got, want := ok.QuotaSummary(), "requests left: 99, tokens left: 5000"; got != want {
+		t.Fatalf("QuotaSummary() = %q, want %q", got, want)
+	}
+
+	bad := checker.Check(context.Background(), types.ProviderOpenAI, "expired")
+	if bad.OK || !bad.Reachable || bad.Problem == "" {
+		t.Fatalf("expected invalid key to be flagged, got %+v", bad)
+	}
+
+	limited := checker.Check(context.Background(), types.ProviderOpenAI, "limited")
+	if limited.OK || limited.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("expected rate limit to be flagged, got %+v", limited)
+	}
+
+	missing := checker.Check(context.Background(), types.ProviderOpenAI, "")
+	if missing.OK || missing.Reachable {
+		t.Fatalf("expected missing key to fail without a request, got %+v", missing)
+	}
+}
+
+func TestHealthCheckerGeminiUsesQueryKey(t *testing.T) {
+	t.Parallel()
+
+	var gotKey string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotKey = r.URL.Query().Get("key")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	checker := HealthChecker{
+		HTTP:      server.Client(),
+		Endpoints: map[types.LLMProvider]string{types.ProviderGemini: server.URL + "/v1beta/models"},
+	}
+	if health := checker.Check(context.Background(), types.ProviderGemini, "gem-key"); !health.OK {
+		t.Fatalf("expected healthy result, got %+v", health)
+	}
+	if gotKey != "gem-key" {
+		t.Fatalf("key query parameter = %q, want gem-key", gotKey)
+	}
+}
+
+func TestHealthCheckerHonoursConfiguredEndpoints(t *testing.T) {
+	t.Setenv("CLAUDE_API_URL", "")
+
+	var gotPath string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	checker := HealthChecker{
+		HTTP:   server.Client(),
+		Config: &types.Config{ClaudeAPI: server.URL + "/anthropic/v1/messages"},
+	}
+	if health := checker.Check(context.Background(), types.ProviderClaude, "sk-ant"); !health.OK {
+		t.Fatalf("expected healthy result, got %+v", health)
+	}
+	if gotPath != "/anthropic/v1/models" {
+		t.Fatalf("checked %q, want the gateway's model listing", gotPath)
+	}
+}
+
+func TestOllamaTagsURL(t *testing.T) {
+	t.Parallel()
+
+	got, ok := ollamaTagsURL("http://localhost:11434/api/generate")
+	if !ok || got != "http://localhost:11434/api/tags" {
+		t.Fatalf("ollamaTagsURL() = %q, %v", got, ok)
+	}
+}
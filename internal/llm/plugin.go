@@ -0,0 +1,159 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/dfanso/commit-msg/pkg/types"
+)
+
+// PluginExecutablePrefix is prepended to a plugin name to find its
+// executable on PATH, so plugin "mycompany" runs commit-provider-mycompany.
+const PluginExecutablePrefix = "commit-provider-"
+
+// PluginProtocolVersion is sent with every plugin request so executables
+// can reject requests they do not understand.
+const PluginProtocolVersion = 1
+
+// PluginRequest is written as JSON to a plugin's stdin.
+type PluginRequest struct {
+	Version    int           `json:"version"`
+	Prompt     string        `json:"prompt"`
+	Changes    string        `json:"changes"`
+	Credential string        `json:"credential,omitempty"`
+	Options    PluginOptions `json:"options"`
+}
+
+// PluginOptions carries the generation options a plugin may honour.
+type PluginOptions struct {
+	StyleInstruction string   `json:"style_instruction,omitempty"`
+	Attempt          int      `json:"attempt,omitempty"`
+	Temperature      *float64 `json:"temperature,omitempty"`
+	Seed             *int64   `json:"seed,omitempty"`
+	MaxTokens        int      `json:"max_tokens,omitempty"`
+}
+
+// PluginResponse is read as JSON from a plugin's stdout. A non-empty Error
+// reports a failure even when the plugin exits successfully.
+type PluginResponse struct {
+	Message string `json:"message"`
+	Error   string `json:"error,omitempty"`
+}
+
+// FindPlugin returns the path of the executable implementing plugin name.
+func FindPlugin(name string) (string, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) {
+		return "", fmt.Errorf("invalid plugin name %q", name)
+	}
+	path, err := exec.LookPath(PluginExecutablePrefix + name)
+	if err != nil {
+		return "", fmt.Errorf("plugin %q not found: put an executable named %s%s on your PATH", name, PluginExecutablePrefix, name)
+	}
+	return path, nil
+}
+
+// DiscoverPlugins lists the names of the plugin executables on PATH.
+func DiscoverPlugins() []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name, ok := strings.CutPrefix(entry.Name(), PluginExecutablePrefix)
+			if !ok || entry.IsDir() {
+				continue
+			}
+			name = strings.TrimSuffix(name, filepath.Ext(name))
+			if name == "" || seen[name] {
+				continue
+			}
+			if _, err := FindPlugin(name); err != nil {
+				continue
+			}
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+type pluginProvider struct {
+	name       types.LLMProvider
+	path       string
+	credential string
+}
+
+func newPluginProvider(name types.LLMProvider, opts ProviderOptions) (Provider, error) {
+	path, err := FindPlugin(name.PluginName())
+	if err != nil {
+		return nil, err
+	}
+	return &pluginProvider{name: name, path: path, credential: strings.TrimSpace(opts.Credential)}, nil
+}
+
+func (p *pluginProvider) Name() types.LLMProvider {
+	return p.name
+}
+
+func (p *pluginProvider) Generate(ctx context.Context, changes string, opts *types.GenerationOptions) (string, error) {
+	req := PluginRequest{
+		Version:    PluginProtocolVersion,
+		Prompt:     types.BuildCommitPrompt(changes, opts),
+		Changes:    changes,
+		Credential: p.credential,
+	}
+	if opts != nil {
+		req.Options = PluginOptions{
+			StyleInstruction: opts.Style(),
+			Attempt:          opts.Attempt,
+			Temperature:      opts.Temperature,
+			Seed:             opts.Seed,
+			MaxTokens:        opts.MaxTokens,
+		}
+	}
+
+	input, err := json.Marshal(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal plugin request: %w", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, p.path)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		if detail := strings.TrimSpace(stderr.String()); detail != "" {
+			return "", fmt.Errorf("plugin %s failed: %w: %s", p.name.PluginName(), err, detail)
+		}
+		return "", fmt.Errorf("plugin %s failed: %w", p.name.PluginName(), err)
+	}
+
+	var resp PluginResponse
+	if err := json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+		return "", fmt.Errorf("plugin %s returned invalid JSON: %w", p.name.PluginName(), err)
+	}
+	if resp.Error != "" {
+		return "", fmt.Errorf("plugin %s: %s", p.name.PluginName(), resp.Error)
+	}
+	if strings.TrimSpace(resp.Message) == "" {
+		return "", errors.New("plugin " + p.name.PluginName() + " returned an empty message")
+	}
+	return sanitized(p.name, resp.Message, nil)
+}
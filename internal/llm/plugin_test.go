@@ -0,0 +1,89 @@
+package llm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/dfanso/commit-msg/pkg/types"
+)
+
+// writePlugin installs a shell-script plugin named name into a temporary
+// directory placed on PATH.
+func writePlugin(t *testing.T, name, script string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("shell-script plugins require a POSIX shell")
+	}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, PluginExecutablePrefix+name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0o755); err != nil {
+		t.Fatalf("failed to write plugin: %v", err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestPluginProviderGenerate(t *testing.T) {
+	// The plugin echoes back whether it received the credential and prompt.
+	writePlugin(t, "acme", `input=$(cat)
+case "$input" in
+  *'"credential":"secret"'*'"style_instruction":"be brief"'*) ;;
+  *) echo '{"error":"unexpected request"}'; exit 0 ;;
+esac
+echo '{"message":"feat: add acme gateway"}'
+`)
+
+	provider, err := NewProvider(types.PluginProvider("acme"), ProviderOptions{Credential: "secret"})
+	if err != nil {
+		t.Fatalf("NewProvider: %v", err)
+	}
+	if provider.Name() != "plugin:acme" {
+		t.Fatalf("unexpected name %q", provider.Name())
+	}
+
+	message, err := provider.Generate(context.Background(), "some changes", &types.GenerationOptions{StyleInstruction: "be brief"})
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if message != "feat: add acme gateway" {
+		t.Fatalf("unexpected message %q", message)
+	}
+}
+
+func TestPluginProviderErrors(t *testing.T) {
+	writePlugin(t, "broken", `cat >/dev/null
+echo "gateway unavailable" >&2
+exit 3
+`)
+
+	provider, err := NewProvider(types.PluginProvider("broken"), ProviderOptions{})
+	if err != nil {
+		t.Fatalf("NewProvider: %v", err)
+	}
+	_, err = provider.Generate(context.Background(), "some changes", nil)
+	if err == nil || !strings.Contains(err.Error(), "gateway unavailable") {
+		t.Fatalf("expected stderr in error, got %v", err)
+	}
+
+	if _, err := NewProvider(types.PluginProvider("missing-plugin"), ProviderOptions{}); err == nil {
+		t.Fatal("expected error for missing plugin")
+	}
+}
+
+func TestDiscoverPlugins(t *testing.T) {
+	writePlugin(t, "acme", "exit 0\n")
+
+	found := false
+	for _, name := range DiscoverPlugins() {
+		if name == "acme" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatal("expected acme plugin to be discovered")
+	}
+}
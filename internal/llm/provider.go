@@ -4,22 +4,45 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
+	"net/url"
 	"os"
 	"strings"
 	"sync"
 
 	"github.com/dfanso/commit-msg/internal/chatgpt"
 	"github.com/dfanso/commit-msg/internal/claude"
+	"github.com/dfanso/commit-msg/internal/cohere"
 	"github.com/dfanso/commit-msg/internal/gemini"
 	"github.com/dfanso/commit-msg/internal/grok"
 	"github.com/dfanso/commit-msg/internal/groq"
+	"github.com/dfanso/commit-msg/internal/huggingface"
+	"github.com/dfanso/commit-msg/internal/llmerr"
 	"github.com/dfanso/commit-msg/internal/ollama"
+	"github.com/dfanso/commit-msg/internal/openaicompat"
+	"github.com/dfanso/commit-msg/internal/vertex"
 	"github.com/dfanso/commit-msg/pkg/types"
 )
 
 // ErrMissingCredential signals that a provider requires a credential such as an API key or URL.
 var ErrMissingCredential = errors.New("llm: missing credential")
 
+// Typed provider failures, re-exported from internal/llmerr. Provider errors
+// wrap one of these when the provider's error payload could be classified.
+var (
+	ErrRateLimited     = llmerr.ErrRateLimited
+	ErrQuotaExceeded   = llmerr.ErrQuotaExceeded
+	ErrAuth            = llmerr.ErrAuth
+	ErrModelNotFound   = llmerr.ErrModelNotFound
+	ErrContextTooLarge = llmerr.ErrContextTooLarge
+)
+
+// Retryable reports whether err is a transient provider failure (a rate
+// limit or server error) that may succeed if the request is repeated.
+func Retryable(err error) bool {
+	return llmerr.Retryable(err)
+}
+
 // Provider declares the behaviour required by commit-msg to talk to an LLM backend.
 type Provider interface {
 	// Name returns the LLM provider identifier this instance represents.
@@ -28,15 +51,46 @@ type Provider interface {
 	Generate(ctx context.Context, changes string, opts *types.GenerationOptions) (string, error)
 }
 
+// UsageReporter is implemented by providers that can report the token usage
+// of their most recent Generate call.
+type UsageReporter interface {
+	// LastUsage returns the usage of the last successful request, or nil
+	// when the provider did not report any.
+	LastUsage() *types.UsageInfo
+}
+
+// CandidateGenerator is implemented by providers whose API can return
+// several alternative messages from a single request.
+type CandidateGenerator interface {
+	// GenerateCandidates requests n commit messages for the supplied
+	// repository changes. The provider may return fewer.
+	GenerateCandidates(ctx context.Context, changes string, opts *types.GenerationOptions, n int) ([]string, error)
+}
+
 // ProviderOptions captures the data needed to construct a provider instance.
 type ProviderOptions struct {
 	Credential string
 	Config     *types.Config
+	// Endpoint points an OpenAI, Claude, Gemini, or Grok provider at another
+	// server, such as a gateway or a test server, taking the place of the
+	// matching Config field.
+	Endpoint string
 }
 
 // Factory describes a function capable of building a Provider.
 type Factory func(ProviderOptions) (Provider, error)
 
+// defaultOllamaModel is used when neither OLLAMA_MODEL nor a saved model is set.
+const defaultOllamaModel = ollama.RecommendedModel
+
+// defaultOllamaURL is used when neither a credential nor OLLAMA_URL is set.
+const defaultOllamaURL = "http://localhost:11434/api/generate"
+
+var (
+	modelsMu         sync.RWMutex
+	configuredModels = map[types.LLMProvider]string{}
+)
+
 var (
 	factoryMu sync.RWMutex
 	factories = map[types.LLMProvider]Factory{
@@ -46,6 +100,12 @@ var (
 		types.ProviderGrok:   newGrokProvider,
 		types.ProviderGroq:   newGroqProvider,
 		types.ProviderOllama: newOllamaProvider,
+
+		types.ProviderHuggingFace: newHuggingFaceProvider,
+		types.ProviderVertex:      newVertexProvider,
+		types.ProviderCohere:      newCohereProvider,
+		types.ProviderTogether:    compatFactory(openaicompat.Together),
+		types.ProviderFireworks:   compatFactory(openaicompat.Fireworks),
 	}
 )
 
@@ -61,14 +121,43 @@ func NewProvider(name types.LLMProvider, opts ProviderOptions) (Provider, error)
 	factoryMu.RLock()
 	factory, ok := factories[name]
 	factoryMu.RUnlock()
+	if !ok && name.IsPlugin() {
+		return newPluginProvider(name, opts)
+	}
 	if !ok {
 		return nil, fmt.Errorf("llm: unsupported provider %s", name)
 	}
 
 	opts.Config = ensureConfig(opts.Config)
+	if endpoint := strings.TrimSpace(opts.Endpoint); endpoint != "" {
+		config, err := withEndpoint(name, opts.Config, endpoint)
+		if err != nil {
+			return nil, err
+		}
+		opts.Config = config
+	}
 	return factory(opts)
 }
 
+// withEndpoint returns a copy of cfg with the endpoint of the named provider
+// set to endpoint.
+func withEndpoint(name types.LLMProvider, cfg *types.Config, endpoint string) (*types.Config, error) {
+	updated := *cfg
+	switch name {
+	case types.ProviderOpenAI:
+		updated.OpenAIAPI = endpoint
+	case types.ProviderClaude:
+		updated.ClaudeAPI = endpoint
+	case types.ProviderGemini:
+		updated.GeminiAPI = endpoint
+	case types.ProviderGrok:
+		updated.GrokAPI = endpoint
+	default:
+		return nil, fmt.Errorf("llm: %s does not support an endpoint override", name)
+	}
+	return &updated, nil
+}
+
 type missingCredentialError struct {
 	provider types.LLMProvider
 }
@@ -85,6 +174,103 @@ func newMissingCredentialError(provider types.LLMProvider) error {
 	return &missingCredentialError{provider: provider}
 }
 
+// ConfigureModels sets the models saved per provider by `commit llm setup`.
+// Environment overrides such as OLLAMA_MODEL still take precedence.
+func ConfigureModels(models map[types.LLMProvider]string) {
+	modelsMu.Lock()
+	defer modelsMu.Unlock()
+	configuredModels = make(map[types.LLMProvider]string, len(models))
+	for provider, model := range models {
+		if model = strings.TrimSpace(model); model != "" {
+			configuredModels[provider] = model
+		}
+	}
+}
+
+func configuredModel(name types.LLMProvider) string {
+	modelsMu.RLock()
+	defer modelsMu.RUnlock()
+	return configuredModels[name]
+}
+
+// ModelFor returns the model identifier the named provider requests, honouring
+// the same environment overrides as the provider implementations.
+func ModelFor(name types.LLMProvider) string {
+	switch name {
+	case types.ProviderOpenAI:
+		return chatgpt.DefaultModel
+	case types.ProviderClaude:
+		return claude.ResolveModel()
+	case types.ProviderGemini:
+		return gemini.ResolveModel()
+	case types.ProviderGrok:
+		return grok.ResolveModel(configuredModel(types.ProviderGrok))
+	case types.ProviderGroq:
+		return groq.ResolveModel(configuredModel(types.ProviderGroq))
+	case types.ProviderOllama:
+		return resolveOllamaModel()
+	case types.ProviderHuggingFace:
+		return huggingface.ResolveModel(configuredModel(types.ProviderHuggingFace))
+	case types.ProviderVertex:
+		return vertex.ResolveModel(configuredModel(types.ProviderVertex))
+	case types.ProviderCohere:
+		return cohere.ResolveModel(configuredModel(types.ProviderCohere))
+	default:
+		if preset, ok := openaicompat.Lookup(name); ok {
+			return preset.ResolveModel(configuredModel(name))
+		}
+		return ""
+	}
+}
+
+func resolveOllamaModel() string {
+	model := strings.TrimSpace(os.Getenv("OLLAMA_MODEL"))
+	if model == "" {
+		model = configuredModel(types.ProviderOllama)
+	}
+	if model == "" {
+		model = defaultOllamaModel
+	}
+	return model
+}
+
+// ResolveOllamaURL returns the generate endpoint for an Ollama credential,
+// falling back to OLLAMA_URL and then the local default.
+func ResolveOllamaURL(credential string) string {
+	return resolveOllamaURL(credential)
+}
+
+func resolveOllamaURL(credential string) string {
+	endpoint := strings.TrimSpace(credential)
+	if endpoint == "" {
+		endpoint = strings.TrimSpace(os.Getenv("OLLAMA_URL"))
+		if endpoint == "" {
+			endpoint = defaultOllamaURL
+		}
+	}
+	return endpoint
+}
+
+// IsLocal reports whether the named provider, configured with credential,
+// runs on this machine and so keeps working without network access. Only an
+// Ollama endpoint on a loopback address qualifies.
+func IsLocal(name types.LLMProvider, credential string) bool {
+	if name != types.ProviderOllama {
+		return false
+	}
+
+	endpoint, err := url.Parse(resolveOllamaURL(credential))
+	if err != nil {
+		return false
+	}
+	host := endpoint.Hostname()
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
 func ensureConfig(cfg *types.Config) *types.Config {
 	if cfg != nil {
 		return cfg
@@ -115,12 +301,27 @@ func (p *openAIProvider) Name() types.LLMProvider {
 }
 
 func (p *openAIProvider) Generate(_ context.Context, changes string, opts *types.GenerationOptions) (string, error) {
-	return chatgpt.GenerateCommitMessage(p.config, changes, p.apiKey, opts)
+	message, err := chatgpt.GenerateCommitMessage(p.config, changes, p.apiKey, opts)
+	return sanitized(types.ProviderOpenAI, message, err)
+}
+
+func (p *openAIProvider) GenerateCandidates(_ context.Context, changes string, opts *types.GenerationOptions, n int) ([]string, error) {
+	messages, err := chatgpt.GenerateCandidates(p.config, changes, p.apiKey, opts, n)
+	if err != nil {
+		return nil, err
+	}
+	for i, message := range messages {
+		messages[i] = Sanitize(types.ProviderOpenAI, message)
+	}
+	return messages, nil
 }
 
 type claudeProvider struct {
 	apiKey string
 	config *types.Config
+
+	mu    sync.Mutex
+	usage *types.UsageInfo
 }
 
 func newClaudeProvider(opts ProviderOptions) (Provider, error) {
@@ -139,7 +340,19 @@ func (p *claudeProvider) Name() types.LLMProvider {
 }
 
 func (p *claudeProvider) Generate(_ context.Context, changes string, opts *types.GenerationOptions) (string, error) {
-	return claude.GenerateCommitMessage(p.config, changes, p.apiKey, opts)
+	message, usage, err := claude.GenerateWithUsage(p.config, changes, p.apiKey, opts)
+	if err == nil {
+		p.mu.Lock()
+		p.usage = usage
+		p.mu.Unlock()
+	}
+	return sanitized(types.ProviderClaude, message, err)
+}
+
+func (p *claudeProvider) LastUsage() *types.UsageInfo {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.usage
 }
 
 type geminiProvider struct {
@@ -162,13 +375,18 @@ func (p *geminiProvider) Name() types.LLMProvider {
 	return types.ProviderGemini
 }
 
-func (p *geminiProvider) Generate(_ context.Context, changes string, opts *types.GenerationOptions) (string, error) {
-	return gemini.GenerateCommitMessage(p.config, changes, p.apiKey, opts)
+func (p *geminiProvider) Generate(ctx context.Context, changes string, opts *types.GenerationOptions) (string, error) {
+	message, err := gemini.GenerateCommitMessage(ctx, p.config, changes, p.apiKey, opts)
+	return sanitized(types.ProviderGemini, message, err)
 }
 
 type grokProvider struct {
 	apiKey string
+	model  string
 	config *types.Config
+
+	mu    sync.Mutex
+	usage *types.UsageInfo
 }
 
 func newGrokProvider(opts ProviderOptions) (Provider, error) {
@@ -179,19 +397,32 @@ func newGrokProvider(opts ProviderOptions) (Provider, error) {
 	if key == "" {
 		return nil, newMissingCredentialError(types.ProviderGrok)
 	}
-	return &grokProvider{apiKey: key, config: opts.Config}, nil
+	return &grokProvider{apiKey: key, model: grok.ResolveModel(configuredModel(types.ProviderGrok)), config: opts.Config}, nil
 }
 
 func (p *grokProvider) Name() types.LLMProvider {
 	return types.ProviderGrok
 }
 
-func (p *grokProvider) Generate(_ context.Context, changes string, opts *types.GenerationOptions) (string, error) {
-	return grok.GenerateCommitMessage(p.config, changes, p.apiKey, opts)
+func (p *grokProvider) Generate(ctx context.Context, changes string, opts *types.GenerationOptions) (string, error) {
+	message, usage, err := grok.GenerateCommitMessage(ctx, p.config, changes, p.apiKey, p.model, opts)
+	if err == nil {
+		p.mu.Lock()
+		p.usage = usage
+		p.mu.Unlock()
+	}
+	return sanitized(types.ProviderGrok, message, err)
+}
+
+func (p *grokProvider) LastUsage() *types.UsageInfo {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.usage
 }
 
 type groqProvider struct {
 	apiKey string
+	model  string
 	config *types.Config
 }
 
@@ -203,7 +434,7 @@ func newGroqProvider(opts ProviderOptions) (Provider, error) {
 	if key == "" {
 		return nil, newMissingCredentialError(types.ProviderGroq)
 	}
-	return &groqProvider{apiKey: key, config: opts.Config}, nil
+	return &groqProvider{apiKey: key, model: groq.ResolveModel(configuredModel(types.ProviderGroq)), config: opts.Config}, nil
 }
 
 func (p *groqProvider) Name() types.LLMProvider {
@@ -211,30 +442,149 @@ func (p *groqProvider) Name() types.LLMProvider {
 }
 
 func (p *groqProvider) Generate(_ context.Context, changes string, opts *types.GenerationOptions) (string, error) {
-	return groq.GenerateCommitMessage(p.config, changes, p.apiKey, opts)
+	message, err := groq.GenerateCommitMessage(p.config, changes, p.apiKey, p.model, opts)
+	return sanitized(types.ProviderGroq, message, err)
 }
 
-type ollamaProvider struct {
-	url    string
+type huggingFaceProvider struct {
+	token  string
 	model  string
 	config *types.Config
 }
 
-func newOllamaProvider(opts ProviderOptions) (Provider, error) {
-	url := strings.TrimSpace(opts.Credential)
-	if url == "" {
-		url = strings.TrimSpace(os.Getenv("OLLAMA_URL"))
-		if url == "" {
-			url = "http://localhost:11434/api/generate"
+func newHuggingFaceProvider(opts ProviderOptions) (Provider, error) {
+	token := strings.TrimSpace(opts.Credential)
+	if token == "" {
+		token = strings.TrimSpace(os.Getenv("HF_TOKEN"))
+	}
+	if token == "" {
+		return nil, newMissingCredentialError(types.ProviderHuggingFace)
+	}
+	return &huggingFaceProvider{token: token, model: huggingface.ResolveModel(configuredModel(types.ProviderHuggingFace)), config: opts.Config}, nil
+}
+
+func (p *huggingFaceProvider) Name() types.LLMProvider {
+	return types.ProviderHuggingFace
+}
+
+func (p *huggingFaceProvider) Generate(_ context.Context, changes string, opts *types.GenerationOptions) (string, error) {
+	message, err := huggingface.GenerateCommitMessage(p.config, changes, p.token, p.model, opts)
+	return sanitized(types.ProviderHuggingFace, message, err)
+}
+
+type cohereProvider struct {
+	apiKey string
+	model  string
+
+	mu    sync.Mutex
+	usage *types.UsageInfo
+}
+
+func newCohereProvider(opts ProviderOptions) (Provider, error) {
+	key := strings.TrimSpace(opts.Credential)
+	if key == "" {
+		key = strings.TrimSpace(os.Getenv("COHERE_API_KEY"))
+	}
+	if key == "" {
+		return nil, newMissingCredentialError(types.ProviderCohere)
+	}
+	return &cohereProvider{apiKey: key, model: cohere.ResolveModel(configuredModel(types.ProviderCohere))}, nil
+}
+
+func (p *cohereProvider) Name() types.LLMProvider {
+	return types.ProviderCohere
+}
+
+func (p *cohereProvider) Generate(ctx context.Context, changes string, opts *types.GenerationOptions) (string, error) {
+	message, usage, err := cohere.GenerateCommitMessage(ctx, changes, p.apiKey, p.model, opts)
+	if err == nil {
+		p.mu.Lock()
+		p.usage = usage
+		p.mu.Unlock()
+	}
+	return sanitized(types.ProviderCohere, message, err)
+}
+
+func (p *cohereProvider) LastUsage() *types.UsageInfo {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.usage
+}
+
+// compatProvider serves a hosted OpenAI-compatible preset such as Together
+// AI or Fireworks.
+type compatProvider struct {
+	preset openaicompat.Preset
+	apiKey string
+	model  string
+
+	mu    sync.Mutex
+	usage *types.UsageInfo
+}
+
+func compatFactory(preset openaicompat.Preset) Factory {
+	return func(opts ProviderOptions) (Provider, error) {
+		key := preset.APIKey(opts.Credential)
+		if key == "" {
+			return nil, newMissingCredentialError(preset.Provider)
 		}
+		return &compatProvider{preset: preset, apiKey: key, model: preset.ResolveModel(configuredModel(preset.Provider))}, nil
 	}
+}
 
-	model := strings.TrimSpace(os.Getenv("OLLAMA_MODEL"))
-	if model == "" {
-		model = "llama3.1"
+func (p *compatProvider) Name() types.LLMProvider {
+	return p.preset.Provider
+}
+
+func (p *compatProvider) Generate(ctx context.Context, changes string, opts *types.GenerationOptions) (string, error) {
+	message, usage, err := p.preset.Generate(ctx, nil, changes, p.apiKey, p.model, opts)
+	if err == nil {
+		p.mu.Lock()
+		p.usage = usage
+		p.mu.Unlock()
 	}
+	return sanitized(p.preset.Provider, message, err)
+}
+
+func (p *compatProvider) LastUsage() *types.UsageInfo {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.usage
+}
+
+// vertexProvider authenticates with the service account key file saved as
+// its credential, or with Application Default Credentials when none was
+// given, so it never reports a missing credential.
+type vertexProvider struct {
+	keyFile string
+	model   string
+}
+
+func newVertexProvider(opts ProviderOptions) (Provider, error) {
+	return &vertexProvider{keyFile: strings.TrimSpace(opts.Credential), model: vertex.ResolveModel(configuredModel(types.ProviderVertex))}, nil
+}
+
+func (p *vertexProvider) Name() types.LLMProvider {
+	return types.ProviderVertex
+}
+
+func (p *vertexProvider) Generate(ctx context.Context, changes string, opts *types.GenerationOptions) (string, error) {
+	message, err := vertex.GenerateCommitMessage(ctx, changes, p.keyFile, p.model, opts)
+	return sanitized(types.ProviderVertex, message, err)
+}
 
-	return &ollamaProvider{url: url, model: model, config: opts.Config}, nil
+type ollamaProvider struct {
+	url    string
+	model  string
+	config *types.Config
+}
+
+func newOllamaProvider(opts ProviderOptions) (Provider, error) {
+	url := resolveOllamaURL(opts.Credential)
+	if err := ollama.ValidateURL(url); err != nil {
+		return nil, err
+	}
+	return &ollamaProvider{url: url, model: resolveOllamaModel(), config: opts.Config}, nil
 }
 
 func (p *ollamaProvider) Name() types.LLMProvider {
@@ -242,5 +592,6 @@ func (p *ollamaProvider) Name() types.LLMProvider {
 }
 
 func (p *ollamaProvider) Generate(_ context.Context, changes string, opts *types.GenerationOptions) (string, error) {
-	return ollama.GenerateCommitMessage(p.config, changes, p.url, p.model, opts)
+	message, err := ollama.GenerateCommitMessage(p.config, changes, p.url, p.model, opts)
+	return sanitized(types.ProviderOllama, message, err)
 }
@@ -3,6 +3,8 @@ package llm
 import (
 	"context"
 	"errors"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/dfanso/commit-msg/pkg/types"
@@ -15,6 +17,10 @@ func TestNewProviderRequiresCredential(t *testing.T) {
 		types.ProviderGemini,
 		types.ProviderGrok,
 		types.ProviderGroq,
+		types.ProviderHuggingFace,
+		types.ProviderCohere,
+		types.ProviderTogether,
+		types.ProviderFireworks,
 	}
 
 	for _, provider := range remoteProviders {
@@ -31,6 +37,14 @@ func TestNewProviderRequiresCredential(t *testing.T) {
 				t.Setenv("GROK_API_KEY", "")
 			case types.ProviderGroq:
 				t.Setenv("GROQ_API_KEY", "")
+			case types.ProviderHuggingFace:
+				t.Setenv("HF_TOKEN", "")
+			case types.ProviderCohere:
+				t.Setenv("COHERE_API_KEY", "")
+			case types.ProviderTogether:
+				t.Setenv("TOGETHER_API_KEY", "")
+			case types.ProviderFireworks:
+				t.Setenv("FIREWORKS_API_KEY", "")
 			}
 
 			_, err := NewProvider(provider, ProviderOptions{})
@@ -65,6 +79,36 @@ func TestNewProviderUnsupported(t *testing.T) {
 	}
 }
 
+func TestNewProviderEndpoint(t *testing.T) {
+	t.Setenv("CLAUDE_API_URL", "")
+	t.Setenv("CLAUDE_MODEL", "")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/messages" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"msg_1","type":"message","content":[{"type":"text","text":"fix: handle nil config"}],"usage":{"input_tokens":50,"output_tokens":6}}`))
+	}))
+	defer server.Close()
+
+	provider, err := NewProvider(types.ProviderClaude, ProviderOptions{Credential: "sk-ant", Endpoint: server.URL + "/v1/messages"})
+	if err != nil {
+		t.Fatalf("NewProvider: %v", err)
+	}
+	message, err := provider.Generate(context.Background(), "some changes", nil)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if message != "fix: handle nil config" {
+		t.Fatalf("unexpected message %q", message)
+	}
+
+	if _, err := NewProvider(types.ProviderGroq, ProviderOptions{Credential: "gsk", Endpoint: server.URL}); err == nil {
+		t.Fatal("expected an endpoint override to be rejected for Groq")
+	}
+}
+
 func TestNewProviderOllamaDefaults(t *testing.T) {
 	t.Setenv("OLLAMA_URL", "")
 	t.Setenv("OLLAMA_MODEL", "")
@@ -88,6 +132,21 @@ func TestNewProviderOllamaDefaults(t *testing.T) {
 	}
 }
 
+func TestConfiguredOllamaModel(t *testing.T) {
+	t.Setenv("OLLAMA_MODEL", "")
+	ConfigureModels(map[types.LLMProvider]string{types.ProviderOllama: "qwen2.5-coder"})
+	t.Cleanup(func() { ConfigureModels(nil) })
+
+	if got := ModelFor(types.ProviderOllama); got != "qwen2.5-coder" {
+		t.Fatalf("expected saved model, got %q", got)
+	}
+
+	t.Setenv("OLLAMA_MODEL", "mistral")
+	if got := ModelFor(types.ProviderOllama); got != "mistral" {
+		t.Fatalf("expected OLLAMA_MODEL to win, got %q", got)
+	}
+}
+
 func TestRegisterFactoryOverrides(t *testing.T) {
 	factoryMu.Lock()
 	original := factories[types.ProviderOpenAI]
@@ -128,3 +187,25 @@ func (f fakeProvider) Name() types.LLMProvider {
 func (f fakeProvider) Generate(context.Context, string, *types.GenerationOptions) (string, error) {
 	return "", nil
 }
+
+func TestIsLocal(t *testing.T) {
+	t.Setenv("OLLAMA_URL", "")
+
+	tests := []struct {
+		provider   types.LLMProvider
+		credential string
+		want       bool
+	}{
+		{types.ProviderOllama, "", true},
+		{types.ProviderOllama, "http://127.0.0.1:11434/api/generate", true},
+		{types.ProviderOllama, "http://[::1]:11434/api/generate", true},
+		{types.ProviderOllama, "https://ollama.example.com/api/generate", false},
+		{types.ProviderOpenAI, "sk-test", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsLocal(tt.provider, tt.credential); got != tt.want {
+			t.Errorf("IsLocal(%s, %q) = %v, want %v", tt.provider, tt.credential, got, tt.want)
+		}
+	}
+}
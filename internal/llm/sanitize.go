@@ -0,0 +1,132 @@
+package llm
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/dfanso/commit-msg/pkg/types"
+)
+
+// sanitizeRules selects which clean-up heuristics apply to a provider.
+type sanitizeRules struct {
+	// reasoning strips <think>-style blocks emitted by reasoning models.
+	reasoning bool
+	// preamble drops chatty lead-ins such as "Here is your commit message:".
+	preamble bool
+	// epilogue drops trailing offers such as "Let me know if...".
+	epilogue bool
+}
+
+// providerRules records which junk each provider is known to produce. Groq,
+// Ollama, and Hugging Face commonly serve reasoning models (DeepSeek-R1, Qwen) that emit
+// <think> blocks; the smaller Grok models are prone to chatty preambles.
+var providerRules = map[types.LLMProvider]sanitizeRules{
+	types.ProviderOpenAI: {preamble: true, epilogue: true},
+	types.ProviderClaude: {preamble: true, epilogue: true},
+	types.ProviderGemini: {preamble: true, epilogue: true},
+	types.ProviderGrok:   {reasoning: true, preamble: true, epilogue: true},
+	types.ProviderGroq:   {reasoning: true, preamble: true, epilogue: true},
+	types.ProviderOllama: {reasoning: true, preamble: true, epilogue: true},
+
+	types.ProviderHuggingFace: {reasoning: true, preamble: true, epilogue: true},
+	types.ProviderVertex:      {preamble: true, epilogue: true},
+	types.ProviderCohere:      {preamble: true, epilogue: true},
+	types.ProviderTogether:    {reasoning: true, preamble: true, epilogue: true},
+	types.ProviderFireworks:   {reasoning: true, preamble: true, epilogue: true},
+}
+
+var (
+	reasoningBlock = regexp.MustCompile(`(?is)<(think|thinking|reasoning|reflection)>.*?</(think|thinking|reasoning|reflection)>`)
+	// reasoningClose catches models whose chat template swallows the
+	// opening tag, leaving only "...</think>" before the answer.
+	reasoningClose = regexp.MustCompile(`(?is)^.*</(think|thinking|reasoning|reflection)>`)
+	// reasoningOpen catches a block that was cut off before it closed.
+	reasoningOpen = regexp.MustCompile(`(?is)<(think|thinking|reasoning|reflection)>.*$`)
+
+	preambleLine = regexp.MustCompile(`(?i)^(sure|certainly|of course|okay|ok|absolutely|great)\b[^\n]*$|` +
+		`^(here('s| is| are)|below is|the following is|i('ve| have) (written|generated|created))\b[^\n]*:\s*$|` +
+		`^[*_#\s]*(suggested |proposed |generated )?commit message[*_\s]*:?[*_\s]*$`)
+	labelPrefix = regexp.MustCompile(`(?i)^[*_]*(suggested |proposed |generated )?commit message[*_]*\s*:[*_]*\s+`)
+
+	epilogueLine = regexp.MustCompile(`(?i)^(let me know|i hope|hope this|feel free|this commit message|this message|note:|explanation:|\(note)`)
+)
+
+// Sanitize removes reasoning blocks, preambles, and epilogues that provider
+// wraps around the commit message. Text that does not match a known junk
+// pattern is returned unchanged apart from surrounding whitespace.
+func Sanitize(provider types.LLMProvider, response string) string {
+	rules, ok := providerRules[provider]
+	if !ok {
+		rules = sanitizeRules{reasoning: true, preamble: true, epilogue: true}
+	}
+
+	text := strings.TrimSpace(response)
+	if rules.reasoning {
+		text = stripReasoning(text)
+	}
+
+	lines := strings.Split(text, "\n")
+	if rules.preamble {
+		lines = stripPreamble(lines)
+	}
+	if rules.epilogue {
+		lines = stripEpilogue(lines)
+	}
+
+	cleaned := strings.TrimSpace(strings.Join(lines, "\n"))
+	if cleaned == "" {
+		// Never turn a response into nothing; let the caller see the original.
+		return strings.TrimSpace(response)
+	}
+	return cleaned
+}
+
+func stripReasoning(text string) string {
+	text = reasoningBlock.ReplaceAllString(text, "")
+	text = reasoningClose.ReplaceAllString(text, "")
+	text = reasoningOpen.ReplaceAllString(text, "")
+	return strings.TrimSpace(text)
+}
+
+func stripPreamble(lines []string) []string {
+	for len(lines) > 0 {
+		first := strings.TrimSpace(lines[0])
+		switch {
+		case first == "":
+			lines = lines[1:]
+		case preambleLine.MatchString(first):
+			lines = lines[1:]
+		default:
+			lines[0] = labelPrefix.ReplaceAllString(first, "")
+			return lines
+		}
+	}
+	return lines
+}
+
+// stripEpilogue drops a trailing paragraph that talks about the message
+// rather than being part of it.
+func stripEpilogue(lines []string) []string {
+	end := len(lines)
+	for end > 0 && strings.TrimSpace(lines[end-1]) == "" {
+		end--
+	}
+
+	start := end
+	for start > 0 && strings.TrimSpace(lines[start-1]) != "" {
+		start--
+	}
+	// Only a separate paragraph can be an epilogue; never drop the subject.
+	if start == 0 || !epilogueLine.MatchString(strings.TrimSpace(lines[start])) {
+		return lines[:end]
+	}
+	return stripEpilogue(lines[:start])
+}
+
+// sanitized applies Sanitize to a provider result, passing errors through.
+func sanitized(provider types.LLMProvider, message string, err error) (string, error) {
+	if err != nil {
+		return message, err
+	}
+	return Sanitize(provider, message), nil
+}
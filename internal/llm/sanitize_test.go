@@ -0,0 +1,85 @@
+package llm
+
+import (
+	"testing"
+
+	"github.com/dfanso/commit-msg/pkg/types"
+)
+
+func TestSanitize(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		provider types.LLMProvider
+		input    string
+		want     string
+	}{
+		{
+			name:     "clean message unchanged",
+			provider: types.ProviderOpenAI,
+			input:    "feat: add login\n\n- validate input",
+			want:     "feat: add login\n\n- validate input",
+		},
+		{
+			name:     "think block",
+			provider: types.ProviderOllama,
+			input:    "<think>\nThe user changed the parser, so...\n</think>\n\nfix(parser): handle empty input",
+			want:     "fix(parser): handle empty input",
+		},
+		{
+			name:     "missing opening think tag",
+			provider: types.ProviderGroq,
+			input:    "Okay, let me look at the diff.\n</think>\nchore: bump deps",
+			want:     "chore: bump deps",
+		},
+		{
+			name:     "unterminated think block",
+			provider: types.ProviderOllama,
+			input:    "docs: update README\n<think>I should also mention",
+			want:     "docs: update README",
+		},
+		{
+			name:     "here is preamble",
+			provider: types.ProviderGrok,
+			input:    "Here is your commit message:\n\nfeat: add cache stats",
+			want:     "feat: add cache stats",
+		},
+		{
+			name:     "acknowledgement and label",
+			provider: types.ProviderGemini,
+			input:    "Sure! Based on the diff provided.\n**Commit message:**\nrefactor: split handlers",
+			want:     "refactor: split handlers",
+		},
+		{
+			name:     "inline label",
+			provider: types.ProviderClaude,
+			input:    "Commit message: fix: close file handles",
+			want:     "fix: close file handles",
+		},
+		{
+			name:     "epilogue",
+			provider: types.ProviderOpenAI,
+			input:    "feat: add retries\n\n- retry on 5xx\n\nLet me know if you'd like a shorter version!",
+			want:     "feat: add retries\n\n- retry on 5xx",
+		},
+		{
+			name:     "reasoning tags kept for providers without reasoning models",
+			provider: types.ProviderClaude,
+			input:    "feat: document <think> tags",
+			want:     "feat: document <think> tags",
+		},
+		{
+			name:     "all junk returns original",
+			provider: types.ProviderOllama,
+			input:    "Sure!",
+			want:     "Sure!",
+		},
+	}
+
+	for _, tt := range tests {
+		if got := Sanitize(tt.provider, tt.input); got != tt.want {
+			t.Errorf("%s: Sanitize() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
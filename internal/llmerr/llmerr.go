@@ -0,0 +1,217 @@
+// Package llmerr classifies provider API failures into typed errors so
+// callers can show the right remediation and decide whether a retry can
+// help. Provider clients build errors with FromResponse; callers test them
+// with errors.Is against the sentinels.
+package llmerr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dfanso/commit-msg/pkg/types"
+)
+
+// Sentinel errors matched with errors.Is.
+var (
+	// ErrRateLimited means too many requests were sent; retrying later works.
+	ErrRateLimited = errors.New("rate limited")
+	// ErrQuotaExceeded means the account is out of credit or quota.
+	ErrQuotaExceeded = errors.New("quota exceeded")
+	// ErrAuth means the API key is missing, invalid, expired, or lacks access.
+	ErrAuth = errors.New("authentication failed")
+	// ErrModelNotFound means the requested model does not exist or is not
+	// available to the account (or, for Ollama, has not been pulled).
+	ErrModelNotFound = errors.New("model not found")
+	// ErrContextTooLarge means the prompt exceeds the model's context window.
+	ErrContextTooLarge = errors.New("prompt too large for the model")
+)
+
+// Error is a failed provider API call.
+type Error struct {
+	Provider   types.LLMProvider
+	StatusCode int
+	// Kind is one of the sentinel errors, or nil when the failure did not
+	// match a known category.
+	Kind error
+	// Message is the provider's own description of the failure.
+	Message string
+	// RetryAfter is the delay requested by the provider, if any.
+	RetryAfter time.Duration
+}
+
+func (e *Error) Error() string {
+	var b strings.Builder
+	b.WriteString(e.Provider.String())
+	b.WriteString(" API error")
+	if e.StatusCode != 0 {
+		fmt.Fprintf(&b, " (status %d)", e.StatusCode)
+	}
+	if e.Kind != nil {
+		b.WriteString(": ")
+		b.WriteString(e.Kind.Error())
+	}
+	if e.Message != "" {
+		b.WriteString(": ")
+		b.WriteString(e.Message)
+	}
+	return b.String()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
+
+// Retryable reports whether repeating the request later may succeed: rate
+// limits, timeouts, and server-side failures qualify, bad keys and oversized
+// prompts do not.
+func Retryable(err error) bool {
+	var netErr net.Error
+	if errors.Is(err, ErrRateLimited) || errors.Is(err, context.DeadlineExceeded) || errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Kind == nil && apiErr.StatusCode >= 500
+	}
+	return false
+}
+
+// Payload holds the fields providers use to describe an error.
+type Payload struct {
+	// Type is e.g. "rate_limit_error" (Anthropic) or "invalid_request_error".
+	Type string
+	// Code is e.g. "insufficient_quota" or "context_length_exceeded".
+	Code string
+	// Status is the Google RPC status such as "RESOURCE_EXHAUSTED".
+	Status string
+	// Message is the human-readable description.
+	Message string
+}
+
+// errorBody covers the JSON error shapes of the supported providers:
+// {"error": {"message", "type", "code"}} (OpenAI, Groq, Grok),
+// {"type": "error", "error": {"type", "message"}} (Anthropic),
+// {"error": {"code", "message", "status"}} (Gemini), and
+// {"error": "message"} (Ollama).
+type errorBody struct {
+	Error json.RawMessage `json:"error"`
+}
+
+type errorObject struct {
+	Message string          `json:"message"`
+	Type    string          `json:"type"`
+	Code    json.RawMessage `json:"code"`
+	Status  string          `json:"status"`
+}
+
+// ParsePayload extracts the error fields from a provider response body. A
+// body that is not a recognised JSON error becomes the message verbatim.
+func ParsePayload(body []byte) Payload {
+	text := strings.TrimSpace(string(body))
+
+	var wrapper errorBody
+	if err := json.Unmarshal(body, &wrapper); err != nil || len(wrapper.Error) == 0 {
+		return Payload{Message: text}
+	}
+
+	var message string
+	if err := json.Unmarshal(wrapper.Error, &message); err == nil {
+		return Payload{Message: message}
+	}
+
+	var obj errorObject
+	if err := json.Unmarshal(wrapper.Error, &obj); err != nil {
+		return Payload{Message: text}
+	}
+
+	// Code is a string for OpenAI and a number for Gemini.
+	code := strings.Trim(string(obj.Code), `"`)
+	if code == "null" {
+		code = ""
+	}
+	return Payload{Type: obj.Type, Code: code, Status: obj.Status, Message: obj.Message}
+}
+
+// FromResponse builds the error for a non-2xx provider response.
+func FromResponse(provider types.LLMProvider, status int, header http.Header, body []byte) *Error {
+	return New(provider, status, header, ParsePayload(body))
+}
+
+// New classifies a provider failure described by status and payload.
+func New(provider types.LLMProvider, status int, header http.Header, payload Payload) *Error {
+	return &Error{
+		Provider:   provider,
+		StatusCode: status,
+		Kind:       classify(status, payload),
+		Message:    payload.Message,
+		RetryAfter: retryAfter(header),
+	}
+}
+
+// Wrap classifies err from its text when a client library hides the HTTP
+// response. Errors that are already typed are returned unchanged.
+func Wrap(provider types.LLMProvider, err error) error {
+	if err == nil {
+		return nil
+	}
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return err
+	}
+	kind := classify(0, Payload{Message: err.Error()})
+	if kind == nil {
+		return err
+	}
+	return &Error{Provider: provider, Kind: kind, Message: err.Error()}
+}
+
+func classify(status int, p Payload) error {
+	lower := strings.ToLower(strings.Join([]string{p.Type, p.Code, p.Status, p.Message}, " "))
+	has := func(words ...string) bool {
+		for _, w := range words {
+			if strings.Contains(lower, w) {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch {
+	case has("context_length_exceeded", "context length", "context window", "maximum context",
+		"prompt is too long", "too many tokens", "request_too_large", "exceeds the maximum number of tokens"):
+		return ErrContextTooLarge
+	case status == http.StatusRequestEntityTooLarge:
+		return ErrContextTooLarge
+	case status == http.StatusUnauthorized || status == http.StatusForbidden,
+		has("authentication_error", "permission_error", "invalid_api_key", "invalid api key",
+			"incorrect api key", "api key not valid", "api_key_invalid", "unauthenticated", "permission_denied"):
+		return ErrAuth
+	case has("insufficient_quota", "quota", "billing", "credit balance", "resource_exhausted"):
+		return ErrQuotaExceeded
+	case status == http.StatusTooManyRequests, has("rate_limit", "rate limit"):
+		return ErrRateLimited
+	case has("model_not_found", "model not found", "does not exist", "try pulling it first"),
+		status == http.StatusNotFound && has("model"):
+		return ErrModelNotFound
+	}
+	return nil
+}
+
+// retryAfter reads the Retry-After header in its delay-seconds form.
+func retryAfter(header http.Header) time.Duration {
+	if header == nil {
+		return 0
+	}
+	seconds, err := strconv.Atoi(strings.TrimSpace(header.Get("Retry-After")))
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
@@ -0,0 +1,165 @@
+package llmerr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dfanso/commit-msg/pkg/types"
+)
+
+func TestFromResponseClassifiesPayloads(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		provider types.LLMProvider
+		status   int
+		body     string
+		want     error
+	}{
+		{
+			name:     "openai invalid key",
+			provider: types.ProviderOpenAI,
+			status:   401,
+			body:     `{"error":{"message":"Incorrect API key provided","type":"invalid_request_error","code":"invalid_api_key"}}`,
+			want:     ErrAuth,
+		},
+		{
+			name:     "openai quota",
+			provider: types.ProviderOpenAI,
+			status:   429,
+			body:     `{"error":{"message":"You exceeded your current quota","type":"insufficient_quota","code":"insufficient_quota"}}`,
+			want:     ErrQuotaExceeded,
+		},
+		{
+			name:     "groq rate limit",
+			provider: types.ProviderGroq,
+			status:   429,
+			body:     `{"error":{"message":"Rate limit reached for model","type":"tokens","code":"rate_limit_exceeded"}}`,
+			want:     ErrRateLimited,
+		},
+		{
+			name:     "openai context length",
+			provider: types.ProviderOpenAI,
+			status:   400,
+			body:     `{"error":{"message":"This model's maximum context length is 8192 tokens","code":"context_length_exceeded"}}`,
+			want:     ErrContextTooLarge,
+		},
+		{
+			name:     "anthropic prompt too long",
+			provider: types.ProviderClaude,
+			status:   400,
+			body:     `{"type":"error","error":{"type":"invalid_request_error","message":"prompt is too long: 210000 tokens > 200000 maximum"}}`,
+			want:     ErrContextTooLarge,
+		},
+		{
+			name:     "anthropic rate limit",
+			provider: types.ProviderClaude,
+			status:   429,
+			body:     `{"type":"error","error":{"type":"rate_limit_error","message":"Number of requests has exceeded your rate limit"}}`,
+			want:     ErrRateLimited,
+		},
+		{
+			name:     "anthropic unknown model",
+			provider: types.ProviderClaude,
+			status:   404,
+			body:     `{"type":"error","error":{"type":"not_found_error","message":"model: claude-9"}}`,
+			want:     ErrModelNotFound,
+		},
+		{
+			name:     "gemini bad key",
+			provider: types.ProviderGemini,
+			status:   400,
+			body:     `{"error":{"code":400,"message":"API key not valid. Please pass a valid API key.","status":"INVALID_ARGUMENT"}}`,
+			want:     ErrAuth,
+		},
+		{
+			name:     "gemini quota",
+			provider: types.ProviderGemini,
+			status:   429,
+			body:     `{"error":{"code":429,"message":"Resource has been exhausted","status":"RESOURCE_EXHAUSTED"}}`,
+			want:     ErrQuotaExceeded,
+		},
+		{
+			name:     "ollama missing model",
+			provider: types.ProviderOllama,
+			status:   404,
+			body:     `{"error":"model 'llama9' not found, try pulling it first"}`,
+			want:     ErrModelNotFound,
+		},
+		{
+			name:     "server error",
+			provider: types.ProviderGrok,
+			status:   500,
+			body:     `internal error`,
+			want:     nil,
+		},
+	}
+
+	for _, tt := range tests {
+		err := FromResponse(tt.provider, tt.status, nil, []byte(tt.body))
+		if err.Kind != tt.want {
+			t.Errorf("%s: Kind = %v, want %v", tt.name, err.Kind, tt.want)
+		}
+		if tt.want != nil && !errors.Is(err, tt.want) {
+			t.Errorf("%s: errors.Is(%v) = false", tt.name, tt.want)
+		}
+		if !strings.Contains(err.Error(), fmt.Sprintf("status %d", tt.status)) {
+			t.Errorf("%s: Error() = %q, want status code", tt.name, err.Error())
+		}
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	t.Parallel()
+
+	header := http.Header{}
+	header.Set("Retry-After", "7")
+	limited := FromResponse(types.ProviderGroq, 429, header, []byte(`{"error":{"message":"slow down","code":"rate_limit_exceeded"}}`))
+	if !Retryable(limited) {
+		t.Fatal("expected rate limit to be retryable")
+	}
+	if limited.RetryAfter != 7*time.Second {
+		t.Fatalf("RetryAfter = %v, want 7s", limited.RetryAfter)
+	}
+
+	if !Retryable(fmt.Errorf("wrapped: %w", FromResponse(types.ProviderGrok, 503, nil, nil))) {
+		t.Fatal("expected server errors to be retryable")
+	}
+	if Retryable(FromResponse(types.ProviderOpenAI, 401, nil, nil)) {
+		t.Fatal("expected auth errors not to be retryable")
+	}
+	if Retryable(errors.New("boom")) {
+		t.Fatal("expected untyped errors not to be retryable")
+	}
+	if !Retryable(fmt.Errorf("request failed: %w", context.DeadlineExceeded)) {
+		t.Fatal("expected deadline errors to be retryable")
+	}
+	if !Retryable(&url.Error{Op: "Post", URL: "https://api.example.com", Err: timeoutError{}}) {
+		t.Fatal("expected network timeouts to be retryable")
+	}
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestWrap(t *testing.T) {
+	t.Parallel()
+
+	if err := Wrap(types.ProviderGemini, errors.New("googleapi: Error 400: API key not valid")); !errors.Is(err, ErrAuth) {
+		t.Fatalf("expected ErrAuth, got %v", err)
+	}
+	plain := errors.New("connection reset")
+	if err := Wrap(types.ProviderGemini, plain); err != plain {
+		t.Fatalf("expected unclassified error to be returned unchanged, got %v", err)
+	}
+}
@@ -0,0 +1,261 @@
+// Package lockfile replaces the diffs of dependency lockfiles such as go.sum,
+// package-lock.json, and yarn.lock with a short list of the dependency
+// versions that changed, which says far more in far fewer tokens.
+package lockfile
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/dfanso/commit-msg/internal/diffsection"
+)
+
+// MaxChanges caps how many dependency changes are listed per lockfile.
+const MaxChanges = 40
+
+// Change is one dependency whose locked version changed. From is empty for
+// an added dependency and To is empty for a removed one.
+type Change struct {
+	Name string
+	From string
+	To   string
+}
+
+// String renders the change as "bumped x from a to b", "added x a", or
+// "removed x a".
+func (c Change) String() string {
+	switch {
+	case c.From == "":
+		return fmt.Sprintf("added %s %s", c.Name, c.To)
+	case c.To == "":
+		return fmt.Sprintf("removed %s %s", c.Name, c.From)
+	default:
+		return fmt.Sprintf("bumped %s from %s to %s", c.Name, c.From, c.To)
+	}
+}
+
+// Supported reports whether the lockfile at path can be summarized.
+func Supported(file string) bool {
+	switch path.Base(file) {
+	case "go.sum", "package-lock.json", "npm-shrinkwrap.json", "yarn.lock":
+		return true
+	}
+	return false
+}
+
+// Summarize returns changes with the diff of every supported lockfile
+// replaced by the dependency versions it changed. Other files are kept as
+// they are.
+func Summarize(changes string) string {
+	return diffsection.Replace(changes, func(file string, lines []string) ([]string, bool) {
+		if !Supported(file) {
+			return nil, false
+		}
+		return summarize(file, lines), true
+	})
+}
+
+// Parse returns the dependency changes in the diff lines of the lockfile
+// at file, sorted by name.
+func Parse(file string, diff []string) []Change {
+	var removed, added map[string][]string
+	switch path.Base(file) {
+	case "go.sum":
+		removed, added = parseGoSum(diff)
+	case "package-lock.json", "npm-shrinkwrap.json":
+		removed, added = parseEntries(diff, packageLockKey, packageLockVersion)
+	case "yarn.lock":
+		removed, added = parseEntries(diff, yarnKey, yarnVersion)
+	default:
+		return nil
+	}
+	return compare(removed, added)
+}
+
+func summarize(file string, section []string) []string {
+	added, removed := diffsection.Count(section)
+	changed := added + removed
+
+	changes := Parse(file, section)
+	if len(changes) == 0 {
+		return []string{fmt.Sprintf("Lockfile %s: %d changed lines without dependency version changes omitted.", file, changed)}
+	}
+
+	out := []string{fmt.Sprintf("Lockfile %s: %d changed lines summarized as dependency changes:", file, changed)}
+	for i, change := range changes {
+		if i == MaxChanges {
+			out = append(out, fmt.Sprintf("- ... %d more", len(changes)-MaxChanges))
+			break
+		}
+		out = append(out, "- "+change.String())
+	}
+	return out
+}
+
+// parseGoSum collects the module versions on the removed and added lines of
+// a go.sum diff, such as "+golang.org/x/net v0.19.0/go.mod h1:...".
+func parseGoSum(diff []string) (removed, added map[string][]string) {
+	removed, added = map[string][]string{}, map[string][]string{}
+	for _, line := range diff {
+		if !diffsection.IsChange(line) {
+			continue
+		}
+		fields := strings.Fields(line[1:])
+		if len(fields) != 3 {
+			continue
+		}
+		version := strings.TrimSuffix(fields[1], "/go.mod")
+		if line[0] == '-' {
+			removed[fields[0]] = appendUnique(removed[fields[0]], version)
+		} else {
+			added[fields[0]] = appendUnique(added[fields[0]], version)
+		}
+	}
+	return removed, added
+}
+
+var (
+	// packageLockEntry matches an object key such as
+	// `"node_modules/@babel/core": {`.
+	packageLockEntry   = regexp.MustCompile(`^\s*"([^"]*)": \{\s*$`)
+	packageLockVersion = regexp.MustCompile(`^\s*"version": "([^"]+)"`)
+	// yarnEntry matches an unindented entry such as
+	// `"@babel/core@^7.0.0", "@babel/core@^7.1.0":` or `lodash@^4.17.21:`.
+	yarnEntry   = regexp.MustCompile(`^([^\s#].*):\s*$`)
+	yarnVersion = regexp.MustCompile(`^\s+version:? "?([^"\s]+)"?`)
+)
+
+// packageLockFields are object keys in package-lock.json that never name a
+// package.
+var packageLockFields = map[string]bool{
+	"packages": true, "dependencies": true, "devDependencies": true,
+	"peerDependencies": true, "optionalDependencies": true,
+	"peerDependenciesMeta": true, "requires": true, "engines": true,
+	"bin": true, "funding": true,
+}
+
+// packageLockKey returns the package named by an entry line, and whether
+// line starts an object at all. The name is "" for objects that are not
+// package entries.
+func packageLockKey(line string) (string, bool) {
+	match := packageLockEntry.FindStringSubmatch(line)
+	if match == nil {
+		return "", false
+	}
+	name := match[1]
+	if idx := strings.LastIndex(name, "node_modules/"); idx >= 0 {
+		name = name[idx+len("node_modules/"):]
+	}
+	if name == "" || packageLockFields[name] {
+		return "", true
+	}
+	return name, true
+}
+
+// yarnKey returns the package named by an entry line of yarn.lock.
+func yarnKey(line string) (string, bool) {
+	match := yarnEntry.FindStringSubmatch(line)
+	if match == nil {
+		return "", false
+	}
+	spec, _, _ := strings.Cut(match[1], ",")
+	spec = strings.Trim(strings.TrimSpace(spec), `"`)
+	// Yarn 2+ keeps its own settings under "__metadata".
+	if strings.HasPrefix(spec, "__") {
+		return "", true
+	}
+	// The version range follows the last "@"; a leading "@" is a scope.
+	if idx := strings.LastIndex(spec, "@"); idx > 0 {
+		spec = spec[:idx]
+	}
+	return spec, true
+}
+
+// parseEntries collects the versions on the removed and added lines of a
+// lockfile made of package entries followed by a version line. The entry a
+// version belongs to is tracked separately for the old and new sides.
+func parseEntries(diff []string, key func(string) (string, bool), version *regexp.Regexp) (removed, added map[string][]string) {
+	removed, added = map[string][]string{}, map[string][]string{}
+	var oldKey, newKey string
+	for _, line := range diff {
+		if strings.HasPrefix(line, "@@") {
+			oldKey, newKey = "", ""
+			continue
+		}
+		if line == "" || strings.HasPrefix(line, "+++") || strings.HasPrefix(line, "---") {
+			continue
+		}
+		side, text := line[0], line[1:]
+		if side != ' ' && side != '-' && side != '+' {
+			continue
+		}
+
+		if name, ok := key(text); ok {
+			if side != '+' {
+				oldKey = name
+			}
+			if side != '-' {
+				newKey = name
+			}
+			continue
+		}
+
+		match := version.FindStringSubmatch(text)
+		if match == nil {
+			continue
+		}
+		switch {
+		case side == '-' && oldKey != "":
+			removed[oldKey] = appendUnique(removed[oldKey], match[1])
+		case side == '+' && newKey != "":
+			added[newKey] = appendUnique(added[newKey], match[1])
+		}
+	}
+	return removed, added
+}
+
+// compare pairs the removed and added versions of each dependency.
+// Versions present on both sides, such as a go.sum line that only moved,
+// are not changes.
+func compare(removed, added map[string][]string) []Change {
+	names := map[string]bool{}
+	for name := range removed {
+		names[name] = true
+	}
+	for name := range added {
+		names[name] = true
+	}
+
+	var changes []Change
+	for name := range names {
+		from := without(removed[name], added[name])
+		to := without(added[name], removed[name])
+		if len(from) == 0 && len(to) == 0 {
+			continue
+		}
+		changes = append(changes, Change{Name: name, From: strings.Join(from, ", "), To: strings.Join(to, ", ")})
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Name < changes[j].Name })
+	return changes
+}
+
+func without(values, remove []string) []string {
+	var out []string
+	for _, value := range values {
+		if !slices.Contains(remove, value) {
+			out = append(out, value)
+		}
+	}
+	return out
+}
+
+func appendUnique(values []string, value string) []string {
+	if slices.Contains(values, value) {
+		return values
+	}
+	return append(values, value)
+}
@@ -0,0 +1,144 @@
+package lockfile
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseGoSum(t *testing.T) {
+	t.Parallel()
+
+	diff := strings.Split(`@@ -10,8 +10,8 @@
+-golang.org/x/net v0.17.0 h1:abc=
+-golang.org/x/net v0.17.0/go.mod h1:def=
++golang.org/x/net v0.19.0 h1:ghi=
++golang.org/x/net v0.19.0/go.mod h1:jkl=
+ golang.org/x/sys v0.15.0 h1:mno=
++github.com/new/dep v1.2.0 h1:pqr=
+-github.com/old/dep v0.1.0/go.mod h1:stu=
+-github.com/moved/dep v1.0.0 h1:vwx=
++github.com/moved/dep v1.0.0 h1:vwx=`, "\n")
+
+	want := []Change{
+		{Name: "github.com/new/dep", To: "v1.2.0"},
+		{Name: "github.com/old/dep", From: "v0.1.0"},
+		{Name: "golang.org/x/net", From: "v0.17.0", To: "v0.19.0"},
+	}
+	if got := Parse("go.sum", diff); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Parse(go.sum) = %+v, want %+v", got, want)
+	}
+}
+
+func TestParsePackageLock(t *testing.T) {
+	t.Parallel()
+
+	diff := strings.Split(`@@ -20,9 +20,9 @@
+     "node_modules/@babel/core": {
+-      "version": "7.22.0",
+-      "resolved": "https://registry.npmjs.org/@babel/core/-/core-7.22.0.tgz",
++      "version": "7.23.0",
++      "resolved": "https://registry.npmjs.org/@babel/core/-/core-7.23.0.tgz",
+       "dependencies": {
+         "debug": "^4.1.0"
+       }
+@@ -90,6 +90,10 @@
++    "node_modules/left-pad": {
++      "version": "1.3.0",
++      "license": "MIT"
++    },
+-    "node_modules/a/node_modules/ms": {
+-      "version": "2.0.0"
+-    },`, "\n")
+
+	want := []Change{
+		{Name: "@babel/core", From: "7.22.0", To: "7.23.0"},
+		{Name: "left-pad", To: "1.3.0"},
+		{Name: "ms", From: "2.0.0"},
+	}
+	if got := Parse("web/package-lock.json", diff); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Parse(package-lock.json) = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseYarnLock(t *testing.T) {
+	t.Parallel()
+
+	diff := strings.Split(`@@ -1,8 +1,8 @@
+ "@babel/core@^7.0.0", "@babel/core@^7.22.0":
+-  version "7.22.0"
++  version "7.23.0"
+   resolved "https://registry.yarnpkg.com/@babel/core/-/core-7.23.0.tgz"
+@@ -40,3 +40,3 @@
+-"lodash@npm:^4.17.20":
+-  version: 4.17.20
++"lodash@npm:^4.17.21":
++  version: 4.17.21`, "\n")
+
+	want := []Change{
+		{Name: "@babel/core", From: "7.22.0", To: "7.23.0"},
+		{Name: "lodash", From: "4.17.20", To: "4.17.21"},
+	}
+	if got := Parse("yarn.lock", diff); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Parse(yarn.lock) = %+v, want %+v", got, want)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	t.Parallel()
+
+	changes := `Staged changes:
+M	go.sum
+M	main.go
+
+Staged diff content:
+diff --git a/go.sum b/go.sum
+index 1111111..2222222 100644
+--- a/go.sum
++++ b/go.sum
+@@ -1,2 +1,2 @@
+-golang.org/x/net v0.17.0 h1:abc=
++golang.org/x/net v0.19.0 h1:ghi=
+diff --git a/main.go b/main.go
+index 3333333..4444444 100644
+--- a/main.go
++++ b/main.go
+@@ -1 +1 @@
+-package old
++package main
+
+Untracked files:
+- notes.txt`
+
+	want := `Staged changes:
+M	go.sum
+M	main.go
+
+Staged diff content:
+diff --git a/go.sum b/go.sum
+Lockfile go.sum: 2 changed lines summarized as dependency changes:
+- bumped golang.org/x/net from v0.17.0 to v0.19.0
+diff --git a/main.go b/main.go
+index 3333333..4444444 100644
+--- a/main.go
++++ b/main.go
+@@ -1 +1 @@
+-package old
++package main
+
+Untracked files:
+- notes.txt`
+	if got := Summarize(changes); got != want {
+		t.Fatalf("Summarize() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestSummarizeWithoutVersionChanges(t *testing.T) {
+	t.Parallel()
+
+	changes := "diff --git a/yarn.lock b/yarn.lock\n@@ -1,2 +1,2 @@\n-  integrity sha512-old\n+  integrity sha512-new"
+	want := "diff --git a/yarn.lock b/yarn.lock\nLockfile yarn.lock: 2 changed lines without dependency version changes omitted."
+	if got := Summarize(changes); got != want {
+		t.Fatalf("Summarize() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,106 @@
+// Package logging provides the opt-in debug logger used by --verbose.
+package logging
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/dfanso/commit-msg/internal/scrubber"
+)
+
+var (
+	mu      sync.RWMutex
+	logger  = slog.New(slog.NewTextHandler(io.Discard, nil))
+	enabled bool
+	logFile *os.File
+)
+
+// Init enables debug logging. When path is empty, records are written to
+// stderr; otherwise they are appended to the file at path.
+func Init(path string) error {
+	var out io.Writer = os.Stderr
+	var file *os.File
+	if strings.TrimSpace(path) != "" {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return err
+		}
+		out = f
+		file = f
+	}
+
+	SetOutput(out)
+
+	mu.Lock()
+	logFile = file
+	mu.Unlock()
+	return nil
+}
+
+// SetOutput routes debug records to w and enables logging. Messages and
+// attributes are scrubbed of sensitive data before they are written.
+func SetOutput(w io.Writer) {
+	mu.Lock()
+	defer mu.Unlock()
+	logger = slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{Level: slog.LevelDebug, ReplaceAttr: scrubAttr}))
+	enabled = true
+}
+
+// scrubAttr redacts sensitive data from string and error attributes, which
+// include the record message and logged command lines.
+func scrubAttr(_ []string, attr slog.Attr) slog.Attr {
+	switch attr.Value.Kind() {
+	case slog.KindString:
+		attr.Value = slog.StringValue(scrubber.ScrubDiff(attr.Value.String()))
+	case slog.KindAny:
+		if err, ok := attr.Value.Any().(error); ok {
+			attr.Value = slog.StringValue(scrubber.ScrubDiff(err.Error()))
+		}
+	}
+	return attr
+}
+
+// Close flushes and closes the log file opened by Init, if any, and disables logging.
+func Close() error {
+	mu.Lock()
+	defer mu.Unlock()
+	logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	enabled = false
+	if logFile == nil {
+		return nil
+	}
+	err := logFile.Close()
+	logFile = nil
+	return err
+}
+
+// Enabled reports whether debug logging is active.
+func Enabled() bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return enabled
+}
+
+// Logger returns the current debug logger.
+func Logger() *slog.Logger {
+	mu.RLock()
+	defer mu.RUnlock()
+	return logger
+}
+
+// Debug logs a debug record with optional key/value attributes.
+func Debug(msg string, args ...any) {
+	Logger().Debug(msg, args...)
+}
+
+// Command logs an external command before it is executed.
+func Command(cmd *exec.Cmd) {
+	if !Enabled() {
+		return
+	}
+	Debug("exec", "cmd", strings.Join(cmd.Args, " "), "dir", cmd.Dir)
+}
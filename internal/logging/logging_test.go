@@ -0,0 +1,91 @@
+package logging
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDisabledByDefault(t *testing.T) {
+	t.Cleanup(func() { Close() })
+
+	if Enabled() {
+		t.Fatal("expected logging to be disabled by default")
+	}
+
+	// Must not panic or write anywhere when disabled.
+	Debug("ignored", "key", "value")
+	Command(exec.Command("git", "status"))
+}
+
+func TestSetOutputWritesRecords(t *testing.T) {
+	t.Cleanup(func() { Close() })
+
+	var buf bytes.Buffer
+	SetOutput(&buf)
+
+	if !Enabled() {
+		t.Fatal("expected logging to be enabled after SetOutput")
+	}
+
+	Debug("cache miss", "provider", "OpenAI")
+	cmd := exec.Command("git", "diff", "--cached")
+	cmd.Dir = "/tmp/repo"
+	Command(cmd)
+
+	out := buf.String()
+	for _, fragment := range []string{"cache miss", "provider=OpenAI", `cmd="git diff --cached"`, "dir=/tmp/repo"} {
+		if !strings.Contains(out, fragment) {
+			t.Fatalf("expected log output to contain %q, got %q", fragment, out)
+		}
+	}
+}
+
+func TestSetOutputScrubsRecords(t *testing.T) {
+	t.Cleanup(func() { Close() })
+
+	var buf bytes.Buffer
+	SetOutput(&buf)
+
+	secret := "abcdefghijklmnopqrstuvwxyz123"
+	Debug("sending api_key="+secret, "header", "Bearer "+secret, "error", errors.New("rejected api_key="+secret))
+	Command(exec.Command("git", "commit", "-m", "api_key="+secret))
+
+	out := buf.String()
+	if strings.Contains(out, secret) {
+		t.Fatalf("expected the secret to be redacted, got %q", out)
+	}
+	if !strings.Contains(out, "[REDACTED_BEARER_TOKEN]") {
+		t.Fatalf("expected a redaction marker, got %q", out)
+	}
+}
+
+func TestInitWithFile(t *testing.T) {
+	t.Cleanup(func() { Close() })
+
+	path := filepath.Join(t.TempDir(), "commit.log")
+	if err := Init(path); err != nil {
+		t.Fatalf("Init returned error: %v", err)
+	}
+
+	Debug("hello")
+
+	if err := Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+	if Enabled() {
+		t.Fatal("expected logging to be disabled after Close")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	if !strings.Contains(string(data), "hello") {
+		t.Fatalf("expected log file to contain record, got %q", string(data))
+	}
+}
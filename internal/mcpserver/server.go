@@ -0,0 +1,144 @@
+package mcpserver
+
+import (
+	"context"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/dfanso/commit-msg/internal/git"
+	"github.com/dfanso/commit-msg/internal/scrubber"
+	"github.com/dfanso/commit-msg/pkg/types"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// GenerateFunc produces a commit message for the scrubbed changes of the
+// repository at repoPath.
+type GenerateFunc func(ctx context.Context, repoPath, changes string, opts *types.GenerationOptions) (*types.GenerationResult, error)
+
+// Options configures the MCP server.
+type Options struct {
+	// Name and Version are reported to clients during initialisation.
+	Name    string
+	Version string
+	// DefaultPath is the repository used when a tool call omits "path".
+	DefaultPath string
+	// Generate is called by the generate_commit_message tool.
+	Generate GenerateFunc
+}
+
+// New builds an MCP server exposing get_changes, scrub_diff, and
+// generate_commit_message.
+func New(opts Options) *server.MCPServer {
+	if opts.Name == "" {
+		opts.Name = "commit-msg"
+	}
+	if opts.Version == "" {
+		opts.Version = "dev"
+	}
+
+	s := server.NewMCPServer(opts.Name, opts.Version, server.WithToolCapabilities(false))
+	h := &handlers{opts: opts}
+
+	s.AddTool(mcp.NewTool("get_changes",
+		mcp.WithDescription("Return the staged, unstaged, and untracked changes of a Git repository with secrets scrubbed."),
+		mcp.WithString("path", mcp.Description("Repository path. Defaults to the server's working directory.")),
+	), h.getChanges)
+
+	s.AddTool(mcp.NewTool("scrub_diff",
+		mcp.WithDescription("Redact API keys, passwords, tokens, and other secrets from a diff or text."),
+		mcp.WithString("diff", mcp.Required(), mcp.Description("The diff or text to scrub.")),
+	), h.scrubDiff)
+
+	s.AddTool(mcp.NewTool("generate_commit_message",
+		mcp.WithDescription("Generate a commit message for the current changes of a Git repository using the configured LLM provider."),
+		mcp.WithString("path", mcp.Description("Repository path. Defaults to the server's working directory.")),
+		mcp.WithString("style", mcp.Description("Optional extra instructions, e.g. tone or format.")),
+	), h.generate)
+
+	return s
+}
+
+// ServeStdio serves s over the given streams until ctx is cancelled or the
+// input is closed.
+func ServeStdio(ctx context.Context, s *server.MCPServer, in io.Reader, out io.Writer) error {
+	return server.NewStdioServer(s).Listen(ctx, in, out)
+}
+
+type handlers struct {
+	opts Options
+}
+
+func (h *handlers) getChanges(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	repoPath, result := h.repoPath(req)
+	if result != nil {
+		return result, nil
+	}
+
+	changes, err := git.GetChanges(&types.RepoConfig{Path: repoPath})
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("failed to get changes", err), nil
+	}
+	return mcp.NewToolResultText(changes), nil
+}
+
+func (h *handlers) scrubDiff(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	diff, err := req.RequireString("diff")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return mcp.NewToolResultText(scrubber.ScrubDiff(diff)), nil
+}
+
+func (h *handlers) generate(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if h.opts.Generate == nil {
+		return mcp.NewToolResultError("commit message generation is not configured"), nil
+	}
+
+	repoPath, result := h.repoPath(req)
+	if result != nil {
+		return result, nil
+	}
+
+	changes, err := git.GetChanges(&types.RepoConfig{Path: repoPath})
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("failed to get changes", err), nil
+	}
+
+	files, err := git.ChangedFiles(&types.RepoConfig{Path: repoPath})
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("failed to get changes", err), nil
+	}
+	if len(files) == 0 {
+		return mcp.NewToolResultError("no changes detected in the Git repository"), nil
+	}
+
+	opts := &types.GenerationOptions{
+		StyleInstruction: strings.TrimSpace(req.GetString("style", "")),
+		Attempt:          1,
+	}
+	generated, err := h.opts.Generate(ctx, repoPath, changes, opts)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("failed to generate commit message", err), nil
+	}
+	return mcp.NewToolResultText(strings.TrimSpace(generated.Message)), nil
+}
+
+// repoPath resolves the "path" argument and verifies it is a repository. A
+// non-nil result is an error to return to the client.
+func (h *handlers) repoPath(req mcp.CallToolRequest) (string, *mcp.CallToolResult) {
+	path := req.GetString("path", "")
+	if strings.TrimSpace(path) == "" {
+		path = h.opts.DefaultPath
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", mcp.NewToolResultErrorFromErr("invalid path", err)
+	}
+	if !git.IsRepository(abs) {
+		return "", mcp.NewToolResultErrorf("not a Git repository: %s", abs)
+	}
+	return abs, nil
+}
@@ -0,0 +1,167 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dfanso/commit-msg/pkg/types"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// call sends a tools/call request to s and returns the tool result.
+func call(t *testing.T, s *server.MCPServer, tool string, args map[string]any) *mcp.CallToolResult {
+	t.Helper()
+
+	request, err := json.Marshal(map[string]any{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "tools/call",
+		"params":  map[string]any{"name": tool, "arguments": args},
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal request: %v", err)
+	}
+
+	response := s.HandleMessage(context.Background(), request)
+	rpc, ok := response.(mcp.JSONRPCResponse)
+	if !ok {
+		t.Fatalf("expected JSON-RPC response, got %T: %+v", response, response)
+	}
+
+	result, ok := rpc.Result.(mcp.CallToolResult)
+	if !ok {
+		t.Fatalf("expected CallToolResult, got %T", rpc.Result)
+	}
+	return &result
+}
+
+func resultText(t *testing.T, result *mcp.CallToolResult) string {
+	t.Helper()
+	if len(result.Content) == 0 {
+		t.Fatal("expected result content")
+	}
+	text, ok := mcp.AsTextContent(result.Content[0])
+	if !ok {
+		t.Fatalf("expected text content, got %T", result.Content[0])
+	}
+	return text.Text
+}
+
+func newRepo(t *testing.T) string {
+	t.Helper()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git executable not available")
+	}
+
+	dir := t.TempDir()
+	cmd := exec.Command("git", "init", dir)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("failed to init git repo: %v: %s", err, string(output))
+	}
+	return dir
+}
+
+func TestToolsList(t *testing.T) {
+	t.Parallel()
+
+	s := New(Options{})
+	response := s.HandleMessage(context.Background(), json.RawMessage(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`))
+	rpc, ok := response.(mcp.JSONRPCResponse)
+	if !ok {
+		t.Fatalf("expected JSON-RPC response, got %T", response)
+	}
+
+	result, ok := rpc.Result.(mcp.ListToolsResult)
+	if !ok {
+		t.Fatalf("expected ListToolsResult, got %T", rpc.Result)
+	}
+
+	var names []string
+	for _, tool := range result.Tools {
+		names = append(names, tool.Name)
+	}
+	got := strings.Join(names, ",")
+	for _, want := range []string{"generate_commit_message", "get_changes", "scrub_diff"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected tool %q in %q", want, got)
+		}
+	}
+}
+
+func TestScrubDiffTool(t *testing.T) {
+	t.Parallel()
+
+	s := New(Options{})
+	result := call(t, s, "scrub_diff", map[string]any{"diff": `+api_key="sk-1234567890abcdef1234567890"`})
+	if result.IsError {
+		t.Fatalf("unexpected error result: %s", resultText(t, result))
+	}
+	if text := resultText(t, result); strings.Contains(text, "sk-1234567890abcdef") {
+		t.Fatalf("expected secret to be scrubbed, got %q", text)
+	}
+}
+
+func TestGetChangesTool(t *testing.T) {
+	t.Parallel()
+
+	dir := newRepo(t)
+	if err := os.WriteFile(filepath.Join(dir, "hello.txt"), []byte("hello\n"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	s := New(Options{DefaultPath: dir})
+	result := call(t, s, "get_changes", nil)
+	if result.IsError {
+		t.Fatalf("unexpected error result: %s", resultText(t, result))
+	}
+	if text := resultText(t, result); !strings.Contains(text, "hello.txt") {
+		t.Fatalf("expected changes to mention hello.txt, got %q", text)
+	}
+
+	result = call(t, s, "get_changes", map[string]any{"path": t.TempDir()})
+	if !result.IsError {
+		t.Fatal("expected an error result for a non-repository path")
+	}
+}
+
+func TestGenerateTool(t *testing.T) {
+	t.Parallel()
+
+	dir := newRepo(t)
+
+	var gotStyle string
+	s := New(Options{
+		DefaultPath: dir,
+		Generate: func(ctx context.Context, repoPath, changes string, opts *types.GenerationOptions) (*types.GenerationResult, error) {
+			gotStyle = opts.StyleInstruction
+			return &types.GenerationResult{Message: "  feat: add greeting\n"}, nil
+		},
+	})
+
+	result := call(t, s, "generate_commit_message", nil)
+	if !result.IsError {
+		t.Fatal("expected an error result when there are no changes")
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "hello.txt"), []byte("hello\n"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	result = call(t, s, "generate_commit_message", map[string]any{"style": "Be terse."})
+	if result.IsError {
+		t.Fatalf("unexpected error result: %s", resultText(t, result))
+	}
+	if text := resultText(t, result); text != "feat: add greeting" {
+		t.Fatalf("generated message = %q, want %q", text, "feat: add greeting")
+	}
+	if gotStyle != "Be terse." {
+		t.Fatalf("style instruction = %q, want %q", gotStyle, "Be terse.")
+	}
+}
@@ -0,0 +1,282 @@
+package monorepo
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Kind identifies the workspace manifest that declared the packages.
+type Kind string
+
+const (
+	KindGoWork Kind = "go.work"
+	KindPnpm   Kind = "pnpm"
+	KindLerna  Kind = "lerna"
+	KindCargo  Kind = "cargo"
+)
+
+// Workspace describes a monorepo rooted at Root. Packages holds the package
+// directories relative to Root, slash-separated and sorted.
+type Workspace struct {
+	Kind     Kind
+	Root     string
+	Packages []string
+}
+
+// Detect inspects root for a supported workspace manifest and returns the
+// packages it declares. It returns nil without error when root is not a
+// monorepo or the manifest lists fewer than two packages.
+func Detect(root string) (*Workspace, error) {
+	detectors := []struct {
+		kind  Kind
+		file  string
+		parse func(data []byte) ([]string, bool)
+	}{
+		{KindGoWork, "go.work", parseGoWork},
+		{KindPnpm, "pnpm-workspace.yaml", parsePnpmWorkspace},
+		{KindLerna, "lerna.json", parseLerna},
+		{KindCargo, "Cargo.toml", parseCargoWorkspace},
+	}
+
+	for _, d := range detectors {
+		data, err := os.ReadFile(filepath.Join(root, d.file))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to read %s: %w", d.file, err)
+		}
+
+		patterns, ok := d.parse(data)
+		if !ok {
+			continue
+		}
+
+		packages := expandPatterns(root, patterns)
+		if len(packages) < 2 {
+			continue
+		}
+
+		return &Workspace{Kind: d.kind, Root: root, Packages: packages}, nil
+	}
+
+	return nil, nil
+}
+
+// PackageFor returns the package directory containing file, which must be
+// relative to the workspace root. It returns "" for files outside every
+// package (e.g. shared configuration at the root).
+func (w *Workspace) PackageFor(file string) string {
+	file = path.Clean(filepath.ToSlash(file))
+	best := ""
+	for _, pkg := range w.Packages {
+		if pkg == "." {
+			continue
+		}
+		if (file == pkg || strings.HasPrefix(file, pkg+"/")) && len(pkg) > len(best) {
+			best = pkg
+		}
+	}
+	return best
+}
+
+// Group buckets files by the package that contains them. Files outside every
+// package are grouped under the "" key.
+func (w *Workspace) Group(files []string) map[string][]string {
+	groups := make(map[string][]string)
+	for _, file := range files {
+		pkg := w.PackageFor(file)
+		groups[pkg] = append(groups[pkg], file)
+	}
+	return groups
+}
+
+// ScopeName returns the short name used as a conventional commit scope for
+// the package directory pkg.
+func ScopeName(pkg string) string {
+	if pkg == "" || pkg == "." {
+		return ""
+	}
+	return path.Base(pkg)
+}
+
+// parseGoWork extracts the use directives from a go.work file.
+func parseGoWork(data []byte) ([]string, bool) {
+	var dirs []string
+	inBlock := false
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	for scanner.Scan() {
+		line := stripComment(scanner.Text(), "//")
+		if line == "" {
+			continue
+		}
+
+		if inBlock {
+			if line == ")" {
+				inBlock = false
+				continue
+			}
+			dirs = append(dirs, unquote(line))
+			continue
+		}
+
+		if !strings.HasPrefix(line, "use") {
+			continue
+		}
+		rest := strings.TrimSpace(strings.TrimPrefix(line, "use"))
+		if rest == "(" {
+			inBlock = true
+			continue
+		}
+		if rest != "" {
+			dirs = append(dirs, unquote(rest))
+		}
+	}
+	return dirs, len(dirs) > 0
+}
+
+// parsePnpmWorkspace extracts the packages list from pnpm-workspace.yaml.
+// Only the simple block-sequence form used by pnpm is supported.
+func parsePnpmWorkspace(data []byte) ([]string, bool) {
+	var patterns []string
+	inPackages := false
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	for scanner.Scan() {
+		raw := scanner.Text()
+		line := stripComment(raw, "#")
+		if line == "" {
+			continue
+		}
+
+		if !strings.HasPrefix(raw, " ") && !strings.HasPrefix(raw, "\t") && !strings.HasPrefix(line, "-") {
+			inPackages = strings.HasPrefix(line, "packages:")
+			continue
+		}
+
+		if inPackages && strings.HasPrefix(line, "-") {
+			patterns = append(patterns, unquote(strings.TrimSpace(strings.TrimPrefix(line, "-"))))
+		}
+	}
+	return patterns, len(patterns) > 0
+}
+
+// parseLerna reads the packages globs from lerna.json, defaulting to
+// packages/* like lerna itself.
+func parseLerna(data []byte) ([]string, bool) {
+	var manifest struct {
+		Packages []string `json:"packages"`
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, false
+	}
+	if len(manifest.Packages) == 0 {
+		return []string{"packages/*"}, true
+	}
+	return manifest.Packages, true
+}
+
+// parseCargoWorkspace reads the members array from the [workspace] table of
+// a Cargo.toml. Manifests without a workspace table are not monorepos.
+func parseCargoWorkspace(data []byte) ([]string, bool) {
+	var members []string
+	inWorkspace := false
+	inMembers := false
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	for scanner.Scan() {
+		line := stripComment(scanner.Text(), "#")
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && !inMembers {
+			inWorkspace = line == "[workspace]"
+			continue
+		}
+		if !inWorkspace {
+			continue
+		}
+
+		if !inMembers {
+			key, value, found := strings.Cut(line, "=")
+			if !found || strings.TrimSpace(key) != "members" {
+				continue
+			}
+			line = strings.TrimSpace(value)
+			if !strings.HasPrefix(line, "[") {
+				continue
+			}
+			line = strings.TrimPrefix(line, "[")
+			inMembers = true
+		}
+
+		closing := strings.Contains(line, "]")
+		line, _, _ = strings.Cut(line, "]")
+		for _, item := range strings.Split(line, ",") {
+			if item = unquote(strings.TrimSpace(item)); item != "" {
+				members = append(members, item)
+			}
+		}
+		if closing {
+			inMembers = false
+		}
+	}
+	return members, len(members) > 0
+}
+
+// expandPatterns resolves workspace globs against root and returns the
+// matching directories relative to root. Exclusion patterns (leading "!")
+// remove previously matched directories; "**" is treated as a single level.
+func expandPatterns(root string, patterns []string) []string {
+	seen := make(map[string]bool)
+	for _, pattern := range patterns {
+		exclude := strings.HasPrefix(pattern, "!")
+		pattern = strings.TrimPrefix(pattern, "!")
+		pattern = strings.TrimPrefix(path.Clean(filepath.ToSlash(pattern)), "./")
+		pattern = strings.ReplaceAll(pattern, "**", "*")
+
+		matches, err := filepath.Glob(filepath.Join(root, filepath.FromSlash(pattern)))
+		if err != nil {
+			continue
+		}
+		for _, match := range matches {
+			info, err := os.Stat(match)
+			if err != nil || !info.IsDir() {
+				continue
+			}
+			rel, err := filepath.Rel(root, match)
+			if err != nil {
+				continue
+			}
+			rel = filepath.ToSlash(rel)
+			if exclude {
+				delete(seen, rel)
+			} else {
+				seen[rel] = true
+			}
+		}
+	}
+
+	packages := make([]string, 0, len(seen))
+	for pkg := range seen {
+		packages = append(packages, pkg)
+	}
+	sort.Strings(packages)
+	return packages
+}
+
+func stripComment(line, marker string) string {
+	if idx := strings.Index(line, marker); idx >= 0 {
+		line = line[:idx]
+	}
+	return strings.TrimSpace(line)
+}
+
+func unquote(s string) string {
+	return strings.Trim(strings.TrimSpace(s), `"'`)
+}
@@ -0,0 +1,142 @@
+package monorepo
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeFile(t *testing.T, root, name, content string) {
+	t.Helper()
+	full := filepath.Join(root, name)
+	if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+		t.Fatalf("failed to create directory for %s: %v", name, err)
+	}
+	if err := os.WriteFile(full, []byte(content), 0o644); err != nil {
+		t.Fatalf("failed to write %s: %v", name, err)
+	}
+}
+
+func mkdirs(t *testing.T, root string, dirs ...string) {
+	t.Helper()
+	for _, dir := range dirs {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
+			t.Fatalf("failed to create %s: %v", dir, err)
+		}
+	}
+}
+
+func TestDetect(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		setup    func(t *testing.T, root string)
+		kind     Kind
+		packages []string
+	}{
+		{
+			name: "go.work",
+			setup: func(t *testing.T, root string) {
+				mkdirs(t, root, "cmd/tool", "lib")
+				writeFile(t, root, "go.work", "go 1.22\n\nuse (\n\t./cmd/tool // the CLI\n\t./lib\n)\n")
+			},
+			kind:     KindGoWork,
+			packages: []string{"cmd/tool", "lib"},
+		},
+		{
+			name: "pnpm workspace",
+			setup: func(t *testing.T, root string) {
+				mkdirs(t, root, "packages/ui", "packages/api", "apps/web", "packages/legacy")
+				writeFile(t, root, "pnpm-workspace.yaml", "packages:\n  - 'packages/*'\n  - \"apps/**\"\n  - '!packages/legacy'\n")
+			},
+			kind:     KindPnpm,
+			packages: []string{"apps/web", "packages/api", "packages/ui"},
+		},
+		{
+			name: "lerna default packages",
+			setup: func(t *testing.T, root string) {
+				mkdirs(t, root, "packages/a", "packages/b")
+				writeFile(t, root, "lerna.json", `{"version": "1.0.0"}`)
+			},
+			kind:     KindLerna,
+			packages: []string{"packages/a", "packages/b"},
+		},
+		{
+			name: "cargo workspace",
+			setup: func(t *testing.T, root string) {
+				mkdirs(t, root, "crates/core", "crates/cli")
+				writeFile(t, root, "Cargo.toml", "[workspace]\nmembers = [\n    \"crates/core\",\n    \"crates/cli\", # binary\n]\n\n[profile.release]\nlto = true\n")
+			},
+			kind:     KindCargo,
+			packages: []string{"crates/cli", "crates/core"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			root := t.TempDir()
+			tt.setup(t, root)
+
+			ws, err := Detect(root)
+			if err != nil {
+				t.Fatalf("Detect returned error: %v", err)
+			}
+			if ws == nil {
+				t.Fatal("expected a workspace to be detected")
+			}
+			if ws.Kind != tt.kind {
+				t.Fatalf("Kind = %q, want %q", ws.Kind, tt.kind)
+			}
+			if !reflect.DeepEqual(ws.Packages, tt.packages) {
+				t.Fatalf("Packages = %v, want %v", ws.Packages, tt.packages)
+			}
+		})
+	}
+}
+
+func TestDetectIgnoresSinglePackageRepos(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	writeFile(t, root, "Cargo.toml", "[package]\nname = \"solo\"\n")
+	writeFile(t, root, "go.mod", "module example.com/solo\n")
+
+	ws, err := Detect(root)
+	if err != nil {
+		t.Fatalf("Detect returned error: %v", err)
+	}
+	if ws != nil {
+		t.Fatalf("expected no workspace, got %+v", ws)
+	}
+}
+
+func TestGroup(t *testing.T) {
+	t.Parallel()
+
+	ws := &Workspace{Kind: KindPnpm, Packages: []string{"packages/ui", "packages/ui-kit", "apps/web"}}
+	groups := ws.Group([]string{
+		"packages/ui/button.tsx",
+		"packages/ui-kit/index.ts",
+		"apps/web/page.tsx",
+		"package.json",
+	})
+
+	want := map[string][]string{
+		"packages/ui":     {"packages/ui/button.tsx"},
+		"packages/ui-kit": {"packages/ui-kit/index.ts"},
+		"apps/web":        {"apps/web/page.tsx"},
+		"":                {"package.json"},
+	}
+	if !reflect.DeepEqual(groups, want) {
+		t.Fatalf("Group() = %v, want %v", groups, want)
+	}
+
+	if got := ScopeName("packages/ui-kit"); got != "ui-kit" {
+		t.Fatalf("ScopeName() = %q, want %q", got, "ui-kit")
+	}
+}
@@ -0,0 +1,112 @@
+// Package msgfmt lays out an accepted commit message the way git tooling
+// expects: a subject, one blank line, and a body wrapped to a fixed width.
+package msgfmt
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultWidth is the body width recommended for commit messages, which
+// leaves room for the indentation git log adds.
+const DefaultWidth = 72
+
+var (
+	// trailerLine matches a git trailer such as "Signed-off-by: Jane <j@x>".
+	trailerLine = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9-]*: \S`)
+	// listItem captures the marker of a "- ", "* ", or "1. " list item.
+	listItem = regexp.MustCompile(`^([-*+]|\d+[.)]) +`)
+)
+
+// Format normalizes message for committing:
+//
+//   - line endings become "\n" and trailing whitespace is removed
+//   - leading and trailing blank lines are dropped, and runs of blank lines
+//     collapse into one
+//   - the subject is separated from the body by exactly one blank line
+//   - body lines longer than width are wrapped at word boundaries, with
+//     list items continued under their text
+//
+// Indented lines, fenced code blocks, trailers, and lines without spaces,
+// such as URLs, are never wrapped. A width of zero or less disables
+// wrapping.
+func Format(message string, width int) string {
+	message = strings.ReplaceAll(message, "\r\n", "\n")
+	message = strings.ReplaceAll(message, "\r", "\n")
+
+	var lines []string
+	for _, line := range strings.Split(message, "\n") {
+		lines = append(lines, strings.TrimRight(line, " \t"))
+	}
+	lines = trimBlank(lines)
+	if len(lines) == 0 {
+		return ""
+	}
+
+	subject := strings.TrimSpace(lines[0])
+	body := trimBlank(lines[1:])
+	if len(body) == 0 {
+		return subject
+	}
+
+	var out []string
+	inFence := false
+	for _, line := range body {
+		fence := strings.HasPrefix(strings.TrimSpace(line), "```")
+		switch {
+		case inFence || fence:
+			out = append(out, line)
+			if fence {
+				inFence = !inFence
+			}
+		case line == "":
+			if out[len(out)-1] != "" {
+				out = append(out, line)
+			}
+		default:
+			out = append(out, wrap(line, width)...)
+		}
+	}
+	return subject + "\n\n" + strings.Join(out, "\n")
+}
+
+// wrap breaks line into lines of at most width characters where that is
+// possible without splitting a word.
+func wrap(line string, width int) []string {
+	if width <= 0 || utf8.RuneCountInString(line) <= width ||
+		strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") ||
+		trailerLine.MatchString(line) || !strings.Contains(line, " ") {
+		return []string{line}
+	}
+
+	indent := ""
+	if marker := listItem.FindString(line); marker != "" {
+		indent = strings.Repeat(" ", utf8.RuneCountInString(marker))
+	}
+
+	var wrapped []string
+	current := ""
+	for _, word := range strings.Fields(line) {
+		switch {
+		case current == "":
+			current = word
+		case utf8.RuneCountInString(current)+1+utf8.RuneCountInString(word) <= width:
+			current += " " + word
+		default:
+			wrapped = append(wrapped, current)
+			current = indent + word
+		}
+	}
+	return append(wrapped, current)
+}
+
+func trimBlank(lines []string) []string {
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
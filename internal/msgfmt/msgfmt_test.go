@@ -0,0 +1,97 @@
+package msgfmt
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		message string
+		want    string
+	}{
+		{
+			name:    "subject only",
+			message: "\n  feat: add login  \n\n",
+			want:    "feat: add login",
+		},
+		{
+			name:    "line endings and trailing spaces",
+			message: "fix: typo\r\n\r\nCorrect the spelling. \r\n",
+			want:    "fix: typo\n\nCorrect the spelling.",
+		},
+		{
+			name:    "missing blank line after subject",
+			message: "fix: typo\nCorrect the spelling.",
+			want:    "fix: typo\n\nCorrect the spelling.",
+		},
+		{
+			name:    "extra blank lines",
+			message: "fix: typo\n\n\n\nFirst paragraph.\n\n\n\nSecond paragraph.",
+			want:    "fix: typo\n\nFirst paragraph.\n\nSecond paragraph.",
+		},
+		{
+			name:    "long paragraph",
+			message: "feat: add cache\n\nCache generated messages by diff hash so repeated runs over the same changes skip the provider entirely.",
+			want:    "feat: add cache\n\nCache generated messages by diff hash so repeated runs over the same\nchanges skip the provider entirely.",
+		},
+		{
+			name:    "list item",
+			message: "feat: add cache\n\n- Cache generated messages by diff hash so repeated runs over the same changes skip the provider.",
+			want:    "feat: add cache\n\n- Cache generated messages by diff hash so repeated runs over the same\n  changes skip the provider.",
+		},
+		{
+			name:    "long subject is kept",
+			message: "feat: " + strings.Repeat("word ", 20),
+			want:    "feat: " + strings.TrimSpace(strings.Repeat("word ", 20)),
+		},
+	}
+
+	for _, tt := range tests {
+		if got := Format(tt.message, DefaultWidth); got != tt.want {
+			t.Errorf("%s: Format() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFormatLeavesUnwrappableLines(t *testing.T) {
+	t.Parallel()
+
+	long := strings.Repeat("word ", 20)
+	url := "https://example.com/" + strings.Repeat("a", 80)
+	trailer := "Co-authored-by: " + strings.Repeat("Someone ", 10) + "<someone@example.com>"
+	message := "fix: things\n\n" +
+		"    " + long + "\n\n" +
+		"```\n" + long + "\n\n\n```\n\n" +
+		url + "\n\n" +
+		trailer
+
+	want := "fix: things\n\n" +
+		"    " + strings.TrimSpace(long) + "\n\n" +
+		"```\n" + strings.TrimSpace(long) + "\n\n\n```\n\n" +
+		url + "\n\n" +
+		trailer
+	if got := Format(message, DefaultWidth); got != want {
+		t.Errorf("Format() = %q, want %q", got, want)
+	}
+}
+
+func TestFormatWithoutWrapping(t *testing.T) {
+	t.Parallel()
+
+	body := strings.TrimSpace(strings.Repeat("word ", 30))
+	if got := Format("fix: x\n\n"+body, 0); got != "fix: x\n\n"+body {
+		t.Errorf("Format() with width 0 wrapped the body: %q", got)
+	}
+}
+
+func TestFormatEmpty(t *testing.T) {
+	t.Parallel()
+
+	if got := Format(" \r\n\n ", DefaultWidth); got != "" {
+		t.Errorf("Format() = %q, want empty", got)
+	}
+}
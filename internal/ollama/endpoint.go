@@ -0,0 +1,92 @@
+package ollama
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ValidateURL checks an Ollama endpoint such as
+// http://localhost:11434/api/generate. The scheme must be http or https,
+// the host must be present, IPv6 addresses must be in brackets, and a port
+// must be a number from 1 to 65535.
+func ValidateURL(raw string) error {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return fmt.Errorf("invalid Ollama URL %q: %w", raw, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("invalid Ollama URL %q: scheme must be http or https", raw)
+	}
+	if u.Hostname() == "" {
+		return fmt.Errorf("invalid Ollama URL %q: missing host", raw)
+	}
+	if strings.Count(u.Host, ":") > 1 && !strings.HasPrefix(u.Host, "[") {
+		return fmt.Errorf("invalid Ollama URL %q: put IPv6 addresses in brackets, as in http://[::1]:11434", raw)
+	}
+	if port := u.Port(); port != "" {
+		if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+			return fmt.Errorf("invalid Ollama URL %q: port must be between 1 and 65535", raw)
+		}
+	} else if strings.HasSuffix(u.Host, ":") {
+		return fmt.Errorf("invalid Ollama URL %q: empty port", raw)
+	}
+	return nil
+}
+
+// sharedAddressSpace is 100.64.0.0/10, used by carrier-grade NAT and by
+// overlay networks such as Tailscale to reach machines privately.
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// lookupIPAddr resolves host names; tests replace it.
+var lookupIPAddr = net.DefaultResolver.LookupIPAddr
+
+// PublicHost reports whether the endpoint at raw is on a public host, so
+// prompts sent to it leave this machine and its private network. Hosts
+// listed in the configured allowed_hosts never count as public, and neither
+// do names that cannot be resolved, since they cannot be classified. host is
+// the endpoint's host name, for messages.
+func PublicHost(ctx context.Context, raw string) (host string, public bool) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return "", false
+	}
+	host = u.Hostname()
+	for _, allowed := range configured().AllowedHosts {
+		if strings.EqualFold(strings.TrimSpace(allowed), host) {
+			return host, false
+		}
+	}
+	return host, !isPrivateHost(ctx, host)
+}
+
+// isPrivateHost reports whether host is localhost, an mDNS .local name, a
+// loopback, private, link-local, or shared address, or a name that resolves
+// only to such addresses.
+func isPrivateHost(ctx context.Context, host string) bool {
+	lower := strings.ToLower(strings.TrimSuffix(host, "."))
+	if lower == "localhost" || strings.HasSuffix(lower, ".localhost") || strings.HasSuffix(lower, ".local") {
+		return true
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return isPrivateIP(ip)
+	}
+
+	addrs, err := lookupIPAddr(ctx, host)
+	if err != nil || len(addrs) == 0 {
+		return true
+	}
+	for _, addr := range addrs {
+		if !isPrivateIP(addr.IP) {
+			return false
+		}
+	}
+	return true
+}
+
+func isPrivateIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsUnspecified() || sharedAddressSpace.Contains(ip)
+}
@@ -0,0 +1,86 @@
+package ollama
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+)
+
+func TestValidateURL(t *testing.T) {
+	t.Parallel()
+
+	for _, raw := range []string{
+		"http://localhost:11434/api/generate",
+		"https://ollama.example.com/api/generate",
+		"http://[::1]:11434/api/generate",
+		"http://[fd00::5]/api/generate",
+		"http://192.168.1.20:11434",
+	} {
+		if err := ValidateURL(raw); err != nil {
+			t.Errorf("ValidateURL(%q) error = %v", raw, err)
+		}
+	}
+	for _, raw := range []string{
+		"localhost:11434",
+		"ftp://localhost/api/generate",
+		"http:///api/generate",
+		"http://::1:11434/api/generate",
+		"http://localhost:0/api/generate",
+		"http://localhost:70000/api/generate",
+		"http://localhost:port/api/generate",
+		"http://localhost:/api/generate",
+	} {
+		if err := ValidateURL(raw); err == nil {
+			t.Errorf("ValidateURL(%q) succeeded, want an error", raw)
+		}
+	}
+}
+
+// TestPublicHost replaces lookupIPAddr and the configured options, so it
+// does not run in parallel.
+func TestPublicHost(t *testing.T) {
+	lookup := lookupIPAddr
+	t.Cleanup(func() {
+		lookupIPAddr = lookup
+		Configure(Options{})
+	})
+	lookupIPAddr = func(_ context.Context, host string) ([]net.IPAddr, error) {
+		switch host {
+		case "gpu.internal":
+			return []net.IPAddr{{IP: net.ParseIP("10.0.0.7")}}, nil
+		case "ollama.example.com":
+			return []net.IPAddr{{IP: net.ParseIP("203.0.113.9")}}, nil
+		}
+		return nil, errors.New("no such host")
+	}
+
+	tests := []struct {
+		url    string
+		public bool
+	}{
+		{"http://localhost:11434/api/generate", false},
+		{"http://127.0.0.1:11434", false},
+		{"http://[::1]:11434", false},
+		{"http://[fd12::1]:11434", false},
+		{"http://[fe80::1]:11434", false},
+		{"http://172.16.4.2:11434", false},
+		{"http://100.101.102.103:11434", false},
+		{"http://studio.local:11434", false},
+		{"http://gpu.internal:11434", false},
+		{"http://unknown.invalid:11434", false},
+		{"http://8.8.8.8:11434", true},
+		{"http://[2001:4860::8888]:11434", true},
+		{"https://ollama.example.com/api/generate", true},
+	}
+	for _, tt := range tests {
+		if _, public := PublicHost(context.Background(), tt.url); public != tt.public {
+			t.Errorf("PublicHost(%q) = %v, want %v", tt.url, public, tt.public)
+		}
+	}
+
+	Configure(Options{AllowedHosts: []string{"ollama.example.com"}})
+	if host, public := PublicHost(context.Background(), "https://ollama.example.com/api/generate"); public || host != "ollama.example.com" {
+		t.Fatalf("PublicHost() for an allowed host = %q, %v", host, public)
+	}
+}
@@ -0,0 +1,146 @@
+package ollama
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	httpClient "github.com/dfanso/commit-msg/internal/http"
+	"github.com/dfanso/commit-msg/internal/llmerr"
+	"github.com/dfanso/commit-msg/pkg/types"
+)
+
+// RecommendedModel is offered for download when no models are installed.
+const RecommendedModel = "llama3.1"
+
+// Model is an installed model reported by /api/tags.
+type Model struct {
+	Name       string `json:"name"`
+	Size       int64  `json:"size"`
+	ModifiedAt string `json:"modified_at"`
+}
+
+// PullProgress is one status update streamed by /api/pull.
+type PullProgress struct {
+	Status    string `json:"status"`
+	Completed int64  `json:"completed"`
+	Total     int64  `json:"total"`
+	Error     string `json:"error"`
+}
+
+// EndpointURL swaps the path of a configured Ollama URL (usually ending in
+// /api/generate) for the given API path, so every endpoint shares one host.
+func EndpointURL(configured, path string) (string, error) {
+	u, err := url.Parse(configured)
+	if err != nil {
+		return "", fmt.Errorf("invalid Ollama URL %q: %w", configured, err)
+	}
+	if u.Host == "" {
+		return "", fmt.Errorf("invalid Ollama URL %q: missing host", configured)
+	}
+	u.Path = path
+	u.RawQuery = ""
+	return u.String(), nil
+}
+
+// ListModels returns the models installed on the Ollama server at endpoint.
+func ListModels(ctx context.Context, endpoint string) ([]Model, error) {
+	tagsURL, err := EndpointURL(endpoint, "/api/tags")
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, tagsURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := httpClient.ClientFor(types.ProviderOllama).Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach Ollama: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, llmerr.FromResponse(types.ProviderOllama, resp.StatusCode, resp.Header, body)
+	}
+
+	var tags struct {
+		Models []Model `json:"models"`
+	}
+	if err := json.Unmarshal(body, &tags); err != nil {
+		return nil, fmt.Errorf("failed to decode model list: %w", err)
+	}
+	return tags.Models, nil
+}
+
+// Pull downloads model onto the Ollama server at endpoint, calling progress
+// (when non-nil) for every status update the server streams.
+func Pull(ctx context.Context, endpoint, model string, progress func(PullProgress)) error {
+	pullURL, err := EndpointURL(endpoint, "/api/pull")
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(map[string]interface{}{"model": model, "stream": true})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, pullURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", ollamaContentType)
+
+	// Downloads take far longer than a generation request, so rely on ctx
+	// rather than the provider timeout.
+	client := *httpClient.ClientFor(types.ProviderOllama)
+	client.Timeout = 0
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach Ollama: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		responseBody, _ := io.ReadAll(resp.Body)
+		return llmerr.FromResponse(types.ProviderOllama, resp.StatusCode, resp.Header, responseBody)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var update PullProgress
+		if err := json.Unmarshal(line, &update); err != nil {
+			return fmt.Errorf("failed to decode pull progress: %w", err)
+		}
+		if update.Error != "" {
+			return errors.New(update.Error)
+		}
+		if progress != nil {
+			progress(update)
+		}
+		if update.Status == "success" {
+			return nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return fmt.Errorf("pull of %s ended before completing", model)
+}
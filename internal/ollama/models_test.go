@@ -0,0 +1,85 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestEndpointURL(t *testing.T) {
+	t.Parallel()
+
+	got, err := EndpointURL("http://localhost:11434/api/generate?x=1", "/api/tags")
+	if err != nil {
+		t.Fatalf("EndpointURL: %v", err)
+	}
+	if got != "http://localhost:11434/api/tags" {
+		t.Fatalf("unexpected URL %q", got)
+	}
+
+	if _, err := EndpointURL("localhost", "/api/tags"); err == nil {
+		t.Fatal("expected error for URL without host")
+	}
+}
+
+func TestListModels(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/tags" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		w.Write([]byte(`{"models":[{"name":"llama3.1:latest","size":4661224676},{"name":"qwen2.5-coder:7b","size":4683087332}]}`))
+	}))
+	t.Cleanup(server.Close)
+
+	models, err := ListModels(context.Background(), server.URL+"/api/generate")
+	if err != nil {
+		t.Fatalf("ListModels: %v", err)
+	}
+	if len(models) != 2 || models[0].Name != "llama3.1:latest" || models[1].Name != "qwen2.5-coder:7b" {
+		t.Fatalf("unexpected models %+v", models)
+	}
+}
+
+func TestPull(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		if req["model"] != "llama3.1" {
+			t.Errorf("unexpected model %v", req["model"])
+		}
+		w.Write([]byte("{\"status\":\"pulling manifest\"}\n{\"status\":\"downloading\",\"completed\":50,\"total\":100}\n{\"status\":\"success\"}\n"))
+	}))
+	t.Cleanup(server.Close)
+
+	var updates []PullProgress
+	err := Pull(context.Background(), server.URL+"/api/generate", "llama3.1", func(p PullProgress) {
+		updates = append(updates, p)
+	})
+	if err != nil {
+		t.Fatalf("Pull: %v", err)
+	}
+	if len(updates) != 3 || updates[1].Completed != 50 || updates[2].Status != "success" {
+		t.Fatalf("unexpected progress %+v", updates)
+	}
+}
+
+func TestPullReportsStreamedError(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("{\"status\":\"pulling manifest\"}\n{\"error\":\"pull model manifest: file does not exist\"}\n"))
+	}))
+	t.Cleanup(server.Close)
+
+	if err := Pull(context.Background(), server.URL, "nope", nil); err == nil {
+		t.Fatal("expected streamed error to be returned")
+	}
+}
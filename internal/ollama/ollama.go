@@ -1,91 +1,217 @@
 package ollama
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
+	"sync"
 
 	httpClient "github.com/dfanso/commit-msg/internal/http"
+	"github.com/dfanso/commit-msg/internal/llmerr"
 	"github.com/dfanso/commit-msg/pkg/types"
 )
 
 const (
 	ollamaDefaultModel = "llama3:latest"
-	ollamaStream       = false
 	ollamaContentType  = "application/json"
+	ollamaChatPath     = "/api/chat"
 )
 
-// OllamaRequest captures the prompt payload sent to an Ollama HTTP endpoint.
+// Options are the generation settings read from the "ollama" section of
+// config.json.
+type Options struct {
+	// Temperature sets the sampling temperature; nil keeps the model default.
+	Temperature *float64 `json:"temperature,omitempty"`
+	// NumCtx sets the context window in tokens; zero keeps the model default.
+	NumCtx int `json:"num_ctx,omitempty"`
+	// KeepAlive controls how long the model stays loaded after a request,
+	// such as "10m", or a negative duration to keep it loaded indefinitely.
+	KeepAlive string `json:"keep_alive,omitempty"`
+	// AllowedHosts lists public hosts the Ollama URL may point at, such as
+	// a team server, without a warning that prompts leave the network.
+	AllowedHosts []string `json:"allowed_hosts,omitempty"`
+}
+
+var (
+	optionsMu sync.RWMutex
+	options   Options
+)
+
+// Configure sets the options applied to every subsequent request.
+func Configure(opts Options) {
+	optionsMu.Lock()
+	defer optionsMu.Unlock()
+	options = opts
+}
+
+func configured() Options {
+	optionsMu.RLock()
+	defer optionsMu.RUnlock()
+	return options
+}
+
+// OllamaRequest captures the chat payload sent to an Ollama HTTP endpoint.
 type OllamaRequest struct {
-	Model  string `json:"model"`
-	Prompt string `json:"prompt"`
+	Model     string                 `json:"model"`
+	Messages  []types.Message        `json:"messages"`
+	Stream    bool                   `json:"stream"`
+	Options   map[string]interface{} `json:"options,omitempty"`
+	KeepAlive string                 `json:"keep_alive,omitempty"`
 }
 
-// OllamaResponse represents the non-streaming response from Ollama.
+// OllamaResponse represents a chat response, or one chunk of a streamed
+// response, from Ollama.
 type OllamaResponse struct {
-	Response string `json:"response"`
-	Done     bool   `json:"done"`
+	Message types.Message `json:"message"`
+	Done    bool          `json:"done"`
+	Error   string        `json:"error,omitempty"`
 }
 
 // GenerateCommitMessage uses a locally hosted Ollama model to draft a commit
-// message from repository changes and optional style guidance.
+// message from repository changes and optional style guidance. When
+// opts.Progress is set the response is streamed and reported as it arrives.
 func GenerateCommitMessage(_ *types.Config, changes string, url string, model string, opts *types.GenerationOptions) (string, error) {
 	// Use llama3:latest as the default model
 	if model == "" {
 		model = ollamaDefaultModel
 	}
 
-	// Preparing the prompt
-	prompt := types.BuildCommitPrompt(changes, opts)
+	endpoint, err := EndpointURL(url, ollamaChatPath)
+	if err != nil {
+		return "", err
+	}
 
-	// Generating the request body - add stream: false for non-streaming response
-	reqBody := map[string]interface{}{
-		"model":  model,
-		"prompt": prompt,
-		"stream": ollamaStream,
+	var progress func(string)
+	if opts != nil {
+		progress = opts.Progress
 	}
 
-	// Generating the body
+	reqBody := buildRequest(model, types.BuildCommitPrompt(changes, opts), configured(), opts)
+	reqBody.Stream = progress != nil
+
 	body, err := json.Marshal(reqBody)
 	if err != nil {
 		return "", fmt.Errorf("failed to marshal request: %v", err)
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(body))
+	req, err := http.NewRequest("POST", endpoint, bytes.NewBuffer(body))
 	if err != nil {
 		return "", fmt.Errorf("failed to create request: %v", err)
 	}
 	req.Header.Set("Content-Type", ollamaContentType)
 
-	resp, err := httpClient.GetOllamaClient().Do(req)
+	resp, err := httpClient.ClientFor(types.ProviderOllama).Do(req)
 	if err != nil {
 		return "", fmt.Errorf("failed to send request to Ollama: %v", err)
 	}
 	defer resp.Body.Close()
 
-	// Read the full response body for better error handling
-	responseBody, err := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		responseBody, _ := io.ReadAll(resp.Body)
+		return "", llmerr.FromResponse(types.ProviderOllama, resp.StatusCode, resp.Header, responseBody)
+	}
+
+	var message string
+	if progress != nil {
+		message, err = readStream(resp.Body, progress)
+	} else {
+		message, err = readResponse(resp.Body)
+	}
 	if err != nil {
-		return "", fmt.Errorf("failed to read response body: %v", err)
+		return "", err
 	}
 
-	// Check HTTP status
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("Ollama API returned status %d: %s", resp.StatusCode, string(responseBody))
+	// Check if we got any response
+	if message == "" {
+		return "", fmt.Errorf("received empty response from Ollama")
+	}
+
+	return message, nil
+}
+
+// buildRequest assembles the chat request, letting a per-request temperature
+// override the configured one and passing on a requested seed.
+func buildRequest(model, prompt string, cfg Options, opts *types.GenerationOptions) OllamaRequest {
+	req := OllamaRequest{
+		Model: model,
+		Messages: []types.Message{
+			{
+				Role:    "user",
+				Content: prompt,
+			},
+		},
+		KeepAlive: cfg.KeepAlive,
+	}
+
+	modelOptions := make(map[string]interface{})
+	temperature := cfg.Temperature
+	if opts != nil && opts.Temperature != nil {
+		temperature = opts.Temperature
+	}
+	if temperature != nil {
+		modelOptions["temperature"] = *temperature
+	}
+	if opts != nil && opts.Seed != nil {
+		modelOptions["seed"] = *opts.Seed
+	}
+	if cfg.NumCtx > 0 {
+		modelOptions["num_ctx"] = cfg.NumCtx
+	}
+	if len(modelOptions) > 0 {
+		req.Options = modelOptions
+	}
+	return req
+}
+
+func readResponse(r io.Reader) (string, error) {
+	// Read the full response body for better error handling
+	responseBody, err := io.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response body: %v", err)
 	}
 
-	// Since we set stream: false, we get a single response object
 	var response OllamaResponse
 	if err := json.Unmarshal(responseBody, &response); err != nil {
 		return "", fmt.Errorf("failed to decode response: %v", err)
 	}
-
-	// Check if we got any response
-	if response.Response == "" {
-		return "", fmt.Errorf("received empty response from Ollama")
+	if response.Error != "" {
+		return "", fmt.Errorf("ollama error: %s", response.Error)
 	}
+	return response.Message.Content, nil
+}
 
-	return response.Response, nil
+// readStream accumulates newline-delimited chunks, passing the text so far
+// to progress after each one.
+func readStream(r io.Reader, progress func(string)) (string, error) {
+	var text strings.Builder
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var chunk OllamaResponse
+		if err := json.Unmarshal(line, &chunk); err != nil {
+			return "", fmt.Errorf("failed to decode response: %v", err)
+		}
+		if chunk.Error != "" {
+			return "", fmt.Errorf("ollama error: %s", chunk.Error)
+		}
+		if chunk.Message.Content != "" {
+			text.WriteString(chunk.Message.Content)
+			progress(text.String())
+		}
+		if chunk.Done {
+			break
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("failed to read response body: %v", err)
+	}
+	return text.String(), nil
 }
@@ -35,8 +35,8 @@ func TestGenerateCommitMessage(t *testing.T) {
 		t.Parallel()
 
 		expectedResponse := OllamaResponse{
-			Response: "feat: add new feature",
-			Done:     true,
+			Message: types.Message{Role: "assistant", Content: "feat: add new feature"},
+			Done:    true,
 		}
 
 		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -77,8 +77,8 @@ func TestGenerateCommitMessage(t *testing.T) {
 		t.Parallel()
 
 		expectedResponse := OllamaResponse{
-			Response: "feat: add new feature",
-			Done:     true,
+			Message: types.Message{Role: "assistant", Content: "feat: add new feature"},
+			Done:    true,
 		}
 
 		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -110,8 +110,8 @@ func TestGenerateCommitMessageWithMockServer(t *testing.T) {
 		t.Parallel()
 
 		expectedResponse := OllamaResponse{
-			Response: "feat: add new feature",
-			Done:     true,
+			Message: types.Message{Role: "assistant", Content: "feat: add new feature"},
+			Done:    true,
 		}
 
 		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -125,8 +125,8 @@ func TestGenerateCommitMessageWithMockServer(t *testing.T) {
 			t.Fatalf("unexpected error: %v", err)
 		}
 
-		if result != expectedResponse.Response {
-			t.Fatalf("expected response '%s', got '%s'", expectedResponse.Response, result)
+		if result != expectedResponse.Message.Content {
+			t.Fatalf("expected response '%s', got '%s'", expectedResponse.Message.Content, result)
 		}
 	})
 
@@ -172,8 +172,8 @@ func TestGenerateCommitMessageWithMockServer(t *testing.T) {
 		t.Parallel()
 
 		expectedResponse := OllamaResponse{
-			Response: "",
-			Done:     true,
+			Message: types.Message{Role: "assistant", Content: ""},
+			Done:    true,
 		}
 
 		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -212,8 +212,8 @@ func TestGenerateCommitMessageIncludesStyleInstructions(t *testing.T) {
 	}
 
 	expectedResponse := OllamaResponse{
-		Response: "feat: add new feature",
-		Done:     true,
+		Message: types.Message{Role: "assistant", Content: "feat: add new feature"},
+		Done:    true,
 	}
 
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -222,10 +222,7 @@ func TestGenerateCommitMessageIncludesStyleInstructions(t *testing.T) {
 			t.Fatalf("failed to decode request: %v", err)
 		}
 
-		prompt, ok := req["prompt"].(string)
-		if !ok {
-			t.Fatal("expected prompt to be a string")
-		}
+		prompt := chatPrompt(t, req)
 
 		// Check that style instruction is included in the prompt
 		if !strings.Contains(prompt, "Use a casual tone") {
@@ -255,8 +252,8 @@ func TestGenerateCommitMessageWithLongChanges(t *testing.T) {
 	longChanges := strings.Repeat("This is a test change. ", 1000)
 
 	expectedResponse := OllamaResponse{
-		Response: "feat: add new feature",
-		Done:     true,
+		Message: types.Message{Role: "assistant", Content: "feat: add new feature"},
+		Done:    true,
 	}
 
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -265,10 +262,7 @@ func TestGenerateCommitMessageWithLongChanges(t *testing.T) {
 			t.Fatalf("failed to decode request: %v", err)
 		}
 
-		prompt, ok := req["prompt"].(string)
-		if !ok {
-			t.Fatal("expected prompt to be a string")
-		}
+		prompt := chatPrompt(t, req)
 
 		// Check that the long changes are included in the prompt
 		if !strings.Contains(prompt, longChanges) {
@@ -298,8 +292,8 @@ Line 2
 Line 3`
 
 	expectedResponse := OllamaResponse{
-		Response: "feat: add new feature",
-		Done:     true,
+		Message: types.Message{Role: "assistant", Content: "feat: add new feature"},
+		Done:    true,
 	}
 
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -308,10 +302,7 @@ Line 3`
 			t.Fatalf("failed to decode request: %v", err)
 		}
 
-		prompt, ok := req["prompt"].(string)
-		if !ok {
-			t.Fatal("expected prompt to be a string")
-		}
+		prompt := chatPrompt(t, req)
 
 		// Check that special characters are included in the prompt
 		if !strings.Contains(prompt, "ñáéíóú 🚀 🎉") {
@@ -333,8 +324,8 @@ func TestOllamaRequestSerialization(t *testing.T) {
 	t.Parallel()
 
 	req := OllamaRequest{
-		Model:  "llama3:latest",
-		Prompt: "test prompt",
+		Model:    "llama3:latest",
+		Messages: []types.Message{{Role: "user", Content: "test prompt"}},
 	}
 
 	data, err := json.Marshal(req)
@@ -351,8 +342,8 @@ func TestOllamaRequestSerialization(t *testing.T) {
 		t.Fatalf("expected model %s, got %s", req.Model, unmarshaled.Model)
 	}
 
-	if unmarshaled.Prompt != req.Prompt {
-		t.Fatalf("expected prompt %s, got %s", req.Prompt, unmarshaled.Prompt)
+	if len(unmarshaled.Messages) != 1 || unmarshaled.Messages[0].Content != "test prompt" {
+		t.Fatalf("expected prompt message, got %+v", unmarshaled.Messages)
 	}
 }
 
@@ -360,7 +351,7 @@ func TestOllamaResponseDeserialization(t *testing.T) {
 	t.Parallel()
 
 	jsonData := `{
-		"response": "feat: add new feature",
+		"message": {"role": "assistant", "content": "feat: add new feature"},
 		"done": true
 	}`
 
@@ -369,8 +360,8 @@ func TestOllamaResponseDeserialization(t *testing.T) {
 		t.Fatalf("failed to unmarshal response: %v", err)
 	}
 
-	if resp.Response != "feat: add new feature" {
-		t.Fatalf("expected response 'feat: add new feature', got %s", resp.Response)
+	if resp.Message.Content != "feat: add new feature" {
+		t.Fatalf("expected response 'feat: add new feature', got %s", resp.Message.Content)
 	}
 
 	if resp.Done != true {
@@ -386,8 +377,8 @@ func TestGenerateCommitMessageWithConfig(t *testing.T) {
 	}
 
 	expectedResponse := OllamaResponse{
-		Response: "feat: add new feature",
-		Done:     true,
+		Message: types.Message{Role: "assistant", Content: "feat: add new feature"},
+		Done:    true,
 	}
 
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -406,8 +397,8 @@ func TestGenerateCommitMessageWithNilConfig(t *testing.T) {
 	t.Parallel()
 
 	expectedResponse := OllamaResponse{
-		Response: "feat: add new feature",
-		Done:     true,
+		Message: types.Message{Role: "assistant", Content: "feat: add new feature"},
+		Done:    true,
 	}
 
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -421,3 +412,100 @@ func TestGenerateCommitMessageWithNilConfig(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 }
+
+// chatPrompt returns the user message content of a decoded chat request.
+func chatPrompt(t *testing.T, req map[string]interface{}) string {
+	t.Helper()
+
+	messages, ok := req["messages"].([]interface{})
+	if !ok || len(messages) == 0 {
+		t.Fatal("expected messages to be a non-empty array")
+	}
+	message, ok := messages[0].(map[string]interface{})
+	if !ok {
+		t.Fatal("expected message to be an object")
+	}
+	prompt, ok := message["content"].(string)
+	if !ok {
+		t.Fatal("expected prompt to be a string")
+	}
+	return prompt
+}
+
+func TestGenerateCommitMessageUsesChatEndpoint(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/chat" {
+			t.Errorf("expected /api/chat, got %s", r.URL.Path)
+		}
+
+		var req OllamaRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		if req.KeepAlive != "10m" {
+			t.Errorf("expected keep_alive 10m, got %q", req.KeepAlive)
+		}
+		if req.Options["num_ctx"] != float64(8192) || req.Options["temperature"] != 0.5 {
+			t.Errorf("unexpected options %v", req.Options)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(OllamaResponse{Message: types.Message{Role: "assistant", Content: "fix: trim input"}, Done: true})
+	}))
+	t.Cleanup(server.Close)
+
+	temperature := 0.2
+	Configure(Options{Temperature: &temperature, NumCtx: 8192, KeepAlive: "10m"})
+	t.Cleanup(func() { Configure(Options{}) })
+
+	override := 0.5
+	result, err := GenerateCommitMessage(&types.Config{}, "some changes", server.URL+"/api/generate", "llama3:latest", &types.GenerationOptions{Temperature: &override})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result != "fix: trim input" {
+		t.Fatalf("unexpected result %q", result)
+	}
+}
+
+func TestGenerateCommitMessageStreamsProgress(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req OllamaRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		if !req.Stream {
+			t.Error("expected stream true when progress is requested")
+		}
+		w.Write([]byte("{\"message\":{\"content\":\"feat: \"}}\n{\"message\":{\"content\":\"stream output\"}}\n{\"done\":true}\n"))
+	}))
+	t.Cleanup(server.Close)
+
+	var updates []string
+	opts := &types.GenerationOptions{Progress: func(partial string) { updates = append(updates, partial) }}
+	result, err := GenerateCommitMessage(&types.Config{}, "some changes", server.URL, "llama3:latest", opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result != "feat: stream output" {
+		t.Fatalf("unexpected result %q", result)
+	}
+	if len(updates) != 2 || updates[0] != "feat: " || updates[1] != "feat: stream output" {
+		t.Fatalf("unexpected progress %q", updates)
+	}
+}
+
+func TestBuildRequestPassesSeed(t *testing.T) {
+	seed := int64(42)
+	req := buildRequest("llama3", "prompt", Options{}, &types.GenerationOptions{Seed: &seed})
+	if req.Options["seed"] != int64(42) {
+		t.Fatalf("expected seed 42 in model options, got %v", req.Options)
+	}
+
+	req = buildRequest("llama3", "prompt", Options{}, nil)
+	if _, ok := req.Options["seed"]; ok {
+		t.Fatalf("expected no seed without options, got %v", req.Options)
+	}
+}
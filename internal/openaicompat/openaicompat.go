@@ -0,0 +1,173 @@
+// Package openaicompat talks to chat completions APIs that follow OpenAI's
+// schema, and holds presets for hosted open-model services that speak it.
+package openaicompat
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/dfanso/commit-msg/internal/llmerr"
+	"github.com/dfanso/commit-msg/pkg/types"
+)
+
+const (
+	contentType         = "application/json"
+	authorizationPrefix = "Bearer "
+	// SystemMessage is sent ahead of the commit prompt.
+	SystemMessage = "You are an assistant that writes clear, concise git commit messages."
+)
+
+// Message is one chat message.
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// ChatRequest is the body of a chat completions request.
+type ChatRequest struct {
+	Model       string    `json:"model"`
+	Messages    []Message `json:"messages"`
+	Temperature float64   `json:"temperature"`
+	MaxTokens   int       `json:"max_tokens,omitempty"`
+	Seed        *int64    `json:"seed,omitempty"`
+	Stream      bool      `json:"stream"`
+}
+
+// Usage is the token accounting returned with a completion.
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// ChatResponse is the body of a successful chat completions response.
+type ChatResponse struct {
+	Choices []struct {
+		Message      Message `json:"message"`
+		FinishReason string  `json:"finish_reason"`
+	} `json:"choices"`
+	Usage *Usage `json:"usage,omitempty"`
+}
+
+// NewChatRequest builds the request for a commit message from changes,
+// sending SystemMessage and the commit prompt.
+func NewChatRequest(model string, changes string, temperature float64, maxTokens int, opts *types.GenerationOptions) ChatRequest {
+	request := ChatRequest{
+		Model:       model,
+		Temperature: opts.TemperatureOr(temperature),
+		MaxTokens:   maxTokens,
+		Messages: []Message{
+			{Role: "system", Content: SystemMessage},
+			{Role: "user", Content: types.BuildCommitPrompt(changes, opts)},
+		},
+	}
+	if opts != nil {
+		request.Seed = opts.Seed
+	}
+	return request
+}
+
+// Complete posts request to the chat completions endpoint with apiKey and
+// returns the first choice and the reported usage, if any. Failures are
+// reported as coming from provider.
+func Complete(ctx context.Context, client *http.Client, provider types.LLMProvider, endpoint, apiKey string, request ChatRequest) (string, *types.UsageInfo, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to marshal %s request: %w", provider, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create %s request: %w", provider, err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Authorization", authorizationPrefix+apiKey)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to call %s API: %w", provider, err)
+	}
+	defer resp.Body.Close()
+
+	responseBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read %s response: %w", provider, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, llmerr.FromResponse(provider, resp.StatusCode, resp.Header, responseBody)
+	}
+
+	var completion ChatResponse
+	if err := json.Unmarshal(responseBody, &completion); err != nil {
+		return "", nil, fmt.Errorf("failed to decode %s response: %w", provider, err)
+	}
+	if len(completion.Choices) == 0 || completion.Choices[0].Message.Content == "" {
+		return "", nil, fmt.Errorf("empty response from %s API", provider)
+	}
+
+	return completion.Choices[0].Message.Content, completion.Usage.info(), nil
+}
+
+// info converts the usage to the form the rest of the program reports, or
+// nil when the service sent none.
+func (u *Usage) info() *types.UsageInfo {
+	if u == nil {
+		return nil
+	}
+	total := u.TotalTokens
+	if total == 0 {
+		total = u.PromptTokens + u.CompletionTokens
+	}
+	if total == 0 {
+		return nil
+	}
+	return &types.UsageInfo{
+		PromptTokens:     u.PromptTokens,
+		CompletionTokens: u.CompletionTokens,
+		TotalTokens:      total,
+	}
+}
+
+// ListModels returns the IDs served by an OpenAI-compatible /models
+// endpoint, in the order listed.
+func ListModels(ctx context.Context, client *http.Client, provider types.LLMProvider, endpoint, apiKey string) ([]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s request: %w", provider, err)
+	}
+	req.Header.Set("Authorization", authorizationPrefix+apiKey)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call %s API: %w", provider, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s response: %w", provider, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, llmerr.FromResponse(provider, resp.StatusCode, resp.Header, body)
+	}
+
+	var list struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &list); err != nil {
+		return nil, fmt.Errorf("failed to decode %s model list: %w", provider, err)
+	}
+	ids := make([]string, 0, len(list.Data))
+	for _, m := range list.Data {
+		if m.ID != "" {
+			ids = append(ids, m.ID)
+		}
+	}
+	return ids, nil
+}
@@ -0,0 +1,88 @@
+package openaicompat
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dfanso/commit-msg/internal/llmerr"
+	"github.com/dfanso/commit-msg/pkg/types"
+)
+
+func TestPresetGenerate(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer tg_test" {
+			t.Fatalf("unexpected authorization header: %s", got)
+		}
+		var payload ChatRequest
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+		if payload.Model != Together.DefaultModel() || len(payload.Messages) != 2 || payload.MaxTokens != presetMaxTokens {
+			t.Fatalf("unexpected request: %+v", payload)
+		}
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"docs: fix typo"}}],"usage":{"prompt_tokens":90,"completion_tokens":5}}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	t.Setenv("TOGETHER_BASE_URL", srv.URL+"/v1/")
+	t.Setenv("TOGETHER_MODEL", "")
+
+	msg, usage, err := Together.Generate(context.Background(), srv.Client(), "diff", "tg_test", "", nil)
+	if err != nil {
+		t.Fatalf("Generate returned error: %v", err)
+	}
+	if msg != "docs: fix typo" {
+		t.Fatalf("unexpected message: %q", msg)
+	}
+	if usage == nil || usage.TotalTokens != 95 {
+		t.Fatalf("expected usage to be totalled, got %+v", usage)
+	}
+}
+
+func TestCompleteClassifiesErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"error":{"message":"rate limit exceeded","type":"rate_limit"}}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	_, _, err := Complete(context.Background(), srv.Client(), types.ProviderFireworks, srv.URL, "key", ChatRequest{Model: "m"})
+	if !errors.Is(err, llmerr.ErrRateLimited) {
+		t.Fatalf("expected ErrRateLimited, got %v", err)
+	}
+}
+
+func TestPresetResolveModel(t *testing.T) {
+	t.Setenv("FIREWORKS_MODEL", "")
+	if got := Fireworks.ResolveModel(""); got != Fireworks.Models[0].ID {
+		t.Fatalf("expected the first curated model, got %q", got)
+	}
+	if got := Fireworks.ResolveModel("accounts/acme/models/commit"); got != "accounts/acme/models/commit" {
+		t.Fatalf("expected saved model, got %q", got)
+	}
+	t.Setenv("FIREWORKS_MODEL", "accounts/fireworks/models/deepseek-v3")
+	if got := Fireworks.ResolveModel("accounts/acme/models/commit"); got != "accounts/fireworks/models/deepseek-v3" {
+		t.Fatalf("expected FIREWORKS_MODEL to win, got %q", got)
+	}
+}
+
+func TestPresetsAreRegisteredProviders(t *testing.T) {
+	for _, preset := range Presets() {
+		if !preset.Provider.IsValid() {
+			t.Errorf("%s is not a supported provider", preset.Provider)
+		}
+		if got, ok := Lookup(preset.Provider); !ok || got.Name != preset.Name {
+			t.Errorf("Lookup(%s) = %+v, %v", preset.Provider, got, ok)
+		}
+		if len(preset.Models) == 0 {
+			t.Errorf("%s has no curated models", preset.Provider)
+		}
+	}
+}
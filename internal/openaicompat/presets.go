@@ -0,0 +1,144 @@
+package openaicompat
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"strings"
+
+	internalHTTP "github.com/dfanso/commit-msg/internal/http"
+	"github.com/dfanso/commit-msg/pkg/types"
+)
+
+const (
+	presetTemperature = 0.2
+	presetMaxTokens   = 200
+)
+
+// Model is a model offered in setup for a preset.
+type Model struct {
+	ID string
+	// Description is shown next to the ID when choosing a model.
+	Description string
+}
+
+// Preset describes a hosted service with an OpenAI-compatible API.
+type Preset struct {
+	Provider types.LLMProvider
+	// Name is the service's display name.
+	Name string
+	// BaseURL is the API root, to which /chat/completions and /models are
+	// appended.
+	BaseURL string
+	// KeyEnv holds the API key when none was saved.
+	KeyEnv string
+	// ModelEnv overrides the saved model.
+	ModelEnv string
+	// BaseURLEnv overrides BaseURL.
+	BaseURLEnv string
+	// KeysURL is where API keys are created.
+	KeysURL string
+	// Models is the curated list offered during setup; the first is the
+	// default.
+	Models []Model
+}
+
+// Together serves open models on Together AI.
+var Together = Preset{
+	Provider:   types.ProviderTogether,
+	Name:       "Together AI",
+	BaseURL:    "https://api.together.xyz/v1",
+	KeyEnv:     "TOGETHER_API_KEY",
+	ModelEnv:   "TOGETHER_MODEL",
+	BaseURLEnv: "TOGETHER_BASE_URL",
+	KeysURL:    "https://api.together.ai/settings/api-keys",
+	Models: []Model{
+		{ID: "meta-llama/Llama-3.3-70B-Instruct-Turbo", Description: "Llama 3.3 70B, a good all-rounder"},
+		{ID: "Qwen/Qwen2.5-Coder-32B-Instruct", Description: "Qwen 2.5 Coder 32B, tuned for code"},
+		{ID: "deepseek-ai/DeepSeek-V3", Description: "DeepSeek V3, the most capable"},
+		{ID: "meta-llama/Meta-Llama-3.1-8B-Instruct-Turbo", Description: "Llama 3.1 8B, the cheapest"},
+	},
+}
+
+// Fireworks serves open models on Fireworks AI.
+var Fireworks = Preset{
+	Provider:   types.ProviderFireworks,
+	Name:       "Fireworks AI",
+	BaseURL:    "https://api.fireworks.ai/inference/v1",
+	KeyEnv:     "FIREWORKS_API_KEY",
+	ModelEnv:   "FIREWORKS_MODEL",
+	BaseURLEnv: "FIREWORKS_BASE_URL",
+	KeysURL:    "https://fireworks.ai/account/api-keys",
+	Models: []Model{
+		{ID: "accounts/fireworks/models/llama-v3p3-70b-instruct", Description: "Llama 3.3 70B, a good all-rounder"},
+		{ID: "accounts/fireworks/models/qwen2p5-coder-32b-instruct", Description: "Qwen 2.5 Coder 32B, tuned for code"},
+		{ID: "accounts/fireworks/models/deepseek-v3", Description: "DeepSeek V3, the most capable"},
+		{ID: "accounts/fireworks/models/llama-v3p1-8b-instruct", Description: "Llama 3.1 8B, the cheapest"},
+	},
+}
+
+var presets = []Preset{Together, Fireworks}
+
+// Presets returns the built-in presets.
+func Presets() []Preset {
+	return append([]Preset(nil), presets...)
+}
+
+// Lookup returns the preset for provider.
+func Lookup(provider types.LLMProvider) (Preset, bool) {
+	for _, preset := range presets {
+		if preset.Provider == provider {
+			return preset, true
+		}
+	}
+	return Preset{}, false
+}
+
+// DefaultModel is the first curated model.
+func (p Preset) DefaultModel() string {
+	if len(p.Models) == 0 {
+		return ""
+	}
+	return p.Models[0].ID
+}
+
+// ResolveModel returns the model requested via the preset's model variable,
+// then saved (the model chosen during setup), then DefaultModel.
+func (p Preset) ResolveModel(saved string) string {
+	if model := strings.TrimSpace(os.Getenv(p.ModelEnv)); model != "" {
+		return model
+	}
+	if saved = strings.TrimSpace(saved); saved != "" {
+		return saved
+	}
+	return p.DefaultModel()
+}
+
+// APIBase returns the API root, honouring the preset's base URL variable.
+func (p Preset) APIBase() string {
+	if custom := strings.TrimSpace(os.Getenv(p.BaseURLEnv)); custom != "" {
+		return strings.TrimRight(custom, "/")
+	}
+	return p.BaseURL
+}
+
+// APIKey returns credential, or the preset's key variable when it is empty.
+func (p Preset) APIKey(credential string) string {
+	if key := strings.TrimSpace(credential); key != "" {
+		return key
+	}
+	return strings.TrimSpace(os.Getenv(p.KeyEnv))
+}
+
+// Generate asks the service for a commit message with model, or the model
+// ResolveModel picks when model is empty, and returns the reported usage.
+func (p Preset) Generate(ctx context.Context, client *http.Client, changes, apiKey, model string, opts *types.GenerationOptions) (string, *types.UsageInfo, error) {
+	if model == "" {
+		model = p.ResolveModel("")
+	}
+	if client == nil {
+		client = internalHTTP.ClientFor(p.Provider)
+	}
+	request := NewChatRequest(model, changes, presetTemperature, presetMaxTokens, opts)
+	return Complete(ctx, client, p.Provider, p.APIBase()+"/chat/completions", apiKey, request)
+}
@@ -0,0 +1,210 @@
+// Package platform smooths over differences between operating systems that
+// matter to an interactive CLI: Windows Subsystem for Linux, Git Bash paths,
+// clipboard tools, and the fallback editor.
+package platform
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/atotto/clipboard"
+	"github.com/dfanso/commit-msg/internal/utils"
+	"github.com/google/shlex"
+)
+
+var (
+	wslOnce sync.Once
+	wsl     bool
+)
+
+// IsWSL reports whether the process runs under Windows Subsystem for Linux.
+func IsWSL() bool {
+	wslOnce.Do(func() {
+		release, _ := os.ReadFile("/proc/sys/kernel/osrelease")
+		wsl = isWSL(runtime.GOOS, string(release), os.Getenv)
+	})
+	return wsl
+}
+
+func isWSL(goos, osRelease string, getenv func(string) string) bool {
+	if goos != "linux" {
+		return false
+	}
+	if getenv("WSL_DISTRO_NAME") != "" || getenv("WSL_INTEROP") != "" {
+		return true
+	}
+	release := strings.ToLower(osRelease)
+	return strings.Contains(release, "microsoft") || strings.Contains(release, "wsl")
+}
+
+var (
+	// wslShare matches //wsl$/<distro>/ and //wsl.localhost/<distro>/ after
+	// utils.NormalizePath.
+	wslShare = regexp.MustCompile(`(?i)^//wsl(\$|\.localhost)/[^/]+(/.*)?$`)
+	// drivePath matches C:/... after utils.NormalizePath.
+	drivePath = regexp.MustCompile(`^([A-Za-z]):(/.*)?$`)
+	// msysDrivePath matches the /c/... form used by Git Bash and MSYS2.
+	msysDrivePath = regexp.MustCompile(`^/([A-Za-z])(/.*)?$`)
+)
+
+// ResolvePath translates a path written for another side of a Windows
+// machine into one this process can open: under WSL, \\wsl$\Distro\home and
+// C:\Users become /home and /mnt/c/Users; on Windows, the Git Bash form
+// /c/Users becomes C:/Users. Other paths are returned unchanged.
+func ResolvePath(path string) string {
+	return resolvePath(path, runtime.GOOS, IsWSL())
+}
+
+func resolvePath(path, goos string, wsl bool) string {
+	normalized := utils.NormalizePath(path)
+	switch {
+	case wsl:
+		if match := wslShare.FindStringSubmatch(normalized); match != nil {
+			if match[2] == "" {
+				return "/"
+			}
+			return match[2]
+		}
+		if match := drivePath.FindStringSubmatch(normalized); match != nil {
+			return "/mnt/" + strings.ToLower(match[1]) + match[2]
+		}
+	case goos == "windows":
+		if match := msysDrivePath.FindStringSubmatch(normalized); match != nil {
+			rest := match[2]
+			if rest == "" {
+				rest = "/"
+			}
+			return strings.ToUpper(match[1]) + ":" + rest
+		}
+	}
+	return path
+}
+
+// CopyToClipboard places text on the system clipboard. Under WSL the Windows
+// clipboard is used through clip.exe; on Linux, wl-copy, xclip, and xsel are
+// tried for the running display server before the generic fallback.
+func CopyToClipboard(text string) error {
+	var errs []error
+	for _, args := range clipboardCommands(runtime.GOOS, IsWSL(), os.Getenv) {
+		if _, err := exec.LookPath(args[0]); err != nil {
+			continue
+		}
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		if err := cmd.Run(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", args[0], err))
+			continue
+		}
+		return nil
+	}
+
+	if err := clipboard.WriteAll(text); err != nil {
+		return errors.Join(append(errs, err)...)
+	}
+	return nil
+}
+
+// ClipboardTool names the program CopyToClipboard would use, and reports
+// false when no clipboard is available.
+func ClipboardTool() (string, bool) {
+	for _, args := range clipboardCommands(runtime.GOOS, IsWSL(), os.Getenv) {
+		if _, err := exec.LookPath(args[0]); err == nil {
+			return args[0], true
+		}
+	}
+	if clipboard.Unsupported {
+		return "", false
+	}
+	return "system clipboard", true
+}
+
+// clipboardCommands lists the copy commands to try, in order, before falling
+// back to the clipboard package.
+func clipboardCommands(goos string, wsl bool, getenv func(string) string) [][]string {
+	if goos != "linux" {
+		return nil
+	}
+
+	var commands [][]string
+	if wsl {
+		commands = append(commands, []string{"clip.exe"})
+	}
+	if getenv("WAYLAND_DISPLAY") != "" {
+		commands = append(commands, []string{"wl-copy"})
+	}
+	if getenv("DISPLAY") != "" {
+		commands = append(commands,
+			[]string{"xclip", "-selection", "clipboard"},
+			[]string{"xsel", "--clipboard", "--input"},
+		)
+	}
+	return commands
+}
+
+// EditorCommand returns the program and arguments of the first non-empty
+// editor command among candidates, such as the values of GIT_EDITOR, VISUAL,
+// and EDITOR. When none is set it falls back to notepad on Windows and nano
+// elsewhere.
+func EditorCommand(candidates ...string) (string, []string, error) {
+	return editorCommand(candidates, runtime.GOOS, fileExists)
+}
+
+// PagerCommand returns the program and arguments of the first non-empty
+// pager command among candidates, such as the value of PAGER. The program is
+// empty when none is set.
+func PagerCommand(candidates ...string) (string, []string, error) {
+	return splitCommand(candidates, runtime.GOOS, fileExists)
+}
+
+func editorCommand(candidates []string, goos string, exists func(string) bool) (string, []string, error) {
+	command, args, err := splitCommand(candidates, goos, exists)
+	if err != nil || command != "" {
+		return command, args, err
+	}
+	if goos == "windows" {
+		return "notepad", nil, nil
+	}
+	return "nano", nil, nil
+}
+
+// splitCommand parses the first non-empty command among candidates into its
+// program and arguments. The program is empty when every candidate is.
+func splitCommand(candidates []string, goos string, exists func(string) bool) (string, []string, error) {
+	for _, candidate := range candidates {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "" {
+			continue
+		}
+
+		if goos == "windows" {
+			// An unquoted path such as C:\Program Files\Vim\gvim.exe is
+			// taken as a whole, and backslashes are path separators rather
+			// than escapes.
+			if exists(candidate) {
+				return candidate, nil, nil
+			}
+			candidate = strings.ReplaceAll(candidate, `\`, `\\`)
+		}
+
+		parts, err := shlex.Split(candidate)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to parse command %q: %w", candidate, err)
+		}
+		if len(parts) == 0 {
+			continue
+		}
+		return parts[0], parts[1:], nil
+	}
+	return "", nil, nil
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
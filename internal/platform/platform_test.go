@@ -0,0 +1,141 @@
+package platform
+
+import (
+	"strings"
+	"testing"
+)
+
+func env(values map[string]string) func(string) string {
+	return func(key string) string { return values[key] }
+}
+
+func TestIsWSL(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		goos    string
+		release string
+		env     map[string]string
+		want    bool
+	}{
+		{name: "wsl2 kernel", goos: "linux", release: "5.15.90.1-microsoft-standard-WSL2", want: true},
+		{name: "distro env", goos: "linux", release: "6.1.0", env: map[string]string{"WSL_DISTRO_NAME": "Ubuntu"}, want: true},
+		{name: "plain linux", goos: "linux", release: "6.1.0-13-amd64"},
+		{name: "windows", goos: "windows", env: map[string]string{"WSL_DISTRO_NAME": "Ubuntu"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isWSL(tt.goos, tt.release, env(tt.env)); got != tt.want {
+				t.Fatalf("isWSL() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolvePath(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		path string
+		goos string
+		wsl  bool
+		want string
+	}{
+		{name: "wsl share", path: `\\wsl$\Ubuntu\home\me\repo`, goos: "linux", wsl: true, want: "/home/me/repo"},
+		{name: "wsl localhost share", path: `\\wsl.localhost\Debian\srv`, goos: "linux", wsl: true, want: "/srv"},
+		{name: "drive under wsl", path: `C:\Users\me\repo`, goos: "linux", wsl: true, want: "/mnt/c/Users/me/repo"},
+		{name: "drive root under wsl", path: `D:\`, goos: "linux", wsl: true, want: "/mnt/d"},
+		{name: "linux path under wsl", path: "/home/me/repo", goos: "linux", wsl: true, want: "/home/me/repo"},
+		{name: "git bash path on windows", path: "/c/Users/me/repo", goos: "windows", want: "C:/Users/me/repo"},
+		{name: "windows path on windows", path: `C:\repo`, goos: "windows", want: `C:\repo`},
+		{name: "plain linux", path: `C:\repo`, goos: "linux", want: `C:\repo`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resolvePath(tt.path, tt.goos, tt.wsl); got != tt.want {
+				t.Fatalf("resolvePath(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClipboardCommands(t *testing.T) {
+	t.Parallel()
+
+	names := func(commands [][]string) string {
+		var out []string
+		for _, command := range commands {
+			out = append(out, command[0])
+		}
+		return strings.Join(out, ",")
+	}
+
+	if got := names(clipboardCommands("linux", true, env(nil))); got != "clip.exe" {
+		t.Fatalf("WSL commands = %q, want clip.exe", got)
+	}
+	if got := names(clipboardCommands("linux", false, env(map[string]string{"WAYLAND_DISPLAY": "wayland-0", "DISPLAY": ":0"}))); got != "wl-copy,xclip,xsel" {
+		t.Fatalf("desktop commands = %q, want wl-copy,xclip,xsel", got)
+	}
+	if got := clipboardCommands("darwin", false, env(map[string]string{"DISPLAY": ":0"})); got != nil {
+		t.Fatalf("darwin commands = %v, want nil", got)
+	}
+}
+
+func TestEditorCommand(t *testing.T) {
+	t.Parallel()
+
+	noFiles := func(string) bool { return false }
+	tests := []struct {
+		name       string
+		candidates []string
+		goos       string
+		exists     func(string) bool
+		want       string
+		wantArgs   []string
+	}{
+		{name: "first set wins", candidates: []string{"", "code --wait", "vim"}, goos: "linux", want: "code", wantArgs: []string{"--wait"}},
+		{name: "linux fallback", candidates: []string{"", " "}, goos: "linux", want: "nano"},
+		{name: "windows fallback", goos: "windows", want: "notepad"},
+		{
+			name:       "windows quoted path",
+			candidates: []string{`"C:\Program Files\Notepad++\notepad++.exe" -multiInst -nosession`},
+			goos:       "windows",
+			want:       `C:\Program Files\Notepad++\notepad++.exe`,
+			wantArgs:   []string{"-multiInst", "-nosession"},
+		},
+		{
+			name:       "windows unquoted existing path",
+			candidates: []string{`C:\Program Files\Vim\gvim.exe`},
+			goos:       "windows",
+			exists:     func(path string) bool { return path == `C:\Program Files\Vim\gvim.exe` },
+			want:       `C:\Program Files\Vim\gvim.exe`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exists := tt.exists
+			if exists == nil {
+				exists = noFiles
+			}
+			got, args, err := editorCommand(tt.candidates, tt.goos, exists)
+			if err != nil {
+				t.Fatalf("editorCommand() error = %v", err)
+			}
+			if got != tt.want || strings.Join(args, " ") != strings.Join(tt.wantArgs, " ") {
+				t.Fatalf("editorCommand() = %q %q, want %q %q", got, args, tt.want, tt.wantArgs)
+			}
+		})
+	}
+
+	if _, _, err := editorCommand([]string{`vim "unterminated`}, "linux", noFiles); err == nil {
+		t.Fatal("expected an error for an unterminated quote")
+	}
+	if got, _, err := splitCommand([]string{"", " "}, "linux", noFiles); err != nil || got != "" {
+		t.Fatalf("splitCommand() = %q, %v; want no command", got, err)
+	}
+}
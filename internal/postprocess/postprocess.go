@@ -0,0 +1,206 @@
+// Package postprocess normalises LLM output into a clean commit message.
+// Models wrap their answers inconsistently, so every provider's response
+// goes through the same steps before it is shown.
+package postprocess
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Options selects the post-processing steps. The JSON names are the keys of
+// the "postprocess" section in config.json.
+type Options struct {
+	// StripCodeFences unwraps a message enclosed in a ``` block.
+	StripCodeFences bool `json:"strip_code_fences"`
+	// StripQuotes removes quotes surrounding the whole message.
+	StripQuotes bool `json:"strip_quotes"`
+	// ImperativeMood rewrites a leading "Added"/"Adds"/"Adding" as "Add".
+	ImperativeMood bool `json:"imperative_mood"`
+	// CapitalizeSubject upper-cases the first letter of subjects without a
+	// conventional commit prefix.
+	CapitalizeSubject bool `json:"capitalize_subject"`
+	// StripTrailingPeriod removes a single trailing period from the subject.
+	StripTrailingPeriod bool `json:"strip_trailing_period"`
+}
+
+// DefaultOptions enables every step.
+func DefaultOptions() Options {
+	return Options{
+		StripCodeFences:     true,
+		StripQuotes:         true,
+		ImperativeMood:      true,
+		CapitalizeSubject:   true,
+		StripTrailingPeriod: true,
+	}
+}
+
+var (
+	fencePattern = regexp.MustCompile("(?s)^```[A-Za-z0-9_-]*[ \t]*\n(.*?)\n?```$")
+	// conventionalPrefix matches "type(scope)!: " at the start of a subject.
+	conventionalPrefix = regexp.MustCompile(`^[A-Za-z]+(\([^)]*\))?!?:\s*`)
+)
+
+// quotePairs lists the quote characters stripped from around a message.
+var quotePairs = [][2]string{
+	{`"`, `"`},
+	{`'`, `'`},
+	{"`", "`"},
+	{"“", "”"},
+	{"‘", "’"},
+}
+
+// Apply runs the enabled steps over message.
+func Apply(message string, opts Options) string {
+	message = strings.TrimSpace(message)
+
+	if opts.StripCodeFences {
+		if match := fencePattern.FindStringSubmatch(message); match != nil {
+			message = strings.TrimSpace(match[1])
+		}
+	}
+	if opts.StripQuotes {
+		message = stripQuotes(message)
+	}
+
+	subject, body, hasBody := strings.Cut(message, "\n")
+	subject = strings.TrimSpace(subject)
+
+	prefix := conventionalPrefix.FindString(subject)
+	description := subject[len(prefix):]
+
+	if opts.ImperativeMood {
+		description = imperative(description)
+	}
+	if opts.CapitalizeSubject && prefix == "" {
+		description = capitalize(description)
+	}
+	if opts.StripTrailingPeriod && strings.HasSuffix(description, ".") && !strings.HasSuffix(description, "..") {
+		description = strings.TrimSuffix(description, ".")
+	}
+
+	subject = prefix + description
+	if !hasBody {
+		return subject
+	}
+	return subject + "\n" + body
+}
+
+func stripQuotes(message string) string {
+	for _, pair := range quotePairs {
+		open, close := pair[0], pair[1]
+		if len(message) >= len(open)+len(close) && strings.HasPrefix(message, open) && strings.HasSuffix(message, close) {
+			inner := message[len(open) : len(message)-len(close)]
+			// Leave messages that merely start and end with separate quotes.
+			if !strings.Contains(inner, close) {
+				return strings.TrimSpace(inner)
+			}
+		}
+	}
+	return message
+}
+
+func capitalize(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	if r == utf8.RuneError || !unicode.IsLower(r) {
+		return s
+	}
+	return string(unicode.ToUpper(r)) + s[size:]
+}
+
+// BaseForm returns the imperative form of word when it is a known verb in
+// past tense, third person, or gerund form, such as "add" for "Added".
+func BaseForm(word string) (string, bool) {
+	base, ok := verbForms[strings.ToLower(word)]
+	if !ok || base == strings.ToLower(word) {
+		return "", false
+	}
+	return base, true
+}
+
+// imperative rewrites the first word of description into its base form when
+// it is a known verb in past tense, third person, or gerund form.
+func imperative(description string) string {
+	word, rest, hasRest := strings.Cut(description, " ")
+	base, ok := verbForms[strings.ToLower(word)]
+	if !ok {
+		return description
+	}
+
+	if r, _ := utf8.DecodeRuneInString(word); unicode.IsUpper(r) {
+		base = capitalize(base)
+	}
+	if !hasRest {
+		return base
+	}
+	return base + " " + rest
+}
+
+// baseVerbs are verbs that commonly start commit subjects.
+var baseVerbs = []string{
+	"add", "adjust", "allow", "apply", "avoid", "bump", "change", "clarify",
+	"clean", "configure", "convert", "correct", "create", "deprecate",
+	"delete", "disable", "document", "drop", "enable", "ensure", "expose",
+	"extract", "fix", "handle", "implement", "improve", "include",
+	"initialize", "introduce", "merge", "migrate", "move", "optimize",
+	"prevent", "refactor", "reduce", "release", "remove", "rename",
+	"reorganize", "replace", "restore", "restructure", "revert", "rewrite",
+	"simplify", "skip", "split", "strip", "support", "switch", "tidy",
+	"update", "upgrade", "use", "validate", "wrap",
+}
+
+// irregularForms maps inflections that the suffix rules get wrong.
+var irregularForms = map[string]string{
+	"made":      "make",
+	"makes":     "make",
+	"making":    "make",
+	"rewrote":   "rewrite",
+	"rewritten": "rewrite",
+	"wrote":     "write",
+	"writes":    "write",
+	"writing":   "write",
+	"split":     "split",
+	"splitting": "split",
+}
+
+// doubled lists verbs that double their final consonant before -ed/-ing.
+var doubled = map[string]bool{"drop": true, "skip": true, "strip": true, "wrap": true}
+
+var verbForms = buildVerbForms()
+
+func buildVerbForms() map[string]string {
+	forms := make(map[string]string)
+	for _, base := range baseVerbs {
+		for _, form := range inflect(base) {
+			forms[form] = base
+		}
+	}
+	for form, base := range irregularForms {
+		forms[form] = base
+	}
+	return forms
+}
+
+// inflect returns the third-person, past, and gerund forms of a regular verb.
+func inflect(base string) []string {
+	last := base[len(base)-1]
+	stem := base
+	if doubled[base] {
+		stem = base + string(last)
+	}
+
+	switch {
+	case strings.HasSuffix(base, "e"):
+		trimmed := strings.TrimSuffix(base, "e")
+		return []string{base + "s", base + "d", trimmed + "ing"}
+	case strings.HasSuffix(base, "y") && !strings.ContainsRune("aeiou", rune(base[len(base)-2])):
+		trimmed := strings.TrimSuffix(base, "y")
+		return []string{trimmed + "ies", trimmed + "ied", base + "ing"}
+	case strings.HasSuffix(base, "x"), strings.HasSuffix(base, "sh"), strings.HasSuffix(base, "ch"), strings.HasSuffix(base, "s"):
+		return []string{base + "es", base + "ed", base + "ing"}
+	default:
+		return []string{base + "s", stem + "ed", stem + "ing"}
+	}
+}
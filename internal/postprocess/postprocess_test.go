@@ -0,0 +1,127 @@
+package postprocess
+
+import "testing"
+
+func TestApply(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "clean message unchanged", input: "feat: add login page", want: "feat: add login page"},
+		{name: "code fence", input: "```\nfix: handle nil config\n```", want: "fix: handle nil config"},
+		{name: "code fence with language", input: "```text\nfeat: add cache\n\n- store results\n```", want: "feat: add cache\n\n- store results"},
+		{name: "double quotes", input: `"Update README"`, want: "Update README"},
+		{name: "smart quotes", input: "“fix: typo in docs”", want: "fix: typo in docs"},
+		{name: "quotes inside kept", input: `"a" and "b" differ`, want: `"a" and "b" differ`},
+		{name: "past tense", input: "Added retry logic", want: "Add retry logic"},
+		{name: "third person after prefix", input: "fix(api): fixes nil pointer", want: "fix(api): fix nil pointer"},
+		{name: "gerund", input: "Updating dependencies", want: "Update dependencies"},
+		{name: "y verb", input: "Simplified parser", want: "Simplify parser"},
+		{name: "doubled consonant", input: "chore: dropped legacy flag", want: "chore: drop legacy flag"},
+		{name: "irregular", input: "Rewrote the scheduler", want: "Rewrite the scheduler"},
+		{name: "unknown verb untouched", input: "Bugfix for login", want: "Bugfix for login"},
+		{name: "capitalize without prefix", input: "remove unused helper", want: "Remove unused helper"},
+		{name: "conventional prefix stays lowercase", input: "refactor!: remove old API", want: "refactor!: remove old API"},
+		{name: "trailing period", input: "Fix crash on startup.\n\nThe body keeps its period.", want: "Fix crash on startup\n\nThe body keeps its period."},
+		{name: "ellipsis kept", input: "Fix crash...", want: "Fix crash..."},
+		{name: "all steps", input: "```\n\"added tests.\"\n```", want: "Add tests"},
+	}
+
+	for _, tt := range tests {
+		if got := Apply(tt.input, DefaultOptions()); got != tt.want {
+			t.Errorf("%s: Apply(%q) = %q, want %q", tt.name, tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestApplyDisabledSteps(t *testing.T) {
+	t.Parallel()
+
+	input := "```\n\"added tests.\"\n```"
+	if got := Apply(input, Options{}); got != input {
+		t.Fatalf("Apply with no steps = %q, want input unchanged", got)
+	}
+
+	opts := DefaultOptions()
+	opts.ImperativeMood = false
+	if got, want := Apply("added tests.", opts), "Added tests"; got != want {
+		t.Fatalf("Apply without imperative mood = %q, want %q", got, want)
+	}
+}
+
+func TestBaseForm(t *testing.T) {
+	t.Parallel()
+
+	if base, ok := BaseForm("Fixes"); !ok || base != "fix" {
+		t.Fatalf("BaseForm(Fixes) = %q, %v; want fix, true", base, ok)
+	}
+	for _, word := range []string{"Add", "split", "parser"} {
+		if base, ok := BaseForm(word); ok {
+			t.Fatalf("BaseForm(%q) = %q, want no rewrite", word, base)
+		}
+	}
+}
+
+func TestAddTrailer(t *testing.T) {
+	t.Parallel()
+
+	const trailer = "Commit-Generated-By: gocommit/OpenAI"
+	tests := []struct {
+		name    string
+		message string
+		want    string
+	}{
+		{name: "subject only", message: "feat: add login", want: "feat: add login\n\n" + trailer},
+		{name: "trailer-like body", message: "feat: add login\n\nUses: the new session store", want: "feat: add login\n\nUses: the new session store\n" + trailer},
+		{name: "prose body", message: "feat: add login\n\nAdds a form.\n", want: "feat: add login\n\nAdds a form.\n\n" + trailer},
+		{name: "existing trailers", message: "fix: typo\n\nRefs: #12\nSigned-off-by: A <a@b.c>", want: "fix: typo\n\nRefs: #12\nSigned-off-by: A <a@b.c>\n" + trailer},
+		{name: "already present", message: "fix: typo\n\n" + trailer, want: "fix: typo\n\n" + trailer},
+		{name: "subject looking like a trailer", message: "fix: typo", want: "fix: typo\n\n" + trailer},
+	}
+
+	for _, tt := range tests {
+		if got := AddTrailer(tt.message, trailer); got != tt.want {
+			t.Errorf("%s: AddTrailer(%q) = %q, want %q", tt.name, tt.message, got, tt.want)
+		}
+	}
+	if got := AddTrailer("fix: typo", " "); got != "fix: typo" {
+		t.Errorf("AddTrailer with an empty trailer = %q, want the message unchanged", got)
+	}
+}
+
+func TestSubject(t *testing.T) {
+	t.Parallel()
+
+	if got := Subject("\n  feat: add login  \n\nBody text."); got != "feat: add login" {
+		t.Fatalf("Subject() = %q, want the first non-blank line", got)
+	}
+	if got := Subject(" \n "); got != "" {
+		t.Fatalf("Subject() = %q, want empty", got)
+	}
+}
+
+func TestShortenSubject(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		subject string
+		limit   int
+		want    string
+	}{
+		{subject: "fix: handle nil config", limit: 50, want: "fix: handle nil config"},
+		{subject: "fix: handle nil config in the loader", limit: 22, want: "fix: handle nil config"},
+		{subject: "fix: handle nil config, empty paths", limit: 24, want: "fix: handle nil config"},
+		{subject: "refactor: supercalifragilistic", limit: 12, want: "refactor"},
+		{subject: "supercalifragilistic", limit: 5, want: "super"},
+		{subject: "fix: anything", limit: 0, want: "fix: anything"},
+	}
+
+	for _, tt := range tests {
+		if got := ShortenSubject(tt.subject, tt.limit); got != tt.want {
+			t.Errorf("ShortenSubject(%q, %d) = %q, want %q", tt.subject, tt.limit, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package postprocess
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Subject returns the first non-blank line of message, trimmed.
+func Subject(message string) string {
+	for _, line := range strings.Split(message, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
+
+// ShortenSubject cuts subject to at most limit characters, breaking at the
+// last word that fits and dropping punctuation left dangling at the end. A
+// first word longer than limit is cut mid-word. A limit of zero or less
+// leaves subject unchanged.
+func ShortenSubject(subject string, limit int) string {
+	subject = strings.TrimSpace(subject)
+	if limit <= 0 || utf8.RuneCountInString(subject) <= limit {
+		return subject
+	}
+
+	runes := []rune(subject)
+	cut := string(runes[:limit])
+	// Keep whole words unless that would leave nothing but a prefix.
+	if runes[limit] != ' ' {
+		if i := strings.LastIndexByte(cut, ' '); i > 0 {
+			cut = cut[:i]
+		}
+	}
+	return strings.TrimRight(cut, " ,;:-(/")
+}
@@ -0,0 +1,37 @@
+package postprocess
+
+import (
+	"regexp"
+	"strings"
+)
+
+// trailerLine matches a git trailer such as "Signed-off-by: Jane <j@x>".
+var trailerLine = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9-]*: \S`)
+
+// AddTrailer appends trailer to message the way git interpret-trailers
+// does: to the existing trailer block when the last paragraph is one,
+// otherwise as a new paragraph. A trailer already present is not repeated.
+func AddTrailer(message, trailer string) string {
+	message = strings.TrimRight(message, "\n\t ")
+	trailer = strings.TrimSpace(trailer)
+	if trailer == "" {
+		return message
+	}
+
+	paragraphs := strings.Split(message, "\n\n")
+	last := paragraphs[len(paragraphs)-1]
+	isTrailerBlock := len(paragraphs) > 1
+	for _, line := range strings.Split(last, "\n") {
+		if strings.TrimSpace(line) == trailer {
+			return message
+		}
+		if !trailerLine.MatchString(line) {
+			isTrailerBlock = false
+		}
+	}
+
+	if isTrailerBlock {
+		return message + "\n" + trailer
+	}
+	return message + "\n\n" + trailer
+}
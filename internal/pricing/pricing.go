@@ -0,0 +1,192 @@
+// Package pricing estimates LLM request costs from a per-model price table.
+package pricing
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dfanso/commit-msg/pkg/types"
+	StoreUtils "github.com/dfanso/commit-msg/utils"
+)
+
+// OverrideFileName is the name of the optional price override file stored
+// alongside config.json.
+const OverrideFileName = "pricing.json"
+
+// Price holds the USD price per one million input and output tokens.
+type Price struct {
+	InputPerMillion  float64 `json:"input_per_million"`
+	OutputPerMillion float64 `json:"output_per_million"`
+}
+
+// Table maps a provider and model name to its price.
+type Table map[types.LLMProvider]map[string]Price
+
+// defaultModelKey is the model entry used when a model has no explicit price.
+const defaultModelKey = "*"
+
+// defaultTable lists approximate public list prices per 1M tokens.
+var defaultTable = Table{
+	types.ProviderOpenAI: {
+		defaultModelKey: {InputPerMillion: 2.50, OutputPerMillion: 10.00},
+		"gpt-4o":        {InputPerMillion: 2.50, OutputPerMillion: 10.00},
+		"gpt-4o-mini":   {InputPerMillion: 0.15, OutputPerMillion: 0.60},
+		"gpt-4.1":       {InputPerMillion: 2.00, OutputPerMillion: 8.00},
+		"gpt-4.1-mini":  {InputPerMillion: 0.40, OutputPerMillion: 1.60},
+	},
+	types.ProviderClaude: {
+		defaultModelKey:              {InputPerMillion: 3.00, OutputPerMillion: 15.00},
+		"claude-3-5-sonnet-20241022": {InputPerMillion: 3.00, OutputPerMillion: 15.00},
+		"claude-3-5-haiku-20241022":  {InputPerMillion: 0.80, OutputPerMillion: 4.00},
+		"claude-3-opus-20240229":     {InputPerMillion: 15.00, OutputPerMillion: 75.00},
+	},
+	types.ProviderGemini: {
+		defaultModelKey:    {InputPerMillion: 0.10, OutputPerMillion: 0.40},
+		"gemini-2.0-flash": {InputPerMillion: 0.10, OutputPerMillion: 0.40},
+		"gemini-1.5-flash": {InputPerMillion: 0.075, OutputPerMillion: 0.30},
+		"gemini-1.5-pro":   {InputPerMillion: 1.25, OutputPerMillion: 5.00},
+	},
+	types.ProviderGrok: {
+		defaultModelKey:         {InputPerMillion: 3.00, OutputPerMillion: 15.00},
+		"grok-3-mini-fast-beta": {InputPerMillion: 0.60, OutputPerMillion: 4.00},
+		"grok-3-mini":           {InputPerMillion: 0.30, OutputPerMillion: 0.50},
+		"grok-3":                {InputPerMillion: 3.00, OutputPerMillion: 15.00},
+		"grok-4":                {InputPerMillion: 3.00, OutputPerMillion: 15.00},
+		"grok-code-fast-1":      {InputPerMillion: 0.20, OutputPerMillion: 1.50},
+	},
+	types.ProviderGroq: {
+		defaultModelKey:           {InputPerMillion: 0.59, OutputPerMillion: 0.79},
+		"llama-3.3-70b-versatile": {InputPerMillion: 0.59, OutputPerMillion: 0.79},
+		"llama-3.1-8b-instant":    {InputPerMillion: 0.05, OutputPerMillion: 0.08},
+	},
+	types.ProviderOllama: {
+		defaultModelKey: {},
+	},
+	// Dedicated endpoints bill by the hour and serverless usage by compute
+	// time, so there is no per-token price to estimate.
+	types.ProviderHuggingFace: {
+		defaultModelKey: {},
+	},
+	types.ProviderVertex: {
+		defaultModelKey:    {InputPerMillion: 0.15, OutputPerMillion: 0.60},
+		"gemini-2.0-flash": {InputPerMillion: 0.15, OutputPerMillion: 0.60},
+		"gemini-2.5-flash": {InputPerMillion: 0.30, OutputPerMillion: 2.50},
+		"gemini-2.5-pro":   {InputPerMillion: 1.25, OutputPerMillion: 10.00},
+	},
+	types.ProviderCohere: {
+		defaultModelKey:          {InputPerMillion: 0.15, OutputPerMillion: 0.60},
+		"command-r-08-2024":      {InputPerMillion: 0.15, OutputPerMillion: 0.60},
+		"command-r7b-12-2024":    {InputPerMillion: 0.0375, OutputPerMillion: 0.15},
+		"command-r-plus-08-2024": {InputPerMillion: 2.50, OutputPerMillion: 10.00},
+		"command-a-03-2025":      {InputPerMillion: 2.50, OutputPerMillion: 10.00},
+	},
+	types.ProviderTogether: {
+		defaultModelKey: {InputPerMillion: 0.88, OutputPerMillion: 0.88},
+		"meta-llama/Llama-3.3-70B-Instruct-Turbo":     {InputPerMillion: 0.88, OutputPerMillion: 0.88},
+		"Qwen/Qwen2.5-Coder-32B-Instruct":             {InputPerMillion: 0.80, OutputPerMillion: 0.80},
+		"deepseek-ai/DeepSeek-V3":                     {InputPerMillion: 1.25, OutputPerMillion: 1.25},
+		"meta-llama/Meta-Llama-3.1-8B-Instruct-Turbo": {InputPerMillion: 0.18, OutputPerMillion: 0.18},
+	},
+	types.ProviderFireworks: {
+		defaultModelKey: {InputPerMillion: 0.90, OutputPerMillion: 0.90},
+		"accounts/fireworks/models/llama-v3p3-70b-instruct":    {InputPerMillion: 0.90, OutputPerMillion: 0.90},
+		"accounts/fireworks/models/qwen2p5-coder-32b-instruct": {InputPerMillion: 0.90, OutputPerMillion: 0.90},
+		"accounts/fireworks/models/deepseek-v3":                {InputPerMillion: 0.90, OutputPerMillion: 0.90},
+		"accounts/fireworks/models/llama-v3p1-8b-instruct":     {InputPerMillion: 0.20, OutputPerMillion: 0.20},
+	},
+}
+
+// Default returns a copy of the built-in price table.
+func Default() Table {
+	return defaultTable.clone()
+}
+
+// Load returns the built-in price table merged with the user's override file,
+// if one exists next to the config file.
+func Load() (Table, error) {
+	path, err := OverridePath()
+	if err != nil {
+		return Default(), err
+	}
+	return LoadFile(path)
+}
+
+// LoadFile returns the built-in price table merged with overrides read from
+// path. A missing file is not an error.
+func LoadFile(path string) (Table, error) {
+	table := Default()
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return table, nil
+	}
+	if err != nil {
+		return table, fmt.Errorf("failed to read pricing overrides: %w", err)
+	}
+
+	var overrides Table
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return table, fmt.Errorf("failed to parse pricing overrides %s: %w", path, err)
+	}
+
+	table.merge(overrides)
+	return table, nil
+}
+
+// OverridePath returns the location of the user's pricing override file.
+func OverridePath() (string, error) {
+	configPath, err := StoreUtils.GetConfigPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(configPath), OverrideFileName), nil
+}
+
+// Lookup returns the price for the given provider and model, falling back to
+// the provider's default entry when the model is unknown.
+func (t Table) Lookup(provider types.LLMProvider, model string) (Price, bool) {
+	models, ok := t[provider]
+	if !ok {
+		return Price{}, false
+	}
+	if price, ok := models[strings.TrimSpace(model)]; ok {
+		return price, true
+	}
+	price, ok := models[defaultModelKey]
+	return price, ok
+}
+
+// Estimate calculates the USD cost of a request with the given token counts.
+func (t Table) Estimate(provider types.LLMProvider, model string, inputTokens, outputTokens int) float64 {
+	price, ok := t.Lookup(provider, model)
+	if !ok {
+		return 0.0
+	}
+	return float64(inputTokens)*price.InputPerMillion/1000000 + float64(outputTokens)*price.OutputPerMillion/1000000
+}
+
+func (t Table) clone() Table {
+	out := make(Table, len(t))
+	for provider, models := range t {
+		copied := make(map[string]Price, len(models))
+		for model, price := range models {
+			copied[model] = price
+		}
+		out[provider] = copied
+	}
+	return out
+}
+
+func (t Table) merge(overrides Table) {
+	for provider, models := range overrides {
+		if _, ok := t[provider]; !ok {
+			t[provider] = make(map[string]Price, len(models))
+		}
+		for model, price := range models {
+			t[provider][model] = price
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package pricing
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dfanso/commit-msg/pkg/types"
+)
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestLookupUsesModelSpecificPrice(t *testing.T) {
+	t.Parallel()
+
+	table := Default()
+
+	mini, ok := table.Lookup(types.ProviderOpenAI, "gpt-4o-mini")
+	if !ok {
+		t.Fatal("expected price for gpt-4o-mini")
+	}
+	full, ok := table.Lookup(types.ProviderOpenAI, "gpt-4o")
+	if !ok {
+		t.Fatal("expected price for gpt-4o")
+	}
+	if mini == full {
+		t.Fatalf("expected different prices per model, got %+v for both", mini)
+	}
+}
+
+func TestLookupFallsBackToProviderDefault(t *testing.T) {
+	t.Parallel()
+
+	table := Default()
+
+	price, ok := table.Lookup(types.ProviderClaude, "some-future-model")
+	if !ok {
+		t.Fatal("expected fallback price for unknown Claude model")
+	}
+	if price != table[types.ProviderClaude][defaultModelKey] {
+		t.Fatalf("expected provider default price, got %+v", price)
+	}
+
+	if _, ok := table.Lookup(types.LLMProvider("unknown"), "x"); ok {
+		t.Fatal("expected no price for unknown provider")
+	}
+}
+
+func TestEstimate(t *testing.T) {
+	t.Parallel()
+
+	table := Table{
+		types.ProviderOpenAI: {"m": {InputPerMillion: 1, OutputPerMillion: 2}},
+	}
+
+	got := table.Estimate(types.ProviderOpenAI, "m", 1000000, 500000)
+	if !almostEqual(got, 2.0) {
+		t.Fatalf("Estimate() = %f, want 2.0", got)
+	}
+
+	if got := Default().Estimate(types.ProviderOllama, "llama3.1", 1000, 1000); got != 0 {
+		t.Fatalf("expected Ollama to be free, got %f", got)
+	}
+}
+
+func TestLoadFileMergesOverrides(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), OverrideFileName)
+	overrides := `{"OpenAI": {"gpt-4o": {"input_per_million": 1.0, "output_per_million": 4.0}, "gpt-5": {"input_per_million": 5.0, "output_per_million": 20.0}}}`
+	if err := os.WriteFile(path, []byte(overrides), 0600); err != nil {
+		t.Fatalf("failed to write overrides: %v", err)
+	}
+
+	table, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile returned error: %v", err)
+	}
+
+	if price, _ := table.Lookup(types.ProviderOpenAI, "gpt-4o"); price.InputPerMillion != 1.0 {
+		t.Fatalf("expected overridden gpt-4o price, got %+v", price)
+	}
+	if _, ok := table[types.ProviderOpenAI]["gpt-5"]; !ok {
+		t.Fatal("expected new model from overrides to be added")
+	}
+	if _, ok := table[types.ProviderOpenAI]["gpt-4o-mini"]; !ok {
+		t.Fatal("expected built-in models to be kept")
+	}
+
+	// Overrides must not leak into the built-in table.
+	if price, _ := Default().Lookup(types.ProviderOpenAI, "gpt-4o"); price.InputPerMillion == 1.0 {
+		t.Fatal("expected built-in table to be unchanged")
+	}
+}
+
+func TestLoadFileMissingAndInvalid(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	if _, err := LoadFile(filepath.Join(dir, "missing.json")); err != nil {
+		t.Fatalf("expected no error for missing file, got %v", err)
+	}
+
+	invalid := filepath.Join(dir, OverrideFileName)
+	if err := os.WriteFile(invalid, []byte("{not json"), 0600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	table, err := LoadFile(invalid)
+	if err == nil {
+		t.Fatal("expected error for invalid overrides")
+	}
+	if len(table) == 0 {
+		t.Fatal("expected built-in table to be returned alongside the error")
+	}
+}
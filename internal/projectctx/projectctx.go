@@ -0,0 +1,83 @@
+// Package projectctx reads the opening lines of configured project files,
+// such as a README or ARCHITECTURE.md, so the LLM learns the project's
+// domain language and can name what the changes touch.
+package projectctx
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultMaxLines is how many lines of each file are read when max_lines is
+// not set.
+const DefaultMaxLines = 20
+
+// maxLineLength cuts lines longer than this, such as minified badges.
+const maxLineLength = 300
+
+// Settings is the "project_context" section of config.json.
+type Settings struct {
+	// Files lists the files to include, relative to the repository root.
+	// Files that do not exist in a repository are skipped.
+	Files []string `json:"files"`
+	// MaxLines is how many lines of each file are included; zero means
+	// DefaultMaxLines.
+	MaxLines int `json:"max_lines"`
+}
+
+// Read returns the opening lines of each of settings.Files found under
+// root, each headed by its path, or "" when none are found. Leading blank
+// lines are skipped and trailing ones dropped.
+func Read(root string, settings Settings) string {
+	maxLines := settings.MaxLines
+	if maxLines <= 0 {
+		maxLines = DefaultMaxLines
+	}
+
+	var sections []string
+	for _, name := range settings.Files {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		path := name
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(root, name)
+		}
+		if lines := readLines(path, maxLines); len(lines) > 0 {
+			sections = append(sections, "From "+filepath.ToSlash(name)+":\n"+strings.Join(lines, "\n"))
+		}
+	}
+	return strings.Join(sections, "\n\n")
+}
+
+// readLines returns up to n lines of the file at path, starting at its
+// first non-blank line.
+func readLines(path string, n int) []string {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() && len(lines) < n {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if len(lines) == 0 && line == "" {
+			continue
+		}
+		if utf8.RuneCountInString(line) > maxLineLength {
+			line = string([]rune(line)[:maxLineLength]) + "..."
+		}
+		lines = append(lines, line)
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
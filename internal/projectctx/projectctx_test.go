@@ -0,0 +1,58 @@
+package projectctx
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRead(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		full := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	write("README.md", "\n\n# Ledger\n\nLedger reconciles invoices against payouts.\n\n## Install\n")
+	write("docs/ARCHITECTURE.md", "Payouts flow from the settlement worker.\n"+strings.Repeat("x", 400)+"\n")
+
+	tests := map[string]struct {
+		settings Settings
+		want     string
+	}{
+		"first lines of each file": {
+			settings: Settings{Files: []string{"README.md", "missing.md", "docs/ARCHITECTURE.md"}, MaxLines: 3},
+			want: "From README.md:\n# Ledger\n\nLedger reconciles invoices against payouts.\n\n" +
+				"From docs/ARCHITECTURE.md:\nPayouts flow from the settlement worker.\n" + strings.Repeat("x", maxLineLength) + "...",
+		},
+		"trailing blank lines dropped": {
+			settings: Settings{Files: []string{"README.md"}, MaxLines: 2},
+			want:     "From README.md:\n# Ledger",
+		},
+		"default line count": {
+			settings: Settings{Files: []string{"README.md"}},
+			want:     "From README.md:\n# Ledger\n\nLedger reconciles invoices against payouts.\n\n## Install",
+		},
+		"nothing found": {
+			settings: Settings{Files: []string{"missing.md", " "}},
+			want:     "",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			if got := Read(root, tt.settings); got != tt.want {
+				t.Fatalf("Read() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
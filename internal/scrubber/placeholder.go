@@ -0,0 +1,135 @@
+package scrubber
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Tokens a placeholder may contain.
+const (
+	// KindToken expands to the kind of data redacted, such as API_KEY.
+	KindToken = "{{kind}}"
+	// HashToken expands to the first hashLength hex digits of the SHA-256
+	// of the redacted value, so repeated values redact identically.
+	HashToken = "{{hash}}"
+)
+
+// DefaultPlaceholder is the placeholder used when none is configured.
+const DefaultPlaceholder = "[REDACTED_" + KindToken + "]"
+
+// hashLength is short on purpose: enough to tell a handful of values in one
+// diff apart, too little to confirm a guessed value.
+const hashLength = 4
+
+// Settings are read from the "redaction" section of config.json.
+type Settings struct {
+	// Placeholder replaces each redacted value, such as "«secret»" or
+	// "[SECRET:{{hash}}]". Empty means DefaultPlaceholder.
+	Placeholder string `json:"placeholder"`
+}
+
+// Validate reports whether the placeholder can be used. It must contain a
+// character other than a letter, digit, '_', '-', or '.' so it is never
+// mistaken for a secret itself.
+func (s Settings) Validate() error {
+	if s.Placeholder == "" {
+		return nil
+	}
+	rendered := strings.NewReplacer(KindToken, "", HashToken, "").Replace(s.Placeholder)
+	if !strings.ContainsFunc(rendered, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-' || r == '.')
+	}) {
+		return fmt.Errorf("placeholder %q must contain punctuation such as brackets so it cannot look like a secret", s.Placeholder)
+	}
+	return nil
+}
+
+// placeholderStyle renders and recognizes one placeholder.
+type placeholderStyle struct {
+	template string
+	// marker matches rendered placeholders; its first group is the kind when
+	// the template contains KindToken.
+	marker   *regexp.Regexp
+	hasKind  bool
+	isCustom bool
+}
+
+var (
+	styleMu sync.RWMutex
+	style   = newPlaceholderStyle(DefaultPlaceholder)
+)
+
+// Configure sets the placeholder used by every subsequent scrub. Invalid
+// settings keep the default.
+func Configure(s Settings) {
+	template := s.Placeholder
+	if template == "" || s.Validate() != nil {
+		template = DefaultPlaceholder
+	}
+	styleMu.Lock()
+	defer styleMu.Unlock()
+	style = newPlaceholderStyle(template)
+}
+
+func currentStyle() placeholderStyle {
+	styleMu.RLock()
+	defer styleMu.RUnlock()
+	return style
+}
+
+func newPlaceholderStyle(template string) placeholderStyle {
+	var pattern strings.Builder
+	hasKind := false
+	for rest := template; rest != ""; {
+		kind := strings.Index(rest, KindToken)
+		hash := strings.Index(rest, HashToken)
+		switch {
+		case kind >= 0 && (hash < 0 || kind < hash):
+			pattern.WriteString(regexp.QuoteMeta(rest[:kind]))
+			if hasKind {
+				pattern.WriteString(`[A-Z0-9_]+`)
+			} else {
+				pattern.WriteString(`([A-Z0-9_]+)`)
+			}
+			hasKind = true
+			rest = rest[kind+len(KindToken):]
+		case hash >= 0:
+			pattern.WriteString(regexp.QuoteMeta(rest[:hash]))
+			pattern.WriteString(fmt.Sprintf(`[0-9a-f]{%d}`, hashLength))
+			rest = rest[hash+len(HashToken):]
+		default:
+			pattern.WriteString(regexp.QuoteMeta(rest))
+			rest = ""
+		}
+	}
+	return placeholderStyle{
+		template: template,
+		marker:   regexp.MustCompile(pattern.String()),
+		hasKind:  hasKind,
+		isCustom: template != DefaultPlaceholder,
+	}
+}
+
+// render returns the placeholder for a value of the given kind.
+func (s placeholderStyle) render(kind, value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return strings.NewReplacer(
+		KindToken, kind,
+		HashToken, hex.EncodeToString(sum[:])[:hashLength],
+	).Replace(s.template)
+}
+
+// restyle swaps the default placeholder in replacement, a pattern's
+// expanded Redact template, for this style's placeholder of value.
+func (s placeholderStyle) restyle(replacement, value string) string {
+	if !s.isCustom {
+		return replacement
+	}
+	return redactionMarker.ReplaceAllStringFunc(replacement, func(marker string) string {
+		return s.render(redactionMarker.FindStringSubmatch(marker)[1], value)
+	})
+}
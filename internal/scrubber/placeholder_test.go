@@ -0,0 +1,82 @@
+package scrubber
+
+import (
+	"strings"
+	"testing"
+)
+
+// Tests that call Configure change package state, so they do not run in
+// parallel and restore the default when done.
+
+func TestConfigurePlaceholder(t *testing.T) {
+	t.Cleanup(func() { Configure(Settings{}) })
+	Configure(Settings{Placeholder: "«secret»"})
+
+	scrubbed := ScrubDiff(`OPENAI_API_KEY=sk-abcdefghijklmnop` + "\n" + `password="supersecretvalue1"`)
+	if strings.Contains(scrubbed, "sk-abcdefghijklmnop") || strings.Contains(scrubbed, "supersecretvalue1") {
+		t.Fatalf("ScrubDiff() left a secret: %q", scrubbed)
+	}
+	if strings.Count(scrubbed, "«secret»") != 2 || strings.Contains(scrubbed, "REDACTED") {
+		t.Fatalf("ScrubDiff() = %q, want two «secret» placeholders", scrubbed)
+	}
+	if findings := Findings(scrubbed); len(findings) != 1 || findings[0] != "SECRET" {
+		t.Fatalf("Findings() = %v, want [SECRET]", findings)
+	}
+	if detected := Unredacted(scrubbed); detected != nil {
+		t.Fatalf("Unredacted() = %v, want nil", detected)
+	}
+}
+
+func TestConfigureHashPlaceholder(t *testing.T) {
+	t.Cleanup(func() { Configure(Settings{}) })
+	Configure(Settings{Placeholder: "[SECRET:{{kind}}:{{hash}}]"})
+
+	scrubbed := ScrubDiff(strings.Join([]string{
+		"OPENAI_API_KEY=sk-aaaaaaaaaaaaaaaa",
+		`openai_api_key: "sk-aaaaaaaaaaaaaaaa"`,
+		"OPENAI_API_KEY=sk-bbbbbbbbbbbbbbbb",
+	}, "\n"))
+	placeholders := currentStyle().marker.FindAllString(scrubbed, -1)
+	if len(placeholders) != 3 {
+		t.Fatalf("ScrubDiff() = %q, want three placeholders", scrubbed)
+	}
+	if placeholders[0] != placeholders[1] {
+		t.Fatalf("the same value redacted as %q and %q", placeholders[0], placeholders[1])
+	}
+	if placeholders[0] == placeholders[2] {
+		t.Fatalf("different values both redacted as %q", placeholders[0])
+	}
+	if !strings.HasPrefix(placeholders[0], "[SECRET:OPENAI_KEY:") {
+		t.Fatalf("placeholder %q does not name the kind", placeholders[0])
+	}
+	if findings := Findings(scrubbed); len(findings) != 1 || findings[0] != "OPENAI_KEY" {
+		t.Fatalf("Findings() = %v, want [OPENAI_KEY]", findings)
+	}
+	if again := ScrubDiff(scrubbed); again != scrubbed {
+		t.Fatalf("scrubbing twice changed the placeholders:\n%s\n%s", scrubbed, again)
+	}
+}
+
+func TestConfigureIgnoresInvalidPlaceholder(t *testing.T) {
+	t.Cleanup(func() { Configure(Settings{}) })
+	Configure(Settings{Placeholder: "REDACTED{{hash}}"})
+
+	if got := ScrubDiff("OPENAI_API_KEY=sk-abcdefghijklmnop"); !strings.Contains(got, "[REDACTED_OPENAI_KEY]") {
+		t.Fatalf("ScrubDiff() = %q, want the default placeholder", got)
+	}
+}
+
+func TestSettingsValidate(t *testing.T) {
+	t.Parallel()
+
+	for _, placeholder := range []string{"", "«secret»", "[SECRET:{{hash}}]", "<{{kind}}>"} {
+		if err := (Settings{Placeholder: placeholder}).Validate(); err != nil {
+			t.Errorf("Validate(%q) error = %v", placeholder, err)
+		}
+	}
+	for _, placeholder := range []string{"REDACTED", "SECRET_{{kind}}_{{hash}}"} {
+		if err := (Settings{Placeholder: placeholder}).Validate(); err == nil {
+			t.Errorf("Validate(%q) succeeded, want an error", placeholder)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package scrubber
 
 import (
 	"regexp"
+	"strconv"
 	"strings"
 )
 
@@ -329,25 +330,78 @@ var (
 // ScrubDiff removes sensitive information from git diff output
 func ScrubDiff(diff string) string {
 	scrubbed := diff
+	style := currentStyle()
 
 	// Apply each pattern
-	for _, pattern := range sensitivePatterns {
-		scrubbed = pattern.Pattern.ReplaceAllString(scrubbed, pattern.Redact)
+	for i := range sensitivePatterns {
+		scrubbed = redact(scrubbed, i, style)
 	}
 
 	return scrubbed
 }
 
+// redact replaces the matches of sensitivePatterns[i] in content with the
+// pattern's Redact template, using style's placeholder. Matches whose value
+// is already a placeholder are left alone, so a value caught by two
+// patterns keeps its first placeholder.
+func redact(content string, i int, style placeholderStyle) string {
+	pattern := sensitivePatterns[i]
+	locs := pattern.Pattern.FindAllStringSubmatchIndex(content, -1)
+	if locs == nil {
+		return content
+	}
+
+	var scrubbed strings.Builder
+	last := 0
+	for _, loc := range locs {
+		scrubbed.WriteString(content[last:loc[0]])
+		last = loc[1]
+		value := secretValue(content, loc, secretGroups[i])
+		if style.marker.MatchString(value) {
+			scrubbed.WriteString(content[loc[0]:loc[1]])
+			continue
+		}
+		replacement := string(pattern.Pattern.ExpandString(nil, pattern.Redact, content, loc))
+		scrubbed.WriteString(style.restyle(replacement, value))
+	}
+	scrubbed.WriteString(content[last:])
+	return scrubbed.String()
+}
+
+// secretValue returns the text of group in the match at loc, or "" when the
+// group did not take part in the match.
+func secretValue(content string, loc []int, group int) string {
+	start, end := loc[2*group], loc[2*group+1]
+	if start < 0 {
+		return ""
+	}
+	return content[start:end]
+}
+
+// ScrubAll returns a copy of values with ScrubDiff applied to each, for lists
+// such as file names and example messages.
+func ScrubAll(values []string) []string {
+	if values == nil {
+		return nil
+	}
+	scrubbed := make([]string, len(values))
+	for i, value := range values {
+		scrubbed[i] = ScrubDiff(value)
+	}
+	return scrubbed
+}
+
 // ScrubLines removes sensitive information line by line
 // This is useful for more granular control
 func ScrubLines(content string) string {
 	lines := strings.Split(content, "\n")
 	scrubbedLines := make([]string, len(lines))
 
+	style := currentStyle()
 	for i, line := range lines {
 		scrubbedLine := line
-		for _, pattern := range sensitivePatterns {
-			scrubbedLine = pattern.Pattern.ReplaceAllString(scrubbedLine, pattern.Redact)
+		for j := range sensitivePatterns {
+			scrubbedLine = redact(scrubbedLine, j, style)
 		}
 		scrubbedLines[i] = scrubbedLine
 	}
@@ -376,6 +430,74 @@ func GetDetectedPatterns(content string) []string {
 	return detected
 }
 
+// redactionMarker matches DefaultPlaceholder, which every Redact template
+// uses; configured placeholders are matched by placeholderStyle.marker.
+var redactionMarker = regexp.MustCompile(`\[REDACTED_([A-Z0-9_]+)\]`)
+
+// groupReference matches the ${N} references in a Redact template.
+var groupReference = regexp.MustCompile(`\$\{(\d+)\}`)
+
+// secretGroups holds, for each of sensitivePatterns, the capture group
+// holding the secret: the last group its Redact template drops, or 0 for the
+// whole match when the template keeps every group or none.
+var secretGroups = func() []int {
+	groups := make([]int, len(sensitivePatterns))
+	for i, pattern := range sensitivePatterns {
+		kept := make(map[string]bool)
+		for _, ref := range groupReference.FindAllStringSubmatch(pattern.Redact, -1) {
+			kept[ref[1]] = true
+		}
+		if len(kept) == 0 {
+			continue
+		}
+		for group := pattern.Pattern.NumSubexp(); group > 0; group-- {
+			if !kept[strconv.Itoa(group)] {
+				groups[i] = group
+				break
+			}
+		}
+	}
+	return groups
+}()
+
+// Unredacted returns the names of the patterns that match content with a
+// value that is not already a redaction placeholder. Unlike
+// GetDetectedPatterns it reports nothing for scrubbed content, whose
+// placeholders can themselves match, as in password="[REDACTED_PASSWORD]".
+func Unredacted(content string) []string {
+	style := currentStyle()
+	var detected []string
+	for i, pattern := range sensitivePatterns {
+		for _, loc := range pattern.Pattern.FindAllStringSubmatchIndex(content, -1) {
+			if !style.marker.MatchString(secretValue(content, loc, secretGroups[i])) {
+				detected = append(detected, pattern.Name)
+				break
+			}
+		}
+	}
+	return detected
+}
+
+// Findings lists, in order of first appearance, the kinds of data redacted
+// from already-scrubbed content, such as "OPENAI_KEY". A configured
+// placeholder without {{kind}} is reported as the single kind "SECRET".
+func Findings(scrubbed string) []string {
+	style := currentStyle()
+	var findings []string
+	seen := make(map[string]bool)
+	for _, match := range style.marker.FindAllStringSubmatch(scrubbed, -1) {
+		kind := "SECRET"
+		if style.hasKind {
+			kind = match[1]
+		}
+		if !seen[kind] {
+			seen[kind] = true
+			findings = append(findings, kind)
+		}
+	}
+	return findings
+}
+
 // ScrubEnvFile specifically handles .env file patterns
 func ScrubEnvFile(content string) string {
 	lines := strings.Split(content, "\n")
@@ -404,7 +526,7 @@ func ScrubEnvFile(content string) string {
 					strings.Contains(upperKey, "PASS") ||
 					strings.Contains(upperKey, "API") ||
 					strings.Contains(upperKey, "AUTH") {
-					scrubbedLines[i] = key + "=[REDACTED]"
+					scrubbedLines[i] = key + "=" + envPlaceholder(parts[1])
 					continue
 				}
 			}
@@ -416,3 +538,13 @@ func ScrubEnvFile(content string) string {
 
 	return strings.Join(scrubbedLines, "\n")
 }
+
+// envPlaceholder returns the placeholder for a .env value. The default
+// style keeps the historical bare "[REDACTED]".
+func envPlaceholder(value string) string {
+	style := currentStyle()
+	if !style.isCustom {
+		return "[REDACTED]"
+	}
+	return style.render("ENV_VALUE", value)
+}
@@ -0,0 +1,253 @@
+// Package spellcheck corrects common misspellings and the casing of project
+// terms such as "PostgreSQL" in generated commit messages. Code spans,
+// identifiers, paths, and URLs are left untouched.
+package spellcheck
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// TermsFile is the per-repository terminology file read by LoadTerms, kept
+// at the repository root.
+const TermsFile = ".commit-terms"
+
+// Settings is the "spellcheck" section of config.json.
+type Settings struct {
+	// Enabled turns on the misspelling dictionary. Terms are applied either
+	// way.
+	Enabled bool `json:"enabled"`
+	// Terms lists words and names whose spelling and casing are enforced,
+	// such as "PostgreSQL" or a product name.
+	Terms []string `json:"terms"`
+}
+
+// DefaultTerms are product names commonly written with the wrong casing.
+var DefaultTerms = []string{
+	"GitHub", "GitLab", "JavaScript", "TypeScript", "PostgreSQL", "MySQL",
+	"SQLite", "MongoDB", "macOS", "OAuth", "WebSocket", "GraphQL",
+}
+
+//go:embed misspellings.txt
+var misspellingsFile string
+
+// Correction records a replacement made by Correct.
+type Correction struct {
+	From string
+	To   string
+}
+
+func (c Correction) String() string {
+	return fmt.Sprintf("%q -> %q", c.From, c.To)
+}
+
+// Checker corrects messages against a dictionary and a list of terms.
+type Checker struct {
+	misspellings map[string]string
+	terms        []*regexp.Regexp
+	termValues   []string
+}
+
+// New returns a Checker enforcing DefaultTerms and terms. The misspelling
+// dictionary is used when dictionary is true.
+func New(dictionary bool, terms []string) *Checker {
+	c := &Checker{}
+	if dictionary {
+		c.misspellings = parseMisspellings(misspellingsFile)
+	}
+
+	seen := map[string]bool{}
+	all := append(append([]string{}, DefaultTerms...), terms...)
+	// Longer terms first, so "Acme Cloud Storage" wins over "Acme Cloud".
+	sort.SliceStable(all, func(i, j int) bool { return len(all[i]) > len(all[j]) })
+	for _, term := range all {
+		term = strings.TrimSpace(term)
+		key := strings.ToLower(term)
+		if term == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		pattern := `(?i)\b` + strings.Join(strings.Fields(regexp.QuoteMeta(term)), `\s+`) + `\b`
+		c.terms = append(c.terms, regexp.MustCompile(pattern))
+		c.termValues = append(c.termValues, term)
+	}
+	return c
+}
+
+func parseMisspellings(data string) map[string]string {
+	words := map[string]string{}
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if wrong, right, ok := strings.Cut(line, "->"); ok {
+			words[strings.ToLower(strings.TrimSpace(wrong))] = strings.TrimSpace(right)
+		}
+	}
+	return words
+}
+
+// LoadTerms reads a terminology file: one term per line, with blank lines
+// and lines starting with "#" ignored. A missing file yields no terms.
+func LoadTerms(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var terms []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		terms = append(terms, line)
+	}
+	return terms, scanner.Err()
+}
+
+var (
+	// conventionalPrefix matches "type(scope)!: " at the start of a subject.
+	conventionalPrefix = regexp.MustCompile(`^[A-Za-z]+(\([^)]*\))?!?:\s*`)
+	// codeSpan matches inline code, which is never corrected.
+	codeSpan = regexp.MustCompile("`[^`\n]*`")
+	// word matches a run of letters with inner apostrophes.
+	word = regexp.MustCompile(`[A-Za-z]+(?:'[A-Za-z]+)*`)
+)
+
+// Correct returns message with misspellings and term casing fixed, and the
+// corrections made. The conventional commit prefix, inline code, fenced
+// blocks, and words that are part of identifiers, paths, or URLs are kept.
+func (c *Checker) Correct(message string) (string, []Correction) {
+	var corrections []Correction
+	lines := strings.Split(message, "\n")
+	inFence := false
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+			continue
+		}
+		if inFence || strings.HasPrefix(line, "    ") || strings.HasPrefix(line, "\t") {
+			continue
+		}
+
+		prefix := ""
+		if i == 0 {
+			prefix = conventionalPrefix.FindString(line)
+		}
+		lines[i] = prefix + c.correctText(line[len(prefix):], &corrections)
+	}
+	return strings.Join(lines, "\n"), corrections
+}
+
+// correctText corrects the prose of a single line, outside inline code.
+func (c *Checker) correctText(text string, corrections *[]Correction) string {
+	var b strings.Builder
+	last := 0
+	for _, span := range codeSpan.FindAllStringIndex(text, -1) {
+		b.WriteString(c.correctProse(text[last:span[0]], corrections))
+		b.WriteString(text[span[0]:span[1]])
+		last = span[1]
+	}
+	b.WriteString(c.correctProse(text[last:], corrections))
+	return b.String()
+}
+
+func (c *Checker) correctProse(text string, corrections *[]Correction) string {
+	for i, pattern := range c.terms {
+		term := c.termValues[i]
+		text = replaceWords(text, pattern, func(match string) string {
+			if match != term {
+				*corrections = append(*corrections, Correction{From: match, To: term})
+			}
+			return term
+		})
+	}
+	if len(c.misspellings) == 0 {
+		return text
+	}
+	return replaceWords(text, word, func(match string) string {
+		right, ok := c.misspellings[strings.ToLower(match)]
+		if !ok {
+			return match
+		}
+		right = matchCase(match, right)
+		*corrections = append(*corrections, Correction{From: match, To: right})
+		return right
+	})
+}
+
+// replaceWords replaces the matches of pattern in text that stand alone as
+// words, leaving those that are part of an identifier, path, or URL.
+func replaceWords(text string, pattern *regexp.Regexp, replace func(string) string) string {
+	var b strings.Builder
+	last := 0
+	for _, loc := range pattern.FindAllStringIndex(text, -1) {
+		b.WriteString(text[last:loc[0]])
+		match := text[loc[0]:loc[1]]
+		if partOfToken(text, loc[0], loc[1]) {
+			b.WriteString(match)
+		} else {
+			b.WriteString(replace(match))
+		}
+		last = loc[1]
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// partOfToken reports whether text[start:end] is joined to the characters
+// around it, as in "github.com", "my_var", "src/teh", or "CamelCase".
+func partOfToken(text string, start, end int) bool {
+	before, _ := utf8.DecodeLastRuneInString(text[:start])
+	after, size := utf8.DecodeRuneInString(text[end:])
+	if start > 0 && (isJoiner(before) || unicode.IsLetter(before) || unicode.IsDigit(before)) {
+		return true
+	}
+	if end < len(text) {
+		if isJoiner(after) || unicode.IsLetter(after) || unicode.IsDigit(after) {
+			return true
+		}
+		// A period or hyphen ends a sentence or clause unless another
+		// word character follows, as in "example.com" or "go-json".
+		if after == '.' || after == '-' {
+			next, _ := utf8.DecodeRuneInString(text[end+size:])
+			return end+size < len(text) && (unicode.IsLetter(next) || unicode.IsDigit(next))
+		}
+	}
+	if before == '.' || before == '-' {
+		prev, _ := utf8.DecodeLastRuneInString(text[:start-1])
+		return start > 1 && (unicode.IsLetter(prev) || unicode.IsDigit(prev))
+	}
+	return false
+}
+
+func isJoiner(r rune) bool {
+	return r == '_' || r == '/' || r == '\\' || r == '@' || r == '$' || r == '#' || r == '='
+}
+
+// matchCase gives replacement the capitalization of original: all upper
+// case, a leading capital, or as written.
+func matchCase(original, replacement string) string {
+	switch {
+	case original == strings.ToUpper(original) && utf8.RuneCountInString(original) > 1:
+		return strings.ToUpper(replacement)
+	case unicode.IsUpper([]rune(original)[0]):
+		r, size := utf8.DecodeRuneInString(replacement)
+		return string(unicode.ToUpper(r)) + replacement[size:]
+	}
+	return replacement
+}
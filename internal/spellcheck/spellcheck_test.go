@@ -0,0 +1,75 @@
+package spellcheck
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCorrect(t *testing.T) {
+	t.Parallel()
+
+	c := New(true, []string{"Acme Cloud", "gRPC"})
+	tests := []struct {
+		name    string
+		message string
+		want    string
+	}{
+		{name: "misspelling", message: "fix: recieve the seperate config", want: "fix: receive the separate config"},
+		{name: "case preserved", message: "Recieve updates\n\nTEH END", want: "Receive updates\n\nTHE END"},
+		{name: "default term", message: "feat: migrate to postgresql", want: "feat: migrate to PostgreSQL"},
+		{name: "custom terms", message: "docs: describe acme  cloud and GRPC setup", want: "docs: describe Acme Cloud and gRPC setup"},
+		{name: "scope kept", message: "fix(github): update github action.", want: "fix(github): update GitHub action."},
+		{name: "inline code kept", message: "fix: rename `teh` to the", want: "fix: rename `teh` to the"},
+		{name: "identifiers kept", message: "fix: read github.com/teh_pkg and mysql_conn", want: "fix: read github.com/teh_pkg and mysql_conn"},
+		{name: "fenced block kept", message: "fix: x\n\n```\nrecieve()\n```\nrecieve", want: "fix: x\n\n```\nrecieve()\n```\nreceive"},
+		{name: "correct text unchanged", message: "feat: add GitHub login", want: "feat: add GitHub login"},
+	}
+
+	for _, tt := range tests {
+		if got, _ := c.Correct(tt.message); got != tt.want {
+			t.Errorf("%s: Correct(%q) = %q, want %q", tt.name, tt.message, got, tt.want)
+		}
+	}
+}
+
+func TestCorrectReportsCorrections(t *testing.T) {
+	t.Parallel()
+
+	_, corrections := New(true, nil).Correct("fix: occured in github")
+	want := []Correction{{From: "github", To: "GitHub"}, {From: "occured", To: "occurred"}}
+	if !reflect.DeepEqual(corrections, want) {
+		t.Fatalf("corrections = %v, want %v", corrections, want)
+	}
+}
+
+func TestCorrectWithoutDictionary(t *testing.T) {
+	t.Parallel()
+
+	got, _ := New(false, nil).Correct("fix: recieve from github")
+	if got != "fix: recieve from GitHub" {
+		t.Fatalf("Correct() = %q, want only the term fixed", got)
+	}
+}
+
+func TestLoadTerms(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), TermsFile)
+	if err := os.WriteFile(path, []byte("# product names\nAcme Cloud\n\n  gRPC  \n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	terms, err := LoadTerms(path)
+	if err != nil {
+		t.Fatalf("LoadTerms: %v", err)
+	}
+	if !reflect.DeepEqual(terms, []string{"Acme Cloud", "gRPC"}) {
+		t.Fatalf("terms = %q", terms)
+	}
+
+	terms, err = LoadTerms(filepath.Join(t.TempDir(), "missing"))
+	if err != nil || terms != nil {
+		t.Fatalf("expected no terms for a missing file, got %q, %v", terms, err)
+	}
+}
@@ -0,0 +1,6 @@
+//go:build sqlite
+
+package sqlstore
+
+// Register the SQLite driver under DriverName.
+import _ "modernc.org/sqlite"
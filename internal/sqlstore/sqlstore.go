@@ -0,0 +1,128 @@
+// Package sqlstore opens the optional SQLite database that can hold the
+// message cache and history in place of cache.json and history.json, which
+// are read and rewritten whole on every change and slow down once they hold
+// thousands of entries.
+//
+// The database is reached through database/sql. The driver,
+// modernc.org/sqlite, is pure Go and needs no cgo, but it is large, so it is
+// only linked into builds made with -tags sqlite; other builds report
+// ErrUnavailable when SQLite storage is asked for.
+package sqlstore
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	StoreUtils "github.com/dfanso/commit-msg/utils"
+)
+
+// FileName is the database stored alongside config.json.
+const FileName = "commit-msg.db"
+
+// DriverName is the database/sql driver modernc.org/sqlite registers.
+const DriverName = "sqlite"
+
+// Storage backends accepted in the "storage" section of config.json.
+const (
+	BackendJSON   = "json"
+	BackendSQLite = "sqlite"
+)
+
+// ErrUnavailable is returned by Open when the SQLite driver was not built in.
+var ErrUnavailable = errors.New("this build of commit has no SQLite support; rebuild it with -tags sqlite")
+
+// Settings chooses where the cache and history are kept. The JSON names are
+// the keys of the "storage" section in config.json.
+type Settings struct {
+	// Backend is "json" (the default) or "sqlite".
+	Backend string `json:"backend"`
+}
+
+// UseSQLite reports whether the settings ask for the SQLite backend.
+func (s Settings) UseSQLite() bool {
+	return strings.EqualFold(strings.TrimSpace(s.Backend), BackendSQLite)
+}
+
+// Validate reports an unknown backend.
+func (s Settings) Validate() error {
+	switch strings.ToLower(strings.TrimSpace(s.Backend)) {
+	case "", BackendJSON, BackendSQLite:
+		return nil
+	default:
+		return fmt.Errorf("unknown storage backend %q (want %q or %q)", s.Backend, BackendJSON, BackendSQLite)
+	}
+}
+
+// Available reports whether this build can open SQLite databases.
+func Available() bool {
+	return slices.Contains(sql.Drivers(), DriverName)
+}
+
+// DefaultPath returns the location of the database.
+func DefaultPath() (string, error) {
+	configPath, err := StoreUtils.GetConfigPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(configPath), FileName), nil
+}
+
+// schema creates the tables used by the cache and history. Cache entries
+// are kept as JSON so new fields need no migration.
+var schema = []string{
+	`CREATE TABLE IF NOT EXISTS cache_entries (
+		key TEXT PRIMARY KEY,
+		entry TEXT NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS cache_meta (
+		name TEXT PRIMARY KEY,
+		value TEXT NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS history (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		message TEXT NOT NULL,
+		repo TEXT NOT NULL DEFAULT '',
+		provider TEXT NOT NULL DEFAULT '',
+		status TEXT NOT NULL,
+		created_at TEXT NOT NULL
+	)`,
+}
+
+// Open opens the database at path, creating it and its tables as needed.
+func Open(path string) (*sql.DB, error) {
+	if !Available() {
+		return nil, ErrUnavailable
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create database directory: %w", err)
+	}
+
+	db, err := sql.Open(DriverName, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	// One connection keeps the pragmas below in effect for every statement
+	// and serializes writers within the process.
+	db.SetMaxOpenConns(1)
+
+	statements := append([]string{
+		`PRAGMA busy_timeout = 5000`,
+		`PRAGMA journal_mode = WAL`,
+	}, schema...)
+	for _, statement := range statements {
+		if _, err := db.Exec(statement); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to prepare %s: %w", path, err)
+		}
+	}
+	if err := os.Chmod(path, 0o600); err != nil && !os.IsNotExist(err) {
+		db.Close()
+		return nil, fmt.Errorf("failed to restrict %s: %w", path, err)
+	}
+	return db, nil
+}
@@ -0,0 +1,31 @@
+package sqlstore
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestSettings(t *testing.T) {
+	for _, backend := range []string{"", "json", "sqlite", "SQLite "} {
+		if err := (Settings{Backend: backend}).Validate(); err != nil {
+			t.Errorf("Validate(%q) = %v", backend, err)
+		}
+	}
+	if err := (Settings{Backend: "postgres"}).Validate(); err == nil {
+		t.Error("expected an unknown backend to be rejected")
+	}
+	if (Settings{}).UseSQLite() || !(Settings{Backend: "SQLite "}).UseSQLite() {
+		t.Error("UseSQLite must only be true for the sqlite backend")
+	}
+}
+
+func TestOpenWithoutDriver(t *testing.T) {
+	if Available() {
+		t.Skip("built with SQLite support")
+	}
+	_, err := Open(filepath.Join(t.TempDir(), FileName))
+	if !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("expected ErrUnavailable, got %v", err)
+	}
+}
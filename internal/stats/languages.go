@@ -0,0 +1,195 @@
+package stats
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/dfanso/commit-msg/internal/display"
+	"github.com/dfanso/commit-msg/internal/utils"
+)
+
+// languageByExtension maps lower-case file extensions to the language (or
+// category) shown in the breakdown.
+var languageByExtension = map[string]string{
+	".go":    "Go",
+	".ts":    "TypeScript",
+	".tsx":   "TypeScript",
+	".js":    "JavaScript",
+	".jsx":   "JavaScript",
+	".mjs":   "JavaScript",
+	".cjs":   "JavaScript",
+	".py":    "Python",
+	".rs":    "Rust",
+	".java":  "Java",
+	".kt":    "Kotlin",
+	".rb":    "Ruby",
+	".php":   "PHP",
+	".c":     "C",
+	".h":     "C",
+	".cc":    "C++",
+	".cpp":   "C++",
+	".hpp":   "C++",
+	".cs":    "C#",
+	".swift": "Swift",
+	".sh":    "Shell",
+	".bash":  "Shell",
+	".zsh":   "Shell",
+	".ps1":   "PowerShell",
+	".sql":   "SQL",
+	".html":  "HTML",
+	".css":   "CSS",
+	".scss":  "CSS",
+	".yaml":  "YAML",
+	".yml":   "YAML",
+	".json":  "JSON",
+	".toml":  "TOML",
+	".xml":   "XML",
+	".proto": "Protobuf",
+	".md":    "Docs",
+	".mdx":   "Docs",
+	".rst":   "Docs",
+	".txt":   "Docs",
+	".adoc":  "Docs",
+}
+
+// languageByName covers files recognised by name rather than extension.
+var languageByName = map[string]string{
+	"Dockerfile": "Docker",
+	"Makefile":   "Make",
+	"go.mod":     "Go",
+	"go.sum":     "Go",
+	"LICENSE":    "Docs",
+}
+
+// Language returns the language or category of file, or "Other".
+func Language(file string) string {
+	base := path.Base(filepath.ToSlash(strings.TrimSuffix(file, "}")))
+	if lang, ok := languageByName[base]; ok {
+		return lang
+	}
+	if lang, ok := languageByExtension[strings.ToLower(path.Ext(base))]; ok {
+		return lang
+	}
+	return "Other"
+}
+
+// languageTally accumulates per-language line counts, counting each file
+// once even when it has both staged and unstaged changes.
+type languageTally struct {
+	byName map[string]*display.LanguageStat
+	seen   map[string]bool
+}
+
+func newLanguageTally() *languageTally {
+	return &languageTally{
+		byName: make(map[string]*display.LanguageStat),
+		seen:   make(map[string]bool),
+	}
+}
+
+func (t *languageTally) add(file string, added, deleted int) {
+	name := Language(file)
+	stat, ok := t.byName[name]
+	if !ok {
+		stat = &display.LanguageStat{Name: name}
+		t.byName[name] = stat
+	}
+	if !t.seen[file] {
+		t.seen[file] = true
+		stat.Files++
+	}
+	stat.Added += added
+	stat.Deleted += deleted
+}
+
+// sorted returns the languages ordered by churn, largest first.
+func (t *languageTally) sorted() []display.LanguageStat {
+	languages := make([]display.LanguageStat, 0, len(t.byName))
+	for _, stat := range t.byName {
+		languages = append(languages, *stat)
+	}
+	sort.Slice(languages, func(i, j int) bool {
+		ci := languages[i].Added + languages[i].Deleted
+		cj := languages[j].Added + languages[j].Deleted
+		if ci != cj {
+			return ci > cj
+		}
+		if languages[i].Files != languages[j].Files {
+			return languages[i].Files > languages[j].Files
+		}
+		return languages[i].Name < languages[j].Name
+	})
+	return languages
+}
+
+// countFileLines returns the number of lines in a small text file, or 0 when
+// the file is binary, large, or unreadable.
+func countFileLines(fullPath string) int {
+	if !utils.IsTextFile(fullPath) || !utils.IsSmallFile(fullPath) {
+		return 0
+	}
+	content, err := os.ReadFile(fullPath)
+	if err != nil || len(content) == 0 {
+		return 0
+	}
+	lines := strings.Count(string(content), "\n")
+	if !strings.HasSuffix(string(content), "\n") {
+		lines++
+	}
+	return lines
+}
+
+// LanguagesFromDiff builds a language breakdown from a unified diff in git
+// format, for backends without --numstat.
+func LanguagesFromDiff(diff string) []display.LanguageStat {
+	tally := newLanguageTally()
+	file := ""
+	added, deleted := 0, 0
+	flush := func() {
+		if file != "" {
+			tally.add(file, added, deleted)
+		}
+	}
+
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			flush()
+			file, added, deleted = "", 0, 0
+			if idx := strings.LastIndex(line, " b/"); idx >= 0 {
+				file = line[idx+3:]
+			}
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+		case strings.HasPrefix(line, "+"):
+			added++
+		case strings.HasPrefix(line, "-"):
+			deleted++
+		}
+	}
+	flush()
+
+	return tally.sorted()
+}
+
+// SummarizeLanguages renders the breakdown as a short structured block for
+// the LLM prompt. It returns "" when there is nothing to summarise.
+func SummarizeLanguages(languages []display.LanguageStat) string {
+	if len(languages) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("Change summary by language (largest first; lead the message with the dominant area):\n")
+	for _, lang := range languages {
+		files := "files"
+		if lang.Files == 1 {
+			files = "file"
+		}
+		b.WriteString(fmt.Sprintf("- %s: %d %s, +%d/-%d lines\n", lang.Name, lang.Files, files, lang.Added, lang.Deleted))
+	}
+	return b.String()
+}
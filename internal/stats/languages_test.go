@@ -0,0 +1,124 @@
+package stats
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dfanso/commit-msg/internal/display"
+	"github.com/dfanso/commit-msg/pkg/types"
+)
+
+func TestLanguage(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"main.go":                     "Go",
+		"go.mod":                      "Go",
+		"web/src/App.TSX":             "TypeScript",
+		".github/workflows/ci.yml":    "YAML",
+		"README.md":                   "Docs",
+		"deploy/Dockerfile":           "Docker",
+		"assets/logo.png":             "Other",
+		"internal/{old.go => new.go}": "Go",
+	}
+
+	for file, want := range tests {
+		if got := Language(file); got != want {
+			t.Errorf("Language(%q) = %q, want %q", file, got, want)
+		}
+	}
+}
+
+func TestLanguagesFromDiff(t *testing.T) {
+	t.Parallel()
+
+	diff := strings.Join([]string{
+		"diff --git a/main.go b/main.go",
+		"--- a/main.go",
+		"+++ b/main.go",
+		"@@ -1 +1,2 @@",
+		"-old",
+		"+new",
+		"+more",
+		"diff --git a/README.md b/README.md",
+		"--- a/README.md",
+		"+++ b/README.md",
+		"@@ -1 +1 @@",
+		"+docs",
+	}, "\n")
+
+	want := []display.LanguageStat{
+		{Name: "Go", Files: 1, Added: 2, Deleted: 1},
+		{Name: "Docs", Files: 1, Added: 1, Deleted: 0},
+	}
+	if got := LanguagesFromDiff(diff); !reflect.DeepEqual(got, want) {
+		t.Fatalf("LanguagesFromDiff() = %+v, want %+v", got, want)
+	}
+}
+
+func TestSummarizeLanguages(t *testing.T) {
+	t.Parallel()
+
+	if got := SummarizeLanguages(nil); got != "" {
+		t.Fatalf("expected empty summary, got %q", got)
+	}
+
+	summary := SummarizeLanguages([]display.LanguageStat{
+		{Name: "Go", Files: 2, Added: 40, Deleted: 3},
+		{Name: "YAML", Files: 1, Added: 2, Deleted: 0},
+	})
+	for _, want := range []string{"- Go: 2 files, +40/-3 lines", "- YAML: 1 file, +2/-0 lines"} {
+		if !strings.Contains(summary, want) {
+			t.Fatalf("expected summary to contain %q, got %q", want, summary)
+		}
+	}
+}
+
+func TestGetFileStatisticsLanguages(t *testing.T) {
+	t.Parallel()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git executable not available")
+	}
+
+	dir := t.TempDir()
+	setupGitRepo(t, dir)
+
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0o644); err != nil {
+		t.Fatalf("failed to write main.go: %v", err)
+	}
+	runGit(t, dir, "add", "main.go")
+	runGit(t, dir, "commit", "-m", "initial")
+
+	// Staged Go change, unstaged change to the same file, and a new YAML file.
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0o644); err != nil {
+		t.Fatalf("failed to modify main.go: %v", err)
+	}
+	runGit(t, dir, "add", "main.go")
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\nfunc main() {}\n\nfunc helper() {}\n"), 0o644); err != nil {
+		t.Fatalf("failed to modify main.go: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "ci.yml"), []byte("on: push\njobs: {}\n"), 0o644); err != nil {
+		t.Fatalf("failed to write ci.yml: %v", err)
+	}
+
+	stats, err := GetFileStatistics(&types.RepoConfig{Path: dir})
+	if err != nil {
+		t.Fatalf("GetFileStatistics returned error: %v", err)
+	}
+
+	want := []display.LanguageStat{
+		{Name: "Go", Files: 1, Added: 4, Deleted: 0},
+		{Name: "YAML", Files: 1, Added: 2, Deleted: 0},
+	}
+	if !reflect.DeepEqual(stats.Languages, want) {
+		t.Fatalf("Languages = %+v, want %+v", stats.Languages, want)
+	}
+	if stats.LinesAdded != 2 {
+		t.Fatalf("LinesAdded = %d, want 2 (staged only)", stats.LinesAdded)
+	}
+}
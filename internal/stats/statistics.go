@@ -2,85 +2,51 @@ package stats
 
 import (
 	"fmt"
-	"os/exec"
-	"strings"
+	"path/filepath"
 
 	"github.com/dfanso/commit-msg/internal/display"
-	"github.com/dfanso/commit-msg/internal/utils"
+	"github.com/dfanso/commit-msg/internal/git"
 	"github.com/dfanso/commit-msg/pkg/types"
 )
 
 // GetFileStatistics collects comprehensive file statistics from Git
 func GetFileStatistics(config *types.RepoConfig) (*display.FileStatistics, error) {
+	set, err := git.CollectChanges(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect changes: %w", err)
+	}
+	return FromChangeSet(set), nil
+}
+
+// FromChangeSet summarises changes collected by git.CollectChanges, so the
+// statistics describe exactly the files the prompt is built from. Binary
+// files are counted as files without lines.
+func FromChangeSet(set *git.ChangeSet) *display.FileStatistics {
 	stats := &display.FileStatistics{
 		StagedFiles:    []string{},
 		UnstagedFiles:  []string{},
 		UntrackedFiles: []string{},
 	}
+	languages := newLanguageTally()
 
-	// Get staged files
-	stagedCmd := exec.Command("git", "-C", config.Path, "diff", "--name-only", "--cached")
-	stagedOutput, err := stagedCmd.Output()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get staged files: %w", err)
-	}
-	if len(stagedOutput) > 0 {
-		stats.StagedFiles = strings.Split(strings.TrimSpace(string(stagedOutput)), "\n")
-	}
-
-	// Get unstaged files
-	unstagedCmd := exec.Command("git", "-C", config.Path, "diff", "--name-only")
-	unstagedOutput, err := unstagedCmd.Output()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get unstaged files: %w", err)
-	}
-	if len(unstagedOutput) > 0 {
-		stats.UnstagedFiles = strings.Split(strings.TrimSpace(string(unstagedOutput)), "\n")
+	for _, file := range set.Staged {
+		stats.StagedFiles = append(stats.StagedFiles, file.Path)
+		stats.LinesAdded += file.Insertions
+		stats.LinesDeleted += file.Deletions
+		languages.add(file.Path, file.Insertions, file.Deletions)
 	}
-
-	// Get untracked files
-	untrackedCmd := exec.Command("git", "-C", config.Path, "ls-files", "--others", "--exclude-standard")
-	untrackedOutput, err := untrackedCmd.Output()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get untracked files: %w", err)
+	// Unstaged and untracked changes only feed the language breakdown;
+	// LinesAdded/LinesDeleted describe what would be committed.
+	for _, file := range set.Unstaged {
+		stats.UnstagedFiles = append(stats.UnstagedFiles, file.Path)
+		languages.add(file.Path, file.Insertions, file.Deletions)
 	}
-	if len(untrackedOutput) > 0 {
-		stats.UntrackedFiles = strings.Split(strings.TrimSpace(string(untrackedOutput)), "\n")
+	for _, file := range set.Untracked {
+		stats.UntrackedFiles = append(stats.UntrackedFiles, file)
+		languages.add(file, countFileLines(filepath.Join(set.Root, file)), 0)
 	}
 
-	// Filter empty strings
-	stats.StagedFiles = utils.FilterEmpty(stats.StagedFiles)
-	stats.UnstagedFiles = utils.FilterEmpty(stats.UnstagedFiles)
-	stats.UntrackedFiles = utils.FilterEmpty(stats.UntrackedFiles)
-
 	stats.TotalFiles = len(stats.StagedFiles) + len(stats.UnstagedFiles) + len(stats.UntrackedFiles)
-
-	// Get line statistics from staged changes
-	if len(stats.StagedFiles) > 0 {
-		statCmd := exec.Command("git", "-C", config.Path, "diff", "--cached", "--numstat")
-		statOutput, err := statCmd.Output()
-		if err != nil {
-			return nil, fmt.Errorf("failed to get line statistics: %w", err)
-		}
-		if len(statOutput) > 0 {
-			lines := strings.Split(strings.TrimSpace(string(statOutput)), "\n")
-			for _, line := range lines {
-				parts := strings.Fields(line)
-				if len(parts) >= 2 {
-					if added := parts[0]; added != "-" {
-						var addedNum int
-						fmt.Sscanf(added, "%d", &addedNum)
-						stats.LinesAdded += addedNum
-					}
-					if deleted := parts[1]; deleted != "-" {
-						var deletedNum int
-						fmt.Sscanf(deleted, "%d", &deletedNum)
-						stats.LinesDeleted += deletedNum
-					}
-				}
-			}
-		}
-	}
-
-	return stats, nil
+	stats.Languages = languages.sorted()
+	return stats
 }
@@ -0,0 +1,199 @@
+package symbols
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"sort"
+	"strings"
+)
+
+// MaxFiles caps how many files are listed in a summary so the section stays
+// small next to the diff.
+const MaxFiles = 20
+
+// Symbol is a top-level declaration such as a function, method, or type.
+type Symbol struct {
+	Kind string // func, method, type, const, or var
+	Name string
+}
+
+// String renders the symbol as "kind name".
+func (s Symbol) String() string {
+	return s.Kind + " " + s.Name
+}
+
+// FileChange lists the symbols added, modified, and removed in one file.
+type FileChange struct {
+	Path     string
+	Added    []Symbol
+	Modified []Symbol
+	Removed  []Symbol
+}
+
+// Empty reports whether no symbols changed.
+func (c FileChange) Empty() bool {
+	return len(c.Added) == 0 && len(c.Modified) == 0 && len(c.Removed) == 0
+}
+
+// Supported reports whether symbols can be extracted from path.
+func Supported(path string) bool {
+	return strings.HasSuffix(path, ".go")
+}
+
+// DiffGo compares two versions of a Go source file. Either side may be nil
+// for added or deleted files. Files that do not parse (for example in the
+// middle of an edit) yield an error.
+func DiffGo(path string, oldSrc, newSrc []byte) (FileChange, error) {
+	change := FileChange{Path: path}
+
+	oldDecls, err := collect(path, oldSrc)
+	if err != nil {
+		return change, err
+	}
+	newDecls, err := collect(path, newSrc)
+	if err != nil {
+		return change, err
+	}
+
+	for key, newDecl := range newDecls {
+		oldDecl, ok := oldDecls[key]
+		switch {
+		case !ok:
+			change.Added = append(change.Added, newDecl.symbol)
+		case oldDecl.text != newDecl.text:
+			change.Modified = append(change.Modified, newDecl.symbol)
+		}
+	}
+	for key, oldDecl := range oldDecls {
+		if _, ok := newDecls[key]; !ok {
+			change.Removed = append(change.Removed, oldDecl.symbol)
+		}
+	}
+
+	sortSymbols(change.Added)
+	sortSymbols(change.Modified)
+	sortSymbols(change.Removed)
+	return change, nil
+}
+
+// Summarize renders changes as a prompt section. It returns "" when no
+// symbols changed.
+func Summarize(changes []FileChange) string {
+	var lines []string
+	for _, change := range changes {
+		if change.Empty() {
+			continue
+		}
+		if len(lines) == MaxFiles {
+			lines = append(lines, "- ... more files omitted")
+			break
+		}
+
+		var parts []string
+		if len(change.Added) > 0 {
+			parts = append(parts, "added "+joinSymbols(change.Added))
+		}
+		if len(change.Modified) > 0 {
+			parts = append(parts, "modified "+joinSymbols(change.Modified))
+		}
+		if len(change.Removed) > 0 {
+			parts = append(parts, "removed "+joinSymbols(change.Removed))
+		}
+		lines = append(lines, fmt.Sprintf("- %s: %s", change.Path, strings.Join(parts, "; ")))
+	}
+
+	if len(lines) == 0 {
+		return ""
+	}
+	return "Symbols changed:\n" + strings.Join(lines, "\n") + "\n"
+}
+
+type decl struct {
+	symbol Symbol
+	text   string
+}
+
+// collect returns the top-level declarations of src keyed by kind and name.
+func collect(path string, src []byte) (map[string]decl, error) {
+	decls := make(map[string]decl)
+	if src == nil {
+		return decls, nil
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, src, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+
+	text := func(node ast.Node) string {
+		start := fset.Position(node.Pos()).Offset
+		end := fset.Position(node.End()).Offset
+		if start < 0 || end > len(src) || start > end {
+			return ""
+		}
+		return string(src[start:end])
+	}
+	add := func(kind, name string, node ast.Node) {
+		if name == "_" {
+			return
+		}
+		decls[kind+" "+name] = decl{symbol: Symbol{Kind: kind, Name: name}, text: text(node)}
+	}
+
+	for _, d := range file.Decls {
+		switch d := d.(type) {
+		case *ast.FuncDecl:
+			if d.Recv != nil && len(d.Recv.List) > 0 {
+				add("method", fmt.Sprintf("(%s).%s", exprString(fset, d.Recv.List[0].Type), d.Name.Name), d)
+			} else {
+				add("func", d.Name.Name, d)
+			}
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					add("type", spec.Name.Name, spec)
+				case *ast.ValueSpec:
+					kind := "var"
+					if d.Tok == token.CONST {
+						kind = "const"
+					}
+					for _, name := range spec.Names {
+						add(kind, name.Name, spec)
+					}
+				}
+			}
+		}
+	}
+	return decls, nil
+}
+
+func exprString(fset *token.FileSet, expr ast.Expr) string {
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, fset, expr); err != nil {
+		return "?"
+	}
+	return buf.String()
+}
+
+func sortSymbols(symbols []Symbol) {
+	sort.Slice(symbols, func(i, j int) bool {
+		if symbols[i].Kind != symbols[j].Kind {
+			return symbols[i].Kind < symbols[j].Kind
+		}
+		return symbols[i].Name < symbols[j].Name
+	})
+}
+
+func joinSymbols(symbols []Symbol) string {
+	names := make([]string, len(symbols))
+	for i, symbol := range symbols {
+		names[i] = symbol.String()
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,113 @@
+package symbols
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const oldSource = `package server
+
+// Server handles requests.
+type Server struct{ addr string }
+
+const defaultAddr = ":8080"
+
+func New() *Server { return &Server{addr: defaultAddr} }
+
+func (s *Server) Start() error { return nil }
+
+func legacy() {}
+`
+
+const newSource = `package server
+
+// Server handles requests. The comment change is not a code change.
+type Server struct{ addr string }
+
+const defaultAddr = ":8080"
+
+func New() *Server { return &Server{addr: defaultAddr} }
+
+func (s *Server) Start() error {
+	return s.listen()
+}
+
+func (s *Server) listen() error { return nil }
+
+var ErrClosed = errClosed{}
+`
+
+func TestDiffGo(t *testing.T) {
+	t.Parallel()
+
+	change, err := DiffGo("server.go", []byte(oldSource), []byte(newSource))
+	if err != nil {
+		t.Fatalf("DiffGo returned error: %v", err)
+	}
+
+	wantAdded := []Symbol{{Kind: "method", Name: "(*Server).listen"}, {Kind: "var", Name: "ErrClosed"}}
+	wantModified := []Symbol{{Kind: "method", Name: "(*Server).Start"}}
+	wantRemoved := []Symbol{{Kind: "func", Name: "legacy"}}
+
+	if !reflect.DeepEqual(change.Added, wantAdded) {
+		t.Errorf("Added = %v, want %v", change.Added, wantAdded)
+	}
+	if !reflect.DeepEqual(change.Modified, wantModified) {
+		t.Errorf("Modified = %v, want %v", change.Modified, wantModified)
+	}
+	if !reflect.DeepEqual(change.Removed, wantRemoved) {
+		t.Errorf("Removed = %v, want %v", change.Removed, wantRemoved)
+	}
+}
+
+func TestDiffGoNewAndDeletedFiles(t *testing.T) {
+	t.Parallel()
+
+	added, err := DiffGo("new.go", nil, []byte("package x\n\ntype T int\n"))
+	if err != nil {
+		t.Fatalf("DiffGo returned error: %v", err)
+	}
+	if len(added.Added) != 1 || added.Added[0].String() != "type T" {
+		t.Fatalf("expected type T to be added, got %+v", added)
+	}
+
+	removed, err := DiffGo("old.go", []byte("package x\n\nfunc F() {}\n"), nil)
+	if err != nil {
+		t.Fatalf("DiffGo returned error: %v", err)
+	}
+	if len(removed.Removed) != 1 || removed.Removed[0].String() != "func F" {
+		t.Fatalf("expected func F to be removed, got %+v", removed)
+	}
+}
+
+func TestDiffGoInvalidSource(t *testing.T) {
+	t.Parallel()
+
+	if _, err := DiffGo("broken.go", nil, []byte("package x\n\nfunc {")); err == nil {
+		t.Fatal("expected an error for unparsable source")
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	t.Parallel()
+
+	if got := Summarize([]FileChange{{Path: "empty.go"}}); got != "" {
+		t.Fatalf("expected empty summary, got %q", got)
+	}
+
+	summary := Summarize([]FileChange{{
+		Path:     "server.go",
+		Added:    []Symbol{{Kind: "func", Name: "New"}},
+		Modified: []Symbol{{Kind: "method", Name: "(*Server).Start"}},
+		Removed:  []Symbol{{Kind: "type", Name: "old"}},
+	}})
+
+	want := "- server.go: added func New; modified method (*Server).Start; removed type old"
+	if !strings.Contains(summary, want) {
+		t.Fatalf("expected summary to contain %q, got %q", want, summary)
+	}
+	if !strings.HasPrefix(summary, "Symbols changed:") {
+		t.Fatalf("expected section header, got %q", summary)
+	}
+}
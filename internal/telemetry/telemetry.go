@@ -0,0 +1,290 @@
+// Package telemetry keeps opt-in, local-only metrics about commit message
+// generation: how long each provider takes, how often it fails, and how
+// often its messages are regenerated. Only the provider, the model, timings,
+// and counters are recorded; diffs, prompts, messages, and repository names
+// never are. Nothing is sent anywhere; Export writes the metrics for the
+// user to share if they choose to.
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/dfanso/commit-msg/internal/llmerr"
+	"github.com/dfanso/commit-msg/pkg/types"
+	StoreUtils "github.com/dfanso/commit-msg/utils"
+)
+
+// FileName is the metrics file stored alongside config.json.
+const FileName = "telemetry.json"
+
+// maxLatencies bounds how many latencies are kept per provider for the
+// percentiles; older ones are dropped.
+const maxLatencies = 200
+
+// Failure kinds recorded for failed requests.
+const (
+	KindRateLimited     = "rate_limited"
+	KindQuotaExceeded   = "quota_exceeded"
+	KindAuth            = "auth"
+	KindModelNotFound   = "model_not_found"
+	KindContextTooLarge = "context_too_large"
+	KindTimeout         = "timeout"
+	KindOther           = "other"
+)
+
+// Settings is the "telemetry" section of config.json.
+type Settings struct {
+	// Enabled turns recording on. Telemetry is off unless enabled.
+	Enabled bool `json:"enabled"`
+}
+
+// Event is one generation outcome.
+type Event struct {
+	Provider types.LLMProvider
+	Model    string
+	// Latency is how long the provider took to answer or fail. It is
+	// ignored for cache hits.
+	Latency time.Duration
+	// Err is the provider's error, or nil when it answered. Only its
+	// Kind is recorded.
+	Err error
+	// Regeneration marks a request for another message for the same
+	// changes.
+	Regeneration bool
+	// CacheHit marks a message served from the cache without a request.
+	CacheHit bool
+}
+
+// ProviderMetrics aggregates the events of one provider.
+type ProviderMetrics struct {
+	Provider      types.LLMProvider `json:"provider"`
+	Model         string            `json:"model,omitempty"`
+	Requests      int               `json:"requests"`
+	Failures      int               `json:"failures"`
+	Regenerations int               `json:"regenerations"`
+	CacheHits     int               `json:"cache_hits"`
+	FailureKinds  map[string]int    `json:"failure_kinds,omitempty"`
+	// LatenciesMS holds the latencies of the most recent successful
+	// requests, in milliseconds, oldest first.
+	LatenciesMS []int64 `json:"latencies_ms,omitempty"`
+}
+
+// FailureRate is the share of requests that failed.
+func (m ProviderMetrics) FailureRate() float64 {
+	if m.Requests == 0 {
+		return 0
+	}
+	return float64(m.Failures) / float64(m.Requests)
+}
+
+// RegenerationRate is the share of requests that asked for another message.
+func (m ProviderMetrics) RegenerationRate() float64 {
+	if m.Requests == 0 {
+		return 0
+	}
+	return float64(m.Regenerations) / float64(m.Requests)
+}
+
+// Latency returns the p-th percentile (0-100) of the recorded latencies, or
+// zero when none were recorded.
+func (m ProviderMetrics) Latency(p float64) time.Duration {
+	if len(m.LatenciesMS) == 0 {
+		return 0
+	}
+	sorted := append([]int64(nil), m.LatenciesMS...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	idx := int(p / 100 * float64(len(sorted)-1))
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= len(sorted) {
+		idx = len(sorted) - 1
+	}
+	return time.Duration(sorted[idx]) * time.Millisecond
+}
+
+// Report is what Export writes.
+type Report struct {
+	Since      time.Time         `json:"since"`
+	ExportedAt time.Time         `json:"exported_at"`
+	Providers  []ProviderMetrics `json:"providers"`
+}
+
+// data is the on-disk format.
+type data struct {
+	Since     time.Time                              `json:"since"`
+	Providers map[types.LLMProvider]*ProviderMetrics `json:"providers"`
+}
+
+// Log persists metrics in a JSON file.
+type Log struct {
+	path string
+	now  func() time.Time
+}
+
+// NewLog returns a log backed by the file at path.
+func NewLog(path string) *Log {
+	return &Log{path: path, now: time.Now}
+}
+
+// DefaultPath returns the location of the metrics file.
+func DefaultPath() (string, error) {
+	configPath, err := StoreUtils.GetConfigPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(configPath), FileName), nil
+}
+
+// Kind classifies err into one of the failure kinds.
+func Kind(err error) string {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, llmerr.ErrRateLimited):
+		return KindRateLimited
+	case errors.Is(err, llmerr.ErrQuotaExceeded):
+		return KindQuotaExceeded
+	case errors.Is(err, llmerr.ErrAuth):
+		return KindAuth
+	case errors.Is(err, llmerr.ErrModelNotFound):
+		return KindModelNotFound
+	case errors.Is(err, llmerr.ErrContextTooLarge):
+		return KindContextTooLarge
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return KindTimeout
+	}
+	return KindOther
+}
+
+// Record adds e to the metrics of its provider.
+func (l *Log) Record(e Event) error {
+	d, err := l.load()
+	if err != nil {
+		return err
+	}
+
+	m, ok := d.Providers[e.Provider]
+	if !ok {
+		m = &ProviderMetrics{Provider: e.Provider}
+		d.Providers[e.Provider] = m
+	}
+	if e.Model != "" {
+		m.Model = e.Model
+	}
+
+	switch {
+	case e.CacheHit:
+		m.CacheHits++
+	case e.Err != nil:
+		m.Requests++
+		m.Failures++
+		if m.FailureKinds == nil {
+			m.FailureKinds = make(map[string]int)
+		}
+		m.FailureKinds[Kind(e.Err)]++
+	default:
+		m.Requests++
+		m.LatenciesMS = append(m.LatenciesMS, e.Latency.Milliseconds())
+		if len(m.LatenciesMS) > maxLatencies {
+			m.LatenciesMS = m.LatenciesMS[len(m.LatenciesMS)-maxLatencies:]
+		}
+	}
+	if e.Regeneration && !e.CacheHit {
+		m.Regenerations++
+	}
+
+	return l.save(d)
+}
+
+// Summary returns the metrics of every provider that was used, sorted by
+// provider, and when recording started.
+func (l *Log) Summary() ([]ProviderMetrics, time.Time, error) {
+	d, err := l.load()
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	metrics := make([]ProviderMetrics, 0, len(d.Providers))
+	for _, m := range d.Providers {
+		metrics = append(metrics, *m)
+	}
+	sort.Slice(metrics, func(i, j int) bool {
+		return metrics[i].Provider < metrics[j].Provider
+	})
+	return metrics, d.Since, nil
+}
+
+// Export writes the metrics to w as indented JSON.
+func (l *Log) Export(w io.Writer) error {
+	metrics, since, err := l.Summary()
+	if err != nil {
+		return err
+	}
+
+	raw, err := json.MarshalIndent(Report{
+		Since:      since,
+		ExportedAt: l.now().UTC(),
+		Providers:  metrics,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal telemetry: %w", err)
+	}
+	_, err = fmt.Fprintln(w, string(raw))
+	return err
+}
+
+// Reset deletes the recorded metrics.
+func (l *Log) Reset() error {
+	if err := os.Remove(l.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to reset telemetry: %w", err)
+	}
+	return nil
+}
+
+func (l *Log) load() (*data, error) {
+	d := &data{}
+
+	raw, err := os.ReadFile(l.path)
+	switch {
+	case os.IsNotExist(err):
+	case err != nil:
+		return nil, fmt.Errorf("failed to read telemetry: %w", err)
+	default:
+		if err := json.Unmarshal(raw, d); err != nil {
+			return nil, fmt.Errorf("failed to parse telemetry %s: %w", l.path, err)
+		}
+	}
+
+	if d.Providers == nil {
+		d.Providers = make(map[types.LLMProvider]*ProviderMetrics)
+	}
+	if d.Since.IsZero() {
+		d.Since = l.now().UTC()
+	}
+	return d, nil
+}
+
+func (l *Log) save(d *data) error {
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o700); err != nil {
+		return fmt.Errorf("failed to create telemetry directory: %w", err)
+	}
+
+	raw, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal telemetry: %w", err)
+	}
+
+	if err := os.WriteFile(l.path, raw, 0o600); err != nil {
+		return fmt.Errorf("failed to write telemetry: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dfanso/commit-msg/internal/llmerr"
+	"github.com/dfanso/commit-msg/pkg/types"
+)
+
+func TestRecordAndSummary(t *testing.T) {
+	t.Parallel()
+
+	log := NewLog(filepath.Join(t.TempDir(), FileName))
+	record := func(e Event) {
+		t.Helper()
+		if err := log.Record(e); err != nil {
+			t.Fatalf("Record error: %v", err)
+		}
+	}
+
+	record(Event{Provider: types.ProviderOpenAI, Model: "gpt-4o", Latency: 100 * time.Millisecond})
+	record(Event{Provider: types.ProviderOpenAI, Model: "gpt-4o", Latency: 300 * time.Millisecond, Regeneration: true})
+	record(Event{Provider: types.ProviderOpenAI, Model: "gpt-4o", Latency: 200 * time.Millisecond})
+	record(Event{Provider: types.ProviderOpenAI, Err: fmt.Errorf("openai: %w", llmerr.ErrRateLimited)})
+	record(Event{Provider: types.ProviderOpenAI, CacheHit: true, Regeneration: true})
+	record(Event{Provider: types.ProviderClaude, Err: errors.New("boom")})
+
+	metrics, since, err := log.Summary()
+	if err != nil {
+		t.Fatalf("Summary error: %v", err)
+	}
+	if since.IsZero() {
+		t.Fatal("expected the start of recording to be kept")
+	}
+	if len(metrics) != 2 || metrics[0].Provider != types.ProviderClaude || metrics[1].Provider != types.ProviderOpenAI {
+		t.Fatalf("unexpected providers: %+v", metrics)
+	}
+
+	claude := metrics[0]
+	if claude.Requests != 1 || claude.Failures != 1 || claude.FailureKinds[KindOther] != 1 || claude.FailureRate() != 1 {
+		t.Fatalf("unexpected Claude metrics: %+v", claude)
+	}
+
+	openai := metrics[1]
+	if openai.Requests != 4 || openai.Failures != 1 || openai.CacheHits != 1 || openai.Regenerations != 1 {
+		t.Fatalf("unexpected OpenAI metrics: %+v", openai)
+	}
+	if openai.FailureKinds[KindRateLimited] != 1 || openai.FailureRate() != 0.25 || openai.RegenerationRate() != 0.25 {
+		t.Fatalf("unexpected OpenAI failures: %+v", openai)
+	}
+	if openai.Model != "gpt-4o" {
+		t.Fatalf("expected the model to be kept, got %q", openai.Model)
+	}
+	if got := openai.Latency(50); got != 200*time.Millisecond {
+		t.Fatalf("Latency(50) = %s, want 200ms", got)
+	}
+	if got := openai.Latency(100); got != 300*time.Millisecond {
+		t.Fatalf("Latency(100) = %s, want 300ms", got)
+	}
+}
+
+func TestRecordBoundsLatencies(t *testing.T) {
+	t.Parallel()
+
+	log := NewLog(filepath.Join(t.TempDir(), FileName))
+	for i := 0; i < maxLatencies+10; i++ {
+		if err := log.Record(Event{Provider: types.ProviderGroq, Latency: time.Duration(i) * time.Millisecond}); err != nil {
+			t.Fatalf("Record error: %v", err)
+		}
+	}
+
+	metrics, _, err := log.Summary()
+	if err != nil {
+		t.Fatalf("Summary error: %v", err)
+	}
+	if got := len(metrics[0].LatenciesMS); got != maxLatencies {
+		t.Fatalf("expected %d latencies, got %d", maxLatencies, got)
+	}
+	if metrics[0].LatenciesMS[0] != 10 || metrics[0].Requests != maxLatencies+10 {
+		t.Fatalf("expected the oldest latencies to be dropped, got %+v", metrics[0])
+	}
+}
+
+func TestExportAndReset(t *testing.T) {
+	t.Parallel()
+
+	log := NewLog(filepath.Join(t.TempDir(), FileName))
+	if err := log.Record(Event{Provider: types.ProviderGemini, Model: "gemini-2.5-flash", Latency: time.Second}); err != nil {
+		t.Fatalf("Record error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := log.Export(&buf); err != nil {
+		t.Fatalf("Export error: %v", err)
+	}
+	var report Report
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("Export wrote invalid JSON: %v\n%s", err, buf.String())
+	}
+	if len(report.Providers) != 1 || report.Providers[0].LatenciesMS[0] != 1000 || report.ExportedAt.IsZero() {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+
+	if err := log.Reset(); err != nil {
+		t.Fatalf("Reset error: %v", err)
+	}
+	if err := log.Reset(); err != nil {
+		t.Fatalf("Reset without metrics error: %v", err)
+	}
+	metrics, _, err := log.Summary()
+	if err != nil || len(metrics) != 0 {
+		t.Fatalf("expected no metrics after Reset, got %+v, %v", metrics, err)
+	}
+}
+
+func TestRecordKeepsNoErrorText(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), FileName)
+	log := NewLog(path)
+	if err := log.Record(Event{Provider: types.ProviderOpenAI, Err: errors.New("diff --git a/secret.go b/secret.go")}); err != nil {
+		t.Fatalf("Record error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := log.Export(&buf); err != nil {
+		t.Fatalf("Export error: %v", err)
+	}
+	if strings.Contains(buf.String(), "secret") {
+		t.Fatalf("error text must not be recorded:\n%s", buf.String())
+	}
+}
+
+func TestKind(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{fmt.Errorf("wrapped: %w", llmerr.ErrRateLimited), KindRateLimited},
+		{llmerr.ErrQuotaExceeded, KindQuotaExceeded},
+		{llmerr.ErrAuth, KindAuth},
+		{llmerr.ErrModelNotFound, KindModelNotFound},
+		{llmerr.ErrContextTooLarge, KindContextTooLarge},
+		{fmt.Errorf("call: %w", context.DeadlineExceeded), KindTimeout},
+		{errors.New("boom"), KindOther},
+	}
+	for _, tt := range tests {
+		if got := Kind(tt.err); got != tt.want {
+			t.Errorf("Kind(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
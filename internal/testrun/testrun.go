@@ -0,0 +1,163 @@
+package testrun
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/dfanso/commit-msg/internal/logging"
+	"github.com/google/shlex"
+)
+
+// CommandEnv overrides the detected test command.
+const CommandEnv = "COMMIT_TEST_COMMAND"
+
+// DefaultTimeout bounds a test run; the feature is meant for quick suites.
+const DefaultTimeout = 2 * time.Minute
+
+// maxFailures caps how many failing test names are reported.
+const maxFailures = 10
+
+// Result summarises a test run.
+type Result struct {
+	Command  string
+	Passed   bool
+	TimedOut bool
+	Duration time.Duration
+	// Failures lists the names of failing tests that could be recognised.
+	Failures []string
+}
+
+// projectCommands maps a marker file at the repository root to the quick
+// test command for that ecosystem, in detection order.
+var projectCommands = []struct {
+	marker  string
+	command string
+}{
+	{"go.mod", "go test ./..."},
+	{"Cargo.toml", "cargo test --quiet"},
+	{"package.json", "npm test --silent"},
+	{"pyproject.toml", "pytest -q"},
+	{"pytest.ini", "pytest -q"},
+	{"Makefile", "make test"},
+}
+
+// DetectCommand returns the test command for the project at root: the
+// COMMIT_TEST_COMMAND environment variable when set, otherwise a default
+// chosen from the files present. It returns "" when nothing matches.
+func DetectCommand(root string) string {
+	if command := strings.TrimSpace(os.Getenv(CommandEnv)); command != "" {
+		return command
+	}
+	for _, candidate := range projectCommands {
+		if _, err := os.Stat(filepath.Join(root, candidate.marker)); err == nil {
+			return candidate.command
+		}
+	}
+	return ""
+}
+
+// Run executes command in dir and reports whether it passed. A failing test
+// suite is not an error; only a command that cannot be started is.
+func Run(ctx context.Context, dir, command string, timeout time.Duration) (*Result, error) {
+	args, err := shlex.Split(command)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse test command %q: %w", command, err)
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("test command is empty")
+	}
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Dir = dir
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+
+	logging.Command(cmd)
+	start := time.Now()
+	err = cmd.Run()
+	result := &Result{
+		Command:  command,
+		Duration: time.Since(start),
+		Failures: ParseFailures(output.String()),
+	}
+
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		result.Passed = true
+	case ctx.Err() == context.DeadlineExceeded:
+		result.TimedOut = true
+	case errors.As(err, &exitErr):
+		// Tests ran and failed.
+	default:
+		return nil, fmt.Errorf("failed to run %q: %w", command, err)
+	}
+
+	logging.Debug("test run finished", "command", command, "passed", result.Passed, "timed_out", result.TimedOut, "failures", len(result.Failures), "elapsed", result.Duration.Round(time.Millisecond))
+	return result, nil
+}
+
+// failurePatterns recognise failing test names in common runners' output.
+var failurePatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?m)^\s*--- FAIL: (\S+)`),                 // go test
+	regexp.MustCompile(`(?m)^test (\S+) \.\.\. FAILED$`),          // cargo test
+	regexp.MustCompile(`(?m)^FAILED (\S+?)(?: - .*)?$`),           // pytest -q
+	regexp.MustCompile(`(?m)^\s*(?:✕|×) (.+?)(?: \(\d+ m?s\))?$`), // jest/vitest
+}
+
+// ParseFailures extracts failing test names from runner output.
+func ParseFailures(output string) []string {
+	var failures []string
+	seen := make(map[string]bool)
+	for _, pattern := range failurePatterns {
+		for _, match := range pattern.FindAllStringSubmatch(output, -1) {
+			name := strings.TrimSpace(match[1])
+			if name == "" || seen[name] {
+				continue
+			}
+			seen[name] = true
+			failures = append(failures, name)
+		}
+	}
+	return failures
+}
+
+// Summary renders the result as a prompt section.
+func (r *Result) Summary() string {
+	var b strings.Builder
+	status := "PASSED"
+	switch {
+	case r.TimedOut:
+		status = "TIMED OUT"
+	case !r.Passed:
+		status = "FAILED"
+	}
+	b.WriteString(fmt.Sprintf("Test results (`%s`): %s in %s\n", r.Command, status, r.Duration.Round(100*time.Millisecond)))
+
+	if len(r.Failures) > 0 {
+		b.WriteString("Failing tests:\n")
+		for i, name := range r.Failures {
+			if i == maxFailures {
+				b.WriteString(fmt.Sprintf("- ... and %d more\n", len(r.Failures)-maxFailures))
+				break
+			}
+			b.WriteString("- " + name + "\n")
+		}
+	}
+	return b.String()
+}
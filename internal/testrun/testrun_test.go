@@ -0,0 +1,107 @@
+package testrun
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseFailures(t *testing.T) {
+	t.Parallel()
+
+	output := strings.Join([]string{
+		"=== RUN   TestFoo",
+		"--- FAIL: TestFoo (0.00s)",
+		"    --- FAIL: TestFoo/sub_case (0.00s)",
+		"--- FAIL: TestFoo (0.00s)",
+		"test parser::tests::handles_empty ... FAILED",
+		"FAILED tests/test_api.py::test_login - AssertionError: boom",
+		"  ✕ renders the header (12 ms)",
+		"ok  	example.com/pkg	0.01s",
+	}, "\n")
+
+	want := []string{
+		"TestFoo",
+		"TestFoo/sub_case",
+		"parser::tests::handles_empty",
+		"tests/test_api.py::test_login",
+		"renders the header",
+	}
+	if got := ParseFailures(output); !reflect.DeepEqual(got, want) {
+		t.Fatalf("ParseFailures() = %v, want %v", got, want)
+	}
+}
+
+func TestDetectCommand(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Setenv(CommandEnv, "")
+	if got := DetectCommand(dir); got != "" {
+		t.Fatalf("DetectCommand() = %q for empty project, want empty", got)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "package.json"), []byte("{}"), 0o644); err != nil {
+		t.Fatalf("failed to write package.json: %v", err)
+	}
+	if got := DetectCommand(dir); got != "npm test --silent" {
+		t.Fatalf("DetectCommand() = %q, want npm test", got)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module x\n"), 0o644); err != nil {
+		t.Fatalf("failed to write go.mod: %v", err)
+	}
+	if got := DetectCommand(dir); got != "go test ./..." {
+		t.Fatalf("DetectCommand() = %q, want go test", got)
+	}
+
+	t.Setenv(CommandEnv, "make quicktest")
+	if got := DetectCommand(dir); got != "make quicktest" {
+		t.Fatalf("DetectCommand() = %q, want the environment override", got)
+	}
+}
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git executable not available")
+	}
+
+	passed, err := Run(context.Background(), t.TempDir(), "git --version", time.Minute)
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if !passed.Passed || !strings.Contains(passed.Summary(), "PASSED") {
+		t.Fatalf("expected a passing run, got %+v", passed)
+	}
+
+	failed, err := Run(context.Background(), t.TempDir(), "git not-a-real-command", time.Minute)
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if failed.Passed || !strings.Contains(failed.Summary(), "FAILED") {
+		t.Fatalf("expected a failing run, got %+v", failed)
+	}
+
+	if _, err := Run(context.Background(), t.TempDir(), "definitely-not-installed-test-runner", time.Minute); err == nil {
+		t.Fatal("expected an error for a missing command")
+	}
+}
+
+func TestSummaryListsFailures(t *testing.T) {
+	t.Parallel()
+
+	result := &Result{Command: "go test ./...", Duration: 1500 * time.Millisecond, Failures: []string{"TestA", "TestB"}}
+	summary := result.Summary()
+
+	for _, want := range []string{"`go test ./...`", "FAILED in 1.5s", "- TestA", "- TestB"} {
+		if !strings.Contains(summary, want) {
+			t.Fatalf("expected summary to contain %q, got %q", want, summary)
+		}
+	}
+}
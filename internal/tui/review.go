@@ -0,0 +1,827 @@
+// Package tui implements the interactive review screen used to accept,
+// edit, or regenerate AI-generated commit messages.
+package tui
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/dfanso/commit-msg/pkg/types"
+)
+
+// StylePreset is a named tone/style instruction offered when regenerating.
+type StylePreset struct {
+	Label       string
+	Instruction string
+}
+
+// GenerateFunc produces a commit message for the supplied generation options.
+type GenerateFunc func(opts *types.GenerationOptions) (string, error)
+
+// Config describes the inputs to the review screen.
+type Config struct {
+	// Diff is the change set shown in the scrollable preview pane.
+	Diff string
+	// Message is the initial candidate commit message.
+	Message string
+	// Alternatives are other candidates generated alongside Message. When
+	// set, the screen opens on a selector listing all of them.
+	Alternatives []string
+	// Styles lists the presets offered when regenerating; the first entry is the default.
+	Styles []StylePreset
+	// Style is the style Message was generated with. The zero value means
+	// the first entry of Styles; an Instruction without a Label is shown as
+	// custom instructions.
+	Style StylePreset
+	// Temperature is the sampling temperature Message was generated with;
+	// nil means the provider default. The creative and conservative quick
+	// actions adjust it for later attempts.
+	Temperature *float64
+	// Generate is called in the background to regenerate the message.
+	Generate GenerateFunc
+	// EditorCommand builds the external editor command for the given file.
+	EditorCommand func(path string) (*exec.Cmd, error)
+	// PagerCommand builds the external pager the changes are piped to when
+	// viewing them. A nil command, or a nil PagerCommand, shows them in the
+	// built-in pager instead.
+	PagerCommand func() (*exec.Cmd, error)
+	// Warnings returns validation warnings for a candidate message.
+	Warnings func(message string) []string
+}
+
+// Result reports how the review session ended.
+type Result struct {
+	Accepted bool
+	Message  string
+	// Commit asks for the accepted message to be committed right away,
+	// with a Signed-off-by trailer when SignOff is set.
+	Commit  bool
+	SignOff bool
+	// Rejected lists the generated candidates that were not accepted,
+	// oldest first.
+	Rejected []string
+}
+
+// Run shows the review screen and blocks until the user accepts or discards
+// the message.
+func Run(cfg Config) (Result, error) {
+	final, err := tea.NewProgram(newModel(cfg), tea.WithAltScreen()).Run()
+	if err != nil {
+		return Result{}, err
+	}
+	return final.(*model).result, nil
+}
+
+type mode int
+
+const (
+	modeReview mode = iota
+	modeStyle
+	modeCustomStyle
+	modeInlineEdit
+	modeHistory
+	modeChanges
+)
+
+const (
+	customStyleLabel      = "Custom instructions (enter your own)"
+	moreCreativeLabel     = "More creative (higher temperature)"
+	moreConservativeLabel = "More conservative (lower temperature)"
+)
+
+// The quick actions move the temperature in steps of temperatureStep.
+const (
+	baselineTemperature = 0.5
+	temperatureStep     = 0.2
+	maxQuickTemperature = 1.0
+)
+
+// candidate is a message produced during the session, kept so users can go
+// back to an earlier attempt after regenerating.
+type candidate struct {
+	message string
+	attempt int
+	style   string
+}
+
+type generatedMsg struct {
+	message string
+	err     error
+	attempt int
+}
+
+type editedMsg struct {
+	message string
+	err     error
+}
+
+type pagedMsg struct {
+	err error
+}
+
+type model struct {
+	cfg Config
+
+	mode    mode
+	diff    viewport.Model
+	pager   viewport.Model
+	custom  textinput.Model
+	editor  textarea.Model
+	spinner spinner.Model
+
+	message    string
+	attempt    int
+	styleLabel string
+	styleOpts  *types.GenerationOptions
+	cursor     int
+
+	temperature *float64
+
+	history []candidate
+	current int
+
+	generating bool
+	status     string
+
+	width  int
+	height int
+	ready  bool
+
+	result Result
+}
+
+var (
+	headerStyle  = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("0")).Background(lipgloss.Color("6")).Padding(0, 1)
+	paneStyle    = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("8"))
+	activePane   = paneStyle.BorderForeground(lipgloss.Color("10"))
+	titleStyle   = lipgloss.NewStyle().Bold(true)
+	messageStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
+	warningStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("11"))
+	errorStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+	helpStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+	cursorStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("14")).Bold(true)
+
+	addedStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("2"))
+	removedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("1"))
+	hunkStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("6"))
+	fileStyle    = lipgloss.NewStyle().Bold(true)
+	sectionStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("3"))
+)
+
+func newModel(cfg Config) *model {
+	custom := textinput.New()
+	custom.Placeholder = "Describe the tone or style you're looking for"
+	custom.CharLimit = 500
+
+	editor := textarea.New()
+	editor.ShowLineNumbers = false
+	editor.CharLimit = 0
+
+	s := spinner.New()
+	s.Spinner = spinner.Dot
+
+	label := ""
+	if len(cfg.Styles) > 0 {
+		label = cfg.Styles[0].Label
+	}
+	var styleOpts *types.GenerationOptions
+	if strings.TrimSpace(cfg.Style.Instruction) != "" {
+		styleOpts = &types.GenerationOptions{StyleInstruction: cfg.Style.Instruction}
+		label = formatCustomStyleLabel(cfg.Style.Instruction)
+	}
+	if cfg.Style.Label != "" {
+		label = cfg.Style.Label
+	}
+
+	message := strings.TrimSpace(cfg.Message)
+	m := &model{
+		cfg:         cfg,
+		custom:      custom,
+		editor:      editor,
+		spinner:     s,
+		message:     message,
+		attempt:     1,
+		styleLabel:  label,
+		styleOpts:   styleOpts,
+		temperature: cfg.Temperature,
+		history:     []candidate{{message: message, attempt: 1, style: label}},
+	}
+	for _, alternative := range cfg.Alternatives {
+		alternative = strings.TrimSpace(alternative)
+		if alternative == "" || m.hasCandidate(alternative) {
+			continue
+		}
+		m.history = append(m.history, candidate{message: alternative, attempt: 1, style: label})
+	}
+	if len(m.history) > 1 {
+		m.mode = modeHistory
+	}
+	return m
+}
+
+func (m *model) hasCandidate(message string) bool {
+	for _, c := range m.history {
+		if c.message == message {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *model) Init() tea.Cmd {
+	return nil
+}
+
+func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.resize(msg.Width, msg.Height)
+		return m, nil
+	case spinner.TickMsg:
+		if !m.generating {
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+	case generatedMsg:
+		m.generating = false
+		if msg.err != nil {
+			m.status = errorStyle.Render(fmt.Sprintf("Regeneration failed: %v", msg.err))
+			return m, nil
+		}
+		m.attempt = msg.attempt
+		m.message = strings.TrimSpace(msg.message)
+		m.history = append(m.history, candidate{message: m.message, attempt: msg.attempt, style: m.styleLabel})
+		m.current = len(m.history) - 1
+		m.status = "Commit message regenerated!"
+		return m, nil
+	case editedMsg:
+		switch {
+		case msg.err != nil:
+			m.status = errorStyle.Render(fmt.Sprintf("Failed to edit commit message: %v", msg.err))
+		case strings.TrimSpace(msg.message) == "":
+			m.status = warningStyle.Render("Edited commit message is empty; keeping previous message.")
+		default:
+			m.message = strings.TrimSpace(msg.message)
+			m.status = "Commit message updated."
+		}
+		return m, nil
+	case pagedMsg:
+		if msg.err != nil {
+			m.status = errorStyle.Render(fmt.Sprintf("Failed to show changes: %v", msg.err))
+		}
+		return m, nil
+	case tea.KeyMsg:
+		if msg.String() == "ctrl+c" {
+			return m.quit(false)
+		}
+		switch m.mode {
+		case modeStyle:
+			return m.updateStyle(msg)
+		case modeCustomStyle:
+			return m.updateCustomStyle(msg)
+		case modeInlineEdit:
+			return m.updateInlineEdit(msg)
+		case modeHistory:
+			return m.updateHistory(msg)
+		case modeChanges:
+			return m.updateChanges(msg)
+		default:
+			return m.updateReview(msg)
+		}
+	}
+	return m, nil
+}
+
+// quit ends the session, recording the current message when accepted and
+// every other generated candidate as rejected.
+func (m *model) quit(accepted bool) (tea.Model, tea.Cmd) {
+	m.result = Result{Accepted: accepted}
+	if accepted {
+		m.result.Message = m.message
+	}
+	for _, c := range m.history {
+		if accepted && strings.TrimSpace(c.message) == strings.TrimSpace(m.message) {
+			continue
+		}
+		m.result.Rejected = append(m.result.Rejected, c.message)
+	}
+	return m, tea.Quit
+}
+
+func (m *model) updateReview(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.generating {
+		if msg.String() == "q" || msg.String() == "esc" {
+			return m.quit(false)
+		}
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "enter", "a", "c", "C":
+		if strings.TrimSpace(m.message) == "" {
+			m.status = warningStyle.Render("Commit message is empty; please edit or regenerate before accepting.")
+			return m, nil
+		}
+		model, cmd := m.quit(true)
+		m.result.Commit = msg.String() == "c" || msg.String() == "C"
+		m.result.SignOff = msg.String() == "C"
+		return model, cmd
+	case "q", "esc":
+		return m.quit(false)
+	case "r":
+		return m, m.regenerate()
+	case "s":
+		m.mode = modeStyle
+		m.cursor = m.currentStyleIndex()
+		return m, nil
+	case "b":
+		if len(m.history) < 2 {
+			m.status = "No previous attempts yet; press r to regenerate."
+			return m, nil
+		}
+		m.mode = modeHistory
+		m.cursor = m.current
+		return m, nil
+	case "i":
+		m.mode = modeInlineEdit
+		m.editor.SetValue(m.message)
+		return m, m.editor.Focus()
+	case "e":
+		return m, m.startEdit()
+	case "v":
+		return m, m.viewChanges()
+	}
+
+	var cmd tea.Cmd
+	m.diff, cmd = m.diff.Update(msg)
+	return m, cmd
+}
+
+func (m *model) updateStyle(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	options := m.styleOptions()
+	switch msg.String() {
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < len(options)-1 {
+			m.cursor++
+		}
+	case "esc", "q":
+		m.mode = modeReview
+	case "enter":
+		switch options[m.cursor] {
+		case customStyleLabel:
+			m.mode = modeCustomStyle
+			m.custom.SetValue("")
+			return m, m.custom.Focus()
+		case moreCreativeLabel:
+			m.adjustTemperature(temperatureStep)
+			m.mode = modeReview
+			return m, m.regenerate()
+		case moreConservativeLabel:
+			m.adjustTemperature(-temperatureStep)
+			m.mode = modeReview
+			return m, m.regenerate()
+		}
+		preset := m.cfg.Styles[m.cursor]
+		m.styleLabel = preset.Label
+		m.styleOpts = nil
+		if strings.TrimSpace(preset.Instruction) != "" {
+			m.styleOpts = &types.GenerationOptions{StyleInstruction: preset.Instruction}
+		}
+		m.mode = modeReview
+		return m, m.regenerate()
+	}
+	return m, nil
+}
+
+func (m *model) updateCustomStyle(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.custom.Blur()
+		m.mode = modeStyle
+		return m, nil
+	case "enter":
+		text := strings.TrimSpace(m.custom.Value())
+		m.custom.Blur()
+		if text == "" {
+			m.mode = modeStyle
+			return m, nil
+		}
+		m.styleLabel = formatCustomStyleLabel(text)
+		m.styleOpts = &types.GenerationOptions{StyleInstruction: text}
+		m.mode = modeReview
+		return m, m.regenerate()
+	}
+
+	var cmd tea.Cmd
+	m.custom, cmd = m.custom.Update(msg)
+	return m, cmd
+}
+
+// updateHistory handles keys while browsing previous attempts.
+func (m *model) updateHistory(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < len(m.history)-1 {
+			m.cursor++
+		}
+	case "esc", "q":
+		m.mode = modeReview
+	case "enter":
+		selected := m.history[m.cursor]
+		m.current = m.cursor
+		m.message = selected.message
+		m.mode = modeReview
+		m.status = fmt.Sprintf("Restored attempt #%d.", selected.attempt)
+		if m.attempt == 1 {
+			m.status = fmt.Sprintf("Selected candidate %d of %d.", m.cursor+1, len(m.history))
+		}
+	}
+	return m, nil
+}
+
+// updateChanges handles keys while the changes are shown in the built-in
+// pager.
+func (m *model) updateChanges(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q", "v":
+		m.mode = modeReview
+		return m, nil
+	case "g", "home":
+		m.pager.GotoTop()
+		return m, nil
+	case "G", "end":
+		m.pager.GotoBottom()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.pager, cmd = m.pager.Update(msg)
+	return m, cmd
+}
+
+// updateInlineEdit handles keys while the message is being edited in place.
+// ctrl+s saves the edit; esc discards it.
+func (m *model) updateInlineEdit(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.editor.Blur()
+		m.mode = modeReview
+		m.status = "Edit discarded."
+		return m, nil
+	case "ctrl+s":
+		value := m.editor.Value()
+		m.editor.Blur()
+		m.mode = modeReview
+		return m, func() tea.Msg { return editedMsg{message: value} }
+	}
+
+	var cmd tea.Cmd
+	m.editor, cmd = m.editor.Update(msg)
+	return m, cmd
+}
+
+// regenerate requests a new candidate in the background so the screen keeps
+// responding while the provider works.
+func (m *model) regenerate() tea.Cmd {
+	if m.cfg.Generate == nil {
+		return nil
+	}
+
+	nextAttempt := m.attempt + 1
+	opts := &types.GenerationOptions{}
+	if m.styleOpts != nil {
+		clone := *m.styleOpts
+		opts = &clone
+	}
+	opts.Attempt = nextAttempt
+	if m.temperature != nil {
+		temperature := *m.temperature
+		opts.Temperature = &temperature
+	}
+
+	m.generating = true
+	m.status = fmt.Sprintf("Regenerating commit message (%s)...", m.styleDescription())
+
+	generate := m.cfg.Generate
+	return tea.Batch(m.spinner.Tick, func() tea.Msg {
+		message, err := generate(opts)
+		return generatedMsg{message: message, err: err, attempt: nextAttempt}
+	})
+}
+
+// adjustTemperature moves the sampling temperature by delta, starting from
+// baselineTemperature when none is set. Steps never go below zero or above
+// maxQuickTemperature, which every provider accepts; a higher temperature
+// given on the command line is only ever lowered.
+func (m *model) adjustTemperature(delta float64) {
+	current := baselineTemperature
+	if m.temperature != nil {
+		current = *m.temperature
+	}
+	next := math.Round((current+delta)*10) / 10
+	if delta > 0 && next > maxQuickTemperature {
+		next = max(maxQuickTemperature, current)
+	}
+	next = max(next, 0)
+	m.temperature = &next
+}
+
+// styleDescription is the style label, followed by the temperature when one
+// is set.
+func (m *model) styleDescription() string {
+	if m.temperature == nil {
+		return m.styleLabel
+	}
+	return fmt.Sprintf("%s, temperature %.1f", m.styleLabel, *m.temperature)
+}
+
+// startEdit suspends the screen and opens the message in the external editor.
+func (m *model) startEdit() tea.Cmd {
+	if m.cfg.EditorCommand == nil {
+		return nil
+	}
+
+	tmpFile, err := os.CreateTemp("", "commit-msg-*.txt")
+	if err != nil {
+		return func() tea.Msg { return editedMsg{err: err} }
+	}
+	path := tmpFile.Name()
+
+	if _, err := tmpFile.WriteString(strings.TrimSpace(m.message) + "\n"); err != nil {
+		tmpFile.Close()
+		os.Remove(path)
+		return func() tea.Msg { return editedMsg{err: err} }
+	}
+	if err := tmpFile.Close(); err != nil {
+		os.Remove(path)
+		return func() tea.Msg { return editedMsg{err: err} }
+	}
+
+	cmd, err := m.cfg.EditorCommand(path)
+	if err != nil {
+		os.Remove(path)
+		return func() tea.Msg { return editedMsg{err: err} }
+	}
+
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		defer os.Remove(path)
+		if err != nil {
+			return editedMsg{err: fmt.Errorf("editor exited with error: %w", err)}
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return editedMsg{err: err}
+		}
+		return editedMsg{message: string(content)}
+	})
+}
+
+// viewChanges pipes the changes to the external pager, suspending the
+// screen, or opens the built-in pager when none is configured.
+func (m *model) viewChanges() tea.Cmd {
+	if m.cfg.PagerCommand != nil {
+		cmd, err := m.cfg.PagerCommand()
+		if err != nil {
+			return func() tea.Msg { return pagedMsg{err: err} }
+		}
+		if cmd != nil {
+			cmd.Stdin = strings.NewReader(m.cfg.Diff)
+			return tea.ExecProcess(cmd, func(err error) tea.Msg {
+				if err != nil {
+					return pagedMsg{err: fmt.Errorf("pager exited with error: %w", err)}
+				}
+				return pagedMsg{}
+			})
+		}
+	}
+
+	m.mode = modeChanges
+	m.pager.GotoTop()
+	return nil
+}
+
+func (m *model) resize(width, height int) {
+	m.width = width
+	m.height = height
+
+	leftWidth, rightWidth, bodyHeight := m.layout()
+	if !m.ready {
+		m.diff = viewport.New(1, 1)
+		m.diff.SetContent(colorizeDiff(m.cfg.Diff))
+		m.pager = viewport.New(1, 1)
+		m.pager.SetContent(colorizeDiff(m.cfg.Diff))
+		m.ready = true
+	}
+	m.diff.Width = max(leftWidth-2, 1)
+	m.diff.Height = max(bodyHeight-3, 1)
+	m.pager.Width = max(width-2, 1)
+	m.pager.Height = max(bodyHeight-3, 1)
+	m.editor.SetWidth(max(rightWidth-4, 10))
+	m.editor.SetHeight(max(bodyHeight-6, 3))
+}
+
+// layout returns the width of the diff pane, the width of the message pane,
+// and the height available to both.
+func (m *model) layout() (int, int, int) {
+	leftWidth := m.width * 3 / 5
+	rightWidth := m.width - leftWidth
+	bodyHeight := m.height - 2 // header and help lines
+	return leftWidth, rightWidth, bodyHeight
+}
+
+func (m *model) View() string {
+	if !m.ready {
+		return "Loading..."
+	}
+
+	leftWidth, rightWidth, bodyHeight := m.layout()
+
+	header := headerStyle.Width(m.width).Render(fmt.Sprintf("Commit Message Generator · attempt #%d (%d/%d) · style: %s",
+		m.history[m.current].attempt, m.current+1, len(m.history), m.styleDescription()))
+
+	if m.mode == modeChanges {
+		pane := activePane.Width(m.width - 2).Height(bodyHeight - 2).Render(
+			titleStyle.Render(fmt.Sprintf("Changes sent to the provider (%d%%)", int(m.pager.ScrollPercent()*100))) + "\n" + m.pager.View(),
+		)
+		return lipgloss.JoinVertical(lipgloss.Left, header, pane, helpStyle.Render(m.helpLine()))
+	}
+
+	diffPane := paneStyle
+	if m.mode == modeReview {
+		diffPane = activePane
+	}
+	left := diffPane.Width(leftWidth - 2).Height(bodyHeight - 2).Render(
+		titleStyle.Render(fmt.Sprintf("Changes (%d%%)", int(m.diff.ScrollPercent()*100))) + "\n" + m.diff.View(),
+	)
+
+	right := paneStyle.Width(rightWidth - 2).Height(bodyHeight - 2).Render(m.sideView(rightWidth - 4))
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		header,
+		lipgloss.JoinHorizontal(lipgloss.Top, left, right),
+		helpStyle.Render(m.helpLine()),
+	)
+}
+
+func (m *model) sideView(width int) string {
+	var b strings.Builder
+
+	switch m.mode {
+	case modeStyle:
+		b.WriteString(titleStyle.Render("Regenerate with style"))
+		b.WriteString("\n\n")
+		for i, option := range m.styleOptions() {
+			if i == m.cursor {
+				b.WriteString(cursorStyle.Render("> " + option))
+			} else {
+				b.WriteString("  " + option)
+			}
+			b.WriteString("\n")
+		}
+		return b.String()
+	case modeCustomStyle:
+		b.WriteString(titleStyle.Render("Custom instructions"))
+		b.WriteString("\n\n")
+		b.WriteString(m.custom.View())
+		return b.String()
+	case modeHistory:
+		title := "Previous attempts"
+		if m.attempt == 1 {
+			title = "Candidates"
+		}
+		b.WriteString(titleStyle.Render(title))
+		b.WriteString("\n\n")
+		for i, c := range m.history {
+			line := fmt.Sprintf("#%d [%s] %s", c.attempt, c.style, firstLine(c.message))
+			if i == m.cursor {
+				b.WriteString(cursorStyle.Render("> " + line))
+			} else {
+				b.WriteString("  " + line)
+			}
+			b.WriteString("\n")
+		}
+		if m.cursor < len(m.history) {
+			b.WriteString("\n")
+			b.WriteString(messageStyle.Width(width).Render(m.history[m.cursor].message))
+		}
+		return b.String()
+	case modeInlineEdit:
+		b.WriteString(titleStyle.Render("Edit Commit Message"))
+		b.WriteString("\n\n")
+		b.WriteString(m.editor.View())
+		return b.String()
+	}
+
+	b.WriteString(titleStyle.Render("Commit Message"))
+	b.WriteString("\n\n")
+	b.WriteString(messageStyle.Width(width).Render(m.message))
+	b.WriteString("\n")
+
+	if m.cfg.Warnings != nil {
+		for _, warning := range m.cfg.Warnings(m.message) {
+			b.WriteString("\n")
+			b.WriteString(warningStyle.Width(width).Render("! " + warning))
+		}
+	}
+
+	if m.generating {
+		b.WriteString("\n\n")
+		b.WriteString(m.spinner.View() + " " + m.status)
+	} else if m.status != "" {
+		b.WriteString("\n\n")
+		b.WriteString(lipgloss.NewStyle().Width(width).Render(m.status))
+	}
+
+	return b.String()
+}
+
+func (m *model) helpLine() string {
+	switch m.mode {
+	case modeStyle:
+		return "↑/↓ select • enter regenerate • esc back"
+	case modeCustomStyle:
+		return "enter regenerate • esc back"
+	case modeInlineEdit:
+		return "ctrl+s save • esc cancel"
+	case modeHistory:
+		return "↑/↓ select • enter restore • esc back"
+	case modeChanges:
+		return "↑/↓ pgup/pgdn scroll • g/G top/bottom • esc back"
+	}
+	return "enter accept • c commit • C commit signed off • r regenerate • s style • b previous attempts • i edit inline • e open editor • v view changes • ↑/↓ pgup/pgdn scroll diff • q discard"
+}
+
+func (m *model) styleOptions() []string {
+	options := make([]string, 0, len(m.cfg.Styles)+3)
+	for _, preset := range m.cfg.Styles {
+		options = append(options, preset.Label)
+	}
+	return append(options, customStyleLabel, moreCreativeLabel, moreConservativeLabel)
+}
+
+func (m *model) currentStyleIndex() int {
+	for i, preset := range m.cfg.Styles {
+		if preset.Label == m.styleLabel {
+			return i
+		}
+	}
+	if m.styleOpts != nil {
+		return len(m.cfg.Styles)
+	}
+	return 0
+}
+
+// colorizeDiff highlights additions, removals, hunk headers, and section
+// titles in the collected changes.
+func colorizeDiff(diff string) string {
+	lines := strings.Split(diff, "\n")
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"), strings.HasPrefix(line, "diff --git"):
+			lines[i] = fileStyle.Render(line)
+		case strings.HasPrefix(line, "+"):
+			lines[i] = addedStyle.Render(line)
+		case strings.HasPrefix(line, "-"):
+			lines[i] = removedStyle.Render(line)
+		case strings.HasPrefix(line, "@@"):
+			lines[i] = hunkStyle.Render(line)
+		case strings.HasSuffix(line, ":") && !strings.HasPrefix(line, " "):
+			lines[i] = sectionStyle.Render(line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func firstLine(message string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(message), "\n")
+	return line
+}
+
+func formatCustomStyleLabel(instruction string) string {
+	trimmed := strings.TrimSpace(instruction)
+	runes := []rune(trimmed)
+	if len(runes) > 40 {
+		return fmt.Sprintf("Custom: %s…", string(runes[:37]))
+	}
+	return fmt.Sprintf("Custom: %s", trimmed)
+}
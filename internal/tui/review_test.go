@@ -0,0 +1,555 @@
+package tui
+
+import (
+	"errors"
+	"io"
+	"os/exec"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/dfanso/commit-msg/pkg/types"
+)
+
+func keyMsg(key string) tea.KeyMsg {
+	switch key {
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	case "esc":
+		return tea.KeyMsg{Type: tea.KeyEsc}
+	case "down":
+		return tea.KeyMsg{Type: tea.KeyDown}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+}
+
+// generatedFrom runs the commands in a regeneration batch and returns the
+// resulting generatedMsg, as the tea runtime would deliver it.
+func generatedFrom(t *testing.T, cmd tea.Cmd) generatedMsg {
+	t.Helper()
+
+	batch, ok := cmd().(tea.BatchMsg)
+	if !ok {
+		t.Fatal("expected batch command from regeneration")
+	}
+	for _, c := range batch {
+		if c == nil {
+			continue
+		}
+		if gen, ok := c().(generatedMsg); ok {
+			return gen
+		}
+	}
+	t.Fatal("expected generatedMsg in batch")
+	return generatedMsg{}
+}
+
+func newTestModel(cfg Config) *model {
+	m := newModel(cfg)
+	m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	return m
+}
+
+func TestAcceptReturnsMessage(t *testing.T) {
+	t.Parallel()
+
+	m := newTestModel(Config{Message: "  feat: add TUI  "})
+	_, cmd := m.Update(keyMsg("enter"))
+	if cmd == nil {
+		t.Fatal("expected quit command after accepting")
+	}
+	if !m.result.Accepted || m.result.Message != "feat: add TUI" {
+		t.Fatalf("unexpected result: %+v", m.result)
+	}
+}
+
+func TestAcceptAndCommit(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		key                 string
+		wantCommit, signOff bool
+	}{
+		{key: "enter"},
+		{key: "c", wantCommit: true},
+		{key: "C", wantCommit: true, signOff: true},
+	}
+	for _, tt := range tests {
+		m := newTestModel(Config{Message: "feat: commit now"})
+		if _, cmd := m.Update(keyMsg(tt.key)); cmd == nil {
+			t.Fatalf("%s: expected quit command after accepting", tt.key)
+		}
+		if !m.result.Accepted || m.result.Commit != tt.wantCommit || m.result.SignOff != tt.signOff {
+			t.Fatalf("%s: unexpected result: %+v", tt.key, m.result)
+		}
+	}
+}
+
+func TestAcceptRejectsEmptyMessage(t *testing.T) {
+	t.Parallel()
+
+	m := newTestModel(Config{Message: "   "})
+	m.Update(keyMsg("enter"))
+	if m.result.Accepted {
+		t.Fatal("expected empty message not to be accepted")
+	}
+	if !strings.Contains(m.status, "empty") {
+		t.Fatalf("expected empty message warning, got %q", m.status)
+	}
+}
+
+func TestDiscard(t *testing.T) {
+	t.Parallel()
+
+	m := newTestModel(Config{Message: "fix: bug"})
+	_, cmd := m.Update(keyMsg("q"))
+	if cmd == nil {
+		t.Fatal("expected quit command after discarding")
+	}
+	if m.result.Accepted {
+		t.Fatal("expected discarded session not to be accepted")
+	}
+}
+
+func TestRegenerateWithStyle(t *testing.T) {
+	t.Parallel()
+
+	var gotOpts *types.GenerationOptions
+	m := newTestModel(Config{
+		Message: "fix: first",
+		Styles: []StylePreset{
+			{Label: "Default"},
+			{Label: "Casual", Instruction: "Be casual."},
+		},
+		Generate: func(opts *types.GenerationOptions) (string, error) {
+			gotOpts = opts
+			return "fix: second", nil
+		},
+	})
+
+	m.Update(keyMsg("s"))
+	if m.mode != modeStyle {
+		t.Fatalf("expected style mode, got %v", m.mode)
+	}
+	m.Update(keyMsg("down"))
+	_, cmd := m.Update(keyMsg("enter"))
+	if cmd == nil || !m.generating {
+		t.Fatal("expected regeneration to start")
+	}
+
+	m.Update(generatedFrom(t, cmd))
+
+	if m.message != "fix: second" || m.attempt != 2 || m.styleLabel != "Casual" {
+		t.Fatalf("unexpected state after regeneration: message=%q attempt=%d style=%q", m.message, m.attempt, m.styleLabel)
+	}
+	if gotOpts == nil || gotOpts.StyleInstruction != "Be casual." {
+		t.Fatalf("expected style instruction to be passed, got %+v", gotOpts)
+	}
+}
+
+func TestInitialStyleUsedForRegeneration(t *testing.T) {
+	t.Parallel()
+
+	var gotOpts *types.GenerationOptions
+	m := newTestModel(Config{
+		Message: "fix: first",
+		Styles:  []StylePreset{{Label: "Default"}},
+		Style:   StylePreset{Label: "jira", Instruction: "Prefix with PROJ-1."},
+		Generate: func(opts *types.GenerationOptions) (string, error) {
+			gotOpts = opts
+			return "PROJ-1: fix second", nil
+		},
+	})
+	if m.styleLabel != "jira" {
+		t.Fatalf("styleLabel = %q, want jira", m.styleLabel)
+	}
+
+	_, cmd := m.Update(keyMsg("r"))
+	m.Update(generatedFrom(t, cmd))
+	if gotOpts == nil || gotOpts.StyleInstruction != "Prefix with PROJ-1." {
+		t.Fatalf("expected the initial style to be reused, got %+v", gotOpts)
+	}
+}
+
+func TestInitialCustomInstruction(t *testing.T) {
+	t.Parallel()
+
+	m := newTestModel(Config{
+		Message: "fix: first",
+		Styles:  []StylePreset{{Label: "Default"}},
+		Style:   StylePreset{Instruction: "Mention the migration."},
+	})
+	if m.styleLabel != "Custom: Mention the migration." {
+		t.Fatalf("styleLabel = %q, want a custom label", m.styleLabel)
+	}
+	if m.currentStyleIndex() != len(m.cfg.Styles) {
+		t.Fatalf("expected the custom entry to be selected in the style menu")
+	}
+}
+
+func TestTemperatureQuickActions(t *testing.T) {
+	t.Parallel()
+
+	var temperatures []float64
+	initial := 0.9
+	m := newTestModel(Config{
+		Message:     "fix: first",
+		Styles:      []StylePreset{{Label: "Default"}, {Label: "Casual", Instruction: "Be casual."}},
+		Style:       StylePreset{Label: "Casual", Instruction: "Be casual."},
+		Temperature: &initial,
+		Generate: func(opts *types.GenerationOptions) (string, error) {
+			if opts.Temperature == nil {
+				t.Fatal("expected a temperature to be passed")
+			}
+			if opts.StyleInstruction != "Be casual." {
+				t.Fatalf("expected the current style to be kept, got %q", opts.StyleInstruction)
+			}
+			temperatures = append(temperatures, *opts.Temperature)
+			return "fix: again", nil
+		},
+	})
+
+	choose := func(label string) {
+		m.Update(keyMsg("s"))
+		for i, option := range m.styleOptions() {
+			if option == label {
+				m.cursor = i
+			}
+		}
+		_, cmd := m.Update(keyMsg("enter"))
+		m.Update(generatedFrom(t, cmd))
+	}
+
+	choose(moreCreativeLabel)
+	choose(moreCreativeLabel)
+	choose(moreConservativeLabel)
+
+	want := []float64{1.0, 1.0, 0.8}
+	if len(temperatures) != len(want) {
+		t.Fatalf("temperatures = %v, want %v", temperatures, want)
+	}
+	for i := range want {
+		if temperatures[i] != want[i] {
+			t.Fatalf("temperatures = %v, want %v", temperatures, want)
+		}
+	}
+	if m.styleLabel != "Casual" {
+		t.Fatalf("styleLabel = %q, want Casual", m.styleLabel)
+	}
+}
+
+func TestAdjustTemperatureFromProviderDefault(t *testing.T) {
+	t.Parallel()
+
+	m := newTestModel(Config{Message: "fix: first"})
+	for range 5 {
+		m.adjustTemperature(-temperatureStep)
+	}
+	if m.temperature == nil || *m.temperature != 0 {
+		t.Fatalf("expected the temperature to stop at zero, got %v", m.temperature)
+	}
+
+	high := 1.5
+	m = newTestModel(Config{Message: "fix: first", Temperature: &high})
+	m.adjustTemperature(temperatureStep)
+	if *m.temperature != 1.5 {
+		t.Fatalf("expected a temperature above the quick range to be kept, got %v", *m.temperature)
+	}
+	m.adjustTemperature(-temperatureStep)
+	if *m.temperature != 1.3 {
+		t.Fatalf("expected the temperature to be lowered to 1.3, got %v", *m.temperature)
+	}
+}
+
+func TestRegenerateFailureKeepsMessage(t *testing.T) {
+	t.Parallel()
+
+	m := newTestModel(Config{Message: "fix: first"})
+	m.generating = true
+	m.Update(generatedMsg{err: errors.New("boom")})
+
+	if m.generating {
+		t.Fatal("expected generating flag to be cleared")
+	}
+	if m.message != "fix: first" {
+		t.Fatalf("expected previous message to be kept, got %q", m.message)
+	}
+	if !strings.Contains(m.status, "boom") {
+		t.Fatalf("expected error in status, got %q", m.status)
+	}
+}
+
+func TestRegenerateCmdPassesAttempt(t *testing.T) {
+	t.Parallel()
+
+	called := make(chan *types.GenerationOptions, 1)
+	m := newTestModel(Config{
+		Message: "fix: first",
+		Generate: func(opts *types.GenerationOptions) (string, error) {
+			called <- opts
+			return "fix: again", nil
+		},
+	})
+	m.styleOpts = &types.GenerationOptions{StyleInstruction: "Short."}
+
+	cmd := m.regenerate()
+	if cmd == nil {
+		t.Fatal("expected regenerate command")
+	}
+	gen := generatedFrom(t, cmd)
+	if gen.attempt != 2 || gen.message != "fix: again" {
+		t.Fatalf("unexpected generated message: %+v", gen)
+	}
+
+	opts := <-called
+	if opts.Attempt != 2 || opts.StyleInstruction != "Short." {
+		t.Fatalf("unexpected options: %+v", opts)
+	}
+	if m.styleOpts.Attempt != 0 {
+		t.Fatal("expected stored style options not to be mutated")
+	}
+}
+
+func TestEditedMessage(t *testing.T) {
+	t.Parallel()
+
+	m := newTestModel(Config{Message: "fix: first"})
+
+	m.Update(editedMsg{message: "  \n"})
+	if m.message != "fix: first" {
+		t.Fatalf("expected empty edit to be ignored, got %q", m.message)
+	}
+
+	m.Update(editedMsg{message: "fix: edited\n"})
+	if m.message != "fix: edited" {
+		t.Fatalf("expected edited message, got %q", m.message)
+	}
+}
+
+func TestBrowsePreviousAttempts(t *testing.T) {
+	t.Parallel()
+
+	m := newTestModel(Config{Message: "fix: first", Styles: []StylePreset{{Label: "Default"}}})
+
+	m.Update(keyMsg("b"))
+	if m.mode != modeReview {
+		t.Fatal("expected history to stay closed with a single candidate")
+	}
+
+	m.generating = true
+	m.Update(generatedMsg{message: "fix: second", attempt: 2})
+	m.generating = true
+	m.Update(generatedMsg{message: "fix: third", attempt: 3})
+
+	if len(m.history) != 3 || m.current != 2 {
+		t.Fatalf("expected three candidates with the latest selected, got %d (current %d)", len(m.history), m.current)
+	}
+
+	m.Update(keyMsg("b"))
+	if m.mode != modeHistory {
+		t.Fatalf("expected history mode, got %v", m.mode)
+	}
+	m.Update(keyMsg("k"))
+	m.Update(keyMsg("k"))
+	m.Update(keyMsg("enter"))
+
+	if m.message != "fix: first" || m.current != 0 {
+		t.Fatalf("expected attempt #1 to be restored, got %q (current %d)", m.message, m.current)
+	}
+	if !strings.Contains(m.View(), "attempt #1 (1/3)") {
+		t.Fatal("expected header to show the restored attempt")
+	}
+
+	// Regenerating after restoring continues the attempt sequence.
+	if m.attempt != 3 {
+		t.Fatalf("expected attempt counter to stay at 3, got %d", m.attempt)
+	}
+}
+
+func TestAlternativesOpenCandidateSelector(t *testing.T) {
+	t.Parallel()
+
+	m := newTestModel(Config{
+		Message:      "fix: first",
+		Alternatives: []string{"fix: second", " fix: first ", "", "fix: third"},
+		Styles:       []StylePreset{{Label: "Default"}},
+	})
+	if m.mode != modeHistory || m.cursor != 0 {
+		t.Fatalf("expected the selector on the first candidate, got mode %v cursor %d", m.mode, m.cursor)
+	}
+	if len(m.history) != 3 {
+		t.Fatalf("expected duplicate and empty candidates to be dropped, got %d", len(m.history))
+	}
+	if !strings.Contains(m.View(), "Candidates") {
+		t.Fatal("expected the selector to be titled Candidates")
+	}
+
+	m.Update(keyMsg("down"))
+	m.Update(keyMsg("down"))
+	m.Update(keyMsg("enter"))
+	if m.mode != modeReview || m.message != "fix: third" {
+		t.Fatalf("expected the third candidate to be selected, got %q in mode %v", m.message, m.mode)
+	}
+
+	_, cmd := m.Update(keyMsg("enter"))
+	if cmd == nil || !m.result.Accepted || m.result.Message != "fix: third" {
+		t.Fatalf("unexpected result %+v", m.result)
+	}
+	if len(m.result.Rejected) != 2 {
+		t.Fatalf("expected the other candidates to be rejected, got %q", m.result.Rejected)
+	}
+}
+
+func TestResultListsRejectedCandidates(t *testing.T) {
+	t.Parallel()
+
+	m := newTestModel(Config{Message: "fix: first"})
+	m.generating = true
+	m.Update(generatedMsg{message: "fix: second", attempt: 2})
+	m.Update(keyMsg("enter"))
+
+	if !m.result.Accepted || m.result.Message != "fix: second" {
+		t.Fatalf("unexpected result: %+v", m.result)
+	}
+	if len(m.result.Rejected) != 1 || m.result.Rejected[0] != "fix: first" {
+		t.Fatalf("Rejected = %q, want [fix: first]", m.result.Rejected)
+	}
+
+	m = newTestModel(Config{Message: "fix: only"})
+	m.Update(keyMsg("q"))
+	if m.result.Accepted || len(m.result.Rejected) != 1 || m.result.Rejected[0] != "fix: only" {
+		t.Fatalf("unexpected result after discarding: %+v", m.result)
+	}
+}
+
+func TestInlineEdit(t *testing.T) {
+	t.Parallel()
+
+	m := newTestModel(Config{Message: "fix: typo"})
+
+	m.Update(keyMsg("i"))
+	if m.mode != modeInlineEdit {
+		t.Fatalf("expected inline edit mode, got %v", m.mode)
+	}
+	if m.editor.Value() != "fix: typo" {
+		t.Fatalf("expected editor to be seeded with message, got %q", m.editor.Value())
+	}
+
+	m.Update(keyMsg("!"))
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	if m.mode != modeReview {
+		t.Fatalf("expected review mode after saving, got %v", m.mode)
+	}
+	if cmd == nil {
+		t.Fatal("expected save command")
+	}
+	m.Update(cmd())
+
+	if m.message != "fix: typo!" {
+		t.Fatalf("expected inline edit to be applied, got %q", m.message)
+	}
+}
+
+func TestInlineEditCancel(t *testing.T) {
+	t.Parallel()
+
+	m := newTestModel(Config{Message: "fix: typo"})
+
+	m.Update(keyMsg("i"))
+	m.Update(keyMsg("x"))
+	m.Update(keyMsg("esc"))
+
+	if m.mode != modeReview {
+		t.Fatalf("expected review mode after cancelling, got %v", m.mode)
+	}
+	if m.message != "fix: typo" {
+		t.Fatalf("expected message to be unchanged, got %q", m.message)
+	}
+}
+
+func TestViewChangesInBuiltInPager(t *testing.T) {
+	t.Parallel()
+
+	m := newTestModel(Config{Diff: "+added line", Message: "feat: page"})
+	if _, cmd := m.Update(keyMsg("v")); cmd != nil {
+		t.Fatal("expected no command without an external pager")
+	}
+	if m.mode != modeChanges {
+		t.Fatalf("expected changes mode, got %v", m.mode)
+	}
+	if view := m.View(); !strings.Contains(view, "+added line") || !strings.Contains(view, "esc back") {
+		t.Fatalf("expected the pager to show the changes, got %q", view)
+	}
+
+	m.Update(keyMsg("esc"))
+	if m.mode != modeReview {
+		t.Fatalf("expected review mode after closing the pager, got %v", m.mode)
+	}
+}
+
+func TestViewChangesInExternalPager(t *testing.T) {
+	t.Parallel()
+
+	var pager *exec.Cmd
+	m := newTestModel(Config{
+		Diff:    "+added line",
+		Message: "feat: page",
+		PagerCommand: func() (*exec.Cmd, error) {
+			pager = exec.Command("less")
+			return pager, nil
+		},
+	})
+	_, cmd := m.Update(keyMsg("v"))
+	if cmd == nil {
+		t.Fatal("expected the external pager to be started")
+	}
+	if m.mode != modeReview {
+		t.Fatalf("expected review mode while paging externally, got %v", m.mode)
+	}
+	input, err := io.ReadAll(pager.Stdin)
+	if err != nil || string(input) != "+added line" {
+		t.Fatalf("expected the changes on the pager's stdin, got %q (%v)", input, err)
+	}
+
+	m.Update(pagedMsg{err: errors.New("exit status 2")})
+	if !strings.Contains(m.status, "exit status 2") {
+		t.Fatalf("expected pager failure in status, got %q", m.status)
+	}
+}
+
+func TestColorizeDiffKeepsContent(t *testing.T) {
+	t.Parallel()
+
+	diff := "Staged changes:\nM\tmain.go\n@@ -1 +1 @@\n-old\n+new"
+	out := colorizeDiff(diff)
+	for _, fragment := range []string{"Staged changes:", "main.go", "-old", "+new"} {
+		if !strings.Contains(out, fragment) {
+			t.Fatalf("expected colorized diff to contain %q, got %q", fragment, out)
+		}
+	}
+}
+
+func TestViewRendersPanes(t *testing.T) {
+	t.Parallel()
+
+	m := newTestModel(Config{Diff: "+added line", Message: "feat: render"})
+	view := m.View()
+	for _, fragment := range []string{"Changes", "Commit Message", "feat: render", "enter accept"} {
+		if !strings.Contains(view, fragment) {
+			t.Fatalf("expected view to contain %q", fragment)
+		}
+	}
+}
+
+func TestFormatCustomStyleLabel(t *testing.T) {
+	t.Parallel()
+
+	if got := formatCustomStyleLabel("short"); got != "Custom: short" {
+		t.Fatalf("unexpected label: %q", got)
+	}
+	long := strings.Repeat("x", 50)
+	if got := formatCustomStyleLabel(long); !strings.HasSuffix(got, "…") {
+		t.Fatalf("expected truncated label, got %q", got)
+	}
+}
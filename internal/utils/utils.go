@@ -1,22 +1,87 @@
 package utils
 
 import (
+	"bytes"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
+	"unicode/utf8"
 )
 
+// sniffLen is how much of a file is inspected by content-based detection.
+const sniffLen = 8 * 1024
+
 // NormalizePath handles both forward and backslashes
 func NormalizePath(path string) string {
 	// Replace backslashes with forward slashes
 	normalized := strings.ReplaceAll(path, "\\", "/")
-	// Remove any trailing slash
-	normalized = strings.TrimSuffix(normalized, "/")
+	// Drop the Windows long-path prefix (\\?\C:\...)
+	normalized = strings.TrimPrefix(normalized, "//?/")
+	// Remove any trailing slash, keeping a bare root
+	if normalized != "/" {
+		normalized = strings.TrimSuffix(normalized, "/")
+	}
 	return normalized
 }
 
-// IsTextFile checks if a file is likely to be a text file
+// IsBinaryContent reports whether data looks like binary content. Only the
+// first 8KB are inspected: any NUL byte marks the data as binary, as does a
+// high share of invalid UTF-8 or control characters.
+func IsBinaryContent(data []byte) bool {
+	if len(data) > sniffLen {
+		data = data[:sniffLen]
+	}
+	if bytes.IndexByte(data, 0) >= 0 {
+		return true
+	}
+
+	suspicious := 0
+	for i := 0; i < len(data); {
+		r, size := utf8.DecodeRune(data[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			// A multi-byte character cut off by the sample boundary is not
+			// evidence of binary content.
+			if !utf8.FullRune(data[i:]) {
+				i = len(data)
+				continue
+			}
+			suspicious++
+		case r < 0x20 && !strings.ContainsRune("\n\r\t\f\b\x1b", r):
+			suspicious++
+		}
+		i += size
+	}
+	return suspicious*10 > len(data)
+}
+
+// sniffFile classifies filename by its content. ok is false when the file
+// cannot be read, e.g. because it was deleted or is a directory.
+func sniffFile(filename string) (binary, ok bool) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return false, false
+	}
+	defer f.Close()
+
+	buf := make([]byte, sniffLen)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return false, false
+	}
+	return IsBinaryContent(buf[:n]), true
+}
+
+// IsTextFile checks if a file is likely to be a text file. Readable files
+// are classified by content; the extension is only consulted for files that
+// cannot be read.
 func IsTextFile(filename string) bool {
+	if binary, ok := sniffFile(filename); ok {
+		return !binary
+	}
+
 	// List of common text file extensions
 	textExtensions := []string{
 		".txt", ".md", ".go", ".js", ".py", ".java", ".c", ".cpp", ".h",
@@ -53,8 +118,14 @@ func IsTextFile(filename string) bool {
 	return false
 }
 
-// IsBinaryFile checks if a file is likely to be a binary file that should be excluded from diffs
+// IsBinaryFile checks if a file is likely to be a binary file that should be excluded from diffs.
+// Readable files are classified by content; the extension is only consulted
+// for files that cannot be read, such as deleted ones.
 func IsBinaryFile(filename string) bool {
+	if binary, ok := sniffFile(filename); ok {
+		return binary
+	}
+
 	// List of common binary file extensions
 	binaryExtensions := []string{
 		// Images (excluding SVG which is XML text)
@@ -90,12 +161,31 @@ func IsBinaryFile(filename string) bool {
 func IsSmallFile(filename string) bool {
 	const maxSize = 10 * 1024 // 10KB max
 
+	return FitsSize(filename, maxSize)
+}
+
+// FitsSize reports whether filename exists and is at most maxBytes long.
+func FitsSize(filename string, maxBytes int64) bool {
 	info, err := os.Stat(filename)
 	if err != nil {
 		return false
 	}
 
-	return info.Size() <= maxSize
+	return info.Size() <= maxBytes
+}
+
+// FormatBytes formats a size in bytes for display, such as "1.5 MB".
+func FormatBytes(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
 }
 
 // FilterEmpty removes empty strings from a slice
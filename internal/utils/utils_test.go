@@ -18,6 +18,9 @@ func TestNormalizePath(t *testing.T) {
 		{name: "windows style", input: "foo\\bar\\", expected: "foo/bar"},
 		{name: "already normalized", input: "foo/bar", expected: "foo/bar"},
 		{name: "no trailing slash", input: "foo", expected: "foo"},
+		{name: "root", input: "/", expected: "/"},
+		{name: "wsl share", input: `\\wsl$\Ubuntu\home\me\`, expected: "//wsl$/Ubuntu/home/me"},
+		{name: "long path prefix", input: `\\?\C:\repo`, expected: "C:/repo"},
 	}
 
 	for _, tt := range tests {
@@ -57,6 +60,66 @@ func TestIsTextFile(t *testing.T) {
 	}
 }
 
+func TestIsBinaryContent(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		data []byte
+		want bool
+	}{
+		{name: "empty", data: nil, want: false},
+		{name: "ascii", data: []byte("#!/bin/sh\necho hi\n"), want: false},
+		{name: "utf8", data: []byte("héllo wörld, こんにちは\n"), want: false},
+		{name: "nul byte", data: []byte("abc\x00def"), want: true},
+		{name: "invalid utf8", data: bytes.Repeat([]byte{0xff, 0xfe, 'a'}, 100), want: true},
+		{name: "control characters", data: bytes.Repeat([]byte{0x01, 0x02, 0x03, 'a'}, 100), want: true},
+		{name: "rune cut at sample end", data: append(bytes.Repeat([]byte("a"), sniffLen-1), "é"...), want: false},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := IsBinaryContent(tc.data); got != tc.want {
+				t.Fatalf("IsBinaryContent(%q) = %v, want %v", tc.name, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestFileDetectionSniffsContent(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	script := filepath.Join(dir, "deploy")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\nmake release\n"), 0o755); err != nil {
+		t.Fatalf("failed to write script: %v", err)
+	}
+
+	blob := filepath.Join(dir, "data.txt")
+	if err := os.WriteFile(blob, []byte{0x1f, 0x8b, 0x08, 0x00, 0x00}, 0o644); err != nil {
+		t.Fatalf("failed to write blob: %v", err)
+	}
+
+	if !IsTextFile(script) || IsBinaryFile(script) {
+		t.Errorf("extensionless script should be detected as text")
+	}
+	if IsTextFile(blob) || !IsBinaryFile(blob) {
+		t.Errorf("binary content with a text extension should be detected as binary")
+	}
+
+	// Unreadable files fall back to the extension.
+	if !IsBinaryFile(filepath.Join(dir, "deleted.png")) {
+		t.Errorf("missing .png should fall back to binary by extension")
+	}
+	if !IsTextFile(filepath.Join(dir, "deleted.go")) {
+		t.Errorf("missing .go should fall back to text by extension")
+	}
+}
+
 func TestIsSmallFile(t *testing.T) {
 	t.Parallel()
 
@@ -94,6 +157,22 @@ func TestIsSmallFile(t *testing.T) {
 	}
 }
 
+func TestFormatBytes(t *testing.T) {
+	t.Parallel()
+
+	tests := map[int64]string{
+		0:               "0 B",
+		1023:            "1023 B",
+		1536:            "1.5 KB",
+		5 * 1024 * 1024: "5.0 MB",
+	}
+	for bytes, want := range tests {
+		if got := FormatBytes(bytes); got != want {
+			t.Errorf("FormatBytes(%d) = %q, want %q", bytes, got, want)
+		}
+	}
+}
+
 func TestFilterEmpty(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,426 @@
+// Package vcr records the requests sent to LLM providers, and their
+// responses, to a cassette file and replays them later. Recording helps
+// debug what a provider was sent; replaying lets tests exercise the real
+// request and response handling of a provider without network access.
+//
+// Credentials are never written: authentication headers, key query
+// parameters, and token fields of JSON bodies are replaced with Redacted,
+// and request bodies are passed through the secret scrubber.
+package vcr
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/dfanso/commit-msg/internal/scrubber"
+	"github.com/dfanso/commit-msg/pkg/types"
+)
+
+// Environment variables naming the cassette to record to or replay from.
+const (
+	RecordEnv = "COMMIT_RECORD"
+	ReplayEnv = "COMMIT_REPLAY"
+)
+
+// Redacted replaces credentials in recorded interactions.
+const Redacted = "REDACTED"
+
+// sensitiveHeaders carry credentials and are redacted, compared
+// case-insensitively.
+var sensitiveHeaders = map[string]bool{
+	"authorization":       true,
+	"proxy-authorization": true,
+	"x-api-key":           true,
+	"x-goog-api-key":      true,
+	"api-key":             true,
+	"cookie":              true,
+	"set-cookie":          true,
+}
+
+// sensitiveParams are query parameters that carry credentials.
+var sensitiveParams = []string{"key", "api_key", "access_token", "token"}
+
+// sensitiveFields are JSON fields that carry credentials, as in OAuth token
+// requests and responses.
+var sensitiveFields = map[string]bool{
+	"access_token":  true,
+	"refresh_token": true,
+	"id_token":      true,
+	"client_secret": true,
+	"private_key":   true,
+	"assertion":     true,
+	"api_key":       true,
+}
+
+// Cassette is the file format of a recording.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is one request and the response it got.
+type Interaction struct {
+	Provider types.LLMProvider `json:"provider,omitempty"`
+	Request  Request           `json:"request"`
+	Response Response          `json:"response"`
+}
+
+// Request is a recorded request.
+type Request struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// Response is a recorded response.
+type Response struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// Load reads the cassette at path.
+func Load(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+	var cassette Cassette
+	if err := json.Unmarshal(data, &cassette); err != nil {
+		return nil, fmt.Errorf("failed to parse cassette %s: %w", path, err)
+	}
+	return &cassette, nil
+}
+
+// Save writes the cassette to path, replacing it atomically.
+func (c *Cassette) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode cassette: %w", err)
+	}
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("failed to create cassette directory: %w", err)
+		}
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	return nil
+}
+
+// Wrapper wraps the transport of the HTTP client built for a provider, the
+// signature of http.Settings.Wrap.
+type Wrapper func(provider types.LLMProvider, next http.RoundTripper) http.RoundTripper
+
+// FromEnv returns the wrapper selected by COMMIT_RECORD or COMMIT_REPLAY, or
+// nil when neither is set.
+func FromEnv() (Wrapper, error) {
+	record := strings.TrimSpace(os.Getenv(RecordEnv))
+	replay := strings.TrimSpace(os.Getenv(ReplayEnv))
+	switch {
+	case record != "" && replay != "":
+		return nil, fmt.Errorf("%s and %s cannot be used together", RecordEnv, ReplayEnv)
+	case record != "":
+		return NewRecorder(record).Wrap, nil
+	case replay != "":
+		replayer, err := LoadReplayer(replay)
+		if err != nil {
+			return nil, err
+		}
+		return replayer.Wrap, nil
+	}
+	return nil, nil
+}
+
+// Recorder saves every interaction made through its transports to a
+// cassette, rewriting the file after each one so an interrupted run keeps
+// what it recorded. The file is replaced, not appended to, on the first
+// interaction.
+type Recorder struct {
+	path string
+
+	mu       sync.Mutex
+	cassette Cassette
+}
+
+// NewRecorder records to the cassette at path.
+func NewRecorder(path string) *Recorder {
+	return &Recorder{path: path}
+}
+
+// Wrap returns a transport that sends requests through next and records
+// them as made for provider. A nil next uses http.DefaultTransport.
+func (r *Recorder) Wrap(provider types.LLMProvider, next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &recordingTransport{recorder: r, provider: provider, next: next}
+}
+
+func (r *Recorder) add(interaction Interaction) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	return r.cassette.Save(r.path)
+}
+
+type recordingTransport struct {
+	recorder *Recorder
+	provider types.LLMProvider
+	next     http.RoundTripper
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	requestBody, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	responseBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response for recording: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(responseBody))
+
+	interaction := Interaction{
+		Provider: t.provider,
+		Request: Request{
+			Method: req.Method,
+			URL:    sanitizeURL(req.URL),
+			Header: sanitizeHeader(req.Header),
+			Body:   scrubber.ScrubDiff(sanitizeBody(requestBody)),
+		},
+		Response: Response{
+			StatusCode: resp.StatusCode,
+			Header:     sanitizeHeader(resp.Header),
+			Body:       sanitizeBody(responseBody),
+		},
+	}
+	if err := t.recorder.add(interaction); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// readRequestBody reads the body of req and leaves an unread copy in its
+// place.
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request for recording: %w", err)
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// ErrNoInteraction is returned when a replayed request was not recorded, or
+// all its recordings were already used.
+var ErrNoInteraction = errors.New("no recorded interaction")
+
+// Replayer answers requests from a cassette without touching the network.
+// A request is answered by the first unused interaction recorded for the
+// same provider, method, and URL, so a cassette may hold several responses
+// to the same request, used in order.
+type Replayer struct {
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+// NewReplayer replays the interactions of cassette.
+func NewReplayer(cassette *Cassette) *Replayer {
+	return &Replayer{
+		interactions: cassette.Interactions,
+		used:         make([]bool, len(cassette.Interactions)),
+	}
+}
+
+// LoadReplayer replays the cassette at path.
+func LoadReplayer(path string) (*Replayer, error) {
+	cassette, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewReplayer(cassette), nil
+}
+
+// Wrap returns a transport that answers provider's requests from the
+// cassette; next is never used.
+func (r *Replayer) Wrap(provider types.LLMProvider, _ http.RoundTripper) http.RoundTripper {
+	return &replayingTransport{replayer: r, provider: provider}
+}
+
+// Transport answers requests made for any provider.
+func (r *Replayer) Transport() http.RoundTripper {
+	return &replayingTransport{replayer: r}
+}
+
+// Client returns an HTTP client whose requests are answered from the
+// cassette.
+func (r *Replayer) Client() *http.Client {
+	return &http.Client{Transport: r.Transport()}
+}
+
+// Remaining returns the number of interactions not replayed yet.
+func (r *Replayer) Remaining() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	remaining := 0
+	for _, used := range r.used {
+		if !used {
+			remaining++
+		}
+	}
+	return remaining
+}
+
+func (r *Replayer) next(provider types.LLMProvider, method, rawURL string) (Interaction, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, interaction := range r.interactions {
+		if r.used[i] || !strings.EqualFold(interaction.Request.Method, method) || interaction.Request.URL != rawURL {
+			continue
+		}
+		if provider != "" && interaction.Provider != "" && interaction.Provider != provider {
+			continue
+		}
+		r.used[i] = true
+		return interaction, true
+	}
+	return Interaction{}, false
+}
+
+type replayingTransport struct {
+	replayer *Replayer
+	provider types.LLMProvider
+}
+
+func (t *replayingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		_, _ = io.Copy(io.Discard, req.Body)
+		req.Body.Close()
+	}
+	rawURL := sanitizeURL(req.URL)
+	interaction, ok := t.replayer.next(t.provider, req.Method, rawURL)
+	if !ok {
+		return nil, fmt.Errorf("%w for %s %s", ErrNoInteraction, req.Method, rawURL)
+	}
+
+	header := interaction.Response.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	body := interaction.Response.Body
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+		StatusCode:    interaction.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// sanitizeURL returns u with credential query parameters redacted.
+func sanitizeURL(u *url.URL) string {
+	clean := *u
+	clean.User = nil
+	query := clean.Query()
+	changed := false
+	for _, name := range sensitiveParams {
+		if query.Has(name) {
+			query.Set(name, Redacted)
+			changed = true
+		}
+	}
+	if changed {
+		clean.RawQuery = query.Encode()
+	}
+	return clean.String()
+}
+
+// sanitizeHeader returns a copy of header with credentials redacted.
+func sanitizeHeader(header http.Header) http.Header {
+	if len(header) == 0 {
+		return nil
+	}
+	clean := header.Clone()
+	for name := range clean {
+		if sensitiveHeaders[strings.ToLower(name)] {
+			clean[name] = []string{Redacted}
+		}
+	}
+	return clean
+}
+
+// sanitizeBody redacts the credential fields of a JSON body. Other bodies
+// are returned unchanged.
+func sanitizeBody(body []byte) string {
+	var value any
+	if len(body) == 0 || json.Unmarshal(body, &value) != nil {
+		return string(body)
+	}
+	if !redactFields(value) {
+		return string(body)
+	}
+	clean, err := json.Marshal(value)
+	if err != nil {
+		return string(body)
+	}
+	return string(clean)
+}
+
+// redactFields replaces the sensitive fields found anywhere in value and
+// reports whether it changed anything.
+func redactFields(value any) bool {
+	changed := false
+	switch v := value.(type) {
+	case map[string]any:
+		for key, field := range v {
+			if sensitiveFields[strings.ToLower(key)] {
+				if s, ok := field.(string); ok && s != "" {
+					v[key] = Redacted
+					changed = true
+					continue
+				}
+			}
+			if redactFields(field) {
+				changed = true
+			}
+		}
+	case []any:
+		for _, item := range v {
+			if redactFields(item) {
+				changed = true
+			}
+		}
+	}
+	return changed
+}
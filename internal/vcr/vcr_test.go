@@ -0,0 +1,129 @@
+package vcr
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dfanso/commit-msg/pkg/types"
+)
+
+func TestRecordThenReplay(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Api-Key") != "sk-ant-secret" {
+			t.Fatalf("the recorder must forward credentials, got %q", r.Header.Get("X-Api-Key"))
+		}
+		body, _ := io.ReadAll(r.Body)
+		if !strings.Contains(string(body), "diff") {
+			t.Fatalf("the recorder must forward the body, got %q", body)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=abc")
+		_, _ = w.Write([]byte(`{"content":[{"type":"text","text":"fix: typo"}]}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	path := filepath.Join(t.TempDir(), "fixtures", "claude.json")
+	recorder := NewRecorder(path)
+	client := &http.Client{Transport: recorder.Wrap(types.ProviderClaude, nil)}
+
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+"/v1/messages?key=abc&beta=true", strings.NewReader(`{"prompt":"diff"}`))
+	req.Header.Set("X-Api-Key", "sk-ant-secret")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("recorded request failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != `{"content":[{"type":"text","text":"fix: typo"}]}` {
+		t.Fatalf("the recorder must pass the response through, got %q", body)
+	}
+
+	cassette, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if len(cassette.Interactions) != 1 {
+		t.Fatalf("expected one interaction, got %d", len(cassette.Interactions))
+	}
+	recorded := cassette.Interactions[0]
+	if recorded.Provider != types.ProviderClaude || recorded.Request.Header.Get("X-Api-Key") != Redacted || recorded.Response.Header.Get("Set-Cookie") != Redacted {
+		t.Fatalf("expected credentials to be redacted, got %+v", recorded)
+	}
+	if strings.Contains(recorded.Request.URL, "abc") || !strings.Contains(recorded.Request.URL, "beta=true") {
+		t.Fatalf("expected only the key parameter to be redacted, got %s", recorded.Request.URL)
+	}
+
+	replayer := NewReplayer(cassette)
+	replayClient := &http.Client{Transport: replayer.Wrap(types.ProviderClaude, nil)}
+	req, _ = http.NewRequest(http.MethodPost, srv.URL+"/v1/messages?key=other&beta=true", strings.NewReader(`{}`))
+	resp, err = replayClient.Do(req)
+	if err != nil {
+		t.Fatalf("replayed request failed: %v", err)
+	}
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), "fix: typo") || resp.Header.Get("Content-Type") != "application/json" {
+		t.Fatalf("unexpected replayed response: %d %s", resp.StatusCode, body)
+	}
+	if replayer.Remaining() != 0 {
+		t.Fatalf("expected the interaction to be used, %d remain", replayer.Remaining())
+	}
+
+	_, err = replayClient.Do(req)
+	if !errors.Is(err, ErrNoInteraction) {
+		t.Fatalf("expected ErrNoInteraction once the cassette is used up, got %v", err)
+	}
+}
+
+func TestReplayerMatchesProvider(t *testing.T) {
+	cassette := &Cassette{Interactions: []Interaction{
+		{Provider: types.ProviderGroq, Request: Request{Method: "GET", URL: "https://example.com/models"}, Response: Response{StatusCode: 200, Body: "groq"}},
+		{Provider: types.ProviderOpenAI, Request: Request{Method: "GET", URL: "https://example.com/models"}, Response: Response{StatusCode: 200, Body: "openai"}},
+	}}
+	replayer := NewReplayer(cassette)
+
+	client := &http.Client{Transport: replayer.Wrap(types.ProviderOpenAI, nil)}
+	resp, err := client.Get("https://example.com/models")
+	if err != nil {
+		t.Fatalf("replayed request failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "openai" {
+		t.Fatalf("expected the OpenAI recording, got %q", body)
+	}
+}
+
+func TestSanitizeBodyRedactsTokens(t *testing.T) {
+	got := sanitizeBody([]byte(`{"access_token":"ya29.secret","expires_in":3599,"nested":[{"refresh_token":"1//secret"}]}`))
+	if strings.Contains(got, "secret") || !strings.Contains(got, `"expires_in":3599`) {
+		t.Fatalf("unexpected sanitized body: %s", got)
+	}
+	if got := sanitizeBody([]byte("not json access_token")); got != "not json access_token" {
+		t.Fatalf("non-JSON bodies must be left alone, got %s", got)
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv(RecordEnv, "")
+	t.Setenv(ReplayEnv, "")
+	if wrap, err := FromEnv(); wrap != nil || err != nil {
+		t.Fatalf("expected no wrapper, got %v, %v", wrap != nil, err)
+	}
+
+	t.Setenv(RecordEnv, filepath.Join(t.TempDir(), "out.json"))
+	t.Setenv(ReplayEnv, "in.json")
+	if _, err := FromEnv(); err == nil {
+		t.Fatal("expected recording and replay together to be rejected")
+	}
+
+	t.Setenv(RecordEnv, "")
+	if _, err := FromEnv(); err == nil {
+		t.Fatal("expected a missing cassette to be reported")
+	}
+}
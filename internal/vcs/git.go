@@ -0,0 +1,114 @@
+package vcs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/dfanso/commit-msg/internal/display"
+	"github.com/dfanso/commit-msg/internal/git"
+	"github.com/dfanso/commit-msg/internal/stats"
+	"github.com/dfanso/commit-msg/pkg/types"
+)
+
+// Git is the Backend for git repositories, built on internal/git.
+type Git struct {
+	config types.RepoConfig
+	// changes is collected by Statistics and reused by the next Changes
+	// call, so both describe the same snapshot without running git twice.
+	changes *git.ChangeSet
+}
+
+// NewGit returns a git backend rooted at path.
+func NewGit(path string) *Git {
+	return &Git{config: types.RepoConfig{Path: path}}
+}
+
+func (g *Git) Name() string { return "git" }
+
+func (g *Git) Path() string { return g.config.Path }
+
+// SetLimits implements Limiter.
+func (g *Git) SetLimits(limits types.ContentLimits) {
+	g.config.Limits = limits
+	g.changes = nil
+}
+
+func (g *Git) Changes() (string, error) {
+	set := g.changes
+	g.changes = nil
+	if set == nil {
+		var err error
+		if set, err = git.CollectChanges(&g.config); err != nil {
+			return "", err
+		}
+	}
+	return set.Text(), nil
+}
+
+func (g *Git) Statistics() (*display.FileStatistics, error) {
+	set, err := git.CollectChanges(&g.config)
+	if err != nil {
+		return nil, err
+	}
+	g.changes = set
+	return stats.FromChangeSet(set), nil
+}
+
+// Commit commits the staging area. When paths are given only their staged
+// changes are committed, from a temporary index: a pathspec commit would
+// take the working tree copies instead and sweep up unstaged hunks.
+func (g *Git) Commit(message string, paths ...string) (string, error) {
+	// The reflog action lets commit undo recognise commits made here.
+	env := []string{"GIT_REFLOG_ACTION=" + git.AutoCommitReflogAction}
+	if len(paths) > 0 {
+		index, err := g.partialIndex(paths)
+		if err != nil {
+			return "", err
+		}
+		defer os.RemoveAll(filepath.Dir(index))
+		env = append(env, "GIT_INDEX_FILE="+index)
+	}
+	return runCombinedEnv(g.config.Path, env, "git", "commit", "-m", message)
+}
+
+// partialIndex writes a temporary index holding HEAD plus the staged
+// entries of paths and returns its path, in a directory of its own the
+// caller removes.
+func (g *Git) partialIndex(paths []string) (string, error) {
+	dir, err := os.MkdirTemp("", "commit-msg-index-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary index: %w", err)
+	}
+	index := filepath.Join(dir, "index")
+	partial := []string{"GIT_INDEX_FILE=" + index, "GIT_LITERAL_PATHSPECS=1"}
+
+	// The staged entries come from the real index, before the temporary
+	// one is selected.
+	entries, err := runInput(g.config.Path, []string{"GIT_LITERAL_PATHSPECS=1"}, "", "git", append([]string{"ls-files", "--stage", "-z", "--"}, paths...)...)
+	if err == nil {
+		if _, headErr := run(g.config.Path, "git", "rev-parse", "--verify", "--quiet", "HEAD"); headErr == nil {
+			_, err = runInput(g.config.Path, partial, "", "git", "read-tree", "HEAD")
+		} else {
+			_, err = runInput(g.config.Path, partial, "", "git", "read-tree", "--empty")
+		}
+	}
+	if err == nil {
+		// Dropping the paths first records the ones whose deletion is staged.
+		_, err = runInput(g.config.Path, partial, "", "git", append([]string{"update-index", "--force-remove", "--"}, paths...)...)
+	}
+	if err == nil && entries != "" {
+		_, err = runInput(g.config.Path, partial, entries, "git", "update-index", "-z", "--index-info")
+	}
+	if err != nil {
+		os.RemoveAll(dir)
+		return "", fmt.Errorf("failed to prepare the commit: %w", err)
+	}
+	return index, nil
+}
+
+// Stage implements Stager.
+func (g *Git) Stage(trackedOnly bool) error {
+	g.changes = nil
+	return git.StageChanges(&g.config, trackedOnly)
+}
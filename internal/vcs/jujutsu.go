@@ -0,0 +1,107 @@
+package vcs
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dfanso/commit-msg/internal/display"
+	"github.com/dfanso/commit-msg/internal/stats"
+	"github.com/dfanso/commit-msg/internal/utils"
+)
+
+// Jujutsu is the Backend for jj repositories. jj has no staging area: the
+// working-copy change (@) holds every pending edit, and committing gives it
+// a description and starts a new change on top.
+type Jujutsu struct {
+	path string
+}
+
+// NewJujutsu returns a jj backend rooted at path.
+func NewJujutsu(path string) *Jujutsu {
+	return &Jujutsu{path: path}
+}
+
+func (j *Jujutsu) Name() string { return "jj" }
+
+func (j *Jujutsu) Path() string { return j.path }
+
+func (j *Jujutsu) jj(args ...string) (string, error) {
+	return run(j.path, "jj", append([]string{"--no-pager", "--color=never"}, args...)...)
+}
+
+func (j *Jujutsu) Changes() (string, error) {
+	var changes strings.Builder
+
+	summary, err := j.jj("diff", "--summary")
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(summary) != "" {
+		changes.WriteString("Working copy changes:\n")
+		changes.WriteString(strings.TrimSpace(summary))
+		changes.WriteString("\n\n")
+
+		diff, err := j.jj("diff", "--git")
+		if err != nil {
+			return "", err
+		}
+		changes.WriteString("Working copy diff content:\n")
+		changes.WriteString(diff)
+		changes.WriteString("\n\n")
+	}
+
+	history, err := j.jj("log", "--no-graph", "-r", "ancestors(@-, 3)",
+		"-T", `change_id.short() ++ " " ++ description.first_line() ++ "\n"`)
+	if err == nil && strings.TrimSpace(history) != "" {
+		changes.WriteString("Recent changes for context:\n")
+		changes.WriteString(history)
+		changes.WriteString("\n")
+	}
+
+	return changes.String(), nil
+}
+
+func (j *Jujutsu) Statistics() (*display.FileStatistics, error) {
+	summary, err := j.jj("diff", "--summary")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get changed files: %w", err)
+	}
+
+	fileStats := &display.FileStatistics{
+		StagedFiles:    parseSummary(summary),
+		UnstagedFiles:  []string{},
+		UntrackedFiles: []string{},
+	}
+	fileStats.TotalFiles = len(fileStats.StagedFiles)
+
+	if fileStats.TotalFiles > 0 {
+		diff, err := j.jj("diff", "--git")
+		if err != nil {
+			return nil, fmt.Errorf("failed to get line statistics: %w", err)
+		}
+		fileStats.LinesAdded, fileStats.LinesDeleted = countDiffLines(diff)
+		fileStats.Languages = stats.LanguagesFromDiff(diff)
+	}
+	return fileStats, nil
+}
+
+// Commit describes the working-copy change and starts a new one. jj commit
+// accepts filesets, so paths limit which files go into the described change.
+func (j *Jujutsu) Commit(message string, paths ...string) (string, error) {
+	args := []string{"--no-pager", "--color=never", "commit", "-m", message}
+	args = append(args, paths...)
+	return runCombined(j.path, "jj", args...)
+}
+
+// parseSummary extracts paths from jj diff --summary or hg status output,
+// where each line is a one-letter status, a space, and the path.
+func parseSummary(output string) []string {
+	var files []string
+	for _, line := range strings.Split(output, "\n") {
+		if len(line) < 3 || line[1] != ' ' {
+			continue
+		}
+		files = append(files, strings.TrimSpace(line[2:]))
+	}
+	return utils.FilterEmpty(files)
+}
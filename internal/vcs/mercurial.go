@@ -0,0 +1,120 @@
+package vcs
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dfanso/commit-msg/internal/display"
+	"github.com/dfanso/commit-msg/internal/git"
+	"github.com/dfanso/commit-msg/internal/stats"
+	"github.com/dfanso/commit-msg/pkg/types"
+)
+
+// Mercurial is the Backend for hg repositories. Like jj it has no staging
+// area; modified, added, and removed files are committed together.
+type Mercurial struct {
+	path   string
+	limits types.ContentLimits
+}
+
+// NewMercurial returns an hg backend rooted at path.
+func NewMercurial(path string) *Mercurial {
+	return &Mercurial{path: path}
+}
+
+func (h *Mercurial) Name() string { return "hg" }
+
+func (h *Mercurial) Path() string { return h.path }
+
+// SetLimits implements Limiter.
+func (h *Mercurial) SetLimits(limits types.ContentLimits) { h.limits = limits }
+
+func (h *Mercurial) hg(args ...string) (string, error) {
+	return run(h.path, "hg", append([]string{"--pager=never", "--color=never"}, args...)...)
+}
+
+// root returns the repository root, which hg status paths are relative to.
+func (h *Mercurial) root() (string, error) {
+	root, err := h.hg("root")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(root), nil
+}
+
+func (h *Mercurial) Changes() (string, error) {
+	var changes strings.Builder
+
+	status, err := h.hg("status", "--modified", "--added", "--removed", "--deleted", "--root-relative")
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(status) != "" {
+		changes.WriteString("Working directory changes:\n")
+		changes.WriteString(strings.TrimSpace(status))
+		changes.WriteString("\n\n")
+
+		diff, err := h.hg("diff", "--git")
+		if err != nil {
+			return "", err
+		}
+		changes.WriteString("Working directory diff content:\n")
+		changes.WriteString(diff)
+		changes.WriteString("\n\n")
+	}
+
+	untracked, err := h.hg("status", "--unknown", "--no-status", "--root-relative")
+	if err != nil {
+		return "", err
+	}
+	if files := strings.Fields(untracked); len(files) > 0 {
+		root, err := h.root()
+		if err != nil {
+			return "", err
+		}
+		git.WriteUntrackedFiles(&changes, root, files, h.limits)
+	}
+
+	history, err := h.hg("log", "--limit", "3", "--template", "{node|short} {desc|firstline}\n")
+	if err == nil && strings.TrimSpace(history) != "" {
+		changes.WriteString("Recent commits for context:\n")
+		changes.WriteString(history)
+		changes.WriteString("\n")
+	}
+
+	return changes.String(), nil
+}
+
+func (h *Mercurial) Statistics() (*display.FileStatistics, error) {
+	status, err := h.hg("status", "--modified", "--added", "--removed", "--deleted", "--root-relative")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get changed files: %w", err)
+	}
+	untracked, err := h.hg("status", "--unknown", "--root-relative")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get untracked files: %w", err)
+	}
+
+	fileStats := &display.FileStatistics{
+		StagedFiles:    parseSummary(status),
+		UnstagedFiles:  []string{},
+		UntrackedFiles: parseSummary(untracked),
+	}
+	fileStats.TotalFiles = len(fileStats.StagedFiles) + len(fileStats.UntrackedFiles)
+
+	if len(fileStats.StagedFiles) > 0 {
+		diff, err := h.hg("diff", "--git")
+		if err != nil {
+			return nil, fmt.Errorf("failed to get line statistics: %w", err)
+		}
+		fileStats.LinesAdded, fileStats.LinesDeleted = countDiffLines(diff)
+		fileStats.Languages = stats.LanguagesFromDiff(diff)
+	}
+	return fileStats, nil
+}
+
+func (h *Mercurial) Commit(message string, paths ...string) (string, error) {
+	args := []string{"commit", "-m", message}
+	args = append(args, paths...)
+	return runCombined(h.path, "hg", args...)
+}
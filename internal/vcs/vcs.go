@@ -0,0 +1,151 @@
+package vcs
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/dfanso/commit-msg/internal/display"
+	"github.com/dfanso/commit-msg/internal/git"
+	"github.com/dfanso/commit-msg/internal/logging"
+	"github.com/dfanso/commit-msg/pkg/types"
+)
+
+// ErrNotRepository is returned by Detect when no supported repository
+// contains the given path.
+var ErrNotRepository = errors.New("not a version-controlled repository")
+
+// Backend abstracts the version control system that holds the changes a
+// commit message is generated for.
+type Backend interface {
+	// Name identifies the backend, e.g. "git" or "jj".
+	Name() string
+	// Path is the directory the backend operates on.
+	Path() string
+	// Changes returns the description of the pending changes that is sent
+	// to the LLM. It is not scrubbed yet; callers run scrubber.ScrubDiff on
+	// it before it leaves the machine.
+	Changes() (string, error)
+	// Statistics summarises the pending changes for display.
+	Statistics() (*display.FileStatistics, error)
+	// Commit records the pending changes with message. When paths are given
+	// only the pending changes of those files are committed. It returns the
+	// tool's output.
+	Commit(message string, paths ...string) (string, error)
+}
+
+// Stager is implemented by backends with a staging area.
+type Stager interface {
+	// Stage adds working tree changes to the staging area. When trackedOnly
+	// is true untracked files are left alone.
+	Stage(trackedOnly bool) error
+}
+
+// Limiter is implemented by backends that include file content in Changes
+// and can bound it.
+type Limiter interface {
+	// SetLimits bounds the content collected by later Changes calls.
+	SetLimits(limits types.ContentLimits)
+}
+
+// Detect returns the backend for the repository containing path. Jujutsu
+// takes precedence over git in colocated repositories, since jj manages the
+// working copy there.
+func Detect(path string) (Backend, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", path, err)
+	}
+
+	for dir := abs; ; dir = filepath.Dir(dir) {
+		switch {
+		case exists(filepath.Join(dir, ".jj")):
+			return NewJujutsu(abs), nil
+		case exists(filepath.Join(dir, ".hg")):
+			return NewMercurial(abs), nil
+		case exists(filepath.Join(dir, ".git")):
+			if git.IsRepository(abs) {
+				return NewGit(abs), nil
+			}
+			return nil, ErrNotRepository
+		}
+		if parent := filepath.Dir(dir); parent == dir {
+			break
+		}
+	}
+
+	// Repositories located through GIT_DIR and friends have no marker.
+	if git.IsRepository(abs) {
+		return NewGit(abs), nil
+	}
+	return nil, ErrNotRepository
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// run executes a VCS command in dir and returns its stdout.
+func run(dir, name string, args ...string) (string, error) {
+	return runInput(dir, nil, "", name, args...)
+}
+
+// runInput is like run but adds env to the environment and feeds input to
+// the command's standard input.
+func runInput(dir string, env []string, input string, name string, args ...string) (string, error) {
+	cmd := exec.Command(name, args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdin = strings.NewReader(input)
+	logging.Command(cmd)
+	output, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return "", fmt.Errorf("%s %s failed: %v: %s", name, strings.Join(args, " "), err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", fmt.Errorf("%s %s failed: %v", name, strings.Join(args, " "), err)
+	}
+	return string(output), nil
+}
+
+// runCombined is like run but returns stdout and stderr together, which is
+// how commit summaries are reported.
+func runCombined(dir, name string, args ...string) (string, error) {
+	return runCombinedEnv(dir, nil, name, args...)
+}
+
+// runCombinedEnv is like runCombined with env added to the environment.
+func runCombinedEnv(dir string, env []string, name string, args ...string) (string, error) {
+	cmd := exec.Command(name, args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), env...)
+	logging.Command(cmd)
+	output, err := cmd.CombinedOutput()
+	trimmed := strings.TrimSpace(string(output))
+	if err != nil {
+		if trimmed != "" {
+			return "", fmt.Errorf("%w\n%s", err, trimmed)
+		}
+		return "", err
+	}
+	return trimmed, nil
+}
+
+// countDiffLines counts added and deleted lines in a unified diff.
+func countDiffLines(diff string) (added, deleted int) {
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+		case strings.HasPrefix(line, "+"):
+			added++
+		case strings.HasPrefix(line, "-"):
+			deleted++
+		}
+	}
+	return added, deleted
+}
@@ -0,0 +1,254 @@
+package vcs
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dfanso/commit-msg/internal/git"
+)
+
+func TestDetect(t *testing.T) {
+	t.Parallel()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git executable not available")
+	}
+
+	t.Run("jujutsu takes precedence in colocated repos", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		if output, err := exec.Command("git", "init", dir).CombinedOutput(); err != nil {
+			t.Fatalf("failed to init git repo: %v: %s", err, output)
+		}
+		if err := os.Mkdir(filepath.Join(dir, ".jj"), 0o755); err != nil {
+			t.Fatalf("failed to create .jj: %v", err)
+		}
+		sub := filepath.Join(dir, "src")
+		if err := os.Mkdir(sub, 0o755); err != nil {
+			t.Fatalf("failed to create subdirectory: %v", err)
+		}
+
+		backend, err := Detect(sub)
+		if err != nil {
+			t.Fatalf("Detect returned error: %v", err)
+		}
+		if backend.Name() != "jj" {
+			t.Fatalf("backend = %q, want %q", backend.Name(), "jj")
+		}
+		if backend.Path() != sub {
+			t.Fatalf("path = %q, want %q", backend.Path(), sub)
+		}
+	})
+
+	t.Run("mercurial", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		if err := os.Mkdir(filepath.Join(dir, ".hg"), 0o755); err != nil {
+			t.Fatalf("failed to create .hg: %v", err)
+		}
+
+		backend, err := Detect(dir)
+		if err != nil {
+			t.Fatalf("Detect returned error: %v", err)
+		}
+		if backend.Name() != "hg" {
+			t.Fatalf("backend = %q, want %q", backend.Name(), "hg")
+		}
+	})
+
+	t.Run("git", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		if output, err := exec.Command("git", "init", dir).CombinedOutput(); err != nil {
+			t.Fatalf("failed to init git repo: %v: %s", err, output)
+		}
+
+		backend, err := Detect(dir)
+		if err != nil {
+			t.Fatalf("Detect returned error: %v", err)
+		}
+		if backend.Name() != "git" {
+			t.Fatalf("backend = %q, want %q", backend.Name(), "git")
+		}
+		if _, ok := backend.(Stager); !ok {
+			t.Fatal("expected the git backend to support staging")
+		}
+	})
+
+	t.Run("not a repository", func(t *testing.T) {
+		t.Parallel()
+
+		if _, err := Detect(t.TempDir()); err != ErrNotRepository {
+			t.Fatalf("Detect error = %v, want ErrNotRepository", err)
+		}
+	})
+}
+
+func TestParseSummary(t *testing.T) {
+	t.Parallel()
+
+	output := "M src/main.go\nA docs/new file.md\nD old.txt\n\n"
+	want := []string{"src/main.go", "docs/new file.md", "old.txt"}
+	if got := parseSummary(output); !reflect.DeepEqual(got, want) {
+		t.Fatalf("parseSummary() = %v, want %v", got, want)
+	}
+}
+
+func TestCountDiffLines(t *testing.T) {
+	t.Parallel()
+
+	diff := strings.Join([]string{
+		"diff --git a/main.go b/main.go",
+		"--- a/main.go",
+		"+++ b/main.go",
+		"@@ -1,3 +1,4 @@",
+		" package main",
+		"-func old() {}",
+		"+func new() {}",
+		"+func extra() {}",
+	}, "\n")
+
+	added, deleted := countDiffLines(diff)
+	if added != 2 || deleted != 1 {
+		t.Fatalf("countDiffLines() = (%d, %d), want (2, 1)", added, deleted)
+	}
+}
+
+func TestGitCommit(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration-style test in short mode")
+	}
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git executable not available")
+	}
+
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init"},
+		{"config", "user.name", "Test User"},
+		{"config", "user.email", "test@example.com"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v: %s", args, err, output)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "file.txt"), []byte("hello\n"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	backend := NewGit(dir)
+	if err := backend.Stage(false); err != nil {
+		t.Fatalf("Stage returned error: %v", err)
+	}
+
+	stats, err := backend.Statistics()
+	if err != nil {
+		t.Fatalf("Statistics returned error: %v", err)
+	}
+	if len(stats.StagedFiles) != 1 || stats.LinesAdded != 1 {
+		t.Fatalf("unexpected statistics: %+v", stats)
+	}
+
+	if _, err := backend.Commit("feat: add file"); err != nil {
+		t.Fatalf("Commit returned error: %v", err)
+	}
+
+	cmd := exec.Command("git", "log", "-1", "--format=%s")
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("git log failed: %v", err)
+	}
+	if got := strings.TrimSpace(string(output)); got != "feat: add file" {
+		t.Fatalf("commit subject = %q, want %q", got, "feat: add file")
+	}
+
+	cmd = exec.Command("git", "log", "--walk-reflogs", "-1", "--format=%gs")
+	cmd.Dir = dir
+	output, err = cmd.Output()
+	if err != nil {
+		t.Fatalf("git log --walk-reflogs failed: %v", err)
+	}
+	if got := string(output); !strings.HasPrefix(got, git.AutoCommitReflogAction+":") {
+		t.Fatalf("reflog entry = %q, want it marked as an auto-commit", got)
+	}
+}
+
+func TestGitCommitPathsUsesIndex(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration-style test in short mode")
+	}
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git executable not available")
+	}
+
+	dir := t.TempDir()
+	gitRun := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v failed: %v: %s", args, err, output)
+		}
+		return string(output)
+	}
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0o755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	gitRun("init")
+	gitRun("config", "user.name", "Test User")
+	gitRun("config", "user.email", "test@example.com")
+	write("api/main.go", "one\n")
+	write("api/old.go", "old\n")
+	write("web/app.js", "one\n")
+	gitRun("add", ".")
+	gitRun("commit", "-m", "initial")
+
+	// api/main.go is partially staged, api/old.go is deleted, and web has
+	// staged changes that belong to another commit.
+	write("api/main.go", "two\n")
+	gitRun("add", "api/main.go")
+	write("api/main.go", "three\n")
+	gitRun("rm", "-q", "api/old.go")
+	write("web/app.js", "two\n")
+	gitRun("add", "web/app.js")
+
+	if _, err := NewGit(dir).Commit("feat(api): update", "api/main.go", "api/old.go"); err != nil {
+		t.Fatalf("Commit returned error: %v", err)
+	}
+
+	if got := gitRun("show", "HEAD:api/main.go"); got != "two\n" {
+		t.Fatalf("committed api/main.go = %q, want the staged content", got)
+	}
+	if got := gitRun("ls-tree", "--name-only", "-r", "HEAD"); got != "api/main.go\nweb/app.js\n" {
+		t.Fatalf("committed files = %q, want api/old.go deleted", got)
+	}
+	if got := gitRun("show", "HEAD:web/app.js"); got != "one\n" {
+		t.Fatalf("committed web/app.js = %q, want it left out", got)
+	}
+	if got := gitRun("diff", "--cached", "--name-only"); got != "web/app.js\n" {
+		t.Fatalf("still staged = %q, want only web/app.js", got)
+	}
+	if got := gitRun("diff", "--name-only"); got != "api/main.go\n" {
+		t.Fatalf("unstaged = %q, want the api/main.go hunk kept", got)
+	}
+}
@@ -0,0 +1,208 @@
+package vertex
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+
+	"github.com/dfanso/commit-msg/internal/llmerr"
+)
+
+const cloudPlatformScope = "https://www.googleapis.com/auth/cloud-platform"
+
+// supportedTypes are the credential files accepted as a key file. Anything
+// else, such as an OAuth client secret, is refused rather than handed to
+// the Google library unchecked.
+var supportedTypes = map[string]bool{
+	string(google.ServiceAccount):                true,
+	string(google.AuthorizedUser):                true,
+	string(google.ExternalAccount):               true,
+	string(google.ExternalAccountAuthorizedUser): true,
+	string(google.ImpersonatedServiceAccount):    true,
+}
+
+// ErrNoCredentials is returned when no Application Default Credentials
+// could be found.
+var ErrNoCredentials = errors.New("no Application Default Credentials found: run 'gcloud auth application-default login', set GOOGLE_APPLICATION_CREDENTIALS, or give a service account key file in 'commit llm setup'")
+
+// credentialsFile holds the fields of a credentials file that say which
+// project to bill; the Google library reads the rest.
+type credentialsFile struct {
+	Type           string `json:"type"`
+	ProjectID      string `json:"project_id"`
+	QuotaProjectID string `json:"quota_project_id"`
+}
+
+// Credentials mint OAuth access tokens for Vertex AI. They wrap the Google
+// client library, which caches tokens until shortly before they expire.
+type Credentials struct {
+	// Source says where the credentials were found, for messages.
+	Source string
+
+	kind  string
+	file  credentialsFile
+	creds *google.Credentials
+}
+
+var (
+	credentialsMu     sync.Mutex
+	loadedCredentials = map[string]*Credentials{}
+)
+
+// FindCredentials returns the credentials in keyFile: a service account
+// key, gcloud user credentials, or a workload identity federation
+// (external_account) configuration. When keyFile is empty it follows the
+// Application Default Credentials order of the Google client library:
+// GOOGLE_APPLICATION_CREDENTIALS, the file written by 'gcloud auth
+// application-default login', and finally the metadata server of the Google
+// Cloud machine this runs on. Token requests go through client when it is
+// not nil. Credentials are loaded once per process so their tokens are
+// reused.
+func FindCredentials(keyFile string, client *http.Client) (*Credentials, error) {
+	keyFile = strings.TrimSpace(keyFile)
+
+	credentialsMu.Lock()
+	defer credentialsMu.Unlock()
+	if creds, ok := loadedCredentials[keyFile]; ok {
+		return creds, nil
+	}
+
+	// The token sources keep this context for every refresh, so it must
+	// outlive the call.
+	ctx := context.Background()
+	if client != nil {
+		ctx = context.WithValue(ctx, oauth2.HTTPClient, client)
+	}
+
+	var (
+		creds *Credentials
+		err   error
+	)
+	if keyFile == "" {
+		creds, err = findDefaultCredentials(ctx)
+	} else {
+		creds, err = loadCredentialsFile(ctx, keyFile)
+	}
+	if err != nil {
+		return nil, err
+	}
+	loadedCredentials[keyFile] = creds
+	return creds, nil
+}
+
+func findDefaultCredentials(ctx context.Context) (*Credentials, error) {
+	found, err := google.FindDefaultCredentials(ctx, cloudPlatformScope)
+	env := strings.TrimSpace(os.Getenv("GOOGLE_APPLICATION_CREDENTIALS"))
+	if err != nil {
+		if env != "" {
+			return nil, fmt.Errorf("invalid Google credentials in GOOGLE_APPLICATION_CREDENTIALS: %w", err)
+		}
+		return nil, fmt.Errorf("%w (%v)", ErrNoCredentials, err)
+	}
+
+	creds := &Credentials{creds: found}
+	switch {
+	case found.JSON == nil:
+		creds.Source = "the metadata server"
+	case env != "":
+		creds.Source = env
+	default:
+		creds.Source = "the gcloud application default credentials"
+	}
+	if found.JSON != nil {
+		if err := json.Unmarshal(found.JSON, &creds.file); err != nil {
+			return nil, fmt.Errorf("invalid Google credentials in %s: %w", creds.Source, err)
+		}
+		creds.kind = creds.file.Type
+	}
+	return creds, nil
+}
+
+func loadCredentialsFile(ctx context.Context, path string) (*Credentials, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Google credentials: %w", err)
+	}
+	var file credentialsFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid Google credentials in %s: %w", path, err)
+	}
+	if !supportedTypes[file.Type] {
+		return nil, fmt.Errorf("unsupported Google credentials type %q in %s: use a service account key, a workload identity federation configuration, or 'gcloud auth application-default login'", file.Type, path)
+	}
+
+	found, err := google.CredentialsFromJSONWithType(ctx, data, google.CredentialsType(file.Type), cloudPlatformScope)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Google credentials in %s: %w", path, err)
+	}
+	return &Credentials{Source: path, kind: file.Type, file: file, creds: found}, nil
+}
+
+// ProjectID returns the project recorded with the credentials: the
+// service account's project, the quota project of user or federated
+// credentials, or the project of the machine the metadata server describes.
+// It returns "" when none is known.
+func (c *Credentials) ProjectID(ctx context.Context) string {
+	if c.creds.ProjectID != "" {
+		return c.creds.ProjectID
+	}
+	return c.file.QuotaProjectID
+}
+
+// billsQuotaProject reports whether requests must name the project to bill,
+// because the credentials do not belong to one.
+func (c *Credentials) billsQuotaProject() bool {
+	return c.kind == string(google.AuthorizedUser) || c.kind == string(google.ExternalAccountAuthorizedUser)
+}
+
+// Token returns an access token for the cloud-platform scope, minting a new
+// one when the cached token is about to expire. It gives up when ctx is
+// done, even though the request to Google carries on in the background.
+func (c *Credentials) Token(ctx context.Context) (string, error) {
+	type result struct {
+		token *oauth2.Token
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		token, err := c.creds.TokenSource.Token()
+		done <- result{token, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return "", fmt.Errorf("failed to get a Google access token: %w", ctx.Err())
+	case r := <-done:
+		if r.err != nil {
+			return "", c.tokenError(r.err)
+		}
+		return r.token.AccessToken, nil
+	}
+}
+
+// tokenError reports credentials Google refused as llmerr.ErrAuth, so they
+// are not retried, and leaves network and server errors as they are.
+func (c *Credentials) tokenError(err error) error {
+	var retrieve *oauth2.RetrieveError
+	if !errors.As(err, &retrieve) || retrieve.Response == nil {
+		return fmt.Errorf("failed to request a Google access token: %w", err)
+	}
+	switch status := retrieve.Response.StatusCode; status {
+	case http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden:
+		reason := strings.TrimSpace(retrieve.ErrorCode + ": " + retrieve.ErrorDescription)
+		if retrieve.ErrorCode == "" {
+			reason = http.StatusText(status)
+		}
+		return fmt.Errorf("%w: Google rejected the credentials in %s (%s)", llmerr.ErrAuth, c.Source, reason)
+	default:
+		return fmt.Errorf("failed to request a Google access token: %w", err)
+	}
+}
@@ -0,0 +1,197 @@
+// Package vertex generates commit messages with Gemini models served by
+// Google Cloud Vertex AI. Unlike the public Gemini API it authenticates with
+// Application Default Credentials or a service account, and bills and
+// audits requests to a Google Cloud project.
+package vertex
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/dfanso/commit-msg/internal/gemini"
+	internalHTTP "github.com/dfanso/commit-msg/internal/http"
+	"github.com/dfanso/commit-msg/pkg/types"
+)
+
+const (
+	// DefaultModel is requested when no model was chosen during setup and
+	// VERTEX_MODEL is unset.
+	DefaultModel = gemini.DefaultModel
+	// DefaultLocation is the region requests are sent to when none is
+	// configured.
+	DefaultLocation = "us-central1"
+	// GlobalLocation routes requests to the global endpoint.
+	GlobalLocation = "global"
+)
+
+var locationPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// Options are the settings read from the "vertex" section of config.json.
+type Options struct {
+	// Project is the Google Cloud project billed for requests. Empty uses
+	// GOOGLE_CLOUD_PROJECT or the project of the credentials.
+	Project string `json:"project,omitempty"`
+	// Location is the region, such as europe-west4, or "global". Empty uses
+	// GOOGLE_CLOUD_LOCATION or us-central1.
+	Location string `json:"location,omitempty"`
+}
+
+// Validate reports settings that cannot work.
+func (o Options) Validate() error {
+	if o.Location != "" {
+		if err := ValidateLocation(o.Location); err != nil {
+			return err
+		}
+	}
+	if strings.ContainsAny(o.Project, "/ ") {
+		return fmt.Errorf("invalid vertex.project %q: use the project ID", o.Project)
+	}
+	return nil
+}
+
+// ValidateLocation checks that location looks like a Google Cloud region.
+func ValidateLocation(location string) error {
+	if !locationPattern.MatchString(strings.TrimSpace(location)) {
+		return fmt.Errorf("invalid Vertex AI location %q: use a region such as us-central1, or global", location)
+	}
+	return nil
+}
+
+var (
+	optionsMu sync.RWMutex
+	options   Options
+	// httpClient can be overridden in tests; nil uses the shared client
+	httpClient *http.Client
+)
+
+// Configure sets the options applied to every subsequent request.
+func Configure(opts Options) {
+	optionsMu.Lock()
+	defer optionsMu.Unlock()
+	options = opts
+}
+
+func configured() Options {
+	optionsMu.RLock()
+	defer optionsMu.RUnlock()
+	return options
+}
+
+// ResolveModel returns the model requested via VERTEX_MODEL, then saved (the
+// model chosen during setup), then DefaultModel.
+func ResolveModel(saved string) string {
+	if model := strings.TrimSpace(os.Getenv("VERTEX_MODEL")); model != "" {
+		return model
+	}
+	if saved = strings.TrimSpace(saved); saved != "" {
+		return saved
+	}
+	return DefaultModel
+}
+
+// Location returns the region requests go to: GOOGLE_CLOUD_LOCATION, then
+// the configured location, then DefaultLocation.
+func Location() string {
+	if location := strings.TrimSpace(os.Getenv("GOOGLE_CLOUD_LOCATION")); location != "" {
+		return location
+	}
+	if location := configured().Location; location != "" {
+		return location
+	}
+	return DefaultLocation
+}
+
+// Project returns the project billed for requests: GOOGLE_CLOUD_PROJECT,
+// then the configured project, then the project of creds.
+func Project(ctx context.Context, creds *Credentials) (string, error) {
+	if project := strings.TrimSpace(os.Getenv("GOOGLE_CLOUD_PROJECT")); project != "" {
+		return project, nil
+	}
+	if project := configured().Project; project != "" {
+		return project, nil
+	}
+	if creds != nil {
+		if project := creds.ProjectID(ctx); project != "" {
+			return project, nil
+		}
+	}
+	return "", fmt.Errorf("no Google Cloud project configured: set GOOGLE_CLOUD_PROJECT or run 'commit llm setup'")
+}
+
+// apiBase returns the API root serving location. VERTEX_API_ENDPOINT
+// overrides it for Private Service Connect endpoints.
+func apiBase(location string) string {
+	if custom := strings.TrimSpace(os.Getenv("VERTEX_API_ENDPOINT")); custom != "" {
+		return strings.TrimRight(custom, "/")
+	}
+	if location == GlobalLocation {
+		return "https://aiplatform.googleapis.com"
+	}
+	return "https://" + location + "-aiplatform.googleapis.com"
+}
+
+// GenerateURL is the generateContent endpoint of model in project and
+// location.
+func GenerateURL(project, location, model string) string {
+	return fmt.Sprintf("%s/v1/projects/%s/locations/%s/publishers/google/models/%s:generateContent",
+		apiBase(location), url.PathEscape(project), url.PathEscape(location), url.PathEscape(model))
+}
+
+// ModelURL is the publisher model resource for model, a cheap authenticated
+// GET used to check credentials.
+func ModelURL(location, model string) string {
+	return fmt.Sprintf("%s/v1beta1/publishers/google/models/%s", apiBase(location), url.PathEscape(model))
+}
+
+func client() *http.Client {
+	if httpClient != nil {
+		return httpClient
+	}
+	return internalHTTP.ClientFor(types.ProviderVertex)
+}
+
+// LoadCredentials returns the credentials in keyFile, or the Application
+// Default Credentials when keyFile is empty.
+func LoadCredentials(keyFile string) (*Credentials, error) {
+	return FindCredentials(keyFile, client())
+}
+
+// GenerateCommitMessage asks Vertex AI for a commit message, authenticating
+// with the service account key in keyFile or, when it is empty, the
+// Application Default Credentials. An empty model uses the one ResolveModel
+// picks.
+func GenerateCommitMessage(ctx context.Context, changes string, keyFile string, model string, opts *types.GenerationOptions) (string, error) {
+	if changes == "" {
+		return "", fmt.Errorf("no changes provided for commit message generation")
+	}
+	if model == "" {
+		model = ResolveModel("")
+	}
+
+	creds, err := LoadCredentials(keyFile)
+	if err != nil {
+		return "", err
+	}
+	token, err := creds.Token(ctx)
+	if err != nil {
+		return "", err
+	}
+	project, err := Project(ctx, creds)
+	if err != nil {
+		return "", err
+	}
+
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+token)
+	if creds.billsQuotaProject() {
+		// User credentials bill the quota project rather than gcloud's own.
+		header.Set("x-goog-user-project", project)
+	}
+	return gemini.GenerateContent(ctx, client(), types.ProviderVertex, GenerateURL(project, Location(), model), header, changes, opts)
+}
@@ -0,0 +1,308 @@
+package vertex
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dfanso/commit-msg/internal/llmerr"
+)
+
+func writeCredentials(t *testing.T, creds map[string]interface{}) string {
+	t.Helper()
+
+	data, err := json.Marshal(creds)
+	if err != nil {
+		t.Fatalf("failed to encode credentials: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "credentials.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("failed to write credentials: %v", err)
+	}
+	return path
+}
+
+func clearEnv(t *testing.T) {
+	t.Helper()
+	for _, name := range []string{"VERTEX_MODEL", "GOOGLE_CLOUD_PROJECT", "GOOGLE_CLOUD_LOCATION", "GOOGLE_APPLICATION_CREDENTIALS", "VERTEX_API_ENDPOINT"} {
+		t.Setenv(name, "")
+	}
+}
+
+func TestServiceAccountToken(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to encode key: %v", err)
+	}
+
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if err := r.ParseForm(); err != nil {
+			t.Fatalf("failed to parse form: %v", err)
+		}
+		if got := r.PostForm.Get("grant_type"); got != "urn:ietf:params:oauth:grant-type:jwt-bearer" {
+			t.Fatalf("unexpected grant type %q", got)
+		}
+		parts := strings.Split(r.PostForm.Get("assertion"), ".")
+		if len(parts) != 3 {
+			t.Fatalf("assertion is not a JWT: %q", r.PostForm.Get("assertion"))
+		}
+		signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+		if err != nil {
+			t.Fatalf("failed to decode signature: %v", err)
+		}
+		digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+		if err := rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], signature); err != nil {
+			t.Fatalf("assertion signature does not verify: %v", err)
+		}
+		claims, _ := base64.RawURLEncoding.DecodeString(parts[1])
+		var payload map[string]interface{}
+		if err := json.Unmarshal(claims, &payload); err != nil {
+			t.Fatalf("failed to decode claims: %v", err)
+		}
+		if payload["iss"] != "bot@acme-ml.iam.gserviceaccount.com" || payload["scope"] != cloudPlatformScope {
+			t.Fatalf("unexpected claims: %v", payload)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"sa-token","expires_in":3600,"token_type":"Bearer"}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	path := writeCredentials(t, map[string]interface{}{
+		"type":         "service_account",
+		"project_id":   "acme-ml",
+		"client_email": "bot@acme-ml.iam.gserviceaccount.com",
+		"private_key":  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		"token_uri":    srv.URL + "/token",
+	})
+	creds, err := FindCredentials(path, srv.Client())
+	if err != nil {
+		t.Fatalf("FindCredentials returned error: %v", err)
+	}
+	if got := creds.ProjectID(context.Background()); got != "acme-ml" {
+		t.Fatalf("unexpected project %q", got)
+	}
+
+	for i := 0; i < 2; i++ {
+		token, err := creds.Token(context.Background())
+		if err != nil {
+			t.Fatalf("Token returned error: %v", err)
+		}
+		if token != "sa-token" {
+			t.Fatalf("unexpected token %q", token)
+		}
+	}
+	if requests != 1 {
+		t.Fatalf("expected the token to be cached, got %d requests", requests)
+	}
+}
+
+func TestGenerateCommitMessageWithUserCredentials(t *testing.T) {
+	clearEnv(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			if err := r.ParseForm(); err != nil {
+				t.Fatalf("failed to parse form: %v", err)
+			}
+			if r.PostForm.Get("grant_type") != "refresh_token" || r.PostForm.Get("refresh_token") != "refresh" {
+				t.Fatalf("unexpected token request: %v", r.PostForm)
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"access_token":"user-token","expires_in":3600}`))
+			return
+		}
+
+		want := "/v1/projects/quota-project/locations/europe-west4/publishers/google/models/gemini-2.5-flash:generateContent"
+		if r.URL.Path != want {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer user-token" {
+			t.Fatalf("unexpected authorization header: %s", got)
+		}
+		if got := r.Header.Get("x-goog-user-project"); got != "quota-project" {
+			t.Fatalf("unexpected quota project header: %s", got)
+		}
+		_, _ = w.Write([]byte(`{"candidates":[{"content":{"parts":[{"text":"feat: add vertex provider"}]}}]}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	prevClient := httpClient
+	httpClient = srv.Client()
+	t.Cleanup(func() {
+		httpClient = prevClient
+		Configure(Options{})
+	})
+	t.Setenv("VERTEX_API_ENDPOINT", srv.URL)
+	Configure(Options{Location: "europe-west4"})
+
+	path := writeCredentials(t, map[string]interface{}{
+		"type":             "authorized_user",
+		"client_id":        "client",
+		"client_secret":    "secret",
+		"refresh_token":    "refresh",
+		"quota_project_id": "quota-project",
+		"token_uri":        srv.URL + "/token",
+	})
+	msg, err := GenerateCommitMessage(context.Background(), "diff", path, "gemini-2.5-flash", nil)
+	if err != nil {
+		t.Fatalf("GenerateCommitMessage returned error: %v", err)
+	}
+	if msg != "feat: add vertex provider" {
+		t.Fatalf("unexpected message: %q", msg)
+	}
+}
+
+func TestTokenRejected(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error":"invalid_grant","error_description":"Token has been expired or revoked."}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	path := writeCredentials(t, map[string]interface{}{
+		"type":          "authorized_user",
+		"client_id":     "client",
+		"refresh_token": "revoked",
+		"token_uri":     srv.URL,
+	})
+	creds, err := FindCredentials(path, srv.Client())
+	if err != nil {
+		t.Fatalf("FindCredentials returned error: %v", err)
+	}
+	_, err = creds.Token(context.Background())
+	if !errors.Is(err, llmerr.ErrAuth) || !strings.Contains(err.Error(), "invalid_grant") {
+		t.Fatalf("expected an auth error naming the reason, got %v", err)
+	}
+}
+
+func TestExternalAccountToken(t *testing.T) {
+	subjectToken := filepath.Join(t.TempDir(), "oidc-token")
+	if err := os.WriteFile(subjectToken, []byte("ci-oidc-token"), 0o600); err != nil {
+		t.Fatalf("failed to write subject token: %v", err)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Fatalf("failed to parse form: %v", err)
+		}
+		if got := r.PostForm.Get("grant_type"); got != "urn:ietf:params:oauth:grant-type:token-exchange" {
+			t.Fatalf("unexpected grant type %q", got)
+		}
+		if got := r.PostForm.Get("subject_token"); got != "ci-oidc-token" {
+			t.Fatalf("unexpected subject token %q", got)
+		}
+		if got := r.PostForm.Get("scope"); got != cloudPlatformScope {
+			t.Fatalf("unexpected scope %q", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"federated-token","issued_token_type":"urn:ietf:params:oauth:token-type:access_token","token_type":"Bearer","expires_in":3600}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	path := writeCredentials(t, map[string]interface{}{
+		"type":               "external_account",
+		"audience":           "//iam.googleapis.com/projects/123/locations/global/workloadIdentityPools/ci/providers/github",
+		"subject_token_type": "urn:ietf:params:oauth:token-type:jwt",
+		"token_url":          srv.URL + "/v1/token",
+		"credential_source":  map[string]string{"file": subjectToken},
+		"quota_project_id":   "acme-ml",
+	})
+	creds, err := FindCredentials(path, srv.Client())
+	if err != nil {
+		t.Fatalf("FindCredentials returned error: %v", err)
+	}
+	if got := creds.ProjectID(context.Background()); got != "acme-ml" {
+		t.Fatalf("unexpected project %q", got)
+	}
+	token, err := creds.Token(context.Background())
+	if err != nil {
+		t.Fatalf("Token returned error: %v", err)
+	}
+	if token != "federated-token" {
+		t.Fatalf("unexpected token %q", token)
+	}
+}
+
+func TestFindCredentialsRejectsUnsupportedType(t *testing.T) {
+	// An OAuth client secret has no type and must not start a browser login.
+	path := writeCredentials(t, map[string]interface{}{
+		"installed": map[string]string{"client_id": "client", "client_secret": "secret"},
+	})
+	if _, err := FindCredentials(path, nil); err == nil {
+		t.Fatal("expected an error for an OAuth client secret")
+	}
+}
+
+func TestGenerateURL(t *testing.T) {
+	clearEnv(t)
+
+	tests := []struct {
+		location string
+		want     string
+	}{
+		{"us-central1", "https://us-central1-aiplatform.googleapis.com/v1/projects/p/locations/us-central1/publishers/google/models/gemini-2.0-flash:generateContent"},
+		{"global", "https://aiplatform.googleapis.com/v1/projects/p/locations/global/publishers/google/models/gemini-2.0-flash:generateContent"},
+	}
+	for _, tt := range tests {
+		if got := GenerateURL("p", tt.location, "gemini-2.0-flash"); got != tt.want {
+			t.Errorf("GenerateURL(%q) = %q, want %q", tt.location, got, tt.want)
+		}
+	}
+}
+
+func TestProjectPrecedence(t *testing.T) {
+	clearEnv(t)
+	t.Cleanup(func() { Configure(Options{}) })
+
+	if _, err := Project(context.Background(), nil); err == nil {
+		t.Fatal("expected an error without a project")
+	}
+
+	Configure(Options{Project: "configured"})
+	if got, _ := Project(context.Background(), nil); got != "configured" {
+		t.Fatalf("expected the configured project, got %q", got)
+	}
+
+	t.Setenv("GOOGLE_CLOUD_PROJECT", "from-env")
+	if got, _ := Project(context.Background(), nil); got != "from-env" {
+		t.Fatalf("expected GOOGLE_CLOUD_PROJECT to win, got %q", got)
+	}
+}
+
+func TestOptionsValidate(t *testing.T) {
+	tests := []struct {
+		opts    Options
+		wantErr bool
+	}{
+		{Options{}, false},
+		{Options{Project: "acme-ml", Location: "europe-west4"}, false},
+		{Options{Location: "global"}, false},
+		{Options{Location: "Europe West"}, true},
+		{Options{Project: "projects/acme-ml"}, true},
+	}
+	for _, tt := range tests {
+		if err := tt.opts.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("Validate(%+v) error = %v, wantErr %v", tt.opts, err, tt.wantErr)
+		}
+	}
+}
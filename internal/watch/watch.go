@@ -0,0 +1,243 @@
+package watch
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dfanso/commit-msg/internal/logging"
+	"github.com/fsnotify/fsnotify"
+)
+
+// DefaultDebounce is how long the working tree must stay quiet before a
+// change notification is delivered.
+const DefaultDebounce = 2 * time.Second
+
+// Options configures a watch session.
+type Options struct {
+	// Root is the working tree to watch.
+	Root string
+	// Debounce is the quiet period before onChange runs. Zero uses
+	// DefaultDebounce.
+	Debounce time.Duration
+	// Ignore lists files whose changes never trigger a notification, such
+	// as the draft file the watcher itself writes.
+	Ignore []string
+}
+
+// Run watches the working tree and calls onChange after every burst of file
+// system activity once it has been quiet for the debounce period. Changes to
+// the git index (staging) also trigger a notification. Paths the repository
+// ignores, such as build output and dependencies, are not watched, and a
+// burst touching only ignored files is not reported. Run blocks until ctx is
+// cancelled and returns nil in that case.
+func Run(ctx context.Context, opts Options, onChange func()) error {
+	root, err := filepath.Abs(opts.Root)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", opts.Root, err)
+	}
+	debounce := opts.Debounce
+	if debounce <= 0 {
+		debounce = DefaultDebounce
+	}
+
+	ignored := make(map[string]bool, len(opts.Ignore))
+	for _, path := range opts.Ignore {
+		if abs, err := filepath.Abs(path); err == nil {
+			ignored[abs] = true
+		}
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create file watcher: %w", err)
+	}
+	defer watcher.Close()
+
+	if err := addTree(watcher, root, root); err != nil {
+		return err
+	}
+
+	// Watch the .git directory itself (not its subdirectories) so staging
+	// and commits, which rewrite .git/index, are noticed.
+	gitDir := filepath.Join(root, ".git")
+	if info, err := os.Stat(gitDir); err == nil && info.IsDir() {
+		if err := watcher.Add(gitDir); err != nil {
+			return fmt.Errorf("failed to watch %s: %w", gitDir, err)
+		}
+	}
+
+	timer := time.NewTimer(debounce)
+	if !timer.Stop() {
+		<-timer.C
+	}
+
+	// Paths changed in the current burst, checked against the ignore rules
+	// in one go when it ends.
+	var pending []string
+	indexChanged := false
+
+	for {
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if ignored[event.Name] || !relevant(root, event) {
+				continue
+			}
+			logging.Debug("watch event", "op", event.Op.String(), "path", event.Name)
+
+			if event.Has(fsnotify.Create) {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					if err := addTree(watcher, root, event.Name); err != nil {
+						logging.Debug("failed to watch new directory", "path", event.Name, "error", err)
+					}
+				}
+			}
+			if filepath.Dir(event.Name) == gitDir {
+				indexChanged = true
+			} else {
+				pending = append(pending, event.Name)
+			}
+			timer.Reset(debounce)
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			logging.Debug("watch error", "error", err)
+
+		case <-timer.C:
+			notify := indexChanged || !allIgnored(root, pending)
+			pending, indexChanged = nil, false
+			if notify {
+				onChange()
+			}
+		}
+	}
+}
+
+// addTree adds dir and every subdirectory the repository at root does not
+// ignore to watcher.
+func addTree(watcher *fsnotify.Watcher, root, dir string) error {
+	ignored := ignoredDirs(root, dir)
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Directories can vanish between the event and the walk.
+			return nil
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if d.Name() == ".git" || ignored[path] {
+			return filepath.SkipDir
+		}
+		if err := watcher.Add(path); err != nil {
+			return fmt.Errorf("failed to watch %s: %w", path, err)
+		}
+		return nil
+	})
+}
+
+// relevant filters out noise such as editor swap files and git internals
+// other than the index.
+func relevant(root string, event fsnotify.Event) bool {
+	if event.Op == fsnotify.Chmod {
+		return false
+	}
+
+	rel, err := filepath.Rel(root, event.Name)
+	if err != nil {
+		return true
+	}
+	if filepath.Dir(rel) == ".git" {
+		return filepath.Base(rel) == "index"
+	}
+
+	base := filepath.Base(rel)
+	switch {
+	case len(base) > 0 && base[len(base)-1] == '~':
+		return false
+	case filepath.Ext(base) == ".swp" || filepath.Ext(base) == ".swx":
+		return false
+	}
+	return true
+}
+
+// ignoredDirs returns the directories under dir, dir included, that the
+// repository at root ignores. Outside a git repository nothing is ignored.
+func ignoredDirs(root, dir string) map[string]bool {
+	rel, err := filepath.Rel(root, dir)
+	if err != nil {
+		return nil
+	}
+	cmd := exec.Command("git", "-C", root, "ls-files", "-z", "--others", "--ignored", "--exclude-standard", "--directory", "--", rel)
+	cmd.Env = append(os.Environ(), "GIT_LITERAL_PATHSPECS=1")
+	output, err := cmd.Output()
+	if err != nil {
+		logging.Debug("failed to list ignored directories", "path", dir, "error", err)
+		return nil
+	}
+
+	ignored := make(map[string]bool)
+	for _, entry := range strings.Split(string(output), "\x00") {
+		// Only whole directories carry a trailing slash.
+		if name, ok := strings.CutSuffix(entry, "/"); ok {
+			ignored[filepath.Join(root, filepath.FromSlash(name))] = true
+		}
+	}
+	return ignored
+}
+
+// allIgnored reports whether the repository at root ignores every one of
+// paths. When git cannot tell, the paths count as changes.
+func allIgnored(root string, paths []string) bool {
+	if len(paths) == 0 {
+		return false
+	}
+	var input bytes.Buffer
+	for _, path := range paths {
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return false
+		}
+		input.WriteString(filepath.ToSlash(rel) + "\x00")
+	}
+
+	cmd := exec.Command("git", "-C", root, "check-ignore", "-z", "--stdin")
+	cmd.Stdin = &input
+	output, err := cmd.Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		// Exit status 1 means none of the paths is ignored.
+		return false
+	}
+	if err != nil {
+		logging.Debug("failed to check ignored paths", "error", err)
+		return false
+	}
+
+	ignored := make(map[string]bool)
+	for _, rel := range strings.Split(string(output), "\x00") {
+		ignored[rel] = true
+	}
+	for _, path := range paths {
+		rel, _ := filepath.Rel(root, path)
+		if !ignored[filepath.ToSlash(rel)] {
+			return false
+		}
+	}
+	return true
+}
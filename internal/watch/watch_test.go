@@ -0,0 +1,165 @@
+package watch
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func startWatch(t *testing.T, opts Options) *atomic.Int32 {
+	t.Helper()
+
+	var calls atomic.Int32
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- Run(ctx, opts, func() { calls.Add(1) })
+	}()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("Run returned error: %v", err)
+		}
+	})
+
+	// Give the watcher time to register before the test mutates the tree.
+	time.Sleep(100 * time.Millisecond)
+	return &calls
+}
+
+func waitForCalls(calls *atomic.Int32, want int32, timeout time.Duration) int32 {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if got := calls.Load(); got >= want {
+			return got
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return calls.Load()
+}
+
+func TestRunDebouncesBursts(t *testing.T) {
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, "src"), 0o755); err != nil {
+		t.Fatalf("failed to create src: %v", err)
+	}
+
+	calls := startWatch(t, Options{Root: root, Debounce: 200 * time.Millisecond})
+
+	for i := 0; i < 5; i++ {
+		if err := os.WriteFile(filepath.Join(root, "src", "main.go"), []byte{byte('a' + i)}, 0o644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	if got := waitForCalls(calls, 1, 2*time.Second); got != 1 {
+		t.Fatalf("expected one notification for the burst, got %d", got)
+	}
+
+	// Let any stray timer fire before asserting the count stayed at one.
+	time.Sleep(400 * time.Millisecond)
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("expected exactly one notification, got %d", got)
+	}
+}
+
+func TestRunIgnoresConfiguredFilesAndGitInternals(t *testing.T) {
+	root := t.TempDir()
+	gitDir := filepath.Join(root, ".git")
+	if err := os.Mkdir(gitDir, 0o755); err != nil {
+		t.Fatalf("failed to create .git: %v", err)
+	}
+	draft := filepath.Join(root, "DRAFT")
+
+	calls := startWatch(t, Options{Root: root, Debounce: 50 * time.Millisecond, Ignore: []string{draft}})
+
+	if err := os.WriteFile(draft, []byte("draft"), 0o644); err != nil {
+		t.Fatalf("failed to write draft: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(gitDir, "ORIG_HEAD"), []byte("x"), 0o644); err != nil {
+		t.Fatalf("failed to write ORIG_HEAD: %v", err)
+	}
+
+	time.Sleep(300 * time.Millisecond)
+	if got := calls.Load(); got != 0 {
+		t.Fatalf("expected ignored writes not to notify, got %d", got)
+	}
+
+	if err := os.WriteFile(filepath.Join(gitDir, "index"), []byte("x"), 0o644); err != nil {
+		t.Fatalf("failed to write index: %v", err)
+	}
+	if got := waitForCalls(calls, 1, 2*time.Second); got != 1 {
+		t.Fatalf("expected index change to notify once, got %d", got)
+	}
+}
+
+func TestRunWatchesNewDirectories(t *testing.T) {
+	root := t.TempDir()
+	calls := startWatch(t, Options{Root: root, Debounce: 50 * time.Millisecond})
+
+	nested := filepath.Join(root, "pkg")
+	if err := os.Mkdir(nested, 0o755); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	if got := waitForCalls(calls, 1, 2*time.Second); got != 1 {
+		t.Fatalf("expected directory creation to notify, got %d", got)
+	}
+
+	if err := os.WriteFile(filepath.Join(nested, "file.go"), []byte("package pkg"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if got := waitForCalls(calls, 2, 2*time.Second); got != 2 {
+		t.Fatalf("expected write in new directory to notify, got %d", got)
+	}
+}
+
+func TestRunSkipsGitIgnoredPaths(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git executable not available")
+	}
+
+	root := t.TempDir()
+	if output, err := exec.Command("git", "init", root).CombinedOutput(); err != nil {
+		t.Fatalf("failed to init git repo: %v: %s", err, string(output))
+	}
+	if err := os.WriteFile(filepath.Join(root, ".gitignore"), []byte("out/\n*.log\n"), 0o644); err != nil {
+		t.Fatalf("failed to write .gitignore: %v", err)
+	}
+	for _, dir := range []string{"out", "src"} {
+		if err := os.Mkdir(filepath.Join(root, dir), 0o755); err != nil {
+			t.Fatalf("failed to create %s: %v", dir, err)
+		}
+	}
+
+	calls := startWatch(t, Options{Root: root, Debounce: 50 * time.Millisecond})
+
+	if err := os.WriteFile(filepath.Join(root, "out", "bundle.js"), []byte("x"), 0o644); err != nil {
+		t.Fatalf("failed to write build output: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "src", "debug.log"), []byte("x"), 0o644); err != nil {
+		t.Fatalf("failed to write log: %v", err)
+	}
+	if err := os.Mkdir(filepath.Join(root, "src", "out"), 0o755); err != nil {
+		t.Fatalf("failed to create nested output directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "src", "out", "gen.go"), []byte("x"), 0o644); err != nil {
+		t.Fatalf("failed to write nested output: %v", err)
+	}
+
+	time.Sleep(300 * time.Millisecond)
+	if got := calls.Load(); got != 0 {
+		t.Fatalf("expected ignored paths not to notify, got %d", got)
+	}
+
+	if err := os.WriteFile(filepath.Join(root, "src", "main.go"), []byte("package main"), 0o644); err != nil {
+		t.Fatalf("failed to write source: %v", err)
+	}
+	if got := waitForCalls(calls, 1, 2*time.Second); got != 1 {
+		t.Fatalf("expected source change to notify once, got %d", got)
+	}
+}
@@ -0,0 +1,139 @@
+// Package gocommit is the library API for embedding commit message
+// generation in other Go programs. It collects the scrubbed changes of a Git
+// repository, asks the chosen provider for a message, and cleans the result
+// the same way the commit CLI does.
+package gocommit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dfanso/commit-msg/internal/git"
+	"github.com/dfanso/commit-msg/internal/issues"
+	"github.com/dfanso/commit-msg/internal/llm"
+	"github.com/dfanso/commit-msg/internal/postprocess"
+	"github.com/dfanso/commit-msg/internal/scrubber"
+	"github.com/dfanso/commit-msg/pkg/types"
+)
+
+var (
+	// ErrNotRepository is returned when RepoPath is not inside a Git work tree.
+	ErrNotRepository = errors.New("gocommit: not a git repository")
+	// ErrNoChanges is returned when the repository has nothing to describe.
+	ErrNoChanges = errors.New("gocommit: no changes to describe")
+)
+
+// Options selects the provider and tunes generation.
+type Options struct {
+	// Provider is the LLM backend to use, such as types.ProviderOpenAI or a
+	// plugin created with types.PluginProvider.
+	Provider types.LLMProvider
+	// Credential is the provider's API key, or the endpoint URL for Ollama.
+	// When empty the provider's usual environment variable is consulted.
+	Credential string
+	// Endpoint points an OpenAI, Claude, Gemini, or Grok provider at a
+	// gateway or test server instead of the provider's own API.
+	Endpoint string
+	// StyleInstruction adds tone or format guidance to the prompt. It may
+	// reference {{branch}}, {{ticket}}, and {{author}}.
+	StyleInstruction string
+	// Temperature and MaxTokens override the provider defaults when set.
+	Temperature *float64
+	MaxTokens   int
+	// Seed asks providers that support it for the same message on every
+	// run over the same changes.
+	Seed *int64
+	// Limits bounds how much repository content is sent; zero fields use
+	// the defaults.
+	Limits types.ContentLimits
+	// Raw skips the clean-up of code fences, quotes, and verb tense.
+	Raw bool
+}
+
+// Result is a generated commit message and how it was produced. Tokens is
+// set when the provider reports its usage.
+type Result = types.GenerationResult
+
+// Generate produces a commit message for the uncommitted changes of the
+// repository at repoPath.
+func Generate(ctx context.Context, repoPath string, opts Options) (*Result, error) {
+	if opts.Provider == "" {
+		return nil, errors.New("gocommit: Options.Provider is required")
+	}
+	if !git.IsRepository(repoPath) {
+		return nil, ErrNotRepository
+	}
+
+	limits := opts.Limits.WithDefaults()
+	config := &types.RepoConfig{Path: repoPath, Limits: limits}
+
+	changes, err := git.GetChanges(config)
+	if err != nil {
+		return nil, fmt.Errorf("gocommit: failed to collect changes: %w", err)
+	}
+	if strings.TrimSpace(changes) == "" {
+		return nil, ErrNoChanges
+	}
+	changes = truncate(changes, limits.MaxTotalPromptBytes)
+
+	genOpts := &types.GenerationOptions{
+		StyleInstruction: opts.StyleInstruction,
+		Attempt:          1,
+		Temperature:      opts.Temperature,
+		Seed:             opts.Seed,
+		MaxTokens:        opts.MaxTokens,
+	}
+	if branch, err := git.CurrentBranch(config); err == nil {
+		genOpts.Branch = branch
+	}
+	var ticket string
+	if ref, ok := issues.DetectRef(genOpts.Branch); ok {
+		ticket = ref.String()
+	}
+	genOpts.Variables = map[string]string{
+		types.StyleVarBranch: genOpts.Branch,
+		types.StyleVarTicket: ticket,
+		types.StyleVarScope:  "",
+		types.StyleVarAuthor: git.ConfigValue(config, "user.name"),
+	}
+	genOpts.Upstream = git.Upstream(config)
+	genOpts.Repository = git.RepoName(config)
+	if commits, err := git.RecentHistory(config); err == nil {
+		genOpts.RecentCommits = strings.TrimSpace(commits)
+	}
+
+	provider, err := llm.NewProvider(opts.Provider, llm.ProviderOptions{Credential: opts.Credential, Endpoint: opts.Endpoint})
+	if err != nil {
+		return nil, fmt.Errorf("gocommit: %s: %w", opts.Provider, err)
+	}
+
+	result, err := llm.Generate(ctx, provider, changes, genOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Message = strings.TrimSpace(result.Message)
+	if !opts.Raw {
+		result.Message = postprocess.Apply(result.Message, postprocess.DefaultOptions())
+	}
+	result.ScrubFindings = scrubber.Findings(changes)
+	return result, nil
+}
+
+// truncate cuts changes at the last whole line that fits in maxBytes.
+func truncate(changes string, maxBytes int) string {
+	if len(changes) <= maxBytes {
+		return changes
+	}
+	cut := strings.LastIndex(changes[:maxBytes], "\n")
+	if cut <= 0 {
+		cut = maxBytes
+		for cut > 0 && !utf8.RuneStart(changes[cut]) {
+			cut--
+		}
+	}
+	return changes[:cut]
+}
@@ -0,0 +1,107 @@
+package gocommit
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dfanso/commit-msg/internal/llm"
+	"github.com/dfanso/commit-msg/pkg/types"
+)
+
+const fakeProvider types.LLMProvider = "gocommit-test"
+
+type stubProvider struct {
+	changes string
+	opts    *types.GenerationOptions
+}
+
+func (p *stubProvider) Name() types.LLMProvider { return fakeProvider }
+
+func (p *stubProvider) Generate(_ context.Context, changes string, opts *types.GenerationOptions) (string, error) {
+	p.changes = changes
+	p.opts = opts
+	return "```\nAdded greeting file.\n```", nil
+}
+
+func newRepo(t *testing.T) string {
+	t.Helper()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git executable not available")
+	}
+
+	dir := t.TempDir()
+	cmd := exec.Command("git", "init", dir)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("failed to init git repo: %v: %s", err, string(output))
+	}
+	return dir
+}
+
+func TestGenerate(t *testing.T) {
+	stub := &stubProvider{}
+	llm.RegisterFactory(fakeProvider, func(llm.ProviderOptions) (llm.Provider, error) {
+		return stub, nil
+	})
+
+	dir := newRepo(t)
+	if _, err := Generate(context.Background(), dir, Options{Provider: fakeProvider}); !errors.Is(err, ErrNoChanges) {
+		t.Fatalf("expected ErrNoChanges, got %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "hello.txt"), []byte("hello\n"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	result, err := Generate(context.Background(), dir, Options{Provider: fakeProvider, StyleInstruction: "be brief"})
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if result.Message != "Add greeting file" {
+		t.Fatalf("message = %q, want cleaned message", result.Message)
+	}
+	if result.Provider != fakeProvider {
+		t.Fatalf("provider = %q", result.Provider)
+	}
+	if !strings.Contains(stub.changes, "hello.txt") {
+		t.Fatalf("changes did not include the new file: %q", stub.changes)
+	}
+	if stub.opts.StyleInstruction != "be brief" {
+		t.Fatalf("style instruction not passed: %+v", stub.opts)
+	}
+
+	raw, err := Generate(context.Background(), dir, Options{Provider: fakeProvider, Raw: true})
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if !strings.HasPrefix(raw.Message, "```") {
+		t.Fatalf("expected raw message, got %q", raw.Message)
+	}
+}
+
+func TestGenerateRejectsNonRepository(t *testing.T) {
+	t.Parallel()
+
+	if _, err := Generate(context.Background(), t.TempDir(), Options{Provider: types.ProviderOpenAI}); !errors.Is(err, ErrNotRepository) {
+		t.Fatalf("expected ErrNotRepository, got %v", err)
+	}
+	if _, err := Generate(context.Background(), t.TempDir(), Options{}); err == nil {
+		t.Fatal("expected error without a provider")
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	t.Parallel()
+
+	if got := truncate("one\ntwo\nthree", 9); got != "one\ntwo" {
+		t.Fatalf("truncate = %q", got)
+	}
+	if got := truncate("héllo", 2); got != "h" {
+		t.Fatalf("truncate = %q, want rune-safe cut", got)
+	}
+}
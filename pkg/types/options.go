@@ -4,7 +4,58 @@ package types
 type GenerationOptions struct {
 	// StyleInstruction contains optional tone/style guidance appended to the base prompt.
 	StyleInstruction string
+	// Variables are substituted for {{name}} references in StyleInstruction;
+	// see ExpandStyleVariables and the StyleVar* names.
+	Variables map[string]string
 	// Attempt records the 1-indexed attempt number for this generation request.
 	// Attempt > 1 signals that the LLM should provide an alternative output.
 	Attempt int
+	// Scope is an optional hint about where the changes live, such as the
+	// monorepo package they belong to.
+	Scope string
+	// Branch and RecentCommits describe the repository for prompt templates.
+	Branch        string
+	RecentCommits string
+	// Upstream describes the branch Branch tracks, and Repository names the
+	// repository.
+	Upstream   string
+	Repository string
+	// Examples are commit messages the user accepted before, shown to the
+	// LLM as style demonstrations.
+	Examples []string
+	// ProjectContext holds the opening lines of configured project files,
+	// such as the README, so the LLM knows the project's vocabulary.
+	ProjectContext string
+	// PreviousMessage is an existing commit message the LLM should improve
+	// rather than write from scratch.
+	PreviousMessage string
+	// Template overrides DefaultPromptTemplate when non-empty.
+	Template string
+	// Temperature overrides the provider's sampling temperature when set.
+	Temperature *float64
+	// Seed asks providers that support it for deterministic sampling, so
+	// the same prompt produces the same message.
+	Seed *int64
+	// Candidates asks providers that can return several completions from
+	// one request for this many; the extra ones are reported in
+	// GenerationResult.Alternatives. Values below 2 request one message.
+	Candidates int
+	// CacheScope identifies the repository the changes come from; cached
+	// messages are only reused within the same scope. Empty shares them
+	// across repositories.
+	CacheScope string
+	// MaxTokens caps the length of the generated message when positive.
+	MaxTokens int
+	// Progress, when set, receives the text generated so far from
+	// providers that can stream their output.
+	Progress func(partial string)
+}
+
+// TemperatureOr returns the requested sampling temperature, or def when o is
+// nil or leaves Temperature unset.
+func (o *GenerationOptions) TemperatureOr(def float64) float64 {
+	if o == nil || o.Temperature == nil {
+		return def
+	}
+	return *o.Temperature
 }
@@ -1,8 +1,9 @@
 package types
 
 import (
-	"fmt"
+	"io"
 	"strings"
+	"text/template"
 )
 
 // CommitPrompt is the base instruction template sent to LLM providers before
@@ -25,27 +26,141 @@ here is a sample commit msgs:
 Here are the changes:
 `
 
-// BuildCommitPrompt constructs the prompt that will be sent to the LLM, applying
-// any optional tone/style instructions before appending the repository changes.
-func BuildCommitPrompt(changes string, opts *GenerationOptions) string {
-	var builder strings.Builder
-	builder.WriteString(CommitPrompt)
+// DefaultPromptTemplate is the text/template used to build the prompt when
+// no override is configured. Templates are executed with PromptData.
+const DefaultPromptTemplate = `{{.Base}}{{with .Examples}}
+
+Examples of commit messages I wrote previously; match their style and format:
+{{range .}}---
+{{.}}
+{{end}}---{{end}}{{if or .Repository .CurrentBranch}}
+
+Repository context (branch names often state the intent of the change):{{with .Repository}}
+- Repository: {{.}}{{end}}{{with .CurrentBranch}}
+- Branch: {{.}}{{end}}{{with .Upstream}}
+- Upstream: {{.}}{{end}}{{end}}{{with .ProjectContext}}
+
+Project context (use its terms to name what the changes touch):
+{{.}}{{end}}{{if gt .Attempt 1}}
+
+Regeneration context:
+- This is attempt #{{.Attempt}}.
+- Provide a commit message that is meaningfully different from earlier attempts.
+{{end}}{{with .PreviousMessage}}
+
+The changes were committed with the message below. Rewrite it so it follows
+these guidelines and accurately describes the changes, keeping any issue
+references and trailers:
+---
+{{.}}
+---{{end}}{{with .Instructions}}
+
+Additional instructions:
+{{.}}{{end}}
+
+{{.Changes}}`
+
+var defaultPromptTemplate = template.Must(ParsePromptTemplate(DefaultPromptTemplate))
+
+// PromptData is the data available to prompt templates.
+type PromptData struct {
+	// Base is the built-in instruction text (CommitPrompt).
+	Base string
+	// Changes is the collected, scrubbed repository changes.
+	Changes string
+	// RecentCommits lists recent commit subjects, one per line.
+	RecentCommits string
+	// Branch is the current branch name ("HEAD" when detached).
+	Branch string
+	// Upstream describes the tracked branch, such as "origin/main (2 ahead)".
+	Upstream string
+	// Repository is the repository name.
+	Repository string
+	// Scope is the monorepo scope hint, if any.
+	Scope string
+	// Style is the user's tone/style instruction, if any.
+	Style string
+	// Attempt is the 1-indexed generation attempt.
+	Attempt int
+	// Examples are previously accepted commit messages.
+	Examples []string
+	// PreviousMessage is the existing message to improve, if any.
+	PreviousMessage string
+	// ProjectContext is the opening lines of configured project files.
+	ProjectContext string
+}
+
+// CurrentBranch returns Branch, or "" when HEAD is detached.
+func (d PromptData) CurrentBranch() string {
+	if d.Branch == "HEAD" {
+		return ""
+	}
+	return d.Branch
+}
 
+// Instructions joins the style instruction and scope hint, which the default
+// template renders as "Additional instructions".
+func (d PromptData) Instructions() string {
+	var parts []string
+	if style := strings.TrimSpace(d.Style); style != "" {
+		parts = append(parts, style)
+	}
+	if scope := strings.TrimSpace(d.Scope); scope != "" {
+		parts = append(parts, scope)
+	}
+	return strings.Join(parts, "\n")
+}
+
+// ParsePromptTemplate parses a prompt template.
+func ParsePromptTemplate(text string) (*template.Template, error) {
+	return template.New("prompt").Option("missingkey=error").Parse(text)
+}
+
+// ValidatePromptTemplate reports whether text parses and executes against
+// sample data, catching references to unknown fields before generation.
+func ValidatePromptTemplate(text string) error {
+	tmpl, err := ParsePromptTemplate(text)
+	if err != nil {
+		return err
+	}
+	sample := PromptData{Base: CommitPrompt, Changes: "diff", Attempt: 1}
+	return tmpl.Execute(io.Discard, sample)
+}
+
+// BuildCommitPrompt constructs the prompt that will be sent to the LLM by
+// executing opts.Template, or DefaultPromptTemplate when none is set. An
+// invalid override falls back to the default template.
+func BuildCommitPrompt(changes string, opts *GenerationOptions) string {
+	data := PromptData{
+		Base:    CommitPrompt,
+		Changes: changes,
+	}
 	if opts != nil {
-		if opts.Attempt > 1 {
-			builder.WriteString("\n\nRegeneration context:\n")
-			builder.WriteString(fmt.Sprintf("- This is attempt #%d.\n", opts.Attempt))
-			builder.WriteString("- Provide a commit message that is meaningfully different from earlier attempts.\n")
-		}
+		data.RecentCommits = opts.RecentCommits
+		data.Branch = opts.Branch
+		data.Upstream = opts.Upstream
+		data.Repository = opts.Repository
+		data.Scope = opts.Scope
+		data.Style = opts.Style()
+		data.Attempt = opts.Attempt
+		data.Examples = opts.Examples
+		data.PreviousMessage = opts.PreviousMessage
+		data.ProjectContext = opts.ProjectContext
 
-		if strings.TrimSpace(opts.StyleInstruction) != "" {
-			builder.WriteString("\n\nAdditional instructions:\n")
-			builder.WriteString(strings.TrimSpace(opts.StyleInstruction))
+		if strings.TrimSpace(opts.Template) != "" {
+			custom, err := ParsePromptTemplate(opts.Template)
+			if err == nil {
+				var builder strings.Builder
+				if err := custom.Execute(&builder, data); err == nil {
+					return builder.String()
+				}
+			}
 		}
 	}
 
-	builder.WriteString("\n\n")
-	builder.WriteString(changes)
-
+	// The built-in template is known to be valid and strings.Builder never
+	// fails, so execution cannot error here.
+	var builder strings.Builder
+	_ = defaultPromptTemplate.Execute(&builder, data)
 	return builder.String()
 }
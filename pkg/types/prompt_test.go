@@ -0,0 +1,27 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/dfanso/commit-msg/internal/benchdata"
+)
+
+func BenchmarkBuildCommitPrompt(b *testing.B) {
+	opts := &GenerationOptions{Scope: "api", StyleInstruction: "Mention the migration in the body"}
+	for _, size := range []struct {
+		name         string
+		files, lines int
+	}{
+		{"10files", 10, 20},
+		{"100files", 100, 50},
+		{"500files", 500, 50},
+	} {
+		diff := benchdata.Diff(size.files, size.lines)
+		b.Run(size.name, func(b *testing.B) {
+			b.SetBytes(int64(len(diff)))
+			for i := 0; i < b.N; i++ {
+				BuildCommitPrompt(diff, opts)
+			}
+		})
+	}
+}
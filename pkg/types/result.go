@@ -0,0 +1,25 @@
+package types
+
+import "time"
+
+// GenerationResult is a generated commit message together with how it was
+// produced.
+type GenerationResult struct {
+	Message string `json:"message"`
+	// Alternatives are the other candidates returned by the same request
+	// when GenerationOptions.Candidates asked for more than one.
+	Alternatives []string    `json:"alternatives,omitempty"`
+	Provider     LLMProvider `json:"provider,omitempty"`
+	Model        string      `json:"model,omitempty"`
+	// Tokens is the usage reported by the provider, or recorded with the
+	// cache entry, when known.
+	Tokens *UsageInfo `json:"tokens,omitempty"`
+	// Cost is the estimated cost in US dollars; for cache hits it is the
+	// cost the cache saved.
+	Cost     float64       `json:"cost,omitempty"`
+	Duration time.Duration `json:"duration_ns"`
+	CacheHit bool          `json:"cache_hit"`
+	// ScrubFindings lists the kinds of sensitive data redacted from the
+	// changes before they were sent, such as "OPENAI_KEY".
+	ScrubFindings []string `json:"scrub_findings,omitempty"`
+}
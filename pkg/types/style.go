@@ -0,0 +1,67 @@
+package types
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Names of the variables style instructions can reference as {{name}}.
+const (
+	// StyleVarBranch is the current branch.
+	StyleVarBranch = "branch"
+	// StyleVarTicket is the issue key found in the branch name, such as
+	// "PROJ-123" or "#42".
+	StyleVarTicket = "ticket"
+	// StyleVarScope is the monorepo package the changes belong to.
+	StyleVarScope = "scope"
+	// StyleVarAuthor is the committer's git user.name.
+	StyleVarAuthor = "author"
+)
+
+// StylePreset is a named style instruction saved by the user. The JSON names
+// are the keys of the entries in the "styles" section of config.json.
+type StylePreset struct {
+	Name        string `json:"name"`
+	Instruction string `json:"instruction"`
+}
+
+var styleVariable = regexp.MustCompile(`\{\{\s*([A-Za-z_]+)\s*\}\}`)
+
+// ExpandStyleVariables replaces {{name}} references in text with the value
+// of name in vars. A line referencing a variable that is known but empty is
+// dropped, so "Prefix the subject with {{ticket}}:" disappears on branches
+// without a ticket. References to unknown names are left as written.
+func ExpandStyleVariables(text string, vars map[string]string) string {
+	if !strings.Contains(text, "{{") {
+		return text
+	}
+
+	lines := strings.Split(text, "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		missing := false
+		expanded := styleVariable.ReplaceAllStringFunc(line, func(ref string) string {
+			name := strings.ToLower(styleVariable.FindStringSubmatch(ref)[1])
+			value, ok := vars[name]
+			if !ok {
+				return ref
+			}
+			if strings.TrimSpace(value) == "" {
+				missing = true
+			}
+			return value
+		})
+		if !missing {
+			kept = append(kept, expanded)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
+
+// Style returns StyleInstruction with Variables expanded.
+func (o *GenerationOptions) Style() string {
+	if o == nil {
+		return ""
+	}
+	return ExpandStyleVariables(o.StyleInstruction, o.Variables)
+}
@@ -1,5 +1,10 @@
 package types
 
+import (
+	"strings"
+	"time"
+)
+
 // LLMProvider identifies the large language model backend used to author
 // commit messages.
 type LLMProvider string
@@ -11,20 +16,50 @@ const (
 	ProviderGrok   LLMProvider = "Grok"
 	ProviderGroq   LLMProvider = "Groq"
 	ProviderOllama LLMProvider = "Ollama"
+
+	ProviderHuggingFace LLMProvider = "HuggingFace"
+	ProviderVertex      LLMProvider = "VertexAI"
+	ProviderCohere      LLMProvider = "Cohere"
+	ProviderTogether    LLMProvider = "Together"
+	ProviderFireworks   LLMProvider = "Fireworks"
 )
 
+// PluginPrefix marks providers implemented by an external executable, as in
+// "plugin:mycompany".
+const PluginPrefix = "plugin:"
+
+// PluginProvider returns the provider identifier for the named plugin.
+func PluginProvider(name string) LLMProvider {
+	return LLMProvider(PluginPrefix + name)
+}
+
 // String returns the provider identifier as a plain string.
 func (p LLMProvider) String() string {
 	return string(p)
 }
 
-// IsValid reports whether the provider is part of the supported set.
+// IsPlugin reports whether the provider is an external plugin.
+func (p LLMProvider) IsPlugin() bool {
+	return strings.HasPrefix(string(p), PluginPrefix) && p.PluginName() != ""
+}
+
+// PluginName returns the plugin name for plugin providers and "" otherwise.
+func (p LLMProvider) PluginName() string {
+	name, ok := strings.CutPrefix(string(p), PluginPrefix)
+	if !ok {
+		return ""
+	}
+	return name
+}
+
+// IsValid reports whether the provider is part of the supported set or a
+// plugin.
 func (p LLMProvider) IsValid() bool {
 	switch p {
-	case ProviderOpenAI, ProviderClaude, ProviderGemini, ProviderGrok, ProviderGroq, ProviderOllama:
+	case ProviderOpenAI, ProviderClaude, ProviderGemini, ProviderGrok, ProviderGroq, ProviderOllama, ProviderHuggingFace, ProviderVertex, ProviderCohere, ProviderTogether, ProviderFireworks:
 		return true
 	default:
-		return false
+		return p.IsPlugin()
 	}
 }
 
@@ -37,6 +72,11 @@ func GetSupportedProviders() []LLMProvider {
 		ProviderGrok,
 		ProviderGroq,
 		ProviderOllama,
+		ProviderHuggingFace,
+		ProviderVertex,
+		ProviderCohere,
+		ProviderTogether,
+		ProviderFireworks,
 	}
 }
 
@@ -58,6 +98,13 @@ func ParseLLMProvider(s string) (LLMProvider, bool) {
 
 // Config stores CLI-level configuration including named repositories.
 type Config struct {
+	// OpenAIAPI is the OpenAI API root; empty uses OpenAI's.
+	OpenAIAPI string `json:"openai_api"`
+	// ClaudeAPI is the Claude messages endpoint; empty uses Anthropic's.
+	ClaudeAPI string `json:"claude_api"`
+	// GeminiAPI is the Gemini API root; empty uses Google's.
+	GeminiAPI string `json:"gemini_api"`
+	// GrokAPI is the Grok chat completions endpoint; empty uses xAI's.
 	GrokAPI string                `json:"grok_api"`
 	Repos   map[string]RepoConfig `json:"repos"`
 }
@@ -66,9 +113,95 @@ type Config struct {
 type RepoConfig struct {
 	Path    string `json:"path"`
 	LastRun string `json:"last_run"`
+	// Limits bounds how much content is collected from the repository.
+	Limits ContentLimits `json:"-"`
+}
+
+// Default content limits, used for any ContentLimits field left at zero.
+const (
+	DefaultMaxFileBytes        = 10 * 1024
+	DefaultMaxTotalPromptBytes = 8000
+	DefaultMaxUntrackedFiles   = 100
+	DefaultRecentCommits       = 3
+	// DefaultMaxUntrackedBytes caps the content read from all untracked
+	// files together.
+	DefaultMaxUntrackedBytes = 64 * 1024
+	// DefaultMaxUntrackedContents is how many untracked files have their
+	// content included.
+	DefaultMaxUntrackedContents = 20
+	// DefaultUntrackedReadTimeout bounds the time spent reading untracked
+	// files, which can hang on network mounts.
+	DefaultUntrackedReadTimeout = 5 * time.Second
+)
+
+// ContentLimits bounds how much repository content is included in the
+// prompt. Zero values fall back to the defaults.
+type ContentLimits struct {
+	// MaxFileBytes is the largest untracked file whose content is included.
+	MaxFileBytes int64 `json:"max_file_bytes,omitempty"`
+	// MaxTotalPromptBytes caps the collected changes sent to the LLM.
+	MaxTotalPromptBytes int `json:"max_total_prompt_bytes,omitempty"`
+	// MaxUntrackedFiles caps how many untracked files are listed.
+	MaxUntrackedFiles int `json:"max_untracked_files,omitempty"`
+	// RecentCommits is how many recent commits are shown for context. A
+	// negative value leaves the recent commits out of the prompt.
+	RecentCommits int `json:"recent_commits,omitempty"`
+	// RecentCommitBodies includes the full message of each recent commit
+	// instead of only its subject.
+	RecentCommitBodies bool `json:"recent_commit_bodies,omitempty"`
+	// MaxUntrackedBytes caps the content read from all untracked files
+	// together.
+	MaxUntrackedBytes int64 `json:"max_untracked_bytes,omitempty"`
+	// MaxUntrackedContents caps how many untracked files have their content
+	// included; the rest are only listed.
+	MaxUntrackedContents int `json:"max_untracked_contents,omitempty"`
+	// UntrackedReadTimeout bounds the time spent reading untracked files,
+	// as a Go duration such as "5s".
+	UntrackedReadTimeout string `json:"untracked_read_timeout,omitempty"`
+	// UntrackedIgnore lists patterns of untracked files that are listed but
+	// never read, with the syntax of the "generated" patterns.
+	UntrackedIgnore []string `json:"untracked_ignore,omitempty"`
+}
+
+// WithDefaults returns l with unset or negative fields replaced by the
+// defaults. A negative RecentCommits is kept, since it disables the recent
+// commits section.
+func (l ContentLimits) WithDefaults() ContentLimits {
+	if l.MaxFileBytes <= 0 {
+		l.MaxFileBytes = DefaultMaxFileBytes
+	}
+	if l.MaxTotalPromptBytes <= 0 {
+		l.MaxTotalPromptBytes = DefaultMaxTotalPromptBytes
+	}
+	if l.MaxUntrackedFiles <= 0 {
+		l.MaxUntrackedFiles = DefaultMaxUntrackedFiles
+	}
+	if l.RecentCommits == 0 {
+		l.RecentCommits = DefaultRecentCommits
+	}
+	if l.MaxUntrackedBytes <= 0 {
+		l.MaxUntrackedBytes = DefaultMaxUntrackedBytes
+	}
+	if l.MaxUntrackedContents <= 0 {
+		l.MaxUntrackedContents = DefaultMaxUntrackedContents
+	}
+	return l
+}
+
+// UntrackedTimeout returns UntrackedReadTimeout as a duration, or the
+// default when it is unset or invalid.
+func (l ContentLimits) UntrackedTimeout() time.Duration {
+	d, err := time.ParseDuration(l.UntrackedReadTimeout)
+	if err != nil || d <= 0 {
+		return DefaultUntrackedReadTimeout
+	}
+	return d
 }
 
 // GrokRequest represents a chat completion request sent to X.AI's API.
+//
+// Deprecated: the Grok client sends the OpenAI-compatible request in
+// internal/openaicompat; this type is no longer used.
 type GrokRequest struct {
 	Messages    []Message `json:"messages"`
 	Model       string    `json:"model"`
@@ -83,6 +216,9 @@ type Message struct {
 }
 
 // GrokResponse contains the relevant fields parsed from X.AI responses.
+//
+// Deprecated: xAI returns the OpenAI-compatible response decoded by
+// internal/openaicompat; this type is no longer used.
 type GrokResponse struct {
 	Message Message   `json:"message,omitempty"`
 	Choices []Choice  `json:"choices,omitempty"`
@@ -131,6 +267,8 @@ type CacheStats struct {
 	OldestEntry    string  `json:"oldest_entry"`
 	NewestEntry    string  `json:"newest_entry"`
 	CacheSizeBytes int64   `json:"cache_size_bytes"`
+	// LastCleanup is when expired entries were last removed, in RFC 3339.
+	LastCleanup string `json:"last_cleanup,omitempty"`
 }
 
 // CacheConfig holds configuration for the cache system.
@@ -138,6 +276,7 @@ type CacheConfig struct {
 	Enabled         bool   `json:"enabled"`
 	MaxEntries      int    `json:"max_entries"`
 	MaxAgeDays      int    `json:"max_age_days"`
+	MaxSizeBytes    int64  `json:"max_size_bytes,omitempty"`
 	CleanupInterval int    `json:"cleanup_interval_hours"`
 	CacheFilePath   string `json:"cache_file_path"`
 }
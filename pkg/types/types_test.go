@@ -84,6 +84,37 @@ func TestBuildCommitPromptWithInstructions(t *testing.T) {
 	}
 }
 
+func TestExpandStyleVariables(t *testing.T) {
+	t.Parallel()
+
+	vars := map[string]string{
+		StyleVarBranch: "feature/PROJ-7-login",
+		StyleVarTicket: "PROJ-7",
+		StyleVarScope:  "",
+		StyleVarAuthor: "Ada",
+	}
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Be brief.", "Be brief."},
+		{"Prefix the subject with {{ticket}}:", "Prefix the subject with PROJ-7:"},
+		{"Branch {{ branch }} by {{AUTHOR}}", "Branch feature/PROJ-7-login by Ada"},
+		{"Be brief.\nUse {{scope}} as the scope.\nMention {{ticket}}.", "Be brief.\nMention PROJ-7."},
+		{"Keep {{unknown}} as written", "Keep {{unknown}} as written"},
+	}
+	for _, tt := range tests {
+		if got := ExpandStyleVariables(tt.text, vars); got != tt.want {
+			t.Errorf("ExpandStyleVariables(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+
+	prompt := BuildCommitPrompt("diff", &GenerationOptions{StyleInstruction: "Prefix the subject with {{ticket}}:", Variables: vars})
+	if !strings.Contains(prompt, "Prefix the subject with PROJ-7:") {
+		t.Fatalf("expected expanded style instruction in prompt, got %q", prompt)
+	}
+}
+
 func TestBuildCommitPromptWithAttempt(t *testing.T) {
 	t.Parallel()
 
@@ -103,3 +134,141 @@ func TestBuildCommitPromptWithAttempt(t *testing.T) {
 		t.Fatalf("expected prompt to end with changes, got %q", prompt)
 	}
 }
+
+func TestBuildCommitPromptWithScope(t *testing.T) {
+	t.Parallel()
+
+	changes := "diff --git a/main.go b/main.go"
+	options := &GenerationOptions{StyleInstruction: "Be brief.", Scope: "Use \"api\" as the scope."}
+	prompt := BuildCommitPrompt(changes, options)
+
+	if !strings.Contains(prompt, "Additional instructions:\nBe brief.\nUse \"api\" as the scope.") {
+		t.Fatalf("expected style and scope in additional instructions, got %q", prompt)
+	}
+}
+
+func TestBuildCommitPromptCustomTemplate(t *testing.T) {
+	t.Parallel()
+
+	options := &GenerationOptions{
+		Branch:        "feature/login",
+		RecentCommits: "abc123 Add user model",
+		Template:      "Branch: {{.Branch}}\nHistory: {{.RecentCommits}}\n{{.Changes}}",
+	}
+	prompt := BuildCommitPrompt("diff", options)
+
+	want := "Branch: feature/login\nHistory: abc123 Add user model\ndiff"
+	if prompt != want {
+		t.Fatalf("BuildCommitPrompt() = %q, want %q", prompt, want)
+	}
+}
+
+func TestBuildCommitPromptInvalidTemplateFallsBack(t *testing.T) {
+	t.Parallel()
+
+	changes := "diff --git a/main.go b/main.go"
+	prompt := BuildCommitPrompt(changes, &GenerationOptions{Template: "{{.Nope}}"})
+
+	if !strings.HasPrefix(prompt, CommitPrompt) || !strings.HasSuffix(prompt, changes) {
+		t.Fatalf("expected default prompt on template error, got %q", prompt)
+	}
+}
+
+func TestValidatePromptTemplate(t *testing.T) {
+	t.Parallel()
+
+	if err := ValidatePromptTemplate(DefaultPromptTemplate); err != nil {
+		t.Fatalf("default template invalid: %v", err)
+	}
+	if err := ValidatePromptTemplate("{{.Changes"); err == nil {
+		t.Fatal("expected parse error")
+	}
+	if err := ValidatePromptTemplate("{{.Unknown}}"); err == nil {
+		t.Fatal("expected error for unknown field")
+	}
+}
+
+func TestBuildCommitPromptWithExamples(t *testing.T) {
+	t.Parallel()
+
+	changes := "diff --git a/main.go b/main.go"
+	options := &GenerationOptions{Examples: []string{"feat(api): add login", "fix: handle nil config"}}
+	prompt := BuildCommitPrompt(changes, options)
+
+	want := "Examples of commit messages I wrote previously; match their style and format:\n---\nfeat(api): add login\n---\nfix: handle nil config\n---"
+	if !strings.Contains(prompt, want) {
+		t.Fatalf("expected examples block %q, got %q", want, prompt)
+	}
+	if !strings.HasSuffix(prompt, changes) {
+		t.Fatalf("expected prompt to end with changes, got %q", prompt)
+	}
+}
+
+func TestBuildCommitPromptWithPreviousMessage(t *testing.T) {
+	t.Parallel()
+
+	changes := "diff --git a/main.go b/main.go"
+	prompt := BuildCommitPrompt(changes, &GenerationOptions{PreviousMessage: "fixed stuff"})
+
+	if !strings.Contains(prompt, "Rewrite it") || !strings.Contains(prompt, "---\nfixed stuff\n---") {
+		t.Fatalf("expected prompt to include the previous message, got %q", prompt)
+	}
+	if strings.Contains(BuildCommitPrompt(changes, nil), "Rewrite it") {
+		t.Fatal("expected no rewrite block without a previous message")
+	}
+}
+
+func TestBuildCommitPromptWithRepositoryContext(t *testing.T) {
+	t.Parallel()
+
+	prompt := BuildCommitPrompt("diff", &GenerationOptions{
+		Repository: "commit-msg",
+		Branch:     "hotfix/payment-retry",
+		Upstream:   "origin/hotfix/payment-retry (1 ahead)",
+	})
+	for _, want := range []string{
+		"Repository context",
+		"- Repository: commit-msg",
+		"- Branch: hotfix/payment-retry",
+		"- Upstream: origin/hotfix/payment-retry (1 ahead)",
+	} {
+		if !strings.Contains(prompt, want) {
+			t.Fatalf("expected prompt to contain %q, got %q", want, prompt)
+		}
+	}
+
+	if prompt := BuildCommitPrompt("diff", &GenerationOptions{Branch: "HEAD"}); strings.Contains(prompt, "Repository context") {
+		t.Fatalf("expected no repository context for a detached HEAD, got %q", prompt)
+	}
+}
+
+func TestPluginProvider(t *testing.T) {
+	t.Parallel()
+
+	p := PluginProvider("acme")
+	if !p.IsPlugin() || !p.IsValid() || p.PluginName() != "acme" {
+		t.Fatalf("unexpected plugin provider %q", p)
+	}
+	if LLMProvider(PluginPrefix).IsValid() {
+		t.Fatal("expected empty plugin name to be invalid")
+	}
+	if ProviderOpenAI.IsPlugin() || ProviderOpenAI.PluginName() != "" {
+		t.Fatal("expected built-in provider not to be a plugin")
+	}
+}
+
+func TestTemperatureOr(t *testing.T) {
+	t.Parallel()
+
+	var nilOpts *GenerationOptions
+	if got := nilOpts.TemperatureOr(0.2); got != 0.2 {
+		t.Fatalf("expected default for nil options, got %v", got)
+	}
+	if got := (&GenerationOptions{}).TemperatureOr(0.2); got != 0.2 {
+		t.Fatalf("expected default for unset temperature, got %v", got)
+	}
+	zero := 0.0
+	if got := (&GenerationOptions{Temperature: &zero}).TemperatureOr(0.2); got != 0 {
+		t.Fatalf("expected explicit zero temperature, got %v", got)
+	}
+}
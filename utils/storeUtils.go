@@ -1,11 +1,49 @@
 package StoreUtils
 
 import (
+	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"runtime"
+	"strings"
 )
 
+// ProfileEnv names the environment variable that selects a configuration
+// profile when --profile is not given.
+const ProfileEnv = "COMMIT_PROFILE"
+
+// ConfigDirEnv names the environment variable that moves the application
+// directory, and the cache with it, for portable installs.
+const ConfigDirEnv = "COMMIT_MSG_CONFIG_DIR"
+
+const appName = "commit-msg"
+
+var (
+	profile      string
+	validProfile = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+)
+
+// SetProfile selects the configuration profile for the rest of the process.
+// An empty name falls back to COMMIT_PROFILE.
+func SetProfile(name string) {
+	profile = strings.TrimSpace(name)
+}
+
+// Profile returns the selected configuration profile, or "" for the
+// default one. Profile names may contain letters, digits, '-' and '_'.
+func Profile() (string, error) {
+	name := profile
+	if name == "" {
+		name = strings.TrimSpace(os.Getenv(ProfileEnv))
+	}
+	if name != "" && !validProfile.MatchString(name) {
+		return "", fmt.Errorf("invalid profile name %q: use letters, digits, '-' and '_'", name)
+	}
+	return name, nil
+}
+
 func CheckConfig(configPath string) bool {
 
 	_, err := os.Stat(configPath)
@@ -27,42 +65,172 @@ func CreateConfigFile(configPath string) error {
 
 }
 
-func GetConfigPath() (string, error) {
-
-	appName := "commit-msg"
+// BackupPath returns where WriteConfigFile keeps the previous contents of
+// the config at path.
+func BackupPath(path string) string {
+	return path + ".bak"
+}
 
-	switch runtime.GOOS {
+// WriteConfigFile replaces the config at path without ever leaving it
+// partly written: data goes to a temporary file in the same directory,
+// which is synced and then renamed over path. The previous contents, when
+// they are valid JSON, are kept in BackupPath(path) first.
+func WriteConfigFile(path string, data []byte) error {
+	if err := CreateConfigFile(path); err != nil {
+		return err
+	}
 
-	case "windows":
-		localAppData := os.Getenv("LOCALAPPDATA")
-		if localAppData == "" {
-			localAppData = filepath.Join(os.Getenv("USERPROFILE"), "AppData", "Local")
+	if old, err := os.ReadFile(path); err == nil && len(old) > 2 && json.Valid(old) {
+		if err := writeFileAtomic(BackupPath(path), old); err != nil {
+			return fmt.Errorf("failed to back up config: %w", err)
 		}
+	}
+	return writeFileAtomic(path, data)
+}
 
-		return filepath.Join(localAppData, appName, "config.json"), nil
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
 
-	case "darwin":
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// GetConfigPath returns the config.json of the selected profile. The
+// default profile lives directly in the application directory; a named
+// profile gets its own directory under profiles/, so its history, cache,
+// and other files stay separate too.
+func GetConfigPath() (string, error) {
+	dir, err := GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	name, err := Profile()
+	if err != nil {
+		return "", err
+	}
+	if name != "" {
+		dir = filepath.Join(dir, "profiles", name)
+	}
+	return filepath.Join(dir, "config.json"), nil
+}
+
+// GetConfigDir returns the application directory shared by all profiles:
+// COMMIT_MSG_CONFIG_DIR when set, for portable installs; otherwise
+// %LOCALAPPDATA%\commit-msg on Windows,
+// ~/Library/Application Support/commit-msg on macOS, and
+// $XDG_CONFIG_HOME/commit-msg (~/.config/commit-msg) elsewhere.
+func GetConfigDir() (string, error) {
+	if dir, ok, err := configDirOverride(); ok || err != nil {
+		return dir, err
+	}
 
+	switch runtime.GOOS {
+	case "windows":
+		return filepath.Join(localAppData(), appName), nil
+	case "darwin":
 		home, err := os.UserHomeDir()
 		if err != nil {
 			return "", err
 		}
+		return filepath.Join(home, "Library", "Application Support", appName), nil
+	default:
+		return xdgDir("XDG_CONFIG_HOME", ".config")
+	}
+}
 
-		return filepath.Join(home, "Library", "Application Support", appName, "config.json"), nil
+// GetCacheDir returns the directory for the selected profile's files that
+// can be rebuilt, such as cached commit messages. It is kept apart from the
+// config so it can be cleared or excluded from backups:
+// COMMIT_MSG_CONFIG_DIR/cache when set; otherwise
+// %LOCALAPPDATA%\commit-msg\cache on Windows, ~/Library/Caches/commit-msg
+// on macOS, and $XDG_CACHE_HOME/commit-msg (~/.cache/commit-msg) elsewhere.
+// Named profiles get their own directory under profiles/.
+func GetCacheDir() (string, error) {
+	dir, err := cacheBaseDir()
+	if err != nil {
+		return "", err
+	}
 
-	default:
+	name, err := Profile()
+	if err != nil {
+		return "", err
+	}
+	if name != "" {
+		dir = filepath.Join(dir, "profiles", name)
+	}
+	return dir, nil
+}
 
-		configHome := os.Getenv("XDG_CONFIG_HOME")
-		if configHome == "" {
-			home, err := os.UserHomeDir()
-			if err != nil {
-				return "", err
-			}
+func cacheBaseDir() (string, error) {
+	if dir, ok, err := configDirOverride(); ok || err != nil {
+		return filepath.Join(dir, "cache"), err
+	}
 
-			configHome = filepath.Join(home, ".config")
+	switch runtime.GOOS {
+	case "windows":
+		return filepath.Join(localAppData(), appName, "cache"), nil
+	case "darwin":
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
 		}
+		return filepath.Join(home, "Library", "Caches", appName), nil
+	default:
+		return xdgDir("XDG_CACHE_HOME", ".cache")
+	}
+}
 
-		return filepath.Join(configHome, appName, "config.json"), nil
+// configDirOverride returns the directory named by COMMIT_MSG_CONFIG_DIR,
+// made absolute, and whether it is set.
+func configDirOverride() (string, bool, error) {
+	dir := strings.TrimSpace(os.Getenv(ConfigDirEnv))
+	if dir == "" {
+		return "", false, nil
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", true, fmt.Errorf("invalid %s %q: %w", ConfigDirEnv, dir, err)
 	}
+	return abs, true, nil
+}
 
+func localAppData() string {
+	if dir := os.Getenv("LOCALAPPDATA"); dir != "" {
+		return dir
+	}
+	return filepath.Join(os.Getenv("USERPROFILE"), "AppData", "Local")
+}
+
+// xdgDir returns the commit-msg directory under the XDG base directory
+// named by env, or under fallback in the home directory when env is unset.
+// The XDG Base Directory specification says relative paths must be
+// ignored, so they fall back too.
+func xdgDir(env, fallback string) (string, error) {
+	base := os.Getenv(env)
+	if base == "" || !filepath.IsAbs(base) {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		base = filepath.Join(home, fallback)
+	}
+	return filepath.Join(base, appName), nil
 }